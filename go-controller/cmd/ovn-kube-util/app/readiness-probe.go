@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	"github.com/urfave/cli/v2"
 	kexec "k8s.io/utils/exec"
@@ -156,6 +157,11 @@ func ovnNodeReadiness(target string) error {
 	if os.IsNotExist(err) {
 		return fmt.Errorf("OVN Kubernetes config file %q doesn't exist", confFile)
 	}
+	// ovnkube-node reports the management port as degraded while its health
+	// check keeps finding it drifted from its configuration
+	if reason, err := os.ReadFile(types.ManagementPortDegradedFile); err == nil {
+		return fmt.Errorf("management port is degraded: %s", strings.TrimSpace(string(reason)))
+	}
 	return nil
 }
 
@@ -538,7 +538,17 @@ func runOvnKube(ctx context.Context, runMode *ovnkubeRunMode, ovnClientset *util
 		}
 		// register ovnkube node specific prometheus metrics exported by the node
 		metrics.RegisterNodeMetrics()
-		ncm, err := controllerManager.NewNodeNetworkControllerManager(ovnClientset, nodeWatchFactory, runMode.identity, eventRecorder)
+		if config.Metrics.EnableServiceLBStats {
+			metrics.RegisterServiceLBStatsMetrics(nodeWatchFactory, stopChan)
+		}
+		// there is no local OVS in dpu-host mode
+		var ovsClient libovsdbclient.Client
+		if config.OvnKubeNode.Mode != types.NodeModeDPUHost {
+			if ovsClient, err = libovsdb.NewOVSClient(stopChan); err != nil {
+				return fmt.Errorf("error when trying to initialize libovsdb OVS client: %v", err)
+			}
+		}
+		ncm, err := controllerManager.NewNodeNetworkControllerManager(ovnClientset, nodeWatchFactory, runMode.identity, eventRecorder, ovsClient)
 		if err != nil {
 			return fmt.Errorf("failed to create ovnkube node ovnkube controller: %w", err)
 		}
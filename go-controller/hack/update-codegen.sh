@@ -103,3 +103,7 @@ echo "Copying adminpolicybasedexternalroutes CRD"
 cp _output/crds/k8s.ovn.org_adminpolicybasedexternalroutes.yaml ../dist/templates/k8s.ovn.org_adminpolicybasedexternalroutes.yaml.j2
 echo "Copying egressService CRD"
 cp _output/crds/k8s.ovn.org_egressservices.yaml ../dist/templates/k8s.ovn.org_egressservices.yaml.j2
+echo "Copying IPAMClaim CRD"
+cp _output/crds/k8s.ovn.org_ipamclaims.yaml ../dist/templates/k8s.ovn.org_ipamclaims.yaml.j2
+echo "Copying NetworkDefinition CRD"
+cp _output/crds/k8s.ovn.org_networkdefinitions.yaml ../dist/templates/k8s.ovn.org_networkdefinitions.yaml.j2
@@ -112,6 +112,35 @@ func NewAllocatorCIDRRange(cidr *net.IPNet, allocatorFactory allocator.Allocator
 	return &r, err
 }
 
+// NewAllocatorIPRange creates a Range over the IPs from start to end of a
+// net.IPNet, calling allocatorFactory to construct the backing store.
+func NewAllocatorIPRange(cidr *net.IPNet, start, end net.IP, allocatorFactory allocator.AllocatorFactory) (*Range, error) {
+	if !cidr.Contains(start) || !cidr.Contains(end) {
+		return nil, fmt.Errorf("range %s-%s is not contained in %s", start, end, cidr)
+	}
+	base := utilnet.BigForIP(start)
+	size := big.NewInt(0).Sub(utilnet.BigForIP(end), base)
+	size.Add(size, big.NewInt(1))
+	if size.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid range %s-%s: start after end", start, end)
+	}
+	max := int64(65536)
+	if utilnet.IsIPv4CIDR(cidr) || size.Cmp(big.NewInt(max)) < 0 {
+		// Limit the max size of IPv6 ranges, since the allocator keeps a
+		// bitmap of that size.
+		max = size.Int64()
+	}
+
+	r := Range{
+		net:  cidr,
+		base: base,
+		max:  int(max),
+	}
+	var err error
+	r.alloc, err = allocatorFactory(r.max, fmt.Sprintf("%s-%s", start, end))
+	return &r, err
+}
+
 // NewCIDRRange is a helper that wraps NewAllocatorCIDRRange, for creating a range backed by an in-memory store.
 func NewCIDRRange(cidr *net.IPNet) (*Range, error) {
 	return NewAllocatorCIDRRange(cidr, func(max int, rangeSpec string) (allocator.Interface, error) {
@@ -189,8 +218,7 @@ func (r *Range) Release(ip net.IP) {
 // ForEach calls the provided function for each allocated IP.
 func (r *Range) ForEach(fn func(net.IP)) {
 	r.alloc.ForEach(func(offset int) {
-		ip, _ := utilnet.GetIndexedIP(r.net, offset+1) // +1 because Range doesn't store IP 0
-		fn(ip)
+		fn(utilnet.AddIPOffset(r.base, offset))
 	})
 }
 
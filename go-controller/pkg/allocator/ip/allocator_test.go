@@ -20,6 +20,7 @@ import (
 	"net"
 	"testing"
 
+	allocator "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/bitmap"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -285,3 +286,75 @@ func TestReserved(t *testing.T) {
 		t.Errorf("should not be a reserved address: %s", "192.168.1.254")
 	}
 }
+
+func TestAllocateIPRange(t *testing.T) {
+	_, cidr, err := net.ParseCIDR("192.168.1.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewAllocatorIPRange(cidr, net.ParseIP("192.168.1.100"), net.ParseIP("192.168.1.102"),
+		func(max int, rangeSpec string) (allocator.Interface, error) {
+			return allocator.NewAllocationMap(max, rangeSpec), nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := r.Free(); f != 3 {
+		t.Fatalf("unexpected free %d", f)
+	}
+	for _, ip := range []string{"192.168.1.99", "192.168.1.103"} {
+		if err := r.Allocate(net.ParseIP(ip)); err == nil {
+			t.Errorf("expected IP %s out of range", ip)
+		}
+	}
+	found := sets.NewString()
+	for i := 0; i < 3; i++ {
+		ip, err := r.AllocateNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		found.Insert(ip.String())
+	}
+	if !found.Equal(sets.NewString("192.168.1.100", "192.168.1.101", "192.168.1.102")) {
+		t.Errorf("unexpected allocated IPs %v", found.List())
+	}
+	if _, err := r.AllocateNext(); err != ErrFull {
+		t.Errorf("expected range to be full, got %v", err)
+	}
+	calls := sets.NewString()
+	r.ForEach(func(ip net.IP) {
+		calls.Insert(ip.String())
+	})
+	if !calls.Equal(found) {
+		t.Errorf("expected calls to equal allocated IPs: %v vs %v", calls.List(), found.List())
+	}
+
+	_, cidr, err = net.ParseCIDR("2001:db8:1::/48")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = NewAllocatorIPRange(cidr, net.ParseIP("2001:db8:1::1:0"), net.ParseIP("2001:db8:1:ffff::"),
+		func(max int, rangeSpec string) (allocator.Interface, error) {
+			return allocator.NewAllocationMap(max, rangeSpec), nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := r.Free(); f != 65536 {
+		t.Errorf("unexpected free %d", f)
+	}
+	if err := r.Allocate(net.ParseIP("2001:db8:1::1:ffff")); err != nil {
+		t.Errorf("unexpected error allocating the last IP of the range: %v", err)
+	}
+	if err := r.Allocate(net.ParseIP("2001:db8:1::2:0")); err == nil {
+		t.Errorf("expected IP beyond the size limit of the range out of range")
+	}
+
+	_, err = NewAllocatorIPRange(cidr, net.ParseIP("2001:db8:1::2"), net.ParseIP("2001:db8:1::1"),
+		func(max int, rangeSpec string) (allocator.Interface, error) {
+			return allocator.NewAllocationMap(max, rangeSpec), nil
+		})
+	if err == nil {
+		t.Errorf("expected an error for a range starting after its end")
+	}
+}
@@ -1,6 +1,7 @@
 package subnet
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
@@ -18,11 +19,13 @@ import (
 // identified by a name. Allocator should be threadsafe.
 type Allocator interface {
 	AddOrUpdateSubnet(name string, subnets []*net.IPNet, excludeSubnets ...*net.IPNet) error
+	AddOrUpdateSubnetWithIPPools(name string, subnets []*net.IPNet, ipPools []util.IPPool, excludeSubnets ...*net.IPNet) error
 	DeleteSubnet(name string)
 	GetSubnets(name string) ([]*net.IPNet, error)
 	AllocateUntilFull(name string) error
 	AllocateIPs(name string, ips []*net.IPNet) error
 	AllocateNextIPs(name string) ([]*net.IPNet, error)
+	AllocateNextIPsFromPools(name string, pools []string) ([]*net.IPNet, error)
 	ReleaseIPs(name string, ips []*net.IPNet) error
 	ConditionalIPRelease(name string, ips []*net.IPNet, predicate func() (bool, error)) (bool, error)
 	ForSubnet(name string) NamedAllocator
@@ -33,6 +36,7 @@ type Allocator interface {
 type NamedAllocator interface {
 	AllocateIPs(ips []*net.IPNet) error
 	AllocateNextIPs() ([]*net.IPNet, error)
+	AllocateNextIPsFromPools(pools []string) ([]*net.IPNet, error)
 	ReleaseIPs(ips []*net.IPNet) error
 }
 
@@ -41,10 +45,35 @@ var ErrSubnetNotFound = errors.New("subnet not found")
 
 // subnetInfo contains information corresponding to the subnet. It holds the
 // allocations (v4 and v6) as well as the IPAM allocator instances for each
-// of the managed subnets
+// of the managed subnets and IP pools. The IPs of the pools are only
+// allocated through the IPAM allocator instances of the pools.
 type subnetInfo struct {
 	subnets []*net.IPNet
 	ipams   []ipallocator.Interface
+	pools   map[string]*poolInfo
+}
+
+// poolInfo holds the IPAM allocator instance of an IP pool
+type poolInfo struct {
+	util.IPPool
+	ipam ipallocator.Interface
+}
+
+// getIPAM returns the IPAM allocator instance the IP is allocated from: the
+// one of the pool the IP belongs to, if any, or the one of its subnet
+func (subnetInfo *subnetInfo) getIPAM(ip net.IP) ipallocator.Interface {
+	for _, pool := range subnetInfo.pools {
+		if pool.Contains(ip) {
+			return pool.ipam
+		}
+	}
+	for _, ipam := range subnetInfo.ipams {
+		cidr := ipam.CIDR()
+		if cidr.Contains(ip) {
+			return ipam
+		}
+	}
+	return nil
 }
 
 type ipamFactoryFunc func(*net.IPNet) (ipallocator.Interface, error)
@@ -68,6 +97,15 @@ func newIPAMAllocator(cidr *net.IPNet) (ipallocator.Interface, error) {
 	})
 }
 
+// newIPAMPoolAllocator provides an ipam interface which can be used for IPAM
+// allocations for the range of an IP pool using a contiguous allocation
+// strategy.
+func newIPAMPoolAllocator(pool util.IPPool) (ipallocator.Interface, error) {
+	return ipallocator.NewAllocatorIPRange(pool.Subnet, pool.RangeStart, pool.RangeEnd, func(max int, rangeSpec string) (bitmapallocator.Interface, error) {
+		return bitmapallocator.NewRoundRobinAllocationMap(max, rangeSpec), nil
+	})
+}
+
 // Initializes a new subnet IP allocator
 func NewAllocator() *allocator {
 	return &allocator{
@@ -79,6 +117,12 @@ func NewAllocator() *allocator {
 
 // AddOrUpdateSubnet set to the allocator for IPAM management, or update it.
 func (allocator *allocator) AddOrUpdateSubnet(name string, subnets []*net.IPNet, excludeSubnets ...*net.IPNet) error {
+	return allocator.AddOrUpdateSubnetWithIPPools(name, subnets, nil, excludeSubnets...)
+}
+
+// AddOrUpdateSubnetWithIPPools set to the allocator for IPAM management, or
+// update it, along with IP pools of its subnets.
+func (allocator *allocator) AddOrUpdateSubnetWithIPPools(name string, subnets []*net.IPNet, ipPools []util.IPPool, excludeSubnets ...*net.IPNet) error {
 	allocator.Lock()
 	defer allocator.Unlock()
 	if subnetInfo, ok := allocator.cache[name]; ok && !reflect.DeepEqual(subnetInfo.subnets, subnets) {
@@ -92,9 +136,18 @@ func (allocator *allocator) AddOrUpdateSubnet(name string, subnets []*net.IPNet,
 		}
 		ipams = append(ipams, ipam)
 	}
+	pools := make(map[string]*poolInfo, len(ipPools))
+	for _, ipPool := range ipPools {
+		ipam, err := newIPAMPoolAllocator(ipPool)
+		if err != nil {
+			return fmt.Errorf("failed to initialize IPAM of IP pool %s for %s: %w", ipPool.Name, name, err)
+		}
+		pools[ipPool.Name] = &poolInfo{IPPool: ipPool, ipam: ipam}
+	}
 	allocator.cache[name] = subnetInfo{
 		subnets: subnets,
 		ipams:   ipams,
+		pools:   pools,
 	}
 
 	for _, excludeSubnet := range excludeSubnets {
@@ -111,6 +164,22 @@ func (allocator *allocator) AddOrUpdateSubnet(name string, subnets []*net.IPNet,
 		if !excluded {
 			return fmt.Errorf("failed to exclude subnet %s for %s: not contained in any of the subnets", excludeSubnet, name)
 		}
+		for _, pool := range pools {
+			if err := reserveSubnetInRange(excludeSubnet, pool.RangeStart, pool.RangeEnd, pool.ipam); err != nil {
+				return fmt.Errorf("failed to exclude subnet %s from IP pool %s for %s: %w", excludeSubnet, pool.Name, name, err)
+			}
+		}
+	}
+
+	// the IPs of the pools are not allocated out of the pools
+	for _, pool := range pools {
+		for i, subnet := range subnets {
+			if subnet.Contains(pool.RangeStart) {
+				if err := reserveSubnetInRange(subnet, pool.RangeStart, pool.RangeEnd, ipams[i]); err != nil {
+					return fmt.Errorf("failed to reserve IP pool %s for %s: %w", pool.Name, name, err)
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -175,13 +244,13 @@ func (allocator *allocator) AllocateIPs(name string, ips []*net.IPNet) error {
 	}
 
 	var err error
-	allocated := make(map[int]*net.IPNet)
+	allocated := make(map[ipallocator.Interface]*net.IPNet)
 	defer func() {
 		if err != nil {
-			// iterate over range of already allocated indices and release
-			// ips allocated before the error occurred.
-			for relIdx, relIPNet := range allocated {
-				subnetInfo.ipams[relIdx].Release(relIPNet.IP)
+			// iterate over range of already allocated IPAM instances and
+			// release ips allocated before the error occurred.
+			for relIPAM, relIPNet := range allocated {
+				relIPAM.Release(relIPNet.IP)
 				if relIPNet.IP != nil {
 					klog.Warningf("Reserved IP %s was released for %s", relIPNet.IP, name)
 				}
@@ -190,20 +259,18 @@ func (allocator *allocator) AllocateIPs(name string, ips []*net.IPNet) error {
 	}()
 
 	for _, ipnet := range ips {
-		for idx, ipam := range subnetInfo.ipams {
-			cidr := ipam.CIDR()
-			if cidr.Contains(ipnet.IP) {
-				if _, ok = allocated[idx]; ok {
-					err = fmt.Errorf("failed to allocate IP %s for %s: attempted to reserve multiple IPs in the same IPAM instance", ipnet.IP, name)
-					return err
-				}
-				if err = ipam.Allocate(ipnet.IP); err != nil {
-					return err
-				}
-				allocated[idx] = ipnet
-				break
-			}
+		ipam := subnetInfo.getIPAM(ipnet.IP)
+		if ipam == nil {
+			continue
+		}
+		if _, ok = allocated[ipam]; ok {
+			err = fmt.Errorf("failed to allocate IP %s for %s: attempted to reserve multiple IPs in the same IPAM instance", ipnet.IP, name)
+			return err
 		}
+		if err = ipam.Allocate(ipnet.IP); err != nil {
+			return err
+		}
+		allocated[ipam] = ipnet
 	}
 	return nil
 }
@@ -223,6 +290,46 @@ func reserveSubnets(subnet *net.IPNet, ipam ipallocator.Interface) error {
 	return nil
 }
 
+// reserveSubnetInRange reserves the subnet IPs within the range from start to
+// end
+func reserveSubnetInRange(subnet *net.IPNet, start, end net.IP, ipam ipallocator.Interface) error {
+	first, last := subnet.IP, lastIP(subnet)
+	if bytes.Compare(first.To16(), start.To16()) < 0 {
+		first = start
+	}
+	if bytes.Compare(last.To16(), end.To16()) > 0 {
+		last = end
+	}
+	for ip := first; bytes.Compare(ip.To16(), last.To16()) <= 0; ip = iputils.NextIP(ip) {
+		if ipam.Reserved(ip) || ipam.Has(ip) {
+			continue
+		}
+		err := ipam.Allocate(ip)
+		var errNotInRange *ipallocator.ErrNotInRange
+		if errors.As(err, &errNotInRange) {
+			// past the size limit of the IPAM allocator instance
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reserve IP %s: %w", ip, err)
+		}
+	}
+	return nil
+}
+
+// lastIP returns the last IP of the subnet
+func lastIP(subnet *net.IPNet) net.IP {
+	ip := subnet.IP
+	if len(subnet.Mask) == net.IPv4len {
+		ip = ip.To4()
+	}
+	last := make(net.IP, len(ip))
+	for i := range ip {
+		last[i] = ip[i] | ^subnet.Mask[i]
+	}
+	return last
+}
+
 // AllocateNextIPs allocates IP addresses from the given subnet set
 func (allocator *allocator) AllocateNextIPs(name string) ([]*net.IPNet, error) {
 	allocator.RLock()
@@ -272,6 +379,51 @@ func (allocator *allocator) AllocateNextIPs(name string) ([]*net.IPNet, error) {
 	return ipnets, nil
 }
 
+// AllocateNextIPsFromPools allocates an IP address from each of the given IP
+// pools of the subnet set
+func (allocator *allocator) AllocateNextIPsFromPools(name string, pools []string) ([]*net.IPNet, error) {
+	allocator.RLock()
+	defer allocator.RUnlock()
+	var ipnets []*net.IPNet
+	var ip net.IP
+	var err error
+	subnetInfo, ok := allocator.cache[name]
+
+	if !ok {
+		return nil, fmt.Errorf("failed to allocate new IPs for %s: %w", name, ErrSubnetNotFound)
+	}
+
+	var ipams []ipallocator.Interface
+	defer func() {
+		if err != nil {
+			// iterate over range of already allocated indices and release
+			// ips allocated before the error occurred.
+			for relIdx, relIPNet := range ipnets {
+				ipams[relIdx].Release(relIPNet.IP)
+				klog.Warningf("Reserved IP %s was released for %s", relIPNet.IP, name)
+			}
+		}
+	}()
+
+	for _, poolName := range pools {
+		pool, ok := subnetInfo.pools[poolName]
+		if !ok {
+			err = fmt.Errorf("failed to allocate new IPs for %s: IP pool %s not found", name, poolName)
+			return nil, err
+		}
+		ip, err = pool.ipam.AllocateNext()
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate new IP from IP pool %s for %s: %w", poolName, name, err)
+		}
+		ipams = append(ipams, pool.ipam)
+		ipnets = append(ipnets, &net.IPNet{
+			IP:   ip,
+			Mask: pool.Subnet.Mask,
+		})
+	}
+	return ipnets, nil
+}
+
 // ReleaseIPs marks the IPs in ipnets slice as available for allocation by
 // releasing them from the IPAM pool of allocated IPs of the given subnet set.
 // If there aren't IPs to release the method does not return an error.
@@ -287,12 +439,8 @@ func (allocator *allocator) ReleaseIPs(name string, ips []*net.IPNet) error {
 	}
 
 	for _, ipnet := range ips {
-		for _, ipam := range subnetInfo.ipams {
-			cidr := ipam.CIDR()
-			if cidr.Contains(ipnet.IP) {
-				ipam.Release(ipnet.IP)
-				break
-			}
+		if ipam := subnetInfo.getIPAM(ipnet.IP); ipam != nil {
+			ipam.Release(ipnet.IP)
 		}
 	}
 	return nil
@@ -318,13 +466,8 @@ func (allocator *allocator) ConditionalIPRelease(name string, ips []*net.IPNet,
 	// check if ipam has one of the ip addresses, and then execute the predicate function to determine
 	// if this IP should be released or not
 	for _, ipnet := range ips {
-		for _, ipam := range subnetInfo.ipams {
-			cidr := ipam.CIDR()
-			if cidr.Contains(ipnet.IP) {
-				if ipam.Has(ipnet.IP) {
-					return predicate()
-				}
-			}
+		if ipam := subnetInfo.getIPAM(ipnet.IP); ipam != nil && ipam.Has(ipnet.IP) {
+			return predicate()
 		}
 	}
 
@@ -372,6 +515,12 @@ func (ipAllocator *IPAllocator) AllocateNextIPs() ([]*net.IPNet, error) {
 	return ipAllocator.allocator.AllocateNextIPs(ipAllocator.name)
 }
 
+// AllocateNextIPsFromPools allocates the next available IPs of the provided
+// IP pools
+func (ipAllocator *IPAllocator) AllocateNextIPsFromPools(pools []string) ([]*net.IPNet, error) {
+	return ipAllocator.allocator.AllocateNextIPsFromPools(ipAllocator.name, pools)
+}
+
 // ReleaseIPs release the provided IPs
 func (ipAllocator *IPAllocator) ReleaseIPs(ips []*net.IPNet) error {
 	return ipAllocator.allocator.ReleaseIPs(ipAllocator.name, ips)
@@ -5,6 +5,7 @@ import (
 
 	ipam "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/ip"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
@@ -209,6 +210,76 @@ var _ = ginkgo.Describe("Subnet IP allocator operations", func() {
 
 	})
 
+	ginkgo.Context("when adding subnets with IP pools", func() {
+		const subnetName = "subnet1"
+		var ipPools []util.IPPool
+
+		ginkgo.BeforeEach(func() {
+			ipPools = []util.IPPool{
+				{
+					Name:       "pool1",
+					Subnet:     ovntest.MustParseIPNet("10.1.1.0/24"),
+					RangeStart: ovntest.MustParseIP("10.1.1.100"),
+					RangeEnd:   ovntest.MustParseIP("10.1.1.101"),
+				},
+				{
+					Name:       "pool2",
+					Subnet:     ovntest.MustParseIPNet("2000::/64"),
+					RangeStart: ovntest.MustParseIP("2000::1:0"),
+					RangeEnd:   ovntest.MustParseIP("2000::1:ffff"),
+				},
+			}
+		})
+
+		ginkgo.It("only allocates the IPs of the pools from the pools", func() {
+			err := allocator.AddOrUpdateSubnetWithIPPools(subnetName,
+				ovntest.MustParseIPNets("10.1.1.0/24", "2000::/64"), ipPools, ovntest.MustParseIPNet("10.1.1.101/32"))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ips, err := allocator.AllocateNextIPsFromPools(subnetName, []string{"pool1", "pool2"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(util.StringSlice(ips)).To(gomega.Equal([]string{"10.1.1.100/24", "2000::1:0/64"}))
+
+			// the pool is full, the excluded IP not being allocatable either
+			_, err = allocator.AllocateNextIPsFromPools(subnetName, []string{"pool1"})
+			gomega.Expect(err).To(gomega.MatchError(ipam.ErrFull))
+			_, err = allocator.AllocateNextIPsFromPools(subnetName, []string{"pool2", "pool1"})
+			gomega.Expect(err).To(gomega.MatchError(ipam.ErrFull))
+			_, err = allocator.AllocateNextIPsFromPools(subnetName, []string{"pool3"})
+			gomega.Expect(err).To(gomega.HaveOccurred())
+
+			// the IPs of the pools are out of the subnets allocation
+			gomega.Expect(allocator.AllocateIPs(subnetName, ovntest.MustParseIPNets("10.1.1.100/24"))).To(gomega.MatchError(ipam.ErrAllocated))
+			gomega.Expect(allocator.AllocateIPs(subnetName, ovntest.MustParseIPNets("10.1.1.101/24"))).To(gomega.MatchError(ipam.ErrAllocated))
+			err = allocator.AllocateUntilFull(subnetName)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			// released IPs go back to their pools
+			err = allocator.ReleaseIPs(subnetName, ips)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			ips, err = allocator.AllocateNextIPsFromPools(subnetName, []string{"pool1"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(util.StringSlice(ips)).To(gomega.Equal([]string{"10.1.1.100/24"}))
+			gomega.Expect(allocator.AllocateIPs(subnetName, ovntest.MustParseIPNets("2000::1:0/64"))).To(gomega.Succeed())
+		})
+
+		ginkgo.It("allocates the IPs out of the pools from the subnets", func() {
+			err := allocator.AddOrUpdateSubnetWithIPPools(subnetName, ovntest.MustParseIPNets("10.1.1.96/29"), ipPools[:1])
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			var allocated []string
+			for {
+				ips, err := allocator.AllocateNextIPs(subnetName)
+				if err != nil {
+					gomega.Expect(err).To(gomega.MatchError(ipam.ErrFull))
+					break
+				}
+				allocated = append(allocated, util.StringSlice(ips)...)
+			}
+			gomega.Expect(allocated).To(gomega.ConsistOf("10.1.1.97/29", "10.1.1.98/29", "10.1.1.99/29", "10.1.1.102/29"))
+		})
+	})
+
 })
 
 func TestSubnetIPAllocator(t *testing.T) {
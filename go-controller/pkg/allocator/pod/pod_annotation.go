@@ -14,6 +14,7 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/ip"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/ip/subnet"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 )
@@ -81,12 +82,17 @@ func allocatePodAnnotation(
 
 	allocateToPodWithRollback := func(pod *v1.Pod) (*v1.Pod, func(), error) {
 		var rollback func()
+		network, claimedIPs, err := requestVirtualMachineAddresses(podLister, netInfo, pod, network, nil)
+		if err != nil {
+			return nil, nil, err
+		}
 		pod, podAnnotation, rollback, err = allocatePodAnnotationWithRollback(
 			ipAllocator,
 			idAllocator,
 			netInfo,
 			pod,
 			network,
+			claimedIPs,
 			reallocateIP)
 		return pod, rollback, err
 	}
@@ -114,11 +120,18 @@ func allocatePodAnnotation(
 // derived from the allocator provided IPs. If the requested IPs cannot be
 // honored, a new set of IPs will be allocated unless reallocateIP is set to
 // false.
+//
+// claimedIPs are the IPs persisted for the workload of the pod, if any. They
+// are preferred over the requested IPs when the pod is not annotated yet and
+// may be already allocated: the caller must have made sure that they are held
+// on behalf of the workload of the pod, and not allocated to any other pod. If
+// they cannot be honored, a new set of IPs is allocated.
 func (allocator *PodAnnotationAllocator) AllocatePodAnnotationWithTunnelID(
 	ipAllocator subnet.NamedAllocator,
 	idAllocator id.NamedAllocator,
 	pod *v1.Pod,
 	network *nadapi.NetworkSelectionElement,
+	claimedIPs []*net.IPNet,
 	reallocateIP bool) (
 	*v1.Pod,
 	*util.PodAnnotation,
@@ -132,6 +145,7 @@ func (allocator *PodAnnotationAllocator) AllocatePodAnnotationWithTunnelID(
 		allocator.netInfo,
 		pod,
 		network,
+		claimedIPs,
 		reallocateIP,
 	)
 }
@@ -144,6 +158,7 @@ func allocatePodAnnotationWithTunnelID(
 	netInfo util.NetInfo,
 	pod *v1.Pod,
 	network *nadapi.NetworkSelectionElement,
+	claimedIPs []*net.IPNet,
 	reallocateIP bool) (
 	updatedPod *v1.Pod,
 	podAnnotation *util.PodAnnotation,
@@ -151,12 +166,17 @@ func allocatePodAnnotationWithTunnelID(
 
 	allocateToPodWithRollback := func(pod *v1.Pod) (*v1.Pod, func(), error) {
 		var rollback func()
+		network, claimedIPs, err := requestVirtualMachineAddresses(podLister, netInfo, pod, network, claimedIPs)
+		if err != nil {
+			return nil, nil, err
+		}
 		pod, podAnnotation, rollback, err = allocatePodAnnotationWithRollback(
 			ipAllocator,
 			idAllocator,
 			netInfo,
 			pod,
 			network,
+			claimedIPs,
 			reallocateIP)
 		return pod, rollback, err
 	}
@@ -175,6 +195,49 @@ func allocatePodAnnotationWithTunnelID(
 	return pod, podAnnotation, nil
 }
 
+// requestVirtualMachineAddresses returns the network selection element and
+// the claimed IPs to allocate a live migratable pod with on a secondary
+// network. A pod not annotated yet is requested the MAC and IPs the other pods
+// of its VM are annotated with, so that the VM keeps them when live migrated
+// between the pods.
+func requestVirtualMachineAddresses(
+	podLister listers.PodLister,
+	netInfo util.NetInfo,
+	pod *v1.Pod,
+	network *nadapi.NetworkSelectionElement,
+	claimedIPs []*net.IPNet) (
+	*nadapi.NetworkSelectionElement,
+	[]*net.IPNet,
+	error) {
+
+	if !netInfo.IsSecondary() || network == nil || !kubevirt.IsPodAllowedForMigration(pod, netInfo) {
+		return network, claimedIPs, nil
+	}
+
+	nadName := util.GetNADName(network.Namespace, network.Name)
+	if _, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName); err == nil {
+		return network, claimedIPs, nil
+	}
+
+	vmPodAnnotation, err := kubevirt.FindVMPodAnnotation(podLister, pod, nadName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vmPodAnnotation == nil {
+		return network, claimedIPs, nil
+	}
+
+	klog.V(5).Infof("Requesting MAC %s and IPs %v of the VM for pod %s/%s on nad %s",
+		vmPodAnnotation.MAC, util.StringSlice(vmPodAnnotation.IPs), pod.Namespace, pod.Name, nadName)
+	request := *network
+	request.MacRequest = vmPodAnnotation.MAC.String()
+	network = &request
+	if len(vmPodAnnotation.IPs) > 0 {
+		claimedIPs = vmPodAnnotation.IPs
+	}
+	return network, claimedIPs, nil
+}
+
 // allocatePodAnnotationWithRollback allocates the PodAnnotation which includes
 // IPs, a mac address, routes, gateways and an ID. Returns the allocated pod
 // annotation and a pod with that annotation set. Returns a nil pod and the existing
@@ -184,7 +247,8 @@ func allocatePodAnnotationWithTunnelID(
 // selection element or derived from the allocator provided IPs. If no IP
 // allocation is required, set allocateIP to false. If the requested IPs cannot
 // be honored, a new set of IPs will be allocated unless reallocateIP is set to
-// false.
+// false. Claimed IPs take precedence over requested IPs and are always
+// reallocated if they cannot be honored.
 
 // A rollback function is returned to rollback the IP allocation if there was
 // any.
@@ -198,6 +262,7 @@ func allocatePodAnnotationWithRollback(
 	netInfo util.NetInfo,
 	pod *v1.Pod,
 	network *nadapi.NetworkSelectionElement,
+	claimedIPs []*net.IPNet,
 	reallocateIP bool) (
 	updatedPod *v1.Pod,
 	podAnnotation *util.PodAnnotation,
@@ -250,6 +315,7 @@ func allocatePodAnnotationWithRollback(
 		IPs:      podAnnotation.IPs,
 		MAC:      podAnnotation.MAC,
 		TunnelID: podAnnotation.TunnelID,
+		VLANID:   podAnnotation.VLANID,
 	}
 
 	hasIDAllocation := util.DoesNetworkRequireTunnelIDs(netInfo)
@@ -287,9 +353,12 @@ func allocatePodAnnotationWithRollback(
 	needsIPOrMAC := len(tentative.IPs) == 0 && (hasIPAM || hasIPRequest)
 	needsIPOrMAC = needsIPOrMAC || len(tentative.MAC) == 0
 	reallocateOnNonStaticIPRequest := len(tentative.IPs) == 0 && hasIPRequest && !hasStaticIPRequest
+	reallocateOnClaimedIPs := len(tentative.IPs) == 0 && hasIPAM && len(claimedIPs) > 0
 
 	if len(tentative.IPs) == 0 {
-		if hasIPRequest {
+		if reallocateOnClaimedIPs {
+			tentative.IPs = util.CopyIPNets(claimedIPs)
+		} else if hasIPRequest {
 			tentative.IPs, err = util.ParseIPNets(network.IPRequest)
 			if err != nil {
 				return
@@ -299,10 +368,12 @@ func allocatePodAnnotationWithRollback(
 
 	if hasIPAM {
 		if len(tentative.IPs) > 0 {
+			// annotated IPs, and claimed IPs held for the workload of the pod
+			// or allocated to another pod of its VM, are already allocated
 			if err = ipAllocator.AllocateIPs(tentative.IPs); err != nil && !ip.IsErrAllocated(err) {
 				err = fmt.Errorf("failed to ensure requested or annotated IPs %v for %s: %w",
 					util.StringSlice(tentative.IPs), podDesc, err)
-				if !reallocateOnNonStaticIPRequest {
+				if !reallocateOnNonStaticIPRequest && !reallocateOnClaimedIPs {
 					return
 				}
 				klog.Warning(err.Error())
@@ -320,7 +391,19 @@ func allocatePodAnnotationWithRollback(
 		}
 
 		if len(tentative.IPs) == 0 {
-			tentative.IPs, err = ipAllocator.AllocateNextIPs()
+			// the IPs of the IP pools are only allocated to the pods
+			// selecting them
+			var ipPools []string
+			ipPools, err = util.GetPodIPPools(netInfo, pod, network)
+			if err != nil {
+				err = fmt.Errorf("failed to get the IP pools of %s: %w", podDesc, err)
+				return
+			}
+			if len(ipPools) > 0 {
+				tentative.IPs, err = ipAllocator.AllocateNextIPsFromPools(ipPools)
+			} else {
+				tentative.IPs, err = ipAllocator.AllocateNextIPs()
+			}
 			if err != nil {
 				err = fmt.Errorf("failed to assign pod addresses for %s: %w", podDesc, err)
 				return
@@ -349,6 +432,13 @@ func allocatePodAnnotationWithRollback(
 		if err != nil {
 			return
 		}
+
+		// handle the VLAN of the pod on trunk networks
+		tentative.VLANID, err = util.GetPodVLANID(netInfo, network)
+		if err != nil {
+			err = fmt.Errorf("failed to get the VLAN of %s: %w", podDesc, err)
+			return
+		}
 	}
 
 	needsAnnotationUpdate := needsIPOrMAC || needsID
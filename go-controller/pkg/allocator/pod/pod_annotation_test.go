@@ -7,6 +7,10 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -27,6 +31,7 @@ type ipAllocatorStub struct {
 	netxtIPs         []*net.IPNet
 	allocateIPsError error
 	releasedIPs      []*net.IPNet
+	pools            []string
 }
 
 func (a *ipAllocatorStub) AllocateIPs(ips []*net.IPNet) error {
@@ -37,6 +42,11 @@ func (a *ipAllocatorStub) AllocateNextIPs() ([]*net.IPNet, error) {
 	return a.netxtIPs, nil
 }
 
+func (a *ipAllocatorStub) AllocateNextIPsFromPools(pools []string) ([]*net.IPNet, error) {
+	a.pools = pools
+	return a.netxtIPs, nil
+}
+
 func (a *ipAllocatorStub) ReleaseIPs(ips []*net.IPNet) error {
 	a.releasedIPs = ips
 	return nil
@@ -80,18 +90,22 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 		ipAllocator subnet.NamedAllocator
 		idAllocator id.NamedAllocator
 		network     *nadapi.NetworkSelectionElement
+		claimedIPs  []*net.IPNet
 		reallocate  bool
 	}
 	tests := []struct {
 		name                      string
 		args                      args
 		ipam                      bool
+		ipPools                   []ovncnitypes.IPPool
+		vlanTrunk                 string
 		idAllocation              bool
 		podAnnotation             *util.PodAnnotation
 		invalidNetworkAnnotation  bool
 		wantUpdatedPod            bool
 		wantGeneratedMac          bool
 		wantPodAnnotation         *util.PodAnnotation
+		wantIPPools               []string
 		wantReleasedIPs           []*net.IPNet
 		wantReleasedIPsOnRollback []*net.IPNet
 		wantReleaseID             bool
@@ -191,6 +205,69 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 			},
 			wantReleasedIPsOnRollback: ovntest.MustParseIPNets("192.168.0.3/24"),
 		},
+		{
+			// on networks with IP pools, expect the IPs to be allocated from
+			// the pools of the pod namespace and the routes of the pools
+			name: "expect new IP from the IP pools of the namespace",
+			ipam: true,
+			ipPools: []ovncnitypes.IPPool{
+				{
+					Name:       "pool",
+					Subnet:     "192.168.0.0/24",
+					RangeStart: "192.168.0.100",
+					RangeEnd:   "192.168.0.199",
+					Gateway:    "192.168.0.254",
+					Routes:     []ovncnitypes.IPPoolRoute{{Dest: "10.0.0.0/8"}},
+					Namespaces: []string{"namespace"},
+				},
+			},
+			args: args{
+				ipAllocator: &ipAllocatorStub{
+					netxtIPs: ovntest.MustParseIPNets("192.168.0.100/24"),
+				},
+			},
+			wantUpdatedPod: true,
+			wantIPPools:    []string{"pool"},
+			wantPodAnnotation: &util.PodAnnotation{
+				IPs: ovntest.MustParseIPNets("192.168.0.100/24"),
+				MAC: util.IPAddrToHWAddr(ovntest.MustParseIPNets("192.168.0.100/24")[0].IP),
+				Routes: []util.PodRoute{
+					{
+						Dest:    ovntest.MustParseIPNet("10.0.0.0/8"),
+						NextHop: ovntest.MustParseIP("192.168.0.254").To4(),
+					},
+				},
+			},
+			wantReleasedIPsOnRollback: ovntest.MustParseIPNets("192.168.0.100/24"),
+		},
+		{
+			// on localnet VLAN trunks, expect the VLAN requested in the
+			// network selection annotation
+			name:      "expect requested VLAN on a VLAN trunk, no IPAM",
+			vlanTrunk: "100-199",
+			args: args{
+				network: &nadapi.NetworkSelectionElement{
+					MacRequest: requestedMAC,
+					CNIArgs:    &map[string]interface{}{util.VLANIDCNIArg: float64(150)},
+				},
+			},
+			wantUpdatedPod: true,
+			wantPodAnnotation: &util.PodAnnotation{
+				MAC:    requestedMACParsed,
+				VLANID: 150,
+			},
+		},
+		{
+			// on localnet VLAN trunks, fail on VLANs out of the trunk
+			name:      "expect error, requested VLAN out of the VLAN trunk, no IPAM",
+			vlanTrunk: "100-199",
+			args: args{
+				network: &nadapi.NetworkSelectionElement{
+					CNIArgs: &map[string]interface{}{util.VLANIDCNIArg: float64(200)},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			// on networks with IPAM, if pod is already annotated, expect no
 			// further updates but do allocate the IP
@@ -329,6 +406,59 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 			},
 			wantReleasedIPsOnRollback: ovntest.MustParseIPNets("192.168.0.3/24"),
 		},
+		{
+			// on networks with IPAM, honor the IPs persisted for the workload
+			// that are already allocated
+			name: "expect claimed IP, already allocated, IPAM",
+			ipam: true,
+			args: args{
+				reallocate: true,
+				claimedIPs: ovntest.MustParseIPNets("192.168.0.4/24"),
+				ipAllocator: &ipAllocatorStub{
+					netxtIPs:         ovntest.MustParseIPNets("192.168.0.3/24"),
+					allocateIPsError: ipam.ErrAllocated,
+				},
+			},
+			wantUpdatedPod: true,
+			wantPodAnnotation: &util.PodAnnotation{
+				IPs:      ovntest.MustParseIPNets("192.168.0.4/24"),
+				MAC:      util.IPAddrToHWAddr(ovntest.MustParseIPNets("192.168.0.4/24")[0].IP),
+				Gateways: []net.IP{ovntest.MustParseIP("192.168.0.1").To4()},
+				Routes: []util.PodRoute{
+					{
+						Dest:    ovntest.MustParseIPNet("100.64.0.0/16"),
+						NextHop: ovntest.MustParseIP("192.168.0.1").To4(),
+					},
+				},
+			},
+		},
+		{
+			// on networks with IPAM, try to honor the IPs persisted for the
+			// workload but re-allocate on error
+			name: "expect reallocate to new IP, error on claimed IP, IPAM",
+			ipam: true,
+			args: args{
+				reallocate: true,
+				claimedIPs: ovntest.MustParseIPNets("192.168.0.4/24"),
+				ipAllocator: &ipAllocatorStub{
+					netxtIPs:         ovntest.MustParseIPNets("192.168.0.3/24"),
+					allocateIPsError: errors.New("Allocate IPs failed"),
+				},
+			},
+			wantUpdatedPod: true,
+			wantPodAnnotation: &util.PodAnnotation{
+				IPs:      ovntest.MustParseIPNets("192.168.0.3/24"),
+				MAC:      util.IPAddrToHWAddr(ovntest.MustParseIPNets("192.168.0.3/24")[0].IP),
+				Gateways: []net.IP{ovntest.MustParseIP("192.168.0.1").To4()},
+				Routes: []util.PodRoute{
+					{
+						Dest:    ovntest.MustParseIPNet("100.64.0.0/16"),
+						NextHop: ovntest.MustParseIP("192.168.0.1").To4(),
+					},
+				},
+			},
+			wantReleasedIPsOnRollback: ovntest.MustParseIPNets("192.168.0.3/24"),
+		},
 		{
 			// on networks with IPAM, expect error on an invalid IP request
 			name: "expect error, invalid requested IP, no IPAM",
@@ -514,6 +644,36 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 					t.Fatalf("failed to create NetInfo: %v", err)
 				}
 			}
+			if len(tt.ipPools) > 0 {
+				nadName = util.GetNADName(network.Namespace, network.Name)
+				netInfo, err = util.NewNetInfo(&ovncnitypes.NetConf{
+					Topology: types.Layer2Topology,
+					NetConf: cnitypes.NetConf{
+						Name: network.Name,
+					},
+					NADName: nadName,
+					Subnets: "192.168.0.0/24",
+					IPPools: tt.ipPools,
+				})
+				if err != nil {
+					t.Fatalf("failed to create NetInfo: %v", err)
+				}
+			}
+
+			if tt.vlanTrunk != "" {
+				nadName = util.GetNADName(network.Namespace, network.Name)
+				netInfo, err = util.NewNetInfo(&ovncnitypes.NetConf{
+					Topology: types.LocalnetTopology,
+					NetConf: cnitypes.NetConf{
+						Name: network.Name,
+					},
+					NADName:   nadName,
+					VLANTrunk: tt.vlanTrunk,
+				})
+				if err != nil {
+					t.Fatalf("failed to create NetInfo: %v", err)
+				}
+			}
 
 			config.OVNKubernetesFeature.EnableInterconnect = tt.idAllocation
 
@@ -542,10 +702,13 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 				netInfo,
 				pod,
 				network,
+				tt.args.claimedIPs,
 				tt.args.reallocate,
 			)
 
 			if tt.args.ipAllocator != nil {
+				pools := tt.args.ipAllocator.(*ipAllocatorStub).pools
+				g.Expect(pools).To(gomega.Equal(tt.wantIPPools), "Allocation from IP pools behaved unexpectedly")
 				releasedIPs := tt.args.ipAllocator.(*ipAllocatorStub).releasedIPs
 				g.Expect(releasedIPs).To(gomega.Equal(tt.wantReleasedIPs), "Release IP on error behaved unexpectedly")
 				tt.args.ipAllocator.(*ipAllocatorStub).releasedIPs = nil
@@ -592,3 +755,118 @@ func Test_allocatePodAnnotationWithRollback(t *testing.T) {
 		})
 	}
 }
+
+func Test_requestVirtualMachineAddresses(t *testing.T) {
+	vmMAC, err := net.ParseMAC("0a:58:c0:a8:00:05")
+	if err != nil {
+		t.Fatalf("failed to parse mac")
+	}
+	vmIPs := ovntest.MustParseIPNets("192.168.0.5/24")
+	claimedIPs := ovntest.MustParseIPNets("192.168.0.6/24")
+
+	tests := []struct {
+		name           string
+		topology       string
+		liveMigratable bool
+		podAnnotated   bool
+		vmPodCompleted bool
+		wantMACRequest string
+		wantClaimedIPs []*net.IPNet
+	}{
+		{
+			name:           "expect the MAC and IPs of the VM for a target pod",
+			topology:       types.Layer2Topology,
+			liveMigratable: true,
+			wantMACRequest: vmMAC.String(),
+			wantClaimedIPs: vmIPs,
+		},
+		{
+			name:           "expect no request for an annotated pod",
+			topology:       types.Layer2Topology,
+			liveMigratable: true,
+			podAnnotated:   true,
+			wantClaimedIPs: claimedIPs,
+		},
+		{
+			name:           "expect no request when the other pod of the VM completed",
+			topology:       types.Layer2Topology,
+			liveMigratable: true,
+			vmPodCompleted: true,
+			wantClaimedIPs: claimedIPs,
+		},
+		{
+			name:           "expect no request for a pod not live migratable",
+			topology:       types.Layer2Topology,
+			wantClaimedIPs: claimedIPs,
+		},
+		{
+			name:           "expect no request on a layer3 network",
+			topology:       types.Layer3Topology,
+			liveMigratable: true,
+			wantClaimedIPs: claimedIPs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			subnets := "192.168.0.0/24"
+			if tt.topology == types.Layer3Topology {
+				subnets = "192.168.0.0/16/24"
+			}
+			nadName := util.GetNADName("namespace", "network")
+			netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+				Topology: tt.topology,
+				NetConf: cnitypes.NetConf{
+					Name: "network",
+				},
+				NADName: nadName,
+				Subnets: subnets,
+			})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			network := &nadapi.NetworkSelectionElement{
+				Name:      "network",
+				Namespace: "namespace",
+			}
+
+			newVMPod := func(name string) *v1.Pod {
+				pod := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   "namespace",
+						UID:         ktypes.UID(name),
+						Labels:      map[string]string{kubevirtv1.VirtualMachineNameLabel: "vm"},
+						Annotations: map[string]string{},
+					},
+				}
+				if tt.liveMigratable {
+					pod.Annotations[kubevirtv1.AllowPodBridgeNetworkLiveMigrationAnnotation] = ""
+				}
+				return pod
+			}
+			sourcePod := newVMPod("source")
+			sourcePod.Annotations, err = util.MarshalPodAnnotation(sourcePod.Annotations,
+				&util.PodAnnotation{MAC: vmMAC, IPs: vmIPs}, nadName)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			if tt.vmPodCompleted {
+				sourcePod.Status.Phase = v1.PodSucceeded
+			}
+			targetPod := newVMPod("target")
+			if tt.podAnnotated {
+				targetPod.Annotations, err = util.MarshalPodAnnotation(targetPod.Annotations,
+					&util.PodAnnotation{MAC: vmMAC, IPs: claimedIPs}, nadName)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			g.Expect(indexer.Add(sourcePod)).To(gomega.Succeed())
+			g.Expect(indexer.Add(targetPod)).To(gomega.Succeed())
+
+			request, ips, err := requestVirtualMachineAddresses(listers.NewPodLister(indexer), netInfo, targetPod, network, claimedIPs)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(request.MacRequest).To(gomega.Equal(tt.wantMACRequest))
+			g.Expect(ips).To(gomega.Equal(tt.wantClaimedIPs))
+			g.Expect(network.MacRequest).To(gomega.BeEmpty(), "Did not expect the network selection element to be modified")
+		})
+	}
+}
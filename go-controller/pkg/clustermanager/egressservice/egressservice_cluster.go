@@ -7,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	egressserviceapi "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1"
 	egressservicelisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/listers/egressservice/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
@@ -512,7 +511,11 @@ func (c *Controller) syncEgressService(key string) error {
 	// set host=ALL and stop processing or not.
 	if es.Spec.SourceIPBy == egressserviceapi.SourceIPNetwork {
 		hostToSet := noSNATHost
-		if config.Gateway.Mode == config.GatewayModeShared {
+		sharedGatewayMode, err := c.backendsUseSharedGatewayMode(svc)
+		if err != nil {
+			return err
+		}
+		if sharedGatewayMode {
 			klog.Errorf("Using SourceIPBy=Network is not supported on Shared gateway mode. EgressService: %s/%s", namespace, name)
 			hostToSet = noHost
 		}
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	egressserviceapi "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/healthcheck"
 	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
@@ -111,6 +113,54 @@ func (c *Controller) onNodeUpdate(oldObj, newObj interface{}) {
 		oldNodeReady != newNodeReady {
 		c.nodesQueue.Add(key)
 	}
+
+	// The egress services with sourceIPBy=Network depend on the gateway mode of their backend nodes
+	if util.NodeGatewayModeChanged(oldNode, newNode) {
+		c.queueNetworkSourceIPEgressServices()
+	}
+}
+
+// queueNetworkSourceIPEgressServices queues all the egress services with sourceIPBy=Network
+func (c *Controller) queueNetworkSourceIPEgressServices() {
+	egressServices, err := c.egressServiceLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't list egress services: %v", err))
+		return
+	}
+	for _, es := range egressServices {
+		if es.Spec.SourceIPBy != egressserviceapi.SourceIPNetwork {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(es)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("couldn't get key for object %+v: %v", es, err))
+			continue
+		}
+		c.egressServiceQueue.Add(key)
+	}
+}
+
+// backendsUseSharedGatewayMode returns whether any backend node of the service
+// is in shared gateway mode, or whether the configured gateway mode is shared
+// if the service has no backends
+func (c *Controller) backendsUseSharedGatewayMode(svc *corev1.Service) (bool, error) {
+	epsNodes, err := c.backendNodesFor(svc)
+	if err != nil {
+		return false, err
+	}
+	if len(epsNodes) == 0 {
+		return config.Gateway.Mode == config.GatewayModeShared, nil
+	}
+	for _, nodeName := range epsNodes {
+		node, err := c.watchFactory.GetNode(nodeName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		if util.GetNodeGatewayMode(node) == config.GatewayModeShared {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (c *Controller) onNodeDelete(obj interface{}) {
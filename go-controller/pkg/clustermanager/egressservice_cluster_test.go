@@ -1365,6 +1365,80 @@ var _ = ginkgo.Describe("Cluster manager Egress Service operations", func() {
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 		})
 
+		ginkgo.It("should follow the gateway mode of the backend nodes for sourceIPBy=Network", func() {
+			app.Action = func(ctx *cli.Context) error {
+				config.Gateway.Mode = config.GatewayModeLocal
+				namespaceT := *newNamespace("testns")
+				node1 := nodeFor(node1Name, node1IPv4, node1IPv6, node1IPv4Subnet, node1IPv6Subnet)
+				setGatewayMode := func(node *v1.Node, mode config.GatewayMode, resourceVersion string) {
+					node.Annotations["k8s.ovn.org/node-chassis-id"] = "chassis-" + node.Name
+					node.Annotations["k8s.ovn.org/l3-gateway-config"] = fmt.Sprintf(`{"default":{"mode":"%s","mac-address":"7e:57:f8:f0:3c:49", "ip-address":"169.254.33.2/24", "next-hop":"169.254.33.1"}}`, mode)
+					node.ResourceVersion = resourceVersion
+				}
+				setGatewayMode(node1, config.GatewayModeLocal, "1")
+
+				esvc1 := egressserviceapi.EgressService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc1",
+						Namespace: "testns",
+					},
+					Spec: egressserviceapi.EgressServiceSpec{
+						SourceIPBy: egressserviceapi.SourceIPNetwork,
+					},
+				}
+				svc1 := lbSvcFor("testns", "svc1")
+				svc1EpSlice := discovery.EndpointSlice{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "svc1-epslice",
+						Namespace: "testns",
+						Labels: map[string]string{
+							discovery.LabelServiceName: "svc1",
+						},
+					},
+					Endpoints: []discovery.Endpoint{
+						{
+							Addresses: []string{"10.128.1.5"},
+							NodeName:  &node1.Name,
+						},
+					},
+				}
+
+				objs := []runtime.Object{
+					&v1.NamespaceList{Items: []v1.Namespace{namespaceT}},
+					&v1.NodeList{Items: []v1.Node{*node1}},
+					&v1.ServiceList{Items: []v1.Service{svc1}},
+					&discovery.EndpointSliceList{Items: []discovery.EndpointSlice{svc1EpSlice}},
+					&egressserviceapi.EgressServiceList{Items: []egressserviceapi.EgressService{esvc1}},
+				}
+				fakeCM.start(objs...)
+
+				hostOfService := func() (string, error) {
+					es, err := fakeCM.fakeClient.EgressServiceClient.K8sV1().EgressServices("testns").Get(context.TODO(), esvc1.Name, metav1.GetOptions{})
+					if err != nil {
+						return "", err
+					}
+					return es.Status.Host, nil
+				}
+				gomega.Eventually(hostOfService).Should(gomega.Equal("ALL"))
+
+				ginkgo.By("switching the backend node to shared gateway mode the service's status will be deleted")
+				setGatewayMode(node1, config.GatewayModeShared, "2")
+				_, err := fakeCM.fakeClient.KubeClient.CoreV1().Nodes().Update(context.TODO(), node1, metav1.UpdateOptions{})
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Eventually(hostOfService).Should(gomega.BeEmpty())
+
+				ginkgo.By("switching the backend node back to local gateway mode the service's host will be ALL")
+				setGatewayMode(node1, config.GatewayModeLocal, "3")
+				_, err = fakeCM.fakeClient.KubeClient.CoreV1().Nodes().Update(context.TODO(), node1, metav1.UpdateOptions{})
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Eventually(hostOfService).Should(gomega.Equal("ALL"))
+
+				return nil
+			}
+			err := app.Run([]string{app.Name})
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		})
+
 		ginkgo.It("should update labels and status on reachability failure", func() {
 			app.Action = func(ctx *cli.Context) error {
 				namespaceT := *newNamespace("testns")
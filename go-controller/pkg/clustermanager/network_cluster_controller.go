@@ -17,6 +17,8 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/clustermanager/node"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/clustermanager/pod"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+	ipamclaimlisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/listers/ipamclaim/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
 	objretry "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/retry"
@@ -30,7 +32,7 @@ import (
 // level to support the necessary configuration for the cluster networks.
 type networkClusterController struct {
 	watchFactory *factory.WatchFactory
-	kube         kube.Interface
+	kube         kube.InterfaceOVN
 	stopChan     chan struct{}
 	wg           *sync.WaitGroup
 
@@ -44,6 +46,9 @@ type networkClusterController struct {
 	podHandler *factory.Handler
 	retryPods  *objretry.RetryFramework
 
+	// IPAMClaim events handler
+	ipamClaimHandler cache.ResourceEventHandlerRegistration
+
 	podAllocator       *pod.PodAllocator
 	nodeAllocator      *node.NodeAllocator
 	networkIDAllocator idallocator.NamedAllocator
@@ -52,8 +57,11 @@ type networkClusterController struct {
 }
 
 func newNetworkClusterController(networkIDAllocator idallocator.NamedAllocator, netInfo util.NetInfo, ovnClient *util.OVNClusterManagerClientset, wf *factory.WatchFactory) *networkClusterController {
-	kube := &kube.Kube{
-		KClient: ovnClient.KubeClient,
+	kube := &kube.KubeOVN{
+		Kube: kube.Kube{
+			KClient: ovnClient.KubeClient,
+		},
+		IPAMClaimClient: ovnClient.IPAMClaimClient,
 	}
 
 	wg := &sync.WaitGroup{}
@@ -135,7 +143,12 @@ func (ncc *networkClusterController) init() error {
 	if ncc.hasPodAllocation() {
 		ncc.retryPods = ncc.newRetryFramework(factory.PodType, true)
 
-		ncc.podAllocator = pod.NewPodAllocator(ncc.NetInfo, ncc.watchFactory.PodCoreInformer().Lister(), ncc.kube)
+		var ipamClaimsLister ipamclaimlisters.IPAMClaimLister
+		if util.IsPersistentIPsSupportEnabled() && ncc.AllowsPersistentIPs() {
+			ipamClaimsLister = ncc.watchFactory.IPAMClaimInformer().Lister()
+		}
+
+		ncc.podAllocator = pod.NewPodAllocator(ncc.NetInfo, ncc.watchFactory.PodCoreInformer().Lister(), ipamClaimsLister, ncc.kube)
 		err := ncc.podAllocator.Init()
 		if err != nil {
 			return fmt.Errorf("failed to initialize pod ip allocator: %w", err)
@@ -169,11 +182,45 @@ func (ncc *networkClusterController) Start(ctx context.Context) error {
 			return fmt.Errorf("unable to watch pods: %w", err)
 		}
 		ncc.podHandler = podHandler
+
+		if util.IsPersistentIPsSupportEnabled() && ncc.AllowsPersistentIPs() {
+			ipamClaimHandler, err := ncc.watchFactory.IPAMClaimInformer().Informer().AddEventHandler(
+				cache.ResourceEventHandlerFuncs{
+					DeleteFunc: ncc.deleteIPAMClaim,
+				})
+			if err != nil {
+				return fmt.Errorf("unable to watch IPAMClaims: %w", err)
+			}
+			ncc.ipamClaimHandler = ipamClaimHandler
+		}
 	}
 
 	return nil
 }
 
+// deleteIPAMClaim releases the IPs held for a deleted IPAMClaim
+func (ncc *networkClusterController) deleteIPAMClaim(obj interface{}) {
+	claim, ok := obj.(*ipamclaimv1.IPAMClaim)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Could not get object from tombstone %#v", obj)
+			return
+		}
+		claim, ok = tombstone.Obj.(*ipamclaimv1.IPAMClaim)
+		if !ok {
+			klog.Errorf("Tombstone contained object that is not an IPAMClaim %#v", tombstone.Obj)
+			return
+		}
+	}
+	if !ncc.HasNAD(claim.Spec.Network) {
+		return
+	}
+	if err := ncc.podAllocator.DeleteIPAMClaim(claim); err != nil {
+		klog.Errorf("Failed to handle the deletion of IPAMClaim %s/%s: %v", claim.Namespace, claim.Name, err)
+	}
+}
+
 func (ncc *networkClusterController) Stop() {
 	close(ncc.stopChan)
 	ncc.wg.Wait()
@@ -185,6 +232,12 @@ func (ncc *networkClusterController) Stop() {
 	if ncc.podHandler != nil {
 		ncc.watchFactory.RemovePodHandler(ncc.podHandler)
 	}
+
+	if ncc.ipamClaimHandler != nil {
+		if err := ncc.watchFactory.IPAMClaimInformer().Informer().RemoveEventHandler(ncc.ipamClaimHandler); err != nil {
+			klog.Errorf("Failed to remove the IPAMClaim handler of network %s: %v", ncc.GetNetworkName(), err)
+		}
+	}
 }
 
 func (ncc *networkClusterController) newRetryFramework(objectType reflect.Type, hasUpdateFunc bool) *objretry.RetryFramework {
@@ -215,6 +268,15 @@ func (ncc *networkClusterController) Cleanup(netName string) error {
 		ncc.networkIDAllocator.ReleaseID()
 	}
 
+	if util.IsPersistentIPsSupportEnabled() {
+		err := pod.DeleteStaleIPAMClaims(ncc.watchFactory.IPAMClaimInformer().Lister(), ncc.kube, func(network string) bool {
+			return network == netName
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
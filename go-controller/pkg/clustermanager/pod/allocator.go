@@ -15,7 +15,10 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/id"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/ip/subnet"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/pod"
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+	ipamclaimlisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/listers/ipamclaim/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 )
@@ -35,14 +38,27 @@ type PodAllocator struct {
 	// An utility to allocate the PodAnnotation to pods
 	podAnnotationAllocator *pod.PodAnnotationAllocator
 
+	podLister        listers.PodLister
+	ipamClaimsLister ipamclaimlisters.IPAMClaimLister
+	kube             kube.InterfaceOVN
+
+	// IPs of IPAMClaims that are kept allocated while no pod of their
+	// workload is running, keyed by claim namespace/name
+	heldClaims map[string][]*net.IPNet
+	// statuses of the IPAMClaims last created or updated, keyed by claim
+	// namespace/name, as the IPAMClaims lister may lag behind
+	claimStatuses   map[string]ipamclaimv1.IPAMClaimStatus
+	heldClaimsMutex sync.Mutex
+
 	// track pods that have been released but not deleted yet so that we don't
 	// release more than once
 	releasedPods      map[string]sets.Set[string]
 	releasedPodsMutex sync.Mutex
 }
 
-// NewPodAllocator builds a new PodAllocator
-func NewPodAllocator(netInfo util.NetInfo, podLister listers.PodLister, kube kube.Interface) *PodAllocator {
+// NewPodAllocator builds a new PodAllocator. The IPAMClaims lister is only
+// required if the network persists the IPs of its workloads.
+func NewPodAllocator(netInfo util.NetInfo, podLister listers.PodLister, ipamClaimsLister ipamclaimlisters.IPAMClaimLister, kube kube.InterfaceOVN) *PodAllocator {
 	podAnnotationAllocator := pod.NewPodAnnotationAllocator(
 		netInfo,
 		podLister,
@@ -54,6 +70,11 @@ func NewPodAllocator(netInfo util.NetInfo, podLister listers.PodLister, kube kub
 		releasedPods:           map[string]sets.Set[string]{},
 		releasedPodsMutex:      sync.Mutex{},
 		podAnnotationAllocator: podAnnotationAllocator,
+		podLister:              podLister,
+		ipamClaimsLister:       ipamClaimsLister,
+		kube:                   kube,
+		heldClaims:             map[string][]*net.IPNet{},
+		claimStatuses:          map[string]ipamclaimv1.IPAMClaimStatus{},
 	}
 
 	// this network might not have IPAM, we will just allocate MAC addresses
@@ -86,7 +107,7 @@ func (a *PodAllocator) Init() error {
 			ipNets = append(ipNets, subnet.CIDR)
 		}
 
-		return a.ipAllocator.AddOrUpdateSubnet(a.netInfo.GetNetworkName(), ipNets, a.netInfo.ExcludeSubnets()...)
+		return a.ipAllocator.AddOrUpdateSubnetWithIPPools(a.netInfo.GetNetworkName(), ipNets, a.netInfo.IPPools(), a.netInfo.ExcludeSubnets()...)
 	}
 
 	return nil
@@ -106,6 +127,14 @@ func (a *PodAllocator) Sync(objs []interface{}) error {
 	// completed pods that might be being used by other pods
 	releaseFromAllocator := false
 
+	// reserve the IPs persisted for workloads first so that they are not
+	// handed out to other pods
+	if a.hasPersistentIPs() {
+		if err := a.syncIPAMClaims(); err != nil {
+			return err
+		}
+	}
+
 	for _, obj := range objs {
 		pod, ok := obj.(*corev1.Pod)
 		if !ok {
@@ -210,6 +239,18 @@ func (a *PodAllocator) releasePodOnNAD(pod *corev1.Pod, nad string, podDeleted,
 		klog.V(5).Infof("Released ID %d", podAnnotation.TunnelID)
 	}
 
+	if doReleaseIPs && a.hasPersistentIPs() && a.holdIPAMClaimIPs(pod, nad, podAnnotation.IPs) {
+		doReleaseIPs = false
+	}
+
+	if doReleaseIPs {
+		inUse, err := a.isVirtualMachineIPsInUse(pod, nad, podAnnotation.IPs)
+		if err != nil {
+			return err
+		}
+		doReleaseIPs = !inUse
+	}
+
 	if doReleaseIPs {
 		err := a.ipAllocator.ReleaseIPs(a.netInfo.GetNetworkName(), podAnnotation.IPs)
 		if err != nil {
@@ -233,6 +274,32 @@ func (a *PodAllocator) releasePodOnNAD(pod *corev1.Pod, nad string, podDeleted,
 	return nil
 }
 
+// isVirtualMachineIPsInUse returns true if the IPs of a live migratable pod
+// are still in use by another pod of its VM, which is the case for the source
+// and target pods of a live migration
+func (a *PodAllocator) isVirtualMachineIPsInUse(pod *corev1.Pod, nad string, ips []*net.IPNet) (bool, error) {
+	if len(ips) == 0 || !kubevirt.IsPodAllowedForMigration(pod, a.netInfo) {
+		return false, nil
+	}
+	vmPodAnnotation, err := kubevirt.FindVMPodAnnotation(a.podLister, pod, nad)
+	if err != nil {
+		return false, err
+	}
+	if vmPodAnnotation == nil {
+		return false, nil
+	}
+	for _, ip := range ips {
+		for _, vmIP := range vmPodAnnotation.IPs {
+			if ip.IP.Equal(vmIP.IP) {
+				klog.V(5).Infof("Not releasing IPs %v of pod %s/%s on nad %s in use by another pod of its VM",
+					util.StringSlice(ips), pod.Namespace, pod.Name, nad)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (a *PodAllocator) allocatePodOnNAD(pod *corev1.Pod, nad string, network *nettypes.NetworkSelectionElement) error {
 	var ipAllocator subnet.NamedAllocator
 	if util.DoesNetworkRequireIPAM(a.netInfo) {
@@ -245,6 +312,27 @@ func (a *PodAllocator) allocatePodOnNAD(pod *corev1.Pod, nad string, network *ne
 		idAllocator = a.idAllocator.ForName(name)
 	}
 
+	// honor the IPs persisted for the workload of the pod, if any
+	var workload string
+	var claim *ipamclaimv1.IPAMClaim
+	var claimedIPs []*net.IPNet
+	if a.hasPersistentIPs() {
+		workload = getWorkloadName(pod)
+	}
+	if workload != "" {
+		var err error
+		claim, err = a.getIPAMClaim(pod, workload, nad)
+		if err != nil {
+			return err
+		}
+		if claim != nil {
+			claimedIPs, err = a.getIPAMClaimIPs(pod, workload, nad, claim)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// don't reallocate to new IPs if currently annotated IPs fail to alloccate
 	reallocate := false
 
@@ -253,6 +341,7 @@ func (a *PodAllocator) allocatePodOnNAD(pod *corev1.Pod, nad string, network *ne
 		idAllocator,
 		pod,
 		network,
+		claimedIPs,
 		reallocate,
 	)
 
@@ -260,6 +349,16 @@ func (a *PodAllocator) allocatePodOnNAD(pod *corev1.Pod, nad string, network *ne
 		return err
 	}
 
+	if workload != "" {
+		err = a.ensureIPAMClaim(pod, workload, nad, claim, podAnnotation.IPs)
+		if err != nil {
+			return err
+		}
+		if claim != nil {
+			a.unholdIPAMClaim(ipamClaimKey(claim.Namespace, claim.Name))
+		}
+	}
+
 	if updatedPod != nil {
 		klog.V(5).Infof("Allocated IP addresses %v, mac address %s, gateways %v, routes %s and tunnel id %d for pod %s/%s on nad %s",
 			util.StringSlice(podAnnotation.IPs),
@@ -75,6 +75,10 @@ func (a *ipAllocatorStub) AddOrUpdateSubnet(name string, subnets []*net.IPNet, e
 	panic("not implemented") // TODO: Implement
 }
 
+func (a *ipAllocatorStub) AddOrUpdateSubnetWithIPPools(name string, subnets []*net.IPNet, ipPools []util.IPPool, excludeSubnets ...*net.IPNet) error {
+	panic("not implemented") // TODO: Implement
+}
+
 func (a ipAllocatorStub) DeleteSubnet(name string) {
 	panic("not implemented") // TODO: Implement
 }
@@ -95,6 +99,10 @@ func (a *ipAllocatorStub) AllocateNextIPs(name string) ([]*net.IPNet, error) {
 	panic("not implemented") // TODO: Implement
 }
 
+func (a *ipAllocatorStub) AllocateNextIPsFromPools(name string, pools []string) ([]*net.IPNet, error) {
+	panic("not implemented") // TODO: Implement
+}
+
 func (a *ipAllocatorStub) ReleaseIPs(name string, ips []*net.IPNet) error {
 	a.released = true
 	return nil
@@ -0,0 +1,378 @@
+package pod
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+	ipamclaimlisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/listers/ipamclaim/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+// hasPersistentIPs returns true if the IPs allocated to the workloads on this
+// network are persisted with IPAMClaims
+func (a *PodAllocator) hasPersistentIPs() bool {
+	return util.IsPersistentIPsSupportEnabled() &&
+		a.netInfo.AllowsPersistentIPs() &&
+		util.DoesNetworkRequireIPAM(a.netInfo) &&
+		a.ipamClaimsLister != nil
+}
+
+// getWorkloadName returns the name of the workload whose IPs are persisted
+// across the pods that run it: the KubeVirt VirtualMachine of a virt-launcher
+// pod or the StatefulSet pod name, which is stable across restarts. An empty
+// name is returned for pods that don't run such a workload.
+func getWorkloadName(pod *corev1.Pod) string {
+	if vmName, ok := pod.Labels[kubevirtv1.VirtualMachineNameLabel]; ok {
+		return vmName
+	}
+	if ref := metav1.GetControllerOf(pod); ref != nil && ref.Kind == "StatefulSet" {
+		return pod.Name
+	}
+	return ""
+}
+
+// ipamClaimName returns the name of the IPAMClaim of a workload on a NAD. The
+// claim is created in the namespace of the workload, which is not necessarily
+// the namespace of the NAD.
+func ipamClaimName(workload, nad string) string {
+	nadNamespace, nadName, err := cache.SplitMetaNamespaceKey(nad)
+	if err != nil || nadNamespace == "" {
+		return fmt.Sprintf("%s.%s", workload, nad)
+	}
+	return fmt.Sprintf("%s.%s.%s", workload, nadNamespace, nadName)
+}
+
+func ipamClaimKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// getIPAMClaim returns the IPAMClaim of the workload on the NAD, or nil if it
+// does not exist yet. An error is returned if the claim found conflicts with
+// the pod.
+func (a *PodAllocator) getIPAMClaim(pod *corev1.Pod, workload, nad string) (*ipamclaimv1.IPAMClaim, error) {
+	name := ipamClaimName(workload, nad)
+	claim, err := a.ipamClaimsLister.IPAMClaims(pod.Namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IPAMClaim %s/%s: %w", pod.Namespace, name, err)
+	}
+
+	if claim.Spec.Network != nad || claim.Spec.Workload != workload {
+		return nil, fmt.Errorf("IPAMClaim %s/%s for network %s and workload %s conflicts with pod %s/%s on network %s and workload %s",
+			claim.Namespace, claim.Name, claim.Spec.Network, claim.Spec.Workload, pod.Namespace, pod.Name, nad, workload)
+	}
+
+	// the IPs can only be handed over to a different pod of the workload once
+	// the previous one is gone, unless both pods run the same workload at the
+	// same time as a KubeVirt VM being live migrated does
+	if claim.Status.OwnerPod == "" || claim.Status.OwnerPod == pod.Name {
+		return claim, nil
+	}
+	ownerPod, err := a.podLister.Pods(pod.Namespace).Get(claim.Status.OwnerPod)
+	if apierrors.IsNotFound(err) {
+		return claim, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner pod %s/%s of IPAMClaim %s/%s: %w",
+			pod.Namespace, claim.Status.OwnerPod, claim.Namespace, claim.Name, err)
+	}
+	if !util.PodCompleted(ownerPod) && getWorkloadName(ownerPod) != workload {
+		return nil, fmt.Errorf("IPAMClaim %s/%s is in use by pod %s/%s which does not run workload %s",
+			claim.Namespace, claim.Name, ownerPod.Namespace, ownerPod.Name, workload)
+	}
+
+	return claim, nil
+}
+
+// getIPAMClaimIPs returns the IPs of the IPAMClaim to allocate to a pod of its
+// workload. The IPs are only honored if the claim holds them: they are held for
+// the workload, allocated to the running pod of the workload owning the claim,
+// or they are free and reserved now. Otherwise they might have been handed out
+// to another pod and nil is returned for the pod to get new IPs.
+func (a *PodAllocator) getIPAMClaimIPs(pod *corev1.Pod, workload, nad string, claim *ipamclaimv1.IPAMClaim) ([]*net.IPNet, error) {
+	ips, err := util.ParseIPNets(claim.Status.IPs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the IPs of IPAMClaim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	if len(ips) == 0 {
+		return nil, nil
+	}
+	ipStrs := sets.New(claim.Status.IPs...)
+
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+
+	key := ipamClaimKey(claim.Namespace, claim.Name)
+	if held, ok := a.heldClaims[key]; ok && ipStrs.Equal(sets.New(util.StringSlice(held)...)) {
+		return ips, nil
+	}
+
+	if claim.Status.OwnerPod != "" && claim.Status.OwnerPod != pod.Name {
+		ownerPod, err := a.podLister.Pods(pod.Namespace).Get(claim.Status.OwnerPod)
+		if err == nil && !util.PodCompleted(ownerPod) && getWorkloadName(ownerPod) == workload {
+			ownerAnnotation, err := util.UnmarshalPodAnnotation(ownerPod.Annotations, nad)
+			if err == nil && ipStrs.Equal(sets.New(util.StringSlice(ownerAnnotation.IPs)...)) {
+				return ips, nil
+			}
+		}
+	}
+
+	err = a.ipAllocator.AllocateIPs(a.netInfo.GetNetworkName(), ips)
+	if err != nil {
+		klog.Warningf("Failed to reserve IPs %v of IPAMClaim %s/%s for pod %s/%s, allocating new IPs: %v",
+			claim.Status.IPs, claim.Namespace, claim.Name, pod.Namespace, pod.Name, err)
+		return nil, nil
+	}
+	a.heldClaims[key] = ips
+	klog.V(5).Infof("Reserved IPs %v of IPAMClaim %s/%s", claim.Status.IPs, claim.Namespace, claim.Name)
+	return ips, nil
+}
+
+// ensureIPAMClaim creates or updates the IPAMClaim of the workload on the NAD
+// to persist the IPs allocated to the pod. Claimed IPs that were held for the
+// workload but not allocated to the pod are released.
+func (a *PodAllocator) ensureIPAMClaim(pod *corev1.Pod, workload, nad string, claim *ipamclaimv1.IPAMClaim, ips []*net.IPNet) error {
+	ipStrs := util.StringSlice(ips)
+
+	if claim == nil {
+		claim = &ipamclaimv1.IPAMClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ipamClaimName(workload, nad),
+				Namespace: pod.Namespace,
+				Labels: map[string]string{
+					types.IPAMClaimNetworkLabel: a.netInfo.GetNetworkName(),
+				},
+			},
+			Spec: ipamclaimv1.IPAMClaimSpec{
+				Network:  nad,
+				Workload: workload,
+			},
+		}
+		// the claim of a StatefulSet pod goes away with the StatefulSet;
+		// the claims of KubeVirt VMs are not owned by their VMIs since these
+		// are re-created every time the VM restarts
+		if ref := metav1.GetControllerOf(pod); ref != nil && ref.Kind == "StatefulSet" {
+			claim.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: ref.APIVersion,
+					Kind:       ref.Kind,
+					Name:       ref.Name,
+					UID:        ref.UID,
+				},
+			}
+		}
+		var err error
+		claim, err = a.kube.CreateIPAMClaim(claim)
+		if err != nil {
+			return fmt.Errorf("failed to create IPAMClaim %s/%s: %w", pod.Namespace, ipamClaimName(workload, nad), err)
+		}
+		a.setIPAMClaimStatus(claim)
+		klog.V(5).Infof("Created IPAMClaim %s/%s for pod %s/%s on nad %s", claim.Namespace, claim.Name, pod.Namespace, pod.Name, nad)
+	}
+
+	if claim.Status.OwnerPod == pod.Name && sets.New(claim.Status.IPs...).Equal(sets.New(ipStrs...)) {
+		return nil
+	}
+
+	oldIPs, err := util.ParseIPNets(claim.Status.IPs)
+	if err != nil {
+		klog.Warningf("Failed to parse the IPs of IPAMClaim %s/%s: %v", claim.Namespace, claim.Name, err)
+	}
+
+	claim = claim.DeepCopy()
+	claim.Status.IPs = ipStrs
+	claim.Status.OwnerPod = pod.Name
+	_, err = a.kube.UpdateIPAMClaimStatus(claim)
+	if err != nil {
+		return fmt.Errorf("failed to update the status of IPAMClaim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	a.setIPAMClaimStatus(claim)
+	klog.V(5).Infof("Updated IPAMClaim %s/%s with IPs %v of pod %s/%s", claim.Namespace, claim.Name, ipStrs, pod.Namespace, pod.Name)
+
+	// release the previously claimed IPs that the pod did not get
+	var stale []*net.IPNet
+	for _, oldIP := range oldIPs {
+		if !sets.New(ipStrs...).Has(oldIP.String()) {
+			stale = append(stale, oldIP)
+		}
+	}
+	if len(stale) > 0 && a.isIPAMClaimHeld(ipamClaimKey(claim.Namespace, claim.Name)) {
+		err = a.ipAllocator.ReleaseIPs(a.netInfo.GetNetworkName(), stale)
+		if err != nil {
+			return fmt.Errorf("failed to release stale IPs %v of IPAMClaim %s/%s: %w",
+				util.StringSlice(stale), claim.Namespace, claim.Name, err)
+		}
+		klog.V(5).Infof("Released stale IPs %v of IPAMClaim %s/%s", util.StringSlice(stale), claim.Namespace, claim.Name)
+	}
+
+	return nil
+}
+
+// setIPAMClaimStatus records the status of an IPAMClaim just created or
+// updated
+func (a *PodAllocator) setIPAMClaimStatus(claim *ipamclaimv1.IPAMClaim) {
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+	a.claimStatuses[ipamClaimKey(claim.Namespace, claim.Name)] = *claim.Status.DeepCopy()
+}
+
+// holdIPAMClaimIPs keeps the IPs of a released pod allocated if they are
+// persisted by an IPAMClaim. Returns true if the IPs must not be released,
+// either because they are being held for the workload or because they have
+// already been handed over to another pod of the workload.
+func (a *PodAllocator) holdIPAMClaimIPs(pod *corev1.Pod, nad string, ips []*net.IPNet) bool {
+	workload := getWorkloadName(pod)
+	if workload == "" || len(ips) == 0 {
+		return false
+	}
+
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+
+	// the claim is checked while holding the lock so that a concurrent
+	// deletion of the claim either sees the IPs held or we see the claim gone.
+	// The status we last wrote takes precedence over the lister, which may not
+	// know yet about a claim just created or updated.
+	name := ipamClaimName(workload, nad)
+	key := ipamClaimKey(pod.Namespace, name)
+	status, ok := a.claimStatuses[key]
+	if !ok {
+		claim, err := a.ipamClaimsLister.IPAMClaims(pod.Namespace).Get(name)
+		if err != nil || claim.Spec.Network != nad {
+			return false
+		}
+		status = claim.Status
+	}
+	if !sets.New(status.IPs...).Equal(sets.New(util.StringSlice(ips)...)) {
+		return false
+	}
+
+	if status.OwnerPod != "" && status.OwnerPod != pod.Name {
+		ownerPod, err := a.podLister.Pods(pod.Namespace).Get(status.OwnerPod)
+		if err == nil && !util.PodCompleted(ownerPod) {
+			return true
+		}
+	}
+
+	a.heldClaims[key] = util.CopyIPNets(ips)
+	klog.V(5).Infof("Holding IPs %v of IPAMClaim %s", util.StringSlice(ips), key)
+	return true
+}
+
+// unholdIPAMClaim stops holding the IPs of an IPAMClaim that are now
+// allocated to a pod
+func (a *PodAllocator) unholdIPAMClaim(key string) {
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+	delete(a.heldClaims, key)
+}
+
+func (a *PodAllocator) isIPAMClaimHeld(key string) bool {
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+	_, held := a.heldClaims[key]
+	return held
+}
+
+// DeleteIPAMClaim releases the IPs held for a deleted IPAMClaim
+func (a *PodAllocator) DeleteIPAMClaim(claim *ipamclaimv1.IPAMClaim) error {
+	if !a.hasPersistentIPs() {
+		return nil
+	}
+
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+
+	key := ipamClaimKey(claim.Namespace, claim.Name)
+	delete(a.claimStatuses, key)
+	ips, held := a.heldClaims[key]
+	if !held {
+		return nil
+	}
+	err := a.ipAllocator.ReleaseIPs(a.netInfo.GetNetworkName(), ips)
+	if err != nil {
+		return fmt.Errorf("failed to release IPs %v of deleted IPAMClaim %s: %w", util.StringSlice(ips), key, err)
+	}
+	delete(a.heldClaims, key)
+	klog.V(5).Infof("Released IPs %v of deleted IPAMClaim %s", util.StringSlice(ips), key)
+	return nil
+}
+
+// syncIPAMClaims reserves the IPs of the existing IPAMClaims of the network.
+// These are held until a pod of their workload shows up or the claims are
+// deleted.
+func (a *PodAllocator) syncIPAMClaims() error {
+	claims, err := a.ipamClaimsLister.List(labels.SelectorFromSet(labels.Set{
+		types.IPAMClaimNetworkLabel: a.netInfo.GetNetworkName(),
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to list IPAMClaims: %w", err)
+	}
+
+	a.heldClaimsMutex.Lock()
+	defer a.heldClaimsMutex.Unlock()
+
+	for _, claim := range claims {
+		if !a.netInfo.HasNAD(claim.Spec.Network) {
+			continue
+		}
+		ips, err := util.ParseIPNets(claim.Status.IPs)
+		if err != nil {
+			klog.Errorf("Failed to parse the IPs of IPAMClaim %s/%s: %v", claim.Namespace, claim.Name, err)
+			continue
+		}
+		if len(ips) == 0 {
+			continue
+		}
+		// IPs already allocated are claimed by another IPAMClaim, they are not
+		// held for this one
+		err = a.ipAllocator.AllocateIPs(a.netInfo.GetNetworkName(), ips)
+		if err != nil {
+			klog.Errorf("Failed to reserve IPs %v of IPAMClaim %s/%s: %v", claim.Status.IPs, claim.Namespace, claim.Name, err)
+			continue
+		}
+		a.heldClaims[ipamClaimKey(claim.Namespace, claim.Name)] = ips
+	}
+
+	return nil
+}
+
+// DeleteStaleIPAMClaims deletes the IPAMClaims of the networks considered
+// stale
+func DeleteStaleIPAMClaims(ipamClaimsLister ipamclaimlisters.IPAMClaimLister, kube kube.InterfaceOVN, isStale func(netName string) bool) error {
+	claims, err := ipamClaimsLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list IPAMClaims: %w", err)
+	}
+
+	var errs []error
+	for _, claim := range claims {
+		netName, ok := claim.Labels[types.IPAMClaimNetworkLabel]
+		if !ok || !isStale(netName) {
+			continue
+		}
+		err = kube.DeleteIPAMClaim(claim.Namespace, claim.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete IPAMClaim %s/%s: %w", claim.Namespace, claim.Name, err))
+			continue
+		}
+		klog.Infof("Deleted IPAMClaim %s/%s of stale network %s", claim.Namespace, claim.Name, netName)
+	}
+
+	return kerrors.NewAggregate(errs)
+}
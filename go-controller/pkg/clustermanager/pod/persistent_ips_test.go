@@ -0,0 +1,421 @@
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+	ipamclaimlisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/listers/ipamclaim/v1"
+	kubemocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube/mocks"
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+const (
+	testNamespace = "namespace"
+	testNAD       = "namespace/nad"
+)
+
+type persistentIPsPod struct {
+	name        string
+	statefulSet string
+	vm          string
+	completed   bool
+	ips         []string
+}
+
+func (p persistentIPsPod) getPod(t *testing.T) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        p.name,
+			UID:         apitypes.UID(p.name),
+			Namespace:   testNamespace,
+			Annotations: map[string]string{},
+			Labels:      map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node",
+		},
+	}
+	if p.statefulSet != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(
+				&metav1.ObjectMeta{Name: p.statefulSet, UID: apitypes.UID(p.statefulSet)},
+				metav1.SchemeGroupVersion.WithKind("StatefulSet"),
+			),
+		}
+	}
+	if p.vm != "" {
+		pod.Labels[kubevirtv1.VirtualMachineNameLabel] = p.vm
+	}
+	if p.completed {
+		pod.Status.Phase = corev1.PodSucceeded
+	}
+
+	bytes, err := json.Marshal([]*nadapi.NetworkSelectionElement{{Name: "nad"}})
+	if err != nil {
+		t.Fatalf("Invalid network selection")
+	}
+	pod.Annotations[nadapi.NetworkAttachmentAnnot] = string(bytes)
+
+	if len(p.ips) > 0 {
+		ips := ovntest.MustParseIPNets(p.ips...)
+		pod.Annotations, err = util.MarshalPodAnnotation(pod.Annotations, &util.PodAnnotation{
+			IPs: ips,
+			MAC: util.IPAddrToHWAddr(ips[0].IP),
+		}, testNAD)
+		if err != nil {
+			t.Fatalf("Invalid pod annotation: %v", err)
+		}
+	}
+
+	return pod
+}
+
+type persistentIPsClaim struct {
+	name     string
+	network  string
+	workload string
+	ips      []string
+	ownerPod string
+}
+
+func (c persistentIPsClaim) getClaim() *ipamclaimv1.IPAMClaim {
+	return &ipamclaimv1.IPAMClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				types.IPAMClaimNetworkLabel: "network",
+			},
+		},
+		Spec: ipamclaimv1.IPAMClaimSpec{
+			Network:  c.network,
+			Workload: c.workload,
+		},
+		Status: ipamclaimv1.IPAMClaimStatus{
+			IPs:      c.ips,
+			OwnerPod: c.ownerPod,
+		},
+	}
+}
+
+func TestPodAllocator_persistentIPs(t *testing.T) {
+	tests := []struct {
+		name string
+		// pods running before the test pod is reconciled
+		pods  []persistentIPsPod
+		claim *persistentIPsClaim
+		// the claim is only added once the running pods are synced
+		claimAfterSync bool
+		// test pod, reconciled as deleted if old is set
+		new *persistentIPsPod
+		old *persistentIPsPod
+		// the test pod is deleted once allocated
+		deleteNew bool
+		// expectations
+		expectError        bool
+		expectIPs          []string
+		expectClaim        *persistentIPsClaim
+		expectHeld         []string
+		expectIPsAvailable []string
+	}{
+		{
+			name:      "StatefulSet pod allocated, claim created",
+			new:       &persistentIPsPod{name: "sts-0", statefulSet: "sts"},
+			expectIPs: []string{"10.1.130.1/24"},
+			expectClaim: &persistentIPsClaim{
+				name:     "sts-0.namespace.nad",
+				network:  testNAD,
+				workload: "sts-0",
+				ips:      []string{"10.1.130.1/24"},
+				ownerPod: "sts-0",
+			},
+		},
+		{
+			name:      "pod without workload allocated, no claim created",
+			new:       &persistentIPsPod{name: "pod"},
+			expectIPs: []string{"10.1.130.1/24"},
+		},
+		{
+			name: "StatefulSet pod allocated, claimed IPs honored",
+			claim: &persistentIPsClaim{
+				name:     "sts-0.namespace.nad",
+				network:  testNAD,
+				workload: "sts-0",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "sts-0",
+			},
+			new:       &persistentIPsPod{name: "sts-0", statefulSet: "sts"},
+			expectIPs: []string{"10.1.130.10/24"},
+		},
+		{
+			name: "VM pod allocated, claimed IPs of previous VM pod honored",
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "virt-launcher-vm-1",
+			},
+			new:       &persistentIPsPod{name: "virt-launcher-vm-2", vm: "vm"},
+			expectIPs: []string{"10.1.130.10/24"},
+			expectClaim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "virt-launcher-vm-2",
+			},
+		},
+		{
+			name: "VM pod allocated, claim owned by a running pod of another workload",
+			pods: []persistentIPsPod{
+				{name: "other", ips: []string{"10.1.130.10/24"}},
+			},
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "other",
+			},
+			new:         &persistentIPsPod{name: "virt-launcher-vm-2", vm: "vm"},
+			expectError: true,
+		},
+		{
+			name: "VM pod allocated, claim for another network",
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  "other/nad",
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+			},
+			new:         &persistentIPsPod{name: "virt-launcher-vm-2", vm: "vm"},
+			expectError: true,
+		},
+		{
+			name: "StatefulSet pod deleted, claimed IPs held",
+			claim: &persistentIPsClaim{
+				name:     "sts-0.namespace.nad",
+				network:  testNAD,
+				workload: "sts-0",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "sts-0",
+			},
+			old:        &persistentIPsPod{name: "sts-0", statefulSet: "sts", ips: []string{"10.1.130.10/24"}},
+			expectHeld: []string{"10.1.130.10/24"},
+		},
+		{
+			name: "VM pod completed, claimed IPs already handed over to the next VM pod",
+			pods: []persistentIPsPod{
+				{name: "virt-launcher-vm-2", vm: "vm", ips: []string{"10.1.130.10/24"}},
+			},
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+				ownerPod: "virt-launcher-vm-2",
+			},
+			new: &persistentIPsPod{name: "virt-launcher-vm-1", vm: "vm", completed: true, ips: []string{"10.1.130.10/24"}},
+		},
+		{
+			name: "VM pod allocated, claimed IPs allocated to another pod not honored",
+			pods: []persistentIPsPod{
+				{name: "other", ips: []string{"10.1.130.10/24"}},
+			},
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+			},
+			claimAfterSync: true,
+			new:            &persistentIPsPod{name: "virt-launcher-vm-2", vm: "vm"},
+			expectIPs:      []string{"10.1.130.1/24"},
+			expectClaim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.1/24"},
+				ownerPod: "virt-launcher-vm-2",
+			},
+		},
+		{
+			name: "VM pod allocated, free claimed IPs not held yet honored",
+			claim: &persistentIPsClaim{
+				name:     "vm.namespace.nad",
+				network:  testNAD,
+				workload: "vm",
+				ips:      []string{"10.1.130.10/24"},
+			},
+			claimAfterSync: true,
+			new:            &persistentIPsPod{name: "virt-launcher-vm-2", vm: "vm"},
+			expectIPs:      []string{"10.1.130.10/24"},
+		},
+		{
+			name:      "StatefulSet pod deleted before the claim it created is listed, claimed IPs held",
+			new:       &persistentIPsPod{name: "sts-0", statefulSet: "sts"},
+			deleteNew: true,
+			expectIPs: []string{"10.1.130.1/24"},
+			expectClaim: &persistentIPsClaim{
+				name:     "sts-0.namespace.nad",
+				network:  testNAD,
+				workload: "sts-0",
+				ips:      []string{"10.1.130.1/24"},
+				ownerPod: "sts-0",
+			},
+			expectHeld: []string{"10.1.130.1/24"},
+		},
+		{
+			name:               "pod without workload deleted, IPs released",
+			old:                &persistentIPsPod{name: "pod", ips: []string{"10.1.130.10/24"}},
+			expectIPsAvailable: []string{"10.1.130.10/24"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			config.OVNKubernetesFeature.EnableInterconnect = true
+			config.OVNKubernetesFeature.EnableMultiNetwork = true
+			config.OVNKubernetesFeature.EnablePersistentIPs = true
+			defer func() {
+				g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			}()
+
+			netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:            cnitypes.NetConf{Name: "network"},
+				Topology:           types.Layer2Topology,
+				Subnets:            "10.1.130.0/24",
+				AllowPersistentIPs: true,
+			})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			netInfo.AddNAD(testNAD)
+
+			podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			claimIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+			var updatedPod *corev1.Pod
+			var createdClaim, updatedClaim *ipamclaimv1.IPAMClaim
+			kubeMock := &kubemocks.InterfaceOVN{}
+			kubeMock.On("UpdatePodStatus", mock.AnythingOfType(fmt.Sprintf("%T", &corev1.Pod{}))).Run(
+				func(args mock.Arguments) {
+					updatedPod = args.Get(0).(*corev1.Pod)
+				},
+			).Return(nil)
+			kubeMock.On("CreateIPAMClaim", mock.AnythingOfType(fmt.Sprintf("%T", &ipamclaimv1.IPAMClaim{}))).Return(
+				func(claim *ipamclaimv1.IPAMClaim) *ipamclaimv1.IPAMClaim {
+					createdClaim = claim
+					return claim
+				},
+				nil,
+			)
+			kubeMock.On("UpdateIPAMClaimStatus", mock.AnythingOfType(fmt.Sprintf("%T", &ipamclaimv1.IPAMClaim{}))).Return(
+				func(claim *ipamclaimv1.IPAMClaim) *ipamclaimv1.IPAMClaim {
+					updatedClaim = claim
+					return claim
+				},
+				nil,
+			)
+
+			a := NewPodAllocator(netInfo, listers.NewPodLister(podIndexer), ipamclaimlisters.NewIPAMClaimLister(claimIndexer), kubeMock)
+			g.Expect(a.Init()).To(gomega.Succeed())
+
+			var syncPods []interface{}
+			for _, p := range tt.pods {
+				pod := p.getPod(t)
+				g.Expect(podIndexer.Add(pod)).To(gomega.Succeed())
+				syncPods = append(syncPods, pod)
+			}
+			if tt.claim != nil && !tt.claimAfterSync {
+				g.Expect(claimIndexer.Add(tt.claim.getClaim())).To(gomega.Succeed())
+			}
+			var old, new *corev1.Pod
+			if tt.old != nil {
+				old = tt.old.getPod(t)
+				g.Expect(podIndexer.Add(old)).To(gomega.Succeed())
+				syncPods = append(syncPods, old)
+			}
+			if tt.new != nil {
+				new = tt.new.getPod(t)
+				g.Expect(podIndexer.Add(new)).To(gomega.Succeed())
+			}
+			g.Expect(a.Sync(syncPods)).To(gomega.Succeed())
+			if old != nil {
+				g.Expect(podIndexer.Delete(old)).To(gomega.Succeed())
+			}
+			if tt.claim != nil && tt.claimAfterSync {
+				g.Expect(claimIndexer.Add(tt.claim.getClaim())).To(gomega.Succeed())
+			}
+
+			err = a.Reconcile(old, new)
+			if tt.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			if tt.deleteNew {
+				g.Expect(podIndexer.Delete(new)).To(gomega.Succeed())
+				g.Expect(a.Reconcile(updatedPod, nil)).To(gomega.Succeed())
+			}
+
+			if tt.expectIPs != nil {
+				g.Expect(updatedPod).NotTo(gomega.BeNil())
+				podAnnotation, err := util.UnmarshalPodAnnotation(updatedPod.Annotations, testNAD)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(util.StringSlice(podAnnotation.IPs)).To(gomega.Equal(tt.expectIPs))
+			}
+
+			if tt.expectClaim != nil {
+				g.Expect(updatedClaim).NotTo(gomega.BeNil())
+				g.Expect(updatedClaim.Name).To(gomega.Equal(tt.expectClaim.name))
+				g.Expect(updatedClaim.Labels).To(gomega.HaveKeyWithValue(types.IPAMClaimNetworkLabel, netInfo.GetNetworkName()))
+				g.Expect(updatedClaim.Spec.Network).To(gomega.Equal(tt.expectClaim.network))
+				g.Expect(updatedClaim.Spec.Workload).To(gomega.Equal(tt.expectClaim.workload))
+				g.Expect(updatedClaim.Status.IPs).To(gomega.Equal(tt.expectClaim.ips))
+				g.Expect(updatedClaim.Status.OwnerPod).To(gomega.Equal(tt.expectClaim.ownerPod))
+			} else if tt.claim == nil {
+				g.Expect(createdClaim).To(gomega.BeNil())
+			}
+
+			var held []string
+			for _, ips := range a.heldClaims {
+				held = append(held, util.StringSlice(ips)...)
+			}
+			g.Expect(held).To(gomega.ConsistOf(tt.expectHeld))
+
+			// held IPs are released once their claim is deleted
+			if tt.claim != nil {
+				g.Expect(a.DeleteIPAMClaim(tt.claim.getClaim())).To(gomega.Succeed())
+			} else if createdClaim != nil {
+				g.Expect(a.DeleteIPAMClaim(createdClaim)).To(gomega.Succeed())
+			}
+			for _, ip := range tt.expectHeld {
+				g.Expect(a.ipAllocator.AllocateIPs(netInfo.GetNetworkName(), ovntest.MustParseIPNets(ip))).To(gomega.Succeed())
+			}
+			for _, ip := range tt.expectIPsAvailable {
+				g.Expect(a.ipAllocator.AllocateIPs(netInfo.GetNetworkName(), ovntest.MustParseIPNets(ip))).To(gomega.Succeed())
+			}
+		})
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/id"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/clustermanager/pod"
 	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
 	nad "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/network-attach-def-controller"
 	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
@@ -27,8 +29,10 @@ const (
 type secondaryNetworkClusterManager struct {
 	// net-attach-def controller handle net-attach-def and create/delete network controllers
 	nadController *nad.NetAttachDefinitionController
-	ovnClient     *util.OVNClusterManagerClientset
-	watchFactory  *factory.WatchFactory
+	// network definition controller renders the net-attach-defs of the network definitions
+	netDefController *nad.NetworkDefinitionController
+	ovnClient        *util.OVNClusterManagerClientset
+	watchFactory     *factory.WatchFactory
 	// networkIDAllocator is used to allocate a unique ID for each secondary layer3 network
 	networkIDAllocator id.Allocator
 }
@@ -56,6 +60,15 @@ func newSecondaryNetworkClusterManager(ovnClient *util.OVNClusterManagerClientse
 	if err != nil {
 		return nil, err
 	}
+
+	if util.IsNetworkDefinitionsEnabled() {
+		sncm.netDefController, err = nad.NewNetworkDefinitionController("cluster-manager",
+			ovnClient.NetworkDefinitionClient, ovnClient.NetworkAttchDefClient,
+			wf.NetworkDefinitionInformer(), wf.NamespaceInformer())
+		if err != nil {
+			return nil, err
+		}
+	}
 	return sncm, nil
 }
 
@@ -69,7 +82,14 @@ func (sncm *secondaryNetworkClusterManager) Start() error {
 		return err
 	}
 
-	return sncm.nadController.Start()
+	if err := sncm.nadController.Start(); err != nil {
+		return err
+	}
+
+	if sncm.netDefController != nil {
+		return sncm.netDefController.Start()
+	}
+	return nil
 }
 
 func (sncm *secondaryNetworkClusterManager) init() error {
@@ -99,6 +119,9 @@ func (sncm *secondaryNetworkClusterManager) init() error {
 func (sncm *secondaryNetworkClusterManager) Stop() {
 	klog.Infof("Stopping secondary network cluster manager")
 	sncm.nadController.Stop()
+	if sncm.netDefController != nil {
+		sncm.netDefController.Stop()
+	}
 }
 
 // NewNetworkController implements the networkAttachDefController.NetworkControllerManager
@@ -185,6 +208,19 @@ func (sncm *secondaryNetworkClusterManager) CleanupDeletedNetworks(allController
 			klog.Errorf("Failed to delete stale subnet annotation for network %s: %v", netName, err)
 		}
 	}
+
+	// IPAMClaims are only created for layer2 and localnet networks which
+	// are not tracked in the node annotations, look for them separately
+	if util.IsPersistentIPsSupportEnabled() {
+		kube := &kube.KubeOVN{IPAMClaimClient: sncm.ovnClient.IPAMClaimClient}
+		err = pod.DeleteStaleIPAMClaims(sncm.watchFactory.IPAMClaimInformer().Lister(), kube, func(netName string) bool {
+			_, ok := existingNetworksMap[netName]
+			return !ok
+		})
+		if err != nil {
+			klog.Errorf("Failed to delete IPAMClaims of stale networks: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -5,10 +5,18 @@ import (
 	"strconv"
 	"strings"
 
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/pkg/errors"
+
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 )
 
 func clearPodBandwidth(sandboxID string) error {
+	if ovsClient != nil {
+		return clearPodBandwidthWithClient(sandboxID)
+	}
+
 	// interfaces will have the same name as ports
 	portList, err := ovsFind("interface", "name", "external-ids:sandbox="+sandboxID)
 	if err != nil {
@@ -36,8 +44,40 @@ func clearPodBandwidth(sandboxID string) error {
 	return nil
 }
 
+// clearPodBandwidthWithClient clears the QoS of any port of the sandbox and
+// removes the QoS in a single transaction
+func clearPodBandwidthWithClient(sandboxID string) error {
+	// interfaces will have the same name as ports
+	ifaces, err := libovsdbops.FindInterfacesWithPredicate(ovsClient, func(item *vswitchd.Interface) bool {
+		return item.ExternalIDs["sandbox"] == sandboxID
+	})
+	if err != nil {
+		return err
+	}
+
+	var ops []libovsdb.Operation
+	for _, iface := range ifaces {
+		ops, err = libovsdbops.UpdatePortSetQoSOps(ovsClient, ops, &vswitchd.Port{Name: iface.Name})
+		if err != nil {
+			return err
+		}
+	}
+	ops, err = libovsdbops.DeleteOVSQoSesWithPredicateOps(ovsClient, ops, func(item *vswitchd.QoS) bool {
+		return item.ExternalIDs["sandbox"] == sandboxID
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = libovsdbops.TransactAndCheck(ovsClient, ops)
+	return err
+}
+
 func setPodBandwidth(sandboxID, ifname string, ingressBPS, egressBPS int64) error {
 	// note pod ingress == OVS egress and vice versa
+	if ovsClient != nil {
+		return setPodBandwidthWithClient(sandboxID, ifname, ingressBPS, egressBPS)
+	}
 
 	if ingressBPS > 0 {
 		qos, err := ovsCreate("qos", "type=linux-htb", fmt.Sprintf("other-config:max-rate=%d", ingressBPS), "external-ids=sandbox="+sandboxID)
@@ -67,6 +107,46 @@ func setPodBandwidth(sandboxID, ifname string, ingressBPS, egressBPS int64) erro
 	return nil
 }
 
+// setPodBandwidthWithClient is the single transaction equivalent of
+// setPodBandwidth
+func setPodBandwidthWithClient(sandboxID, ifname string, ingressBPS, egressBPS int64) error {
+	var ops []libovsdb.Operation
+	var err error
+	if ingressBPS > 0 {
+		qos := &vswitchd.QoS{
+			Type:        "linux-htb",
+			OtherConfig: map[string]string{"max-rate": strconv.FormatInt(ingressBPS, 10)},
+			ExternalIDs: map[string]string{"sandbox": sandboxID},
+		}
+		ops, err = libovsdbops.CreateOVSQoSOps(ovsClient, ops, qos)
+		if err != nil {
+			return err
+		}
+		ops, err = libovsdbops.UpdatePortSetQoSOps(ovsClient, ops, &vswitchd.Port{Name: ifname, QOS: &qos.UUID})
+		if err != nil {
+			return err
+		}
+	}
+	if egressBPS > 0 {
+		// ingress_policing_rate is in Kbps
+		egressKBPS := int(egressBPS / 1000)
+		iface := &vswitchd.Interface{
+			Name:                ifname,
+			IngressPolicingRate: egressKBPS,
+			// Set the ingress_policing_burst too per recommendation in ovsdb
+			// schema, i.e 10% of the rate
+			IngressPolicingBurst: egressKBPS / 10,
+		}
+		ops, err = libovsdbops.UpdateInterfaceOps(ovsClient, ops, iface, &iface.IngressPolicingRate, &iface.IngressPolicingBurst)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = libovsdbops.TransactAndCheck(ovsClient, ops)
+	return err
+}
+
 func getOvsPortBandwidth(ifname string, dir direction) (int64, error) {
 	// note pod ingress == OVS egress and vice versa
 	// so we ingress_policing_rate is egress and max-rate is ingress from the pod's
@@ -81,6 +161,9 @@ func getOvsPortBandwidth(ifname string, dir direction) (int64, error) {
 }
 
 func getInterfaceIngressBandwith(ifname string) (int64, error) {
+	if ovsClient != nil {
+		return getInterfaceIngressBandwithWithClient(ifname)
+	}
 	qos_id, err := ovsGet("port", ifname, "qos", "")
 	if err != nil {
 		return 0, errors.Wrapf(err, "Failed to get qos for port %s", ifname)
@@ -103,7 +186,42 @@ func getInterfaceIngressBandwith(ifname string) (int64, error) {
 	return ingressBPS, nil
 }
 
+func getInterfaceIngressBandwithWithClient(ifname string) (int64, error) {
+	port, err := libovsdbops.GetPort(ovsClient, &vswitchd.Port{Name: ifname})
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to get qos for port %s", ifname)
+	}
+	if port.QOS == nil {
+		return 0, BandwidthNotFound
+	}
+	qosID := *port.QOS
+	qoses, err := libovsdbops.FindOVSQoSesWithPredicate(ovsClient, func(item *vswitchd.QoS) bool {
+		return item.UUID == qosID
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to get max-rate for qos_id %s", qosID)
+	}
+	if len(qoses) == 0 || len(qoses[0].OtherConfig["max-rate"]) == 0 {
+		return 0, BandwidthNotFound
+	}
+	ingressBPS, err := strconv.ParseInt(qoses[0].OtherConfig["max-rate"], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to parse qos max rate for %s", ifname)
+	}
+	return ingressBPS, nil
+}
+
 func getInterfaceEgressBandwith(ifname string) (int64, error) {
+	if ovsClient != nil {
+		iface, err := libovsdbops.GetInterface(ovsClient, &vswitchd.Interface{Name: ifname})
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get ingress_policing_rate for interface %s", ifname)
+		}
+		if iface.IngressPolicingRate == 0 { // 0 is the default value so we return not found
+			return 0, BandwidthNotFound
+		}
+		return int64(iface.IngressPolicingRate) * 1000, nil
+	}
 	// egressBPS
 	out, err := ovsGet("interface", ifname, "ingress_policing_rate", "")
 	if err != nil {
@@ -7,7 +7,9 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	mock_k8s_io_utils_exec "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/k8s.io/utils/exec"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 	"github.com/stretchr/testify/assert"
 	kexec "k8s.io/utils/exec"
 )
@@ -492,3 +494,66 @@ func TestGetEgressPodBandwidth(t *testing.T) {
 		})
 	}
 }
+
+func TestPodBandwidthWithOVSClient(t *testing.T) {
+	portUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f0"
+	ifaceUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f1"
+	qosUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f2"
+	dbSetup := libovsdbtest.TestSetup{
+		OVSData: []libovsdbtest.TestData{
+			&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+			&vswitchd.Interface{UUID: ifaceUUID, Name: "pod-port", ExternalIDs: map[string]string{"sandbox": "sandboxID"}},
+		},
+	}
+	client, cleanup, err := libovsdbtest.NewOVSTestHarness(dbSetup, nil)
+	if err != nil {
+		t.Fatalf("failed to set up test harness: %v", err)
+	}
+	t.Cleanup(cleanup.Cleanup)
+	SetOVSClient(client)
+	t.Cleanup(func() { SetOVSClient(nil) })
+
+	err = setPodBandwidth("sandboxID", "pod-port", 2000000, 1000000)
+	assert.NoError(t, err)
+	ingress, err := getOvsPortBandwidth("pod-port", Ingress)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000000), ingress)
+	egress, err := getOvsPortBandwidth("pod-port", Egress)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000000), egress)
+
+	expectedDB := []libovsdbtest.TestData{
+		&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}, QOS: &qosUUID},
+		&vswitchd.QoS{
+			UUID:        qosUUID,
+			Type:        "linux-htb",
+			OtherConfig: map[string]string{"max-rate": "2000000"},
+			ExternalIDs: map[string]string{"sandbox": "sandboxID"},
+		},
+		&vswitchd.Interface{
+			UUID:                 ifaceUUID,
+			Name:                 "pod-port",
+			ExternalIDs:          map[string]string{"sandbox": "sandboxID"},
+			IngressPolicingRate:  1000,
+			IngressPolicingBurst: 100,
+		},
+	}
+	matcher := libovsdbtest.HaveDataIgnoringUUIDs(expectedDB)
+	match, err := matcher.Match(client)
+	assert.NoError(t, err)
+	assert.True(t, match, matcher.FailureMessage(client))
+
+	err = clearPodBandwidth("sandboxID")
+	assert.NoError(t, err)
+	_, err = getOvsPortBandwidth("pod-port", Ingress)
+	assert.Equal(t, BandwidthNotFound, err)
+
+	expectedDB = []libovsdbtest.TestData{
+		&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+		expectedDB[2],
+	}
+	matcher = libovsdbtest.HaveDataIgnoringUUIDs(expectedDB)
+	match, err = matcher.Match(client)
+	assert.NoError(t, err)
+	assert.True(t, match, matcher.FailureMessage(client))
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 )
 
 var (
@@ -197,27 +199,7 @@ func (pr *PodRequest) cmdDel(clientset *ClientSet) (*Response, error) {
 			_ = pr.updatePodDPUConnDetailsWithRetry(&kube.Kube{KClient: clientset.kclient}, clientset.podLister, nil)
 			netdevName = dpuCD.VfNetdevName
 		} else {
-			// Find the hostInterface name
-			condString := "external-ids:sandbox=" + pr.SandboxID
-			if pr.netName != types.DefaultNetworkName {
-				condString += fmt.Sprintf(" external_ids:%s=%s", types.NADExternalID, pr.nadName)
-			} else {
-				condString += fmt.Sprintf(" external_ids:%s{=}[]", types.NADExternalID)
-			}
-			ovsIfNames, err := ovsFind("Interface", "name", condString)
-			if err != nil || len(ovsIfNames) != 1 {
-				klog.Warningf("Couldn't find the OVS interface for pod %s/%s NAD %s: %v",
-					pr.PodNamespace, pr.PodName, pr.nadName, err)
-			} else {
-				ovsIfName := ovsIfNames[0]
-				out, err := ovsGet("interface", ovsIfName, "external_ids", "vf-netdev-name")
-				if err != nil {
-					klog.Warningf("Couldn't find the original Netdev name from OVS interface %s for pod %s/%s: %v",
-						ovsIfName, pr.PodNamespace, pr.PodName, err)
-				} else {
-					netdevName = out
-				}
-			}
+			netdevName = pr.getVFNetdevName()
 		}
 	}
 
@@ -238,6 +220,55 @@ func (pr *PodRequest) cmdDel(clientset *ClientSet) (*Response, error) {
 	return response, nil
 }
 
+// getVFNetdevName returns the original netdev name of the VF of the pod
+// request as stored in the external IDs of its OVS interface
+func (pr *PodRequest) getVFNetdevName() string {
+	if ovsClient != nil {
+		return pr.getVFNetdevNameWithClient()
+	}
+
+	// Find the hostInterface name
+	condString := "external-ids:sandbox=" + pr.SandboxID
+	if pr.netName != types.DefaultNetworkName {
+		condString += fmt.Sprintf(" external_ids:%s=%s", types.NADExternalID, pr.nadName)
+	} else {
+		condString += fmt.Sprintf(" external_ids:%s{=}[]", types.NADExternalID)
+	}
+	ovsIfNames, err := ovsFind("Interface", "name", condString)
+	if err != nil || len(ovsIfNames) != 1 {
+		klog.Warningf("Couldn't find the OVS interface for pod %s/%s NAD %s: %v",
+			pr.PodNamespace, pr.PodName, pr.nadName, err)
+		return ""
+	}
+	ovsIfName := ovsIfNames[0]
+	out, err := ovsGet("interface", ovsIfName, "external_ids", "vf-netdev-name")
+	if err != nil {
+		klog.Warningf("Couldn't find the original Netdev name from OVS interface %s for pod %s/%s: %v",
+			ovsIfName, pr.PodNamespace, pr.PodName, err)
+		return ""
+	}
+	return out
+}
+
+func (pr *PodRequest) getVFNetdevNameWithClient() string {
+	ifaces, err := libovsdbops.FindInterfacesWithPredicate(ovsClient, func(item *vswitchd.Interface) bool {
+		if item.ExternalIDs["sandbox"] != pr.SandboxID {
+			return false
+		}
+		nadName, ok := item.ExternalIDs[types.NADExternalID]
+		if pr.netName != types.DefaultNetworkName {
+			return nadName == pr.nadName
+		}
+		return !ok
+	})
+	if err != nil || len(ifaces) != 1 {
+		klog.Warningf("Couldn't find the OVS interface for pod %s/%s NAD %s: %v",
+			pr.PodNamespace, pr.PodName, pr.nadName, err)
+		return ""
+	}
+	return ifaces[0].ExternalIDs["vf-netdev-name"]
+}
+
 func (pr *PodRequest) cmdCheck() error {
 	// noop...CMD check is not considered useful, and has a considerable performance impact
 	// to pod bring up times with CRIO. This is due to the fact that CRIO currently calls check
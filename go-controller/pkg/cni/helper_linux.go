@@ -5,7 +5,9 @@ package cni
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"os"
 	"runtime"
@@ -13,10 +15,13 @@ import (
 	"strings"
 	"time"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"k8s.io/klog/v2"
 
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
 	current "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/containernetworking/plugins/pkg/ip"
@@ -28,6 +33,7 @@ import (
 type CNIPluginLibOps interface {
 	AddRoute(ipn *net.IPNet, gw net.IP, dev netlink.Link, mtu int) error
 	SetupVeth(contVethName string, hostVethName string, mtu int, contVethMac string, hostNS ns.NetNS) (net.Interface, net.Interface, error)
+	SetupVLAN(parentName string, ifName string, vlanID int) (net.Interface, error)
 }
 
 type defaultCNIPluginLibOps struct{}
@@ -50,6 +56,34 @@ func (defaultCNIPluginLibOps) SetupVeth(contVethName string, hostVethName string
 	return ip.SetupVethWithName(contVethName, hostVethName, mtu, contVethMac, hostNS)
 }
 
+// SetupVLAN creates a VLAN interface on top of the parent interface, which is
+// brought up to carry the tagged traffic
+func (defaultCNIPluginLibOps) SetupVLAN(parentName string, ifName string, vlanID int) (net.Interface, error) {
+	parent, err := util.GetNetLinkOps().LinkByName(parentName)
+	if err != nil {
+		return net.Interface{}, fmt.Errorf("failed to lookup %s: %v", parentName, err)
+	}
+	if err = util.GetNetLinkOps().LinkSetUp(parent); err != nil {
+		return net.Interface{}, fmt.Errorf("failed to set up interface %s: %v", parentName, err)
+	}
+	vlan := &netlink.Vlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:        ifName,
+			ParentIndex: parent.Attrs().Index,
+			MTU:         parent.Attrs().MTU,
+		},
+		VlanId: vlanID,
+	}
+	if err = util.GetNetLinkOps().LinkAdd(vlan); err != nil {
+		return net.Interface{}, fmt.Errorf("failed to create VLAN %d interface %s on %s: %v", vlanID, ifName, parentName, err)
+	}
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return net.Interface{}, fmt.Errorf("failed to lookup %s: %v", ifName, err)
+	}
+	return *iface, nil
+}
+
 // This is a good value that allows fast streams of small packets to be aggregated,
 // without introducing noticeable latency in slower traffic.
 const udpPacketAggregationTimeout = 50 * time.Microsecond
@@ -220,13 +254,27 @@ func setupInterface(netns ns.NetNS, containerID, ifName string, ifInfo *PodInter
 			hostIface.Name = ""
 		}
 		contIface.Mac = ifInfo.MAC.String()
-		hostVeth, containerVeth, err := cniPluginLibOps.SetupVeth(ifName, hostIface.Name, ifInfo.MTU, contIface.Mac, hostNS)
+		// on a VLAN trunk, the pod interface is a VLAN interface on top of the
+		// veth, which carries the tagged traffic
+		contVethName := ifName
+		if ifInfo.VLANID != 0 {
+			contVethName = getVLANParentName(ifName)
+		}
+		hostVeth, containerVeth, err := cniPluginLibOps.SetupVeth(contVethName, hostIface.Name, ifInfo.MTU, contIface.Mac, hostNS)
 		if err != nil {
 			return err
 		}
 		hostIface.Mac = hostVeth.HardwareAddr.String()
 		contIface.Name = containerVeth.Name
 
+		if ifInfo.VLANID != 0 {
+			vlanIface, err := cniPluginLibOps.SetupVLAN(containerVeth.Name, ifName, int(ifInfo.VLANID))
+			if err != nil {
+				return err
+			}
+			contIface.Name = vlanIface.Name
+		}
+
 		link, err := util.GetNetLinkOps().LinkByName(contIface.Name)
 		if err != nil {
 			return fmt.Errorf("failed to lookup %s: %v", contIface.Name, err)
@@ -276,6 +324,18 @@ func setupInterface(netns ns.NetNS, containerID, ifName string, ifInfo *PodInter
 	return hostIface, contIface, nil
 }
 
+// getVLANParentName returns the name of the veth the VLAN interface of the pod
+// is created on, hashing the interface name when too long for a link name
+func getVLANParentName(ifName string) string {
+	name := "trunk-" + ifName
+	if len(name) <= 15 {
+		return name
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(ifName))
+	return fmt.Sprintf("trunk-%x", h.Sum64())[:15]
+}
+
 // generate a unique interface name for the temporary netdev that will be moved to pod namespace
 func generateIfName(containerID string) string {
 	randomId := util.GenerateId(5) // random ID with 5 chars
@@ -341,7 +401,11 @@ func setupSriovInterface(netns ns.NetNS, containerID, ifName string, ifInfo *Pod
 		}
 
 		// 3. make sure it's not a port managed by OVS to avoid conflicts
-		_, err = ovsExec("--if-exists", "del-port", hostRepName)
+		if ovsClient != nil {
+			err = libovsdbops.DeletePorts(ovsClient, &vswitchd.Port{Name: hostRepName})
+		} else {
+			_, err = ovsExec("--if-exists", "del-port", hostRepName)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -370,6 +434,10 @@ func ConfigureOVS(ctx context.Context, namespace, podName, hostIfaceName string,
 	if ifInfo.NetName != types.DefaultNetworkName {
 		ifaceID = util.GetSecondaryNetworkIfaceId(namespace, podName, ifInfo.NADName)
 	}
+	// the pod may bind to a logical switch port shared with other pods
+	if ifInfo.LogicalSwitchPort != "" {
+		ifaceID = ifInfo.LogicalSwitchPort
+	}
 	initialPodUID := ifInfo.PodUID
 	ipStrs := make([]string, len(ifInfo.IPs))
 	for i, ip := range ifInfo.IPs {
@@ -379,6 +447,46 @@ func ConfigureOVS(ctx context.Context, namespace, podName, hostIfaceName string,
 	klog.Infof("ConfigureOVS: namespace: %s, podName: %s, network: %s, NAD %s, SandboxID: %q, UID: %q, MAC: %s, IPs: %v",
 		namespace, podName, ifInfo.NetName, ifInfo.NADName, sandboxID, initialPodUID, ifInfo.MAC, ipStrs)
 
+	if err := configurePodPort(hostIfaceName, ifaceID, sandboxID, initialPodUID, ipStrs, ifInfo); err != nil {
+		return err
+	}
+
+	if err := clearPodBandwidth(sandboxID); err != nil {
+		return err
+	}
+
+	if ifInfo.Ingress > 0 || ifInfo.Egress > 0 {
+		l, err := netlink.LinkByName(hostIfaceName)
+		if err != nil {
+			return fmt.Errorf("failed to find host veth interface %s: %v", hostIfaceName, err)
+		}
+		err = netlink.LinkSetTxQLen(l, 1000)
+		if err != nil {
+			return fmt.Errorf("failed to set host veth txqlen: %v", err)
+		}
+
+		if err := setPodBandwidth(sandboxID, hostIfaceName, ifInfo.Ingress, ifInfo.Egress); err != nil {
+			return err
+		}
+	}
+
+	if err := waitForPodInterface(ctx, ifInfo, hostIfaceName, ifaceID, getter,
+		namespace, podName, initialPodUID); err != nil {
+		// Ensure the error shows up in node logs, rather than just
+		// being reported back to the runtime.
+		klog.Warningf("[%s/%s %s] pod uid %s: %v", namespace, podName, sandboxID, initialPodUID, err)
+		return err
+	}
+	return nil
+}
+
+// configurePodPort adds the OVS port of the pod interface to br-int, removing
+// any stale port of a previous sandbox of the same pod
+func configurePodPort(hostIfaceName, ifaceID, sandboxID, initialPodUID string, ipStrs []string, ifInfo *PodInterfaceInfo) error {
+	if ovsClient != nil {
+		return configurePodPortWithClient(hostIfaceName, ifaceID, sandboxID, initialPodUID, ipStrs, ifInfo)
+	}
+
 	// Find and remove any existing OVS port with this iface-id. Pods can
 	// have multiple sandboxes if some are waiting for garbage collection,
 	// but only the latest one should have the iface-id set.
@@ -447,32 +555,94 @@ func ConfigureOVS(ctx context.Context, namespace, podName, hostIfaceName string,
 	if out, err := ovsExec(ovsArgs...); err != nil {
 		return fmt.Errorf("failure in plugging pod interface: %v\n  %q", err, out)
 	}
+	return nil
+}
 
-	if err := clearPodBandwidth(sandboxID); err != nil {
-		return err
+// configurePodPortWithClient is the single transaction equivalent of
+// configurePodPort
+func configurePodPortWithClient(hostIfaceName, ifaceID, sandboxID, initialPodUID string, ipStrs []string, ifInfo *PodInterfaceInfo) error {
+	// Find and remove any existing OVS port with this iface-id. Pods can
+	// have multiple sandboxes if some are waiting for garbage collection,
+	// but only the latest one should have the iface-id set.
+	staleIfaces, err := libovsdbops.FindInterfacesWithPredicate(ovsClient, func(item *vswitchd.Interface) bool {
+		// a VF representor may be re-added to br-int for the same pod after
+		// restarting ovnkube-node; do not delete its port in this case.
+		return item.ExternalIDs["iface-id"] == ifaceID && item.Name != hostIfaceName
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find stale OVS ports with iface-id %q: %v", ifaceID, err)
+	}
+	stalePorts := make([]*vswitchd.Port, 0, len(staleIfaces))
+	for _, iface := range staleIfaces {
+		stalePorts = append(stalePorts, &vswitchd.Port{Name: iface.Name})
+	}
+	ops, err := libovsdbops.DeletePortsOps(ovsClient, nil, stalePorts...)
+	if err != nil {
+		return fmt.Errorf("failed to delete stale OVS ports with iface-id %q: %v", ifaceID, err)
 	}
 
-	if ifInfo.Ingress > 0 || ifInfo.Egress > 0 {
-		l, err := netlink.LinkByName(hostIfaceName)
-		if err != nil {
-			return fmt.Errorf("failed to find host veth interface %s: %v", hostIfaceName, err)
+	// if the specified port was created for other Pod/NAD, return error
+	existing, err := libovsdbops.GetInterface(ovsClient, &vswitchd.Interface{Name: hostIfaceName})
+	if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+		return fmt.Errorf("failed to get OVS interface %s: %v", hostIfaceName, err)
+	}
+	if existing != nil {
+		ifaceIDStr := existing.ExternalIDs["iface-id"]
+		nadNameString := existing.ExternalIDs[types.NADExternalID]
+		// if NADExternalID does not exists, it is default network
+		if nadNameString == "" {
+			nadNameString = types.DefaultNetworkName
 		}
-		err = netlink.LinkSetTxQLen(l, 1000)
-		if err != nil {
-			return fmt.Errorf("failed to set host veth txqlen: %v", err)
+		if ifaceIDStr != ifaceID {
+			return fmt.Errorf("OVS port %s was added for iface-id (%s), now readding it for (%s)", hostIfaceName, ifaceIDStr, ifaceID)
+		}
+		if nadNameString != ifInfo.NADName {
+			return fmt.Errorf("OVS port %s was added for NAD (%s), expect (%s)", hostIfaceName, nadNameString, ifInfo.NADName)
 		}
+	}
 
-		if err := setPodBandwidth(sandboxID, hostIfaceName, ifInfo.Ingress, ifInfo.Egress); err != nil {
-			return err
+	iface := &vswitchd.Interface{
+		Name: hostIfaceName,
+		ExternalIDs: map[string]string{
+			"attached_mac": ifInfo.MAC.String(),
+			"iface-id":     ifaceID,
+			"iface-id-ver": initialPodUID,
+			"sandbox":      sandboxID,
+		},
+	}
+	// IPAM is optional for secondary flatL2 networks; thus, the ifaces may not
+	// have IP addresses.
+	if len(ipStrs) > 0 {
+		iface.ExternalIDs["ip_addresses"] = strings.Join(ipStrs, ",")
+	}
+	if len(ifInfo.NetdevName) != 0 {
+		iface.ExternalIDs["vf-netdev-name"] = ifInfo.NetdevName
+	}
+	if ifInfo.NetName != types.DefaultNetworkName {
+		iface.ExternalIDs[types.NetworkExternalID] = ifInfo.NetName
+		iface.ExternalIDs[types.NADExternalID] = ifInfo.NADName
+	} else {
+		ops, err = libovsdbops.RemoveInterfaceExternalIDsOps(ovsClient, ops, &vswitchd.Interface{Name: hostIfaceName},
+			types.NetworkExternalID, types.NADExternalID)
+		if err != nil {
+			return fmt.Errorf("failure in plugging pod interface: %v", err)
 		}
 	}
 
-	if err := waitForPodInterface(ctx, ifInfo, hostIfaceName, ifaceID, getter,
-		namespace, podName, initialPodUID); err != nil {
-		// Ensure the error shows up in node logs, rather than just
-		// being reported back to the runtime.
-		klog.Warningf("[%s/%s %s] pod uid %s: %v", namespace, podName, sandboxID, initialPodUID, err)
-		return err
+	// Add the new sandbox's OVS port, tag the port as transient so stale
+	// pod ports are scrubbed on hard reboot
+	port := &vswitchd.Port{
+		Name:        hostIfaceName,
+		OtherConfig: map[string]string{"transient": "true"},
+	}
+	bridge := &vswitchd.Bridge{Name: "br-int"}
+	ops, err = libovsdbops.CreateOrUpdatePortAndInterfaceOnBridgeOps(ovsClient, ops, bridge, port, iface)
+	if err != nil {
+		return fmt.Errorf("failure in plugging pod interface: %v", err)
+	}
+
+	if _, err = libovsdbops.TransactAndCheck(ovsClient, ops); err != nil {
+		return fmt.Errorf("failure in plugging pod interface: %v", err)
 	}
 	return nil
 }
@@ -488,6 +658,10 @@ func (pr *PodRequest) ConfigureInterface(getter PodInfoGetter, ifInfo *PodInterf
 	var hostIface, contIface *current.Interface
 
 	klog.V(5).Infof("CNI Conf %v", pr.CNIConf)
+	if pr.CNIConf.DeviceID != "" && ifInfo.VLANID != 0 {
+		return nil, fmt.Errorf("unexpected configuration, VLAN %d requested on device %s: "+
+			"VLAN interfaces are not supported with device IDs", ifInfo.VLANID, pr.CNIConf.DeviceID)
+	}
 	if pr.CNIConf.DeviceID != "" {
 		// SR-IOV Case
 		hostIface, contIface, err = setupSriovInterface(netns, pr.SandboxID, pr.IfName, ifInfo, pr.CNIConf.DeviceID)
@@ -607,7 +781,13 @@ func (pr *PodRequest) UnconfigureInterface(ifInfo *PodInterfaceInfo) error {
 				}
 			}
 			if isSecondary && !ifInfo.IsDPUHostMode {
-				ifnameSuffix = fmt.Sprintf("_%d", link.Attrs().Index)
+				index := link.Attrs().Index
+				// the host interface is named after the veth the VLAN
+				// interface of a pod on a VLAN trunk is created on
+				if _, isVLAN := link.(*netlink.Vlan); isVLAN {
+					index = link.Attrs().ParentIndex
+				}
+				ifnameSuffix = fmt.Sprintf("_%d", index)
 			}
 			return nil
 		})
@@ -662,7 +842,13 @@ func (pr *PodRequest) deletePodConntrack() {
 func (pr *PodRequest) deletePorts(ifaceName, podNamespace, podName string) {
 	podDesc := fmt.Sprintf("%s/%s", podNamespace, podName)
 
-	out, err := ovsExec("del-port", "br-int", ifaceName)
+	var out string
+	var err error
+	if ovsClient != nil {
+		err = libovsdbops.DeletePorts(ovsClient, &vswitchd.Port{Name: ifaceName})
+	} else {
+		out, err = ovsExec("del-port", "br-int", ifaceName)
+	}
 	if err != nil && !strings.Contains(err.Error(), "no port named") {
 		// DEL should be idempotent; don't return an error just log it
 		klog.Warningf("Failed to delete pod %q OVS port %s: %v\n  %q", podDesc, ifaceName, err, string(out))
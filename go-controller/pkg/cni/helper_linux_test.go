@@ -13,12 +13,15 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/mocks"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	cni_type_mocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/containernetworking/cni/pkg/types"
 	cni_ns_mocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/containernetworking/plugins/pkg/ns"
 	netlink_mocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/vishvananda/netlink"
 	mock_k8s_io_utils_exec "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/k8s.io/utils/exec"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	util_mocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/vishvananda/netlink"
@@ -525,6 +528,14 @@ func TestSetupInterface(t *testing.T) {
 	}
 }
 
+func TestGetVLANParentName(t *testing.T) {
+	assert.Equal(t, "trunk-net1", getVLANParentName("net1"))
+	long := getVLANParentName("net-vlan-trunk1")
+	assert.Len(t, long, 15)
+	assert.NotEqual(t, long, getVLANParentName("net-vlan-trunk2"))
+	assert.Equal(t, long, getVLANParentName("net-vlan-trunk1"))
+}
+
 func TestSetupSriovInterface(t *testing.T) {
 	mockNetLinkOps := new(util_mocks.NetLinkOps)
 	mockCNIPlugin := new(mocks.CNIPluginLibOps)
@@ -1299,3 +1310,131 @@ func TestPodRequest_deletePodConntrack(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigurePodPortWithOVSClient(t *testing.T) {
+	bridgeUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f0"
+	stalePortUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f1"
+	staleIfaceUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f2"
+	portUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f3"
+	ifaceUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f4"
+	mac, _ := net.ParseMAC("0a:58:0a:80:00:05")
+	_, ipNet, _ := net.ParseCIDR("10.128.0.5/24")
+	ipNet.IP = net.ParseIP("10.128.0.5")
+
+	tests := []struct {
+		desc       string
+		ifInfo     *PodInterfaceInfo
+		initialDB  []libovsdbtest.TestData
+		expectedDB []libovsdbtest.TestData
+		errExp     bool
+	}{
+		{
+			desc: "adds the pod port to br-int and deletes the stale port of a previous sandbox",
+			ifInfo: &PodInterfaceInfo{
+				PodAnnotation: util.PodAnnotation{MAC: mac, IPs: []*net.IPNet{ipNet}},
+				PodUID:        "pod-uid",
+				NetName:       ovntypes.DefaultNetworkName,
+				NADName:       ovntypes.DefaultNetworkName,
+			},
+			initialDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{stalePortUUID}},
+				&vswitchd.Port{UUID: stalePortUUID, Name: "stale-port", Interfaces: []string{staleIfaceUUID}},
+				&vswitchd.Interface{UUID: staleIfaceUUID, Name: "stale-port", ExternalIDs: map[string]string{"iface-id": "ns_pod"}},
+			},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{
+					UUID:        portUUID,
+					Name:        "pod-port",
+					Interfaces:  []string{ifaceUUID},
+					OtherConfig: map[string]string{"transient": "true"},
+				},
+				&vswitchd.Interface{
+					UUID: ifaceUUID,
+					Name: "pod-port",
+					ExternalIDs: map[string]string{
+						"attached_mac": "0a:58:0a:80:00:05",
+						"iface-id":     "ns_pod",
+						"iface-id-ver": "pod-uid",
+						"sandbox":      "sandboxID",
+						"ip_addresses": "10.128.0.5/24",
+					},
+				},
+			},
+		},
+		{
+			desc: "adds the secondary network external IDs to an existing port",
+			ifInfo: &PodInterfaceInfo{
+				PodAnnotation: util.PodAnnotation{MAC: mac},
+				PodUID:        "pod-uid",
+				NetName:       "tenantblue",
+				NADName:       "ns/attachment",
+			},
+			initialDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+				&vswitchd.Interface{
+					UUID:        ifaceUUID,
+					Name:        "pod-port",
+					ExternalIDs: map[string]string{"iface-id": "ns_pod", ovntypes.NADExternalID: "ns/attachment"},
+				},
+			},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+				&vswitchd.Interface{
+					UUID: ifaceUUID,
+					Name: "pod-port",
+					ExternalIDs: map[string]string{
+						"attached_mac":             "0a:58:0a:80:00:05",
+						"iface-id":                 "ns_pod",
+						"iface-id-ver":             "pod-uid",
+						"sandbox":                  "sandboxID",
+						ovntypes.NetworkExternalID: "tenantblue",
+						ovntypes.NADExternalID:     "ns/attachment",
+					},
+				},
+			},
+		},
+		{
+			desc: "fails if the port was added for another pod",
+			ifInfo: &PodInterfaceInfo{
+				PodAnnotation: util.PodAnnotation{MAC: mac},
+				NetName:       ovntypes.DefaultNetworkName,
+				NADName:       ovntypes.DefaultNetworkName,
+			},
+			initialDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+				&vswitchd.Interface{UUID: ifaceUUID, Name: "pod-port", ExternalIDs: map[string]string{"iface-id": "ns_other"}},
+			},
+			errExp: true,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d:%s", i, tc.desc), func(t *testing.T) {
+			client, cleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{OVSData: tc.initialDB}, nil)
+			if err != nil {
+				t.Fatalf("failed to set up test harness: %v", err)
+			}
+			t.Cleanup(cleanup.Cleanup)
+			SetOVSClient(client)
+			t.Cleanup(func() { SetOVSClient(nil) })
+
+			ipStrs := make([]string, 0, len(tc.ifInfo.IPs))
+			for _, ip := range tc.ifInfo.IPs {
+				ipStrs = append(ipStrs, ip.String())
+			}
+			err = configurePodPort("pod-port", "ns_pod", "sandboxID", tc.ifInfo.PodUID, ipStrs, tc.ifInfo)
+			if tc.errExp {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			matcher := libovsdbtest.HaveDataIgnoringUUIDs(tc.expectedDB)
+			match, err := matcher.Match(client)
+			assert.NoError(t, err)
+			assert.True(t, match, matcher.FailureMessage(client))
+		})
+	}
+}
@@ -59,6 +59,27 @@ func (_m *CNIPluginLibOps) SetupVeth(contVethName string, hostVethName string, m
 	return r0, r1, r2
 }
 
+// SetupVLAN provides a mock function with given fields: parentName, ifName, vlanID
+func (_m *CNIPluginLibOps) SetupVLAN(parentName string, ifName string, vlanID int) (net.Interface, error) {
+	ret := _m.Called(parentName, ifName, vlanID)
+
+	var r0 net.Interface
+	if rf, ok := ret.Get(0).(func(string, string, int) net.Interface); ok {
+		r0 = rf(parentName, ifName, vlanID)
+	} else {
+		r0 = ret.Get(0).(net.Interface)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(parentName, ifName, vlanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewCNIPluginLibOps interface {
 	mock.TestingT
 	Cleanup(func())
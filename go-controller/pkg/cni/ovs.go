@@ -3,15 +3,19 @@ package cni
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 	kexec "k8s.io/utils/exec"
@@ -22,6 +26,10 @@ var runner kexec.Interface
 var vsctlPath string
 var ofctlPath string
 
+// ovsClient is used to configure the local Open_vSwitch database when set,
+// otherwise ovs-vsctl is used as the CNI shim has no long lived connection
+var ovsClient libovsdbclient.Client
+
 func SetExec(r kexec.Interface) error {
 	runner = r
 	var err error
@@ -33,6 +41,11 @@ func SetExec(r kexec.Interface) error {
 	return err
 }
 
+// SetOVSClient sets the client used to configure the local Open_vSwitch database
+func SetOVSClient(c libovsdbclient.Client) {
+	ovsClient = c
+}
+
 // ResetRunner used by unit-tests to reset runner to its initial (un-initialized) value
 func ResetRunner() {
 	runner = nil
@@ -73,6 +86,31 @@ func ovsGetMultiOutput(table, record string, columns []string) ([]string, error)
 	return result, err
 }
 
+// getInterfaceExternalIDs returns the values of the given external IDs keys of
+// an OVS interface, an empty value is returned for any missing key. A slice
+// with an empty string is returned if the interface doesn't exist.
+func getInterfaceExternalIDs(ifaceName string, keys ...string) ([]string, error) {
+	if ovsClient == nil {
+		columns := make([]string, 0, len(keys))
+		for _, key := range keys {
+			columns = append(columns, "external-ids:"+key)
+		}
+		return ovsGetMultiOutput("Interface", ifaceName, columns)
+	}
+	iface, err := libovsdbops.GetInterface(ovsClient, &vswitchd.Interface{Name: ifaceName})
+	if errors.Is(err, libovsdbclient.ErrNotFound) {
+		return []string{""}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, iface.ExternalIDs[key])
+	}
+	return values, nil
+}
+
 func ovsCreate(table string, values ...string) (string, error) {
 	args := append([]string{"create", table}, values...)
 	return ovsExec(args...)
@@ -151,6 +189,16 @@ func ofctlExec(args ...string) (string, error) {
 
 // getIfaceOFPort returns the of port number for an interface
 func getIfaceOFPort(ifaceName string) (int, error) {
+	if ovsClient != nil {
+		iface, err := libovsdbops.GetInterface(ovsClient, &vswitchd.Interface{Name: ifaceName})
+		if err != nil {
+			return -1, fmt.Errorf("cannot find OpenFlow port for OVS interface: %s, error: %v ", ifaceName, err)
+		}
+		if iface.Ofport == nil {
+			return -1, fmt.Errorf("cannot find OpenFlow port for OVS interface: %s", ifaceName)
+		}
+		return *iface.Ofport, nil
+	}
 	port, err := ovsGet("Interface", ifaceName, "ofport", "")
 	if err == nil && port == "" {
 		return -1, fmt.Errorf("cannot find OpenFlow port for OVS interface: %s, error: %v ", ifaceName, err)
@@ -290,12 +338,12 @@ func waitForPodInterface(ctx context.Context, ifInfo *PodInterfaceInfo,
 			}
 			return fmt.Errorf("%s waiting for OVS port binding%s for %s %v", errDetail, detail, mac, ifAddrs)
 		default:
-			columns := []string{"external-ids:iface-id"}
+			keys := []string{"iface-id"}
 			if checkExternalIDs {
 				// get ovn-installed flag in the same request
-				columns = append(columns, "external-ids:ovn-installed")
+				keys = append(keys, "ovn-installed")
 			}
-			output, err := ovsGetMultiOutput("Interface", ifaceName, columns)
+			output, err := getInterfaceExternalIDs(ifaceName, keys...)
 			// check to see if the interface has its external id set, which indicates if it is active
 			// It may have been cleared by a subsequent CNI ADD and if so, there's no need to keep checking for flows
 			if err == nil && len(output) > 0 && output[0] != ifaceID {
@@ -27,6 +27,25 @@ type NetConf struct {
 	ExcludeSubnets string `json:"excludeSubnets,omitempty"`
 	// VLANID, valid in localnet topology network only
 	VLANID int `json:"vlanID,omitempty"`
+	// VLANTrunk is the comma-seperated list of VLAN IDs and ranges of VLAN IDs
+	// the pods can tag their traffic with on the localnet, eg. "100,200-299",
+	// valid in localnet topology network only and exclusive with VLANID
+	VLANTrunk string `json:"vlanTrunk,omitempty"`
+	// AllowPersistentIPs allows the IPs of KubeVirt VMs and StatefulSet pods
+	// to persist across pod restarts through IPAMClaims, valid for layer2 and
+	// localnet topology networks with subnets only
+	AllowPersistentIPs bool `json:"allowPersistentIPs,omitempty"`
+	// EnableGateway gives the network a gateway router per node through which
+	// pods can reach external networks and Kubernetes services, valid for
+	// layer3 and layer2 topology networks only
+	EnableGateway bool `json:"enableGateway,omitempty"`
+	// DHCP has OVN serve the IPs of the pods over DHCP, valid for layer2 and
+	// localnet topology networks with subnets only
+	DHCP *DHCPConfig `json:"dhcp,omitempty"`
+	// IPPools are named ranges of the subnets whose IPs are only allocated to
+	// the pods selecting them, valid for layer2 and localnet topology networks
+	// with subnets only
+	IPPools []IPPool `json:"ipPools,omitempty"`
 
 	// PciAddrs in case of using sriov or Auxiliry device name in case of SF
 	DeviceID string `json:"deviceID,omitempty"`
@@ -50,6 +69,60 @@ type NetConf struct {
 	} `json:"runtimeConfig,omitempty"`
 }
 
+// DHCPConfig is the configuration of the DHCP server of a secondary network
+type DHCPConfig struct {
+	// LeaseTime is the lease time of the DHCPv4 addresses, in seconds
+	LeaseTime int `json:"leaseTime,omitempty"`
+	// DNSServers offered to the pods, of both IP families
+	DNSServers []string `json:"dnsServers,omitempty"`
+	// Domain offered to the pods as their domain name over DHCPv4 and their
+	// domain search list over DHCPv6
+	Domain string `json:"domain,omitempty"`
+	// Routes offered to the pods over DHCPv4, a default route sets the router
+	// option
+	Routes []DHCPRoute `json:"routes,omitempty"`
+	// DHCPv6Stateless only offers the DNS configuration over DHCPv6, leaving
+	// the pods to configure their IPv6 addresses on their own
+	DHCPv6Stateless bool `json:"dhcpv6Stateless,omitempty"`
+}
+
+// DHCPRoute is a route offered over DHCPv4
+type DHCPRoute struct {
+	// Dest is the destination subnet, eg. 10.10.0.0/16
+	Dest string `json:"dst"`
+	// NextHop is the gateway IP, eg. 10.1.130.1
+	NextHop string `json:"gw"`
+}
+
+// IPPool is a named range of IPs of one of the subnets of a network. Pods
+// select the pools they get their IPs from with the ipPools CNI argument of
+// their network selection element, or through their namespace.
+type IPPool struct {
+	// Name of the pool, unique in the network
+	Name string `json:"name"`
+	// Subnet the pool belongs to, one of the network subnets
+	Subnet string `json:"subnet"`
+	// RangeStart and RangeEnd are the first and last IPs of the pool, the
+	// whole subnet by default
+	RangeStart string `json:"rangeStart,omitempty"`
+	RangeEnd   string `json:"rangeEnd,omitempty"`
+	// Gateway is the next hop of the routes of the pool without one
+	Gateway string `json:"gateway,omitempty"`
+	// Routes configured on the pods with IPs of the pool
+	Routes []IPPoolRoute `json:"routes,omitempty"`
+	// Namespaces the pool is restricted to, whose pods get their IPs from the
+	// pool unless selecting pools explicitly
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// IPPoolRoute is a route configured on the pods with IPs of a pool
+type IPPoolRoute struct {
+	// Dest is the destination subnet, eg. 10.10.0.0/16
+	Dest string `json:"dst"`
+	// NextHop is the gateway IP, the gateway of the pool by default
+	NextHop string `json:"gw,omitempty"`
+}
+
 // NetworkSelectionElement represents one element of the JSON format
 // Network Attachment Selection Annotation as described in section 4.1.2
 // of the CRD specification.
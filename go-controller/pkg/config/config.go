@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"fmt"
+	"math"
 	"net"
 	"net/url"
 	"os"
@@ -11,10 +12,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/urfave/cli/v2"
 	gcfg "gopkg.in/gcfg.v1"
@@ -139,6 +142,9 @@ var (
 		V6JoinSubnet:       "fd98::/64",
 		V4MasqueradeSubnet: "169.254.169.0/29",
 		V6MasqueradeSubnet: "fd69::/125",
+		// the secondary masquerade subnets must not overlap the masquerade subnets
+		V4SecondaryMasqueradeSubnet: "169.254.0.0/17",
+		V6SecondaryMasqueradeSubnet: "fd69::1:0/112",
 		MasqueradeIPs: MasqueradeIPsConfig{
 			V4OVNMasqueradeIP:               net.ParseIP("169.254.169.1"),
 			V6OVNMasqueradeIP:               net.ParseIP("fd69::1"),
@@ -175,6 +181,12 @@ var (
 		VXLANPort: DefaultVXLANPort,
 	}
 
+	// BGP holds the BGP advertisement config options.
+	BGP = BGPConfig{
+		FRRConfigFile:    "/etc/frr/frr.conf",
+		FRRReloadCommand: "/usr/lib/frr/frr-reload.py --reload",
+	}
+
 	// UnprivilegedMode allows ovnkube-node to run without SYS_ADMIN capability, by performing interface setup in the CNI plugin
 	UnprivilegedMode bool
 
@@ -189,7 +201,13 @@ var (
 
 	// OvnKubeNode holds ovnkube-node parsed config file parameters and command-line overrides
 	OvnKubeNode = OvnKubeNodeConfig{
-		Mode: types.NodeModeFull,
+		Mode:            types.NodeModeFull,
+		FirewallBackend: FirewallBackendIPTables,
+		ConntrackCleanupPolicies: map[string]ConntrackCleanupPolicy{
+			"TCP":  ConntrackCleanupPolicyKeep,
+			"UDP":  ConntrackCleanupPolicyFlush,
+			"SCTP": ConntrackCleanupPolicyKeep,
+		},
 	}
 
 	ClusterManager = ClusterManagerConfig{
@@ -346,6 +364,7 @@ type KubernetesConfig struct {
 	ServiceCIDRs         []*net.IPNet
 	OVNConfigNamespace   string `gcfg:"ovn-config-namespace"`
 	OVNEmptyLbEvents     bool   `gcfg:"ovn-empty-lb-events"`
+	OVNLBHealthChecks    bool   `gcfg:"ovn-lb-health-checks"`
 	PodIP                string `gcfg:"pod-ip"` // UNUSED
 	RawNoHostSubnetNodes string `gcfg:"no-hostsubnet-nodes"`
 	NoHostSubnetNodes    labels.Selector
@@ -376,6 +395,12 @@ type MetricsConfig struct {
 	// configuration duration and optionally, its application to all nodes
 	EnableConfigDuration bool `gcfg:"enable-config-duration"`
 	EnableScaleMetrics   bool `gcfg:"enable-scale-metrics"`
+	// EnableServiceLBStats holds the boolean flag to enable the per service connection tracking
+	// statistics exported by OVN-Kubernetes node
+	EnableServiceLBStats bool `gcfg:"enable-service-lb-stats"`
+	// EnableServiceLBBackendStats holds the boolean flag to break down the
+	// service connection tracking statistics per backend
+	EnableServiceLBBackendStats bool `gcfg:"enable-service-lb-backend-stats"`
 }
 
 // OVNKubernetesFeatureConfig holds OVN-Kubernetes feature enhancement config file parameters and command-line overrides
@@ -392,6 +417,11 @@ type OVNKubernetesFeatureConfig struct {
 	EgressIPNodeHealthCheckPort     int  `gcfg:"egressip-node-healthcheck-port"`
 	EnableMultiNetwork              bool `gcfg:"enable-multi-network"`
 	EnableMultiNetworkPolicy        bool `gcfg:"enable-multi-networkpolicy"`
+	EnablePersistentIPs             bool `gcfg:"enable-persistent-ips"`
+	EnableNetworkDefinitions        bool `gcfg:"enable-network-definitions"`
+	EnableObservedPodIPs            bool `gcfg:"enable-observed-pod-ips"`
+	EnableSecondaryNetworkGateway   bool `gcfg:"enable-secondary-network-gateway"`
+	EnableMultiChassisLiveMigration bool `gcfg:"enable-multi-chassis-live-migration"`
 	EnableStatelessNetPol           bool `gcfg:"enable-stateless-netpol"`
 	EnableInterconnect              bool `gcfg:"enable-interconnect"`
 	EnableMultiExternalGateway      bool `gcfg:"enable-multi-external-gateway"`
@@ -435,6 +465,10 @@ type GatewayConfig struct {
 	V6MasqueradeSubnet string `gcfg:"v6-masquerade-subnet"`
 	// MasqueradeIps to be allocated from the masquerade subnets to enable host to service traffic
 	MasqueradeIPs MasqueradeIPsConfig
+	// V4SecondaryMasqueradeSubnet to be used for the gateway routers of the secondary networks
+	V4SecondaryMasqueradeSubnet string `gcfg:"v4-secondary-masquerade-subnet"`
+	// V6SecondaryMasqueradeSubnet to be used for the gateway routers of the secondary networks
+	V6SecondaryMasqueradeSubnet string `gcfg:"v6-secondary-masquerade-subnet"`
 
 	// DisablePacketMTUCheck disables adding openflow flows to check packets too large to be
 	// delivered to OVN due to pod MTU being lower than NIC MTU. Disabling this check will result in southbound packets
@@ -450,6 +484,35 @@ type GatewayConfig struct {
 	DisableForwarding bool `gcfg:"disable-forwarding"`
 	// AllowNoUplink (disabled by default) controls if the external gateway bridge without an uplink port is allowed in local gateway mode.
 	AllowNoUplink bool `gcfg:"allow-no-uplink"`
+	// RawUplinks holds the unparsed additional gateway uplinks. Should only be used inside the config module.
+	RawUplinks string `gcfg:"uplinks"`
+	// RawUplinkNextHops holds the unparsed next hops of the additional gateway uplinks. Should only be used inside the config module.
+	RawUplinkNextHops string `gcfg:"uplink-next-hops"`
+	// RawUplinkDestinations holds the unparsed destination selectors of the additional gateway uplinks. Should only be used inside the config module.
+	RawUplinkDestinations string `gcfg:"uplink-destinations"`
+	// RawUplinkNamespaces holds the unparsed namespace selectors of the additional gateway uplinks. Should only be used inside the config module.
+	RawUplinkNamespaces string `gcfg:"uplink-namespaces"`
+	// RawUplinkNetworks holds the unparsed secondary network selectors of the additional gateway uplinks. Should only be used inside the config module.
+	RawUplinkNetworks string `gcfg:"uplink-networks"`
+	// Uplinks holds the parsed additional gateway uplinks and may be used outside the config module.
+	Uplinks []GatewayUplink
+}
+
+// GatewayUplink is an additional node uplink connected to the gateway router
+// through its own OVS bridge and external switch
+type GatewayUplink struct {
+	// Name identifies the uplink in the OVN topology
+	Name string
+	// Interface is the network interface or OVS bridge of the uplink
+	Interface string
+	// NextHops are the gateways of the uplink, at most one per IP family
+	NextHops []net.IP
+	// Destinations are the external networks reached through the uplink
+	Destinations []*net.IPNet
+	// Namespaces are the namespaces whose pods egress through the uplink
+	Namespaces []string
+	// Networks are the localnet secondary networks attached to the uplink
+	Networks []string
 }
 
 // OvnAuthConfig holds client authentication and location details for
@@ -491,14 +554,69 @@ type HybridOverlayConfig struct {
 	VXLANPort uint `gcfg:"hybrid-overlay-vxlan-port"`
 }
 
+// BGPConfig holds configuration for the advertisement of the node networks
+// to BGP peers through a local FRR instance
+type BGPConfig struct {
+	// Enabled indicates whether the node advertises its host subnets, the
+	// external and load balancer IPs of services and its egress IPs.
+	Enabled bool `gcfg:"enabled"`
+	// ASN is the autonomous system number of the nodes.
+	ASN uint `gcfg:"asn"`
+	// PeerASN is the autonomous system number of the peers, defaults to ASN.
+	PeerASN uint `gcfg:"peer-asn"`
+	// RawPeers holds the unparsed comma separated peer addresses.
+	// Should only be used inside config module.
+	RawPeers string `gcfg:"peers"`
+	// Peers holds the parsed peer addresses and may be used outside the
+	// config module.
+	Peers []net.IP
+	// FRRConfigFile is the FRR configuration file rendered by ovnkube-node.
+	FRRConfigFile string `gcfg:"frr-config-file"`
+	// FRRReloadCommand is run with the configuration file as last argument
+	// to apply it to the running FRR instance.
+	FRRReloadCommand string `gcfg:"frr-reload-command"`
+	// DisableSNAT disables the SNAT of the pod egress traffic to the node
+	// IP as the advertised pod subnets are routable.
+	DisableSNAT bool `gcfg:"disable-snat"`
+}
+
 // OvnKubeNodeConfig holds ovnkube-node configurations
 type OvnKubeNodeConfig struct {
 	Mode                   string `gcfg:"mode"`
 	DPResourceDeviceIdsMap map[string][]string
 	MgmtPortNetdev         string `gcfg:"mgmt-port-netdev"`
 	MgmtPortDPResourceName string `gcfg:"mgmt-port-dp-resource-name"`
+	// FirewallBackend is the backend programming the host firewall and NAT
+	// rules of the node: iptables (default) or nftables
+	FirewallBackend string `gcfg:"firewall-backend"`
+	// RawConntrackCleanupPolicies holds the unparsed per protocol conntrack cleanup policies.
+	// Should only be used inside the config module.
+	RawConntrackCleanupPolicies string `gcfg:"conntrack-cleanup-policies"`
+	// ConntrackCleanupPolicies holds the parsed conntrack cleanup policies keyed by
+	// protocol (TCP, UDP or SCTP) and may be used outside the config module.
+	ConntrackCleanupPolicies map[string]ConntrackCleanupPolicy
 }
 
+// ConntrackCleanupPolicy tells what ovnkube-node does with the conntrack
+// entries of a protocol that became stale
+type ConntrackCleanupPolicy string
+
+const (
+	// ConntrackCleanupPolicyFlush deletes the stale conntrack entries
+	ConntrackCleanupPolicyFlush ConntrackCleanupPolicy = "flush"
+	// ConntrackCleanupPolicyKeep leaves the stale conntrack entries to expire,
+	// so that established connections are not torn down by ovnkube-node
+	ConntrackCleanupPolicyKeep ConntrackCleanupPolicy = "keep"
+)
+
+const (
+	// FirewallBackendIPTables programs the node firewall and NAT rules with iptables
+	FirewallBackendIPTables = "iptables"
+	// FirewallBackendNFTables programs the node firewall and NAT rules in
+	// dedicated nftables tables
+	FirewallBackendNFTables = "nftables"
+)
+
 // ClusterManagerConfig holds configuration for ovnkube-cluster-manager
 type ClusterManagerConfig struct {
 	// V4TransitSwitchSubnet to be used in the cluster for interconnecting multiple zones
@@ -535,6 +653,7 @@ type config struct {
 	MasterHA             HAConfig
 	ClusterMgrHA         HAConfig
 	HybridOverlay        HybridOverlayConfig
+	BGP                  BGPConfig
 	OvnKubeNode          OvnKubeNodeConfig
 	ClusterManager       ClusterManagerConfig
 }
@@ -554,6 +673,7 @@ var (
 	savedMasterHA             HAConfig
 	savedClusterMgrHA         HAConfig
 	savedHybridOverlay        HybridOverlayConfig
+	savedBGP                  BGPConfig
 	savedOvnKubeNode          OvnKubeNodeConfig
 	savedClusterManager       ClusterManagerConfig
 
@@ -567,6 +687,12 @@ var (
 	gatewayLocal bool
 	// legacy disable-ovn-iface-id-ver CLI option
 	disableOVNIfaceIDVer bool
+
+	// gatewayModeFile is the configuration file the gateway mode is read
+	// from. It is empty when the mode was set on the command line.
+	gatewayModeFile string
+	// gatewayModeLock protects Gateway.Mode, which the node switches at runtime
+	gatewayModeLock sync.RWMutex
 )
 
 func init() {
@@ -585,6 +711,7 @@ func init() {
 	savedMasterHA = MasterHA
 	savedClusterMgrHA = ClusterMgrHA
 	savedHybridOverlay = HybridOverlay
+	savedBGP = BGP
 	savedOvnKubeNode = OvnKubeNode
 	savedClusterManager = ClusterManager
 	cli.VersionPrinter = func(c *cli.Context) {
@@ -615,8 +742,10 @@ func PrepareTestConfig() error {
 	Gateway = savedGateway
 	MasterHA = savedMasterHA
 	HybridOverlay = savedHybridOverlay
+	BGP = savedBGP
 	OvnKubeNode = savedOvnKubeNode
 	ClusterManager = savedClusterManager
+	gatewayModeFile = ""
 
 	if err := completeConfig(); err != nil {
 		return err
@@ -1008,6 +1137,36 @@ var OVNK8sFeatureFlags = []cli.Flag{
 		Destination: &cliConfig.OVNKubernetesFeature.EnableMultiNetworkPolicy,
 		Value:       OVNKubernetesFeature.EnableMultiNetworkPolicy,
 	},
+	&cli.BoolFlag{
+		Name:        "enable-persistent-ips",
+		Usage:       "Configure to use IPAMClaim CRD feature to persist the IPs of workloads on secondary networks.",
+		Destination: &cliConfig.OVNKubernetesFeature.EnablePersistentIPs,
+		Value:       OVNKubernetesFeature.EnablePersistentIPs,
+	},
+	&cli.BoolFlag{
+		Name:        "enable-network-definitions",
+		Usage:       "Configure to use NetworkDefinition CRD feature to define secondary networks for multiple namespaces at once.",
+		Destination: &cliConfig.OVNKubernetesFeature.EnableNetworkDefinitions,
+		Value:       OVNKubernetesFeature.EnableNetworkDefinitions,
+	},
+	&cli.BoolFlag{
+		Name:        "enable-observed-pod-ips",
+		Usage:       "Configure to learn the IPs of pods on IPAM-less secondary networks from their ARP / ND traffic, allowing pod and namespace selectors in their multi-network policies.",
+		Destination: &cliConfig.OVNKubernetesFeature.EnableObservedPodIPs,
+		Value:       OVNKubernetesFeature.EnableObservedPodIPs,
+	},
+	&cli.BoolFlag{
+		Name:        "enable-secondary-network-gateway",
+		Usage:       "Configure to allow layer3 and layer2 secondary networks to have gateway routers through which pods reach external networks and Kubernetes services. Reserves the secondary masquerade subnets.",
+		Destination: &cliConfig.OVNKubernetesFeature.EnableSecondaryNetworkGateway,
+		Value:       OVNKubernetesFeature.EnableSecondaryNetworkGateway,
+	},
+	&cli.BoolFlag{
+		Name:        "enable-multi-chassis-live-migration",
+		Usage:       "Configure to bind the logical switch port of a KubeVirt VM to both the source and target nodes while it is live migrated on the default network, activating it on the target node on RARP.",
+		Destination: &cliConfig.OVNKubernetesFeature.EnableMultiChassisLiveMigration,
+		Value:       OVNKubernetesFeature.EnableMultiChassisLiveMigration,
+	},
 	&cli.BoolFlag{
 		Name:        "enable-stateless-netpol",
 		Usage:       "Configure to use stateless network policy feature with ovn-kubernetes.",
@@ -1111,6 +1270,13 @@ var K8sFlags = []cli.Flag{
 			"will spin up pods for the load balancer to send traffic to.",
 		Destination: &cliConfig.Kubernetes.OVNEmptyLbEvents,
 	},
+	&cli.BoolFlag{
+		Name: "ovn-lb-health-checks",
+		Usage: "If set, services annotated with k8s.ovn.org/lb-health-check get OVN load " +
+			"balancer health checks configured for their backends, and an address is " +
+			"reserved on every node switch to be used as the health check source IP.",
+		Destination: &cliConfig.Kubernetes.OVNLBHealthChecks,
+	},
 	&cli.StringFlag{
 		Name:  "pod-ip",
 		Usage: "UNUSED",
@@ -1195,6 +1361,16 @@ var MetricsFlags = []cli.Flag{
 		Usage:       "Enables metrics related to scaling",
 		Destination: &cliConfig.Metrics.EnableScaleMetrics,
 	},
+	&cli.BoolFlag{
+		Name:        "metrics-enable-service-lb-stats",
+		Usage:       "Enables per service connection tracking statistics on nodes",
+		Destination: &cliConfig.Metrics.EnableServiceLBStats,
+	},
+	&cli.BoolFlag{
+		Name:        "metrics-enable-service-lb-backend-stats",
+		Usage:       "Breaks down the service connection tracking statistics per backend, one series per service backend",
+		Destination: &cliConfig.Metrics.EnableServiceLBBackendStats,
+	},
 }
 
 // OvnNBFlags capture OVN northbound database options
@@ -1356,6 +1532,20 @@ var OVNGatewayFlags = []cli.Flag{
 		Destination: &cliConfig.Gateway.V6MasqueradeSubnet,
 		Value:       Gateway.V6MasqueradeSubnet,
 	},
+	&cli.StringFlag{
+		Name: "gateway-v4-secondary-masquerade-subnet",
+		Usage: "The v4 masquerade subnet used for assigning masquerade IPv4 addresses to the gateway routers " +
+			"of the secondary networks",
+		Destination: &cliConfig.Gateway.V4SecondaryMasqueradeSubnet,
+		Value:       Gateway.V4SecondaryMasqueradeSubnet,
+	},
+	&cli.StringFlag{
+		Name: "gateway-v6-secondary-masquerade-subnet",
+		Usage: "The v6 masquerade subnet used for assigning masquerade IPv6 addresses to the gateway routers " +
+			"of the secondary networks",
+		Destination: &cliConfig.Gateway.V6SecondaryMasqueradeSubnet,
+		Value:       Gateway.V6SecondaryMasqueradeSubnet,
+	},
 	&cli.BoolFlag{
 		Name:        "disable-pkt-mtu-check",
 		Usage:       "Disable OpenFlow checks for if packet size is greater than pod MTU",
@@ -1380,6 +1570,36 @@ var OVNGatewayFlags = []cli.Flag{
 		Usage:       "Allow the external gateway bridge without an uplink port in local gateway mode",
 		Destination: &cliConfig.Gateway.AllowNoUplink,
 	},
+	&cli.StringFlag{
+		Name: "gateway-uplinks",
+		Usage: "A comma separated set of additional gateway uplinks connected to the gateway router " +
+			"(eg, \"storage:eth2,mgmt:eth3\"). Each entry is given in the form <name>:<interface or bridge>",
+		Destination: &cliConfig.Gateway.RawUplinks,
+	},
+	&cli.StringFlag{
+		Name: "gateway-uplink-next-hops",
+		Usage: "A comma separated set of next hops of the additional gateway uplinks " +
+			"(eg, \"storage:10.10.0.1,storage:fd00:10::1\"). Each entry is given in the form <uplink>:<IP address>",
+		Destination: &cliConfig.Gateway.RawUplinkNextHops,
+	},
+	&cli.StringFlag{
+		Name: "gateway-uplink-destinations",
+		Usage: "A comma separated set of external networks reached through the additional gateway uplinks " +
+			"(eg, \"storage:10.20.0.0/16\"). Each entry is given in the form <uplink>:<CIDR>",
+		Destination: &cliConfig.Gateway.RawUplinkDestinations,
+	},
+	&cli.StringFlag{
+		Name: "gateway-uplink-namespaces",
+		Usage: "A comma separated set of namespaces whose pods egress through the additional gateway uplinks " +
+			"(eg, \"mgmt:monitoring\"). Each entry is given in the form <uplink>:<namespace>. Shared gateway mode only",
+		Destination: &cliConfig.Gateway.RawUplinkNamespaces,
+	},
+	&cli.StringFlag{
+		Name: "gateway-uplink-networks",
+		Usage: "A comma separated set of localnet secondary networks attached to the additional gateway uplinks " +
+			"(eg, \"tenant:tenant-blue\"). Each entry is given in the form <uplink>:<network name>",
+		Destination: &cliConfig.Gateway.RawUplinkNetworks,
+	},
 	// Deprecated CLI options
 	&cli.BoolFlag{
 		Name:        "init-gateways",
@@ -1463,6 +1683,48 @@ var HybridOverlayFlags = []cli.Flag{
 	},
 }
 
+// BGPFlags capture BGP advertisement options
+var BGPFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name: "enable-bgp",
+		Usage: "Advertise the node host subnets, the external and load balancer IPs of services and the egress IPs " +
+			"assigned to the node to BGP peers through a local FRR instance",
+		Destination: &cliConfig.BGP.Enabled,
+	},
+	&cli.UintFlag{
+		Name:        "bgp-asn",
+		Usage:       "The autonomous system number of the nodes",
+		Destination: &cliConfig.BGP.ASN,
+	},
+	&cli.UintFlag{
+		Name:        "bgp-peer-asn",
+		Usage:       "The autonomous system number of the BGP peers, defaults to bgp-asn",
+		Destination: &cliConfig.BGP.PeerASN,
+	},
+	&cli.StringFlag{
+		Name:        "bgp-peers",
+		Usage:       "A comma separated set of BGP peer addresses (eg, \"172.18.0.1,fc00:f853:ccd:e793::1\")",
+		Destination: &cliConfig.BGP.RawPeers,
+	},
+	&cli.StringFlag{
+		Name:        "bgp-frr-config-file",
+		Usage:       "The FRR configuration file rendered by ovnkube-node",
+		Value:       BGP.FRRConfigFile,
+		Destination: &cliConfig.BGP.FRRConfigFile,
+	},
+	&cli.StringFlag{
+		Name:        "bgp-frr-reload-command",
+		Usage:       "The command applying the FRR configuration file, which is passed as last argument",
+		Value:       BGP.FRRReloadCommand,
+		Destination: &cliConfig.BGP.FRRReloadCommand,
+	},
+	&cli.BoolFlag{
+		Name:        "bgp-disable-snat",
+		Usage:       "Do not SNAT the pod egress traffic to the node IP as the advertised pod subnets are routable",
+		Destination: &cliConfig.BGP.DisableSNAT,
+	},
+}
+
 // OvnKubeNodeFlags captures ovnkube-node specific configurations
 var OvnKubeNodeFlags = []cli.Flag{
 	&cli.StringFlag{
@@ -1486,6 +1748,21 @@ var OvnKubeNodeFlags = []cli.Flag{
 		Value:       OvnKubeNode.MgmtPortDPResourceName,
 		Destination: &cliConfig.OvnKubeNode.MgmtPortDPResourceName,
 	},
+	&cli.StringFlag{
+		Name: "ovnkube-node-firewall-backend",
+		Usage: "The backend programming the host firewall and NAT rules of the node: iptables(default) or nftables. " +
+			"Switching backend removes the rules the other backend configured.",
+		Value:       OvnKubeNode.FirewallBackend,
+		Destination: &cliConfig.OvnKubeNode.FirewallBackend,
+	},
+	&cli.StringFlag{
+		Name: "ovnkube-node-conntrack-cleanup-policies",
+		Usage: "A comma separated set of conntrack cleanup policies of the stale entries left by endpoint, " +
+			"external gateway and egress IP changes (eg, \"sctp:flush\"). Each entry is given " +
+			"in the form <protocol>:<policy> where the protocol is tcp, udp or sctp and the policy is flush or keep. " +
+			"Protocols not listed keep their default policy: flush for udp, keep for tcp and sctp",
+		Destination: &cliConfig.OvnKubeNode.RawConntrackCleanupPolicies,
+	},
 	&cli.BoolFlag{
 		Name:        "disable-ovn-iface-id-ver",
 		Usage:       "Deprecated; iface-id-ver is always enabled",
@@ -1527,6 +1804,7 @@ func GetFlags(customFlags []cli.Flag) []cli.Flag {
 	flags = append(flags, MasterHAFlags...)
 	flags = append(flags, ClusterMgrHAFlags...)
 	flags = append(flags, HybridOverlayFlags...)
+	flags = append(flags, BGPFlags...)
 	flags = append(flags, MonitoringFlags...)
 	flags = append(flags, IPFIXFlags...)
 	flags = append(flags, OvnKubeNodeFlags...)
@@ -1775,6 +2053,10 @@ func buildGatewayConfig(ctx *cli.Context, cli, file *config) error {
 	if err := overrideFields(&Gateway, &cli.Gateway, &savedGateway); err != nil {
 		return err
 	}
+	if cli.Gateway.Mode != savedGateway.Mode {
+		// the command line takes precedence over the configuration file
+		gatewayModeFile = ""
+	}
 
 	if Gateway.Mode != GatewayModeDisabled {
 		validModes := []string{string(GatewayModeShared), string(GatewayModeLocal)}
@@ -1804,9 +2086,228 @@ func buildGatewayConfig(ctx *cli.Context, cli, file *config) error {
 		return fmt.Errorf("gateway VLAN ID option: %d is supported only in shared gateway mode", Gateway.VLANID)
 	}
 
+	return parseGatewayUplinks()
+}
+
+// GetGatewayMode returns the running gateway mode. Code running while the
+// node may switch gateway modes must use it instead of reading Gateway.Mode.
+func GetGatewayMode() GatewayMode {
+	gatewayModeLock.RLock()
+	defer gatewayModeLock.RUnlock()
+	return Gateway.Mode
+}
+
+// SetGatewayMode switches the running gateway mode
+func SetGatewayMode(mode GatewayMode) {
+	gatewayModeLock.Lock()
+	defer gatewayModeLock.Unlock()
+	Gateway.Mode = mode
+}
+
+// ReadGatewayMode returns the gateway mode currently set in the configuration
+// file ovnkube was started with, allowing to switch between the local and
+// shared gateway modes without a restart. The running mode is returned when
+// the mode was set on the command line or is not set in the file.
+func ReadGatewayMode() (GatewayMode, error) {
+	if gatewayModeFile == "" {
+		return GetGatewayMode(), nil
+	}
+	f, err := os.Open(gatewayModeFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open config file %s: %v", gatewayModeFile, err)
+	}
+	defer f.Close()
+
+	var cfg config
+	if err = gcfg.ReadInto(&cfg, f); err != nil && gcfg.FatalOnly(err) != nil {
+		return "", fmt.Errorf("failed to parse config file %s: %v", gatewayModeFile, err)
+	}
+	if cfg.Gateway.Mode == GatewayModeDisabled {
+		return GetGatewayMode(), nil
+	}
+	if cfg.Gateway.Mode != GatewayModeLocal && cfg.Gateway.Mode != GatewayModeShared {
+		return "", fmt.Errorf("invalid gateway mode %q in config file %s: only %s and %s can be switched at runtime",
+			cfg.Gateway.Mode, gatewayModeFile, GatewayModeShared, GatewayModeLocal)
+	}
+	if cfg.Gateway.Mode != GatewayModeShared {
+		if Gateway.VLANID != 0 {
+			return "", fmt.Errorf("cannot switch to %s gateway mode: gateway VLAN ID is supported only in shared gateway mode",
+				cfg.Gateway.Mode)
+		}
+		if Gateway.RawUplinkNamespaces != "" {
+			return "", fmt.Errorf("cannot switch to %s gateway mode: gateway uplink namespace selectors are supported only in shared gateway mode",
+				cfg.Gateway.Mode)
+		}
+	}
+	return cfg.Gateway.Mode, nil
+}
+
+// parseGatewayUplinks parses the additional gateway uplinks and their
+// selectors into Gateway.Uplinks
+func parseGatewayUplinks() error {
+	Gateway.Uplinks = nil
+	if Gateway.RawUplinks == "" {
+		if Gateway.RawUplinkNextHops != "" || Gateway.RawUplinkDestinations != "" ||
+			Gateway.RawUplinkNamespaces != "" || Gateway.RawUplinkNetworks != "" {
+			return fmt.Errorf("gateway uplink selectors require gateway uplinks")
+		}
+		return nil
+	}
+	if Gateway.Mode == GatewayModeDisabled {
+		return fmt.Errorf("gateway uplinks option %q not allowed when gateway is disabled", Gateway.RawUplinks)
+	}
+
+	entries, err := parseGatewayUplinkEntries(Gateway.RawUplinks)
+	if err != nil {
+		return fmt.Errorf("invalid gateway uplinks: %v", err)
+	}
+	interfaces := map[string]string{}
+	for _, entry := range entries {
+		if errs := validation.IsDNS1123Label(entry[0]); len(errs) > 0 {
+			return fmt.Errorf("invalid gateway uplink name %q: %s", entry[0], strings.Join(errs, ", "))
+		}
+		if entry[1] == Gateway.Interface || entry[1] == Gateway.EgressGWInterface {
+			return fmt.Errorf("gateway uplink %q cannot use the gateway interface %s", entry[0], entry[1])
+		}
+		if other, ok := interfaces[entry[1]]; ok {
+			return fmt.Errorf("gateway uplinks %q and %q use the same interface %s", other, entry[0], entry[1])
+		}
+		interfaces[entry[1]] = entry[0]
+		Gateway.Uplinks = append(Gateway.Uplinks, GatewayUplink{Name: entry[0], Interface: entry[1]})
+	}
+	uplinks := make(map[string]*GatewayUplink, len(Gateway.Uplinks))
+	for i := range Gateway.Uplinks {
+		uplink := &Gateway.Uplinks[i]
+		if _, ok := uplinks[uplink.Name]; ok {
+			return fmt.Errorf("duplicate gateway uplink %q", uplink.Name)
+		}
+		uplinks[uplink.Name] = uplink
+	}
+
+	getUplink := func(option string, entry [2]string) (*GatewayUplink, error) {
+		uplink, ok := uplinks[entry[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid gateway %s %q: unknown uplink %q", option, entry[1], entry[0])
+		}
+		return uplink, nil
+	}
+
+	entries, err = parseGatewayUplinkEntries(Gateway.RawUplinkNextHops)
+	if err != nil {
+		return fmt.Errorf("invalid gateway uplink next hops: %v", err)
+	}
+	for _, entry := range entries {
+		uplink, err := getUplink("uplink next hop", entry)
+		if err != nil {
+			return err
+		}
+		nextHop := net.ParseIP(entry[1])
+		if nextHop == nil {
+			return fmt.Errorf("invalid gateway uplink next hop %q", entry[1])
+		}
+		if uplink.nextHop(utilnet.IsIPv6(nextHop)) != nil {
+			return fmt.Errorf("gateway uplink %q has more than one next hop for the family of %s", uplink.Name, entry[1])
+		}
+		uplink.NextHops = append(uplink.NextHops, nextHop)
+	}
+
+	entries, err = parseGatewayUplinkEntries(Gateway.RawUplinkDestinations)
+	if err != nil {
+		return fmt.Errorf("invalid gateway uplink destinations: %v", err)
+	}
+	var destinations []*net.IPNet
+	for _, entry := range entries {
+		uplink, err := getUplink("uplink destination", entry)
+		if err != nil {
+			return err
+		}
+		_, destination, err := net.ParseCIDR(entry[1])
+		if err != nil {
+			return fmt.Errorf("invalid gateway uplink destination %q: %v", entry[1], err)
+		}
+		for _, other := range destinations {
+			if other.Contains(destination.IP) || destination.Contains(other.IP) {
+				return fmt.Errorf("gateway uplink destinations %s and %s overlap", other, destination)
+			}
+		}
+		if uplink.nextHop(utilnet.IsIPv6CIDR(destination)) == nil {
+			return fmt.Errorf("gateway uplink %q requires a next hop for destination %s", uplink.Name, destination)
+		}
+		destinations = append(destinations, destination)
+		uplink.Destinations = append(uplink.Destinations, destination)
+	}
+
+	entries, err = parseGatewayUplinkEntries(Gateway.RawUplinkNamespaces)
+	if err != nil {
+		return fmt.Errorf("invalid gateway uplink namespaces: %v", err)
+	}
+	namespaces := map[string]string{}
+	for _, entry := range entries {
+		uplink, err := getUplink("uplink namespace", entry)
+		if err != nil {
+			return err
+		}
+		if Gateway.Mode != GatewayModeShared {
+			return fmt.Errorf("gateway uplink namespace selectors are supported only in shared gateway mode")
+		}
+		if other, ok := namespaces[entry[1]]; ok {
+			return fmt.Errorf("namespace %s is selected by gateway uplinks %q and %q", entry[1], other, uplink.Name)
+		}
+		if len(uplink.NextHops) == 0 {
+			return fmt.Errorf("gateway uplink %q requires a next hop to select namespace %s", uplink.Name, entry[1])
+		}
+		namespaces[entry[1]] = uplink.Name
+		uplink.Namespaces = append(uplink.Namespaces, entry[1])
+	}
+
+	entries, err = parseGatewayUplinkEntries(Gateway.RawUplinkNetworks)
+	if err != nil {
+		return fmt.Errorf("invalid gateway uplink networks: %v", err)
+	}
+	networks := map[string]string{}
+	for _, entry := range entries {
+		uplink, err := getUplink("uplink network", entry)
+		if err != nil {
+			return err
+		}
+		if other, ok := networks[entry[1]]; ok {
+			return fmt.Errorf("network %s is selected by gateway uplinks %q and %q", entry[1], other, uplink.Name)
+		}
+		networks[entry[1]] = uplink.Name
+		uplink.Networks = append(uplink.Networks, entry[1])
+	}
+
 	return nil
 }
 
+// nextHop returns the next hop of the uplink of the given family, if any
+func (uplink *GatewayUplink) nextHop(ipv6 bool) net.IP {
+	for _, nextHop := range uplink.NextHops {
+		if utilnet.IsIPv6(nextHop) == ipv6 {
+			return nextHop
+		}
+	}
+	return nil
+}
+
+// parseGatewayUplinkEntries splits a comma separated set of <uplink>:<value>
+// entries. The value may contain colons, e.g. IPv6 addresses.
+func parseGatewayUplinkEntries(raw string) ([][2]string, error) {
+	var entries [][2]string
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, ":")
+		if !found || name == "" || value == "" {
+			return nil, fmt.Errorf("entry %q is not in the form <uplink>:<value>", entry)
+		}
+		entries = append(entries, [2]string{name, value})
+	}
+	return entries, nil
+}
+
 func completeGatewayConfig(allSubnets *configSubnets, masqueradeIPs *MasqueradeIPsConfig) error {
 	// Validate v4 and v6 join subnets
 	v4IP, v4JoinCIDR, err := net.ParseCIDR(Gateway.V4JoinSubnet)
@@ -1841,6 +2342,27 @@ func completeGatewayConfig(allSubnets *configSubnets, masqueradeIPs *MasqueradeI
 	allSubnets.append(configSubnetMasquerade, v4MasqueradeCIDR)
 	allSubnets.append(configSubnetMasquerade, v6MasqueradeCIDR)
 
+	// the secondary masquerade subnets are only reserved when the secondary
+	// networks can have gateways, so that existing clusters with a custom
+	// masquerade subnet overlapping them keep working
+	if !OVNKubernetesFeature.EnableMultiNetwork || !OVNKubernetesFeature.EnableSecondaryNetworkGateway {
+		return nil
+	}
+
+	// validate v4 and v6 secondary masquerade subnets
+	_, v4SecondaryMasqueradeCIDR, err := net.ParseCIDR(Gateway.V4SecondaryMasqueradeSubnet)
+	if err != nil || utilnet.IsIPv6(v4SecondaryMasqueradeCIDR.IP) {
+		return fmt.Errorf("invalid gateway v4 secondary masquerade subnet specified, subnet: %s: error: %v",
+			Gateway.V4SecondaryMasqueradeSubnet, err)
+	}
+	_, v6SecondaryMasqueradeCIDR, err := net.ParseCIDR(Gateway.V6SecondaryMasqueradeSubnet)
+	if err != nil || !utilnet.IsIPv6(v6SecondaryMasqueradeCIDR.IP) {
+		return fmt.Errorf("invalid gateway v6 secondary masquerade subnet specified, subnet: %s: error: %v",
+			Gateway.V6SecondaryMasqueradeSubnet, err)
+	}
+	allSubnets.append(configSubnetMasquerade, v4SecondaryMasqueradeCIDR)
+	allSubnets.append(configSubnetMasquerade, v6SecondaryMasqueradeCIDR)
+
 	return nil
 }
 
@@ -1967,6 +2489,53 @@ func buildHybridOverlayConfig(ctx *cli.Context, cli, file *config) error {
 	return nil
 }
 
+func buildBGPConfig(cli, file *config) error {
+	// Copy config file values over default values
+	if err := overrideFields(&BGP, &file.BGP, &savedBGP); err != nil {
+		return err
+	}
+
+	// And CLI overrides over config file and default values
+	if err := overrideFields(&BGP, &cli.BGP, &savedBGP); err != nil {
+		return err
+	}
+
+	BGP.Peers = nil
+	if !BGP.Enabled {
+		if BGP.DisableSNAT {
+			return fmt.Errorf("bgp-disable-snat requires enable-bgp")
+		}
+		return nil
+	}
+	if BGP.ASN == 0 || BGP.ASN > math.MaxUint32 {
+		return fmt.Errorf("invalid bgp-asn %d: must be between 1 and %d", BGP.ASN, uint32(math.MaxUint32))
+	}
+	if BGP.PeerASN == 0 {
+		BGP.PeerASN = BGP.ASN
+	}
+	if BGP.PeerASN > math.MaxUint32 {
+		return fmt.Errorf("invalid bgp-peer-asn %d: must be between 1 and %d", BGP.PeerASN, uint32(math.MaxUint32))
+	}
+	for _, rawPeer := range strings.Split(BGP.RawPeers, ",") {
+		rawPeer = strings.TrimSpace(rawPeer)
+		if rawPeer == "" {
+			continue
+		}
+		peer := net.ParseIP(rawPeer)
+		if peer == nil {
+			return fmt.Errorf("invalid bgp-peers address %q", rawPeer)
+		}
+		BGP.Peers = append(BGP.Peers, peer)
+	}
+	if len(BGP.Peers) == 0 {
+		return fmt.Errorf("bgp-peers must be provided when BGP is enabled")
+	}
+	if BGP.DisableSNAT && Gateway.DisableSNATMultipleGWs {
+		return fmt.Errorf("bgp-disable-snat cannot be used with disable-snat-multiple-gws")
+	}
+	return nil
+}
+
 // completeHybridOverlayConfig completes the HybridOverlay config by parsing raw values
 // into their final form.
 func completeHybridOverlayConfig(allSubnets *configSubnets) error {
@@ -2114,6 +2683,7 @@ func initConfigWithPath(ctx *cli.Context, exec kexec.Interface, saPath string, d
 		MasterHA:             savedMasterHA,
 		ClusterMgrHA:         savedClusterMgrHA,
 		HybridOverlay:        savedHybridOverlay,
+		BGP:                  savedBGP,
 		OvnKubeNode:          savedOvnKubeNode,
 		ClusterManager:       savedClusterManager,
 	}
@@ -2144,6 +2714,11 @@ func initConfigWithPath(ctx *cli.Context, exec kexec.Interface, saPath string, d
 		klog.Infof("Parsed config file %s", f.Name())
 		klog.Infof("Parsed config: %+v", cfg)
 	}
+	gatewayModeFile = ""
+	if f != nil {
+		// the gateway mode may be set in the file later on
+		gatewayModeFile = f.Name()
+	}
 
 	if defaults == nil {
 		defaults = &Defaults{}
@@ -2229,6 +2804,10 @@ func initConfigWithPath(ctx *cli.Context, exec kexec.Interface, saPath string, d
 		return "", err
 	}
 
+	if err = buildBGPConfig(&cliConfig, &cfg); err != nil {
+		return "", err
+	}
+
 	if err = buildOvnKubeNodeConfig(ctx, &cliConfig, &cfg); err != nil {
 		return "", err
 	}
@@ -2263,6 +2842,7 @@ func initConfigWithPath(ctx *cli.Context, exec kexec.Interface, saPath string, d
 	klog.V(5).Infof("OVN North config: %+v", OvnNorth)
 	klog.V(5).Infof("OVN South config: %+v", OvnSouth)
 	klog.V(5).Infof("Hybrid Overlay config: %+v", HybridOverlay)
+	klog.V(5).Infof("BGP config: %+v", BGP)
 	klog.V(5).Infof("Ovnkube Node config: %+v", OvnKubeNode)
 	klog.V(5).Infof("Ovnkube Cluster Manager config: %+v", ClusterManager)
 
@@ -2555,6 +3135,40 @@ func ovnKubeNodeModeSupported(mode string) error {
 	return nil
 }
 
+// parseConntrackCleanupPolicies parses the conntrack cleanup policies into
+// OvnKubeNode.ConntrackCleanupPolicies, on top of the default policies
+func parseConntrackCleanupPolicies() error {
+	policies := map[string]ConntrackCleanupPolicy{
+		"TCP":  ConntrackCleanupPolicyKeep,
+		"UDP":  ConntrackCleanupPolicyFlush,
+		"SCTP": ConntrackCleanupPolicyKeep,
+	}
+	for _, entry := range strings.Split(OvnKubeNode.RawConntrackCleanupPolicies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		protocol, policy, found := strings.Cut(entry, ":")
+		if !found {
+			return fmt.Errorf("invalid conntrack cleanup policy %q: not in the form <protocol>:<policy>", entry)
+		}
+		protocol = strings.ToUpper(strings.TrimSpace(protocol))
+		if _, ok := policies[protocol]; !ok {
+			return fmt.Errorf("invalid conntrack cleanup policy %q: unsupported protocol, supported protocols: %v",
+				entry, []string{"tcp", "udp", "sctp"})
+		}
+		switch p := ConntrackCleanupPolicy(strings.ToLower(strings.TrimSpace(policy))); p {
+		case ConntrackCleanupPolicyFlush, ConntrackCleanupPolicyKeep:
+			policies[protocol] = p
+		default:
+			return fmt.Errorf("invalid conntrack cleanup policy %q: unsupported policy, supported policies: %v",
+				entry, []ConntrackCleanupPolicy{ConntrackCleanupPolicyFlush, ConntrackCleanupPolicyKeep})
+		}
+	}
+	OvnKubeNode.ConntrackCleanupPolicies = policies
+	return nil
+}
+
 // buildOvnKubeNodeConfig updates OvnKubeNode config from cli and config file
 func buildOvnKubeNodeConfig(ctx *cli.Context, cli, file *config) error {
 	// Copy config file values over default values
@@ -2572,6 +3186,18 @@ func buildOvnKubeNodeConfig(ctx *cli.Context, cli, file *config) error {
 		return err
 	}
 
+	if OvnKubeNode.FirewallBackend == "" {
+		OvnKubeNode.FirewallBackend = FirewallBackendIPTables
+	}
+	if OvnKubeNode.FirewallBackend != FirewallBackendIPTables && OvnKubeNode.FirewallBackend != FirewallBackendNFTables {
+		return fmt.Errorf("unexpected ovnkube-node-firewall-backend: %s. supported backends: %v",
+			OvnKubeNode.FirewallBackend, []string{FirewallBackendIPTables, FirewallBackendNFTables})
+	}
+
+	if err := parseConntrackCleanupPolicies(); err != nil {
+		return err
+	}
+
 	// ovnkube-node-mode dpu/dpu-host does not support hybrid overlay
 	if OvnKubeNode.Mode != types.NodeModeFull && HybridOverlay.Enabled {
 		return fmt.Errorf("hybrid overlay is not supported with ovnkube-node mode %s", OvnKubeNode.Mode)
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -321,6 +322,12 @@ var _ = Describe("Config Operations", func() {
 			gomega.Expect(OvnKubeNode.Mode).To(gomega.Equal(types.NodeModeFull))
 			gomega.Expect(OvnKubeNode.MgmtPortNetdev).To(gomega.Equal(""))
 			gomega.Expect(OvnKubeNode.MgmtPortDPResourceName).To(gomega.Equal(""))
+			gomega.Expect(OvnKubeNode.FirewallBackend).To(gomega.Equal(FirewallBackendIPTables))
+			gomega.Expect(OvnKubeNode.ConntrackCleanupPolicies).To(gomega.Equal(map[string]ConntrackCleanupPolicy{
+				"TCP":  ConntrackCleanupPolicyKeep,
+				"UDP":  ConntrackCleanupPolicyFlush,
+				"SCTP": ConntrackCleanupPolicyKeep,
+			}))
 			gomega.Expect(Gateway.RouterSubnet).To(gomega.Equal(""))
 			gomega.Expect(Gateway.SingleNode).To(gomega.BeFalse())
 			gomega.Expect(Gateway.DisableForwarding).To(gomega.BeFalse())
@@ -1172,6 +1179,30 @@ enable-pprof=true
 		gomega.Expect(Gateway.MasqueradeIPs.V6OVNMasqueradeIP.String()).To(gomega.Equal("fd68::1"))
 
 	})
+	It("only reserves the secondary masquerade subnets with secondary network gateways enabled", func() {
+		app.Action = func(ctx *cli.Context) error {
+			_, err := InitConfig(ctx, kexec.New(), nil)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			return nil
+		}
+		cliArgs := []string{
+			app.Name,
+			"-enable-multi-network",
+			"-gateway-v4-masquerade-subnet=169.254.0.0/17",
+		}
+		err := app.Run(cliArgs)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		app.Action = func(ctx *cli.Context) error {
+			_, err := InitConfig(ctx, kexec.New(), nil)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("overlaps"))
+			return nil
+		}
+		cliArgs = append(cliArgs, "-enable-secondary-network-gateway")
+		err = app.Run(cliArgs)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
 	It("overrides config file and defaults with CLI options (multi-master)", func() {
 		kubeconfigFile, _, err := createTempFile("kubeconfig")
 		gomega.Expect(err).NotTo(gomega.HaveOccurred())
@@ -1876,6 +1907,62 @@ foo=bar
 			gomega.Expect(err.Error()).To(gomega.ContainSubstring("ovnkube-node-mgmt-port-netdev or ovnkube-node-mgmt-port-dp-resource-name must not be provided"))
 		})
 
+		It("Fails if the firewall backend is not supported", func() {
+			cliConfig := config{
+				OvnKubeNode: OvnKubeNodeConfig{
+					Mode:            types.NodeModeDPU,
+					FirewallBackend: "ebpf",
+				},
+			}
+			err := buildOvnKubeNodeConfig(nil, &cliConfig, &config{})
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("unexpected ovnkube-node-firewall-backend: ebpf"))
+		})
+
+		It("Overrides the default conntrack cleanup policies", func() {
+			cliConfig := config{
+				OvnKubeNode: OvnKubeNodeConfig{
+					Mode:                        types.NodeModeFull,
+					RawConntrackCleanupPolicies: "TCP:flush, udp:keep, sctp:flush",
+				},
+			}
+			file := config{
+				OvnKubeNode: OvnKubeNodeConfig{
+					Mode: types.NodeModeFull,
+				},
+			}
+			err := buildOvnKubeNodeConfig(nil, &cliConfig, &file)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(OvnKubeNode.ConntrackCleanupPolicies).To(gomega.Equal(map[string]ConntrackCleanupPolicy{
+				"TCP":  ConntrackCleanupPolicyFlush,
+				"UDP":  ConntrackCleanupPolicyKeep,
+				"SCTP": ConntrackCleanupPolicyFlush,
+			}))
+		})
+
+		It("Fails if a conntrack cleanup policy is not supported", func() {
+			for raw, expected := range map[string]string{
+				"udp":         "not in the form <protocol>:<policy>",
+				"icmp:flush":  "unsupported protocol",
+				"sctp:expire": "unsupported policy",
+			} {
+				cliConfig := config{
+					OvnKubeNode: OvnKubeNodeConfig{
+						Mode:                        types.NodeModeFull,
+						RawConntrackCleanupPolicies: raw,
+					},
+				}
+				file := config{
+					OvnKubeNode: OvnKubeNodeConfig{
+						Mode: types.NodeModeFull,
+					},
+				}
+				err := buildOvnKubeNodeConfig(nil, &cliConfig, &file)
+				gomega.Expect(err).To(gomega.HaveOccurred())
+				gomega.Expect(err.Error()).To(gomega.ContainSubstring(expected))
+			}
+		})
+
 		It("Fails if management port is not provided and ovnkube node mode is dpu-host", func() {
 			cliConfig := config{
 				OvnKubeNode: OvnKubeNodeConfig{
@@ -1919,4 +2006,225 @@ foo=bar
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 		})
 	})
+
+	Describe("BGP config", func() {
+		It("Parses the peers and defaults the peer ASN to the local ASN", func() {
+			cliConfig := config{
+				BGP: BGPConfig{
+					Enabled:  true,
+					ASN:      64512,
+					RawPeers: "172.18.0.1, fc00::1",
+				},
+			}
+			err := buildBGPConfig(&cliConfig, &config{})
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(BGP.PeerASN).To(gomega.Equal(uint(64512)))
+			gomega.Expect(BGP.Peers).To(gomega.Equal([]net.IP{net.ParseIP("172.18.0.1"), net.ParseIP("fc00::1")}))
+		})
+
+		It("Fails if bgp-peers missing", func() {
+			cliConfig := config{
+				BGP: BGPConfig{
+					Enabled: true,
+					ASN:     64512,
+				},
+			}
+			err := buildBGPConfig(&cliConfig, &config{})
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("bgp-peers must be provided"))
+		})
+
+		It("Fails if the ASN is out of range", func() {
+			cliConfig := config{
+				BGP: BGPConfig{
+					Enabled:  true,
+					ASN:      1 << 32,
+					RawPeers: "172.18.0.1",
+				},
+			}
+			err := buildBGPConfig(&cliConfig, &config{})
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("invalid bgp-asn"))
+		})
+
+		It("Fails if SNAT is disabled without BGP", func() {
+			cliConfig := config{
+				BGP: BGPConfig{
+					DisableSNAT: true,
+				},
+			}
+			err := buildBGPConfig(&cliConfig, &config{})
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("bgp-disable-snat requires enable-bgp"))
+		})
+	})
+
+	Describe("Gateway uplinks config", func() {
+		BeforeEach(func() {
+			Gateway.Mode = GatewayModeShared
+			Gateway.Interface = "breth0"
+		})
+
+		It("Parses the uplinks and their selectors", func() {
+			Gateway.RawUplinks = "storage:eth1, tenant:eth2"
+			Gateway.RawUplinkNextHops = "storage:192.168.10.1,storage:fd00:10::1,tenant:192.168.20.1"
+			Gateway.RawUplinkDestinations = "storage:192.168.100.0/24,storage:fd00:100::/64"
+			Gateway.RawUplinkNamespaces = "tenant:tenant-a"
+			Gateway.RawUplinkNetworks = "storage:storage-net"
+			err := parseGatewayUplinks()
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(Gateway.Uplinks).To(gomega.Equal([]GatewayUplink{
+				{
+					Name:         "storage",
+					Interface:    "eth1",
+					NextHops:     []net.IP{net.ParseIP("192.168.10.1"), net.ParseIP("fd00:10::1")},
+					Destinations: ovntest.MustParseIPNets("192.168.100.0/24", "fd00:100::/64"),
+					Networks:     []string{"storage-net"},
+				},
+				{
+					Name:       "tenant",
+					Interface:  "eth2",
+					NextHops:   []net.IP{net.ParseIP("192.168.20.1")},
+					Namespaces: []string{"tenant-a"},
+				},
+			}))
+		})
+
+		It("Fails if a selector references an unknown uplink", func() {
+			Gateway.RawUplinks = "storage:eth1"
+			Gateway.RawUplinkNextHops = "tenant:192.168.20.1"
+			err := parseGatewayUplinks()
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("unknown uplink \"tenant\""))
+		})
+
+		It("Fails if an uplink uses the gateway interface", func() {
+			Gateway.RawUplinks = "storage:breth0"
+			err := parseGatewayUplinks()
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("cannot use the gateway interface"))
+		})
+
+		It("Fails if a destination has no next hop of its family", func() {
+			Gateway.RawUplinks = "storage:eth1"
+			Gateway.RawUplinkNextHops = "storage:192.168.10.1"
+			Gateway.RawUplinkDestinations = "storage:fd00:100::/64"
+			err := parseGatewayUplinks()
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("requires a next hop for destination"))
+		})
+
+		It("Fails if namespaces are selected in local gateway mode", func() {
+			Gateway.Mode = GatewayModeLocal
+			Gateway.RawUplinks = "tenant:eth2"
+			Gateway.RawUplinkNextHops = "tenant:192.168.20.1"
+			Gateway.RawUplinkNamespaces = "tenant:tenant-a"
+			err := parseGatewayUplinks()
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err.Error()).To(gomega.ContainSubstring("supported only in shared gateway mode"))
+		})
+	})
+
+	Describe("Gateway mode switching", func() {
+		var kubeconfigFile, kubeCAFile, fileMode string
+
+		BeforeEach(func() {
+			var err error
+			kubeconfigFile, _, err = createTempFile("kubeconfig")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			kubeCAFile, _, err = createTempFile("kube-ca.crt")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			err = writeTestConfigFile(cfgFile.Name(), "kubeconfig="+kubeconfigFile, "cacert="+kubeCAFile, "vlan-id=0")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			fileMode = "shared"
+		})
+
+		AfterEach(func() {
+			os.Remove(kubeconfigFile)
+			os.Remove(kubeCAFile)
+		})
+
+		setConfigFileGatewayMode := func(mode string) {
+			data, err := os.ReadFile(cfgFile.Name())
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			data = []byte(strings.Replace(string(data), "[gateway]\nmode="+fileMode, "[gateway]\nmode="+mode, 1))
+			gomega.Expect(os.WriteFile(cfgFile.Name(), data, 0o644)).To(gomega.Succeed())
+			fileMode = mode
+		}
+
+		It("reads the gateway mode changed in the config file", func() {
+			app.Action = func(ctx *cli.Context) error {
+				_, err := InitConfig(ctx, kexec.New(), nil)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(Gateway.Mode).To(gomega.Equal(GatewayModeShared))
+
+				mode, err := ReadGatewayMode()
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(mode).To(gomega.Equal(GatewayModeShared))
+
+				setConfigFileGatewayMode("local")
+				mode, err = ReadGatewayMode()
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(mode).To(gomega.Equal(GatewayModeLocal))
+				// the running mode is only changed by the node
+				gomega.Expect(Gateway.Mode).To(gomega.Equal(GatewayModeShared))
+
+				// the gateway VLAN ID requires the shared gateway mode
+				Gateway.VLANID = 10
+				_, err = ReadGatewayMode()
+				gomega.Expect(err).To(gomega.HaveOccurred())
+				gomega.Expect(err.Error()).To(gomega.ContainSubstring("supported only in shared gateway mode"))
+				Gateway.VLANID = 0
+
+				setConfigFileGatewayMode("foo")
+				_, err = ReadGatewayMode()
+				gomega.Expect(err).To(gomega.HaveOccurred())
+				gomega.Expect(err.Error()).To(gomega.ContainSubstring("invalid gateway mode \"foo\""))
+				return nil
+			}
+			err := app.Run([]string{app.Name, "-config-file=" + cfgFile.Name()})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+
+		It("reads the gateway mode set later in the config file", func() {
+			data := "[kubernetes]\nkubeconfig=" + kubeconfigFile + "\ncacert=" + kubeCAFile + "\n\n[gateway]\n"
+			gomega.Expect(os.WriteFile(cfgFile.Name(), []byte(data), 0o644)).To(gomega.Succeed())
+
+			app.Action = func(ctx *cli.Context) error {
+				_, err := InitConfig(ctx, kexec.New(), nil)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				// the running mode is returned while the file does not set the mode
+				mode, err := ReadGatewayMode()
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(mode).To(gomega.Equal(Gateway.Mode))
+
+				data, err := os.ReadFile(cfgFile.Name())
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				data = []byte(strings.Replace(string(data), "[gateway]\n", "[gateway]\nmode=local\n", 1))
+				gomega.Expect(os.WriteFile(cfgFile.Name(), data, 0o644)).To(gomega.Succeed())
+				mode, err = ReadGatewayMode()
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(mode).To(gomega.Equal(GatewayModeLocal))
+				return nil
+			}
+			err := app.Run([]string{app.Name, "-config-file=" + cfgFile.Name()})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+
+		It("ignores the config file if the gateway mode is set on the command line", func() {
+			app.Action = func(ctx *cli.Context) error {
+				_, err := InitConfig(ctx, kexec.New(), nil)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				setConfigFileGatewayMode("local")
+				mode, err := ReadGatewayMode()
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(mode).To(gomega.Equal(GatewayModeShared))
+				return nil
+			}
+			err := app.Run([]string{app.Name, "-config-file=" + cfgFile.Name(), "-gateway-mode=shared"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=ipamclaims
+// +kubebuilder::singular=ipamclaim
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Network",type=string,JSONPath=".spec.network"
+// +kubebuilder:printcolumn:name="Workload",type=string,JSONPath=".spec.workload"
+// +kubebuilder:printcolumn:name="IPs",type=string,JSONPath=".status.ips"
+// IPAMClaim is a CRD that persists the IPs allocated to a workload on a layer2
+// or localnet secondary network with persistent IPs allowed. The IPs of the
+// claim are kept allocated when the pods of the workload are deleted and are
+// handed over to the next pod of the workload attached to the same network,
+// until the claim is removed.
+type IPAMClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPAMClaimSpec   `json:"spec,omitempty"`
+	Status IPAMClaimStatus `json:"status,omitempty"`
+}
+
+// IPAMClaimSpec defines the desired state of IPAMClaim
+type IPAMClaimSpec struct {
+	// Network is the namespace/name of the NetworkAttachmentDefinition the
+	// IPs are claimed on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="network is immutable"
+	Network string `json:"network"`
+	// Workload is the name of the workload owning the claim: the KubeVirt
+	// VirtualMachine or the StatefulSet pod name.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="workload is immutable"
+	Workload string `json:"workload"`
+}
+
+// IPAMClaimStatus defines the observed state of IPAMClaim
+type IPAMClaimStatus struct {
+	// IPs are the claimed IPs in CIDR notation.
+	// +optional
+	IPs []string `json:"ips,omitempty"`
+	// OwnerPod is the name of the pod of the workload the IPs were last
+	// allocated to.
+	// +optional
+	OwnerPod string `json:"ownerPod,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=ipamclaims
+// +kubebuilder::singular=ipamclaim
+// IPAMClaimList contains a list of IPAMClaim
+type IPAMClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAMClaim `json:"items"`
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=networkdefinitions,scope=Cluster,shortName=netdef,singular=networkdefinition
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Topology",type=string,JSONPath=".spec.network.topology"
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=".status.namespaces"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// NetworkDefinition is a CRD that defines a secondary network once for the
+// whole cluster. A NetworkAttachmentDefinition named after the
+// NetworkDefinition is rendered in each of the namespaces selected by its
+// namespace selector, all of them attaching to the same network.
+type NetworkDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NetworkDefinitionSpec   `json:"spec"`
+	Status NetworkDefinitionStatus `json:"status,omitempty"`
+}
+
+// NetworkDefinitionSpec defines the desired state of NetworkDefinition
+type NetworkDefinitionSpec struct {
+	// NamespaceSelector selects the namespaces the network is made available
+	// in. An empty selector selects all the namespaces.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+	// Network is the configuration of the network.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf", message="network is immutable"
+	Network NetworkSpec `json:"network"`
+}
+
+// NetworkSpec is the configuration of a secondary network, as documented for
+// the OVN-Kubernetes NetworkAttachmentDefinitions.
+// +kubebuilder:validation:XValidation:rule="self.topology == 'localnet' || !has(self.vlanID)", message="vlanID is only supported on localnet topology"
+// +kubebuilder:validation:XValidation:rule="self.topology == 'layer3' || !has(self.subnets) || self.subnets.all(s, s.split('/').size() == 2)", message="host subnet length is only supported on layer3 topology"
+type NetworkSpec struct {
+	// Topology is the topology of the network.
+	// +kubebuilder:validation:Enum=layer3;layer2;localnet
+	Topology string `json:"topology"`
+	// Subnets are the subnets of the network. On layer3 topology networks
+	// they can feature the length of the per node subnets,
+	// i.e. 10.128.0.0/16/24.
+	// +optional
+	Subnets []string `json:"subnets,omitempty"`
+	// ExcludeSubnets are the CIDRs / IPs that are never handed over to the
+	// pods, on layer2 and localnet topology networks.
+	// +optional
+	ExcludeSubnets []string `json:"excludeSubnets,omitempty"`
+	// MTU is the MTU of the network interfaces of the pods.
+	// +kubebuilder:validation:Minimum=576
+	// +kubebuilder:validation:Maximum=65536
+	// +optional
+	MTU int `json:"mtu,omitempty"`
+	// VLANID is the VLAN tag of the network traffic on localnet topology
+	// networks.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// +optional
+	VLANID int `json:"vlanID,omitempty"`
+	// AllowPersistentIPs persists the IPs of KubeVirt virtual machines and
+	// StatefulSet pods on layer2 and localnet topology networks.
+	// +optional
+	AllowPersistentIPs bool `json:"allowPersistentIPs,omitempty"`
+	// EnableGateway gives layer3 and layer2 topology networks access to
+	// external networks and Kubernetes services.
+	// +optional
+	EnableGateway bool `json:"enableGateway,omitempty"`
+}
+
+// NetworkDefinitionStatus defines the observed state of NetworkDefinition
+type NetworkDefinitionStatus struct {
+	// Namespaces are the namespaces the NetworkAttachmentDefinition of the
+	// network is rendered in.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Conflicts are the selected namespaces the NetworkAttachmentDefinition
+	// of the network could not be rendered in.
+	// +optional
+	Conflicts []NetworkDefinitionConflict `json:"conflicts,omitempty"`
+	// Conditions report whether the network is valid and rendered in all the
+	// selected namespaces.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NetworkDefinitionConflict is a selected namespace the
+// NetworkAttachmentDefinition of the network could not be rendered in.
+type NetworkDefinitionConflict struct {
+	// Namespace is the namespace in conflict.
+	Namespace string `json:"namespace"`
+	// Message describes the conflict.
+	Message string `json:"message"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=networkdefinitions
+// +kubebuilder::singular=networkdefinition
+// NetworkDefinitionList contains a list of NetworkDefinition
+type NetworkDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkDefinition `json:"items"`
+}
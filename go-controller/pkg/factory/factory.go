@@ -49,6 +49,10 @@ import (
 	egressservicescheme "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/clientset/versioned/scheme"
 	egressserviceinformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/informers/externalversions"
 	egressserviceinformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/informers/externalversions/egressservice/v1"
+	ipamclaiminformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/informers/externalversions"
+	ipamclaiminformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/informers/externalversions/ipamclaim/v1"
+	networkdefinitioninformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/informers/externalversions"
+	networkdefinitioninformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/informers/externalversions/networkdefinition/v1"
 
 	adminbasedpolicyapi "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/adminpolicybasedroute/v1"
 	adminbasedpolicyscheme "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/adminpolicybasedroute/v1/apis/clientset/versioned/scheme"
@@ -90,6 +94,8 @@ type WatchFactory struct {
 	mnpFactory           mnpinformerfactory.SharedInformerFactory
 	egressServiceFactory egressserviceinformerfactory.SharedInformerFactory
 	apbRouteFactory      adminbasedpolicyinformerfactory.SharedInformerFactory
+	ipamClaimFactory     ipamclaiminformerfactory.SharedInformerFactory
+	netDefFactory        networkdefinitioninformerfactory.SharedInformerFactory
 	informers            map[reflect.Type]*informer
 
 	stopChan chan struct{}
@@ -133,8 +139,6 @@ type egressNode struct{}
 type egressFwNode struct{}
 
 // types for handlers in use by ovn-k node
-type namespaceExGw struct{}
-type endpointSliceForStaleConntrackRemoval struct{}
 type serviceForGateway struct{}
 type endpointSliceForGateway struct{}
 type serviceForFakeNodePortWatcher struct{} // only for unit tests
@@ -166,11 +170,9 @@ var (
 	MultiNetworkPolicyType                reflect.Type = reflect.TypeOf(&mnpapi.MultiNetworkPolicy{})
 
 	// Resource types used in ovnk node
-	NamespaceExGwType                         reflect.Type = reflect.TypeOf(&namespaceExGw{})
-	EndpointSliceForStaleConntrackRemovalType reflect.Type = reflect.TypeOf(&endpointSliceForStaleConntrackRemoval{})
-	ServiceForGatewayType                     reflect.Type = reflect.TypeOf(&serviceForGateway{})
-	EndpointSliceForGatewayType               reflect.Type = reflect.TypeOf(&endpointSliceForGateway{})
-	ServiceForFakeNodePortWatcherType         reflect.Type = reflect.TypeOf(&serviceForFakeNodePortWatcher{}) // only for unit tests
+	ServiceForGatewayType             reflect.Type = reflect.TypeOf(&serviceForGateway{})
+	EndpointSliceForGatewayType       reflect.Type = reflect.TypeOf(&endpointSliceForGateway{})
+	ServiceForFakeNodePortWatcherType reflect.Type = reflect.TypeOf(&serviceForFakeNodePortWatcher{}) // only for unit tests
 )
 
 // NewMasterWatchFactory initializes a new watch factory for:
@@ -435,6 +437,24 @@ func (wf *WatchFactory) Start() error {
 		}
 	}
 
+	if util.IsPersistentIPsSupportEnabled() && wf.ipamClaimFactory != nil {
+		wf.ipamClaimFactory.Start(wf.stopChan)
+		for oType, synced := range waitForCacheSyncWithTimeout(wf.ipamClaimFactory, wf.stopChan) {
+			if !synced {
+				return fmt.Errorf("error in syncing cache for %v informer", oType)
+			}
+		}
+	}
+
+	if util.IsNetworkDefinitionsEnabled() && wf.netDefFactory != nil {
+		wf.netDefFactory.Start(wf.stopChan)
+		for oType, synced := range waitForCacheSyncWithTimeout(wf.netDefFactory, wf.stopChan) {
+			if !synced {
+				return fmt.Errorf("error in syncing cache for %v informer", oType)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -570,6 +590,8 @@ func NewClusterManagerWatchFactory(ovnClientset *util.OVNClusterManagerClientset
 		cpipcFactory:         ocpcloudnetworkinformerfactory.NewSharedInformerFactory(ovnClientset.CloudNetworkClient, resyncInterval),
 		egressServiceFactory: egressserviceinformerfactory.NewSharedInformerFactoryWithOptions(ovnClientset.EgressServiceClient, resyncInterval),
 		apbRouteFactory:      adminbasedpolicyinformerfactory.NewSharedInformerFactory(ovnClientset.AdminPolicyRouteClient, resyncInterval),
+		ipamClaimFactory:     ipamclaiminformerfactory.NewSharedInformerFactory(ovnClientset.IPAMClaimClient, resyncInterval),
+		netDefFactory:        networkdefinitioninformerfactory.NewSharedInformerFactory(ovnClientset.NetworkDefinitionClient, resyncInterval),
 		informers:            make(map[reflect.Type]*informer),
 		stopChan:             make(chan struct{}),
 	}
@@ -656,6 +678,16 @@ func NewClusterManagerWatchFactory(ovnClientset *util.OVNClusterManagerClientset
 		wf.efFactory.K8s().V1().EgressFirewalls().Informer()
 	}
 
+	if util.IsPersistentIPsSupportEnabled() {
+		// make sure shared informer is created for a factory, so on wf.ipamClaimFactory.Start() it is initialized and caches are synced.
+		wf.ipamClaimFactory.K8s().V1().IPAMClaims().Informer()
+	}
+
+	if util.IsNetworkDefinitionsEnabled() {
+		// make sure shared informer is created for a factory, so on wf.netDefFactory.Start() it is initialized and caches are synced.
+		wf.netDefFactory.K8s().V1().NetworkDefinitions().Informer()
+	}
+
 	return wf, nil
 }
 
@@ -763,7 +795,7 @@ func (wf *WatchFactory) GetHandlerPriority(objType reflect.Type) (priority int)
 func (wf *WatchFactory) GetResourceHandlerFunc(objType reflect.Type) (AddHandlerFuncType, error) {
 	priority := wf.GetHandlerPriority(objType)
 	switch objType {
-	case NamespaceType, NamespaceExGwType:
+	case NamespaceType:
 		return func(namespace string, sel labels.Selector,
 			funcs cache.ResourceEventHandler, processExisting func([]interface{}) error) (*Handler, error) {
 			return wf.AddNamespaceHandler(funcs, processExisting)
@@ -823,7 +855,7 @@ func (wf *WatchFactory) GetResourceHandlerFunc(objType reflect.Type) (AddHandler
 			return wf.AddCloudPrivateIPConfigHandler(funcs, processExisting)
 		}, nil
 
-	case EndpointSliceForStaleConntrackRemovalType, EndpointSliceForGatewayType:
+	case EndpointSliceForGatewayType:
 		return func(namespace string, sel labels.Selector,
 			funcs cache.ResourceEventHandler, processExisting func([]interface{}) error) (*Handler, error) {
 			return wf.AddEndpointSliceHandler(funcs, processExisting)
@@ -1252,6 +1284,14 @@ func (wf *WatchFactory) EgressServiceInformer() egressserviceinformer.EgressServ
 	return wf.egressServiceFactory.K8s().V1().EgressServices()
 }
 
+func (wf *WatchFactory) IPAMClaimInformer() ipamclaiminformer.IPAMClaimInformer {
+	return wf.ipamClaimFactory.K8s().V1().IPAMClaims()
+}
+
+func (wf *WatchFactory) NetworkDefinitionInformer() networkdefinitioninformer.NetworkDefinitionInformer {
+	return wf.netDefFactory.K8s().V1().NetworkDefinitions()
+}
+
 func (wf *WatchFactory) APBRouteInformer() adminpolicybasedrouteinformer.AdminPolicyBasedExternalRouteInformer {
 	return wf.apbRouteFactory.K8s().V1().AdminPolicyBasedExternalRoutes()
 }
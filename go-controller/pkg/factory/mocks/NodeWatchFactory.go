@@ -94,6 +94,32 @@ func (_m *NodeWatchFactory) AddFilteredServiceHandler(namespace string, handlerF
 	return r0, r1
 }
 
+// AddFilteredNodeHandler provides a mock function with given fields: sel, handlerFuncs, processExisting
+func (_m *NodeWatchFactory) AddFilteredNodeHandler(sel labels.Selector, handlerFuncs cache.ResourceEventHandler, processExisting func([]interface{}) error) (*factory.Handler, error) {
+	ret := _m.Called(sel, handlerFuncs, processExisting)
+
+	var r0 *factory.Handler
+	var r1 error
+	if rf, ok := ret.Get(0).(func(labels.Selector, cache.ResourceEventHandler, func([]interface{}) error) (*factory.Handler, error)); ok {
+		return rf(sel, handlerFuncs, processExisting)
+	}
+	if rf, ok := ret.Get(0).(func(labels.Selector, cache.ResourceEventHandler, func([]interface{}) error) *factory.Handler); ok {
+		r0 = rf(sel, handlerFuncs, processExisting)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*factory.Handler)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(labels.Selector, cache.ResourceEventHandler, func([]interface{}) error) error); ok {
+		r1 = rf(sel, handlerFuncs, processExisting)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddNamespaceHandler provides a mock function with given fields: handlerFuncs, processExisting
 func (_m *NodeWatchFactory) AddNamespaceHandler(handlerFuncs cache.ResourceEventHandler, processExisting func([]interface{}) error) (*factory.Handler, error) {
 	ret := _m.Called(handlerFuncs, processExisting)
@@ -574,6 +600,11 @@ func (_m *NodeWatchFactory) RemoveNamespaceHandler(handler *factory.Handler) {
 	_m.Called(handler)
 }
 
+// RemoveNodeHandler provides a mock function with given fields: handler
+func (_m *NodeWatchFactory) RemoveNodeHandler(handler *factory.Handler) {
+	_m.Called(handler)
+}
+
 // RemovePodHandler provides a mock function with given fields: handler
 func (_m *NodeWatchFactory) RemovePodHandler(handler *factory.Handler) {
 	_m.Called(handler)
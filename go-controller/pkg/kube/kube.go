@@ -12,6 +12,8 @@ import (
 	egressipv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1"
 	egressipclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/clientset/versioned"
 	egressserviceclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/clientset/versioned"
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+	ipamclaimclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/clientset/versioned"
 	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -39,6 +41,9 @@ type InterfaceOVN interface {
 	UpdateCloudPrivateIPConfig(cloudPrivateIPConfig *ocpcloudnetworkapi.CloudPrivateIPConfig) (*ocpcloudnetworkapi.CloudPrivateIPConfig, error)
 	DeleteCloudPrivateIPConfig(name string) error
 	UpdateEgressServiceStatus(namespace, name, host string) error
+	CreateIPAMClaim(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error)
+	UpdateIPAMClaimStatus(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error)
+	DeleteIPAMClaim(namespace, name string) error
 }
 
 // Interface represents the exported methods for dealing with getting/setting
@@ -79,6 +84,7 @@ type KubeOVN struct {
 	CloudNetworkClient   ocpcloudnetworkclientset.Interface
 	EgressServiceClient  egressserviceclientset.Interface
 	APBRouteClient       adminpolicybasedrouteclientset.Interface
+	IPAMClaimClient      ipamclaimclientset.Interface
 }
 
 // SetAnnotationsOnPod takes the pod object and map of key/value string pairs to set as annotations
@@ -456,3 +462,18 @@ func (k *KubeOVN) UpdateEgressServiceStatus(namespace, name, host string) error
 	_, err = k.EgressServiceClient.K8sV1().EgressServices(es.Namespace).UpdateStatus(context.TODO(), es, metav1.UpdateOptions{})
 	return err
 }
+
+// CreateIPAMClaim creates the IPAMClaim
+func (k *KubeOVN) CreateIPAMClaim(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error) {
+	return k.IPAMClaimClient.K8sV1().IPAMClaims(ipamClaim.Namespace).Create(context.TODO(), ipamClaim, metav1.CreateOptions{})
+}
+
+// UpdateIPAMClaimStatus updates the status of the IPAMClaim
+func (k *KubeOVN) UpdateIPAMClaimStatus(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error) {
+	return k.IPAMClaimClient.K8sV1().IPAMClaims(ipamClaim.Namespace).UpdateStatus(context.TODO(), ipamClaim, metav1.UpdateOptions{})
+}
+
+// DeleteIPAMClaim deletes the IPAMClaim
+func (k *KubeOVN) DeleteIPAMClaim(namespace, name string) error {
+	return k.IPAMClaimClient.K8sV1().IPAMClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
@@ -9,6 +9,8 @@ import (
 
 	egressipv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1"
 
+	ipamclaimv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	mock "github.com/stretchr/testify/mock"
@@ -44,6 +46,29 @@ func (_m *InterfaceOVN) CreateCloudPrivateIPConfig(cloudPrivateIPConfig *v1.Clou
 	return r0, r1
 }
 
+// CreateIPAMClaim provides a mock function with given fields: ipamClaim
+func (_m *InterfaceOVN) CreateIPAMClaim(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error) {
+	ret := _m.Called(ipamClaim)
+
+	var r0 *ipamclaimv1.IPAMClaim
+	if rf, ok := ret.Get(0).(func(*ipamclaimv1.IPAMClaim) *ipamclaimv1.IPAMClaim); ok {
+		r0 = rf(ipamClaim)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ipamclaimv1.IPAMClaim)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ipamclaimv1.IPAMClaim) error); ok {
+		r1 = rf(ipamClaim)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteCloudPrivateIPConfig provides a mock function with given fields: name
 func (_m *InterfaceOVN) DeleteCloudPrivateIPConfig(name string) error {
 	ret := _m.Called(name)
@@ -58,6 +83,20 @@ func (_m *InterfaceOVN) DeleteCloudPrivateIPConfig(name string) error {
 	return r0
 }
 
+// DeleteIPAMClaim provides a mock function with given fields: namespace, name
+func (_m *InterfaceOVN) DeleteIPAMClaim(namespace string, name string) error {
+	ret := _m.Called(namespace, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(namespace, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Events provides a mock function with given fields:
 func (_m *InterfaceOVN) Events() corev1.EventInterface {
 	ret := _m.Called()
@@ -472,6 +511,29 @@ func (_m *InterfaceOVN) UpdateEgressServiceStatus(namespace string, name string,
 	return r0
 }
 
+// UpdateIPAMClaimStatus provides a mock function with given fields: ipamClaim
+func (_m *InterfaceOVN) UpdateIPAMClaimStatus(ipamClaim *ipamclaimv1.IPAMClaim) (*ipamclaimv1.IPAMClaim, error) {
+	ret := _m.Called(ipamClaim)
+
+	var r0 *ipamclaimv1.IPAMClaim
+	if rf, ok := ret.Get(0).(func(*ipamclaimv1.IPAMClaim) *ipamclaimv1.IPAMClaim); ok {
+		r0 = rf(ipamClaim)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ipamclaimv1.IPAMClaim)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ipamclaimv1.IPAMClaim) error); ok {
+		r1 = rf(ipamClaim)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateNodeStatus provides a mock function with given fields: node
 func (_m *InterfaceOVN) UpdateNodeStatus(node *apicorev1.Node) error {
 	ret := _m.Called(node)
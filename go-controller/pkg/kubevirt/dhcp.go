@@ -27,20 +27,27 @@ type dhcpConfigs struct {
 	V6 *nbdb.DHCPOptions
 }
 
-func EnsureDHCPOptionsForMigratablePod(controllerName string, nbClient libovsdbclient.Client, watchFactory *factory.WatchFactory, pod *corev1.Pod, ips []*net.IPNet, lsp *nbdb.LogicalSwitchPort) error {
+// EnsureDHCPOptionsForMigratablePod configures the DHCP options of the VM at
+// the logical switch port of the pod, and returns whether the port had none
+func EnsureDHCPOptionsForMigratablePod(controllerName string, nbClient libovsdbclient.Client, watchFactory *factory.WatchFactory, pod *corev1.Pod, ips []*net.IPNet, lsp *nbdb.LogicalSwitchPort) (bool, error) {
 	vmKey := ExtractVMNameFromPod(pod)
 	if vmKey == nil {
-		return fmt.Errorf("missing vm label at pod %s/%s", pod.Namespace, pod.Name)
+		return false, fmt.Errorf("missing vm label at pod %s/%s", pod.Namespace, pod.Name)
 	}
 	dhcpConfigs, err := composeDHCPConfigs(watchFactory, controllerName, *vmKey, ips)
 	if err != nil {
-		return fmt.Errorf("failed composing DHCP options: %v", err)
+		return false, fmt.Errorf("failed composing DHCP options: %v", err)
 	}
+	existingLSP, err := libovsdbops.GetLogicalSwitchPort(nbClient, &nbdb.LogicalSwitchPort{Name: lsp.Name})
+	if err != nil {
+		return false, fmt.Errorf("failed looking up logical switch port %s to add DHCP options: %v", lsp.Name, err)
+	}
+	created := existingLSP.Dhcpv4Options == nil && existingLSP.Dhcpv6Options == nil
 	err = libovsdbops.CreateOrUpdateDhcpOptions(nbClient, lsp, dhcpConfigs.V4, dhcpConfigs.V6)
 	if err != nil {
-		return fmt.Errorf("failed creation or updating OVN operations to add DHCP options: %v", err)
+		return false, fmt.Errorf("failed creation or updating OVN operations to add DHCP options: %v", err)
 	}
-	return nil
+	return created, nil
 }
 
 func composeDHCPConfigs(k8scli *factory.WatchFactory, controllerName string, vmKey ktypes.NamespacedName, podIPs []*net.IPNet) (*dhcpConfigs, error) {
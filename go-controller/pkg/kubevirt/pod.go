@@ -3,10 +3,14 @@ package kubevirt
 import (
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/util/retry"
 
 	kubevirtv1 "kubevirt.io/api/core/v1"
@@ -28,9 +32,9 @@ func IsPodLiveMigratable(pod *corev1.Pod) bool {
 	return ok
 }
 
-// findVMRelatedPods will return pods belong to the same vm annotated at pod and
+// FindVMRelatedPods will return pods belong to the same vm annotated at pod and
 // filter out the one at the function argument
-func findVMRelatedPods(client *factory.WatchFactory, pod *corev1.Pod) ([]*corev1.Pod, error) {
+func FindVMRelatedPods(client *factory.WatchFactory, pod *corev1.Pod) ([]*corev1.Pod, error) {
 	vmName, ok := pod.Labels[kubevirtv1.VirtualMachineNameLabel]
 	if !ok {
 		return nil, nil
@@ -56,34 +60,48 @@ func findVMRelatedPods(client *factory.WatchFactory, pod *corev1.Pod) ([]*corev1
 	return filteredOutVMPods, nil
 }
 
-// findPodAnnotation will return the the OVN pod
-// annotation from any other pod annotated with the same VM as pod
-func findPodAnnotation(client *factory.WatchFactory, pod *corev1.Pod, netInfo util.NetInfo, nadName string) (*util.PodAnnotation, error) {
-	vmPods, err := findVMRelatedPods(client, pod)
+// findPodAnnotation will return the the OVN pod annotation from any other pod
+// annotated with the same VM as pod, preferring the not completed ones, along
+// with the pod it belongs to
+func findPodAnnotation(client *factory.WatchFactory, pod *corev1.Pod, netInfo util.NetInfo, nadName string) (*corev1.Pod, *util.PodAnnotation, error) {
+	vmPods, err := FindVMRelatedPods(client, pod)
 	if err != nil {
-		return nil, fmt.Errorf("failed finding related pods for pod %s/%s when looking for network info: %v", pod.Namespace, pod.Name, err)
+		return nil, nil, fmt.Errorf("failed finding related pods for pod %s/%s when looking for network info: %v", pod.Namespace, pod.Name, err)
 	}
 	// virtual machine is not being live migrated so there is no other
 	// vm pods
 	if len(vmPods) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
+	var completedPod *corev1.Pod
+	var completedPodAnnotation *util.PodAnnotation
 	for _, vmPod := range vmPods {
 		podAnnotation, err := util.UnmarshalPodAnnotation(vmPod.Annotations, nadName)
-		if err == nil {
-			return podAnnotation, nil
+		if err != nil {
+			continue
 		}
+		if !util.PodCompleted(vmPod) {
+			return vmPod, podAnnotation, nil
+		}
+		if completedPod == nil {
+			completedPod, completedPodAnnotation = vmPod, podAnnotation
+		}
+	}
+	if completedPod != nil {
+		return completedPod, completedPodAnnotation, nil
 	}
-	return nil, fmt.Errorf("missing virtual machine pod annotation at stale pods for %s/%s", pod.Namespace, pod.Name)
+	return nil, nil, fmt.Errorf("missing virtual machine pod annotation at stale pods for %s/%s", pod.Namespace, pod.Name)
 }
 
 // EnsurePodAnnotationForVM will at live migration extract the ovn pod
 // annotations from the source vm pod and copy it
 // to the target vm pod so ip address follow vm during migration. This has to
 // done before creating the LSP to be sure that Address field get configured
-// correctly at the target VM pod LSP.
-func EnsurePodAnnotationForVM(watchFactory *factory.WatchFactory, kube *kube.KubeOVN, pod *corev1.Pod, netInfo util.NetInfo, nadName string) (*util.PodAnnotation, error) {
+// correctly at the target VM pod LSP. With multi chassis live migration the
+// copied annotation also points the target vm pod to the LSP it shares with
+// the source vm pod.
+func EnsurePodAnnotationForVM(watchFactory *factory.WatchFactory, kube *kube.KubeOVN, lsManager *logicalswitchmanager.LogicalSwitchManager, pod *corev1.Pod, netInfo util.NetInfo, nadName string) (*util.PodAnnotation, error) {
 	if !IsPodLiveMigratable(pod) {
 		return nil, nil
 	}
@@ -92,13 +110,17 @@ func EnsurePodAnnotationForVM(watchFactory *factory.WatchFactory, kube *kube.Kub
 		return podAnnotation, nil
 	}
 
-	podAnnotation, err := findPodAnnotation(watchFactory, pod, netInfo, nadName)
+	sourcePod, sourcePodAnnotation, err := findPodAnnotation(watchFactory, pod, netInfo, nadName)
 	if err != nil {
 		return nil, err
 	}
-	if podAnnotation == nil {
+	if sourcePodAnnotation == nil {
 		return nil, nil
 	}
+	// the annotation belongs to the informer cache, so copy it before
+	// pointing it to the shared LSP
+	podAnnotation := *sourcePodAnnotation
+	podAnnotation.LogicalSwitchPort = sharedLogicalSwitchPort(lsManager, netInfo, sourcePod, sourcePodAnnotation)
 
 	var modifiedPod *corev1.Pod
 	resultErr := retry.RetryOnConflict(util.OvnConflictBackoff, func() error {
@@ -109,18 +131,16 @@ func EnsurePodAnnotationForVM(watchFactory *factory.WatchFactory, kube *kube.Kub
 		}
 		// Informer cache should not be mutated, so get a copy of the object
 		modifiedPod = pod.DeepCopy()
-		if podAnnotation != nil {
-			modifiedPod.Annotations, err = util.MarshalPodAnnotation(modifiedPod.Annotations, podAnnotation, nadName)
-			if err != nil {
-				return err
-			}
+		modifiedPod.Annotations, err = util.MarshalPodAnnotation(modifiedPod.Annotations, &podAnnotation, nadName)
+		if err != nil {
+			return err
 		}
 		return kube.UpdatePodStatus(modifiedPod)
 	})
 	if resultErr != nil {
 		return nil, fmt.Errorf("failed to update labels and annotations on pod %s/%s: %v", pod.Namespace, pod.Name, resultErr)
 	}
-	return podAnnotation, nil
+	return &podAnnotation, nil
 }
 
 // IsMigratedSourcePodStale return false if the pod is live migratable,
@@ -132,7 +152,7 @@ func IsMigratedSourcePodStale(client *factory.WatchFactory, pod *corev1.Pod) (bo
 	if util.PodCompleted(pod) {
 		return true, nil
 	}
-	vmPods, err := findVMRelatedPods(client, pod)
+	vmPods, err := FindVMRelatedPods(client, pod)
 	if err != nil {
 		return false, fmt.Errorf("failed finding related pods for pod %s/%s when checking live migration left overs: %v", pod.Namespace, pod.Name, err)
 	}
@@ -316,3 +336,100 @@ func ZoneContainsPodSubnetOrUntracked(watchFactory *factory.WatchFactory, lsMana
 	// to a node
 	return hostSubnets, !util.IsContainedInAnyCIDR(annotation.IPs[0], hostSubnets...), nil
 }
+
+// IsPodAllowedForMigration returns true if the pod is live migratable and
+// connected to a network where its VM keeps its addresses when migrated to a
+// different node: the default network or a secondary layer2 network
+func IsPodAllowedForMigration(pod *corev1.Pod, netInfo util.NetInfo) bool {
+	if !IsPodLiveMigratable(pod) {
+		return false
+	}
+	return !netInfo.IsSecondary() || netInfo.TopologyType() == ovntypes.Layer2Topology
+}
+
+// IsPodMigrationTargetReady returns true if the pod is the target of a live
+// migration and is ready to receive the traffic of the VM
+func IsPodMigrationTargetReady(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp]
+	return ok
+}
+
+// MigrationTargetReadyTime returns the time KubeVirt marked the pod, target of
+// a live migration, ready to receive the traffic of the VM
+func MigrationTargetReadyTime(pod *corev1.Pod) (time.Time, bool) {
+	timestamp, ok := pod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp]
+	if !ok {
+		return time.Time{}, false
+	}
+	// strip the monotonic clock reading time.Time.String() may append
+	timestamp, _, _ = strings.Cut(timestamp, " m=")
+	for _, layout := range []string{migrationTargetReadyTimestampLayout, time.RFC3339Nano} {
+		if readyTime, err := time.Parse(layout, timestamp); err == nil {
+			return readyTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FindVMPodAnnotation returns the OVN pod annotation for the NAD of any other
+// not completed pod of the VM of the pod, or nil if there is none
+func FindVMPodAnnotation(podLister listers.PodLister, pod *corev1.Pod, nadName string) (*util.PodAnnotation, error) {
+	vmName, ok := pod.Labels[kubevirtv1.VirtualMachineNameLabel]
+	if !ok {
+		return nil, nil
+	}
+	vmPods, err := podLister.Pods(pod.Namespace).List(labels.SelectorFromSet(labels.Set{kubevirtv1.VirtualMachineNameLabel: vmName}))
+	if err != nil {
+		return nil, fmt.Errorf("failed finding related pods for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	for _, vmPod := range vmPods {
+		if vmPod.UID == pod.UID || util.PodCompleted(vmPod) {
+			continue
+		}
+		if podAnnotation, err := util.UnmarshalPodAnnotation(vmPod.Annotations, nadName); err == nil {
+			return podAnnotation, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsVirtualMachineRunningOnPod returns true if the VM of the live migratable
+// pod runs on it, and so its addresses have to be reachable through the pod
+// port. Through a live migration the VM runs on the source pod until the
+// target pod is ready to receive traffic.
+func IsVirtualMachineRunningOnPod(watchFactory *factory.WatchFactory, pod *corev1.Pod) (bool, error) {
+	if util.PodCompleted(pod) {
+		return false, nil
+	}
+	vmPods, err := FindVMRelatedPods(watchFactory, pod)
+	if err != nil {
+		return false, fmt.Errorf("failed finding related pods for pod %s/%s when looking for the running VM: %v", pod.Namespace, pod.Name, err)
+	}
+	vmPod := findVirtualMachinePod(append(vmPods, pod))
+	return vmPod != nil && vmPod.UID == pod.UID, nil
+}
+
+// findVirtualMachinePod returns the pod the VM runs on out of the pods of the
+// VM: the most recent target pod of a live migration ready to receive traffic,
+// or the oldest not completed pod otherwise.
+func findVirtualMachinePod(vmPods []*corev1.Pod) *corev1.Pod {
+	var oldestPod, readyTargetPod *corev1.Pod
+	for _, vmPod := range vmPods {
+		if util.PodCompleted(vmPod) {
+			continue
+		}
+		if oldestPod == nil || vmPod.CreationTimestamp.Before(&oldestPod.CreationTimestamp) {
+			oldestPod = vmPod
+		}
+		if !IsPodMigrationTargetReady(vmPod) {
+			continue
+		}
+		if readyTargetPod == nil || readyTargetPod.CreationTimestamp.Before(&vmPod.CreationTimestamp) {
+			readyTargetPod = vmPod
+		}
+	}
+	if readyTargetPod != nil {
+		return readyTargetPod
+	}
+	return oldestPod
+}
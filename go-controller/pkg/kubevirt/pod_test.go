@@ -0,0 +1,159 @@
+package kubevirt
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+)
+
+var _ = Describe("Kubevirt virtual machine pods", func() {
+	type vmPod struct {
+		name        string
+		node        string
+		age         time.Duration
+		completed   bool
+		targetReady bool
+	}
+	newVMPods := func(pods []vmPod) []*corev1.Pod {
+		now := time.Now()
+		vmPods := []*corev1.Pod{}
+		for _, p := range pods {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "namespace1",
+					Name:              p.name,
+					UID:               ktypes.UID(p.name),
+					CreationTimestamp: metav1.NewTime(now.Add(-p.age)),
+					Labels:            map[string]string{kubevirtv1.VirtualMachineNameLabel: "vm1"},
+					Annotations:       map[string]string{kubevirtv1.AllowPodBridgeNetworkLiveMigrationAnnotation: ""},
+				},
+				Spec: corev1.PodSpec{NodeName: p.node},
+			}
+			if p.completed {
+				pod.Status.Phase = corev1.PodSucceeded
+			}
+			if p.targetReady {
+				pod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp] = now.String()
+			}
+			vmPods = append(vmPods, pod)
+		}
+		return vmPods
+	}
+
+	DescribeTable("finds the pod the VM runs on",
+		func(pods []vmPod, expectedPod string) {
+			pod := findVirtualMachinePod(newVMPods(pods))
+			if expectedPod == "" {
+				Expect(pod).To(BeNil())
+				return
+			}
+			Expect(pod).NotTo(BeNil())
+			Expect(pod.Name).To(Equal(expectedPod))
+		},
+		Entry("with a single pod",
+			[]vmPod{{name: "source", age: time.Hour}},
+			"source",
+		),
+		Entry("with a target pod not ready yet",
+			[]vmPod{{name: "source", age: time.Hour}, {name: "target", age: time.Minute}},
+			"source",
+		),
+		Entry("with a target pod ready",
+			[]vmPod{{name: "source", age: time.Hour}, {name: "target", age: time.Minute, targetReady: true}},
+			"target",
+		),
+		Entry("with the source pod completed after the migration",
+			[]vmPod{{name: "source", age: time.Hour, completed: true}, {name: "target", age: time.Minute, targetReady: true}},
+			"target",
+		),
+		Entry("with a failed target pod",
+			[]vmPod{{name: "source", age: time.Hour}, {name: "target", age: time.Minute, completed: true}},
+			"source",
+		),
+		Entry("with a second migration not ready yet",
+			[]vmPod{
+				{name: "source", age: time.Hour, completed: true},
+				{name: "target1", age: 10 * time.Minute, targetReady: true},
+				{name: "target2", age: time.Minute},
+			},
+			"target1",
+		),
+		Entry("with a second migration ready",
+			[]vmPod{
+				{name: "target1", age: 10 * time.Minute, targetReady: true},
+				{name: "target2", age: time.Minute, targetReady: true},
+			},
+			"target2",
+		),
+		Entry("with all the pods completed",
+			[]vmPod{{name: "source", age: time.Hour, completed: true}},
+			"",
+		),
+	)
+
+	DescribeTable("binds the shared logical switch port to the chassis of the VM pods",
+		func(pods []vmPod, expectedChassis []string, expectedOptions map[string]string) {
+			chassis := logicalSwitchPortChassis(newVMPods(pods))
+			Expect(chassis).To(Equal(expectedChassis))
+			lsp := &nbdb.LogicalSwitchPort{Options: map[string]string{ActivationStrategyLSPOption: RARPActivationStrategy}}
+			SetLogicalSwitchPortChassis(lsp, chassis)
+			Expect(lsp.Options).To(Equal(expectedOptions))
+		},
+		Entry("with a single pod",
+			[]vmPod{{name: "source", node: "node1", age: time.Hour}},
+			[]string{"node1"},
+			map[string]string{RequestedChassisLSPOption: "node1"},
+		),
+		Entry("with a target pod not ready yet",
+			[]vmPod{{name: "target", node: "node2", age: time.Minute}, {name: "source", node: "node1", age: time.Hour}},
+			[]string{"node1", "node2"},
+			map[string]string{RequestedChassisLSPOption: "node1,node2", ActivationStrategyLSPOption: RARPActivationStrategy},
+		),
+		Entry("with a target pod ready",
+			[]vmPod{{name: "source", node: "node1", age: time.Hour}, {name: "target", node: "node2", age: time.Minute, targetReady: true}},
+			[]string{"node2", "node1"},
+			map[string]string{RequestedChassisLSPOption: "node2,node1", ActivationStrategyLSPOption: RARPActivationStrategy},
+		),
+		Entry("with the source pod completed after the migration",
+			[]vmPod{{name: "source", node: "node1", age: time.Hour, completed: true}, {name: "target", node: "node2", age: time.Minute, targetReady: true}},
+			[]string{"node2"},
+			map[string]string{RequestedChassisLSPOption: "node2"},
+		),
+	)
+
+	DescribeTable("reads the time the migration target pod was ready",
+		func(timestamp string, expectedTime time.Time, expectedOk bool) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if timestamp != "" {
+				pod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp] = timestamp
+			}
+			readyTime, ok := MigrationTargetReadyTime(pod)
+			Expect(ok).To(Equal(expectedOk))
+			Expect(readyTime.Equal(expectedTime)).To(BeTrue(), "expected %s, got %s", expectedTime, readyTime)
+		},
+		Entry("without timestamp", "", time.Time{}, false),
+		Entry("with a time string timestamp",
+			"2023-10-18 09:10:11.123456789 +0000 UTC",
+			time.Date(2023, 10, 18, 9, 10, 11, 123456789, time.UTC), true,
+		),
+		Entry("with a time string timestamp with monotonic clock reading",
+			"2023-10-18 09:10:11.123456789 +0000 UTC m=+12.345678901",
+			time.Date(2023, 10, 18, 9, 10, 11, 123456789, time.UTC), true,
+		),
+		Entry("with a RFC3339 timestamp",
+			"2023-10-18T09:10:11Z",
+			time.Date(2023, 10, 18, 9, 10, 11, 0, time.UTC), true,
+		),
+		Entry("with a wrong timestamp", "yesterday", time.Time{}, false),
+	)
+})
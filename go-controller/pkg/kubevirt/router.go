@@ -2,6 +2,7 @@ package kubevirt
 
 import (
 	"fmt"
+	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
 	utilnet "k8s.io/utils/net"
@@ -65,28 +66,32 @@ func DeleteRoutingForMigratedPod(nbClient libovsdbclient.Client, pod *corev1.Pod
 //
 // Both:
 //   - static route with VM ip as dst-ip prefix and output port the LRP pointing to the VM's node switch
-func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory, nbClient libovsdbclient.Client, lsManager *logicalswitchmanager.LogicalSwitchManager, pod *corev1.Pod, nadName string) error {
+//
+// It returns true if the static routes to the VM were not there yet, meaning
+// the VM traffic has just been routed to the pod.
+func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory, nbClient libovsdbclient.Client, lsManager *logicalswitchmanager.LogicalSwitchManager, pod *corev1.Pod, nadName string) (bool, error) {
 	vmReady, err := virtualMachineReady(watchFactory, pod)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if !vmReady {
-		return nil
+		return false, nil
 	}
 	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName)
 	if err != nil {
-		return fmt.Errorf("failed reading local pod annotation: %v", err)
+		return false, fmt.Errorf("failed reading local pod annotation: %v", err)
 	}
 
 	nodeOwningSubnet, _ := ZoneContainsPodSubnet(lsManager, podAnnotation.IPs)
 	vmRunningAtNodeOwningSubnet := nodeOwningSubnet == pod.Spec.NodeName
-	if vmRunningAtNodeOwningSubnet {
+	if vmRunningAtNodeOwningSubnet || podAnnotation.LogicalSwitchPort != "" {
 		// Point to point routing is no longer needed if vm
-		// is running at the node that owns the subnet
+		// is running at the node that owns the subnet, or bound
+		// to an LSP of the switch of that node
 		if err := DeleteRoutingForMigratedPod(nbClient, pod); err != nil {
-			return fmt.Errorf("failed configuring pod routing when deleting stale static routes or policies for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			return false, fmt.Errorf("failed configuring pod routing when deleting stale static routes or policies for pod %s/%s: %v", pod.Namespace, pod.Name, err)
 		}
-		return nil
+		return false, nil
 	}
 
 	// For interconnect at static route with a cluster-wide src-ip address is
@@ -95,15 +100,16 @@ func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory,
 		// NOTE: EIP & ESVC use same route and if this is already present thanks to those features,
 		// this will be a no-op
 		if err := libovsdbutil.CreateDefaultRouteToExternal(nbClient, pod.Spec.NodeName); err != nil {
-			return err
+			return false, err
 		}
 	}
 
 	lrpName := types.GWRouterToJoinSwitchPrefix + types.GWRouterPrefix + pod.Spec.NodeName
 	lrpAddresses, err := libovsdbutil.GetLRPAddrs(nbClient, lrpName)
 	if err != nil {
-		return fmt.Errorf("failed configuring pod routing when reading LRP %s addresses: %v", lrpName, err)
+		return false, fmt.Errorf("failed configuring pod routing when reading LRP %s addresses: %v", lrpName, err)
 	}
+	routed := false
 	for _, podIP := range podAnnotation.IPs {
 		podAddress := podIP.IP.String()
 
@@ -112,7 +118,7 @@ func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory,
 			ipFamily := utilnet.IPFamilyOfCIDR(podIP)
 			nodeGRAddress, err := util.MatchFirstIPNetFamily(ipFamily == utilnet.IPv6, lrpAddresses)
 			if err != nil {
-				return err
+				return false, err
 			}
 
 			// adds a policy so that a migrated pods egress traffic
@@ -132,7 +138,7 @@ func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory,
 			if err := libovsdbops.CreateOrUpdateLogicalRouterPolicyWithPredicate(nbClient, types.OVNClusterRouter, &egressPolicy, func(item *nbdb.LogicalRouterPolicy) bool {
 				return item.Priority == egressPolicy.Priority && item.Match == egressPolicy.Match && item.Action == egressPolicy.Action
 			}); err != nil {
-				return fmt.Errorf("failed adding point to point policy for pod %s/%s : %v", pod.Namespace, pod.Name, err)
+				return false, fmt.Errorf("failed adding point to point policy for pod %s/%s : %v", pod.Namespace, pod.Name, err)
 			}
 		}
 		// Add a route for reroute ingress traffic to the VM port since
@@ -149,14 +155,13 @@ func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory,
 				NamespaceExternalIDsKey:      pod.Namespace,
 			},
 		}
-		if err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(nbClient, types.OVNClusterRouter, &ingressRoute, func(item *nbdb.LogicalRouterStaticRoute) bool {
-			matches := item.IPPrefix == ingressRoute.IPPrefix && item.Nexthop == ingressRoute.Nexthop && item.Policy != nil && *item.Policy == *ingressRoute.Policy
-			return matches
-		}); err != nil {
-			return fmt.Errorf("failed adding static route: %v", err)
+		added, err := ensureVirtualMachineRoute(nbClient, &ingressRoute)
+		if err != nil {
+			return false, fmt.Errorf("failed adding static route: %v", err)
 		}
+		routed = routed || added
 	}
-	return nil
+	return routed, nil
 }
 
 // EnsureRemoteZonePodAddressesToNodeRoute will add static routes when live
@@ -164,57 +169,61 @@ func EnsureLocalZonePodAddressesToNodeRoute(watchFactory *factory.WatchFactory,
 // port of the node where the pod is running:
 //   - A dst-ip with live migrated pod ip as prefix and nexthop the pod's
 //     current node transit switch port.
-func EnsureRemoteZonePodAddressesToNodeRoute(controllerName string, watchFactory *factory.WatchFactory, nbClient libovsdbclient.Client, lsManager *logicalswitchmanager.LogicalSwitchManager, pod *corev1.Pod, nadName string) error {
+//
+// It returns true if the static routes to the VM were not there yet, meaning
+// the VM traffic has just been routed to the pod.
+func EnsureRemoteZonePodAddressesToNodeRoute(controllerName string, watchFactory *factory.WatchFactory, nbClient libovsdbclient.Client, lsManager *logicalswitchmanager.LogicalSwitchManager, pod *corev1.Pod, nadName string) (bool, error) {
 	vmReady, err := virtualMachineReady(watchFactory, pod)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if !vmReady {
-		return nil
+		return false, nil
 	}
 	// DHCPOptions are only needed at the node is running the VM
 	// at that's the local zone node not the remote zone
 	if err := DeleteDHCPOptions(nbClient, pod); err != nil {
-		return err
+		return false, err
 	}
 
 	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName)
 	if err != nil {
-		return fmt.Errorf("failed reading remote pod annotation: %v", err)
+		return false, fmt.Errorf("failed reading remote pod annotation: %v", err)
 	}
 
 	vmRunningAtNodeOwningSubnet, err := nodeContainsPodSubnet(watchFactory, pod.Spec.NodeName, podAnnotation, nadName)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if vmRunningAtNodeOwningSubnet {
 		// Point to point routing is no longer needed if vm
 		// is running at the node with VM's subnet
 		if err := DeleteRoutingForMigratedPod(nbClient, pod); err != nil {
-			return err
+			return false, err
 		}
-		return nil
+		return false, nil
 	} else {
 		// Since we are at remote zone we should not have local zone point to
 		// to point routing
 		if err := DeleteRoutingForMigratedPodWithZone(nbClient, pod, OvnLocalZone); err != nil {
-			return err
+			return false, err
 		}
 	}
 
 	node, err := watchFactory.GetNode(pod.Spec.NodeName)
 	if err != nil {
-		return err
+		return false, err
 	}
 	transitSwitchPortAddrs, err := util.ParseNodeTransitSwitchPortAddrs(node)
 	if err != nil {
-		return err
+		return false, err
 	}
+	routed := false
 	for _, podIP := range podAnnotation.IPs {
 		ipFamily := utilnet.IPFamilyOfCIDR(podIP)
 		transitSwitchPortAddr, err := util.MatchFirstIPNetFamily(ipFamily == utilnet.IPv6, transitSwitchPortAddrs)
 		if err != nil {
-			return err
+			return false, err
 		}
 		route := nbdb.LogicalRouterStaticRoute{
 			IPPrefix: podIP.IP.String(),
@@ -226,14 +235,32 @@ func EnsureRemoteZonePodAddressesToNodeRoute(controllerName string, watchFactory
 				NamespaceExternalIDsKey:      pod.Namespace,
 			},
 		}
-		if err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(nbClient, types.OVNClusterRouter, &route, func(item *nbdb.LogicalRouterStaticRoute) bool {
-			matches := item.IPPrefix == route.IPPrefix && item.Nexthop == route.Nexthop && item.Policy != nil && *item.Policy == *route.Policy
-			return matches
-		}); err != nil {
-			return fmt.Errorf("failed adding static route to remote pod: %v", err)
+		added, err := ensureVirtualMachineRoute(nbClient, &route)
+		if err != nil {
+			return false, fmt.Errorf("failed adding static route to remote pod: %v", err)
 		}
+		routed = routed || added
 	}
-	return nil
+	return routed, nil
+}
+
+// ensureVirtualMachineRoute creates or replaces the static route to a VM
+// address at ovn_cluster_router and returns true if no route with the same
+// prefix, nexthop and output port was there already.
+func ensureVirtualMachineRoute(nbClient libovsdbclient.Client, route *nbdb.LogicalRouterStaticRoute) (bool, error) {
+	sameRoute := func(item *nbdb.LogicalRouterStaticRoute) bool {
+		return item.IPPrefix == route.IPPrefix && item.Nexthop == route.Nexthop && item.Policy != nil && *item.Policy == *route.Policy
+	}
+	existingRoutes, err := libovsdbops.FindLogicalRouterStaticRoutesWithPredicate(nbClient, func(item *nbdb.LogicalRouterStaticRoute) bool {
+		return sameRoute(item) && reflect.DeepEqual(item.OutputPort, route.OutputPort)
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(nbClient, types.OVNClusterRouter, route, sameRoute); err != nil {
+		return false, err
+	}
+	return len(existingRoutes) == 0, nil
 }
 
 func virtualMachineReady(watchFactory *factory.WatchFactory, pod *corev1.Pod) (bool, error) {
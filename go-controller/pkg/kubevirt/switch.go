@@ -1,9 +1,21 @@
 package kubevirt
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	logicalswitchmanager "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/logical_switch_manager"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 )
 
 const (
@@ -18,6 +30,18 @@ const (
 	// ARPProxyMAC is a generated mac from ARPProxyIPv4, it's generated with
 	// the mechanism at `util.IPAddrToHWAddr`
 	ARPProxyMAC = "0a:58:a9:fe:01:01"
+
+	// RequestedChassisLSPOption is the LSP option with the comma separated
+	// chassis the LSP is bound to, the first one being the main chassis
+	RequestedChassisLSPOption = "requested-chassis"
+
+	// ActivationStrategyLSPOption is the LSP option configuring how the LSP
+	// is activated on the additional chassis it is bound to
+	ActivationStrategyLSPOption = "activation-strategy"
+
+	// RARPActivationStrategy activates the LSP on an additional chassis once
+	// the VM sends a RARP from it after being live migrated
+	RARPActivationStrategy = "rarp"
 )
 
 // ComposeARPProxyLSPOption returns the "arp_proxy" field needed at router type
@@ -53,3 +77,209 @@ func ComposeARPProxyLSPOption() string {
 	}
 	return strings.Join(arpProxy, " ")
 }
+
+// GetPodLogicalPortName returns the name of the default network LSP of the
+// pod: the LSP it shares with the other pods of its VM when live migrated
+// with multi chassis port binding, or its own LSP otherwise.
+func GetPodLogicalPortName(pod *corev1.Pod) string {
+	if IsPodLiveMigratable(pod) {
+		podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, ovntypes.DefaultNetworkName)
+		if err == nil && podAnnotation.LogicalSwitchPort != "" {
+			return podAnnotation.LogicalSwitchPort
+		}
+	}
+	return util.GetLogicalPortName(pod.Namespace, pod.Name)
+}
+
+// sharedLogicalSwitchPort returns the LSP the target pod of a live migration
+// shares with the source pod, given the source pod and its annotation, or an
+// empty string if the target pod gets an LSP of its own. An LSP can only be
+// bound to the chassis of both nodes if it belongs to the switch that owns the
+// VM subnet in this zone, so the LSP of the source pod is shared only if it
+// is on that switch.
+func sharedLogicalSwitchPort(lsManager *logicalswitchmanager.LogicalSwitchManager, netInfo util.NetInfo, sourcePod *corev1.Pod, sourcePodAnnotation *util.PodAnnotation) string {
+	if !config.OVNKubernetesFeature.EnableMultiChassisLiveMigration || netInfo.IsSecondary() {
+		return ""
+	}
+	switchName, zoneContainsPodSubnet := ZoneContainsPodSubnet(lsManager, sourcePodAnnotation.IPs)
+	if !zoneContainsPodSubnet {
+		return ""
+	}
+	if sourcePodAnnotation.LogicalSwitchPort != "" {
+		return sourcePodAnnotation.LogicalSwitchPort
+	}
+	if sourcePod.Spec.NodeName != switchName {
+		return ""
+	}
+	return util.GetLogicalPortName(sourcePod.Namespace, sourcePod.Name)
+}
+
+// FindLogicalSwitchPort returns the default network LSP a live migratable pod
+// shares with other pods of its VM and the switch owning it, or empty strings
+// if the pod has an LSP of its own. It is known before the target pod of a
+// live migration is annotated, from the annotation of the source pod.
+func FindLogicalSwitchPort(watchFactory *factory.WatchFactory, lsManager *logicalswitchmanager.LogicalSwitchManager, netInfo util.NetInfo, pod *corev1.Pod) (string, string, error) {
+	if !IsPodLiveMigratable(pod) || netInfo.IsSecondary() {
+		return "", "", nil
+	}
+	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, ovntypes.DefaultNetworkName)
+	if err != nil {
+		if !util.IsAnnotationNotSetError(err) {
+			return "", "", fmt.Errorf("failed reading pod annotation of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		sourcePod, sourcePodAnnotation, err := findPodAnnotation(watchFactory, pod, netInfo, ovntypes.DefaultNetworkName)
+		if err != nil {
+			return "", "", err
+		}
+		if sourcePodAnnotation == nil {
+			return "", "", nil
+		}
+		podAnnotation = &util.PodAnnotation{
+			IPs:               sourcePodAnnotation.IPs,
+			LogicalSwitchPort: sharedLogicalSwitchPort(lsManager, netInfo, sourcePod, sourcePodAnnotation),
+		}
+	}
+	if podAnnotation.LogicalSwitchPort == "" {
+		return "", "", nil
+	}
+	switchName, zoneContainsPodSubnet := ZoneContainsPodSubnet(lsManager, podAnnotation.IPs)
+	if !zoneContainsPodSubnet {
+		return "", "", fmt.Errorf("missing switch owning the subnet of LSP %s shared by pod %s/%s",
+			podAnnotation.LogicalSwitchPort, pod.Namespace, pod.Name)
+	}
+	return podAnnotation.LogicalSwitchPort, switchName, nil
+}
+
+// findLogicalSwitchPortPods returns the other not completed pods of the VM of
+// the live migratable pod bound to the default network LSP with the given name
+func findLogicalSwitchPortPods(watchFactory *factory.WatchFactory, pod *corev1.Pod, portName string) ([]*corev1.Pod, error) {
+	vmPods, err := FindVMRelatedPods(watchFactory, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding related pods for pod %s/%s when looking for the pods sharing LSP %s: %v",
+			pod.Namespace, pod.Name, portName, err)
+	}
+	portPods := []*corev1.Pod{}
+	for _, vmPod := range vmPods {
+		if util.PodCompleted(vmPod) || !util.PodScheduled(vmPod) || GetPodLogicalPortName(vmPod) != portName {
+			continue
+		}
+		portPods = append(portPods, vmPod)
+	}
+	return portPods, nil
+}
+
+// logicalSwitchPortChassis returns the chassis of the nodes of the not
+// completed pods bound to an LSP: the one of the pod the VM runs on first,
+// followed by the others by creation time.
+func logicalSwitchPortChassis(portPods []*corev1.Pod) []string {
+	vmPod := findVirtualMachinePod(portPods)
+	if vmPod == nil {
+		return nil
+	}
+	sort.SliceStable(portPods, func(i, j int) bool {
+		return portPods[i].CreationTimestamp.Before(&portPods[j].CreationTimestamp)
+	})
+	chassis := []string{vmPod.Spec.NodeName}
+	for _, portPod := range portPods {
+		if portPod.UID == vmPod.UID || util.PodCompleted(portPod) || util.SliceHasStringItem(chassis, portPod.Spec.NodeName) {
+			continue
+		}
+		chassis = append(chassis, portPod.Spec.NodeName)
+	}
+	return chassis
+}
+
+// FindLogicalSwitchPortChassis returns the chassis the default network LSP
+// with the given name of a live migratable pod has to be bound to, which are
+// the ones of the nodes of the pods of its VM sharing it.
+func FindLogicalSwitchPortChassis(watchFactory *factory.WatchFactory, pod *corev1.Pod, portName string) ([]string, error) {
+	portPods, err := findLogicalSwitchPortPods(watchFactory, pod, portName)
+	if err != nil {
+		return nil, err
+	}
+	if !util.PodCompleted(pod) {
+		portPods = append(portPods, pod)
+	}
+	return logicalSwitchPortChassis(portPods), nil
+}
+
+// SetLogicalSwitchPortChassis sets the options binding the LSP to the chassis.
+// With more than one chassis the LSP is bound to all of them, and activated on
+// the additional ones on RARP so that traffic only reaches the VM through
+// the chassis it runs on.
+func SetLogicalSwitchPortChassis(lsp *nbdb.LogicalSwitchPort, chassis []string) {
+	if lsp.Options == nil {
+		lsp.Options = map[string]string{}
+	}
+	lsp.Options[RequestedChassisLSPOption] = strings.Join(chassis, ",")
+	delete(lsp.Options, ActivationStrategyLSPOption)
+	if len(chassis) > 1 {
+		lsp.Options[ActivationStrategyLSPOption] = RARPActivationStrategy
+	}
+}
+
+// updateLogicalSwitchPortChassis binds the existing LSP to the chassis
+func updateLogicalSwitchPortChassis(nbClient libovsdbclient.Client, portName string, chassis []string) error {
+	lsp := &nbdb.LogicalSwitchPort{Name: portName}
+	SetLogicalSwitchPortChassis(lsp, chassis)
+	if len(chassis) < 2 {
+		// an empty value unsets the option
+		lsp.Options[ActivationStrategyLSPOption] = ""
+	}
+	err := libovsdbops.UpdateLogicalSwitchPortSetOptions(nbClient, lsp)
+	if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+		return fmt.Errorf("failed binding LSP %s to chassis %v: %w", portName, chassis, err)
+	}
+	return nil
+}
+
+// EnsureLogicalSwitchPortChassis binds the default network LSP of a live
+// migratable pod to the chassis of the nodes of the pods of its VM sharing it,
+// as they change through a live migration.
+func EnsureLogicalSwitchPortChassis(nbClient libovsdbclient.Client, watchFactory *factory.WatchFactory, pod *corev1.Pod) error {
+	if !IsPodLiveMigratable(pod) || util.PodCompleted(pod) {
+		return nil
+	}
+	portName := GetPodLogicalPortName(pod)
+	chassis, err := FindLogicalSwitchPortChassis(watchFactory, pod, portName)
+	if err != nil {
+		return err
+	}
+	return updateLogicalSwitchPortChassis(nbClient, portName, chassis)
+}
+
+// ReleaseLogicalSwitchPort unbinds the default network LSP of a deleted live
+// migratable pod from its node. It returns true if no other pod of its VM
+// shares the LSP, so that it can be deleted; otherwise the LSP is bound to
+// the chassis of the nodes of those pods and has to be kept.
+func ReleaseLogicalSwitchPort(nbClient libovsdbclient.Client, watchFactory *factory.WatchFactory, pod *corev1.Pod) (bool, error) {
+	if !IsPodLiveMigratable(pod) {
+		return true, nil
+	}
+	portName := GetPodLogicalPortName(pod)
+	portPods, err := findLogicalSwitchPortPods(watchFactory, pod, portName)
+	if err != nil {
+		return false, err
+	}
+	if len(portPods) == 0 {
+		return true, nil
+	}
+	if err := updateLogicalSwitchPortChassis(nbClient, portName, logicalSwitchPortChassis(portPods)); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// IsLogicalSwitchPortShared returns true if the default network LSP of the
+// live migratable pod is shared with other not completed pods of its VM, in
+// which case the LSP and its configuration outlive the pod.
+func IsLogicalSwitchPortShared(watchFactory *factory.WatchFactory, pod *corev1.Pod) (bool, error) {
+	if !IsPodLiveMigratable(pod) {
+		return false, nil
+	}
+	portPods, err := findLogicalSwitchPortPods(watchFactory, pod, GetPodLogicalPortName(pod))
+	if err != nil {
+		return false, err
+	}
+	return len(portPods) > 0, nil
+}
@@ -11,4 +11,13 @@ const (
 
 	NamespaceExternalIDsKey      = "k8s.ovn.org/namespace"
 	VirtualMachineExternalIDsKey = "k8s.ovn.org/vm"
+
+	// layout of the time.Time.String() timestamps KubeVirt annotates the
+	// migration target pods with
+	migrationTargetReadyTimestampLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+	// reasons of the events posted on the pods of the VMs
+	DHCPOptionsConfiguredReason     = "DHCPOptionsConfigured"
+	ARPProxyConfiguredReason        = "ARPProxyConfigured"
+	MigrationRoutesConfiguredReason = "MigrationRoutesConfigured"
 )
@@ -23,6 +23,7 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/sbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/fsnotify/fsnotify.v1"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -224,6 +225,83 @@ func NewNBClientWithConfig(cfg config.OvnAuthConfig, promRegistry prometheus.Reg
 	return c, nil
 }
 
+// NewOVSClient creates a new openvswitch Database client
+func NewOVSClient(stopCh <-chan struct{}) (client.Client, error) {
+	cfg := config.OvnAuthConfig{
+		Scheme:  config.OvnDBSchemeUnix,
+		Address: "unix:/var/run/openvswitch/db.sock",
+	}
+	return NewOVSClientWithConfig(cfg, prometheus.DefaultRegisterer, stopCh)
+}
+
+// NewOVSClientWithConfig creates a new openvswitch Database client with the provided configuration
+func NewOVSClientWithConfig(cfg config.OvnAuthConfig, promRegistry prometheus.Registerer, stopCh <-chan struct{}) (client.Client, error) {
+	dbModel, err := vswitchd.FullDatabaseModel()
+	if err != nil {
+		return nil, err
+	}
+
+	enableMetricsOption := client.WithMetricsRegistryNamespaceSubsystem(promRegistry, "ovnkube",
+		"node_libovsdb")
+
+	c, err := newClient(cfg, dbModel, stopCh, enableMetricsOption)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), types.OVSDBTimeout*2)
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	// Only monitor the columns we configure, the statistics and status
+	// columns are updated by ovs-vswitchd every few seconds
+	openvSwitch := vswitchd.OpenvSwitch{}
+	bridge := vswitchd.Bridge{}
+	port := vswitchd.Port{}
+	iface := vswitchd.Interface{}
+	_, err = c.Monitor(ctx,
+		c.NewMonitor(
+			client.WithTable(&openvSwitch,
+				&openvSwitch.Bridges,
+				&openvSwitch.ExternalIDs,
+				&openvSwitch.OtherConfig,
+			),
+			client.WithTable(&bridge,
+				&bridge.Name,
+				&bridge.Ports,
+				&bridge.ExternalIDs,
+				&bridge.OtherConfig,
+			),
+			client.WithTable(&port,
+				&port.Name,
+				&port.Interfaces,
+				&port.ExternalIDs,
+				&port.OtherConfig,
+				&port.QOS,
+			),
+			client.WithTable(&iface,
+				&iface.Name,
+				&iface.Type,
+				&iface.ExternalIDs,
+				&iface.OtherConfig,
+				&iface.Options,
+				&iface.Ofport,
+				&iface.IngressPolicingRate,
+				&iface.IngressPolicingBurst,
+			),
+			client.WithTable(&vswitchd.QoS{}),
+		),
+	)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
 func createTLSConfig(certFile, privKeyFile, caCertFile, serverName string) (*tls.Config, error) {
 	cert, err := tls.LoadX509KeyPair(certFile, privKeyFile)
 	if err != nil {
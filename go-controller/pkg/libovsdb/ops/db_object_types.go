@@ -30,6 +30,9 @@ const (
 	NetpolNodeOwnerType         ownerType = "NetpolNode"
 	NetpolNamespaceOwnerType    ownerType = "NetpolNamespace"
 	VirtualMachineOwnerType     ownerType = "VirtualMachine"
+	SecondaryNetworkOwnerType   ownerType = "SecondaryNetwork"
+	GatewayUplinkOwnerType      ownerType = "GatewayUplink"
+	PodVLANOwnerType            ownerType = "PodVLAN"
 	// NetworkPolicyPortIndexOwnerType is the old version of NetworkPolicyOwnerType, kept for sync only
 	NetworkPolicyPortIndexOwnerType ownerType = "NetworkPolicyPortIndexOwnerType"
 	// owner extra IDs, make sure to define only 1 ExternalIDKey for every string value
@@ -44,6 +47,7 @@ const (
 	RuleIndex             ExternalIDKey = "rule-index"
 	CIDRKey               ExternalIDKey = types.OvnK8sPrefix + "/cidr"
 	PortPolicyProtocolKey ExternalIDKey = "port-policy-protocol"
+	GatewayUplinkKey      ExternalIDKey = "gateway-uplink"
 )
 
 // ObjectIDsTypes should only be created here
@@ -123,6 +127,14 @@ var AddressSetEgressService = newObjectIDsType(addressSet, EgressServiceOwnerTyp
 	AddressSetIPFamilyKey,
 })
 
+var AddressSetGatewayUplink = newObjectIDsType(addressSet, GatewayUplinkOwnerType, []ExternalIDKey{
+	// nodeName
+	ObjectNameKey,
+	// uplink name
+	GatewayUplinkKey,
+	AddressSetIPFamilyKey,
+})
+
 var ACLAdminNetworkPolicy = newObjectIDsType(acl, AdminNetworkPolicyOwnerType, []ExternalIDKey{
 	// anp name
 	ObjectNameKey,
@@ -233,6 +245,20 @@ var ACLEgressFirewall = newObjectIDsType(acl, EgressFirewallOwnerType, []Externa
 	RuleIndex,
 })
 
+var ACLSecondaryNetworkVLANTrunk = newObjectIDsType(acl, SecondaryNetworkOwnerType, []ExternalIDKey{
+	// network name
+	ObjectNameKey,
+	// egress or ingress
+	PolicyDirectionKey,
+})
+
+var ACLPodVLAN = newObjectIDsType(acl, PodVLANOwnerType, []ExternalIDKey{
+	// logical switch port name
+	ObjectNameKey,
+	// egress or ingress
+	PolicyDirectionKey,
+})
+
 var VirtualMachineDHCPOptions = newObjectIDsType(dhcpOptions, VirtualMachineOwnerType, []ExternalIDKey{
 	// We can have multiple VMs with same CIDR they  may have different
 	// hostname.
@@ -241,3 +267,10 @@ var VirtualMachineDHCPOptions = newObjectIDsType(dhcpOptions, VirtualMachineOwne
 	// CIDR field from DHCPOptions with ":" replaced by "."
 	CIDRKey,
 })
+
+var SecondaryNetworkDHCPOptions = newObjectIDsType(dhcpOptions, SecondaryNetworkOwnerType, []ExternalIDKey{
+	// network name
+	ObjectNameKey,
+	// CIDR field from DHCPOptions with ":" replaced by "."
+	CIDRKey,
+})
@@ -69,6 +69,19 @@ func DeleteDHCPOptions(nbClient libovsdbclient.Client, dhcpOptions *nbdb.DHCPOpt
 
 }
 
+// DeleteDHCPOptionsWithPredicateOps returns the operations to delete the
+// DHCPOptions matching the predicate
+func DeleteDHCPOptionsWithPredicateOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, p DHCPOptionsPredicate) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		Model:          &nbdb.DHCPOptions{},
+		ModelPredicate: p,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+	m := newModelClient(nbClient)
+	return m.DeleteOps(ops, opModel)
+}
+
 func DeleteDHCPOptionsWithPredicate(nbClient libovsdbclient.Client, p DHCPOptionsPredicate) error {
 	opModels := []operationModel{}
 	opModel := operationModel{
@@ -137,3 +137,60 @@ func ListLoadBalancers(nbClient libovsdbclient.Client) ([]*nbdb.LoadBalancer, er
 	err := nbClient.List(ctx, &lbs)
 	return lbs, err
 }
+
+type loadBalancerHealthCheckPredicate func(*nbdb.LoadBalancerHealthCheck) bool
+
+// CreateOrUpdateLoadBalancerHealthChecksOps creates or updates the provided
+// health checks of the provided load balancer, and sets them as the load
+// balancer health checks. Existing health checks are looked up by load
+// balancer name and vip. The load balancer itself needs to be created or
+// updated afterwards for the health check references to be set in the
+// database.
+func CreateOrUpdateLoadBalancerHealthChecksOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, lb *nbdb.LoadBalancer, hcs ...*nbdb.LoadBalancerHealthCheck) ([]libovsdb.Operation, error) {
+	lb.HealthCheck = make([]string, 0, len(hcs))
+	opModels := make([]operationModel, 0, len(hcs))
+	for i := range hcs {
+		// can't use i in the predicate, for loop replaces it in-memory
+		hc := hcs[i]
+		opModel := operationModel{
+			Model: hc,
+			ModelPredicate: func(item *nbdb.LoadBalancerHealthCheck) bool {
+				return item.Vip == hc.Vip &&
+					item.ExternalIDs[types.LoadBalancerNameExternalID] == hc.ExternalIDs[types.LoadBalancerNameExternalID]
+			},
+			OnModelUpdates: []interface{}{&hc.Options, &hc.ExternalIDs},
+			DoAfter:        func() { lb.HealthCheck = append(lb.HealthCheck, hc.UUID) },
+			ErrNotFound:    false,
+			BulkOp:         false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	modelClient := newModelClient(nbClient)
+	return modelClient.CreateOrUpdateOps(ops, opModels...)
+}
+
+// DeleteLoadBalancerHealthChecksWithPredicateOps looks up load balancer health
+// checks from the cache based on a given predicate and returns the ops to
+// delete them
+func DeleteLoadBalancerHealthChecksWithPredicateOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, p loadBalancerHealthCheckPredicate) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		Model:          &nbdb.LoadBalancerHealthCheck{},
+		ModelPredicate: p,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	modelClient := newModelClient(nbClient)
+	return modelClient.DeleteOps(ops, opModel)
+}
+
+// ListLoadBalancerHealthChecks looks up all load balancer health checks from
+// the cache
+func ListLoadBalancerHealthChecks(nbClient libovsdbclient.Client) ([]*nbdb.LoadBalancerHealthCheck, error) {
+	hcs := []*nbdb.LoadBalancerHealthCheck{}
+	ctx, cancel := context.WithTimeout(context.Background(), types.OVSDBTimeout)
+	defer cancel()
+	err := nbClient.List(ctx, &hcs)
+	return hcs, err
+}
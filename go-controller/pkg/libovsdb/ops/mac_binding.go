@@ -2,6 +2,7 @@ package ops
 
 import (
 	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
 
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 )
@@ -39,3 +40,20 @@ func DeleteStaticMacBindings(nbClient libovsdbclient.Client, smbs ...*nbdb.Stati
 	m := newModelClient(nbClient)
 	return m.Delete(opModels...)
 }
+
+type staticMACBindingPredicate func(*nbdb.StaticMACBinding) bool
+
+// DeleteStaticMacBindingsWithPredicateOps returns the operations to delete
+// the static mac bindings matching the provided predicate
+func DeleteStaticMacBindingsWithPredicateOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation,
+	p staticMACBindingPredicate) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		Model:          &nbdb.StaticMACBinding{},
+		ModelPredicate: p,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := newModelClient(nbClient)
+	return m.DeleteOps(ops, opModel)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/sbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 )
 
 func getUUID(model model.Model) string {
@@ -28,6 +29,8 @@ func getUUID(model model.Model) string {
 		return t.UUID
 	case *nbdb.LoadBalancerGroup:
 		return t.UUID
+	case *nbdb.LoadBalancerHealthCheck:
+		return t.UUID
 	case *nbdb.LogicalRouter:
 		return t.UUID
 	case *nbdb.LogicalRouterPolicy:
@@ -70,6 +73,18 @@ func getUUID(model model.Model) string {
 		return t.UUID
 	case *nbdb.DHCPOptions:
 		return t.UUID
+	case *vswitchd.OpenvSwitch:
+		return t.UUID
+	case *vswitchd.Bridge:
+		return t.UUID
+	case *vswitchd.Port:
+		return t.UUID
+	case *vswitchd.Interface:
+		return t.UUID
+	case *vswitchd.QoS:
+		return t.UUID
+	case *vswitchd.Queue:
+		return t.UUID
 	default:
 		panic(fmt.Sprintf("getUUID: unknown model %T", t))
 	}
@@ -91,6 +106,8 @@ func setUUID(model model.Model, uuid string) {
 		t.UUID = uuid
 	case *nbdb.LoadBalancerGroup:
 		t.UUID = uuid
+	case *nbdb.LoadBalancerHealthCheck:
+		t.UUID = uuid
 	case *nbdb.LogicalRouter:
 		t.UUID = uuid
 	case *nbdb.LogicalRouterPolicy:
@@ -133,6 +150,18 @@ func setUUID(model model.Model, uuid string) {
 		t.UUID = uuid
 	case *nbdb.DHCPOptions:
 		t.UUID = uuid
+	case *vswitchd.OpenvSwitch:
+		t.UUID = uuid
+	case *vswitchd.Bridge:
+		t.UUID = uuid
+	case *vswitchd.Port:
+		t.UUID = uuid
+	case *vswitchd.Interface:
+		t.UUID = uuid
+	case *vswitchd.QoS:
+		t.UUID = uuid
+	case *vswitchd.Queue:
+		t.UUID = uuid
 	default:
 		panic(fmt.Sprintf("setUUID: unknown model %T", t))
 	}
@@ -179,6 +208,10 @@ func copyIndexes(model model.Model) model.Model {
 			UUID: t.UUID,
 			Name: t.Name,
 		}
+	case *nbdb.LoadBalancerHealthCheck:
+		return &nbdb.LoadBalancerHealthCheck{
+			UUID: t.UUID,
+		}
 	case *nbdb.LogicalRouter:
 		return &nbdb.LogicalRouter{
 			UUID: t.UUID,
@@ -281,6 +314,33 @@ func copyIndexes(model model.Model) model.Model {
 			UUID:        t.UUID,
 			ExternalIDs: copyExternalIDs(t.ExternalIDs, types.PrimaryIDKey),
 		}
+	case *vswitchd.OpenvSwitch:
+		return &vswitchd.OpenvSwitch{
+			UUID: t.UUID,
+		}
+	case *vswitchd.Bridge:
+		return &vswitchd.Bridge{
+			UUID: t.UUID,
+			Name: t.Name,
+		}
+	case *vswitchd.Port:
+		return &vswitchd.Port{
+			UUID: t.UUID,
+			Name: t.Name,
+		}
+	case *vswitchd.Interface:
+		return &vswitchd.Interface{
+			UUID: t.UUID,
+			Name: t.Name,
+		}
+	case *vswitchd.QoS:
+		return &vswitchd.QoS{
+			UUID: t.UUID,
+		}
+	case *vswitchd.Queue:
+		return &vswitchd.Queue{
+			UUID: t.UUID,
+		}
 	default:
 		panic(fmt.Sprintf("copyIndexes: unknown model %T", t))
 	}
@@ -302,6 +362,8 @@ func getListFromModel(model model.Model) interface{} {
 		return &[]*nbdb.LoadBalancer{}
 	case *nbdb.LoadBalancerGroup:
 		return &[]*nbdb.LoadBalancerGroup{}
+	case *nbdb.LoadBalancerHealthCheck:
+		return &[]*nbdb.LoadBalancerHealthCheck{}
 	case *nbdb.LogicalRouter:
 		return &[]*nbdb.LogicalRouter{}
 	case *nbdb.LogicalRouterPolicy:
@@ -342,6 +404,18 @@ func getListFromModel(model model.Model) interface{} {
 		return &[]*nbdb.ChassisTemplateVar{}
 	case *nbdb.DHCPOptions:
 		return &[]nbdb.DHCPOptions{}
+	case *vswitchd.OpenvSwitch:
+		return &[]*vswitchd.OpenvSwitch{}
+	case *vswitchd.Bridge:
+		return &[]*vswitchd.Bridge{}
+	case *vswitchd.Port:
+		return &[]*vswitchd.Port{}
+	case *vswitchd.Interface:
+		return &[]*vswitchd.Interface{}
+	case *vswitchd.QoS:
+		return &[]*vswitchd.QoS{}
+	case *vswitchd.Queue:
+		return &[]*vswitchd.Queue{}
 	default:
 		panic(fmt.Sprintf("getModelList: unknown model %T", t))
 	}
@@ -445,6 +445,21 @@ func DeleteLogicalSwitchPortsWithPredicateOps(nbClient libovsdbclient.Client, op
 	return m.DeleteOps(ops, opModels...)
 }
 
+// UpdateLogicalSwitchPortAddressesOps sets the addresses of the provided
+// logical switch port and returns the corresponding ops
+func UpdateLogicalSwitchPortAddressesOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, lsp *nbdb.LogicalSwitchPort) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		// For LSP's Name is a valid index, so no predicate is needed
+		Model:          lsp,
+		OnModelUpdates: []interface{}{&lsp.Addresses},
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(nbClient)
+	return m.CreateOrUpdateOps(ops, opModel)
+}
+
 // UpdateLogicalSwitchPortSetOptions sets options on the provided logical switch
 // port adding any missing, removing the ones set to an empty value and updating
 // existing
@@ -0,0 +1,331 @@
+package ops
+
+import (
+	"context"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+// OPEN_VSWITCH OPs
+
+// GetOpenvSwitch looks up the Open_vSwitch root entry from the cache
+func GetOpenvSwitch(ovsClient libovsdbclient.Client) (*vswitchd.OpenvSwitch, error) {
+	found := []*vswitchd.OpenvSwitch{}
+	opModel := operationModel{
+		Model:          &vswitchd.OpenvSwitch{},
+		ModelPredicate: func(item *vswitchd.OpenvSwitch) bool { return true },
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	err := m.Lookup(opModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// UpdateOpenvSwitchSetExternalIDsAndOtherConfig adds or updates the provided
+// external IDs and other config keys on the Open_vSwitch root entry. Keys not
+// provided are left untouched as other daemons like ovn-controller store their
+// own configuration in the same columns.
+func UpdateOpenvSwitchSetExternalIDsAndOtherConfig(ovsClient libovsdbclient.Client, openvSwitch *vswitchd.OpenvSwitch) error {
+	opModel := operationModel{
+		Model:            openvSwitch,
+		ModelPredicate:   func(item *vswitchd.OpenvSwitch) bool { return true },
+		OnModelMutations: []interface{}{&openvSwitch.ExternalIDs, &openvSwitch.OtherConfig},
+		ErrNotFound:      true,
+		BulkOp:           false,
+	}
+
+	m := newModelClient(ovsClient)
+	_, err := m.CreateOrUpdate(opModel)
+	return err
+}
+
+// BRIDGE OPs
+
+// GetBridge looks up a bridge from the cache
+func GetBridge(ovsClient libovsdbclient.Client, bridge *vswitchd.Bridge) (*vswitchd.Bridge, error) {
+	found := []*vswitchd.Bridge{}
+	opModel := operationModel{
+		Model:          bridge,
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	err := m.Lookup(opModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// PORT OPs
+
+// GetPort looks up a port from the cache
+func GetPort(ovsClient libovsdbclient.Client, port *vswitchd.Port) (*vswitchd.Port, error) {
+	found := []*vswitchd.Port{}
+	opModel := operationModel{
+		Model:          port,
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	err := m.Lookup(opModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// CreateOrUpdatePortAndInterfaceOnBridgeOps creates the provided port with the
+// provided interface on the provided bridge if the port does not exist yet, and
+// adds or updates the external IDs of the interface otherwise. Returns the
+// corresponding ops.
+func CreateOrUpdatePortAndInterfaceOnBridgeOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation,
+	bridge *vswitchd.Bridge, port *vswitchd.Port, iface *vswitchd.Interface) ([]libovsdb.Operation, error) {
+	originalInterfaces := port.Interfaces
+	originalPorts := bridge.Ports
+	opModels := []operationModel{
+		{
+			Model:            iface,
+			OnModelMutations: []interface{}{&iface.ExternalIDs},
+			DoAfter:          func() { port.Interfaces = []string{iface.UUID} },
+			ErrNotFound:      false,
+			BulkOp:           false,
+		},
+		{
+			Model:          port,
+			OnModelUpdates: onModelUpdatesNone(),
+			DoAfter:        func() { bridge.Ports = []string{port.UUID} },
+			ErrNotFound:    false,
+			BulkOp:         false,
+		},
+		{
+			Model:            bridge,
+			OnModelMutations: []interface{}{&bridge.Ports},
+			ErrNotFound:      true,
+			BulkOp:           false,
+		},
+	}
+
+	m := newModelClient(ovsClient)
+	ops, err := m.CreateOrUpdateOps(ops, opModels...)
+	port.Interfaces = originalInterfaces
+	bridge.Ports = originalPorts
+	return ops, err
+}
+
+// UpdatePortSetQoSOps sets the QoS of the provided port, clearing it if
+// no QoS is provided, and returns the corresponding ops
+func UpdatePortSetQoSOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, port *vswitchd.Port) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		Model:          port,
+		OnModelUpdates: []interface{}{&port.QOS},
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	return m.CreateOrUpdateOps(ops, opModel)
+}
+
+// DeletePortsOps deletes the provided ports along with their interfaces,
+// removes them from the bridges they are attached to and returns the
+// corresponding ops. Ports that do not exist are ignored.
+func DeletePortsOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, ports ...*vswitchd.Port) ([]libovsdb.Operation, error) {
+	bridge := &vswitchd.Bridge{
+		Ports: make([]string, 0, len(ports)),
+	}
+	// like ovs-vsctl, delete the interfaces explicitly instead of relying on
+	// them being garbage collected
+	ifaces := sets.New[string]()
+	opModels := make([]operationModel, 0, len(ports)+2)
+	for i := range ports {
+		port := ports[i]
+		found := []*vswitchd.Port{}
+		opModel := operationModel{
+			Model:          port,
+			ExistingResult: &found,
+			DoAfter: func() {
+				if port.UUID != "" {
+					bridge.Ports = append(bridge.Ports, port.UUID)
+				}
+				for _, p := range found {
+					ifaces.Insert(p.Interfaces...)
+				}
+			},
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+	opModels = append(opModels, operationModel{
+		ModelPredicate: func(item *vswitchd.Interface) bool { return ifaces.Has(item.UUID) },
+		ExistingResult: &[]*vswitchd.Interface{},
+		ErrNotFound:    false,
+		BulkOp:         true,
+	})
+	opModel := operationModel{
+		Model: bridge,
+		ModelPredicate: func(item *vswitchd.Bridge) bool {
+			for _, uuid := range item.Ports {
+				for _, deleted := range bridge.Ports {
+					if uuid == deleted {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		OnModelMutations: []interface{}{&bridge.Ports},
+		ErrNotFound:      false,
+		BulkOp:           true,
+	}
+	opModels = append(opModels, opModel)
+
+	m := newModelClient(ovsClient)
+	return m.DeleteOps(ops, opModels...)
+}
+
+// DeletePorts deletes the provided ports along with their interfaces and
+// removes them from the bridges they are attached to
+func DeletePorts(ovsClient libovsdbclient.Client, ports ...*vswitchd.Port) error {
+	ops, err := DeletePortsOps(ovsClient, nil, ports...)
+	if err != nil {
+		return err
+	}
+
+	_, err = TransactAndCheck(ovsClient, ops)
+	return err
+}
+
+// INTERFACE OPs
+
+type interfacePredicate func(*vswitchd.Interface) bool
+
+// GetInterface looks up an interface from the cache
+func GetInterface(ovsClient libovsdbclient.Client, iface *vswitchd.Interface) (*vswitchd.Interface, error) {
+	found := []*vswitchd.Interface{}
+	opModel := operationModel{
+		Model:          iface,
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	err := m.Lookup(opModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// FindInterfacesWithPredicate looks up interfaces from the cache based on a
+// given predicate
+func FindInterfacesWithPredicate(ovsClient libovsdbclient.Client, p interfacePredicate) ([]*vswitchd.Interface, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), types.OVSDBTimeout)
+	defer cancel()
+	found := []*vswitchd.Interface{}
+	err := ovsClient.WhereCache(p).List(ctx, &found)
+	return found, err
+}
+
+// UpdateInterfaceOps updates the provided fields of the provided interface and
+// returns the corresponding ops
+func UpdateInterfaceOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, iface *vswitchd.Interface, fields ...interface{}) ([]libovsdb.Operation, error) {
+	if len(fields) == 0 {
+		fields = onModelUpdatesAllNonDefault()
+	}
+	opModel := operationModel{
+		Model:          iface,
+		OnModelUpdates: fields,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := newModelClient(ovsClient)
+	return m.CreateOrUpdateOps(ops, opModel)
+}
+
+// RemoveInterfaceExternalIDsOps removes the provided keys from the external
+// IDs of the provided interface and returns the corresponding ops. Nothing is
+// done if the interface does not exist.
+func RemoveInterfaceExternalIDsOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, iface *vswitchd.Interface, keys ...string) ([]libovsdb.Operation, error) {
+	originalExternalIDs := iface.ExternalIDs
+	iface.ExternalIDs = make(map[string]string, len(keys))
+	for _, key := range keys {
+		iface.ExternalIDs[key] = ""
+	}
+	opModel := operationModel{
+		Model:            iface,
+		OnModelMutations: []interface{}{&iface.ExternalIDs},
+		ErrNotFound:      false,
+		BulkOp:           false,
+	}
+
+	m := newModelClient(ovsClient)
+	ops, err := m.DeleteOps(ops, opModel)
+	iface.ExternalIDs = originalExternalIDs
+	return ops, err
+}
+
+// QOS OPs
+
+type ovsQoSPredicate func(*vswitchd.QoS) bool
+
+// FindOVSQoSesWithPredicate looks up OVS QoSes from the cache based on a
+// given predicate
+func FindOVSQoSesWithPredicate(ovsClient libovsdbclient.Client, p ovsQoSPredicate) ([]*vswitchd.QoS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), types.OVSDBTimeout)
+	defer cancel()
+	found := []*vswitchd.QoS{}
+	err := ovsClient.WhereCache(p).List(ctx, &found)
+	return found, err
+}
+
+// CreateOVSQoSOps creates the provided OVS QoS and returns the corresponding
+// ops. The UUID of the QoS can be referenced by subsequent ops of the same
+// transaction.
+func CreateOVSQoSOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, qos *vswitchd.QoS) ([]libovsdb.Operation, error) {
+	opModel := operationModel{
+		Model:       qos,
+		ErrNotFound: false,
+		BulkOp:      false,
+	}
+
+	m := newModelClient(ovsClient)
+	return m.CreateOrUpdateOps(ops, opModel)
+}
+
+// DeleteOVSQoSesWithPredicateOps looks up OVS QoSes from the cache based on a
+// given predicate and returns the ops to delete them
+func DeleteOVSQoSesWithPredicateOps(ovsClient libovsdbclient.Client, ops []libovsdb.Operation, p ovsQoSPredicate) ([]libovsdb.Operation, error) {
+	deleted := []*vswitchd.QoS{}
+	opModel := operationModel{
+		ModelPredicate: p,
+		ExistingResult: &deleted,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := newModelClient(ovsClient)
+	return m.DeleteOps(ops, opModel)
+}
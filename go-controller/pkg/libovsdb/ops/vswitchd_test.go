@@ -0,0 +1,170 @@
+package ops
+
+import (
+	"fmt"
+	"testing"
+
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+func TestCreateOrUpdatePortAndInterfaceOnBridgeOps(t *testing.T) {
+	bridgeUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f0"
+	portUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f1"
+	ifaceUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f2"
+	tests := []struct {
+		desc       string
+		iface      *vswitchd.Interface
+		initialDB  []libovsdbtest.TestData
+		expectedDB []libovsdbtest.TestData
+	}{
+		{
+			desc: "creates the port and the interface on the bridge",
+			iface: &vswitchd.Interface{
+				Name:        "pod-port",
+				ExternalIDs: map[string]string{"iface-id": "ns_pod"},
+			},
+			initialDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int"},
+			},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{
+					UUID:        portUUID,
+					Name:        "pod-port",
+					Interfaces:  []string{ifaceUUID},
+					OtherConfig: map[string]string{"transient": "true"},
+				},
+				&vswitchd.Interface{
+					UUID:        ifaceUUID,
+					Name:        "pod-port",
+					ExternalIDs: map[string]string{"iface-id": "ns_pod"},
+				},
+			},
+		},
+		{
+			desc: "updates the external IDs of an existing interface",
+			iface: &vswitchd.Interface{
+				Name:        "pod-port",
+				ExternalIDs: map[string]string{"iface-id": "ns_pod", "sandbox": "new"},
+			},
+			initialDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+				&vswitchd.Interface{
+					UUID:        ifaceUUID,
+					Name:        "pod-port",
+					ExternalIDs: map[string]string{"sandbox": "old", "ovn-installed": "true"},
+				},
+			},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+				&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+				&vswitchd.Interface{
+					UUID:        ifaceUUID,
+					Name:        "pod-port",
+					ExternalIDs: map[string]string{"iface-id": "ns_pod", "sandbox": "new", "ovn-installed": "true"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dbSetup := libovsdbtest.TestSetup{
+				OVSData: tt.initialDB,
+			}
+			ovsClient, cleanup, err := libovsdbtest.NewOVSTestHarness(dbSetup, nil)
+			if err != nil {
+				t.Fatalf("%s: failed to set up test harness: %v", tt.desc, err)
+			}
+			t.Cleanup(cleanup.Cleanup)
+
+			bridge := &vswitchd.Bridge{Name: "br-int"}
+			port := &vswitchd.Port{Name: tt.iface.Name, OtherConfig: map[string]string{"transient": "true"}}
+			ops, err := CreateOrUpdatePortAndInterfaceOnBridgeOps(ovsClient, nil, bridge, port, tt.iface)
+			if err != nil {
+				t.Fatal(fmt.Errorf("%s: got unexpected error: %v", tt.desc, err))
+			}
+			_, err = TransactAndCheck(ovsClient, ops)
+			if err != nil {
+				t.Fatal(fmt.Errorf("%s: got unexpected error: %v", tt.desc, err))
+			}
+
+			matcher := libovsdbtest.HaveDataIgnoringUUIDs(tt.expectedDB)
+			match, err := matcher.Match(ovsClient)
+			if err != nil {
+				t.Fatalf("%s: matcher error: %v", tt.desc, err)
+			}
+			if !match {
+				t.Fatalf("%s: DB state did not match: %s", tt.desc, matcher.FailureMessage(ovsClient))
+			}
+		})
+	}
+}
+
+func TestDeletePorts(t *testing.T) {
+	bridgeUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f0"
+	bridge2UUID := "b9998337-2498-4d1e-86e6-fc0417abb2f1"
+	portUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f2"
+	port2UUID := "b9998337-2498-4d1e-86e6-fc0417abb2f3"
+	ifaceUUID := "b9998337-2498-4d1e-86e6-fc0417abb2f4"
+	iface2UUID := "b9998337-2498-4d1e-86e6-fc0417abb2f5"
+	initialDB := []libovsdbtest.TestData{
+		&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int", Ports: []string{portUUID}},
+		&vswitchd.Bridge{UUID: bridge2UUID, Name: "breth0", Ports: []string{port2UUID}},
+		&vswitchd.Port{UUID: portUUID, Name: "pod-port", Interfaces: []string{ifaceUUID}},
+		&vswitchd.Interface{UUID: ifaceUUID, Name: "pod-port"},
+		&vswitchd.Port{UUID: port2UUID, Name: "eth0", Interfaces: []string{iface2UUID}},
+		&vswitchd.Interface{UUID: iface2UUID, Name: "eth0"},
+	}
+	tests := []struct {
+		desc       string
+		ports      []*vswitchd.Port
+		expectedDB []libovsdbtest.TestData
+	}{
+		{
+			desc:  "deletes the port from its bridge",
+			ports: []*vswitchd.Port{{Name: "pod-port"}},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int"},
+				&vswitchd.Bridge{UUID: bridge2UUID, Name: "breth0", Ports: []string{port2UUID}},
+				&vswitchd.Port{UUID: port2UUID, Name: "eth0", Interfaces: []string{iface2UUID}},
+				&vswitchd.Interface{UUID: iface2UUID, Name: "eth0"},
+			},
+		},
+		{
+			desc:  "deletes ports from different bridges and ignores missing ports",
+			ports: []*vswitchd.Port{{Name: "pod-port"}, {Name: "missing"}, {Name: "eth0"}},
+			expectedDB: []libovsdbtest.TestData{
+				&vswitchd.Bridge{UUID: bridgeUUID, Name: "br-int"},
+				&vswitchd.Bridge{UUID: bridge2UUID, Name: "breth0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dbSetup := libovsdbtest.TestSetup{
+				OVSData: initialDB,
+			}
+			ovsClient, cleanup, err := libovsdbtest.NewOVSTestHarness(dbSetup, nil)
+			if err != nil {
+				t.Fatalf("%s: failed to set up test harness: %v", tt.desc, err)
+			}
+			t.Cleanup(cleanup.Cleanup)
+
+			err = DeletePorts(ovsClient, tt.ports...)
+			if err != nil {
+				t.Fatal(fmt.Errorf("%s: got unexpected error: %v", tt.desc, err))
+			}
+
+			matcher := libovsdbtest.HaveDataIgnoringUUIDs(tt.expectedDB)
+			match, err := matcher.Match(ovsClient)
+			if err != nil {
+				t.Fatalf("%s: matcher error: %v", tt.desc, err)
+			}
+			if !match {
+				t.Fatalf("%s: DB state did not match: %s", tt.desc, matcher.FailureMessage(ovsClient))
+			}
+		})
+	}
+}
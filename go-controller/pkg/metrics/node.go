@@ -34,6 +34,50 @@ var metricOvnNodePortEnabled = prometheus.NewGauge(prometheus.GaugeOpts{
 	Help:      "Specifies if the node port is enabled on this node(1) or not(0).",
 })
 
+// MetricManagementPortDriftCount is a prometheus metric that counts the
+// configurations of the management port found missing or wrong, and repaired,
+// by the management port health check
+var MetricManagementPortDriftCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemNode,
+	Name:      "management_port_drift_total",
+	Help:      "The number of times a configuration of the management port was found missing or wrong and repaired.",
+},
+	//labels
+	[]string{"drift"},
+)
+
+// MetricManagementPortDegraded is a prometheus metric that reports whether the
+// management port keeps drifting from its configuration
+var MetricManagementPortDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemNode,
+	Name:      "management_port_degraded",
+	Help:      "Specifies if the management port keeps drifting from its configuration(1) or not(0).",
+})
+
+// MetricConntrackDeletedEntries is a prometheus metric that counts the stale
+// conntrack entries deleted by the node conntrack controller
+var MetricConntrackDeletedEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemNode,
+	Name:      "conntrack_deleted_entries_total",
+	Help:      "The number of stale conntrack entries deleted, by the reason they became stale and their protocol.",
+},
+	//labels
+	[]string{"reason", "protocol"},
+)
+
+// MetricConntrackDeleteBatchDuration is a prometheus metric that observes the
+// time taken to delete a batch of stale conntrack entries
+var MetricConntrackDeleteBatchDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemNode,
+	Name:      "conntrack_delete_batch_duration_seconds",
+	Help:      "The duration of the deletion of a batch of stale conntrack entries.",
+	Buckets:   prometheus.ExponentialBuckets(.001, 2, 15),
+})
+
 var registerNodeMetricsOnce sync.Once
 
 func RegisterNodeMetrics() {
@@ -42,6 +86,10 @@ func RegisterNodeMetrics() {
 		prometheus.MustRegister(MetricCNIRequestDuration)
 		prometheus.MustRegister(MetricNodeReadyDuration)
 		prometheus.MustRegister(metricOvnNodePortEnabled)
+		prometheus.MustRegister(MetricManagementPortDriftCount)
+		prometheus.MustRegister(MetricManagementPortDegraded)
+		prometheus.MustRegister(MetricConntrackDeletedEntries)
+		prometheus.MustRegister(MetricConntrackDeleteBatchDuration)
 		prometheus.MustRegister(prometheus.NewGaugeFunc(
 			prometheus.GaugeOpts{
 				Namespace: MetricOvnkubeNamespace,
@@ -241,6 +241,28 @@ var metricPodEventLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		"event",
 	})
 
+var metricKubevirtDHCPOptionsLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemController,
+	Name:      "kubevirt_dhcp_options_duration_seconds",
+	Help:      "The duration to program the DHCP options of a KubeVirt virtual machine in ovn nb database",
+	Buckets:   prometheus.ExponentialBuckets(.001, 2, 15)},
+	[]string{
+		"namespace",
+	})
+
+// metricKubevirtMigrationCutoverLatency is the time between KubeVirt marking a
+// live migration target pod ready and the VM traffic being routed to it
+var metricKubevirtMigrationCutoverLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: MetricOvnkubeNamespace,
+	Subsystem: MetricOvnkubeSubsystemController,
+	Name:      "kubevirt_live_migration_network_cutover_duration_seconds",
+	Help:      "The duration between a KubeVirt live migration target pod ready and the virtual machine routes installed",
+	Buckets:   prometheus.ExponentialBuckets(.01, 2, 15)},
+	[]string{
+		"namespace",
+	})
+
 var metricEgressFirewallRuleCount = prometheus.NewGauge(prometheus.GaugeOpts{
 	Namespace: MetricOvnkubeNamespace,
 	Subsystem: MetricOvnkubeSubsystemController,
@@ -406,6 +428,8 @@ func RegisterOVNKubeControllerFunctional() {
 	prometheus.MustRegister(metricEgressFirewallRuleCount)
 	prometheus.MustRegister(metricEgressFirewallCount)
 	prometheus.MustRegister(metricEgressRoutingViaHost)
+	prometheus.MustRegister(metricKubevirtDHCPOptionsLatency)
+	prometheus.MustRegister(metricKubevirtMigrationCutoverLatency)
 	if err := prometheus.Register(MetricResourceRetryFailuresCount); err != nil {
 		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
 			panic(err)
@@ -516,6 +540,19 @@ func RecordPodEvent(eventName string, duration time.Duration) {
 	metricPodEventLatency.WithLabelValues(eventName).Observe(duration.Seconds())
 }
 
+// RecordKubevirtDHCPOptions records how long it took to create the DHCP
+// options of a KubeVirt virtual machine of the given namespace.
+func RecordKubevirtDHCPOptions(namespace string, duration time.Duration) {
+	metricKubevirtDHCPOptionsLatency.WithLabelValues(namespace).Observe(duration.Seconds())
+}
+
+// RecordKubevirtMigrationCutover records how long it took to route the traffic
+// of a live migrated KubeVirt virtual machine of the given namespace to its
+// target pod once ready.
+func RecordKubevirtMigrationCutover(namespace string, duration time.Duration) {
+	metricKubevirtMigrationCutoverLatency.WithLabelValues(namespace).Observe(duration.Seconds())
+}
+
 // UpdateEgressFirewallRuleCount records the number of Egress firewall rules.
 func UpdateEgressFirewallRuleCount(count float64) {
 	metricEgressFirewallRuleCount.Add(count)
@@ -0,0 +1,320 @@
+//go:build linux
+// +build linux
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+var serviceLBStatsLabels = []string{"namespace", "name", "port", "protocol"}
+
+// the service load balancer metrics are built on registration, with a backend
+// label only if the statistics are broken down per backend
+var metricServiceLBConnections, metricServiceLBBytes, metricServiceLBPackets *prometheus.GaugeVec
+
+// serviceLBBackendStats is true if the statistics are broken down per backend
+var serviceLBBackendStats bool
+
+var registerServiceLBStatsMetricsOnce sync.Once
+
+// exportedServiceLBStats are the service backends with exported statistics, only
+// accessed by the updater
+var exportedServiceLBStats = map[serviceBackendKey]bool{}
+
+// serviceLister lists the services whose connections are accounted
+type serviceLister interface {
+	GetServices() ([]*kapi.Service, error)
+}
+
+// conntrackLister lists the connection tracking entries of an address family
+type conntrackLister func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error)
+
+// nodeIPLister lists the local IPs of the node NodePorts are reachable on
+type nodeIPLister func() ([]net.IP, error)
+
+// serviceVIP identifies a service port by the destination of its connections.
+// NodePorts are matched on any local IP of the node, ip is empty for them.
+type serviceVIP struct {
+	ip       string
+	port     uint16
+	protocol uint8
+}
+
+type servicePortKey struct {
+	namespace string
+	name      string
+	port      string
+	protocol  string
+}
+
+type serviceBackendKey struct {
+	servicePortKey
+	// backend is empty unless the statistics are broken down per backend
+	backend string
+}
+
+type serviceBackendStats struct {
+	connections float64
+	bytes       float64
+	packets     float64
+}
+
+// connectionKey identifies a connection by its original tuple, the same in
+// each conntrack zone it goes through
+type connectionKey struct {
+	protocol uint8
+	srcIP    string
+	srcPort  uint16
+	dstIP    string
+	dstPort  uint16
+}
+
+// newServiceLBStatsMetrics builds the service load balancer metrics, broken
+// down per backend if perBackend is true
+func newServiceLBStatsMetrics(perBackend bool) {
+	labels := serviceLBStatsLabels
+	if perBackend {
+		labels = append(labels[:len(labels):len(labels)], "backend")
+	}
+	serviceLBBackendStats = perBackend
+	metricServiceLBConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricOvnkubeNamespace,
+		Subsystem: MetricOvnkubeSubsystemNode,
+		Name:      "service_lb_connections",
+		Help:      "The number of tracked connections of a service port load balanced on this node.",
+	}, labels)
+	metricServiceLBBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricOvnkubeNamespace,
+		Subsystem: MetricOvnkubeSubsystemNode,
+		Name:      "service_lb_bytes",
+		Help: "The number of bytes, in both directions, of the tracked connections of a service port load balanced " +
+			"on this node. Requires connection tracking accounting (net.netfilter.nf_conntrack_acct=1).",
+	}, labels)
+	metricServiceLBPackets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricOvnkubeNamespace,
+		Subsystem: MetricOvnkubeSubsystemNode,
+		Name:      "service_lb_packets",
+		Help: "The number of packets, in both directions, of the tracked connections of a service port load balanced " +
+			"on this node. Requires connection tracking accounting (net.netfilter.nf_conntrack_acct=1).",
+	}, labels)
+}
+
+// RegisterServiceLBStatsMetrics registers the per service port connection
+// tracking metrics and periodically updates them from the conntrack table of
+// this node. The metrics are broken down per backend with
+// --metrics-enable-service-lb-backend-stats, at the cost of one series per
+// service backend.
+func RegisterServiceLBStatsMetrics(services serviceLister, stopChan <-chan struct{}) {
+	registerServiceLBStatsMetricsOnce.Do(func() {
+		newServiceLBStatsMetrics(config.Metrics.EnableServiceLBBackendStats)
+		prometheus.MustRegister(metricServiceLBConnections)
+		prometheus.MustRegister(metricServiceLBBytes)
+		prometheus.MustRegister(metricServiceLBPackets)
+		go serviceLBStatsMetricsUpdater(services, util.GetNetLinkOps().ConntrackTableList, listNodeIPs, 30*time.Second, stopChan)
+	})
+}
+
+func serviceLBStatsMetricsUpdater(services serviceLister, conntrackList conntrackLister, nodeIPList nodeIPLister,
+	tickPeriod time.Duration, stopChan <-chan struct{}) {
+	ticker := time.NewTicker(tickPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := updateServiceLBStatsMetrics(services, conntrackList, nodeIPList); err != nil {
+				klog.Errorf("Updating service load balancer statistics failed: %v", err)
+			}
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// listNodeIPs lists the IPs of the local interfaces of the node
+func listNodeIPs() ([]net.IP, error) {
+	addrs, err := util.GetNetLinkOps().AddrList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+func updateServiceLBStatsMetrics(services serviceLister, conntrackList conntrackLister, nodeIPList nodeIPLister) error {
+	svcs, err := services.GetServices()
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	vips := getServiceVIPs(svcs)
+	ips, err := nodeIPList()
+	if err != nil {
+		return fmt.Errorf("failed to list node IPs: %w", err)
+	}
+	nodeIPs := sets.New[string]()
+	for _, ip := range ips {
+		nodeIPs.Insert(ip.String())
+	}
+
+	var families []netlink.InetFamily
+	if config.IPv4Mode {
+		families = append(families, netlink.InetFamily(unix.AF_INET))
+	}
+	if config.IPv6Mode {
+		families = append(families, netlink.InetFamily(unix.AF_INET6))
+	}
+
+	stats := map[serviceBackendKey]*serviceBackendStats{}
+	connections := map[connectionKey]bool{}
+	for _, family := range families {
+		flows, err := conntrackList(netlink.ConntrackTable, family)
+		if err != nil {
+			return fmt.Errorf("failed to list conntrack entries of family %d: %w", family, err)
+		}
+		for _, flow := range flows {
+			svcPort, ok := vips[serviceVIP{ip: flow.Forward.DstIP.String(), port: flow.Forward.DstPort, protocol: flow.Forward.Protocol}]
+			if !ok && nodeIPs.Has(flow.Forward.DstIP.String()) {
+				svcPort, ok = vips[serviceVIP{port: flow.Forward.DstPort, protocol: flow.Forward.Protocol}]
+			}
+			if !ok {
+				continue
+			}
+			// the backend is the source of the replies, skip the entries that
+			// were not load balanced
+			if flow.Reverse.SrcIP.Equal(flow.Forward.DstIP) && flow.Reverse.SrcPort == flow.Forward.DstPort {
+				continue
+			}
+			// skip the entries translated to another service, e.g. a NodePort
+			// to its ClusterIP, the connection is counted when load balanced
+			// from it to a backend
+			if _, ok := vips[serviceVIP{ip: flow.Reverse.SrcIP.String(), port: flow.Reverse.SrcPort, protocol: flow.Forward.Protocol}]; ok {
+				continue
+			}
+			// count the connections only once, whatever the number of
+			// conntrack zones they go through
+			connKey := connectionKey{
+				protocol: flow.Forward.Protocol,
+				srcIP:    flow.Forward.SrcIP.String(),
+				srcPort:  flow.Forward.SrcPort,
+				dstIP:    flow.Forward.DstIP.String(),
+				dstPort:  flow.Forward.DstPort,
+			}
+			if connections[connKey] {
+				continue
+			}
+			connections[connKey] = true
+			key := serviceBackendKey{servicePortKey: svcPort}
+			if serviceLBBackendStats {
+				key.backend = net.JoinHostPort(flow.Reverse.SrcIP.String(), strconv.Itoa(int(flow.Reverse.SrcPort)))
+			}
+			s, ok := stats[key]
+			if !ok {
+				s = &serviceBackendStats{}
+				stats[key] = s
+			}
+			s.connections++
+			s.bytes += float64(flow.Forward.Bytes + flow.Reverse.Bytes)
+			s.packets += float64(flow.Forward.Packets + flow.Reverse.Packets)
+		}
+	}
+
+	for key, s := range stats {
+		labels := key.labels()
+		metricServiceLBConnections.WithLabelValues(labels...).Set(s.connections)
+		metricServiceLBBytes.WithLabelValues(labels...).Set(s.bytes)
+		metricServiceLBPackets.WithLabelValues(labels...).Set(s.packets)
+		exportedServiceLBStats[key] = true
+	}
+	// services and backends come and go, only delete the statistics of the
+	// ones gone so that scrapes never see a partial update
+	for key := range exportedServiceLBStats {
+		if _, ok := stats[key]; ok {
+			continue
+		}
+		labels := key.labels()
+		metricServiceLBConnections.DeleteLabelValues(labels...)
+		metricServiceLBBytes.DeleteLabelValues(labels...)
+		metricServiceLBPackets.DeleteLabelValues(labels...)
+		delete(exportedServiceLBStats, key)
+	}
+	return nil
+}
+
+func (key serviceBackendKey) labels() []string {
+	labels := []string{key.namespace, key.name, key.port, key.protocol}
+	if serviceLBBackendStats {
+		labels = append(labels, key.backend)
+	}
+	return labels
+}
+
+// getServiceVIPs returns the service ports reachable through each of the
+// ClusterIPs, ExternalIPs, load balancer ingress IPs and NodePorts of the
+// services
+func getServiceVIPs(services []*kapi.Service) map[serviceVIP]servicePortKey {
+	vips := map[serviceVIP]servicePortKey{}
+	for _, service := range services {
+		if !util.ServiceTypeHasClusterIP(service) || !util.IsClusterIPSet(service) {
+			continue
+		}
+		ips := append([]string{}, util.GetClusterIPs(service)...)
+		ips = append(ips, service.Spec.ExternalIPs...)
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				ips = append(ips, ingress.IP)
+			}
+		}
+		for _, svcPort := range service.Spec.Ports {
+			protocol, ok := conntrackProtocol(svcPort.Protocol)
+			if !ok {
+				continue
+			}
+			svcPortKey := servicePortKey{
+				namespace: service.Namespace,
+				name:      service.Name,
+				port:      strconv.Itoa(int(svcPort.Port)),
+				protocol:  string(svcPort.Protocol),
+			}
+			for _, ip := range ips {
+				// match the textual representation of the conntrack entries
+				if parsed := net.ParseIP(ip); parsed != nil {
+					ip = parsed.String()
+				}
+				vips[serviceVIP{ip: ip, port: uint16(svcPort.Port), protocol: protocol}] = svcPortKey
+			}
+			if svcPort.NodePort != 0 {
+				vips[serviceVIP{port: uint16(svcPort.NodePort), protocol: protocol}] = svcPortKey
+			}
+		}
+	}
+	return vips
+}
+
+func conntrackProtocol(protocol kapi.Protocol) (uint8, bool) {
+	switch protocol {
+	case kapi.ProtocolTCP:
+		return unix.IPPROTO_TCP, true
+	case kapi.ProtocolUDP:
+		return unix.IPPROTO_UDP, true
+	case kapi.ProtocolSCTP:
+		return unix.IPPROTO_SCTP, true
+	}
+	return 0, false
+}
@@ -0,0 +1,185 @@
+//go:build linux
+// +build linux
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeServiceLister struct {
+	services []*kapi.Service
+	err      error
+}
+
+func (l *fakeServiceLister) GetServices() ([]*kapi.Service, error) {
+	return l.services, l.err
+}
+
+func newConntrackFlow(protocol uint8, srcIP, dstIP, backendIP string, srcPort, dstPort, backendPort uint16, bytes, packets uint64) *netlink.ConntrackFlow {
+	flow := &netlink.ConntrackFlow{}
+	flow.Forward.Protocol = protocol
+	flow.Forward.SrcIP = net.ParseIP(srcIP)
+	flow.Forward.DstIP = net.ParseIP(dstIP)
+	flow.Forward.SrcPort = srcPort
+	flow.Forward.DstPort = dstPort
+	flow.Forward.Bytes = bytes
+	flow.Forward.Packets = packets
+	flow.Reverse.Protocol = protocol
+	flow.Reverse.SrcIP = net.ParseIP(backendIP)
+	flow.Reverse.DstIP = net.ParseIP(srcIP)
+	flow.Reverse.SrcPort = backendPort
+	flow.Reverse.DstPort = srcPort
+	flow.Reverse.Bytes = bytes
+	flow.Reverse.Packets = packets
+	return flow
+}
+
+func getGaugeVecValue(vec *prometheus.GaugeVec, labels ...string) float64 {
+	metric := &io_prometheus_client.Metric{}
+	gomega.Expect(vec.WithLabelValues(labels...).Write(metric)).To(gomega.Succeed())
+	return metric.GetGauge().GetValue()
+}
+
+var _ = ginkgo.Describe("Service load balancer statistics metrics", func() {
+	var services *fakeServiceLister
+	nodeIPList := func() ([]net.IP, error) {
+		return []net.IP{net.ParseIP("172.18.0.2")}, nil
+	}
+
+	ginkgo.BeforeEach(func() {
+		config.IPv4Mode = true
+		config.IPv6Mode = false
+		services = &fakeServiceLister{
+			services: []*kapi.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1"},
+					Spec: kapi.ServiceSpec{
+						Type:        kapi.ServiceTypeNodePort,
+						ClusterIP:   "10.96.0.10",
+						ClusterIPs:  []string{"10.96.0.10"},
+						ExternalIPs: []string{"1.1.1.1"},
+						Ports: []kapi.ServicePort{
+							{Name: "http", Port: 80, Protocol: kapi.ProtocolTCP, NodePort: 30080},
+							{Name: "dns", Port: 53, Protocol: kapi.ProtocolUDP},
+						},
+					},
+				},
+			},
+		}
+		newServiceLBStatsMetrics(true)
+		exportedServiceLBStats = map[serviceBackendKey]bool{}
+	})
+
+	ginkgo.It("aggregates the conntrack entries per service port and backend", func() {
+		conntrackList := func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+			gomega.Expect(family).To(gomega.Equal(netlink.InetFamily(unix.AF_INET)))
+			return []*netlink.ConntrackFlow{
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.10", "10.128.1.4", 40000, 80, 8080, 100, 2),
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.6", "10.96.0.10", "10.128.1.4", 40001, 80, 8080, 50, 1),
+				newConntrackFlow(unix.IPPROTO_TCP, "8.8.8.8", "1.1.1.1", "10.128.2.4", 40002, 80, 8080, 10, 1),
+				newConntrackFlow(unix.IPPROTO_TCP, "8.8.8.8", "172.18.0.2", "10.128.2.4", 40003, 30080, 8080, 10, 1),
+				newConntrackFlow(unix.IPPROTO_UDP, "10.128.0.5", "10.96.0.10", "10.128.1.5", 40004, 53, 5353, 20, 1),
+				// the same connection in another conntrack zone
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.10", "10.128.1.4", 40000, 80, 8080, 100, 2),
+				// a NodePort connection translated to the ClusterIP
+				newConntrackFlow(unix.IPPROTO_TCP, "8.8.8.8", "172.18.0.2", "10.96.0.10", 40007, 30080, 80, 10, 1),
+				// a NodePort of another node
+				newConntrackFlow(unix.IPPROTO_TCP, "8.8.8.8", "172.18.0.3", "10.128.2.4", 40008, 30080, 8080, 10, 1),
+				// not a service
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.11", "10.128.1.4", 40005, 80, 8080, 100, 2),
+				// not load balanced
+				newConntrackFlow(unix.IPPROTO_UDP, "10.128.0.5", "10.96.0.10", "10.96.0.10", 40006, 53, 53, 20, 1),
+			}, nil
+		}
+
+		err := updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		ch := make(chan prometheus.Metric, 10)
+		defer close(ch)
+		metricServiceLBConnections.Collect(ch)
+		gomega.Expect(ch).To(gomega.HaveLen(3))
+
+		gomega.Expect(getGaugeVecValue(metricServiceLBConnections, "ns1", "svc1", "80", "TCP", "10.128.1.4:8080")).To(gomega.Equal(2.0))
+		gomega.Expect(getGaugeVecValue(metricServiceLBBytes, "ns1", "svc1", "80", "TCP", "10.128.1.4:8080")).To(gomega.Equal(300.0))
+		gomega.Expect(getGaugeVecValue(metricServiceLBPackets, "ns1", "svc1", "80", "TCP", "10.128.1.4:8080")).To(gomega.Equal(6.0))
+		gomega.Expect(getGaugeVecValue(metricServiceLBConnections, "ns1", "svc1", "80", "TCP", "10.128.2.4:8080")).To(gomega.Equal(2.0))
+		gomega.Expect(getGaugeVecValue(metricServiceLBConnections, "ns1", "svc1", "53", "UDP", "10.128.1.5:5353")).To(gomega.Equal(1.0))
+	})
+
+	ginkgo.It("aggregates the conntrack entries per service port unless broken down per backend", func() {
+		newServiceLBStatsMetrics(false)
+		conntrackList := func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+			return []*netlink.ConntrackFlow{
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.10", "10.128.1.4", 40000, 80, 8080, 100, 2),
+				newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.6", "10.96.0.10", "10.128.2.4", 40001, 80, 8080, 50, 1),
+			}, nil
+		}
+		gomega.Expect(updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)).To(gomega.Succeed())
+
+		ch := make(chan prometheus.Metric, 10)
+		defer close(ch)
+		metricServiceLBConnections.Collect(ch)
+		gomega.Expect(ch).To(gomega.HaveLen(1))
+		gomega.Expect(getGaugeVecValue(metricServiceLBConnections, "ns1", "svc1", "80", "TCP")).To(gomega.Equal(2.0))
+		gomega.Expect(getGaugeVecValue(metricServiceLBBytes, "ns1", "svc1", "80", "TCP")).To(gomega.Equal(300.0))
+	})
+
+	ginkgo.It("removes the statistics of backends without connections", func() {
+		flows := []*netlink.ConntrackFlow{
+			newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.10", "10.128.1.4", 40000, 80, 8080, 100, 2),
+		}
+		conntrackList := func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+			return flows, nil
+		}
+		gomega.Expect(updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)).To(gomega.Succeed())
+		flows = nil
+		gomega.Expect(updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)).To(gomega.Succeed())
+
+		ch := make(chan prometheus.Metric, 10)
+		defer close(ch)
+		metricServiceLBConnections.Collect(ch)
+		gomega.Expect(ch).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("keeps the statistics of the backends still connected", func() {
+		flows := []*netlink.ConntrackFlow{
+			newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.5", "10.96.0.10", "10.128.1.4", 40000, 80, 8080, 100, 2),
+			newConntrackFlow(unix.IPPROTO_TCP, "10.128.0.6", "10.96.0.10", "10.128.2.4", 40001, 80, 8080, 100, 2),
+		}
+		conntrackList := func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+			return flows, nil
+		}
+		gomega.Expect(updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)).To(gomega.Succeed())
+		flows = flows[:1]
+		gomega.Expect(updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)).To(gomega.Succeed())
+
+		ch := make(chan prometheus.Metric, 10)
+		defer close(ch)
+		metricServiceLBConnections.Collect(ch)
+		gomega.Expect(ch).To(gomega.HaveLen(1))
+		gomega.Expect(getGaugeVecValue(metricServiceLBConnections, "ns1", "svc1", "80", "TCP", "10.128.1.4:8080")).To(gomega.Equal(1.0))
+		gomega.Expect(exportedServiceLBStats).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("returns an error when conntrack entries cannot be listed", func() {
+		conntrackList := func(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+			return nil, fmt.Errorf("operation not permitted")
+		}
+		err := updateServiceLBStatsMetrics(services, conntrackList, nodeIPList)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
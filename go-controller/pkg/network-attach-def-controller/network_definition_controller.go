@@ -0,0 +1,540 @@
+package networkAttachDefController
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	nettypes "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	nadclientset "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned"
+	nadinformers "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/informers/externalversions"
+	nadlisters "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/listers/k8s.cni.cncf.io/v1"
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	networkdefinitionv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1"
+	networkdefinitionclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/clientset/versioned"
+	networkdefinitioninformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/informers/externalversions/networkdefinition/v1"
+	networkdefinitionlisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/listers/networkdefinition/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+const (
+	// networkDefinitionReadyCondition is the condition reporting whether the
+	// network is rendered in all the selected namespaces
+	networkDefinitionReadyCondition = "Ready"
+
+	networkDefinitionReasonRendered       = "NetworkRendered"
+	networkDefinitionReasonInvalid        = "InvalidNetwork"
+	networkDefinitionReasonNetworkInUse   = "NetworkInUse"
+	networkDefinitionReasonConflicts      = "NetAttachDefConflicts"
+	networkDefinitionReasonRenderingError = "NetAttachDefRenderingFailed"
+
+	cniVersion = "0.3.1"
+	cniType    = "ovn-k8s-cni-overlay"
+)
+
+var networkDefinitionKind = networkdefinitionv1.SchemeGroupVersion.WithKind("NetworkDefinition")
+
+// NetworkDefinitionController renders the NetworkAttachmentDefinitions of the
+// cluster scoped NetworkDefinitions in the namespaces they select, and reports
+// the outcome on their status. The rendered NetworkAttachmentDefinitions are
+// owned by their NetworkDefinition and garbage collected with it.
+type NetworkDefinitionController struct {
+	name            string
+	netDefClient    networkdefinitionclientset.Interface
+	nadClient       nadclientset.Interface
+	netDefLister    networkdefinitionlisters.NetworkDefinitionLister
+	netDefSynced    cache.InformerSynced
+	namespaceLister corelisters.NamespaceLister
+	namespaceSynced cache.InformerSynced
+	nadFactory      nadinformers.SharedInformerFactory
+	nadLister       nadlisters.NetworkAttachmentDefinitionLister
+	nadSynced       cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	loopPeriod      time.Duration
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
+}
+
+func NewNetworkDefinitionController(name string, netDefClient networkdefinitionclientset.Interface,
+	nadClient nadclientset.Interface, netDefInformer networkdefinitioninformer.NetworkDefinitionInformer,
+	namespaceInformer coreinformers.NamespaceInformer) (*NetworkDefinitionController, error) {
+	nadFactory := nadinformers.NewSharedInformerFactoryWithOptions(
+		nadClient,
+		avoidResync,
+	)
+	nadInformer := nadFactory.K8sCniCncfIo().V1().NetworkAttachmentDefinitions()
+
+	c := &NetworkDefinitionController{
+		name:            name,
+		netDefClient:    netDefClient,
+		nadClient:       nadClient,
+		netDefLister:    netDefInformer.Lister(),
+		netDefSynced:    netDefInformer.Informer().HasSynced,
+		namespaceLister: namespaceInformer.Lister(),
+		namespaceSynced: namespaceInformer.Informer().HasSynced,
+		nadFactory:      nadFactory,
+		nadLister:       nadInformer.Lister(),
+		nadSynced:       nadInformer.Informer().HasSynced,
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+			"network-definition",
+		),
+		loopPeriod: time.Second,
+		stopChan:   make(chan struct{}),
+	}
+
+	_, err := netDefInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onNetworkDefinitionAdd,
+			UpdateFunc: c.onNetworkDefinitionUpdate,
+			DeleteFunc: c.onNetworkDefinitionDelete,
+		})
+	if err != nil {
+		return nil, err
+	}
+	_, err = namespaceInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onNamespaceAdd,
+			UpdateFunc: c.onNamespaceUpdate,
+			DeleteFunc: c.onNamespaceDelete,
+		})
+	if err != nil {
+		return nil, err
+	}
+	_, err = nadInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onNetworkAttachDefinitionAdd,
+			UpdateFunc: c.onNetworkAttachDefinitionUpdate,
+			DeleteFunc: c.onNetworkAttachDefinitionDelete,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *NetworkDefinitionController) Start() error {
+	klog.Infof("Starting %s network definition controller", c.name)
+	c.nadFactory.Start(c.stopChan)
+	if !util.WaitForNamedCacheSyncWithTimeout(c.name, c.stopChan, c.netDefSynced, c.namespaceSynced, c.nadSynced) {
+		return fmt.Errorf("stop requested while syncing caches")
+	}
+
+	klog.Infof("Starting workers for %s network definition controller", c.name)
+	for i := 0; i < numberOfWorkers; i++ {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			wait.Until(c.worker, c.loopPeriod, c.stopChan)
+		}()
+	}
+	return nil
+}
+
+func (c *NetworkDefinitionController) Stop() {
+	klog.Infof("Shutting down %s network definition controller", c.name)
+	close(c.stopChan)
+	c.queue.ShutDown()
+	c.wg.Wait()
+}
+
+func (c *NetworkDefinitionController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *NetworkDefinitionController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.sync(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		klog.V(2).InfoS("Error syncing network definition, retrying", "network-definition", key, "err", err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	klog.Warningf("%s: Dropping network definition %q out of the queue: %v", c.name, key, err)
+	c.queue.Forget(key)
+	utilruntime.HandleError(err)
+	return true
+}
+
+// sync renders the NetworkAttachmentDefinitions of the NetworkDefinition in
+// the namespaces it selects, removes them from the namespaces it no longer
+// selects and updates its status
+func (c *NetworkDefinitionController) sync(name string) error {
+	startTime := time.Now()
+	klog.V(5).Infof("%s: Sync network definition %s", c.name, name)
+	defer func() {
+		klog.V(4).Infof("%s: Finished syncing network definition %s: %v", c.name, name, time.Since(startTime))
+	}()
+
+	netDef, err := c.netDefLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		// the rendered NADs are garbage collected with their owner
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	status := networkdefinitionv1.NetworkDefinitionStatus{
+		Conditions: append([]metav1.Condition(nil), netDef.Status.Conditions...),
+	}
+
+	if err := validateNetworkDefinition(netDef); err != nil {
+		return c.updateStatus(netDef, status, networkDefinitionReasonInvalid, err.Error())
+	}
+	if inUseBy, err := c.getForeignNetAttachDefs(netDef); err != nil {
+		return err
+	} else if len(inUseBy) > 0 {
+		return c.updateStatus(netDef, status, networkDefinitionReasonNetworkInUse,
+			fmt.Sprintf("network %s is already defined by NetworkAttachmentDefinitions %s", netDef.Name, strings.Join(inUseBy, ", ")))
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&netDef.Spec.NamespaceSelector)
+	if err != nil {
+		return c.updateStatus(netDef, status, networkDefinitionReasonInvalid, err.Error())
+	}
+	namespaces, err := c.namespaceLister.List(selector)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	selected := sets.New[string]()
+	for _, namespace := range namespaces {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		selected.Insert(namespace.Name)
+		conflict, err := c.syncNetAttachDef(netDef, namespace.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if conflict != "" {
+			status.Conflicts = append(status.Conflicts, networkdefinitionv1.NetworkDefinitionConflict{
+				Namespace: namespace.Name,
+				Message:   conflict,
+			})
+			continue
+		}
+		status.Namespaces = append(status.Namespaces, namespace.Name)
+	}
+
+	// remove the NADs from the namespaces no longer selected
+	nads, err := c.nadLister.List(labels.SelectorFromSet(labels.Set{types.NetworkDefinitionLabel: netDef.Name}))
+	if err != nil {
+		return err
+	}
+	for _, nad := range nads {
+		if selected.Has(nad.Namespace) || !metav1.IsControlledBy(nad, netDef) {
+			continue
+		}
+		err := c.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(nad.Namespace).Delete(context.TODO(), nad.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete NetworkAttachmentDefinition %s/%s: %w", nad.Namespace, nad.Name, err))
+		}
+	}
+
+	sort.Strings(status.Namespaces)
+	sort.Slice(status.Conflicts, func(i, j int) bool { return status.Conflicts[i].Namespace < status.Conflicts[j].Namespace })
+
+	reason := networkDefinitionReasonRendered
+	message := fmt.Sprintf("NetworkAttachmentDefinition rendered in %d namespaces", len(status.Namespaces))
+	switch {
+	case len(errs) > 0:
+		reason = networkDefinitionReasonRenderingError
+		message = kerrors.NewAggregate(errs).Error()
+	case len(status.Conflicts) > 0:
+		reason = networkDefinitionReasonConflicts
+		message = fmt.Sprintf("NetworkAttachmentDefinition not rendered in %d namespaces", len(status.Conflicts))
+	}
+	if err := c.updateStatus(netDef, status, reason, message); err != nil {
+		errs = append(errs, err)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// syncNetAttachDef creates or updates the NetworkAttachmentDefinition of the
+// NetworkDefinition in the namespace, returning a conflict message if a
+// NetworkAttachmentDefinition of the same name not rendered from the
+// NetworkDefinition exists already
+func (c *NetworkDefinitionController) syncNetAttachDef(netDef *networkdefinitionv1.NetworkDefinition, namespace string) (string, error) {
+	desired := newNetAttachDef(netDef, namespace, netDef.Name)
+	nad, err := c.nadLister.NetworkAttachmentDefinitions(namespace).Get(netDef.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = c.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to create NetworkAttachmentDefinition %s/%s: %w", namespace, netDef.Name, err)
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !metav1.IsControlledBy(nad, netDef) {
+		return fmt.Sprintf("NetworkAttachmentDefinition %s/%s already exists", namespace, netDef.Name), nil
+	}
+	if nad.Spec.Config == desired.Spec.Config && reflect.DeepEqual(nad.Labels, desired.Labels) {
+		return "", nil
+	}
+
+	// restore what was changed in the rendered NAD
+	nad = nad.DeepCopy()
+	nad.Labels = desired.Labels
+	nad.Spec = desired.Spec
+	_, err = c.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(namespace).Update(context.TODO(), nad, metav1.UpdateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to update NetworkAttachmentDefinition %s/%s: %w", namespace, netDef.Name, err)
+	}
+	return "", nil
+}
+
+// getForeignNetAttachDefs returns the NetworkAttachmentDefinitions not
+// rendered from the NetworkDefinition that define a network of the same name
+func (c *NetworkDefinitionController) getForeignNetAttachDefs(netDef *networkdefinitionv1.NetworkDefinition) ([]string, error) {
+	nads, err := c.nadLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var foreign []string
+	for _, nad := range nads {
+		if metav1.IsControlledBy(nad, netDef) {
+			continue
+		}
+		netconf, err := util.ParseNetConf(nad)
+		if err != nil || netconf.Name != netDef.Name {
+			continue
+		}
+		foreign = append(foreign, util.GetNADName(nad.Namespace, nad.Name))
+	}
+	sort.Strings(foreign)
+	return foreign, nil
+}
+
+// updateStatus sets the ready condition on the status and updates the status
+// of the NetworkDefinition if it changed
+func (c *NetworkDefinitionController) updateStatus(netDef *networkdefinitionv1.NetworkDefinition,
+	status networkdefinitionv1.NetworkDefinitionStatus, reason, message string) error {
+	conditionStatus := metav1.ConditionFalse
+	if reason == networkDefinitionReasonRendered {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               networkDefinitionReadyCondition,
+		Status:             conditionStatus,
+		ObservedGeneration: netDef.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if equality.Semantic.DeepEqual(netDef.Status, status) {
+		return nil
+	}
+
+	netDef = netDef.DeepCopy()
+	netDef.Status = status
+	_, err := c.netDefClient.K8sV1().NetworkDefinitions().UpdateStatus(context.TODO(), netDef, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update the status of network definition %s: %w", netDef.Name, err)
+	}
+	return nil
+}
+
+// validateNetworkDefinition validates the network of the NetworkDefinition
+// once for all of the namespaces, as the network controllers would
+func validateNetworkDefinition(netDef *networkdefinitionv1.NetworkDefinition) error {
+	if netDef.Name == types.DefaultNetworkName {
+		return fmt.Errorf("network name %s is reserved", netDef.Name)
+	}
+	_, err := util.ParseNADInfo(newNetAttachDef(netDef, metav1.NamespaceDefault, netDef.Name))
+	return err
+}
+
+// newNetAttachDef returns the NetworkAttachmentDefinition of the
+// NetworkDefinition in the namespace
+func newNetAttachDef(netDef *networkdefinitionv1.NetworkDefinition, namespace, name string) *nettypes.NetworkAttachmentDefinition {
+	network := netDef.Spec.Network
+	netconf := ovncnitypes.NetConf{
+		NetConf: cnitypes.NetConf{
+			CNIVersion: cniVersion,
+			Name:       netDef.Name,
+			Type:       cniType,
+		},
+		Topology:           network.Topology,
+		NADName:            util.GetNADName(namespace, name),
+		MTU:                network.MTU,
+		Subnets:            strings.Join(network.Subnets, ","),
+		ExcludeSubnets:     strings.Join(network.ExcludeSubnets, ","),
+		VLANID:             network.VLANID,
+		AllowPersistentIPs: network.AllowPersistentIPs,
+		EnableGateway:      network.EnableGateway,
+	}
+	// the marshalling of the netconf cannot fail
+	config, _ := json.Marshal(netconf)
+
+	return &nettypes.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				types.NetworkDefinitionLabel: netDef.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(netDef, networkDefinitionKind)},
+		},
+		Spec: nettypes.NetworkAttachmentDefinitionSpec{
+			Config: string(config),
+		},
+	}
+}
+
+func (c *NetworkDefinitionController) queueAllNetworkDefinitions() {
+	netDefs, err := c.netDefLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%s: failed to list network definitions: %v", c.name, err))
+		return
+	}
+	for _, netDef := range netDefs {
+		c.queue.Add(netDef.Name)
+	}
+}
+
+func (c *NetworkDefinitionController) onNetworkDefinitionAdd(obj interface{}) {
+	netDef := obj.(*networkdefinitionv1.NetworkDefinition)
+	klog.V(4).Infof("%s: Adding network definition %s", c.name, netDef.Name)
+	c.queue.Add(netDef.Name)
+}
+
+func (c *NetworkDefinitionController) onNetworkDefinitionUpdate(oldObj, newObj interface{}) {
+	oldNetDef := oldObj.(*networkdefinitionv1.NetworkDefinition)
+	newNetDef := newObj.(*networkdefinitionv1.NetworkDefinition)
+	// status updates don't change the generation
+	if oldNetDef.Generation == newNetDef.Generation {
+		return
+	}
+	klog.V(4).Infof("%s: Updating network definition %s", c.name, newNetDef.Name)
+	c.queue.Add(newNetDef.Name)
+}
+
+func (c *NetworkDefinitionController) onNetworkDefinitionDelete(obj interface{}) {
+	netDef, ok := obj.(*networkdefinitionv1.NetworkDefinition)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		netDef, ok = tombstone.Obj.(*networkdefinitionv1.NetworkDefinition)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a NetworkDefinition object %#v", obj))
+			return
+		}
+	}
+	klog.V(4).Infof("%s: Deleting network definition %s", c.name, netDef.Name)
+	c.queue.Add(netDef.Name)
+}
+
+func (c *NetworkDefinitionController) onNamespaceAdd(_ interface{}) {
+	c.queueAllNetworkDefinitions()
+}
+
+func (c *NetworkDefinitionController) onNamespaceUpdate(oldObj, newObj interface{}) {
+	oldNamespace := oldObj.(*kapi.Namespace)
+	newNamespace := newObj.(*kapi.Namespace)
+	if reflect.DeepEqual(oldNamespace.Labels, newNamespace.Labels) &&
+		oldNamespace.DeletionTimestamp.IsZero() == newNamespace.DeletionTimestamp.IsZero() {
+		return
+	}
+	c.queueAllNetworkDefinitions()
+}
+
+func (c *NetworkDefinitionController) onNamespaceDelete(_ interface{}) {
+	c.queueAllNetworkDefinitions()
+}
+
+// queueNetworkDefinitionOfNetAttachDef queues the NetworkDefinition the
+// NetworkAttachmentDefinition is, or might be, rendered from
+func (c *NetworkDefinitionController) queueNetworkDefinitionOfNetAttachDef(nad *nettypes.NetworkAttachmentDefinition) {
+	if _, err := c.netDefLister.Get(nad.Name); err == nil {
+		c.queue.Add(nad.Name)
+	}
+	if netDefName := nad.Labels[types.NetworkDefinitionLabel]; netDefName != "" && netDefName != nad.Name {
+		c.queue.Add(netDefName)
+	}
+	// a NAD not rendered from a NetworkDefinition might define the network
+	// of one
+	if _, ok := nad.Labels[types.NetworkDefinitionLabel]; !ok {
+		if netconf, err := util.ParseNetConf(nad); err == nil && netconf.Name != nad.Name {
+			if _, err := c.netDefLister.Get(netconf.Name); err == nil {
+				c.queue.Add(netconf.Name)
+			}
+		}
+	}
+}
+
+func (c *NetworkDefinitionController) onNetworkAttachDefinitionAdd(obj interface{}) {
+	c.queueNetworkDefinitionOfNetAttachDef(obj.(*nettypes.NetworkAttachmentDefinition))
+}
+
+func (c *NetworkDefinitionController) onNetworkAttachDefinitionUpdate(oldObj, newObj interface{}) {
+	oldNAD := oldObj.(*nettypes.NetworkAttachmentDefinition)
+	newNAD := newObj.(*nettypes.NetworkAttachmentDefinition)
+	// only the fields rendered from the NetworkDefinition are of interest
+	if oldNAD.Spec.Config == newNAD.Spec.Config &&
+		reflect.DeepEqual(oldNAD.Labels, newNAD.Labels) &&
+		reflect.DeepEqual(oldNAD.OwnerReferences, newNAD.OwnerReferences) {
+		return
+	}
+	c.queueNetworkDefinitionOfNetAttachDef(newNAD)
+}
+
+func (c *NetworkDefinitionController) onNetworkAttachDefinitionDelete(obj interface{}) {
+	nad, ok := obj.(*nettypes.NetworkAttachmentDefinition)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		nad, ok = tombstone.Obj.(*nettypes.NetworkAttachmentDefinition)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a NetworkAttachmentDefinition object %#v", obj))
+			return
+		}
+	}
+	c.queueNetworkDefinitionOfNetAttachDef(nad)
+}
@@ -0,0 +1,281 @@
+package networkAttachDefController
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	nettypes "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	nadfake "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned/fake"
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	networkdefinitionv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1"
+	networkdefinitionfake "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/clientset/versioned/fake"
+	networkdefinitioninformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/informers/externalversions"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+func newNamespace(name string, labels map[string]string) *kapi.Namespace {
+	return &kapi.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func newNetworkDefinition(name string, network networkdefinitionv1.NetworkSpec) *networkdefinitionv1.NetworkDefinition {
+	return &networkdefinitionv1.NetworkDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: "netdef-uid"},
+		Spec: networkdefinitionv1.NetworkDefinitionSpec{
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "blue"}},
+			Network:           network,
+		},
+	}
+}
+
+type networkDefinitionControllerTest struct {
+	controller   *NetworkDefinitionController
+	netDefClient *networkdefinitionfake.Clientset
+	nadClient    *nadfake.Clientset
+	kubeClient   *fake.Clientset
+}
+
+func newNetworkDefinitionControllerTest(t *testing.T, netDefs []runtime.Object, nads []runtime.Object,
+	namespaces []runtime.Object) *networkDefinitionControllerTest {
+	g := gomega.NewWithT(t)
+	netDefClient := networkdefinitionfake.NewSimpleClientset(netDefs...)
+	// the fake clientset tracks the NADs it is seeded with under a resource
+	// it guesses wrong, create them through the client instead
+	nadClient := nadfake.NewSimpleClientset()
+	for _, obj := range nads {
+		nad := obj.(*nettypes.NetworkAttachmentDefinition)
+		_, err := nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(nad.Namespace).Create(context.TODO(), nad, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+	kubeClient := fake.NewSimpleClientset(namespaces...)
+
+	netDefFactory := networkdefinitioninformerfactory.NewSharedInformerFactory(netDefClient, 0)
+	kubeFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	controller, err := NewNetworkDefinitionController("test", netDefClient, nadClient,
+		netDefFactory.K8s().V1().NetworkDefinitions(), kubeFactory.Core().V1().Namespaces())
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	netDefFactory.Start(controller.stopChan)
+	kubeFactory.Start(controller.stopChan)
+	g.Expect(controller.Start()).To(gomega.Succeed())
+	t.Cleanup(controller.Stop)
+
+	return &networkDefinitionControllerTest{
+		controller:   controller,
+		netDefClient: netDefClient,
+		nadClient:    nadClient,
+		kubeClient:   kubeClient,
+	}
+}
+
+func (test *networkDefinitionControllerTest) getNetAttachDef(namespace, name string) func() *nettypes.NetworkAttachmentDefinition {
+	return func() *nettypes.NetworkAttachmentDefinition {
+		nad, err := test.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return nad
+	}
+}
+
+func (test *networkDefinitionControllerTest) getStatus(name string) func() networkdefinitionv1.NetworkDefinitionStatus {
+	return func() networkdefinitionv1.NetworkDefinitionStatus {
+		netDef, err := test.netDefClient.K8sV1().NetworkDefinitions().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return networkdefinitionv1.NetworkDefinitionStatus{}
+		}
+		return netDef.Status
+	}
+}
+
+func readyReason(status networkdefinitionv1.NetworkDefinitionStatus) string {
+	condition := meta.FindStatusCondition(status.Conditions, networkDefinitionReadyCondition)
+	if condition == nil {
+		return ""
+	}
+	return condition.Reason
+}
+
+func TestNetworkDefinitionControllerRendersNetAttachDefs(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+
+	netDef := newNetworkDefinition("tenantblue", networkdefinitionv1.NetworkSpec{
+		Topology: types.Layer3Topology,
+		Subnets:  []string{"10.128.0.0/16/24"},
+		MTU:      1300,
+	})
+	test := newNetworkDefinitionControllerTest(t,
+		[]runtime.Object{netDef},
+		nil,
+		[]runtime.Object{
+			newNamespace("ns1", map[string]string{"tenant": "blue"}),
+			newNamespace("ns2", map[string]string{"tenant": "blue"}),
+			newNamespace("ns3", map[string]string{"tenant": "red"}),
+		},
+	)
+
+	g.Eventually(test.getStatus(netDef.Name)).Should(gomega.HaveField("Namespaces", gomega.Equal([]string{"ns1", "ns2"})))
+	g.Expect(readyReason(test.getStatus(netDef.Name)())).To(gomega.Equal(networkDefinitionReasonRendered))
+	g.Expect(test.getNetAttachDef("ns3", netDef.Name)()).To(gomega.BeNil())
+
+	// the rendered NADs attach to the same network
+	for _, namespace := range []string{"ns1", "ns2"} {
+		nad := test.getNetAttachDef(namespace, netDef.Name)()
+		g.Expect(nad).NotTo(gomega.BeNil())
+		g.Expect(metav1.IsControlledBy(nad, netDef)).To(gomega.BeTrue())
+		g.Expect(nad.Labels).To(gomega.HaveKeyWithValue(types.NetworkDefinitionLabel, netDef.Name))
+		netInfo, err := util.ParseNADInfo(nad)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(netInfo.GetNetworkName()).To(gomega.Equal(netDef.Name))
+		g.Expect(netInfo.TopologyType()).To(gomega.Equal(types.Layer3Topology))
+		g.Expect(netInfo.MTU()).To(gomega.Equal(1300))
+	}
+
+	// the NAD is removed from the namespaces no longer selected and rendered
+	// in the newly selected ones
+	_, err := test.kubeClient.CoreV1().Namespaces().Update(context.TODO(),
+		newNamespace("ns2", map[string]string{"tenant": "red"}), metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	_, err = test.kubeClient.CoreV1().Namespaces().Update(context.TODO(),
+		newNamespace("ns3", map[string]string{"tenant": "blue"}), metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	g.Eventually(test.getStatus(netDef.Name)).Should(gomega.HaveField("Namespaces", gomega.Equal([]string{"ns1", "ns3"})))
+	g.Eventually(test.getNetAttachDef("ns2", netDef.Name)).Should(gomega.BeNil())
+	g.Expect(test.getNetAttachDef("ns3", netDef.Name)()).NotTo(gomega.BeNil())
+
+	// changes to the rendered NADs are reverted
+	nad := test.getNetAttachDef("ns1", netDef.Name)()
+	rendered := nad.Spec.Config
+	nad.Spec.Config = `{"cniVersion":"0.3.1","name":"tenantblue","type":"ovn-k8s-cni-overlay","topology":"layer2","netAttachDefName":"ns1/tenantblue"}`
+	_, err = test.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions("ns1").Update(context.TODO(), nad, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Eventually(func() string {
+		return test.getNetAttachDef("ns1", netDef.Name)().Spec.Config
+	}).WithTimeout(5 * time.Second).Should(gomega.Equal(rendered))
+}
+
+func TestNetworkDefinitionControllerConflicts(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+
+	netDef := newNetworkDefinition("tenantblue", networkdefinitionv1.NetworkSpec{
+		Topology: types.Layer2Topology,
+		Subnets:  []string{"192.168.0.0/24"},
+	})
+	userNAD := &nettypes.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: netDef.Name, Namespace: "ns2"},
+		Spec: nettypes.NetworkAttachmentDefinitionSpec{
+			Config: `{"cniVersion":"0.3.1","name":"other","type":"ovn-k8s-cni-overlay","topology":"layer2","netAttachDefName":"ns2/tenantblue"}`,
+		},
+	}
+	test := newNetworkDefinitionControllerTest(t,
+		[]runtime.Object{netDef},
+		[]runtime.Object{userNAD},
+		[]runtime.Object{
+			newNamespace("ns1", map[string]string{"tenant": "blue"}),
+			newNamespace("ns2", map[string]string{"tenant": "blue"}),
+		},
+	)
+
+	g.Eventually(test.getStatus(netDef.Name)).Should(gomega.HaveField("Conflicts", gomega.ConsistOf(
+		gomega.HaveField("Namespace", "ns2"),
+	)))
+	status := test.getStatus(netDef.Name)()
+	g.Expect(status.Namespaces).To(gomega.Equal([]string{"ns1"}))
+	g.Expect(readyReason(status)).To(gomega.Equal(networkDefinitionReasonConflicts))
+
+	// the NAD of the user is left untouched
+	nad := test.getNetAttachDef("ns2", netDef.Name)()
+	g.Expect(nad.Spec.Config).To(gomega.Equal(userNAD.Spec.Config))
+
+	// the conflict is resolved once the NAD of the user is removed
+	err := test.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions("ns2").Delete(context.TODO(), userNAD.Name, metav1.DeleteOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Eventually(test.getStatus(netDef.Name)).Should(gomega.HaveField("Namespaces", gomega.Equal([]string{"ns1", "ns2"})))
+	status = test.getStatus(netDef.Name)()
+	g.Expect(status.Conflicts).To(gomega.BeEmpty())
+	g.Expect(readyReason(status)).To(gomega.Equal(networkDefinitionReasonRendered))
+}
+
+func TestNetworkDefinitionControllerValidation(t *testing.T) {
+	tests := []struct {
+		desc           string
+		name           string
+		network        networkdefinitionv1.NetworkSpec
+		nads           []runtime.Object
+		expectedReason string
+	}{
+		{
+			desc: "invalid subnets",
+			name: "tenantblue",
+			network: networkdefinitionv1.NetworkSpec{
+				Topology: types.Layer3Topology,
+				Subnets:  []string{"10.128.0.0/33"},
+			},
+			expectedReason: networkDefinitionReasonInvalid,
+		},
+		{
+			desc: "excludes outside of the subnets",
+			name: "tenantblue",
+			network: networkdefinitionv1.NetworkSpec{
+				Topology:       types.LocalnetTopology,
+				Subnets:        []string{"192.168.0.0/24"},
+				ExcludeSubnets: []string{"192.168.1.0/28"},
+			},
+			expectedReason: networkDefinitionReasonInvalid,
+		},
+		{
+			desc: "reserved network name",
+			name: types.DefaultNetworkName,
+			network: networkdefinitionv1.NetworkSpec{
+				Topology: types.Layer2Topology,
+			},
+			expectedReason: networkDefinitionReasonInvalid,
+		},
+		{
+			desc: "network defined by a NAD",
+			name: "tenantblue",
+			network: networkdefinitionv1.NetworkSpec{
+				Topology: types.Layer2Topology,
+			},
+			nads: []runtime.Object{
+				&nettypes.NetworkAttachmentDefinition{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "ns2"},
+					Spec: nettypes.NetworkAttachmentDefinitionSpec{
+						Config: `{"cniVersion":"0.3.1","name":"tenantblue","type":"ovn-k8s-cni-overlay","topology":"layer2","netAttachDefName":"ns2/blue"}`,
+					},
+				},
+			},
+			expectedReason: networkDefinitionReasonNetworkInUse,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+
+			netDef := newNetworkDefinition(tc.name, tc.network)
+			test := newNetworkDefinitionControllerTest(t,
+				[]runtime.Object{netDef},
+				tc.nads,
+				[]runtime.Object{newNamespace("ns1", map[string]string{"tenant": "blue"})},
+			)
+
+			g.Eventually(func() string { return readyReason(test.getStatus(netDef.Name)()) }).Should(gomega.Equal(tc.expectedReason))
+			g.Expect(test.getNetAttachDef("ns1", netDef.Name)()).To(gomega.BeNil())
+		})
+	}
+}
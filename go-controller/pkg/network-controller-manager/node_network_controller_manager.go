@@ -15,6 +15,7 @@ import (
 	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -29,6 +30,9 @@ type nodeNetworkControllerManager struct {
 	watchFactory  factory.NodeWatchFactory
 	stopChan      chan struct{}
 	recorder      record.EventRecorder
+	// ovsClient is the client of the local Open_vSwitch database, nil in
+	// dpu-host mode
+	ovsClient libovsdbclient.Client
 
 	defaultNodeNetworkController nad.BaseNetworkController
 
@@ -54,12 +58,12 @@ func (ncm *nodeNetworkControllerManager) CleanupDeletedNetworks(allControllers [
 
 // newCommonNetworkControllerInfo creates and returns the base node network controller info
 func (ncm *nodeNetworkControllerManager) newCommonNetworkControllerInfo() *node.CommonNodeNetworkControllerInfo {
-	return node.NewCommonNodeNetworkControllerInfo(ncm.ovnNodeClient.KubeClient, ncm.ovnNodeClient.AdminPolicyRouteClient, ncm.watchFactory, ncm.recorder, ncm.name)
+	return node.NewCommonNodeNetworkControllerInfo(ncm.ovnNodeClient.KubeClient, ncm.ovnNodeClient.AdminPolicyRouteClient, ncm.watchFactory, ncm.recorder, ncm.name, ncm.ovsClient)
 }
 
 // NewNodeNetworkControllerManager creates a new OVN controller manager to manage all the controller for all networks
 func NewNodeNetworkControllerManager(ovnClient *util.OVNClientset, wf factory.NodeWatchFactory, name string,
-	eventRecorder record.EventRecorder) (*nodeNetworkControllerManager, error) {
+	eventRecorder record.EventRecorder, ovsClient libovsdbclient.Client) (*nodeNetworkControllerManager, error) {
 	ncm := &nodeNetworkControllerManager{
 		name:          name,
 		ovnNodeClient: &util.OVNNodeClientset{KubeClient: ovnClient.KubeClient, AdminPolicyRouteClient: ovnClient.AdminPolicyRouteClient},
@@ -67,11 +71,14 @@ func NewNodeNetworkControllerManager(ovnClient *util.OVNClientset, wf factory.No
 		watchFactory:  wf,
 		stopChan:      make(chan struct{}),
 		recorder:      eventRecorder,
+		ovsClient:     ovsClient,
 	}
 
-	// need to configure OVS interfaces for Pods on secondary networks in the DPU mode
+	// need to configure OVS interfaces for Pods on secondary networks in the DPU mode, and to learn the IPs of the
+	// Pods on IPAM-less secondary networks in full mode
 	var err error
-	if config.OVNKubernetesFeature.EnableMultiNetwork && config.OvnKubeNode.Mode == ovntypes.NodeModeDPU {
+	if config.OVNKubernetesFeature.EnableMultiNetwork && (config.OvnKubeNode.Mode == ovntypes.NodeModeDPU ||
+		(config.OvnKubeNode.Mode == ovntypes.NodeModeFull && util.IsObservedPodIPsEnabled())) {
 		ncm.nadController, err = nad.NewNetAttachDefinitionController("node-network-controller-manager", ncm, ovnClient.NetworkAttchDefClient, eventRecorder)
 	}
 	if err != nil {
@@ -103,6 +110,7 @@ func (ncm *nodeNetworkControllerManager) Start(ctx context.Context) (err error)
 	if err = cni.SetExec(kexec.New()); err != nil {
 		return err
 	}
+	cni.SetOVSClient(ncm.ovsClient)
 
 	err = ncm.watchFactory.Start()
 	if err != nil {
@@ -110,7 +110,7 @@ var _ = Describe("Node DPU tests", func() {
 		kubeMock = kubemocks.Interface{}
 		apbExternalRouteClient := adminpolicybasedrouteclient.NewSimpleClientset()
 		factoryMock = factorymocks.NodeWatchFactory{}
-		cnnci := newCommonNodeNetworkControllerInfo(nil, &kubeMock, apbExternalRouteClient, &factoryMock, nil, "")
+		cnnci := newCommonNodeNetworkControllerInfo(nil, &kubeMock, apbExternalRouteClient, &factoryMock, nil, "", nil)
 		dnnc = newDefaultNodeNetworkController(cnnci, nil, nil)
 
 		podInformer = coreinformermocks.PodInformer{}
@@ -0,0 +1,293 @@
+package bgp
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	kexec "k8s.io/utils/exec"
+	utilnet "k8s.io/utils/net"
+
+	egressipinformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/informers/externalversions/egressip/v1"
+	egressiplisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/listers/egressip/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+// reconcileKey is the only key of the queue: any event leads to a full
+// reconciliation of the advertised prefixes
+const reconcileKey = "bgp"
+
+// Controller advertises the host subnets of the node, the external and load
+// balancer IPs of services and the egress IPs assigned to the node to the BGP
+// peers through the local FRR instance. The FRR configuration is rendered
+// from scratch on every event and only applied when it changed.
+type Controller struct {
+	nodeName string
+
+	nodeLister          corelisters.NodeLister
+	serviceLister       corelisters.ServiceLister
+	endpointSliceLister discoverylisters.EndpointSliceLister
+	// eIPLister is nil when egress IP is disabled
+	eIPLister egressiplisters.EgressIPLister
+	synced    []cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	frr *frrManager
+	// applied is the last FRR configuration successfully applied
+	applied string
+}
+
+// NewController returns a BGP controller for the given node. eIPInformer may be
+// nil if egress IP is disabled.
+func NewController(nodeName string, nodeInformer, serviceInformer, endpointSliceInformer cache.SharedIndexInformer,
+	eIPInformer egressipinformer.EgressIPInformer, exec kexec.Interface) (*Controller, error) {
+	c := &Controller{
+		nodeName:            nodeName,
+		nodeLister:          corelisters.NewNodeLister(nodeInformer.GetIndexer()),
+		serviceLister:       corelisters.NewServiceLister(serviceInformer.GetIndexer()),
+		endpointSliceLister: discoverylisters.NewEndpointSliceLister(endpointSliceInformer.GetIndexer()),
+		synced:              []cache.InformerSynced{nodeInformer.HasSynced, serviceInformer.HasSynced, endpointSliceInformer.HasSynced},
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemFastSlowRateLimiter(time.Second, 5*time.Second, 5),
+			"bgp",
+		),
+		frr: newFRRManager(exec),
+	}
+
+	_, err := nodeInformer.AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onNodeAdd,
+		UpdateFunc: c.onNodeUpdate,
+		DeleteFunc: c.onNodeAdd,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for _, informer := range []cache.SharedIndexInformer{serviceInformer, endpointSliceInformer} {
+		_, err = informer.AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onAdd,
+			UpdateFunc: c.onUpdate,
+			DeleteFunc: c.onAdd,
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if eIPInformer != nil {
+		c.eIPLister = eIPInformer.Lister()
+		c.synced = append(c.synced, eIPInformer.Informer().HasSynced)
+		_, err = eIPInformer.Informer().AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onAdd,
+			UpdateFunc: c.onUpdate,
+			DeleteFunc: c.onAdd,
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Controller) onNodeAdd(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		node, ok = tombstone.Obj.(*corev1.Node)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a Node: %#v", tombstone.Obj))
+			return
+		}
+	}
+	if node.Name == c.nodeName {
+		c.queue.Add(reconcileKey)
+	}
+}
+
+func (c *Controller) onNodeUpdate(oldObj, newObj interface{}) {
+	oldNode := oldObj.(*corev1.Node)
+	newNode := newObj.(*corev1.Node)
+	if newNode.Name != c.nodeName || !util.NodeSubnetAnnotationChanged(oldNode, newNode) {
+		return
+	}
+	c.queue.Add(reconcileKey)
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	c.queue.Add(reconcileKey)
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	c.queue.Add(reconcileKey)
+}
+
+// Run waits for the informer caches to sync and starts the worker
+func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) error {
+	klog.Info("Starting BGP Controller")
+
+	if !util.WaitForNamedCacheSyncWithTimeout("bgp", stopCh, c.synced...) {
+		return fmt.Errorf("timed out waiting for caches to sync")
+	}
+
+	// make sure the configuration is applied at least once even when there
+	// is nothing to advertise
+	c.queue.Add(reconcileKey)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait.Until(c.runWorker, time.Second, stopCh)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// wait until we're told to stop
+		<-stopCh
+		c.queue.ShutDown()
+	}()
+	return nil
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(); err != nil {
+		// FRR may not be ready yet, keep retrying as the desired state
+		// is only computed in sync
+		klog.Errorf("Failed to sync BGP advertisements, retrying: %v", err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync() error {
+	prefixes, err := c.getAdvertisedPrefixes()
+	if err != nil {
+		return err
+	}
+	frrConfig, err := renderFRRConfig(prefixes)
+	if err != nil {
+		return err
+	}
+	if frrConfig == c.applied {
+		return nil
+	}
+	klog.Infof("Advertising prefixes %v to BGP peers", sets.List(prefixes))
+	if err = c.frr.apply(frrConfig); err != nil {
+		return err
+	}
+	c.applied = frrConfig
+	return nil
+}
+
+// getAdvertisedPrefixes returns the prefixes the node advertises: its host
+// subnets, the external and load balancer IPs of services, except those with
+// an external traffic policy local and no local endpoints, and the egress IPs
+// assigned to the node
+func (c *Controller) getAdvertisedPrefixes() (sets.Set[string], error) {
+	prefixes := sets.New[string]()
+
+	node, err := c.nodeLister.Get(c.nodeName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if node != nil {
+		hostSubnets, err := util.ParseNodeHostSubnetAnnotation(node, types.DefaultNetworkName)
+		if err != nil && !util.IsAnnotationNotSetError(err) {
+			return nil, fmt.Errorf("failed to parse the host subnets of node %s: %v", c.nodeName, err)
+		}
+		for _, hostSubnet := range hostSubnets {
+			prefixes.Insert(hostSubnet.String())
+		}
+	}
+
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		ips := util.GetExternalAndLBIPs(service)
+		if len(ips) == 0 {
+			continue
+		}
+		if util.ServiceExternalTrafficPolicyLocal(service) {
+			// only nodes with local endpoints attract the traffic of
+			// services with an external traffic policy local
+			endpointSlices, err := c.endpointSliceLister.EndpointSlices(service.Namespace).List(
+				labels.Set{discoveryv1.LabelServiceName: service.Name}.AsSelectorPreValidated())
+			if err != nil {
+				return nil, err
+			}
+			if len(util.GetLocalEndpointAddresses(endpointSlices, service, c.nodeName)) == 0 {
+				continue
+			}
+		}
+		for _, ip := range ips {
+			if prefix := hostPrefix(ip); prefix != "" {
+				prefixes.Insert(prefix)
+			}
+		}
+	}
+
+	if c.eIPLister != nil {
+		eIPs, err := c.eIPLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, eIP := range eIPs {
+			for _, status := range eIP.Status.Items {
+				if status.Node != c.nodeName {
+					continue
+				}
+				if prefix := hostPrefix(status.EgressIP); prefix != "" {
+					prefixes.Insert(prefix)
+				}
+			}
+		}
+	}
+
+	return prefixes, nil
+}
+
+// hostPrefix returns the host prefix of the given IP, or an empty string if it
+// is not a valid IP
+func hostPrefix(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if utilnet.IsIPv6(parsed) {
+		return (&net.IPNet{IP: parsed, Mask: net.CIDRMask(128, 128)}).String()
+	}
+	return (&net.IPNet{IP: parsed.To4(), Mask: net.CIDRMask(32, 32)}).String()
+}
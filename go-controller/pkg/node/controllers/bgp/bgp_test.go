@@ -0,0 +1,228 @@
+package bgp
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	egressipv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1"
+	egressipfake "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/clientset/versioned/fake"
+	egressipinformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/informers/externalversions"
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+)
+
+const nodeName = "node1"
+
+func TestRenderFRRConfig(t *testing.T) {
+	tests := []struct {
+		desc     string
+		peers    []string
+		prefixes []string
+		expected string
+	}{
+		{
+			desc:     "advertises ipv4 prefixes to ipv4 peers",
+			peers:    []string{"172.18.0.1"},
+			prefixes: []string{"10.244.0.0/24", "172.18.0.100/32"},
+			expected: `! Generated by ovnkube-node, do not edit
+frr defaults traditional
+log syslog informational
+!
+router bgp 64512
+ no bgp ebgp-requires-policy
+ no bgp default ipv4-unicast
+ no bgp network import-check
+ neighbor 172.18.0.1 remote-as 64500
+ !
+ address-family ipv4 unicast
+  network 10.244.0.0/24
+  network 172.18.0.100/32
+  neighbor 172.18.0.1 activate
+ exit-address-family
+exit
+!
+`,
+		},
+		{
+			desc:     "advertises each family to the peers of the same family only",
+			peers:    []string{"172.18.0.1", "fc00::1"},
+			prefixes: []string{"fd00:10:244:1::/64", "10.244.0.0/24"},
+			expected: `! Generated by ovnkube-node, do not edit
+frr defaults traditional
+log syslog informational
+!
+router bgp 64512
+ no bgp ebgp-requires-policy
+ no bgp default ipv4-unicast
+ no bgp network import-check
+ neighbor 172.18.0.1 remote-as 64500
+ neighbor fc00::1 remote-as 64500
+ !
+ address-family ipv4 unicast
+  network 10.244.0.0/24
+  neighbor 172.18.0.1 activate
+ exit-address-family
+ !
+ address-family ipv6 unicast
+  network fd00:10:244:1::/64
+  neighbor fc00::1 activate
+ exit-address-family
+exit
+!
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			config.BGP.ASN = 64512
+			config.BGP.PeerASN = 64500
+			for _, peer := range tt.peers {
+				config.BGP.Peers = append(config.BGP.Peers, net.ParseIP(peer))
+			}
+
+			frrConfig, err := renderFRRConfig(sets.New(tt.prefixes...))
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(frrConfig).To(gomega.Equal(tt.expected))
+		})
+	}
+}
+
+func TestGetAdvertisedPrefixes(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        nodeName,
+			Annotations: map[string]string{"k8s.ovn.org/node-subnets": `{"default":["10.244.1.0/24"]}`},
+		},
+	}
+	lbService := func(name string, etpLocal bool, ips ...string) *corev1.Service {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeCluster,
+			},
+		}
+		if etpLocal {
+			svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+		}
+		for _, ip := range ips {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return svc
+	}
+	endpointSlice := func(service, node string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      service + "-ab12",
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses:  []string{"10.244.1.5"},
+					NodeName:   pointer.String(node),
+					Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)},
+				},
+			},
+		}
+	}
+	egressIP := &egressipv1.EgressIP{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip"},
+		Status: egressipv1.EgressIPStatus{
+			Items: []egressipv1.EgressIPStatusItem{
+				{Node: nodeName, EgressIP: "172.18.0.50"},
+				{Node: "node2", EgressIP: "172.18.0.51"},
+			},
+		},
+	}
+
+	g := gomega.NewWithT(t)
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+
+	kubeClient := fake.NewSimpleClientset(
+		node,
+		lbService("cluster", false, "172.18.0.100"),
+		lbService("local", true, "172.18.0.101"),
+		endpointSlice("local", nodeName),
+		lbService("remote", true, "172.18.0.102"),
+		endpointSlice("remote", "node2"),
+	)
+	egressIPClient := egressipfake.NewSimpleClientset(egressIP)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	eIPInformerFactory := egressipinformerfactory.NewSharedInformerFactory(egressIPClient, 0)
+	c, err := NewController(nodeName,
+		informerFactory.Core().V1().Nodes().Informer(),
+		informerFactory.Core().V1().Services().Informer(),
+		informerFactory.Discovery().V1().EndpointSlices().Informer(),
+		eIPInformerFactory.K8s().V1().EgressIPs(),
+		ovntest.NewFakeExec(),
+	)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	eIPInformerFactory.Start(stopCh)
+	g.Expect(cache.WaitForCacheSync(stopCh, c.synced...)).To(gomega.BeTrue())
+
+	prefixes, err := c.getAdvertisedPrefixes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(sets.List(prefixes)).To(gomega.ConsistOf("10.244.1.0/24", "172.18.0.100/32", "172.18.0.101/32", "172.18.0.50/32"))
+}
+
+func TestSyncAppliesChangedConfig(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+	config.BGP.Enabled = true
+	config.BGP.ASN = 64512
+	config.BGP.PeerASN = 64512
+	config.BGP.Peers = []net.IP{net.ParseIP("172.18.0.1")}
+	config.BGP.FRRConfigFile = filepath.Join(t.TempDir(), "frr.conf")
+	config.BGP.FRRReloadCommand = "frr-reload.py --reload"
+
+	fexec := ovntest.NewFakeExec()
+	fexec.AddFakeCmd(&ovntest.ExpectedCmd{
+		Cmd: "frr-reload.py --reload " + config.BGP.FRRConfigFile,
+	})
+
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	c, err := NewController(nodeName,
+		informerFactory.Core().V1().Nodes().Informer(),
+		informerFactory.Core().V1().Services().Informer(),
+		informerFactory.Discovery().V1().EndpointSlices().Informer(),
+		nil,
+		fexec,
+	)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	g.Expect(cache.WaitForCacheSync(stopCh, c.synced...)).To(gomega.BeTrue())
+
+	g.Expect(c.sync()).To(gomega.Succeed())
+	g.Expect(fexec.CalledMatchesExpected()).To(gomega.BeTrue(), fexec.ErrorDesc)
+	written, err := os.ReadFile(config.BGP.FRRConfigFile)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(written)).To(gomega.Equal(c.applied))
+	g.Expect(string(written)).To(gomega.ContainSubstring("neighbor 172.18.0.1 remote-as 64512"))
+
+	// nothing changed, FRR must not be reloaded again
+	g.Expect(c.sync()).To(gomega.Succeed())
+	g.Expect(fexec.CalledMatchesExpected()).To(gomega.BeTrue(), fexec.ErrorDesc)
+}
@@ -0,0 +1,155 @@
+package bgp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	kexec "k8s.io/utils/exec"
+	utilnet "k8s.io/utils/net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+)
+
+var frrConfigTemplate = template.Must(template.New("frr").Parse(`! Generated by ovnkube-node, do not edit
+frr defaults traditional
+log syslog informational
+!
+router bgp {{ .ASN }}
+ no bgp ebgp-requires-policy
+ no bgp default ipv4-unicast
+ no bgp network import-check
+{{- range .Neighbors }}
+ neighbor {{ .Address }} remote-as {{ $.PeerASN }}
+{{- end }}
+{{- range .Families }}
+ !
+ address-family {{ .Name }} unicast
+{{- range .Networks }}
+  network {{ . }}
+{{- end }}
+{{- range .Neighbors }}
+  neighbor {{ .Address }} activate
+{{- end }}
+ exit-address-family
+{{- end }}
+exit
+!
+`))
+
+type frrNeighbor struct {
+	Address string
+}
+
+type frrAddressFamily struct {
+	Name      string
+	Networks  []string
+	Neighbors []frrNeighbor
+}
+
+type frrConfig struct {
+	ASN       uint
+	PeerASN   uint
+	Neighbors []frrNeighbor
+	Families  []frrAddressFamily
+}
+
+// renderFRRConfig renders the FRR configuration advertising the given prefixes
+// to the configured peers. Prefixes and peers are grouped by IP family, a
+// peer only receiving the prefixes of its own family.
+func renderFRRConfig(prefixes sets.Set[string]) (string, error) {
+	data := frrConfig{
+		ASN:     config.BGP.ASN,
+		PeerASN: config.BGP.PeerASN,
+	}
+	ipv4 := frrAddressFamily{Name: "ipv4"}
+	ipv6 := frrAddressFamily{Name: "ipv6"}
+	for _, peer := range config.BGP.Peers {
+		neighbor := frrNeighbor{Address: peer.String()}
+		data.Neighbors = append(data.Neighbors, neighbor)
+		if utilnet.IsIPv6(peer) {
+			ipv6.Neighbors = append(ipv6.Neighbors, neighbor)
+		} else {
+			ipv4.Neighbors = append(ipv4.Neighbors, neighbor)
+		}
+	}
+	for _, prefix := range sets.List(prefixes) {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return "", fmt.Errorf("invalid prefix %q: %v", prefix, err)
+		}
+		if utilnet.IsIPv6CIDR(ipNet) {
+			ipv6.Networks = append(ipv6.Networks, ipNet.String())
+		} else {
+			ipv4.Networks = append(ipv4.Networks, ipNet.String())
+		}
+	}
+	for _, family := range []frrAddressFamily{ipv4, ipv6} {
+		if len(family.Neighbors) > 0 {
+			data.Families = append(data.Families, family)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := frrConfigTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render FRR configuration: %v", err)
+	}
+	return b.String(), nil
+}
+
+// frrManager writes the FRR configuration file and reloads FRR with it
+type frrManager struct {
+	exec kexec.Interface
+}
+
+func newFRRManager(exec kexec.Interface) *frrManager {
+	return &frrManager{exec: exec}
+}
+
+func (m *frrManager) apply(frrConfig string) error {
+	if err := writeFileAtomic(config.BGP.FRRConfigFile, []byte(frrConfig)); err != nil {
+		return fmt.Errorf("failed to write FRR configuration %s: %v", config.BGP.FRRConfigFile, err)
+	}
+	args := strings.Fields(config.BGP.FRRReloadCommand)
+	if len(args) == 0 {
+		return fmt.Errorf("no FRR reload command configured")
+	}
+	path, err := m.exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to find FRR reload command %s: %v", args[0], err)
+	}
+	args = append(args, config.BGP.FRRConfigFile)
+	out, err := m.exec.Command(path, args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to reload FRR with %q: %v, output: %q", strings.Join(args, " "), err, string(out))
+	}
+	klog.V(5).Infof("Reloaded FRR with %s", config.BGP.FRRConfigFile)
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path and
+// renames it to path so that FRR never reads a partial configuration
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".ovnkube-frr-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,728 @@
+package conntrack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	egressipv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1"
+	egressipinformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/informers/externalversions/egressip/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+const (
+	// flushKey is the queue key of the pending conntrack deletions
+	flushKey = "flush"
+	// namespaceKeyPrefix prefixes the queue keys of the namespaces whose pods
+	// may be served by external gateways
+	namespaceKeyPrefix = "namespace/"
+
+	// batchPeriod is how long the conntrack deletions are gathered for before
+	// the conntrack table is dumped and the matching entries deleted
+	batchPeriod = 100 * time.Millisecond
+	// externalGatewaySyncPeriod is the period of the cleanup of the conntrack
+	// entries of the pods served by external gateways
+	externalGatewaySyncPeriod = time.Minute
+)
+
+// reasons the conntrack entries became stale, used as metric label
+const (
+	reasonEndpoint        = "endpoint"
+	reasonService         = "service"
+	reasonExternalGateway = "external-gateway"
+	reasonEgressIP        = "egress-ip"
+)
+
+// protocols are the protocols whose conntrack entries are cleaned up
+var protocols = []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP}
+
+// deletion describes the stale conntrack entries of an IP
+type deletion struct {
+	ip         string
+	port       int32
+	protocol   corev1.Protocol
+	filterType netlink.ConntrackFilterType
+	// labels are the labels of the entries to keep
+	labels [][]byte
+	reason string
+}
+
+func (d deletion) key() string {
+	return fmt.Sprintf("%s/%s/%d/%d/%x", d.ip, d.protocol, d.port, d.filterType, bytes.Join(d.labels, []byte(",")))
+}
+
+// batchFilter matches the conntrack entries of any of its deletions and counts
+// the entries matched by each of them
+type batchFilter struct {
+	deletions []deletion
+	filters   []*netlink.ConntrackFilter
+	matched   []uint
+}
+
+func (b *batchFilter) add(d deletion, filter *netlink.ConntrackFilter) {
+	b.deletions = append(b.deletions, d)
+	b.filters = append(b.filters, filter)
+	b.matched = append(b.matched, 0)
+}
+
+// MatchConntrackFlow implements netlink.CustomConntrackFilter
+func (b *batchFilter) MatchConntrackFlow(flow *netlink.ConntrackFlow) bool {
+	for i, filter := range b.filters {
+		if filter.MatchConntrackFlow(flow) {
+			b.matched[i]++
+			return true
+		}
+	}
+	return false
+}
+
+// Controller deletes the conntrack entries of the node that became stale
+// because of endpoint, service, external gateway and egress IP changes. Deletions are
+// batched so that the conntrack table is dumped once per IP family for all of
+// them, and only applied to the protocols whose cleanup policy is flush.
+type Controller struct {
+	nodeName string
+	policies map[string]config.ConntrackCleanupPolicy
+
+	serviceLister       corelisters.ServiceLister
+	endpointSliceLister discoverylisters.EndpointSliceLister
+	// namespaceLister and podLister are nil unless the external gateways are watched
+	namespaceLister corelisters.NamespaceLister
+	podLister       corelisters.PodLister
+	// getExternalGatewayIPs returns the IPs of the external gateways of a
+	// namespace set by admin policy based external routes
+	getExternalGatewayIPs func(namespace string) (sets.Set[string], error)
+	// getNodeIPs returns the IPs the node ports of the services are exposed on
+	getNodeIPs func() []net.IP
+	synced     []cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	lock sync.Mutex
+	// pending are the deletions not applied yet, by key
+	pending map[string]deletion
+}
+
+// NewController returns a conntrack controller for the given node applying the
+// given per protocol cleanup policies. getNodeIPs returns the IPs of the node
+// the node ports are exposed on, it may be nil if they are not exposed on the
+// node. eIPInformer may be nil if egress IP is disabled.
+func NewController(nodeName string, policies map[string]config.ConntrackCleanupPolicy,
+	serviceInformer, endpointSliceInformer cache.SharedIndexInformer,
+	eIPInformer egressipinformer.EgressIPInformer, getNodeIPs func() []net.IP) (*Controller, error) {
+	c := &Controller{
+		nodeName:            nodeName,
+		policies:            policies,
+		getNodeIPs:          getNodeIPs,
+		serviceLister:       corelisters.NewServiceLister(serviceInformer.GetIndexer()),
+		endpointSliceLister: discoverylisters.NewEndpointSliceLister(endpointSliceInformer.GetIndexer()),
+		synced:              []cache.InformerSynced{serviceInformer.HasSynced, endpointSliceInformer.HasSynced},
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemFastSlowRateLimiter(time.Second, 5*time.Second, 5),
+			"conntrack",
+		),
+		pending: map[string]deletion{},
+	}
+
+	// service deletion is considered as unplugging the network cable, so the
+	// entries towards the service are stale regardless of graceful termination.
+	// See https://github.com/kubernetes/kubernetes/issues/108523#issuecomment-1074044415.
+	_, err := serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: c.onServiceDelete,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// endpoint slice adds are ignored: nothing is stale yet, and services
+	// without endpoints have a placeholder slice, so that their first endpoint
+	// comes as an update
+	_, err = endpointSliceInformer.AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.onEndpointSliceUpdate,
+		DeleteFunc: c.onEndpointSliceDelete,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if eIPInformer != nil {
+		c.synced = append(c.synced, eIPInformer.Informer().HasSynced)
+		_, err = eIPInformer.Informer().AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: c.onEgressIPUpdate,
+			DeleteFunc: c.onEgressIPDelete,
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// WatchExternalGateways makes the controller delete the conntrack entries of
+// the pods of the node that are not going through the current external
+// gateways of their namespace anymore. It must be called before Run.
+func (c *Controller) WatchExternalGateways(namespaceInformer, podInformer cache.SharedIndexInformer,
+	getExternalGatewayIPs func(namespace string) (sets.Set[string], error)) error {
+	c.namespaceLister = corelisters.NewNamespaceLister(namespaceInformer.GetIndexer())
+	c.podLister = corelisters.NewPodLister(podInformer.GetIndexer())
+	c.getExternalGatewayIPs = getExternalGatewayIPs
+	c.synced = append(c.synced, namespaceInformer.HasSynced, podInformer.HasSynced)
+
+	_, err := namespaceInformer.AddEventHandler(factory.WithUpdateHandlingForObjReplace(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.onNamespaceUpdate,
+	}))
+	return err
+}
+
+// Run waits for the informer caches to sync and starts the worker
+func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) error {
+	klog.Info("Starting Conntrack Controller")
+
+	if !util.WaitForNamedCacheSyncWithTimeout("conntrack", stopCh, c.synced...) {
+		return fmt.Errorf("timed out waiting for caches to sync")
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait.Until(c.runWorker, time.Second, stopCh)
+	}()
+	if c.namespaceLister != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.syncExternalGatewayNamespaces, externalGatewaySyncPeriod, stopCh)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// wait until we're told to stop
+		<-stopCh
+		c.queue.ShutDown()
+	}()
+	return nil
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	key := obj.(string)
+	var err error
+	if key == flushKey {
+		err = c.flush()
+	} else {
+		err = c.syncExternalGateways(strings.TrimPrefix(key, namespaceKeyPrefix))
+	}
+	if err != nil {
+		klog.Errorf("Failed to sync conntrack for %s, retrying: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// flushes tells whether the stale conntrack entries of the protocol are deleted
+func (c *Controller) flushes(protocol corev1.Protocol) bool {
+	return c.policies[string(protocol)] == config.ConntrackCleanupPolicyFlush
+}
+
+// enqueue adds the deletions of the protocols whose policy is flush to the
+// next batch
+func (c *Controller) enqueue(deletions ...deletion) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	added := false
+	for _, d := range deletions {
+		if !c.flushes(d.protocol) {
+			klog.V(5).Infof("Keeping stale %s conntrack entries of %s (%s)", d.protocol, d.ip, d.reason)
+			continue
+		}
+		if _, ok := c.pending[d.key()]; !ok {
+			c.pending[d.key()] = d
+			added = true
+		}
+	}
+	if added {
+		c.queue.AddAfter(flushKey, batchPeriod)
+	}
+}
+
+// flush deletes the conntrack entries of the pending deletions, dumping the
+// conntrack table once per IP family. The deletions of a family that failed
+// are kept pending.
+func (c *Controller) flush() error {
+	c.lock.Lock()
+	pending := c.pending
+	c.pending = map[string]deletion{}
+	c.lock.Unlock()
+
+	batches := map[netlink.InetFamily]*batchFilter{}
+	for _, d := range pending {
+		filter, err := util.NewConntrackFilter(d.ip, d.port, d.protocol, d.filterType, d.labels)
+		if err != nil {
+			// retrying would not help
+			klog.Errorf("Skipping conntrack deletion for %s: %v", d.ip, err)
+			continue
+		}
+		family := netlink.InetFamily(netlink.FAMILY_V4)
+		if utilnet.IsIPv6String(d.ip) {
+			family = netlink.FAMILY_V6
+		}
+		if batches[family] == nil {
+			batches[family] = &batchFilter{}
+		}
+		batches[family].add(d, filter)
+	}
+
+	var errs []error
+	for family, batch := range batches {
+		start := time.Now()
+		_, err := util.GetNetLinkOps().ConntrackDeleteFilter(netlink.ConntrackTable, family, batch)
+		metrics.MetricConntrackDeleteBatchDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %d batched conntrack filters of family %d: %w",
+				len(batch.deletions), family, err))
+			c.requeue(batch.deletions)
+			continue
+		}
+		for i, d := range batch.deletions {
+			if batch.matched[i] == 0 {
+				continue
+			}
+			klog.V(5).Infof("Deleted %d stale %s conntrack entries of %s (%s)", batch.matched[i], d.protocol, d.ip, d.reason)
+			metrics.MetricConntrackDeletedEntries.WithLabelValues(d.reason, strings.ToLower(string(d.protocol))).
+				Add(float64(batch.matched[i]))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// requeue puts back failed deletions in the pending ones, unless they were
+// requested again meanwhile
+func (c *Controller) requeue(deletions []deletion) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, d := range deletions {
+		if _, ok := c.pending[d.key()]; !ok {
+			c.pending[d.key()] = d
+		}
+	}
+}
+
+func (c *Controller) onServiceDelete(obj interface{}) {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		svc, ok = tombstone.Obj.(*corev1.Service)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a Service: %#v", tombstone.Obj))
+			return
+		}
+	}
+	c.enqueue(c.serviceDeletions(svc)...)
+}
+
+// serviceDeletions returns the deletions of the conntrack entries towards the
+// VIPs and node ports of a deleted service
+func (c *Controller) serviceDeletions(svc *corev1.Service) []deletion {
+	if !util.ServiceTypeHasClusterIP(svc) || !util.IsClusterIPSet(svc) {
+		return nil
+	}
+	var deletions []deletion
+	vips := append(util.GetClusterIPs(svc), util.GetExternalAndLBIPs(svc)...)
+	for _, svcPort := range svc.Spec.Ports {
+		for _, vip := range vips {
+			deletions = append(deletions, deletion{
+				ip:         vip,
+				port:       svcPort.Port,
+				protocol:   svcPort.Protocol,
+				filterType: netlink.ConntrackOrigDstIP,
+				reason:     reasonService,
+			})
+		}
+	}
+	if !util.ServiceTypeHasNodePort(svc) || c.getNodeIPs == nil {
+		return deletions
+	}
+	for _, nodeIP := range c.getNodeIPs() {
+		for _, svcPort := range svc.Spec.Ports {
+			deletions = append(deletions, deletion{
+				ip:         nodeIP.String(),
+				port:       svcPort.NodePort,
+				protocol:   svcPort.Protocol,
+				filterType: netlink.ConntrackOrigDstIP,
+				reason:     reasonService,
+			})
+		}
+	}
+	return deletions
+}
+
+func (c *Controller) onEndpointSliceUpdate(oldObj, newObj interface{}) {
+	c.enqueue(c.endpointSliceDeletions(oldObj.(*discoveryv1.EndpointSlice), newObj.(*discoveryv1.EndpointSlice))...)
+}
+
+func (c *Controller) onEndpointSliceDelete(obj interface{}) {
+	endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		endpointSlice, ok = tombstone.Obj.(*discoveryv1.EndpointSlice)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not an EndpointSlice: %#v", tombstone.Obj))
+			return
+		}
+	}
+	c.enqueue(c.endpointSliceDeletions(endpointSlice, nil)...)
+}
+
+// endpointSliceDeletions returns the deletions of the conntrack entries made
+// stale by an endpoint slice change: the entries of the endpoints removed from
+// the slice and, when a service port gets its first serving endpoint, the
+// entries created towards the service VIPs while the port had no endpoint.
+// newEndpointSlice is nil upon delete.
+func (c *Controller) endpointSliceDeletions(oldEndpointSlice, newEndpointSlice *discoveryv1.EndpointSlice) []deletion {
+	endpointSlice := newEndpointSlice
+	if endpointSlice == nil {
+		endpointSlice = oldEndpointSlice
+	}
+	namespacedName, err := util.ServiceNamespacedNameFromEndpointSlice(endpointSlice)
+	if err != nil {
+		// not managed by a service
+		return nil
+	}
+	svc, err := c.serviceLister.Services(namespacedName.Namespace).Get(namespacedName.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Failed to get service %s of endpointslice %s/%s: %v",
+			namespacedName, endpointSlice.Namespace, endpointSlice.Name, err)
+		return nil
+	}
+
+	var deletions []deletion
+	if oldEndpointSlice != nil {
+		for _, oldPort := range oldEndpointSlice.Ports {
+			if oldPort.Port == nil || oldPort.Protocol == nil || util.ValidatePort(*oldPort.Protocol, *oldPort.Port) != nil {
+				continue
+			}
+			for _, oldEndpoint := range oldEndpointSlice.Endpoints {
+				for _, oldIP := range oldEndpoint.Addresses {
+					oldIPStr := utilnet.ParseIPSloppy(oldIP).String()
+					// upon an update event, only the IP addresses that are no
+					// longer in the endpointslice are stale
+					if newEndpointSlice != nil && util.DoesEndpointSliceContainEndpoint(newEndpointSlice, oldIPStr,
+						*oldPort.Port, *oldPort.Protocol, svc) {
+						continue
+					}
+					deletions = append(deletions, deletion{
+						ip:         oldIPStr,
+						port:       *oldPort.Port,
+						protocol:   *oldPort.Protocol,
+						filterType: netlink.ConntrackReplyAnyIP,
+						reason:     reasonEndpoint,
+					})
+				}
+			}
+		}
+	}
+
+	if newEndpointSlice == nil || svc == nil {
+		return deletions
+	}
+	newlyServed := servedPorts(newEndpointSlice).Difference(servedPorts(oldEndpointSlice))
+	if newlyServed.Len() == 0 {
+		return deletions
+	}
+	// the ports served by other endpoint slices of the service already had
+	// endpoints
+	endpointSlices, err := c.endpointSliceLister.EndpointSlices(svc.Namespace).List(labels.SelectorFromSet(
+		labels.Set{discoveryv1.LabelServiceName: svc.Name}))
+	if err != nil {
+		klog.Errorf("Failed to list endpointslices of service %s/%s: %v", svc.Namespace, svc.Name, err)
+		return deletions
+	}
+	for _, other := range endpointSlices {
+		if other.Name != newEndpointSlice.Name {
+			newlyServed = newlyServed.Difference(servedPorts(other))
+		}
+	}
+	vips := append(util.GetClusterIPs(svc), util.GetExternalAndLBIPs(svc)...)
+	for _, svcPort := range svc.Spec.Ports {
+		if !newlyServed.Has(portKey(svcPort.Name, svcPort.Protocol)) {
+			continue
+		}
+		for _, vip := range vips {
+			deletions = append(deletions, deletion{
+				ip:         vip,
+				port:       svcPort.Port,
+				protocol:   svcPort.Protocol,
+				filterType: netlink.ConntrackOrigDstIP,
+				reason:     reasonService,
+			})
+		}
+	}
+	return deletions
+}
+
+func portKey(name string, protocol corev1.Protocol) string {
+	return name + "/" + string(protocol)
+}
+
+// servedPorts returns the ports of the endpoint slice, identified by name and
+// protocol, if the slice has at least one serving endpoint
+func servedPorts(endpointSlice *discoveryv1.EndpointSlice) sets.Set[string] {
+	ports := sets.New[string]()
+	if endpointSlice == nil {
+		return ports
+	}
+	for _, endpoint := range endpointSlice.Endpoints {
+		if !util.IsEndpointServing(endpoint) {
+			continue
+		}
+		for _, port := range endpointSlice.Ports {
+			name := ""
+			if port.Name != nil {
+				name = *port.Name
+			}
+			protocol := corev1.ProtocolTCP
+			if port.Protocol != nil {
+				protocol = *port.Protocol
+			}
+			ports.Insert(portKey(name, protocol))
+		}
+		break
+	}
+	return ports
+}
+
+func (c *Controller) onEgressIPUpdate(oldObj, newObj interface{}) {
+	moved := c.localEgressIPs(oldObj.(*egressipv1.EgressIP)).Difference(c.localEgressIPs(newObj.(*egressipv1.EgressIP)))
+	c.enqueue(egressIPDeletions(moved)...)
+}
+
+func (c *Controller) onEgressIPDelete(obj interface{}) {
+	eIP, ok := obj.(*egressipv1.EgressIP)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		eIP, ok = tombstone.Obj.(*egressipv1.EgressIP)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not an EgressIP: %#v", tombstone.Obj))
+			return
+		}
+	}
+	c.enqueue(egressIPDeletions(c.localEgressIPs(eIP))...)
+}
+
+// localEgressIPs returns the egress IPs assigned to the node
+func (c *Controller) localEgressIPs(eIP *egressipv1.EgressIP) sets.Set[string] {
+	ips := sets.New[string]()
+	for _, status := range eIP.Status.Items {
+		if status.Node == c.nodeName {
+			ips.Insert(status.EgressIP)
+		}
+	}
+	return ips
+}
+
+// egressIPDeletions returns the deletions of the conntrack entries of the
+// connections SNATed to egress IPs that moved away from the node
+func egressIPDeletions(egressIPs sets.Set[string]) []deletion {
+	var deletions []deletion
+	for _, egressIP := range sets.List(egressIPs) {
+		for _, protocol := range protocols {
+			deletions = append(deletions, deletion{
+				ip:         egressIP,
+				protocol:   protocol,
+				filterType: netlink.ConntrackReplyDstIP,
+				reason:     reasonEgressIP,
+			})
+		}
+	}
+	return deletions
+}
+
+func (c *Controller) onNamespaceUpdate(oldObj, newObj interface{}) {
+	oldNs := oldObj.(*corev1.Namespace)
+	newNs := newObj.(*corev1.Namespace)
+	if exGatewayPodsAnnotationsChanged(oldNs, newNs) {
+		c.queue.Add(namespaceKeyPrefix + newNs.Name)
+	}
+}
+
+func exGatewayPodsAnnotationsChanged(oldNs, newNs *corev1.Namespace) bool {
+	// In reality we only care about exgw pod deletions, however since the list of IPs is not expected to change
+	// that often, let's check for *any* changes to these annotations compared to their previous state and trigger
+	// the logic for checking if we need to delete any conntrack entries
+	return (oldNs.Annotations[util.ExternalGatewayPodIPsAnnotation] != newNs.Annotations[util.ExternalGatewayPodIPsAnnotation]) ||
+		(oldNs.Annotations[util.RoutingExternalGWsAnnotation] != newNs.Annotations[util.RoutingExternalGWsAnnotation])
+}
+
+// syncExternalGatewayNamespaces queues the namespaces served by external
+// gateways through annotations that have pods on the node
+func (c *Controller) syncExternalGatewayNamespaces() {
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Unable to list namespaces: %v", err)
+		return
+	}
+	for _, namespace := range namespaces {
+		_, foundRoutingExternalGWsAnnotation := namespace.Annotations[util.RoutingExternalGWsAnnotation]
+		_, foundExternalGatewayPodIPsAnnotation := namespace.Annotations[util.ExternalGatewayPodIPsAnnotation]
+		if !foundRoutingExternalGWsAnnotation && !foundExternalGatewayPodIPsAnnotation {
+			continue
+		}
+		pods, err := c.podLister.Pods(namespace.Name).List(labels.Everything())
+		if err != nil {
+			klog.Warningf("Unable to list pods of namespace %s: %v", namespace.Name, err)
+		}
+		if len(pods) > 0 || err != nil {
+			// we only need to proceed if there is at least one pod in this namespace on this node
+			// OR if we couldn't fetch the pods for some reason at this juncture
+			c.queue.Add(namespaceKeyPrefix + namespace.Name)
+		}
+	}
+}
+
+// syncExternalGateways queues the deletion of the conntrack entries of the
+// pods of the namespace whose label is not the MAC of a current external
+// gateway of the namespace
+func (c *Controller) syncExternalGateways(namespace string) error {
+	ns, err := c.namespaceLister.Get(namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	gatewayIPs, err := c.getExternalGatewayIPs(ns.Name)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve gateway IPs for Admin Policy Based External Route objects: %w", err)
+	}
+	// the returned set must not be modified
+	gatewayIPs = gatewayIPs.Clone()
+	gatewayIPs.Insert(strings.Split(ns.Annotations[util.ExternalGatewayPodIPsAnnotation], ",")...)
+	gatewayIPs.Insert(strings.Split(ns.Annotations[util.RoutingExternalGWsAnnotation], ",")...)
+	validNextHopMACs := externalGatewayLabels(gatewayIPs)
+
+	pods, err := c.podLister.Pods(ns.Name).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("unable to list pods of namespace %s: %v", ns.Name, err)
+	}
+	var deletions []deletion
+	var errs []error
+	for _, pod := range pods {
+		podIPs, err := util.GetPodIPsOfNetwork(pod, &util.DefaultNetInfo{})
+		if err != nil && !errors.Is(err, util.ErrNoPodIPFound) {
+			errs = append(errs, fmt.Errorf("unable to fetch IP for pod %s/%s: %v", pod.Namespace, pod.Name, err))
+		}
+		for _, podIP := range podIPs {
+			for _, protocol := range protocols {
+				// for this pod, we check if the conntrack entry has a label that is not in the provided allowlist of MACs
+				// only caveat here is we assume egressGW served pods shouldn't have conntrack entries with other labels set
+				deletions = append(deletions, deletion{
+					ip:         podIP.String(),
+					protocol:   protocol,
+					filterType: netlink.ConntrackOrigDstIP,
+					labels:     validNextHopMACs,
+					reason:     reasonExternalGateway,
+				})
+			}
+		}
+	}
+	c.enqueue(deletions...)
+	return utilerrors.NewAggregate(errs)
+}
+
+// externalGatewayLabels ARPs for the MACs of the gateway IPs and returns them
+// in the format of the conntrack labels set by OVN
+func externalGatewayLabels(gatewayIPs sets.Set[string]) [][]byte {
+	var wg sync.WaitGroup
+	wg.Add(len(gatewayIPs))
+	validMACs := sync.Map{}
+	for gwIP := range gatewayIPs {
+		go func(gwIP string) {
+			defer wg.Done()
+			if len(gwIP) > 0 && !utilnet.IsIPv6String(gwIP) {
+				// TODO: Add support for IPv6 external gateways
+				if hwAddr, err := util.GetMACAddressFromARP(net.ParseIP(gwIP)); err != nil {
+					klog.Errorf("Failed to lookup hardware address for gatewayIP %s: %v", gwIP, err)
+				} else if len(hwAddr) > 0 {
+					// we need to reverse the mac before passing it to the conntrack filter since OVN saves the MAC in the following format
+					// +------------------------------------------------------------ +
+					// | 128 ...  112 ... 96 ... 80 ... 64 ... 48 ... 32 ... 16 ... 0|
+					// +------------------+-------+--------------------+-------------|
+					// |                  | UNUSED|    MAC ADDRESS     |   UNUSED    |
+					// +------------------+-------+--------------------+-------------+
+					for i, j := 0, len(hwAddr)-1; i < j; i, j = i+1, j-1 {
+						hwAddr[i], hwAddr[j] = hwAddr[j], hwAddr[i]
+					}
+					validMACs.Store(gwIP, []byte(hwAddr))
+				}
+			}
+		}(gwIP)
+	}
+	wg.Wait()
+
+	validNextHopMACs := [][]byte{}
+	validMACs.Range(func(key interface{}, value interface{}) bool {
+		validNextHopMACs = append(validNextHopMACs, value.([]byte))
+		return true
+	})
+	// Handle corner case where there are 0 IPs on the annotations OR none of the ARPs were successful; i.e allowMACList={empty}.
+	// This means we *need to* pass a label > 128 bits that will not match on any conntrack entry labels for these pods.
+	// That way any remaining entries with labels having MACs set will get purged.
+	if len(validNextHopMACs) == 0 {
+		validNextHopMACs = append(validNextHopMACs, []byte("does-not-contain-anything"))
+	}
+	// keep the deletion keys stable across syncs
+	sort.Slice(validNextHopMACs, func(i, j int) bool {
+		return bytes.Compare(validNextHopMACs[i], validNextHopMACs[j]) < 0
+	})
+	return validNextHopMACs
+}
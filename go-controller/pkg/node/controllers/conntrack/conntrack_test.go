@@ -0,0 +1,309 @@
+package conntrack
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/onsi/gomega"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	egressipv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1"
+	egressipfake "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/clientset/versioned/fake"
+	egressipinformerfactory "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/informers/externalversions"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
+)
+
+const nodeName = "node1"
+
+func newController(g *gomega.WithT, objects ...runtime.Object) (*Controller, informers.SharedInformerFactory) {
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(objects...), 0)
+	c, err := NewController(nodeName, config.OvnKubeNode.ConntrackCleanupPolicies,
+		informerFactory.Core().V1().Services().Informer(),
+		informerFactory.Discovery().V1().EndpointSlices().Informer(),
+		nil,
+		func() []net.IP { return []net.IP{net.ParseIP("172.18.0.2")} },
+	)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	return c, informerFactory
+}
+
+func newFlow(protocol uint8, srcIP, dstIP, backendIP string, dstPort uint16) *netlink.ConntrackFlow {
+	flow := &netlink.ConntrackFlow{}
+	flow.Forward.Protocol = protocol
+	flow.Forward.SrcIP = net.ParseIP(srcIP)
+	flow.Forward.DstIP = net.ParseIP(dstIP)
+	flow.Forward.DstPort = dstPort
+	flow.Reverse.Protocol = protocol
+	flow.Reverse.SrcIP = net.ParseIP(backendIP)
+	flow.Reverse.DstIP = net.ParseIP(srcIP)
+	return flow
+}
+
+func getDeletedEntries(g *gomega.WithT, labels ...string) float64 {
+	metric := &io_prometheus_client.Metric{}
+	g.Expect(metrics.MetricConntrackDeletedEntries.WithLabelValues(labels...).Write(metric)).To(gomega.Succeed())
+	return metric.GetCounter().GetValue()
+}
+
+func TestFlushBatchesDeletions(t *testing.T) {
+	g := gomega.NewWithT(t)
+	c, _ := newController(g)
+	c.policies = map[string]config.ConntrackCleanupPolicy{
+		"TCP":  config.ConntrackCleanupPolicyKeep,
+		"UDP":  config.ConntrackCleanupPolicyFlush,
+		"SCTP": config.ConntrackCleanupPolicyFlush,
+	}
+
+	nlMock := &mocks.NetLinkOps{}
+	util.SetNetLinkOpMockInst(nlMock)
+	defer util.ResetNetLinkOpMockInst()
+
+	var v4Filter, v6Filter netlink.CustomConntrackFilter
+	nlMock.On("ConntrackDeleteFilter", netlink.ConntrackTableType(netlink.ConntrackTable),
+		netlink.InetFamily(netlink.FAMILY_V4), mock.Anything).Run(func(args mock.Arguments) {
+		v4Filter = args.Get(2).(netlink.CustomConntrackFilter)
+		for _, flow := range []*netlink.ConntrackFlow{
+			newFlow(17, "10.96.0.10", "10.244.1.5", "10.244.1.5", 53),
+			newFlow(17, "10.244.2.3", "10.96.0.10", "10.244.1.5", 53),
+			newFlow(132, "10.244.2.3", "10.96.0.20", "10.244.1.6", 3868),
+			// kept: TCP policy is keep, UDP port does not match
+			newFlow(6, "10.244.2.3", "10.96.0.10", "10.244.1.5", 53),
+			newFlow(17, "10.244.2.3", "10.96.0.10", "10.244.1.5", 5353),
+		} {
+			v4Filter.MatchConntrackFlow(flow)
+		}
+	}).Return(uint(3), nil).Once()
+	nlMock.On("ConntrackDeleteFilter", netlink.ConntrackTableType(netlink.ConntrackTable),
+		netlink.InetFamily(netlink.FAMILY_V6), mock.Anything).Run(func(args mock.Arguments) {
+		v6Filter = args.Get(2).(netlink.CustomConntrackFilter)
+	}).Return(uint(0), fmt.Errorf("netlink error")).Once()
+
+	endpointsBefore := getDeletedEntries(g, reasonEndpoint, "udp")
+	sctpBefore := getDeletedEntries(g, reasonEndpoint, "sctp")
+	endpoint := deletion{ip: "10.244.1.5", port: 53, protocol: corev1.ProtocolUDP,
+		filterType: netlink.ConntrackReplyAnyIP, reason: reasonEndpoint}
+	v6Endpoint := deletion{ip: "fd00:10:244:1::5", port: 53, protocol: corev1.ProtocolUDP,
+		filterType: netlink.ConntrackReplyAnyIP, reason: reasonEndpoint}
+	c.enqueue(
+		endpoint,
+		// duplicates are merged
+		endpoint,
+		deletion{ip: "10.244.1.6", port: 3868, protocol: corev1.ProtocolSCTP,
+			filterType: netlink.ConntrackReplyAnyIP, reason: reasonEndpoint},
+		// TCP entries are kept by default
+		deletion{ip: "10.244.1.5", port: 53, protocol: corev1.ProtocolTCP,
+			filterType: netlink.ConntrackReplyAnyIP, reason: reasonEndpoint},
+		v6Endpoint,
+	)
+	g.Expect(c.pending).To(gomega.HaveLen(3))
+
+	err := c.flush()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("netlink error"))
+	nlMock.AssertExpectations(t)
+
+	// one filter per family for all the deletions of the batch
+	g.Expect(v4Filter.(*batchFilter).deletions).To(gomega.HaveLen(2))
+	g.Expect(v6Filter.(*batchFilter).deletions).To(gomega.ConsistOf(v6Endpoint))
+	g.Expect(getDeletedEntries(g, reasonEndpoint, "udp") - endpointsBefore).To(gomega.Equal(float64(2)))
+	g.Expect(getDeletedEntries(g, reasonEndpoint, "sctp") - sctpBefore).To(gomega.Equal(float64(1)))
+	// the deletions of the failed family are retried with the next batch
+	g.Expect(c.pending).To(gomega.Equal(map[string]deletion{v6Endpoint.key(): v6Endpoint}))
+}
+
+func TestEndpointSliceDeletions(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeClusterIP,
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports: []corev1.ServicePort{
+				{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53},
+				{Name: "dns-tcp", Protocol: corev1.ProtocolTCP, Port: 53},
+			},
+		},
+	}
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	endpointSlice := func(name string, ips ...string) *discoveryv1.EndpointSlice {
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "dns"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports: []discoveryv1.EndpointPort{
+				{Name: pointer.String("dns"), Protocol: &udp, Port: pointer.Int32(5353)},
+				{Name: pointer.String("dns-tcp"), Protocol: &tcp, Port: pointer.Int32(5353)},
+			},
+		}
+		for _, ip := range ips {
+			slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{ip},
+				Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)},
+			})
+		}
+		return slice
+	}
+	endpointDeletion := func(ip string, protocol corev1.Protocol) deletion {
+		return deletion{ip: ip, port: 5353, protocol: protocol, filterType: netlink.ConntrackReplyAnyIP, reason: reasonEndpoint}
+	}
+	serviceDeletion := func(protocol corev1.Protocol) deletion {
+		return deletion{ip: "10.96.0.10", port: 53, protocol: protocol, filterType: netlink.ConntrackOrigDstIP, reason: reasonService}
+	}
+
+	tests := []struct {
+		desc     string
+		old      *discoveryv1.EndpointSlice
+		new      *discoveryv1.EndpointSlice
+		others   []*discoveryv1.EndpointSlice
+		expected []deletion
+	}{
+		{
+			desc: "deletes the entries of the removed endpoints",
+			old:  endpointSlice("dns-ab12", "10.244.1.5", "10.244.1.6"),
+			new:  endpointSlice("dns-ab12", "10.244.1.6"),
+			expected: []deletion{
+				endpointDeletion("10.244.1.5", corev1.ProtocolUDP),
+				endpointDeletion("10.244.1.5", corev1.ProtocolTCP),
+			},
+		},
+		{
+			desc: "deletes the entries of all the endpoints of a deleted slice",
+			old:  endpointSlice("dns-ab12", "10.244.1.5"),
+			expected: []deletion{
+				endpointDeletion("10.244.1.5", corev1.ProtocolUDP),
+				endpointDeletion("10.244.1.5", corev1.ProtocolTCP),
+			},
+		},
+		{
+			desc: "deletes the entries towards the service VIP upon its first endpoint",
+			old:  endpointSlice("dns-ab12"),
+			new:  endpointSlice("dns-ab12", "10.244.1.5"),
+			expected: []deletion{
+				serviceDeletion(corev1.ProtocolUDP),
+				serviceDeletion(corev1.ProtocolTCP),
+			},
+		},
+		{
+			desc:   "keeps the entries towards the service VIP if another slice has endpoints",
+			old:    endpointSlice("dns-ab12"),
+			new:    endpointSlice("dns-ab12", "10.244.1.5"),
+			others: []*discoveryv1.EndpointSlice{endpointSlice("dns-cd34", "10.244.2.5")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			c, informerFactory := newController(g, service)
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			g.Expect(cache.WaitForCacheSync(stopCh, c.synced...)).To(gomega.BeTrue())
+			for _, other := range tt.others {
+				g.Expect(informerFactory.Discovery().V1().EndpointSlices().Informer().GetIndexer().Add(other)).To(gomega.Succeed())
+			}
+
+			g.Expect(c.endpointSliceDeletions(tt.old, tt.new)).To(gomega.ConsistOf(tt.expected))
+		})
+	}
+}
+
+func TestServiceDeletions(t *testing.T) {
+	g := gomega.NewWithT(t)
+	c, _ := newController(g)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:        corev1.ServiceTypeNodePort,
+			ClusterIP:   "10.96.0.10",
+			ClusterIPs:  []string{"10.96.0.10"},
+			ExternalIPs: []string{"172.18.0.100"},
+			Ports: []corev1.ServicePort{
+				{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53, NodePort: 30053},
+				{Name: "dns-tcp", Protocol: corev1.ProtocolTCP, Port: 53, NodePort: 30054},
+			},
+		},
+	}
+	serviceDeletion := func(ip string, port int32, protocol corev1.Protocol) deletion {
+		return deletion{ip: ip, port: port, protocol: protocol, filterType: netlink.ConntrackOrigDstIP, reason: reasonService}
+	}
+	g.Expect(c.serviceDeletions(service)).To(gomega.ConsistOf(
+		serviceDeletion("10.96.0.10", 53, corev1.ProtocolUDP),
+		serviceDeletion("10.96.0.10", 53, corev1.ProtocolTCP),
+		serviceDeletion("172.18.0.100", 53, corev1.ProtocolUDP),
+		serviceDeletion("172.18.0.100", 53, corev1.ProtocolTCP),
+		serviceDeletion("172.18.0.2", 30053, corev1.ProtocolUDP),
+		serviceDeletion("172.18.0.2", 30054, corev1.ProtocolTCP),
+	))
+
+	// the TCP entries are kept by default
+	c.onServiceDelete(cache.DeletedFinalStateUnknown{Key: "default/dns", Obj: service})
+	g.Expect(sets.List(sets.KeySet(c.pending))).To(gomega.ConsistOf(
+		serviceDeletion("10.96.0.10", 53, corev1.ProtocolUDP).key(),
+		serviceDeletion("172.18.0.100", 53, corev1.ProtocolUDP).key(),
+		serviceDeletion("172.18.0.2", 30053, corev1.ProtocolUDP).key(),
+	))
+
+	headless := service.DeepCopy()
+	headless.Spec.Type = corev1.ServiceTypeClusterIP
+	headless.Spec.ClusterIP = corev1.ClusterIPNone
+	headless.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+	g.Expect(c.serviceDeletions(headless)).To(gomega.BeEmpty())
+}
+
+func TestEgressIPDeletions(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+	eIP := &egressipv1.EgressIP{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip"},
+		Status: egressipv1.EgressIPStatus{
+			Items: []egressipv1.EgressIPStatusItem{
+				{Node: nodeName, EgressIP: "172.18.0.50"},
+				{Node: nodeName, EgressIP: "172.18.0.51"},
+				{Node: "node2", EgressIP: "172.18.0.52"},
+			},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	eIPInformerFactory := egressipinformerfactory.NewSharedInformerFactory(egressipfake.NewSimpleClientset(), 0)
+	c, err := NewController(nodeName, config.OvnKubeNode.ConntrackCleanupPolicies,
+		informerFactory.Core().V1().Services().Informer(),
+		informerFactory.Discovery().V1().EndpointSlices().Informer(),
+		eIPInformerFactory.K8s().V1().EgressIPs(),
+		nil,
+	)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	moved := eIP.DeepCopy()
+	moved.Status.Items[0].Node = "node2"
+	c.onEgressIPUpdate(eIP, moved)
+	// only UDP entries are flushed by default
+	g.Expect(sets.List(sets.KeySet(c.pending))).To(gomega.Equal([]string{
+		deletion{ip: "172.18.0.50", protocol: corev1.ProtocolUDP, filterType: netlink.ConntrackReplyDstIP}.key(),
+	}))
+
+	c.onEgressIPDelete(moved)
+	g.Expect(c.pending).To(gomega.HaveLen(2))
+	g.Expect(c.pending).To(gomega.HaveKey(
+		deletion{ip: "172.18.0.51", protocol: corev1.ProtocolUDP, filterType: netlink.ConntrackReplyDstIP}.key()))
+}
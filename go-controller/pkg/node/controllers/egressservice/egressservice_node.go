@@ -541,8 +541,9 @@ func (c *Controller) repairIPTables(v4EpsToServices, v6EpsToServices map[string]
 
 		errorList := []error{}
 		for _, rule := range rulesToDel {
-			args := strings.Fields(rule)
-			err := ipt.Delete("nat", Chain, args...)
+			// cleave off the -A ${chain_name}
+			args := strings.TrimPrefix(rule, fmt.Sprintf("-A %s ", Chain))
+			err := ipt.Delete("nat", Chain, strings.Fields(args)...)
 			if err != nil {
 				errorList = append(errorList, err)
 			}
@@ -12,13 +12,11 @@ import (
 	"time"
 
 	kapi "k8s.io/api/core/v1"
-	discovery "k8s.io/api/discovery/v1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
-	apierrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	kexec "k8s.io/utils/exec"
 	utilnet "k8s.io/utils/net"
 
 	v1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -26,9 +24,13 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni"
 	config "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	adminpolicybasedrouteclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/adminpolicybasedroute/v1/apis/clientset/versioned"
+	egressipinformer "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/informers/externalversions/egressip/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/informer"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/controllers/bgp"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/controllers/conntrack"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/controllers/egressip"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/controllers/egressservice"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/controllers/upgrade"
@@ -37,12 +39,12 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/controller/apbroute"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/healthcheck"
-	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/retry"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
 	"github.com/containernetworking/plugins/pkg/ip"
-	"github.com/vishvananda/netlink"
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 )
 
 type CommonNodeNetworkControllerInfo struct {
@@ -52,6 +54,9 @@ type CommonNodeNetworkControllerInfo struct {
 	recorder               record.EventRecorder
 	name                   string
 	apbExternalRouteClient adminpolicybasedrouteclientset.Interface
+	// ovsClient is the client of the local Open_vSwitch database, nil in DPU
+	// host mode where there is no local OVS
+	ovsClient libovsdbclient.Client
 }
 
 // BaseNodeNetworkController structure per-network fields and network specific configuration
@@ -74,7 +79,7 @@ type BaseNodeNetworkController struct {
 }
 
 func newCommonNodeNetworkControllerInfo(kubeClient clientset.Interface, kube kube.Interface, apbExternalRouteClient adminpolicybasedrouteclientset.Interface,
-	wf factory.NodeWatchFactory, eventRecorder record.EventRecorder, name string) *CommonNodeNetworkControllerInfo {
+	wf factory.NodeWatchFactory, eventRecorder record.EventRecorder, name string, ovsClient libovsdbclient.Client) *CommonNodeNetworkControllerInfo {
 
 	return &CommonNodeNetworkControllerInfo{
 		client:                 kubeClient,
@@ -83,13 +88,14 @@ func newCommonNodeNetworkControllerInfo(kubeClient clientset.Interface, kube kub
 		watchFactory:           wf,
 		name:                   name,
 		recorder:               eventRecorder,
+		ovsClient:              ovsClient,
 	}
 }
 
 // NewCommonNodeNetworkControllerInfo creates and returns the base node network controller info
 func NewCommonNodeNetworkControllerInfo(kubeClient clientset.Interface, apbExternalRouteClient adminpolicybasedrouteclientset.Interface, wf factory.NodeWatchFactory,
-	eventRecorder record.EventRecorder, name string) *CommonNodeNetworkControllerInfo {
-	return newCommonNodeNetworkControllerInfo(kubeClient, &kube.Kube{KClient: kubeClient}, apbExternalRouteClient, wf, eventRecorder, name)
+	eventRecorder record.EventRecorder, name string, ovsClient libovsdbclient.Client) *CommonNodeNetworkControllerInfo {
+	return newCommonNodeNetworkControllerInfo(kubeClient, &kube.Kube{KClient: kubeClient}, apbExternalRouteClient, wf, eventRecorder, name, ovsClient)
 }
 
 // DefaultNodeNetworkController is the object holder for utilities meant for node management of default network
@@ -101,11 +107,6 @@ type DefaultNodeNetworkController struct {
 	healthzServer *proxierHealthUpdater
 	routeManager  *routemanager.Controller
 
-	// retry framework for namespaces, used for the removal of stale conntrack entries for external gateways
-	retryNamespaces *retry.RetryFramework
-	// retry framework for endpoint slices, used for the removal of stale conntrack entries for services
-	retryEndpointSlices *retry.RetryFramework
-
 	apbExternalRouteNodeController *apbroute.ExternalGatewayNodeController
 }
 
@@ -148,16 +149,9 @@ func NewDefaultNodeNetworkController(cnnci *CommonNodeNetworkControllerInfo) (*D
 		return nil, err
 	}
 
-	nc.initRetryFrameworkForNode()
-
 	return nc, nil
 }
 
-func (nc *DefaultNodeNetworkController) initRetryFrameworkForNode() {
-	nc.retryNamespaces = nc.newRetryFrameworkNode(factory.NamespaceExGwType)
-	nc.retryEndpointSlices = nc.newRetryFrameworkNode(factory.EndpointSliceForStaleConntrackRemovalType)
-}
-
 func clearOVSFlowTargets() error {
 	_, _, err := util.RunOVSVsctl(
 		"--",
@@ -271,7 +265,7 @@ func setOVSFlowTargets(node *kapi.Node) error {
 	return nil
 }
 
-func setupOVNNode(node *kapi.Node) error {
+func setupOVNNode(ovsClient libovsdbclient.Client, node *kapi.Node) error {
 	var err error
 
 	encapIP := config.Default.EncapIP
@@ -287,45 +281,39 @@ func setupOVNNode(node *kapi.Node) error {
 		}
 	}
 
-	setExternalIdsCmd := []string{
-		"set",
-		"Open_vSwitch",
-		".",
-		fmt.Sprintf("external_ids:ovn-encap-type=%s", config.Default.EncapType),
-		fmt.Sprintf("external_ids:ovn-encap-ip=%s", encapIP),
-		fmt.Sprintf("external_ids:ovn-remote-probe-interval=%d",
-			config.Default.InactivityProbe),
-		fmt.Sprintf("external_ids:ovn-openflow-probe-interval=%d",
-			config.Default.OpenFlowProbe),
-		// bundle-idle-timeout default value is 10s, it should be set
-		// as high as the ovn-openflow-probe-interval to allow ovn-controller
-		// to finish computation specially with complex acl configuration with port range.
-		fmt.Sprintf("other_config:bundle-idle-timeout=%d",
-			config.Default.OpenFlowProbe),
-		fmt.Sprintf("external_ids:hostname=\"%s\"", node.Name),
-		// If Interconnect feature is enabled, we want to tell ovn-controller to
-		// make this node/chassis as an interconnect gateway.
-		fmt.Sprintf("external_ids:ovn-is-interconn=%s", strconv.FormatBool(config.OVNKubernetesFeature.EnableInterconnect)),
-		fmt.Sprintf("external_ids:ovn-monitor-all=%t", config.Default.MonitorAll),
-		fmt.Sprintf("external_ids:ovn-ofctrl-wait-before-clear=%d", config.Default.OfctrlWaitBeforeClear),
-		fmt.Sprintf("external_ids:ovn-enable-lflow-cache=%t", config.Default.LFlowCacheEnable),
+	openvSwitch := &vswitchd.OpenvSwitch{
+		ExternalIDs: map[string]string{
+			"ovn-encap-type":              config.Default.EncapType,
+			"ovn-encap-ip":                encapIP,
+			"ovn-remote-probe-interval":   strconv.Itoa(config.Default.InactivityProbe),
+			"ovn-openflow-probe-interval": strconv.Itoa(config.Default.OpenFlowProbe),
+			"hostname":                    node.Name,
+			// If Interconnect feature is enabled, we want to tell ovn-controller to
+			// make this node/chassis as an interconnect gateway.
+			"ovn-is-interconn":             strconv.FormatBool(config.OVNKubernetesFeature.EnableInterconnect),
+			"ovn-monitor-all":              strconv.FormatBool(config.Default.MonitorAll),
+			"ovn-ofctrl-wait-before-clear": strconv.Itoa(config.Default.OfctrlWaitBeforeClear),
+			"ovn-enable-lflow-cache":       strconv.FormatBool(config.Default.LFlowCacheEnable),
+		},
+		OtherConfig: map[string]string{
+			// bundle-idle-timeout default value is 10s, it should be set
+			// as high as the ovn-openflow-probe-interval to allow ovn-controller
+			// to finish computation specially with complex acl configuration with port range.
+			"bundle-idle-timeout": strconv.Itoa(config.Default.OpenFlowProbe),
+		},
 	}
 
 	if config.Default.LFlowCacheLimit > 0 {
-		setExternalIdsCmd = append(setExternalIdsCmd,
-			fmt.Sprintf("external_ids:ovn-limit-lflow-cache=%d", config.Default.LFlowCacheLimit),
-		)
+		openvSwitch.ExternalIDs["ovn-limit-lflow-cache"] = strconv.FormatUint(uint64(config.Default.LFlowCacheLimit), 10)
 	}
 
 	if config.Default.LFlowCacheLimitKb > 0 {
-		setExternalIdsCmd = append(setExternalIdsCmd,
-			fmt.Sprintf("external_ids:ovn-memlimit-lflow-cache-kb=%d", config.Default.LFlowCacheLimitKb),
-		)
+		openvSwitch.ExternalIDs["ovn-memlimit-lflow-cache-kb"] = strconv.FormatUint(uint64(config.Default.LFlowCacheLimitKb), 10)
 	}
 
-	_, stderr, err := util.RunOVSVsctl(setExternalIdsCmd...)
+	err = libovsdbops.UpdateOpenvSwitchSetExternalIDsAndOtherConfig(ovsClient, openvSwitch)
 	if err != nil {
-		return fmt.Errorf("error setting OVS external IDs: %v\n  %q", err, stderr)
+		return fmt.Errorf("error setting OVS external IDs: %v", err)
 	}
 
 	// clear stale ovs flow targets if needed
@@ -342,10 +330,18 @@ func setupOVNNode(node *kapi.Node) error {
 	return nil
 }
 
-func setEncapPort(ctx context.Context) error {
-	systemID, err := util.GetNodeChassisID()
+// setEncapPort sets the tunnel destination port on the encap of the local
+// chassis. The chassis ID is read from the local Open_vSwitch database, the
+// encap itself lives in the southbound database which ovnkube-node has no
+// client for, so it is still updated through ovn-sbctl.
+func setEncapPort(ctx context.Context, ovsClient libovsdbclient.Client) error {
+	openvSwitch, err := libovsdbops.GetOpenvSwitch(ovsClient)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get the Open_vSwitch entry: %w", err)
+	}
+	systemID := openvSwitch.ExternalIDs["system-id"]
+	if systemID == "" {
+		return fmt.Errorf("no system-id configured in the local host")
 	}
 	var uuid string
 	err = wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, 300*time.Second, true,
@@ -699,6 +695,11 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 		nc.routeManager.Run(nc.stopChan, 4*time.Minute)
 	}()
 
+	if config.OvnKubeNode.FirewallBackend == config.FirewallBackendNFTables {
+		// remove what the iptables backend may have programmed before switching to nftables
+		cleanupLegacyIPTables()
+	}
+
 	if node, err = nc.Kube.GetNode(nc.name); err != nil {
 		return fmt.Errorf("error retrieving node %s: %v", nc.name, err)
 	}
@@ -747,7 +748,7 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 			}
 		}
 
-		err = setupOVNNode(node)
+		err = setupOVNNode(nc.ovsClient, node)
 		if err != nil {
 			return err
 		}
@@ -840,7 +841,7 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 	// NOTE: ovnkube-node in DPU-host mode has no SBDB to connect to. The encap port will be handled by the
 	// ovnkube-node running in DPU mode on behalf of the host.
 	if config.OvnKubeNode.Mode != types.NodeModeDPUHost && config.Default.EncapPort != config.DefaultEncapPort {
-		if err := setEncapPort(ctx); err != nil {
+		if err := setEncapPort(ctx, nc.ovsClient); err != nil {
 			return err
 		}
 	}
@@ -990,7 +991,7 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 			bridgeName = nc.gateway.GetGatewayBridgeIface()
 
 			needLegacySvcRoute := true
-			if (initialTopoVersion >= types.OvnHostToSvcOFTopoVersion && config.GatewayModeShared == config.Gateway.Mode) ||
+			if (initialTopoVersion >= types.OvnHostToSvcOFTopoVersion && config.GatewayModeShared == config.GetGatewayMode()) ||
 				(initialTopoVersion >= types.OvnRoutingViaHostTopoVersion) {
 				// Configure route for svc towards shared gw bridge
 				// Have to have the route to bridge for multi-NIC mode, where the default gateway may go to a non-OVS interface
@@ -1038,9 +1039,9 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 			// upgrade complete now see what needs upgrading
 			if config.OvnKubeNode.Mode == types.NodeModeFull {
 				// migrate service route from ovn-k8s-mp0 to shared gw bridge
-				if (initialTopoVersion < types.OvnHostToSvcOFTopoVersion && config.GatewayModeShared == config.Gateway.Mode) ||
+				if (initialTopoVersion < types.OvnHostToSvcOFTopoVersion && config.GatewayModeShared == config.GetGatewayMode()) ||
 					(initialTopoVersion < types.OvnRoutingViaHostTopoVersion) {
-					if err := upgradeServiceRoute(nc.routeManager, bridgeName); err != nil {
+					if err := upgradeServiceRoute(nc.routeManager, nc.ovsClient, bridgeName); err != nil {
 						klog.Fatalf("Failed to upgrade service route for node, error: %v", err)
 					}
 				}
@@ -1084,8 +1085,9 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 	}
 
 	// start management ports health check
+	mgmtPortHealth := newManagementPortHealth(nc.name, nc.recorder)
 	for _, mgmtPort := range mgmtPorts {
-		mgmtPort.port.CheckManagementPortHealth(nc.routeManager, mgmtPort.config, nc.stopChan)
+		mgmtPort.port.CheckManagementPortHealth(nc.routeManager, mgmtPort.config, mgmtPortHealth, nc.stopChan)
 		if config.OVNKubernetesFeature.EnableEgressIP {
 			// Start the health checking server used by egressip, if EgressIPNodeHealthCheckPort is specified
 			if err := nc.startEgressIPHealthCheckingServer(mgmtPort); err != nil {
@@ -1095,6 +1097,20 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 	}
 
 	if config.OvnKubeNode.Mode != types.NodeModeDPUHost {
+		wf := nc.watchFactory.(*factory.WatchFactory)
+		var eIPInformer egressipinformer.EgressIPInformer
+		if config.OVNKubernetesFeature.EnableEgressIP {
+			eIPInformer = wf.EgressIPInformer()
+		}
+		var getNodeIPs func() []net.IP
+		if gw, ok := nc.gateway.(*gateway); ok && gw.nodeIPManager != nil {
+			getNodeIPs = gw.nodeIPManager.ListAddresses
+		}
+		c, err := conntrack.NewController(nc.name, config.OvnKubeNode.ConntrackCleanupPolicies, wf.ServiceInformer(),
+			wf.EndpointSliceInformer(), eIPInformer, getNodeIPs)
+		if err != nil {
+			return fmt.Errorf("failed to create conntrack controller: %w", err)
+		}
 		// If interconnect is disabled OR interconnect is running in single-zone-mode,
 		// the ovnkube-master is responsible for patching ICNI managed namespaces with
 		// "k8s.ovn.org/external-gw-pod-ips". In that case, we need ovnkube-node to flush
@@ -1102,18 +1118,14 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 		// directly on the ovnkube-controller code to avoid an extra namespace annotation
 		if !config.OVNKubernetesFeature.EnableInterconnect || sbZone == types.OvnDefaultZone {
 			util.SetARPTimeout()
-			err := nc.WatchNamespaces()
+			err = c.WatchExternalGateways(wf.NamespaceInformer().Informer(), wf.PodCoreInformer().Informer(),
+				nc.apbExternalRouteNodeController.GetAdminPolicyBasedExternalRouteIPsForTargetNamespace)
 			if err != nil {
-				return fmt.Errorf("failed to watch namespaces: %w", err)
+				return fmt.Errorf("failed to watch external gateways: %w", err)
 			}
-			// every minute cleanup stale conntrack entries if any
-			go wait.Until(func() {
-				nc.checkAndDeleteStaleConntrackEntries()
-			}, time.Minute*1, nc.stopChan)
 		}
-		err = nc.WatchEndpointSlices()
-		if err != nil {
-			return fmt.Errorf("failed to watch endpointSlices: %w", err)
+		if err = c.Run(nc.stopChan, nc.wg); err != nil {
+			return fmt.Errorf("failed to run conntrack controller: %w", err)
 		}
 	}
 
@@ -1169,6 +1181,22 @@ func (nc *DefaultNodeNetworkController) Start(ctx context.Context) error {
 		klog.Infof("Egress IP for non-OVN managed networks is disabled")
 	}
 
+	if config.BGP.Enabled {
+		wf := nc.watchFactory.(*factory.WatchFactory)
+		var eIPInformer egressipinformer.EgressIPInformer
+		if config.OVNKubernetesFeature.EnableEgressIP {
+			eIPInformer = wf.EgressIPInformer()
+		}
+		c, err := bgp.NewController(nc.name, wf.NodeInformer(), wf.ServiceInformer(), wf.EndpointSliceInformer(),
+			eIPInformer, kexec.New())
+		if err != nil {
+			return fmt.Errorf("failed to create BGP controller: %v", err)
+		}
+		if err = c.Run(nc.stopChan, nc.wg); err != nil {
+			return fmt.Errorf("failed to run BGP controller: %v", err)
+		}
+	}
+
 	nc.wg.Add(1)
 	go func() {
 		defer nc.wg.Done()
@@ -1226,155 +1254,6 @@ func (nc *DefaultNodeNetworkController) startEgressIPHealthCheckingServer(mgmtPo
 	return nil
 }
 
-func (nc *DefaultNodeNetworkController) reconcileConntrackUponEndpointSliceEvents(oldEndpointSlice, newEndpointSlice *discovery.EndpointSlice) error {
-	var errors []error
-	if oldEndpointSlice == nil {
-		// nothing to do upon an add event
-		return nil
-	}
-	namespacedName, err := util.ServiceNamespacedNameFromEndpointSlice(oldEndpointSlice)
-	if err != nil {
-		return fmt.Errorf("cannot reconcile conntrack: %v", err)
-	}
-	svc, err := nc.watchFactory.GetService(namespacedName.Namespace, namespacedName.Name)
-	if err != nil && !kerrors.IsNotFound(err) {
-		return fmt.Errorf("error while retrieving service for endpointslice %s/%s when reconciling conntrack: %v",
-			newEndpointSlice.Namespace, newEndpointSlice.Name, err)
-	}
-	for _, oldPort := range oldEndpointSlice.Ports {
-		if *oldPort.Protocol != kapi.ProtocolUDP { // flush conntrack only for UDP
-			continue
-		}
-		for _, oldEndpoint := range oldEndpointSlice.Endpoints {
-			for _, oldIP := range oldEndpoint.Addresses {
-				oldIPStr := utilnet.ParseIPSloppy(oldIP).String()
-				// upon an update event, remove conntrack entries for IP addresses that are no longer
-				// in the endpointslice, skip otherwise
-				if newEndpointSlice != nil && util.DoesEndpointSliceContainEndpoint(newEndpointSlice, oldIPStr, *oldPort.Port, *oldPort.Protocol, svc) {
-					continue
-				}
-				// upon update and delete events, flush conntrack only for UDP
-				if err := util.DeleteConntrackServicePort(oldIPStr, *oldPort.Port, *oldPort.Protocol,
-					netlink.ConntrackReplyAnyIP, nil); err != nil {
-					klog.Errorf("Failed to delete conntrack entry for %s: %v", oldIPStr, err)
-				}
-			}
-		}
-	}
-	return apierrors.NewAggregate(errors)
-
-}
-func (nc *DefaultNodeNetworkController) WatchEndpointSlices() error {
-	_, err := nc.retryEndpointSlices.WatchResource()
-	return err
-}
-
-func exGatewayPodsAnnotationsChanged(oldNs, newNs *kapi.Namespace) bool {
-	// In reality we only care about exgw pod deletions, however since the list of IPs is not expected to change
-	// that often, let's check for *any* changes to these annotations compared to their previous state and trigger
-	// the logic for checking if we need to delete any conntrack entries
-	return (oldNs.Annotations[util.ExternalGatewayPodIPsAnnotation] != newNs.Annotations[util.ExternalGatewayPodIPsAnnotation]) ||
-		(oldNs.Annotations[util.RoutingExternalGWsAnnotation] != newNs.Annotations[util.RoutingExternalGWsAnnotation])
-}
-
-func (nc *DefaultNodeNetworkController) checkAndDeleteStaleConntrackEntries() {
-	namespaces, err := nc.watchFactory.GetNamespaces()
-	if err != nil {
-		klog.Errorf("Unable to get pods from informer: %v", err)
-	}
-	for _, namespace := range namespaces {
-		_, foundRoutingExternalGWsAnnotation := namespace.Annotations[util.RoutingExternalGWsAnnotation]
-		_, foundExternalGatewayPodIPsAnnotation := namespace.Annotations[util.ExternalGatewayPodIPsAnnotation]
-		if foundRoutingExternalGWsAnnotation || foundExternalGatewayPodIPsAnnotation {
-			pods, err := nc.watchFactory.GetPods(namespace.Name)
-			if err != nil {
-				klog.Warningf("Unable to get pods from informer for namespace %s: %v", namespace.Name, err)
-			}
-			if len(pods) > 0 || err != nil {
-				// we only need to proceed if there is at least one pod in this namespace on this node
-				// OR if we couldn't fetch the pods for some reason at this juncture
-				_ = nc.syncConntrackForExternalGateways(namespace)
-			}
-		}
-	}
-}
-
-func (nc *DefaultNodeNetworkController) syncConntrackForExternalGateways(newNs *kapi.Namespace) error {
-	gatewayIPs, err := nc.apbExternalRouteNodeController.GetAdminPolicyBasedExternalRouteIPsForTargetNamespace(newNs.Name)
-	if err != nil {
-		return fmt.Errorf("unable to retrieve gateway IPs for Admin Policy Based External Route objects: %w", err)
-	}
-	// loop through all the IPs on the annotations; ARP for their MACs and form an allowlist
-	gatewayIPs = gatewayIPs.Insert(strings.Split(newNs.Annotations[util.ExternalGatewayPodIPsAnnotation], ",")...)
-	gatewayIPs = gatewayIPs.Insert(strings.Split(newNs.Annotations[util.RoutingExternalGWsAnnotation], ",")...)
-	var wg sync.WaitGroup
-	wg.Add(len(gatewayIPs))
-	validMACs := sync.Map{}
-	for gwIP := range gatewayIPs {
-		go func(gwIP string) {
-			defer wg.Done()
-			if len(gwIP) > 0 && !utilnet.IsIPv6String(gwIP) {
-				// TODO: Add support for IPv6 external gateways
-				if hwAddr, err := util.GetMACAddressFromARP(net.ParseIP(gwIP)); err != nil {
-					klog.Errorf("Failed to lookup hardware address for gatewayIP %s: %v", gwIP, err)
-				} else if len(hwAddr) > 0 {
-					// we need to reverse the mac before passing it to the conntrack filter since OVN saves the MAC in the following format
-					// +------------------------------------------------------------ +
-					// | 128 ...  112 ... 96 ... 80 ... 64 ... 48 ... 32 ... 16 ... 0|
-					// +------------------+-------+--------------------+-------------|
-					// |                  | UNUSED|    MAC ADDRESS     |   UNUSED    |
-					// +------------------+-------+--------------------+-------------+
-					for i, j := 0, len(hwAddr)-1; i < j; i, j = i+1, j-1 {
-						hwAddr[i], hwAddr[j] = hwAddr[j], hwAddr[i]
-					}
-					validMACs.Store(gwIP, []byte(hwAddr))
-				}
-			}
-		}(gwIP)
-	}
-	wg.Wait()
-
-	validNextHopMACs := [][]byte{}
-	validMACs.Range(func(key interface{}, value interface{}) bool {
-		validNextHopMACs = append(validNextHopMACs, value.([]byte))
-		return true
-	})
-	// Handle corner case where there are 0 IPs on the annotations OR none of the ARPs were successful; i.e allowMACList={empty}.
-	// This means we *need to* pass a label > 128 bits that will not match on any conntrack entry labels for these pods.
-	// That way any remaining entries with labels having MACs set will get purged.
-	if len(validNextHopMACs) == 0 {
-		validNextHopMACs = append(validNextHopMACs, []byte("does-not-contain-anything"))
-	}
-
-	pods, err := nc.watchFactory.GetPods(newNs.Name)
-	if err != nil {
-		return fmt.Errorf("unable to get pods from informer: %v", err)
-	}
-
-	var errs []error
-	for _, pod := range pods {
-		pod := pod
-		podIPs, err := util.GetPodIPsOfNetwork(pod, &util.DefaultNetInfo{})
-		if err != nil && !errors.Is(err, util.ErrNoPodIPFound) {
-			errs = append(errs, fmt.Errorf("unable to fetch IP for pod %s/%s: %v", pod.Namespace, pod.Name, err))
-		}
-		for _, podIP := range podIPs { // flush conntrack only for UDP
-			// for this pod, we check if the conntrack entry has a label that is not in the provided allowlist of MACs
-			// only caveat here is we assume egressGW served pods shouldn't have conntrack entries with other labels set
-			err := util.DeleteConntrack(podIP.String(), 0, kapi.ProtocolUDP, netlink.ConntrackOrigDstIP, validNextHopMACs)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to delete conntrack entry for pod %s: %v", podIP.String(), err))
-			}
-		}
-	}
-	return apierrors.NewAggregate(errs)
-}
-
-func (nc *DefaultNodeNetworkController) WatchNamespaces() error {
-	_, err := nc.retryNamespaces.WatchResource()
-	return err
-}
-
 // validateVTEPInterfaceMTU checks if the MTU of the interface that has ovn-encap-ip is big
 // enough to carry the `config.Default.MTU` and the Geneve header. If the MTU is not big
 // enough, it will return an error
@@ -1414,7 +1293,7 @@ func configureSvcRouteViaBridge(routeManager *routemanager.Controller, bridge st
 	return configureSvcRouteViaInterface(routeManager, bridge, DummyNextHopIPs())
 }
 
-func upgradeServiceRoute(routeManager *routemanager.Controller, bridgeName string) error {
+func upgradeServiceRoute(routeManager *routemanager.Controller, ovsClient libovsdbclient.Client, bridgeName string) error {
 	klog.Info("Updating K8S Service route")
 	// Flush old routes
 	link, err := util.LinkSetUp(types.K8sMgmtIntfName)
@@ -1432,7 +1311,7 @@ func upgradeServiceRoute(routeManager *routemanager.Controller, bridgeName strin
 	}
 	klog.Info("Successfully updated Kubernetes service route towards OVS")
 	// Clean up gw0 and local ovs bridge as best effort
-	if err := deleteLocalNodeAccessBridge(); err != nil {
+	if err := deleteLocalNodeAccessBridge(ovsClient); err != nil {
 		klog.Warningf("Error while removing Local Node Access Bridge, error: %v", err)
 	}
 	// Clean up gw0 related IPTable rules as best effort.
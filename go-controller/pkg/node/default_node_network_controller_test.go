@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 
 	"github.com/urfave/cli/v2"
 	"github.com/vishvananda/netlink"
@@ -12,10 +13,12 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube/mocks"
 
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	netlink_mocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/vishvananda/netlink"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	util "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	utilMocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -211,6 +214,7 @@ var _ = Describe("Node", func() {
 	})
 
 	Describe("Node Operations", func() {
+		const openvSwitchUUID = "open-vswitch-uuid"
 		var app *cli.App
 
 		BeforeEach(func() {
@@ -244,21 +248,13 @@ var _ = Describe("Node", func() {
 					},
 				}
 
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
+
 				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 set Open_vSwitch . "+
-						"external_ids:ovn-encap-type=geneve "+
-						"external_ids:ovn-encap-ip=%s "+
-						"external_ids:ovn-remote-probe-interval=%d "+
-						"external_ids:ovn-openflow-probe-interval=%d "+
-						"other_config:bundle-idle-timeout=%d "+
-						"external_ids:hostname=\"%s\" "+
-						"external_ids:ovn-is-interconn=false "+
-						"external_ids:ovn-monitor-all=true "+
-						"external_ids:ovn-ofctrl-wait-before-clear=0 "+
-						"external_ids:ovn-enable-lflow-cache=true",
-						nodeIP, interval, ofintval, ofintval, nodeName),
-				})
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: "ovs-vsctl --timeout=15 -- clear bridge br-int netflow" +
 						" -- " +
@@ -266,14 +262,29 @@ var _ = Describe("Node", func() {
 						" -- " +
 						"clear bridge br-int ipfix",
 				})
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
 				Expect(err).NotTo(HaveOccurred())
 
-				err = setupOVNNode(&node)
+				err = setupOVNNode(ovsClient, &node)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(ovsClient).To(libovsdbtest.HaveData(&vswitchd.OpenvSwitch{
+					UUID: openvSwitchUUID,
+					ExternalIDs: map[string]string{
+						"ovn-encap-type":               "geneve",
+						"ovn-encap-ip":                 nodeIP,
+						"ovn-remote-probe-interval":    strconv.Itoa(interval),
+						"ovn-openflow-probe-interval":  strconv.Itoa(ofintval),
+						"hostname":                     nodeName,
+						"ovn-is-interconn":             "false",
+						"ovn-monitor-all":              "true",
+						"ovn-ofctrl-wait-before-clear": "0",
+						"ovn-enable-lflow-cache":       "true",
+					},
+					OtherConfig: map[string]string{"bundle-idle-timeout": strconv.Itoa(ofintval)},
+				}))
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
 				return nil
@@ -294,12 +305,16 @@ var _ = Describe("Node", func() {
 					encapUUID   string = "e4437094-0094-4223-9f14-995d98d5fff8"
 				)
 
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{
+						UUID:        openvSwitchUUID,
+						ExternalIDs: map[string]string{"system-id": chassisUUID},
+					}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
+
 				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 " +
-						"--if-exists get Open_vSwitch . external_ids:system-id"),
-					Output: chassisUUID,
-				})
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: fmt.Sprintf("ovn-sbctl --timeout=15 --no-leader-only --data=bare --no-heading --columns=_uuid find "+
 						"Encap chassis_name=%s", chassisUUID),
@@ -310,13 +325,13 @@ var _ = Describe("Node", func() {
 						"%s options:dst_port=%d", encapUUID, encapPort),
 				})
 
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
 				Expect(err).NotTo(HaveOccurred())
 				config.Default.EncapPort = encapPort
-				err = setEncapPort(context.Background())
+				err = setEncapPort(context.Background(), ovsClient)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
@@ -348,23 +363,13 @@ var _ = Describe("Node", func() {
 					},
 				}
 
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
+
 				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 set Open_vSwitch . "+
-						"external_ids:ovn-encap-type=geneve "+
-						"external_ids:ovn-encap-ip=%s "+
-						"external_ids:ovn-remote-probe-interval=%d "+
-						"external_ids:ovn-openflow-probe-interval=%d "+
-						"other_config:bundle-idle-timeout=%d "+
-						"external_ids:hostname=\"%s\" "+
-						"external_ids:ovn-is-interconn=false "+
-						"external_ids:ovn-monitor-all=true "+
-						"external_ids:ovn-ofctrl-wait-before-clear=0 "+
-						"external_ids:ovn-enable-lflow-cache=false "+
-						"external_ids:ovn-limit-lflow-cache=1000 "+
-						"external_ids:ovn-memlimit-lflow-cache-kb=100000",
-						nodeIP, interval, ofintval, ofintval, nodeName),
-				})
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: "ovs-vsctl --timeout=15 -- clear bridge br-int netflow" +
 						" -- " +
@@ -372,7 +377,7 @@ var _ = Describe("Node", func() {
 						" -- " +
 						"clear bridge br-int ipfix",
 				})
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
@@ -381,8 +386,25 @@ var _ = Describe("Node", func() {
 				config.Default.LFlowCacheEnable = false
 				config.Default.LFlowCacheLimit = 1000
 				config.Default.LFlowCacheLimitKb = 100000
-				err = setupOVNNode(&node)
+				err = setupOVNNode(ovsClient, &node)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(ovsClient).To(libovsdbtest.HaveData(&vswitchd.OpenvSwitch{
+					UUID: openvSwitchUUID,
+					ExternalIDs: map[string]string{
+						"ovn-encap-type":               "geneve",
+						"ovn-encap-ip":                 nodeIP,
+						"ovn-remote-probe-interval":    strconv.Itoa(interval),
+						"ovn-openflow-probe-interval":  strconv.Itoa(ofintval),
+						"hostname":                     nodeName,
+						"ovn-is-interconn":             "false",
+						"ovn-monitor-all":              "true",
+						"ovn-ofctrl-wait-before-clear": "0",
+						"ovn-enable-lflow-cache":       "false",
+						"ovn-limit-lflow-cache":        "1000",
+						"ovn-memlimit-lflow-cache-kb":  "100000",
+					},
+					OtherConfig: map[string]string{"bundle-idle-timeout": strconv.Itoa(ofintval)},
+				}))
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
 				return nil
@@ -396,8 +418,6 @@ var _ = Describe("Node", func() {
 				const (
 					nodeIP    string = "1.2.5.6"
 					nodeName  string = "cannot.be.resolv.ed"
-					interval  int    = 100000
-					ofintval  int    = 180
 					ipfixPort int32  = 456
 				)
 				ipfixIP := net.IP{1, 2, 3, 4}
@@ -416,22 +436,13 @@ var _ = Describe("Node", func() {
 					},
 				}
 
-				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 set Open_vSwitch . "+
-						"external_ids:ovn-encap-type=geneve "+
-						"external_ids:ovn-encap-ip=%s "+
-						"external_ids:ovn-remote-probe-interval=%d "+
-						"external_ids:ovn-openflow-probe-interval=%d "+
-						"other_config:bundle-idle-timeout=%d "+
-						"external_ids:hostname=\"%s\" "+
-						"external_ids:ovn-is-interconn=false "+
-						"external_ids:ovn-monitor-all=true "+
-						"external_ids:ovn-ofctrl-wait-before-clear=0 "+
-						"external_ids:ovn-enable-lflow-cache=true",
-						nodeIP, interval, ofintval, ofintval, nodeName),
-				})
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
 
+				fexec := ovntest.NewFakeExec()
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: "ovs-vsctl --timeout=15 -- clear bridge br-int netflow" +
 						" -- " +
@@ -447,7 +458,7 @@ var _ = Describe("Node", func() {
 						" -- "+
 						"set bridge br-int ipfix=@ipfix", ipfixIP, ipfixPort),
 				})
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
@@ -455,7 +466,7 @@ var _ = Describe("Node", func() {
 				config.Monitoring.IPFIXTargets = []config.HostPort{
 					{Host: &ipfixIP, Port: ipfixPort},
 				}
-				err = setupOVNNode(&node)
+				err = setupOVNNode(ovsClient, &node)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
@@ -470,8 +481,6 @@ var _ = Describe("Node", func() {
 				const (
 					nodeIP    string = "1.2.5.6"
 					nodeName  string = "cannot.be.resolv.ed"
-					interval  int    = 100000
-					ofintval  int    = 180
 					ipfixPort int32  = 456
 				)
 				ipfixIP := net.IP{1, 2, 3, 4}
@@ -490,22 +499,13 @@ var _ = Describe("Node", func() {
 					},
 				}
 
-				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 set Open_vSwitch . "+
-						"external_ids:ovn-encap-type=geneve "+
-						"external_ids:ovn-encap-ip=%s "+
-						"external_ids:ovn-remote-probe-interval=%d "+
-						"external_ids:ovn-openflow-probe-interval=%d "+
-						"other_config:bundle-idle-timeout=%d "+
-						"external_ids:hostname=\"%s\" "+
-						"external_ids:ovn-is-interconn=false "+
-						"external_ids:ovn-monitor-all=true "+
-						"external_ids:ovn-ofctrl-wait-before-clear=0 "+
-						"external_ids:ovn-enable-lflow-cache=true",
-						nodeIP, interval, ofintval, ofintval, nodeName),
-				})
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
 
+				fexec := ovntest.NewFakeExec()
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: "ovs-vsctl --timeout=15 -- clear bridge br-int netflow" +
 						" -- " +
@@ -521,7 +521,7 @@ var _ = Describe("Node", func() {
 						" -- "+
 						"set bridge br-int ipfix=@ipfix", ipfixIP, ipfixPort),
 				})
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
@@ -532,7 +532,7 @@ var _ = Describe("Node", func() {
 				config.IPFIX.CacheActiveTimeout = 123
 				config.IPFIX.CacheMaxFlows = 456
 				config.IPFIX.Sampling = 789
-				err = setupOVNNode(&node)
+				err = setupOVNNode(ovsClient, &node)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
@@ -547,8 +547,6 @@ var _ = Describe("Node", func() {
 				const (
 					nodeIP   string = "1.2.5.6"
 					nodeName string = "anyhost.test"
-					interval int    = 100000
-					ofintval int    = 180
 				)
 				node := kapi.Node{
 					ObjectMeta: metav1.ObjectMeta{
@@ -564,22 +562,13 @@ var _ = Describe("Node", func() {
 					},
 				}
 
-				fexec := ovntest.NewFakeExec()
-				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-					Cmd: fmt.Sprintf("ovs-vsctl --timeout=15 set Open_vSwitch . "+
-						"external_ids:ovn-encap-type=geneve "+
-						"external_ids:ovn-encap-ip=%s "+
-						"external_ids:ovn-remote-probe-interval=%d "+
-						"external_ids:ovn-openflow-probe-interval=%d "+
-						"other_config:bundle-idle-timeout=%d "+
-						"external_ids:hostname=\"%s\" "+
-						"external_ids:ovn-is-interconn=false "+
-						"external_ids:ovn-monitor-all=true "+
-						"external_ids:ovn-ofctrl-wait-before-clear=0 "+
-						"external_ids:ovn-enable-lflow-cache=true",
-						nodeIP, interval, ofintval, ofintval, nodeName),
-				})
+				ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+					OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+				}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer libovsdbCleanup.Cleanup()
 
+				fexec := ovntest.NewFakeExec()
 				fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 					Cmd: "ovs-vsctl --timeout=15 -- clear bridge br-int netflow" +
 						" -- " +
@@ -596,7 +585,7 @@ var _ = Describe("Node", func() {
 						" -- " +
 						"set bridge br-int ipfix=@ipfix",
 				})
-				err := util.SetExec(fexec)
+				err = util.SetExec(fexec)
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = config.InitConfig(ctx, fexec, nil)
@@ -609,7 +598,7 @@ var _ = Describe("Node", func() {
 				config.IPFIX.Sampling = 0
 				Expect(err).NotTo(HaveOccurred())
 
-				err = setupOVNNode(&node)
+				err = setupOVNNode(ovsClient, &node)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
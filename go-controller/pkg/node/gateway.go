@@ -5,6 +5,7 @@ import (
 	"net"
 	"sync"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/informer"
@@ -41,8 +42,10 @@ type gateway struct {
 	nodePortWatcher informer.ServiceAndEndpointsEventHandler
 	openflowManager *openflowManager
 	nodeIPManager   *addressManager
-	initFunc        func() error
-	readyFunc       func() (bool, error)
+	// modeController switches the gateway mode at runtime, nil if not supported
+	modeController *gatewayModeController
+	initFunc       func() error
+	readyFunc      func() (bool, error)
 
 	watchFactory *factory.WatchFactory // used for retry
 	stopChan     <-chan struct{}
@@ -233,6 +236,16 @@ func (g *gateway) Start() {
 		klog.Info("Spawning Conntrack Rule Check Thread")
 		g.openflowManager.Run(g.stopChan, g.wg)
 	}
+
+	if g.modeController != nil {
+		g.modeController.Run(g.stopChan, g.wg)
+	}
+
+	if npw, ok := g.nodePortWatcher.(*nodePortWatcher); ok {
+		if err := npw.watchNodeGatewayMACs(); err != nil {
+			klog.Fatalf("Could not add node event handler while starting the node port watcher: %v", err)
+		}
+	}
 }
 
 // sets up an uplink interface for UDP Generic Receive Offload forwarding as part of
@@ -254,23 +267,34 @@ func setupUDPAggregationUplink(ifname string) error {
 	return nil
 }
 
-func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops []net.IP, gwIPs []*net.IPNet, nodeAnnotator kube.Annotator) (
-	*bridgeConfiguration, *bridgeConfiguration, error) {
-	gatewayBridge, err := bridgeForInterface(gwIntf, nodeName, types.PhysicalNetworkName, gwIPs)
+func gatewayInitInternal(ovsClient libovsdbclient.Client, nodeName, gwIntf, egressGatewayIntf string, gwNextHops []net.IP, gwIPs []*net.IPNet, nodeAnnotator kube.Annotator) (
+	*bridgeConfiguration, *bridgeConfiguration, []*bridgeConfiguration, error) {
+	gatewayBridge, err := bridgeForInterface(ovsClient, gwIntf, nodeName, types.PhysicalNetworkName, gwIPs)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "Bridge for interface failed for %s", gwIntf)
+		return nil, nil, nil, errors.Wrapf(err, "Bridge for interface failed for %s", gwIntf)
 	}
 	var egressGWBridge *bridgeConfiguration
 	if egressGatewayIntf != "" {
-		egressGWBridge, err = bridgeForInterface(egressGatewayIntf, nodeName, types.PhysicalNetworkExGwName, nil)
+		egressGWBridge, err = bridgeForInterface(ovsClient, egressGatewayIntf, nodeName, types.PhysicalNetworkExGwName, nil)
 		if err != nil {
-			return nil, nil, errors.Wrapf(err, "Bridge for interface failed for %s", egressGatewayIntf)
+			return nil, nil, nil, errors.Wrapf(err, "Bridge for interface failed for %s", egressGatewayIntf)
 		}
 	}
+	uplinkBridges := make([]*bridgeConfiguration, 0, len(config.Gateway.Uplinks))
+	for _, uplink := range config.Gateway.Uplinks {
+		// the localnet secondary networks selected by the uplink are mapped
+		// to its bridge along with its own physical network
+		uplinkBridge, err := bridgeForInterface(ovsClient, interfaceForEXGW(uplink.Interface), nodeName,
+			types.PhysicalNetworkUplinkPrefix+uplink.Name, nil, uplink.Networks...)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "Bridge for interface failed for uplink %s interface %s", uplink.Name, uplink.Interface)
+		}
+		uplinkBridges = append(uplinkBridges, uplinkBridge)
+	}
 
 	chassisID, err := util.GetNodeChassisID()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Set annotation that determines if options:gateway_mtu shall be set for this node.
@@ -283,7 +307,7 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 	} else {
 		chkPktLengthSupported, err := util.DetectCheckPktLengthSupport(gatewayBridge.bridgeName)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if !chkPktLengthSupported {
 			klog.Warningf("OVS does not support check_packet_length action. " +
@@ -300,7 +324,7 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 			 */
 			ovsHardwareOffloadEnabled, err := util.IsOvsHwOffloadEnabled()
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			if ovsHardwareOffloadEnabled {
 				klog.Warningf("OVS hardware offloading is enabled. " +
@@ -311,7 +335,7 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 		}
 	}
 	if err := util.SetGatewayMTUSupport(nodeAnnotator, enableGatewayMTU); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	if config.Default.EnableUDPAggregation {
@@ -319,6 +343,12 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 		if err == nil && egressGWBridge != nil {
 			err = setupUDPAggregationUplink(egressGWBridge.uplinkName)
 		}
+		for _, uplinkBridge := range uplinkBridges {
+			if err != nil {
+				break
+			}
+			err = setupUDPAggregationUplink(uplinkBridge.uplinkName)
+		}
 		if err != nil {
 			klog.Warningf("Could not enable UDP packet aggregation on uplink interface (aggregation will be disabled): %v", err)
 			config.Default.EnableUDPAggregation = false
@@ -326,7 +356,7 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 	}
 
 	l3GwConfig := util.L3GatewayConfig{
-		Mode:           config.Gateway.Mode,
+		Mode:           config.GetGatewayMode(),
 		ChassisID:      chassisID,
 		InterfaceID:    gatewayBridge.interfaceID,
 		MACAddress:     gatewayBridge.macAddress,
@@ -341,8 +371,46 @@ func gatewayInitInternal(nodeName, gwIntf, egressGatewayIntf string, gwNextHops
 		l3GwConfig.EgressGWIPAddresses = egressGWBridge.ips
 	}
 
+	for i, uplink := range config.Gateway.Uplinks {
+		l3GwConfig.Uplinks = append(l3GwConfig.Uplinks, util.L3GatewayUplink{
+			Name:         uplink.Name,
+			InterfaceID:  uplinkBridges[i].interfaceID,
+			MACAddress:   uplinkBridges[i].macAddress,
+			IPAddresses:  uplinkBridges[i].ips,
+			NextHops:     uplink.NextHops,
+			Destinations: uplink.Destinations,
+			Namespaces:   uplink.Namespaces,
+		})
+	}
+
 	err = util.SetL3GatewayConfig(nodeAnnotator, &l3GwConfig)
-	return gatewayBridge, egressGWBridge, err
+	return gatewayBridge, egressGWBridge, uplinkBridges, err
+}
+
+// gatewayBridgesReady returns whether the patch ports of all the given bridges
+// have been created by ovn-controller
+func gatewayBridgesReady(bridges ...*bridgeConfiguration) (bool, error) {
+	for _, bridge := range bridges {
+		ready, err := gatewayReady(bridge.patchPort)
+		if err != nil || !ready {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// initExternalBridge programs the ofports of a bridge only carrying the
+// traffic of the gateway router, like the ex gw and the uplink bridges
+func initExternalBridge(bridge *bridgeConfiguration) error {
+	if err := setBridgeOfPorts(bridge); err != nil {
+		return err
+	}
+	if config.Gateway.DisableForwarding {
+		if err := initExternalBridgeDropForwardingRules(bridge.bridgeName); err != nil {
+			return fmt.Errorf("failed to add forwarding block rules for bridge %s: err %v", bridge.bridgeName, err)
+		}
+	}
+	return nil
 }
 
 func gatewayReady(patchPort string) (bool, error) {
@@ -407,7 +475,8 @@ func (b *bridgeConfiguration) updateInterfaceIPAddresses(node *kapi.Node) ([]*ne
 	return ifAddrs, nil
 }
 
-func bridgeForInterface(intfName, nodeName, physicalNetworkName string, gwIPs []*net.IPNet) (*bridgeConfiguration, error) {
+func bridgeForInterface(ovsClient libovsdbclient.Client, intfName, nodeName, physicalNetworkName string, gwIPs []*net.IPNet,
+	extraPhysicalNetworkNames ...string) (*bridgeConfiguration, error) {
 	res := bridgeConfiguration{}
 	gwIntf := intfName
 
@@ -434,7 +503,7 @@ func bridgeForInterface(intfName, nodeName, physicalNetworkName string, gwIPs []
 		// gateway interface is an OVS bridge
 		uplinkName, err := getIntfName(intfName)
 		if err != nil {
-			if config.Gateway.Mode == config.GatewayModeLocal && config.Gateway.AllowNoUplink {
+			if config.GetGatewayMode() == config.GatewayModeLocal && config.Gateway.AllowNoUplink {
 				klog.Infof("Could not find uplink for %s, setup gateway bridge with no uplink port, egress IP and egress GW will not work", intfName)
 			} else {
 				return nil, errors.Wrapf(err, "Failed to find intfName for %s", intfName)
@@ -463,7 +532,8 @@ func bridgeForInterface(intfName, nodeName, physicalNetworkName string, gwIPs []
 		return nil, errors.Wrapf(err, "failed to get MAC address for ovs port %s", gwIntf)
 	}
 
-	res.interfaceID, err = bridgedGatewayNodeSetup(nodeName, res.bridgeName, physicalNetworkName)
+	res.interfaceID, err = bridgedGatewayNodeSetup(ovsClient, nodeName, res.bridgeName,
+		append([]string{physicalNetworkName}, extraPhysicalNetworkNames...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set up shared interface gateway: %v", err)
 	}
@@ -9,17 +9,21 @@ import (
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	util "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 )
 
 // bridgedGatewayNodeSetup enables forwarding on bridge interface, sets up the physical network name mappings for the bridge,
 // and returns an ifaceID created from the bridge name and the node name
-func bridgedGatewayNodeSetup(nodeName, bridgeName, physicalNetworkName string) (string, error) {
+func bridgedGatewayNodeSetup(ovsClient libovsdbclient.Client, nodeName, bridgeName string, physicalNetworkNames ...string) (string, error) {
 	// enable forwarding on bridge interface always
 	createForwardingRule := func(family string) error {
 		stdout, stderr, err := util.RunSysctl("-w", fmt.Sprintf("net.%s.conf.%s.forwarding=1", family, bridgeName))
@@ -44,33 +48,36 @@ func bridgedGatewayNodeSetup(nodeName, bridgeName, physicalNetworkName string) (
 	// that provides connectivity to that network. It is in the form of physnet1:br1,physnet2:br2.
 	// Note that there may be multiple ovs bridge mappings, be sure not to override
 	// the mappings for the other physical network
-	stdout, stderr, err := util.RunOVSVsctl("--if-exists", "get", "Open_vSwitch", ".",
-		"external_ids:ovn-bridge-mappings")
+	openvSwitch, err := libovsdbops.GetOpenvSwitch(ovsClient)
 	if err != nil {
-		return "", fmt.Errorf("failed to get ovn-bridge-mappings stderr:%s (%v)", stderr, err)
+		return "", fmt.Errorf("failed to get ovn-bridge-mappings: %v", err)
 	}
-	// skip the existing mapping setting for the specified physicalNetworkName
+	// skip the existing mapping setting for the specified physicalNetworkNames
 	mapString := ""
-	bridgeMappings := strings.Split(stdout, ",")
-	for _, bridgeMapping := range bridgeMappings {
+	for _, bridgeMapping := range strings.Split(openvSwitch.ExternalIDs["ovn-bridge-mappings"], ",") {
+		if bridgeMapping == "" {
+			continue
+		}
 		m := strings.Split(bridgeMapping, ":")
-		if network := m[0]; network != physicalNetworkName {
+		if network := m[0]; !util.SliceHasStringItem(physicalNetworkNames, network) {
 			if len(mapString) != 0 {
 				mapString += ","
 			}
 			mapString += bridgeMapping
 		}
 	}
-	if len(mapString) != 0 {
-		mapString += ","
+	for _, physicalNetworkName := range physicalNetworkNames {
+		if len(mapString) != 0 {
+			mapString += ","
+		}
+		mapString += physicalNetworkName + ":" + bridgeName
 	}
-	mapString += physicalNetworkName + ":" + bridgeName
 
-	_, stderr, err = util.RunOVSVsctl("set", "Open_vSwitch", ".",
-		fmt.Sprintf("external_ids:ovn-bridge-mappings=%s", mapString))
+	err = libovsdbops.UpdateOpenvSwitchSetExternalIDsAndOtherConfig(ovsClient, &vswitchd.OpenvSwitch{
+		ExternalIDs: map[string]string{"ovn-bridge-mappings": mapString},
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to set ovn-bridge-mappings for ovs bridge %s"+
-			", stderr:%s (%v)", bridgeName, stderr, err)
+		return "", fmt.Errorf("failed to set ovn-bridge-mappings for ovs bridge %s: %v", bridgeName, err)
 	}
 
 	ifaceID := bridgeName + "_" + nodeName
@@ -173,7 +180,7 @@ func getGatewayNextHops() ([]net.IP, string, error) {
 	if needIPv4NextHop || needIPv6NextHop || gatewayIntf == "" {
 		defaultGatewayIntf, defaultGatewayNextHops, err := getDefaultGatewayInterfaceDetails(gatewayIntf, config.IPv4Mode, config.IPv6Mode)
 		if err != nil {
-			if !(errors.As(err, new(*GatewayInterfaceMismatchError)) && config.Gateway.Mode == config.GatewayModeLocal && config.Gateway.AllowNoUplink) {
+			if !(errors.As(err, new(*GatewayInterfaceMismatchError)) && config.GetGatewayMode() == config.GatewayModeLocal && config.Gateway.AllowNoUplink) {
 				return nil, "", err
 			}
 		}
@@ -184,7 +191,7 @@ func getGatewayNextHops() ([]net.IP, string, error) {
 			gatewayIntf = defaultGatewayIntf
 		} else {
 			if gatewayIntf != defaultGatewayIntf || len(defaultGatewayNextHops) == 0 {
-				if config.Gateway.Mode == config.GatewayModeLocal && config.Gateway.AllowNoUplink {
+				if config.GetGatewayMode() == config.GatewayModeLocal && config.Gateway.AllowNoUplink {
 					// For local gw, if not default gateway is available or the provide gateway interface is not the host gateway interface
 					// use nexthop masquerade IP as GR default gw to steer traffic to the gateway bridge
 					if needIPv4NextHop {
@@ -360,15 +367,15 @@ func (nc *DefaultNodeNetworkController) initGateway(subnets []*net.IPNet, nodeAn
 	}
 
 	var gw *gateway
-	switch config.Gateway.Mode {
+	switch config.GetGatewayMode() {
 	case config.GatewayModeLocal:
 		klog.Info("Preparing Local Gateway")
 		gw, err = newLocalGateway(nc.name, subnets, gatewayNextHops, gatewayIntf, egressGWInterface, ifAddrs, nodeAnnotator,
-			managementPortConfig, nc.Kube, nc.watchFactory, nc.routeManager)
+			managementPortConfig, nc.Kube, nc.watchFactory, nc.routeManager, nc.ovsClient)
 	case config.GatewayModeShared:
 		klog.Info("Preparing Shared Gateway")
 		gw, err = newSharedGateway(nc.name, subnets, gatewayNextHops, gatewayIntf, egressGWInterface, ifAddrs, nodeAnnotator, nc.Kube,
-			managementPortConfig, nc.watchFactory, nc.routeManager)
+			managementPortConfig, nc.watchFactory, nc.routeManager, nc.ovsClient)
 	case config.GatewayModeDisabled:
 		var chassisID string
 		klog.Info("Gateway Mode is disabled")
@@ -512,7 +519,7 @@ func CleanupClusterNode(name string) error {
 	var err error
 
 	klog.V(5).Infof("Cleaning up gateway resources on node: %q", name)
-	if config.Gateway.Mode == config.GatewayModeLocal || config.Gateway.Mode == config.GatewayModeShared {
+	if config.GetGatewayMode() == config.GatewayModeLocal || config.GetGatewayMode() == config.GatewayModeShared {
 		err = cleanupLocalnetGateway(types.LocalNetworkName)
 		if err != nil {
 			klog.Errorf("Failed to cleanup Localnet Gateway, error: %v", err)
@@ -532,5 +539,11 @@ func CleanupClusterNode(name string) error {
 	// Delete iptable rules for management port
 	DelMgtPortIptRules()
 
+	if config.OvnKubeNode.FirewallBackend == config.FirewallBackendNFTables {
+		if err = util.DeleteNFTablesTables(); err != nil {
+			klog.Errorf("Failed to delete nftables tables, error: %v", err)
+		}
+	}
+
 	return nil
 }
@@ -29,10 +29,12 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	linkMock "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/vishvananda/netlink"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	utilMock "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/containernetworking/plugins/pkg/testutils"
@@ -65,9 +67,10 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 
 	app.Action = func(ctx *cli.Context) error {
 		const (
-			nodeName   string = "node1"
-			systemID   string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
-			nodeSubnet string = "10.1.1.0/24"
+			nodeName        string = "node1"
+			systemID        string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
+			nodeSubnet      string = "10.1.1.0/24"
+			openvSwitchUUID string = "open-vswitch-uuid"
 		)
 
 		fexec := ovntest.NewLooseCompareFakeExec()
@@ -119,13 +122,6 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get interface breth0 mac_in_use",
 			Output: eth0MAC,
 		})
-		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:ovn-bridge-mappings",
-			Output: "",
-		})
-		fexec.AddFakeCmdsNoOutputNoError([]string{
-			"ovs-vsctl --timeout=15 set Open_vSwitch . external_ids:ovn-bridge-mappings=" + types.PhysicalNetworkName + ":breth0",
-		})
 
 		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:system-id",
@@ -148,12 +144,6 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			Output: "7",
 		})
 		if setNodeIP {
-			fexec.AddFakeCmdsNoOutputNoError([]string{
-				"ovs-vsctl --timeout=15 get Open_vSwitch . external_ids:ovn-encap-ip",
-			})
-			fexec.AddFakeCmdsNoOutputNoError([]string{
-				"ovs-vsctl --timeout=15 set Open_vSwitch . external_ids:ovn-encap-ip=192.168.1.10",
-			})
 			fexec.AddFakeCmdsNoOutputNoError([]string{
 				"ovn-appctl --timeout=5 -t ovn-controller exit --restart",
 			})
@@ -262,6 +252,12 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			wg.Done()
 			return nil
 		})
+		ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+			OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer libovsdbCleanup.Cleanup()
+
 		err = testNS.Do(func(ns.NetNS) error {
 			defer GinkgoRecover()
 
@@ -269,7 +265,7 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			Expect(err).NotTo(HaveOccurred())
 			ifAddrs := ovntest.MustParseIPNets(eth0CIDR)
 			sharedGw, err := newSharedGateway(nodeName, ovntest.MustParseIPNets(nodeSubnet), gatewayNextHops, gatewayIntf, "", ifAddrs, nodeAnnotator, k,
-				&fakeMgmtPortConfig, wf, rm)
+				&fakeMgmtPortConfig, wf, rm, ovsClient)
 			Expect(err).NotTo(HaveOccurred())
 			err = sharedGw.Init(wf, stop, wg)
 			Expect(err).NotTo(HaveOccurred())
@@ -283,6 +279,13 @@ func shareGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			// we cannot start openflow manager directly because it spawns a go routine
 			// FIXME: extract openflow manager func from the spawning of a go routine so it can be called directly below.
 			sharedGw.openflowManager.syncFlows()
+
+			expectedExternalIDs := map[string]string{"ovn-bridge-mappings": types.PhysicalNetworkName + ":breth0"}
+			if setNodeIP {
+				expectedExternalIDs["ovn-encap-ip"] = "192.168.1.10"
+			}
+			Expect(ovsClient).To(libovsdbtest.HaveData(&vswitchd.OpenvSwitch{UUID: openvSwitchUUID, ExternalIDs: expectedExternalIDs}))
+
 			// Verify the code moved eth0's IP address, MAC, and routes
 			// over to breth0
 			l, err := netlink.LinkByName("breth0")
@@ -410,12 +413,13 @@ func shareGatewayInterfaceDPUTest(app *cli.App, testNS ns.NetNS,
 	const clusterCIDR string = "10.1.0.0/16"
 	app.Action = func(ctx *cli.Context) error {
 		const (
-			nodeName   string = "node1"
-			systemID   string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
-			nodeSubnet string = "10.1.1.0/24"
-			uplinkPort string = "p0"
-			uplinkMAC  string = "11:22:33:44:55:66"
-			hostRep    string = "pf0hpf"
+			nodeName        string = "node1"
+			systemID        string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
+			nodeSubnet      string = "10.1.1.0/24"
+			openvSwitchUUID string = "open-vswitch-uuid"
+			uplinkPort      string = "p0"
+			uplinkMAC       string = "11:22:33:44:55:66"
+			hostRep         string = "pf0hpf"
 		)
 
 		// sriovnet mocks
@@ -476,13 +480,6 @@ func shareGatewayInterfaceDPUTest(app *cli.App, testNS ns.NetNS,
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get interface " + brphys + " mac_in_use",
 			Output: uplinkMAC,
 		})
-		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:ovn-bridge-mappings",
-			Output: "",
-		})
-		fexec.AddFakeCmdsNoOutputNoError([]string{
-			"ovs-vsctl --timeout=15 set Open_vSwitch . external_ids:ovn-bridge-mappings=" + types.PhysicalNetworkName + ":" + brphys,
-		})
 		// GetNodeChassisID
 		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:system-id",
@@ -538,12 +535,6 @@ func shareGatewayInterfaceDPUTest(app *cli.App, testNS ns.NetNS,
 			Cmd:    "ovs-vsctl --timeout=15 get interface " + hostRep + " ofport",
 			Output: "9",
 		})
-		fexec.AddFakeCmdsNoOutputNoError([]string{
-			"ovs-vsctl --timeout=15 get Open_vSwitch . external_ids:ovn-encap-ip",
-		})
-		fexec.AddFakeCmdsNoOutputNoError([]string{
-			"ovs-vsctl --timeout=15 set Open_vSwitch . external_ids:ovn-encap-ip=192.168.1.101",
-		})
 		fexec.AddFakeCmdsNoOutputNoError([]string{
 			"ovn-appctl --timeout=5 -t ovn-controller exit --restart",
 		})
@@ -636,13 +627,19 @@ func shareGatewayInterfaceDPUTest(app *cli.App, testNS ns.NetNS,
 		// FIXME(mk): starting the gateaway causing go routines to be spawned within sub functions and therefore they escape the
 		// netns we wanted to set it to originally here. Refactor test cases to not spawn a go routine or just fake out everything
 		// and remove need to create netns
+		ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+			OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{UUID: openvSwitchUUID}},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer libovsdbCleanup.Cleanup()
+
 		err = testNS.Do(func(ns.NetNS) error {
 			defer GinkgoRecover()
 
 			gatewayNextHops, gatewayIntf, err := getGatewayNextHops()
 			Expect(err).NotTo(HaveOccurred())
 			sharedGw, err := newSharedGateway(nodeName, ovntest.MustParseIPNets(nodeSubnet), gatewayNextHops,
-				gatewayIntf, "", ifAddrs, nodeAnnotator, k, &fakeMgmtPortConfig, wf, rm)
+				gatewayIntf, "", ifAddrs, nodeAnnotator, k, &fakeMgmtPortConfig, wf, rm, ovsClient)
 			Expect(err).NotTo(HaveOccurred())
 			err = sharedGw.Init(wf, stop, wg)
 			Expect(err).NotTo(HaveOccurred())
@@ -657,6 +654,13 @@ func shareGatewayInterfaceDPUTest(app *cli.App, testNS ns.NetNS,
 			// we cannot start openflow manager directly because it spawns a go routine
 			// FIXME: extract openflow manager func from the spawning of a go routine so it can be called directly below.
 			sharedGw.openflowManager.syncFlows()
+			Expect(ovsClient).To(libovsdbtest.HaveData(&vswitchd.OpenvSwitch{
+				UUID: openvSwitchUUID,
+				ExternalIDs: map[string]string{
+					"ovn-bridge-mappings": types.PhysicalNetworkName + ":" + brphys,
+					"ovn-encap-ip":        "192.168.1.101",
+				},
+			}))
 
 			// check that the masquerade route was not added
 			l, err := netlink.LinkByName(brphys)
@@ -743,7 +747,7 @@ func shareGatewayInterfaceDPUHostTest(app *cli.App, testNS ns.NetNS, uplinkName,
 		Expect(err).NotTo(HaveOccurred())
 		ip, ipnet, err := net.ParseCIDR(hostIP + "/24")
 		ipnet.IP = ip
-		cnnci := NewCommonNodeNetworkControllerInfo(nil, fakeClient.AdminPolicyRouteClient, wf, nil, nodeName)
+		cnnci := NewCommonNodeNetworkControllerInfo(nil, fakeClient.AdminPolicyRouteClient, wf, nil, nodeName, nil)
 		nc := newDefaultNodeNetworkController(cnnci, stop, wg)
 		// must run route manager manually which is usually started with nc.Start()
 		wg.Add(1)
@@ -842,9 +846,10 @@ func localGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 
 	app.Action = func(ctx *cli.Context) error {
 		const (
-			nodeName   string = "node1"
-			systemID   string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
-			nodeSubnet string = "10.1.1.0/24"
+			nodeName        string = "node1"
+			systemID        string = "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6"
+			nodeSubnet      string = "10.1.1.0/24"
+			openvSwitchUUID string = "open-vswitch-uuid"
 		)
 
 		fexec := ovntest.NewLooseCompareFakeExec()
@@ -896,13 +901,6 @@ func localGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get interface breth0 mac_in_use",
 			Output: eth0MAC,
 		})
-		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:ovn-bridge-mappings",
-			Output: "",
-		})
-		fexec.AddFakeCmdsNoOutputNoError([]string{
-			"ovs-vsctl --timeout=15 set Open_vSwitch . external_ids:ovn-bridge-mappings=" + types.PhysicalNetworkName + ":breth0",
-		})
 
 		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 			Cmd:    "ovs-vsctl --timeout=15 --if-exists get Open_vSwitch . external_ids:system-id",
@@ -924,11 +922,6 @@ func localGatewayInterfaceTest(app *cli.App, testNS ns.NetNS,
 			Cmd:    "ovs-vsctl --timeout=15 get interface eth0 ofport",
 			Output: "7",
 		})
-		// IP already configured, do not try to set it or restart ovn-controller
-		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
-			Cmd:    "ovs-vsctl --timeout=15 get Open_vSwitch . external_ids:ovn-encap-ip",
-			Output: "192.168.1.10",
-		})
 		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
 			Cmd:    "ip route replace table 7 172.16.1.0/24 via 10.1.1.1 dev ovn-k8s-mp0",
 			Output: "0",
@@ -1070,13 +1063,23 @@ OFPT_GET_CONFIG_REPLY (xid=0x4): frags=normal miss_send_len=0`,
 			rm.Run(stop, 10*time.Second)
 			return nil
 		})
+		ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+			OVSData: []libovsdbtest.TestData{&vswitchd.OpenvSwitch{
+				UUID: openvSwitchUUID,
+				// IP already configured, do not try to set it or restart ovn-controller
+				ExternalIDs: map[string]string{"ovn-encap-ip": "192.168.1.10"},
+			}},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer libovsdbCleanup.Cleanup()
+
 		err = testNS.Do(func(ns.NetNS) error {
 			defer GinkgoRecover()
 			gatewayNextHops, gatewayIntf, err := getGatewayNextHops()
 			Expect(err).NotTo(HaveOccurred())
 			ifAddrs := ovntest.MustParseIPNets(eth0CIDR)
 			localGw, err := newLocalGateway(nodeName, ovntest.MustParseIPNets(nodeSubnet), gatewayNextHops, gatewayIntf, "", ifAddrs,
-				nodeAnnotator, &fakeMgmtPortConfig, k, wf, rm)
+				nodeAnnotator, &fakeMgmtPortConfig, k, wf, rm, ovsClient)
 			Expect(err).NotTo(HaveOccurred())
 			err = localGw.Init(wf, stop, wg)
 			Expect(err).NotTo(HaveOccurred())
@@ -1091,6 +1094,13 @@ OFPT_GET_CONFIG_REPLY (xid=0x4): frags=normal miss_send_len=0`,
 			// we cannot start openflow manager directly because it spawns a go routine
 			// FIXME: extract openflow manager func from the spawning of a go routine so it can be called directly below.
 			localGw.openflowManager.syncFlows()
+			Expect(ovsClient).To(libovsdbtest.HaveData(&vswitchd.OpenvSwitch{
+				UUID: openvSwitchUUID,
+				ExternalIDs: map[string]string{
+					"ovn-bridge-mappings": types.PhysicalNetworkName + ":breth0",
+					"ovn-encap-ip":        "192.168.1.10",
+				},
+			}))
 
 			// Verify the code moved eth0's IP address, MAC, and routes
 			// over to breth0
@@ -1597,6 +1607,7 @@ var _ = Describe("Gateway unit tests", func() {
 				Scope:     netlink.SCOPE_UNIVERSE,
 				Gw:        gwIPs[0],
 				MTU:       config.Default.MTU,
+				Protocol:  routemanager.RouteProtocol,
 			}
 
 			lnk.On("Attrs").Return(lnkAttr)
@@ -6,6 +6,7 @@ package node
 import (
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/coreos/go-iptables/iptables"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
@@ -14,11 +15,14 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	kapi "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 )
 
 const (
+	// ovnKubeChainPrefix prefixes the name of the chains owned by ovnkube-node
+	ovnKubeChainPrefix     = "OVN-KUBE-"
 	iptableNodePortChain   = "OVN-KUBE-NODEPORT"   // called from nat-PREROUTING and nat-OUTPUT
 	iptableExternalIPChain = "OVN-KUBE-EXTERNALIP" // called from nat-PREROUTING and nat-OUTPUT
 	iptableETPChain        = "OVN-KUBE-ETP"        // called from nat-PREROUTING only
@@ -279,6 +283,27 @@ func getExternalIPTRules(svcPort kapi.ServicePort, externalIP, dstIP string, svc
 	}
 }
 
+// getExternalIPPortRangeIPTRules returns the IPTable DNAT rules for a service of type LB or ExternalIP
+// forwarding a range of ports, or all ports and protocols, of `externalIP` to the same ports of `clusterIP`
+func getExternalIPPortRangeIPTRules(protocol kapi.Protocol, portRange *util.LBPortRange, externalIP, clusterIP string) []nodeipt.Rule {
+	args := []string{"-d", externalIP}
+	if !portRange.AllPorts() {
+		args = []string{
+			"-p", string(protocol),
+			"-d", externalIP,
+			"--dport", fmt.Sprintf("%d:%d", portRange.First, portRange.Last),
+		}
+	}
+	return []nodeipt.Rule{
+		{
+			Table:    "nat",
+			Chain:    iptableExternalIPChain,
+			Args:     append(args, "-j", "DNAT", "--to-destination", clusterIP),
+			Protocol: getIPTablesProtocol(externalIP),
+		},
+	}
+}
+
 func getGatewayForwardRules(svcCIDR *net.IPNet) []nodeipt.Rule {
 	protocol := getIPTablesProtocol(svcCIDR.IP.String())
 	masqueradeIP := config.Gateway.MasqueradeIPs.V4OVNMasqueradeIP
@@ -369,6 +394,79 @@ func initExternalBridgeDropForwardingRules(ifName string) error {
 	return appendIptRules(getGatewayDropRules(ifName))
 }
 
+func getSecondaryNetworkGatewayRules(secondaryMasqSubnet string) []nodeipt.Rule {
+	protocol := iptables.ProtocolIPv4
+	if utilnet.IsIPv6CIDRString(secondaryMasqSubnet) {
+		protocol = iptables.ProtocolIPv6
+	}
+	return []nodeipt.Rule{
+		{
+			Table: "nat",
+			Chain: "POSTROUTING",
+			Args: []string{
+				"-s", secondaryMasqSubnet,
+				"!", "-d", secondaryMasqSubnet,
+				"-j", "MASQUERADE",
+			},
+			Protocol: protocol,
+		},
+	}
+}
+
+func getSecondaryNetworkGatewayForwardRules(secondaryMasqSubnet string) []nodeipt.Rule {
+	protocol := iptables.ProtocolIPv4
+	if utilnet.IsIPv6CIDRString(secondaryMasqSubnet) {
+		protocol = iptables.ProtocolIPv6
+	}
+	return []nodeipt.Rule{
+		{
+			Table: "filter",
+			Chain: "FORWARD",
+			Args: []string{
+				"-s", secondaryMasqSubnet,
+				"-j", "ACCEPT",
+			},
+			Protocol: protocol,
+		},
+		{
+			Table: "filter",
+			Chain: "FORWARD",
+			Args: []string{
+				"-d", secondaryMasqSubnet,
+				"-j", "ACCEPT",
+			},
+			Protocol: protocol,
+		},
+	}
+}
+
+// initSecondaryNetworkGatewayRules sets up iptables rules for the traffic the
+// gateway routers of the secondary networks send through the host
+// -A POSTROUTING -s 169.254.0.0/17 ! -d 169.254.0.0/17 -j MASQUERADE
+// and, if forwarding is disabled,
+// -I FORWARD -s 169.254.0.0/17 -j ACCEPT
+// -I FORWARD -d 169.254.0.0/17 -j ACCEPT
+func initSecondaryNetworkGatewayRules() error {
+	var secondaryMasqSubnets []string
+	if config.IPv4Mode {
+		secondaryMasqSubnets = append(secondaryMasqSubnets, config.Gateway.V4SecondaryMasqueradeSubnet)
+	}
+	if config.IPv6Mode {
+		secondaryMasqSubnets = append(secondaryMasqSubnets, config.Gateway.V6SecondaryMasqueradeSubnet)
+	}
+	for _, secondaryMasqSubnet := range secondaryMasqSubnets {
+		if config.Gateway.DisableForwarding {
+			if err := insertIptRules(getSecondaryNetworkGatewayForwardRules(secondaryMasqSubnet)); err != nil {
+				return fmt.Errorf("unable to insert forwarding rules for %s: %v", secondaryMasqSubnet, err)
+			}
+		}
+		if err := appendIptRules(getSecondaryNetworkGatewayRules(secondaryMasqSubnet)); err != nil {
+			return fmt.Errorf("unable to append masquerade rules for %s: %v", secondaryMasqSubnet, err)
+		}
+	}
+	return nil
+}
+
 func getLocalGatewayFilterRules(ifname string, cidr *net.IPNet) []nodeipt.Rule {
 	// Allow packets to/from the gateway interface in case defaults deny
 	protocol := getIPTablesProtocol(cidr.IP.String())
@@ -411,7 +509,7 @@ func getLocalGatewayNATRules(ifname string, cidr *net.IPNet) []nodeipt.Rule {
 	if protocol == iptables.ProtocolIPv6 {
 		masqueradeIP = config.Gateway.MasqueradeIPs.V6OVNMasqueradeIP
 	}
-	return []nodeipt.Rule{
+	return append([]nodeipt.Rule{
 		{
 			Table: "nat",
 			Chain: "POSTROUTING",
@@ -421,6 +519,13 @@ func getLocalGatewayNATRules(ifname string, cidr *net.IPNet) []nodeipt.Rule {
 			},
 			Protocol: protocol,
 		},
+	}, getLocalGatewayPodSNATRules(cidr)...)
+}
+
+// getLocalGatewayPodSNATRules returns the rules masquerading the pod egress
+// traffic leaving the node through the management port
+func getLocalGatewayPodSNATRules(cidr *net.IPNet) []nodeipt.Rule {
+	return []nodeipt.Rule{
 		{
 			Table: "nat",
 			Chain: "POSTROUTING",
@@ -428,7 +533,7 @@ func getLocalGatewayNATRules(ifname string, cidr *net.IPNet) []nodeipt.Rule {
 				"-s", cidr.String(),
 				"-j", "MASQUERADE",
 			},
-			Protocol: protocol,
+			Protocol: getIPTablesProtocol(cidr.IP.String()),
 		},
 	}
 }
@@ -442,9 +547,28 @@ func initLocalGatewayNATRules(ifname string, cidr *net.IPNet) error {
 	if err != nil {
 		return fmt.Errorf("unable to insert forwarding rules %v", err)
 	}
+	natRules := getLocalGatewayNATRules(ifname, cidr)
+	if config.BGP.DisableSNAT {
+		// the pod subnets are advertised to the BGP peers, do not masquerade
+		// the pod egress traffic and remove the rules an earlier run added
+		podSNATRules := getLocalGatewayPodSNATRules(cidr)
+		if err := nodeipt.DelRules(podSNATRules); err != nil {
+			return fmt.Errorf("unable to delete pod SNAT rules %v", err)
+		}
+		natRules = natRules[:len(natRules)-len(podSNATRules)]
+	}
 	// append the masquerade rules in POSTROUTING table since that needs to be
 	// evaluated last.
-	return appendIptRules(getLocalGatewayNATRules(ifname, cidr))
+	return appendIptRules(natRules)
+}
+
+// delLocalGatewayNATRules removes the rules added by initLocalGatewayNATRules
+func delLocalGatewayNATRules(ifname string, cidr *net.IPNet) error {
+	rules := append(getLocalGatewayFilterRules(ifname, cidr), getLocalGatewayNATRules(ifname, cidr)...)
+	if err := nodeipt.DelRules(rules); err != nil {
+		return fmt.Errorf("unable to delete local gateway rules for %s: %v", ifname, err)
+	}
+	return nil
 }
 
 func addChaintoTable(ipt util.IPTablesHelper, tableName, chain string) {
@@ -503,6 +627,71 @@ func cleanupSharedGatewayIPTChains() {
 	}
 }
 
+// cleanupLegacyIPTables removes the iptables chains programmed by ovnkube-node when running
+// with the iptables firewall backend, once the node has switched to the nftables backend
+func cleanupLegacyIPTables() {
+	for _, proto := range []iptables.Protocol{iptables.ProtocolIPv4, iptables.ProtocolIPv6} {
+		ipt, err := iptables.NewWithProtocol(proto)
+		if err != nil {
+			klog.V(5).Infof("Skipping cleanup of legacy iptables chains for protocol %v: %v", proto, err)
+			continue
+		}
+		if err := cleanupOVNKubeIPTChains(ipt); err != nil {
+			klog.Warningf("Failed to cleanup legacy iptables chains for protocol %v: %v", proto, err)
+		}
+	}
+}
+
+// cleanupOVNKubeIPTChains removes the chains owned by ovnkube-node from the nat, mangle and
+// filter tables, along with the rules of the other chains jumping to them
+func cleanupOVNKubeIPTChains(ipt util.IPTablesHelper) error {
+	var errors []error
+	for _, table := range []string{"nat", "mangle", "filter"} {
+		chains, err := ipt.ListChains(table)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("failed to list chains of table %s: %v", table, err))
+			continue
+		}
+		var ownedChains []string
+		for _, chain := range chains {
+			if strings.HasPrefix(chain, ovnKubeChainPrefix) {
+				ownedChains = append(ownedChains, chain)
+				continue
+			}
+			if chain == "" {
+				continue
+			}
+			rules, err := ipt.List(table, chain)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("failed to list rules of chain %s in table %s: %v", chain, table, err))
+				continue
+			}
+			for _, rule := range rules {
+				fields := strings.Fields(rule)
+				if len(fields) < 4 || fields[0] != "-A" || fields[len(fields)-2] != "-j" ||
+					!strings.HasPrefix(fields[len(fields)-1], ovnKubeChainPrefix) {
+					continue
+				}
+				if err := ipt.Delete(table, chain, fields[2:]...); err != nil {
+					errors = append(errors, fmt.Errorf("failed to delete rule %q of chain %s in table %s: %v", rule, chain, table, err))
+				}
+			}
+		}
+		// flush all the owned chains first as they may jump to each other
+		for _, chain := range ownedChains {
+			if err := ipt.ClearChain(table, chain); err != nil {
+				errors = append(errors, fmt.Errorf("failed to flush chain %s in table %s: %v", chain, table, err))
+			}
+		}
+		for _, chain := range ownedChains {
+			if err := ipt.DeleteChain(table, chain); err != nil {
+				errors = append(errors, fmt.Errorf("failed to delete chain %s in table %s: %v", chain, table, err))
+			}
+		}
+	}
+	return apierrors.NewAggregate(errors)
+}
+
 func recreateIPTRules(table, chain string, keepIPTRules []nodeipt.Rule) error {
 	var errors []error
 	var err error
@@ -512,6 +701,13 @@ func recreateIPTRules(table, chain string, keepIPTRules []nodeipt.Rule) error {
 			errors = append(errors, err)
 			continue
 		}
+		if replacer, ok := ipt.(util.IPTablesChainReplacer); ok {
+			// replace the chain in a single operation, the remaining rules are then found to exist already
+			if err = replacer.ReplaceChain(table, chain, getChainRuleSpecs(table, chain, proto, keepIPTRules)); err != nil {
+				errors = append(errors, fmt.Errorf("error replacing Chain: %s in Table: %s, err: %v", chain, table, err))
+			}
+			continue
+		}
 		if err = ipt.ClearChain(table, chain); err != nil {
 			errors = append(errors, fmt.Errorf("error clearing Chain: %s in Table: %s, err: %v", chain, table, err))
 		}
@@ -522,6 +718,26 @@ func recreateIPTRules(table, chain string, keepIPTRules []nodeipt.Rule) error {
 	return apierrors.NewAggregate(errors)
 }
 
+// getChainRuleSpecs returns the rulespecs of the given table/chain/protocol in the order
+// insertIptRules would leave them in the chain: each rule is inserted at the top of the chain
+// unless it already exists
+func getChainRuleSpecs(table, chain string, proto iptables.Protocol, rules []nodeipt.Rule) [][]string {
+	var ruleSpecs [][]string
+	seen := sets.New[string]()
+	for _, r := range rules {
+		if r.Table != table || r.Chain != chain || r.Protocol != proto {
+			continue
+		}
+		key := strings.Join(r.Args, " ")
+		if seen.Has(key) {
+			continue
+		}
+		seen.Insert(key)
+		ruleSpecs = append([][]string{r.Args}, ruleSpecs...)
+	}
+	return ruleSpecs
+}
+
 // getGatewayIPTRules returns ClusterIP, NodePort, ExternalIP and LoadBalancer iptables rules for service.
 // case1: If !svcHasLocalHostNetEndPnt and svcTypeIsETPLocal rules that redirect traffic
 // to ovn-k8s-mp0 preserving sourceIP are added.
@@ -531,11 +747,15 @@ func recreateIPTRules(table, chain string, keepIPTRules []nodeipt.Rule) error {
 // case3: if svcHasLocalHostNetEndPnt and svcTypeIsITPLocal, rule that redirects clusterIP traffic to host targetPort is added.
 //
 //	if !svcHasLocalHostNetEndPnt and svcTypeIsITPLocal, rule that marks clusterIP traffic to steer it to ovn-k8s-mp0 is added.
+//
+// case4: if the service forwards a port range or all ports, a DNAT rule towards clusterIP for the whole range replaces
+// the ExternalIP and LoadBalancer rules of the service ports. Local traffic policies do not apply to such services.
 func getGatewayIPTRules(service *kapi.Service, localEndpoints []string, svcHasLocalHostNetEndPnt bool) []nodeipt.Rule {
 	rules := make([]nodeipt.Rule, 0)
 	clusterIPs := util.GetClusterIPs(service)
 	svcTypeIsETPLocal := util.ServiceExternalTrafficPolicyLocal(service)
 	svcTypeIsITPLocal := util.ServiceInternalTrafficPolicyLocal(service)
+	portRange := getServiceLBPortRange(service)
 	for _, svcPort := range service.Spec.Ports {
 		if util.ServiceTypeHasNodePort(service) {
 			err := util.ValidatePort(svcPort.Protocol, svcPort.NodePort)
@@ -552,7 +772,7 @@ func getGatewayIPTRules(service *kapi.Service, localEndpoints []string, svcHasLo
 				if svcTypeIsETPLocal && !svcHasLocalHostNetEndPnt {
 					// case1 (see function description for details)
 					// A DNAT rule to masqueradeIP is added that takes priority over DNAT to clusterIP.
-					if config.Gateway.Mode == config.GatewayModeLocal {
+					if config.GetGatewayMode() == config.GatewayModeLocal {
 						rules = append(rules, getNodePortIPTRules(svcPort, clusterIP, svcPort.NodePort, svcHasLocalHostNetEndPnt, svcTypeIsETPLocal)...)
 					}
 					// add a skip SNAT rule to OVN-KUBE-SNAT-MGMTPORT to preserve sourceIP for etp=local traffic.
@@ -565,6 +785,18 @@ func getGatewayIPTRules(service *kapi.Service, localEndpoints []string, svcHasLo
 
 		externalIPs := util.GetExternalAndLBIPs(service)
 
+		if portRange != nil {
+			// case4 (see function description for details)
+			if portRange.AppliesTo(service, svcPort) {
+				for _, externalIP := range externalIPs {
+					if clusterIP, err := util.MatchIPStringFamily(utilnet.IsIPv6String(externalIP), clusterIPs); err == nil {
+						rules = append(rules, getExternalIPPortRangeIPTRules(svcPort.Protocol, portRange, externalIP, clusterIP)...)
+					}
+				}
+			}
+			continue
+		}
+
 		for _, externalIP := range externalIPs {
 			err := util.ValidatePort(svcPort.Protocol, svcPort.Port)
 			if err != nil {
@@ -0,0 +1,61 @@
+package node
+
+import (
+	"github.com/coreos/go-iptables/iptables"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	nodeipt "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/iptables"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Gateway iptables", func() {
+	It("removes the chains owned by ovnkube-node and the rules jumping to them", func() {
+		iptV4, _ := util.SetFakeIPTablesHelpers()
+		for _, chain := range []struct{ table, chain string }{
+			{"nat", "PREROUTING"},
+			{"nat", "OVN-KUBE-NODEPORT"},
+			{"nat", "OVN-KUBE-ETP"},
+			{"filter", "FORWARD"},
+			{"mangle", "OUTPUT"},
+			{"mangle", "OVN-KUBE-ITP"},
+		} {
+			Expect(iptV4.NewChain(chain.table, chain.chain)).To(Succeed())
+		}
+		Expect(iptV4.Append("nat", "PREROUTING", "-j", "KUBE-SERVICES")).To(Succeed())
+		Expect(iptV4.Append("nat", "PREROUTING", "-j", "OVN-KUBE-NODEPORT")).To(Succeed())
+		Expect(iptV4.Append("nat", "OVN-KUBE-NODEPORT", "-p", "TCP", "--dport", "30080", "-j", "OVN-KUBE-ETP")).To(Succeed())
+		Expect(iptV4.Append("nat", "OVN-KUBE-ETP", "-p", "TCP", "--dport", "30080", "-j", "DNAT", "--to-destination", "169.254.169.3:30080")).To(Succeed())
+		Expect(iptV4.Append("filter", "FORWARD", "-i", "breth0", "-j", "ACCEPT")).To(Succeed())
+		Expect(iptV4.Append("mangle", "OUTPUT", "-j", "OVN-KUBE-ITP")).To(Succeed())
+
+		Expect(cleanupOVNKubeIPTChains(iptV4)).To(Succeed())
+
+		expectedTables := map[string]util.FakeTable{
+			"nat": {
+				"PREROUTING": []string{"-j KUBE-SERVICES"},
+			},
+			"filter": {
+				"FORWARD": []string{"-i breth0 -j ACCEPT"},
+			},
+			"mangle": {
+				"OUTPUT": []string{},
+			},
+		}
+		Expect(iptV4.(*util.FakeIPTables).MatchState(expectedTables)).To(Succeed())
+	})
+
+	It("orders the rulespecs of a chain as inserting them would", func() {
+		rules := []nodeipt.Rule{
+			{Table: "nat", Chain: iptableNodePortChain, Args: []string{"-p", "TCP", "--dport", "30080"}, Protocol: iptables.ProtocolIPv4},
+			{Table: "nat", Chain: iptableExternalIPChain, Args: []string{"-p", "TCP", "--dport", "80"}, Protocol: iptables.ProtocolIPv4},
+			{Table: "nat", Chain: iptableNodePortChain, Args: []string{"-p", "TCP", "--dport", "30081"}, Protocol: iptables.ProtocolIPv4},
+			{Table: "nat", Chain: iptableNodePortChain, Args: []string{"-p", "TCP", "--dport", "30080"}, Protocol: iptables.ProtocolIPv4},
+			{Table: "nat", Chain: iptableNodePortChain, Args: []string{"-p", "TCP", "--dport", "30082"}, Protocol: iptables.ProtocolIPv6},
+		}
+		Expect(getChainRuleSpecs("nat", iptableNodePortChain, iptables.ProtocolIPv4, rules)).To(Equal([][]string{
+			{"-p", "TCP", "--dport", "30081"},
+			{"-p", "TCP", "--dport", "30080"},
+		}))
+	})
+})
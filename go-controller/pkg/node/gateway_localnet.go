@@ -8,6 +8,7 @@ import (
 	"net"
 	"strings"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
@@ -15,13 +16,14 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
+	"github.com/vishvananda/netlink"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 )
 
 func newLocalGateway(nodeName string, hostSubnets []*net.IPNet, gwNextHops []net.IP, gwIntf, egressGWIntf string, gwIPs []*net.IPNet,
 	nodeAnnotator kube.Annotator, cfg *managementPortConfig, kube kube.Interface, watchFactory factory.NodeWatchFactory,
-	routeManager *routemanager.Controller) (*gateway, error) {
+	routeManager *routemanager.Controller, ovsClient libovsdbclient.Client) (*gateway, error) {
 	klog.Info("Creating new local gateway")
 	gw := &gateway{}
 
@@ -43,28 +45,18 @@ func newLocalGateway(nodeName string, hostSubnets []*net.IPNet, gwNextHops []net
 		}
 	}
 
-	gwBridge, exGwBridge, err := gatewayInitInternal(
+	gwBridge, exGwBridge, uplinkBridges, err := gatewayInitInternal(ovsClient,
 		nodeName, gwIntf, egressGWIntf, gwNextHops, gwIPs, nodeAnnotator)
 	if err != nil {
 		return nil, err
 	}
 
+	bridges := append([]*bridgeConfiguration{gwBridge}, uplinkBridges...)
 	if exGwBridge != nil {
-		gw.readyFunc = func() (bool, error) {
-			ready, err := gatewayReady(gwBridge.patchPort)
-			if err != nil {
-				return false, err
-			}
-			exGWReady, err := gatewayReady(exGwBridge.patchPort)
-			if err != nil {
-				return false, err
-			}
-			return ready && exGWReady, nil
-		}
-	} else {
-		gw.readyFunc = func() (bool, error) {
-			return gatewayReady(gwBridge.patchPort)
-		}
+		bridges = append(bridges, exGwBridge)
+	}
+	gw.readyFunc = func() (bool, error) {
+		return gatewayBridgesReady(bridges...)
 	}
 
 	gw.initFunc = func() error {
@@ -74,18 +66,17 @@ func newLocalGateway(nodeName string, hostSubnets []*net.IPNet, gwNextHops []net
 			return err
 		}
 		if exGwBridge != nil {
-			err = setBridgeOfPorts(exGwBridge)
-			if err != nil {
+			if err = initExternalBridge(exGwBridge); err != nil {
 				return err
 			}
-			if config.Gateway.DisableForwarding {
-				if err := initExternalBridgeDropForwardingRules(exGwBridge.bridgeName); err != nil {
-					return fmt.Errorf("failed to add forwarding block rules for bridge %s: err %v", exGwBridge.bridgeName, err)
-				}
+		}
+		for _, uplinkBridge := range uplinkBridges {
+			if err = initExternalBridge(uplinkBridge); err != nil {
+				return err
 			}
 		}
 
-		gw.nodeIPManager = newAddressManager(nodeName, kube, cfg, watchFactory, gwBridge)
+		gw.nodeIPManager = newAddressManager(nodeName, kube, cfg, watchFactory, gwBridge, gwNextHops, ovsClient)
 
 		if err := setNodeMasqueradeIPOnExtBridge(gwBridge.bridgeName); err != nil {
 			return fmt.Errorf("failed to set the node masquerade IP on the ext bridge %s: %v", gwBridge.bridgeName, err)
@@ -95,7 +86,17 @@ func newLocalGateway(nodeName string, hostSubnets []*net.IPNet, gwNextHops []net
 			return fmt.Errorf("failed to set the node masquerade route to OVN: %v", err)
 		}
 
-		gw.openflowManager, err = newGatewayOpenFlowManager(gwBridge, exGwBridge, hostSubnets, gw.nodeIPManager.ListAddresses())
+		if err := addUplinkDestinationRoutes(routeManager, uplinkBridges); err != nil {
+			return fmt.Errorf("failed to set the gateway uplink routes: %v", err)
+		}
+
+		if util.IsSecondaryNetworkGatewaySupportEnabled() {
+			if err := initSecondaryNetworkGatewayRules(); err != nil {
+				return fmt.Errorf("failed to set the secondary network gateway rules: %v", err)
+			}
+		}
+
+		gw.openflowManager, err = newGatewayOpenFlowManager(gwBridge, exGwBridge, uplinkBridges, hostSubnets, gw.nodeIPManager.ListAddresses())
 		if err != nil {
 			return err
 		}
@@ -132,6 +133,12 @@ func newLocalGateway(nodeName string, hostSubnets []*net.IPNet, gwNextHops []net
 			return fmt.Errorf("failed to add MAC bindings for service routing")
 		}
 
+		if config.OvnKubeNode.Mode == types.NodeModeFull {
+			gw.modeController = newGatewayModeController(nodeName, kube, watchFactory, func() error {
+				return gw.reprogramGatewayMode(hostSubnets, cfg, routeManager, uplinkBridges)
+			})
+		}
+
 		return nil
 	}
 	gw.watchFactory = watchFactory.(*factory.WatchFactory)
@@ -188,3 +195,56 @@ func cleanupLocalnetGateway(physnet string) error {
 	}
 	return err
 }
+
+// getUplinkDestinationRoutes returns the routes of the destinations of the
+// gateway uplinks through the bridge of each uplink
+func getUplinkDestinationRoutes(uplinkBridges []*bridgeConfiguration, getLink func(string) (netlink.Link, error)) ([]routemanager.RoutesPerLink, error) {
+	var routesPerLink []routemanager.RoutesPerLink
+	for i, uplink := range config.Gateway.Uplinks {
+		if len(uplink.Destinations) == 0 {
+			continue
+		}
+		link, err := getLink(uplinkBridges[i].bridgeName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find uplink %s bridge interface %s: %v", uplink.Name, uplinkBridges[i].bridgeName, err)
+		}
+		routes := make([]routemanager.Route, 0, len(uplink.Destinations))
+		for _, destination := range uplink.Destinations {
+			nextHop, err := util.MatchFirstIPFamily(utilnet.IsIPv6CIDR(destination), uplink.NextHops)
+			if err != nil {
+				return nil, fmt.Errorf("no next hop of uplink %s for destination %s: %v", uplink.Name, destination, err)
+			}
+			routes = append(routes, routemanager.Route{GwIP: nextHop, Subnet: destination})
+		}
+		routesPerLink = append(routesPerLink, routemanager.RoutesPerLink{Link: link, Routes: routes})
+	}
+	return routesPerLink, nil
+}
+
+// addUplinkDestinationRoutes routes the destinations of the additional gateway
+// uplinks through their bridges, as the egress traffic of the pods is routed
+// by the host in local gateway mode
+func addUplinkDestinationRoutes(routeManager *routemanager.Controller, uplinkBridges []*bridgeConfiguration) error {
+	routesPerLink, err := getUplinkDestinationRoutes(uplinkBridges, util.LinkSetUp)
+	if err != nil {
+		return err
+	}
+	for _, rl := range routesPerLink {
+		klog.Infof("Adding gateway uplink routes %s", rl.String())
+		routeManager.Add(rl)
+	}
+	return nil
+}
+
+// delUplinkDestinationRoutes removes the routes added by addUplinkDestinationRoutes
+func delUplinkDestinationRoutes(routeManager *routemanager.Controller, uplinkBridges []*bridgeConfiguration) error {
+	routesPerLink, err := getUplinkDestinationRoutes(uplinkBridges, util.GetNetLinkOps().LinkByName)
+	if err != nil {
+		return err
+	}
+	for _, rl := range routesPerLink {
+		klog.Infof("Deleting gateway uplink routes %s", rl.String())
+		routeManager.Del(rl)
+	}
+	return nil
+}
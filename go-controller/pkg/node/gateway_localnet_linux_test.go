@@ -16,7 +16,6 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
 	"github.com/urfave/cli/v2"
-	"github.com/vishvananda/netlink"
 
 	kapi "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
@@ -66,7 +65,7 @@ func startNodePortWatcher(n *nodePortWatcher, fakeClient *util.OVNNodeClientset,
 	}
 
 	k := &kube.Kube{KClient: fakeClient.KubeClient}
-	n.nodeIPManager = newAddressManagerInternal(fakeNodeName, k, fakeMgmtPortConfig, n.watchFactory, nil, false)
+	n.nodeIPManager = newAddressManagerInternal(fakeNodeName, k, fakeMgmtPortConfig, n.watchFactory, nil, nil, nil, false)
 	localHostNetEp := "192.168.18.15/32"
 	ip, ipnet, _ := net.ParseCIDR(localHostNetEp)
 	n.nodeIPManager.addAddr(net.IPNet{IP: ip, Mask: ipnet.Mask})
@@ -98,7 +97,7 @@ func startNodePortWatcherWithRetry(n *nodePortWatcher, fakeClient *util.OVNNodeC
 	}
 
 	k := &kube.Kube{KClient: fakeClient.KubeClient}
-	n.nodeIPManager = newAddressManagerInternal(fakeNodeName, k, fakeMgmtPortConfig, n.watchFactory, nil, false)
+	n.nodeIPManager = newAddressManagerInternal(fakeNodeName, k, fakeMgmtPortConfig, n.watchFactory, nil, nil, nil, false)
 	localHostNetEp := "192.168.18.15/32"
 	ip, ipnet, _ := net.ParseCIDR(localHostNetEp)
 	n.nodeIPManager.addAddr(net.IPNet{IP: ip, Mask: ipnet.Mask})
@@ -168,52 +167,6 @@ func newEndpointSlice(svcName, namespace string, endpoints []discovery.Endpoint,
 	}
 }
 
-func makeConntrackFilter(ip string, port int, protocol kapi.Protocol) *netlink.ConntrackFilter {
-	filter := &netlink.ConntrackFilter{}
-
-	var err error
-	if protocol == kapi.ProtocolUDP {
-		err = filter.AddProtocol(17)
-	} else if protocol == kapi.ProtocolTCP {
-		err = filter.AddProtocol(6)
-	} else if protocol == kapi.ProtocolSCTP {
-		err = filter.AddProtocol(132)
-	}
-	Expect(err).NotTo(HaveOccurred())
-
-	if port > 0 {
-		err = filter.AddPort(netlink.ConntrackOrigDstPort, uint16(port))
-		Expect(err).NotTo(HaveOccurred())
-	}
-	ipAddress := net.ParseIP(ip)
-	Expect(ipAddress).NotTo(BeNil())
-	err = filter.AddIP(netlink.ConntrackOrigDstIP, ipAddress)
-	Expect(err).NotTo(HaveOccurred())
-
-	return filter
-}
-
-type ctFilterDesc struct {
-	ip   string
-	port int
-}
-
-func addConntrackMocks(nlMock *mocks.NetLinkOps, filterDescs []ctFilterDesc) {
-	ctMocks := make([]ovntest.TestifyMockHelper, 0, len(filterDescs))
-	for _, ctf := range filterDescs {
-		ctMocks = append(ctMocks, ovntest.TestifyMockHelper{
-			OnCallMethodName: "ConntrackDeleteFilter",
-			OnCallMethodArgs: []interface{}{
-				netlink.ConntrackTableType(netlink.ConntrackTable),
-				netlink.InetFamily(netlink.FAMILY_V4),
-				makeConntrackFilter(ctf.ip, ctf.port, kapi.ProtocolTCP),
-			},
-			RetArgList: []interface{}{uint(1), nil},
-		})
-	}
-	ovntest.ProcessMockFnList(&nlMock.Mock, ctMocks)
-}
-
 var _ = Describe("Node Operations", func() {
 	var (
 		app                *cli.App
@@ -447,6 +400,107 @@ var _ = Describe("Node Operations", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("inits iptables rules and openflows with ExternalIP forwarding a port range, SGW mode", func() {
+			app.Action = func(ctx *cli.Context) error {
+				externalIP := "1.1.1.1"
+				config.Gateway.Mode = config.GatewayModeShared
+				for i := 0; i < 2; i++ {
+					fakeOvnNode.fakeExec.AddFakeCmd(&ovntest.ExpectedCmd{
+						Cmd: "ovs-ofctl show ",
+						Err: fmt.Errorf("deliberate error to fall back to output:LOCAL"),
+					})
+				}
+
+				service := *newService("service1", "namespace1", "10.129.0.2",
+					[]v1.ServicePort{
+						{
+							Name:     "sip",
+							Port:     5060,
+							Protocol: v1.ProtocolTCP,
+						},
+						{
+							Name:     "sip-udp",
+							Port:     5060,
+							Protocol: v1.ProtocolUDP,
+						},
+					},
+					v1.ServiceTypeClusterIP,
+					[]string{externalIP},
+					v1.ServiceStatus{},
+					false, false,
+				)
+				service.Annotations = map[string]string{util.LBPortRangeAnnotation: "9999-10001"}
+				fakeOvnNode.start(ctx,
+					&v1.ServiceList{
+						Items: []v1.Service{
+							service,
+						},
+					},
+				)
+
+				fNPW.watchFactory = fakeOvnNode.watcher
+				Expect(startNodePortWatcher(fNPW, fakeOvnNode.fakeClient, &fakeMgmtPortConfig)).To(Succeed())
+				err := fNPW.AddService(&service)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedTables := map[string]util.FakeTable{
+					"nat": {
+						"PREROUTING": []string{
+							"-j OVN-KUBE-ETP",
+							"-j OVN-KUBE-EXTERNALIP",
+							"-j OVN-KUBE-NODEPORT",
+						},
+						"OUTPUT": []string{
+							"-j OVN-KUBE-EXTERNALIP",
+							"-j OVN-KUBE-NODEPORT",
+							"-j OVN-KUBE-ITP",
+						},
+						"POSTROUTING": []string{
+							"-j OVN-KUBE-EGRESS-SVC",
+						},
+						"OVN-KUBE-NODEPORT": []string{},
+						"OVN-KUBE-EXTERNALIP": []string{
+							fmt.Sprintf("-p UDP -d %s --dport 9999:10001 -j DNAT --to-destination %s", externalIP, service.Spec.ClusterIP),
+							fmt.Sprintf("-p TCP -d %s --dport 9999:10001 -j DNAT --to-destination %s", externalIP, service.Spec.ClusterIP),
+						},
+						"OVN-KUBE-SNAT-MGMTPORT": []string{},
+						"OVN-KUBE-ETP":           []string{},
+						"OVN-KUBE-ITP":           []string{},
+						"OVN-KUBE-EGRESS-SVC":    []string{},
+					},
+					"filter": {},
+					"mangle": {
+						"OUTPUT": []string{
+							"-j OVN-KUBE-ITP",
+						},
+						"OVN-KUBE-ITP": []string{},
+					},
+				}
+
+				f4 := iptV4.(*util.FakeIPTables)
+				err = f4.MatchState(expectedTables)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, proto := range []string{"tcp", "udp"} {
+					cookie, err := svcToCookie(service.Namespace, service.Name, externalIP+proto, 9999)
+					Expect(err).NotTo(HaveOccurred())
+					expectedFlows := []string{
+						fmt.Sprintf("cookie=%s, priority=110, in_port=eth0, arp, arp_op=1, arp_tpa=1.1.1.1, actions=output:LOCAL", cookie),
+						fmt.Sprintf("cookie=%s, priority=110, in_port=eth0, icmp, nw_dst=1.1.1.1, icmp_type=3, icmp_code=4, actions=output:patch-breth0_ov", cookie),
+						fmt.Sprintf("cookie=%s, priority=110, in_port=eth0, %s, nw_dst=1.1.1.1, tp_dst=9999, actions=output:patch-breth0_ov", cookie, proto),
+						fmt.Sprintf("cookie=%s, priority=110, in_port=patch-breth0_ov, %s, nw_src=1.1.1.1, tp_src=9999, actions=output:eth0", cookie, proto),
+						fmt.Sprintf("cookie=%s, priority=110, in_port=eth0, %s, nw_dst=1.1.1.1, tp_dst=0x2710/0xfffe, actions=output:patch-breth0_ov", cookie, proto),
+						fmt.Sprintf("cookie=%s, priority=110, in_port=patch-breth0_ov, %s, nw_src=1.1.1.1, tp_src=0x2710/0xfffe, actions=output:eth0", cookie, proto),
+					}
+					Expect(fNPW.ofm.flowCache["External_namespace1_service1_1.1.1.1_"+proto+"_9999-10001"]).To(Equal(expectedFlows))
+				}
+				Expect(fNPW.ofm.flowCache).NotTo(HaveKey("External_namespace1_service1_1.1.1.1_5060"))
+				return nil
+			}
+			err := app.Run([]string{app.Name})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		It("inits iptables rules with NodePort", func() {
 			app.Action = func(ctx *cli.Context) error {
 
@@ -1198,6 +1252,132 @@ var _ = Describe("Node Operations", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("inits openflows with LoadBalancer preserving the source IP where ETP=cluster, SGW mode", func() {
+			app.Action = func(ctx *cli.Context) error {
+				config.Gateway.Mode = config.GatewayModeShared
+				fakeOvnNode.fakeExec.AddFakeCmd(&ovntest.ExpectedCmd{
+					Cmd: "ovs-ofctl show ",
+					Err: fmt.Errorf("deliberate error to fall back to output:LOCAL"),
+				})
+				service := *newService("service1", "namespace1", "10.129.0.2",
+					[]v1.ServicePort{
+						{
+							NodePort: int32(31111),
+							Protocol: v1.ProtocolTCP,
+							Port:     int32(8080),
+						},
+					},
+					v1.ServiceTypeLoadBalancer,
+					nil,
+					v1.ServiceStatus{
+						LoadBalancer: v1.LoadBalancerStatus{
+							Ingress: []v1.LoadBalancerIngress{{
+								IP: "5.5.5.5",
+							}},
+						},
+					},
+					false, false,
+				)
+				service.Annotations = map[string]string{util.PreserveSourceIPAnnotation: "true"}
+				remoteNodeName := "node2"
+				remoteNode := v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: remoteNodeName,
+						Annotations: map[string]string{
+							"k8s.ovn.org/node-chassis-id":   "79fdcfc4-6fe6-4cd3-8242-c0f85a4668ec",
+							"k8s.ovn.org/l3-gateway-config": `{"default":{"mode":"shared","mac-address":"52:54:00:e2:ed:d0","ip-addresses":["192.168.122.14/24"],"ip-address":"192.168.122.14/24","next-hops":["192.168.122.1"],"next-hop":"192.168.122.1"}}`,
+						},
+					},
+				}
+				// the node hosting the other endpoint is not on the gateway subnet, so it is ignored
+				offSubnetNodeName := "node3"
+				offSubnetNode := v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: offSubnetNodeName,
+						Annotations: map[string]string{
+							"k8s.ovn.org/node-chassis-id":   "a4d5f5ab-a2e5-4e6c-8f34-1d6c2ba3c7f2",
+							"k8s.ovn.org/l3-gateway-config": `{"default":{"mode":"shared","mac-address":"52:54:00:e2:ed:e0","ip-addresses":["192.168.123.14/24"],"ip-address":"192.168.123.14/24","next-hops":["192.168.123.1"],"next-hop":"192.168.123.1"}}`,
+						},
+					},
+				}
+				epPortName := "https"
+				epPortValue := int32(443)
+				// the endpoints are hosted by other nodes, so traffic will be forwarded there
+				endpointSlice := *newEndpointSlice(
+					"service1",
+					"namespace1",
+					[]discovery.Endpoint{
+						{
+							Addresses: []string{"10.244.1.3"},
+							NodeName:  &remoteNodeName,
+						},
+						{
+							Addresses: []string{"10.244.2.3"},
+							NodeName:  &offSubnetNodeName,
+						},
+					},
+					[]discovery.EndpointPort{{
+						Name: &epPortName,
+						Port: &epPortValue,
+					}})
+
+				fakeOvnNode.start(ctx,
+					&v1.ServiceList{
+						Items: []v1.Service{
+							service,
+						},
+					},
+					&v1.NodeList{
+						Items: []v1.Node{
+							remoteNode,
+							offSubnetNode,
+						},
+					},
+					&endpointSlice,
+				)
+
+				fNPW.watchFactory = fakeOvnNode.watcher
+				fNPW.gwBridgeMAC = "0a:58:0a:01:01:01"
+				fNPW.ofm.defaultBridge = &bridgeConfiguration{ips: ovntest.MustParseIPNets("192.168.122.10/24")}
+				Expect(startNodePortWatcher(fNPW, fakeOvnNode.fakeClient, &fakeMgmtPortConfig)).To(Succeed())
+				err := fNPW.AddService(&service)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedNodePortFlows := []string{
+					"cookie=0x453ae29bcbbc08bd, priority=110, in_port=eth0, tcp, tp_dst=31111, actions=output:patch-breth0_ov",
+					"cookie=0x453ae29bcbbc08bd, priority=110, in_port=patch-breth0_ov, tcp, tp_src=31111, actions=output:eth0",
+				}
+				expectedLBIngressFlows := []string{
+					"cookie=0x10c6b89e483ea111, priority=110, in_port=eth0, arp, arp_op=1, arp_tpa=5.5.5.5, actions=output:LOCAL",
+					"cookie=0x10c6b89e483ea111, priority=110, in_port=eth0, icmp, nw_dst=5.5.5.5, icmp_type=3, icmp_code=4, actions=output:patch-breth0_ov",
+					"cookie=0x10c6b89e483ea111, priority=110, table=12, reg1=0, tcp, nw_dst=5.5.5.5, tp_dst=8080, actions=mod_dl_src:0a:58:0a:01:01:01,mod_dl_dst:52:54:00:e2:ed:d0,output:in_port",
+					"cookie=0x10c6b89e483ea111, priority=110, in_port=eth0, tcp, nw_dst=5.5.5.5, tp_dst=8080, actions=multipath(symmetric_l4,0,hrw,1,0,NXM_NX_REG1[0..15]),resubmit(,12)",
+					"cookie=0x10c6b89e483ea111, priority=110, in_port=patch-breth0_ov, tcp, nw_src=5.5.5.5, tp_src=8080, actions=output:eth0",
+				}
+
+				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
+				Expect(flows).To(Equal(expectedNodePortFlows))
+				flows = fNPW.ofm.flowCache["Ingress_namespace1_service1_5.5.5.5_8080"]
+				Expect(flows).To(Equal(expectedLBIngressFlows))
+
+				// the flows follow the gateway MAC address of the node hosting the endpoint
+				Expect(fNPW.watchNodeGatewayMACs()).To(Succeed())
+				remoteNode.Annotations["k8s.ovn.org/l3-gateway-config"] = `{"default":{"mode":"shared","mac-address":"52:54:00:e2:ed:d1","ip-addresses":["192.168.122.14/24"],"ip-address":"192.168.122.14/24","next-hops":["192.168.122.1"],"next-hop":"192.168.122.1"}}`
+				_, err = fakeOvnNode.fakeClient.KubeClient.CoreV1().Nodes().Update(context.TODO(), &remoteNode, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				expectedLBIngressFlows[2] = "cookie=0x10c6b89e483ea111, priority=110, table=12, reg1=0, tcp, nw_dst=5.5.5.5, tp_dst=8080, actions=mod_dl_src:0a:58:0a:01:01:01,mod_dl_dst:52:54:00:e2:ed:d1,output:in_port"
+				Eventually(func() []string {
+					fNPW.ofm.flowMutex.Lock()
+					defer fNPW.ofm.flowMutex.Unlock()
+					return fNPW.ofm.flowCache["Ingress_namespace1_service1_5.5.5.5_8080"]
+				}).Should(Equal(expectedLBIngressFlows))
+
+				return nil
+			}
+			err := app.Run([]string{app.Name})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		It("inits iptables rules with DualStack NodePort", func() {
 			app.Action = func(ctx *cli.Context) error {
 				nodePort := int32(31111)
@@ -1425,7 +1605,6 @@ var _ = Describe("Node Operations", func() {
 				fNPW.watchFactory = fakeOvnNode.watcher
 				Expect(startNodePortWatcher(fNPW, fakeOvnNode.fakeClient, &fakeMgmtPortConfig)).To(Succeed())
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"1.1.1.1", 8032}, {"10.129.0.2", 8032}})
 				err := fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeOvnNode.fakeExec.CalledMatchesExpected()).To(BeTrue(), fExec.ErrorDesc)
@@ -1506,7 +1685,6 @@ var _ = Describe("Node Operations", func() {
 				fNPW.watchFactory = fakeOvnNode.watcher
 				Expect(startNodePortWatcher(fNPW, fakeOvnNode.fakeClient, &fakeMgmtPortConfig)).To(Succeed())
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 0}, {"192.168.18.15", 31111}})
 				err := fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeOvnNode.fakeExec.CalledMatchesExpected()).To(BeTrue(), fExec.ErrorDesc)
@@ -1634,7 +1812,6 @@ var _ = Describe("Node Operations", func() {
 				err = f4.MatchState(expectedTables)
 				Expect(err).NotTo(HaveOccurred())
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.10.10.1", 8034}, {"10.129.0.2", 8034}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -1862,7 +2039,6 @@ var _ = Describe("Node Operations", func() {
 				err = f4.MatchState(expectedTables)
 				Expect(err).NotTo(HaveOccurred())
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 38034}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -1997,7 +2173,6 @@ var _ = Describe("Node Operations", func() {
 				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
 				Expect(flows).To(BeNil())
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 31111}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -2139,7 +2314,6 @@ var _ = Describe("Node Operations", func() {
 				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
 				Expect(flows).To(Equal(expectedFlows))
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 31111}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -2286,7 +2460,6 @@ var _ = Describe("Node Operations", func() {
 				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
 				Expect(flows).To(Equal(expectedFlows))
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 31111}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -2429,7 +2602,6 @@ var _ = Describe("Node Operations", func() {
 				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
 				Expect(flows).To(Equal(expectedFlows))
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 31111}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -2575,7 +2747,6 @@ var _ = Describe("Node Operations", func() {
 				flows := fNPW.ofm.flowCache["NodePort_namespace1_service1_tcp_31111"]
 				Expect(flows).To(Equal(expectedFlows))
 
-				addConntrackMocks(netlinkMock, []ctFilterDesc{{"10.129.0.2", 8080}, {"192.168.18.15", 31111}})
 				err = fNPW.DeleteService(&service)
 				Expect(err).NotTo(HaveOccurred())
 
@@ -2624,3 +2795,13 @@ var _ = Describe("Node Operations", func() {
 		})
 	})
 })
+
+var _ = Describe("Port range flows", func() {
+	It("matches port ranges of thousands of ports with a few flows", func() {
+		Expect(portRangeMatches(10000, 19999)).To(Equal([]string{
+			"0x2710/0xfff0", "0x2720/0xffe0", "0x2740/0xffc0", "0x2780/0xff80", "0x2800/0xf800",
+			"0x3000/0xf000", "0x4000/0xf800", "0x4800/0xfc00", "0x4c00/0xfe00", "0x4e00/0xffe0",
+		}))
+		Expect(portRangeMatches(1, 65535)).To(HaveLen(16))
+	})
+})
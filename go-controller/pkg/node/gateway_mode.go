@@ -0,0 +1,216 @@
+//go:build linux
+// +build linux
+
+package node
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+// gatewayModeCheckInterval is the interval at which the configuration file is
+// checked for a gateway mode change
+var gatewayModeCheckInterval = 30 * time.Second
+
+// gatewayModeController switches the node between the local and shared
+// gateway modes when the mode of the configuration file changes. The host is
+// reprogrammed for the new mode before the mode of the l3-gateway-config
+// annotation is updated, which has ovnkube-controller reprogram the routes of
+// the node in the cluster router. The "k8s.ovn.org/gateway-mode-status"
+// annotation reports the mode programmed on the node.
+type gatewayModeController struct {
+	nodeName     string
+	kube         kube.Interface
+	watchFactory factory.NodeWatchFactory
+
+	// readMode returns the desired gateway mode
+	readMode func() (config.GatewayMode, error)
+	// reprogram reprograms the host for the running gateway mode
+	reprogram func() error
+
+	// mode is the gateway mode programmed on the node
+	mode config.GatewayMode
+	// statusReported is true once the status annotation reports mode
+	statusReported bool
+	// rollbackFailed is true when the host could not be reprogrammed for mode
+	// after a failed switch
+	rollbackFailed bool
+}
+
+func newGatewayModeController(nodeName string, kube kube.Interface, watchFactory factory.NodeWatchFactory,
+	reprogram func() error) *gatewayModeController {
+	return &gatewayModeController{
+		nodeName:     nodeName,
+		kube:         kube,
+		watchFactory: watchFactory,
+		readMode:     config.ReadGatewayMode,
+		reprogram:    reprogram,
+		mode:         config.GetGatewayMode(),
+	}
+}
+
+// Run periodically checks the configured gateway mode until stopCh is closed
+func (c *gatewayModeController) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	klog.Infof("Starting gateway mode controller, gateway mode is %s", c.mode)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait.Until(func() {
+			if err := c.sync(); err != nil {
+				klog.Errorf("Failed to sync the gateway mode of node %s: %v", c.nodeName, err)
+			}
+		}, gatewayModeCheckInterval, stopCh)
+	}()
+}
+
+func (c *gatewayModeController) sync() error {
+	if !c.statusReported {
+		if err := c.setStatus(&util.GatewayModeStatus{Mode: c.mode}); err != nil {
+			return err
+		}
+		c.statusReported = true
+	}
+
+	desired, err := c.readMode()
+	if err != nil {
+		return err
+	}
+	// the host is reprogrammed again when the rollback of an earlier switch failed
+	if desired == c.mode && !c.rollbackFailed {
+		return nil
+	}
+
+	klog.Infof("Switching the gateway mode of node %s from %s to %s", c.nodeName, c.mode, desired)
+	if err := c.setStatus(&util.GatewayModeStatus{Mode: c.mode, TargetMode: desired}); err != nil {
+		return err
+	}
+	config.SetGatewayMode(desired)
+	err = c.reprogram()
+	if err == nil {
+		err = c.setL3GatewayMode(desired)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to switch to %s gateway mode: %v", desired, err)
+		c.rollback()
+		if statusErr := c.setStatus(&util.GatewayModeStatus{Mode: c.mode, TargetMode: desired, Error: err.Error()}); statusErr != nil {
+			klog.Errorf("Failed to report the gateway mode status of node %s: %v", c.nodeName, statusErr)
+		}
+		// the error is cleared from the status on the next sync
+		c.statusReported = false
+		return err
+	}
+	c.rollbackFailed = false
+	c.mode = desired
+	klog.Infof("Switched the gateway mode of node %s to %s", c.nodeName, desired)
+	return c.setStatus(&util.GatewayModeStatus{Mode: desired})
+}
+
+// rollback reprograms the host for the programmed gateway mode after a failed
+// switch, the l3-gateway-config annotation still holds the programmed mode
+func (c *gatewayModeController) rollback() {
+	config.SetGatewayMode(c.mode)
+	if err := c.reprogram(); err != nil {
+		klog.Errorf("Failed to roll back the gateway mode of node %s to %s: %v", c.nodeName, c.mode, err)
+		c.rollbackFailed = true
+		return
+	}
+	c.rollbackFailed = false
+}
+
+// setL3GatewayMode updates the mode of the l3-gateway-config annotation
+func (c *gatewayModeController) setL3GatewayMode(mode config.GatewayMode) error {
+	node, err := c.watchFactory.GetNode(c.nodeName)
+	if err != nil {
+		return err
+	}
+	l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+	if err != nil {
+		return err
+	}
+	if l3GatewayConfig.Mode == mode {
+		return nil
+	}
+	l3GatewayConfig.Mode = mode
+	nodeAnnotator := kube.NewNodeAnnotator(c.kube, c.nodeName)
+	if err := util.SetL3GatewayConfig(nodeAnnotator, l3GatewayConfig); err != nil {
+		return err
+	}
+	return nodeAnnotator.Run()
+}
+
+func (c *gatewayModeController) setStatus(status *util.GatewayModeStatus) error {
+	nodeAnnotator := kube.NewNodeAnnotator(c.kube, c.nodeName)
+	if err := util.SetNodeGatewayModeStatus(nodeAnnotator, status); err != nil {
+		return err
+	}
+	return nodeAnnotator.Run()
+}
+
+// reprogramGatewayMode reprograms the host for the running gateway mode after a
+// gateway mode switch: the management port rules and the uplink routes only
+// used in local gateway mode, the service rules and flows, and the bridge flows
+func (g *gateway) reprogramGatewayMode(hostSubnets []*net.IPNet, cfg *managementPortConfig,
+	routeManager *routemanager.Controller, uplinkBridges []*bridgeConfiguration) error {
+	localGateway := config.GetGatewayMode() == config.GatewayModeLocal
+
+	for _, hostSubnet := range hostSubnets {
+		var ifAddr *net.IPNet
+		if utilnet.IsIPv6CIDR(hostSubnet) {
+			ifAddr = cfg.ipv6.ifAddr
+		} else {
+			ifAddr = cfg.ipv4.ifAddr
+		}
+		cidr := &net.IPNet{IP: ifAddr.IP.Mask(ifAddr.Mask), Mask: ifAddr.Mask}
+		var err error
+		if localGateway {
+			err = initLocalGatewayNATRules(cfg.ifName, cidr)
+		} else {
+			err = delLocalGatewayNATRules(cfg.ifName, cidr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if localGateway {
+		err = addUplinkDestinationRoutes(routeManager, uplinkBridges)
+	} else {
+		err = delUplinkDestinationRoutes(routeManager, uplinkBridges)
+	}
+	if err != nil {
+		return err
+	}
+
+	if g.nodePortWatcher != nil {
+		services, err := g.watchFactory.GetServices()
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+		objs := make([]interface{}, 0, len(services))
+		for _, service := range services {
+			objs = append(objs, service)
+		}
+		if err := g.nodePortWatcher.SyncServices(objs); err != nil {
+			return fmt.Errorf("failed to reprogram services: %v", err)
+		}
+	}
+
+	if err := g.openflowManager.updateBridgeFlowCache(hostSubnets, g.nodeIPManager.ListAddresses()); err != nil {
+		return err
+	}
+	g.openflowManager.requestFlowSync()
+	return nil
+}
@@ -0,0 +1,159 @@
+//go:build linux
+// +build linux
+
+package node
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Gateway mode controller", func() {
+	const nodeName = "node1"
+
+	var (
+		kubeClient *fake.Clientset
+		wf         *factory.WatchFactory
+		desired    config.GatewayMode
+		reprogram  []config.GatewayMode
+		failures   int
+		c          *gatewayModeController
+	)
+
+	getNode := func() *v1.Node {
+		node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return node
+	}
+	getStatus := func() *util.GatewayModeStatus {
+		status, err := util.ParseNodeGatewayModeStatus(getNode())
+		Expect(err).NotTo(HaveOccurred())
+		return status
+	}
+	getL3GatewayMode := func() config.GatewayMode {
+		l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(getNode())
+		Expect(err).NotTo(HaveOccurred())
+		return l3GatewayConfig.Mode
+	}
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.Gateway.Mode = config.GatewayModeShared
+		desired = config.GatewayModeShared
+		reprogram = nil
+		failures = 0
+
+		kubeClient = fake.NewSimpleClientset(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+				Annotations: map[string]string{
+					util.OvnNodeChassisID: "79fdcfc4-6fe6-4cd3-8242-c0f85a4668ec",
+					util.OvnNodeL3GatewayConfig: `{"default":{"mode":"shared","mac-address":"0a:58:0a:01:01:01",` +
+						`"ip-addresses":["172.18.0.2/16"],"next-hops":["172.18.0.1"]}}`,
+				},
+			},
+		})
+		var err error
+		wf, err = factory.NewNodeWatchFactory(&util.OVNNodeClientset{KubeClient: kubeClient}, nodeName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wf.Start()).To(Succeed())
+
+		c = newGatewayModeController(nodeName, &kube.Kube{KClient: kubeClient}, wf, func() error {
+			reprogram = append(reprogram, config.Gateway.Mode)
+			if failures > 0 {
+				failures--
+				return fmt.Errorf("boom")
+			}
+			return nil
+		})
+		c.readMode = func() (config.GatewayMode, error) {
+			return desired, nil
+		}
+	})
+
+	AfterEach(func() {
+		wf.Shutdown()
+	})
+
+	It("reports the gateway mode and switches it when the configuration changes", func() {
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(BeEmpty())
+		Expect(getStatus()).To(Equal(&util.GatewayModeStatus{Mode: config.GatewayModeShared}))
+
+		desired = config.GatewayModeLocal
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(Equal([]config.GatewayMode{config.GatewayModeLocal}))
+		Expect(config.Gateway.Mode).To(Equal(config.GatewayModeLocal))
+		Expect(getStatus()).To(Equal(&util.GatewayModeStatus{Mode: config.GatewayModeLocal}))
+		Expect(getL3GatewayMode()).To(Equal(config.GatewayModeLocal))
+
+		// nothing changed, the host is not reprogrammed again
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(HaveLen(1))
+	})
+
+	It("reports a failed switch, rolls it back and retries it", func() {
+		Expect(c.sync()).To(Succeed())
+
+		desired = config.GatewayModeLocal
+		failures = 1
+		Expect(c.sync()).NotTo(Succeed())
+		// the host is reprogrammed for the programmed mode again
+		Expect(reprogram).To(Equal([]config.GatewayMode{config.GatewayModeLocal, config.GatewayModeShared}))
+		Expect(config.Gateway.Mode).To(Equal(config.GatewayModeShared))
+		status := getStatus()
+		Expect(status.Mode).To(Equal(config.GatewayModeShared))
+		Expect(status.TargetMode).To(Equal(config.GatewayModeLocal))
+		Expect(status.Error).To(ContainSubstring("boom"))
+		Expect(getL3GatewayMode()).To(Equal(config.GatewayModeShared))
+
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(Equal([]config.GatewayMode{config.GatewayModeLocal, config.GatewayModeShared, config.GatewayModeLocal}))
+		Expect(config.Gateway.Mode).To(Equal(config.GatewayModeLocal))
+		Expect(getStatus()).To(Equal(&util.GatewayModeStatus{Mode: config.GatewayModeLocal}))
+		Expect(getL3GatewayMode()).To(Equal(config.GatewayModeLocal))
+	})
+
+	It("clears the error of a failed switch reverted by the configuration", func() {
+		Expect(c.sync()).To(Succeed())
+
+		desired = config.GatewayModeLocal
+		failures = 1
+		Expect(c.sync()).NotTo(Succeed())
+
+		// the host was already rolled back, it is not reprogrammed again
+		desired = config.GatewayModeShared
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(Equal([]config.GatewayMode{config.GatewayModeLocal, config.GatewayModeShared}))
+		Expect(getStatus()).To(Equal(&util.GatewayModeStatus{Mode: config.GatewayModeShared}))
+		Expect(getL3GatewayMode()).To(Equal(config.GatewayModeShared))
+	})
+
+	It("reprograms the host for the programmed mode when the rollback failed", func() {
+		Expect(c.sync()).To(Succeed())
+
+		desired = config.GatewayModeLocal
+		// both the switch and its rollback fail
+		failures = 2
+		Expect(c.sync()).NotTo(Succeed())
+		Expect(c.rollbackFailed).To(BeTrue())
+
+		desired = config.GatewayModeShared
+		Expect(c.sync()).To(Succeed())
+		Expect(reprogram).To(Equal([]config.GatewayMode{config.GatewayModeLocal, config.GatewayModeShared, config.GatewayModeShared}))
+		Expect(c.rollbackFailed).To(BeFalse())
+		Expect(getStatus()).To(Equal(&util.GatewayModeStatus{Mode: config.GatewayModeShared}))
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
@@ -16,15 +17,16 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
-	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 
 	kapi "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	ktypes "k8s.io/apimachinery/pkg/types"
 	apierrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 )
@@ -80,6 +82,9 @@ type nodePortWatcher struct {
 	ofportPhys    string
 	ofportPatch   string
 	gwBridge      string
+	// gwBridgeMAC is the MAC address of the gateway bridge, used as source MAC
+	// of the external service traffic forwarded to other nodes
+	gwBridgeMAC string
 	// Map of service name to programmed iptables/OF rules
 	serviceInfo     map[ktypes.NamespacedName]*serviceConfig
 	serviceInfoLock sync.Mutex
@@ -130,7 +135,7 @@ func (npw *nodePortWatcher) updateGatewayIPs(addressManager *addressManager) {
 // `add` parameter indicates if the flows should exist or be removed from the cache
 // `hasLocalHostNetworkEp` indicates if at least one host networked endpoint exists for this service which is local to this node.
 func (npw *nodePortWatcher) updateServiceFlowCache(service *kapi.Service, add, hasLocalHostNetworkEp bool) error {
-	if config.Gateway.Mode == config.GatewayModeLocal && config.Gateway.AllowNoUplink && npw.ofportPhys == "" {
+	if config.GetGatewayMode() == config.GatewayModeLocal && config.Gateway.AllowNoUplink && npw.ofportPhys == "" {
 		// if LGW mode and no uplink gateway bridge, ingress traffic enters host from node physical interface instead of the breth0. Skip adding these service flows to br-ex.
 		return nil
 	}
@@ -141,6 +146,7 @@ func (npw *nodePortWatcher) updateServiceFlowCache(service *kapi.Service, add, h
 	var errors []error
 
 	isServiceTypeETPLocal := util.ServiceExternalTrafficPolicyLocal(service)
+	portRange := getServiceLBPortRange(service)
 
 	actions := fmt.Sprintf("output:%s", npw.ofportPatch)
 
@@ -200,7 +206,7 @@ func (npw *nodePortWatcher) updateServiceFlowCache(service *kapi.Service, add, h
 						fmt.Sprintf("cookie=%s, priority=110, table=7, "+
 							"actions=output:%s", etpSvcOpenFlowCookie, npw.ofportPhys))
 					npw.ofm.updateFlowCacheEntry(key, nodeportFlows)
-				} else if config.Gateway.Mode == config.GatewayModeShared {
+				} else if config.GetGatewayMode() == config.GatewayModeShared {
 					// case2 (see function description for details)
 					npw.ofm.updateFlowCacheEntry(key, []string{
 						// table=0, matches on service traffic towards nodePort and sends it to OVN pipeline
@@ -215,6 +221,26 @@ func (npw *nodePortWatcher) updateServiceFlowCache(service *kapi.Service, add, h
 			}
 		}
 
+		if portRange != nil {
+			// ingress and external IPs forward a port range instead of the service ports
+			if !portRange.AppliesTo(service, svcPort) {
+				continue
+			}
+			for _, ing := range service.Status.LoadBalancer.Ingress {
+				if len(ing.IP) > 0 {
+					if err = npw.createLbAndExternalSvcPortRangeFlows(service, portRange, add, protocol, actions, utilnet.ParseIPSloppy(ing.IP).String(), "Ingress"); err != nil {
+						errors = append(errors, err)
+					}
+				}
+			}
+			for _, externalIP := range service.Spec.ExternalIPs {
+				if err = npw.createLbAndExternalSvcPortRangeFlows(service, portRange, add, protocol, actions, utilnet.ParseIPSloppy(externalIP).String(), "External"); err != nil {
+					errors = append(errors, err)
+				}
+			}
+			continue
+		}
+
 		// Flows for cloud load balancers on Azure/GCP
 		// Established traffic is handled by default conntrack rules
 		// NodePort/Ingress access in the OVS bridge will only ever come from outside of the host
@@ -246,6 +272,8 @@ func (npw *nodePortWatcher) updateServiceFlowCache(service *kapi.Service, add, h
 //
 //	case2a: if externalTrafficPolicy=cluster + SGW mode, traffic will be steered into OVN via GR.
 //	case2b: if externalTrafficPolicy=local + !hasLocalHostNetworkEp + SGW mode, traffic will be steered into OVN via GR.
+//	case2c: if the service preserves the client source IP + SGW mode, and the node does not host any endpoint, traffic
+//	        will be forwarded at L2 to one of the nodes hosting endpoints. Those nodes handle it as in case1/case2b.
 //
 // NOTE: If LGW mode, the default flow will take care of sending traffic to host irrespective of service flow type.
 //
@@ -289,9 +317,10 @@ func (npw *nodePortWatcher) createLbAndExternalSvcFlows(service *kapi.Service, s
 	// And then ensure that return traffic is UnDNATed correctly back
 	// to the ingress / external IP
 	isServiceTypeETPLocal := util.ServiceExternalTrafficPolicyLocal(service)
-	if isServiceTypeETPLocal && hasLocalHostNetworkEp {
+	preserveSourceIP := util.ServicePreserveSourceIP(service)
+	if (isServiceTypeETPLocal || preserveSourceIP) && hasLocalHostNetworkEp {
 		// case1 (see function description for details)
-		klog.V(5).Infof("Adding flows on breth0 for %s Service %s in Namespace: %s since ExternalTrafficPolicy=local", ipType, service.Name, service.Namespace)
+		klog.V(5).Infof("Adding flows on breth0 for %s Service %s in Namespace: %s since ExternalTrafficPolicy=local or source IP is preserved", ipType, service.Name, service.Namespace)
 		// table 0, This rule matches on all traffic with dst ip == LoadbalancerIP / externalIP, DNAT's the nodePort to the svc targetPort
 		// If ipv6 make sure to choose the ipv6 node address for rule
 		if strings.Contains(flowProtocol, "6") {
@@ -315,10 +344,32 @@ func (npw *nodePortWatcher) createLbAndExternalSvcFlows(service *kapi.Service, s
 			// cookie is used since this would be same for all such services.
 			fmt.Sprintf("cookie=%s, priority=110, table=7, actions=output:%s",
 				etpSvcOpenFlowCookie, npw.ofportPhys))
-	} else if config.Gateway.Mode == config.GatewayModeShared {
+	} else if config.GetGatewayMode() == config.GatewayModeShared {
 		// add the ICMP Fragmentation flow for shared gateway mode.
 		icmpFlow := npw.generateICMPFragmentationFlow(nwDst, externalIPOrLBIngressIP, cookie)
 		externalIPFlows = append(externalIPFlows, icmpFlow)
+		var nextHops []string
+		if preserveSourceIP {
+			if nextHops, err = npw.getPreserveSourceIPNextHops(service); err != nil {
+				return err
+			}
+		}
+		if len(nextHops) > 0 {
+			// case2c (see function description for details)
+			klog.V(5).Infof("Adding flows on breth0 to forward %s Service %s in Namespace: %s traffic to %d nodes hosting endpoints",
+				ipType, service.Name, service.Namespace, len(nextHops))
+			// table=0, pick one of the nodes hosting endpoints, HRW keeps the existing
+			// flows on the same node as much as possible when nodes come and go.
+			actions = fmt.Sprintf("multipath(symmetric_l4,0,hrw,%d,0,NXM_NX_REG1[0..15]),resubmit(,12)", len(nextHops))
+			for i, mac := range nextHops {
+				// table=12, forwards the packet unmodified at L3 to the selected node, which
+				// replies directly to the client
+				externalIPFlows = append(externalIPFlows,
+					fmt.Sprintf("cookie=%s, priority=110, table=12, reg1=%d, %s, %s=%s, tp_dst=%d, "+
+						"actions=mod_dl_src:%s,mod_dl_dst:%s,output:in_port",
+						cookie, i, flowProtocol, nwDst, externalIPOrLBIngressIP, svcPort.Port, npw.gwBridgeMAC, mac))
+			}
+		}
 		// case2 (see function description for details)
 		externalIPFlows = append(externalIPFlows,
 			// table=0, matches on service traffic towards externalIP or LB ingress and sends it to OVN pipeline
@@ -335,6 +386,156 @@ func (npw *nodePortWatcher) createLbAndExternalSvcFlows(service *kapi.Service, s
 	return nil
 }
 
+// createLbAndExternalSvcPortRangeFlows handles managing breth0 gateway flows for ingress traffic towards the
+// externalIPs and LB ingress IPs of a service forwarding a port range, or all ports and protocols. Local traffic
+// policies do not apply to such services, so traffic is always steered into OVN via GR in SGW mode (case2 of
+// createLbAndExternalSvcFlows). OpenFlow does not support port ranges, they are matched with port/mask pairs.
+func (npw *nodePortWatcher) createLbAndExternalSvcPortRangeFlows(service *kapi.Service, portRange *util.LBPortRange, add bool, protocol string, actions string, externalIPOrLBIngressIP string, ipType string) error {
+	if net.ParseIP(externalIPOrLBIngressIP) == nil {
+		return fmt.Errorf("failed to parse %s IP: %q", ipType, externalIPOrLBIngressIP)
+	}
+	flowProtocol := protocol
+	ipProtocol := "ip"
+	nwDst := "nw_dst"
+	nwSrc := "nw_src"
+	if utilnet.IsIPv6String(externalIPOrLBIngressIP) {
+		flowProtocol = protocol + "6"
+		ipProtocol = "ipv6"
+		nwDst = "ipv6_dst"
+		nwSrc = "ipv6_src"
+	}
+	// the flows of each protocol of the range are distinct
+	cookie, err := svcToCookie(service.Namespace, service.Name, externalIPOrLBIngressIP+protocol, portRange.First)
+	if err != nil {
+		klog.Warningf("Unable to generate cookie for %s svc: %s, %s, %s, %s, error: %v",
+			ipType, service.Namespace, service.Name, externalIPOrLBIngressIP, portRange, err)
+		cookie = "0"
+	}
+	key := strings.Join([]string{ipType, service.Namespace, service.Name, externalIPOrLBIngressIP, protocol, portRange.String()}, "_")
+	if !add {
+		npw.ofm.deleteFlowsByKey(key)
+		return nil
+	}
+	externalIPFlows := []string{npw.generateArpBypassFlow(protocol, externalIPOrLBIngressIP, cookie)}
+	if config.GetGatewayMode() == config.GatewayModeShared {
+		if portRange.AllPorts() {
+			externalIPFlows = append(externalIPFlows,
+				// table=0, matches on any traffic towards externalIP or LB ingress and sends it to OVN pipeline
+				fmt.Sprintf("cookie=%s, priority=110, in_port=%s, %s, %s=%s, actions=%s",
+					cookie, npw.ofportPhys, ipProtocol, nwDst, externalIPOrLBIngressIP, actions),
+				// table=0, matches on return traffic from service externalIP or LB ingress and sends it out to primary node interface (br-ex)
+				fmt.Sprintf("cookie=%s, priority=110, in_port=%s, %s, %s=%s, actions=output:%s",
+					cookie, npw.ofportPatch, ipProtocol, nwSrc, externalIPOrLBIngressIP, npw.ofportPhys))
+		} else {
+			externalIPFlows = append(externalIPFlows, npw.generateICMPFragmentationFlow(nwDst, externalIPOrLBIngressIP, cookie))
+			for _, port := range portRangeMatches(portRange.First, portRange.Last) {
+				externalIPFlows = append(externalIPFlows,
+					// table=0, matches on service traffic towards externalIP or LB ingress and sends it to OVN pipeline
+					fmt.Sprintf("cookie=%s, priority=110, in_port=%s, %s, %s=%s, tp_dst=%s, actions=%s",
+						cookie, npw.ofportPhys, flowProtocol, nwDst, externalIPOrLBIngressIP, port, actions),
+					// table=0, matches on return traffic from service externalIP or LB ingress and sends it out to primary node interface (br-ex)
+					fmt.Sprintf("cookie=%s, priority=110, in_port=%s, %s, %s=%s, tp_src=%s, actions=output:%s",
+						cookie, npw.ofportPatch, flowProtocol, nwSrc, externalIPOrLBIngressIP, port, npw.ofportPhys))
+			}
+		}
+	}
+	npw.ofm.updateFlowCacheEntry(key, externalIPFlows)
+
+	return nil
+}
+
+// portRangeMatches splits the [first, last] port range into the minimal list of
+// OpenFlow port or port/mask matches covering it.
+func portRangeMatches(first, last int32) []string {
+	var matches []string
+	for first <= last {
+		// grow the block of ports starting at first as long as it stays
+		// aligned on its size and within the range
+		size := int32(1)
+		for first%(size*2) == 0 && first+size*2-1 <= last {
+			size *= 2
+		}
+		if size == 1 {
+			matches = append(matches, fmt.Sprintf("%d", first))
+		} else {
+			matches = append(matches, fmt.Sprintf("0x%x/0x%x", first, 0xffff&^(size-1)))
+		}
+		first += size
+	}
+	return matches
+}
+
+// getServiceLBPortRange returns the range of ports forwarded by the service
+// external IPs, or nil if only the service ports are.
+func getServiceLBPortRange(service *kapi.Service) *util.LBPortRange {
+	portRange, err := util.ParseLBPortRangeAnnotation(service)
+	if err != nil {
+		klog.Errorf("Ignoring port range of service %s/%s: %v", service.Namespace, service.Name, err)
+		return nil
+	}
+	return portRange
+}
+
+// getPreserveSourceIPNextHops returns the sorted gateway bridge MAC addresses of
+// the nodes hosting eligible endpoints of a service preserving the client source
+// IP, or nil if this node hosts endpoints itself and thus handles the traffic.
+// Nodes whose gateway is not on the gateway subnet of this node are left out.
+func (npw *nodePortWatcher) getPreserveSourceIPNextHops(service *kapi.Service) ([]string, error) {
+	epSlices, err := npw.watchFactory.GetEndpointSlices(service.Namespace, service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving endpointslices for service %s/%s: %w", service.Namespace, service.Name, err)
+	}
+	nodeNames := sets.New[string]()
+	for _, epSlice := range epSlices {
+		util.ForEachEligibleEndpoint(epSlice, service, func(endpoint discovery.Endpoint, shortcut *bool) {
+			if endpoint.NodeName != nil {
+				nodeNames.Insert(*endpoint.NodeName)
+			}
+		})
+	}
+	if nodeNames.Has(npw.nodeIPManager.nodeName) {
+		return nil, nil
+	}
+	// the traffic is sent back out of the physical interface with the MAC address of
+	// the selected node as destination, which only reaches nodes on the same L2 segment
+	npw.ofm.defaultBridge.Lock()
+	localGWIPs := npw.ofm.defaultBridge.ips
+	npw.ofm.defaultBridge.Unlock()
+	macs := sets.New[string]()
+	for _, nodeName := range sets.List(nodeNames) {
+		node, err := npw.watchFactory.GetNode(nodeName)
+		if err != nil {
+			klog.Warningf("Unable to get node %s hosting endpoints of service %s/%s: %v", nodeName, service.Namespace, service.Name, err)
+			continue
+		}
+		gwConf, err := util.ParseNodeL3GatewayAnnotation(node)
+		if err != nil || gwConf.MACAddress == nil {
+			klog.Warningf("Unable to get the gateway MAC address of node %s hosting endpoints of service %s/%s: %v",
+				nodeName, service.Namespace, service.Name, err)
+			continue
+		}
+		if !onGatewaySubnet(localGWIPs, gwConf.IPAddresses) {
+			klog.Warningf("Ignoring node %s hosting endpoints of service %s/%s: its gateway is not on the gateway subnet of node %s",
+				nodeName, service.Namespace, service.Name, npw.nodeIPManager.nodeName)
+			continue
+		}
+		macs.Insert(gwConf.MACAddress.String())
+	}
+	return sets.List(macs), nil
+}
+
+// onGatewaySubnet returns true if one of the gateway IP addresses of a node is on one of the local gateway subnets
+func onGatewaySubnet(localGWIPs, gwIPs []*net.IPNet) bool {
+	for _, gwIP := range gwIPs {
+		for _, localGWIP := range localGWIPs {
+			if localGWIP.Contains(gwIP.IP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // generate ARP/NS bypass flow which will send the ARP/NS request everywhere *but* to OVN
 // OpenFlow will not do hairpin switching, so we can safely add the origin port to the list of ports, too
 func (npw *nodePortWatcher) generateArpBypassFlow(protocol string, ipAddr string, cookie string) string {
@@ -558,6 +759,8 @@ func serviceUpdateNotNeeded(old, new *kapi.Service) bool {
 		reflect.DeepEqual(new.Spec.Type, old.Spec.Type) &&
 		reflect.DeepEqual(new.Status.LoadBalancer.Ingress, old.Status.LoadBalancer.Ingress) &&
 		reflect.DeepEqual(new.Spec.ExternalTrafficPolicy, old.Spec.ExternalTrafficPolicy) &&
+		util.ServicePreserveSourceIP(new) == util.ServicePreserveSourceIP(old) &&
+		new.Annotations[util.LBPortRangeAnnotation] == old.Annotations[util.LBPortRangeAnnotation] &&
 		(new.Spec.InternalTrafficPolicy != nil && old.Spec.InternalTrafficPolicy != nil &&
 			reflect.DeepEqual(*new.Spec.InternalTrafficPolicy, *old.Spec.InternalTrafficPolicy)) &&
 		(new.Spec.AllocateLoadBalancerNodePorts != nil && old.Spec.AllocateLoadBalancerNodePorts != nil &&
@@ -643,48 +846,6 @@ func (npw *nodePortWatcher) UpdateService(old, new *kapi.Service) error {
 
 }
 
-// deleteConntrackForServiceVIP deletes the conntrack entries for the provided svcVIP:svcPort by comparing them to ConntrackOrigDstIP:ConntrackOrigDstPort
-func deleteConntrackForServiceVIP(svcVIPs []string, svcPorts []kapi.ServicePort, ns, name string) error {
-	for _, svcVIP := range svcVIPs {
-		for _, svcPort := range svcPorts {
-			if err := util.DeleteConntrackServicePort(svcVIP, svcPort.Port, svcPort.Protocol,
-				netlink.ConntrackOrigDstIP, nil); err != nil {
-				return fmt.Errorf("failed to delete conntrack entry for service %s/%s with svcVIP %s, svcPort %d, protocol %s: %v",
-					ns, name, svcVIP, svcPort.Port, svcPort.Protocol, err)
-			}
-		}
-	}
-	return nil
-}
-
-// deleteConntrackForService deletes the conntrack entries corresponding to the service VIPs of the provided service
-func (npw *nodePortWatcher) deleteConntrackForService(service *kapi.Service) error {
-	// remove conntrack entries for LB VIPs and External IPs
-	externalIPs := util.GetExternalAndLBIPs(service)
-	if err := deleteConntrackForServiceVIP(externalIPs, service.Spec.Ports, service.Namespace, service.Name); err != nil {
-		return err
-	}
-	if util.ServiceTypeHasNodePort(service) {
-		// remove conntrack entries for NodePorts
-		nodeIPs := npw.nodeIPManager.ListAddresses()
-		for _, nodeIP := range nodeIPs {
-			for _, svcPort := range service.Spec.Ports {
-				if err := util.DeleteConntrackServicePort(nodeIP.String(), svcPort.NodePort, svcPort.Protocol,
-					netlink.ConntrackOrigDstIP, nil); err != nil {
-					return fmt.Errorf("failed to delete conntrack entry for service %s/%s with nodeIP %s, nodePort %d, protocol %s: %v",
-						service.Namespace, service.Name, nodeIP, svcPort.Port, svcPort.Protocol, err)
-				}
-			}
-		}
-	}
-	// remove conntrack entries for ClusterIPs
-	clusterIPs := util.GetClusterIPs(service)
-	if err := deleteConntrackForServiceVIP(clusterIPs, service.Spec.Ports, service.Namespace, service.Name); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (npw *nodePortWatcher) DeleteService(service *kapi.Service) error {
 	var err error
 	var errors []error
@@ -701,13 +862,6 @@ func (npw *nodePortWatcher) DeleteService(service *kapi.Service) error {
 	} else {
 		klog.Warningf("Delete service: no service found in cache for endpoint %s in namespace %s", service.Name, service.Namespace)
 	}
-	// Remove all conntrack entries for the serviceVIPs of this service irrespective of protocol stack
-	// since service deletion is considered as unplugging the network cable and hence graceful termination
-	// is not guaranteed. See https://github.com/kubernetes/kubernetes/issues/108523#issuecomment-1074044415.
-	if err = npw.deleteConntrackForService(service); err != nil {
-		errors = append(errors, fmt.Errorf("failed to delete conntrack entry for service %v: %v", name, err))
-	}
-
 	if err = apierrors.NewAggregate(errors); err != nil {
 		return fmt.Errorf("DeleteService failed for nodePortWatcher: %v", err)
 	}
@@ -834,8 +988,67 @@ func (npw *nodePortWatcher) AddEndpointSlice(epSlice *discovery.EndpointSlice) e
 		}
 		return apierrors.NewAggregate(errors)
 	}
+	if util.ServicePreserveSourceIP(svc) {
+		// flows of services preserving the client source IP depend on the endpoints of other nodes too
+		return npw.refreshServiceFlows(svc, hasLocalHostNetworkEp)
+	}
+	return nil
+
+}
+
+// refreshServiceFlows reprograms the breth0 flows of a service without touching its iptables rules
+func (npw *nodePortWatcher) refreshServiceFlows(service *kapi.Service, hasLocalHostNetworkEp bool) error {
+	if err := npw.updateServiceFlowCache(service, true, hasLocalHostNetworkEp); err != nil {
+		return err
+	}
+	npw.ofm.requestFlowSync()
 	return nil
+}
+
+// watchNodeGatewayMACs refreshes the breth0 flows of the services preserving the
+// client source IP when the gateway of another node changes, since those flows
+// forward the traffic to the nodes hosting endpoints on the gateway subnet by MAC address.
+func (npw *nodePortWatcher) watchNodeGatewayMACs() error {
+	_, err := npw.watchFactory.AddFilteredNodeHandler(labels.Everything(), cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			oldNode, newNode := old.(*kapi.Node), new.(*kapi.Node)
+			if !util.NodeL3GatewayAnnotationChanged(oldNode, newNode) ||
+				nodeGatewayAddresses(oldNode) == nodeGatewayAddresses(newNode) {
+				return
+			}
+			klog.V(5).Infof("Gateway of node %s changed, refreshing the flows of services preserving the source IP", newNode.Name)
+			npw.refreshPreserveSourceIPServices()
+		},
+	}, nil)
+	return err
+}
 
+// nodeGatewayAddresses returns the gateway MAC and IP addresses of a node, or an empty string if unknown
+func nodeGatewayAddresses(node *kapi.Node) string {
+	gwConf, err := util.ParseNodeL3GatewayAnnotation(node)
+	if err != nil || gwConf.MACAddress == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %v", gwConf.MACAddress, gwConf.IPAddresses)
+}
+
+// refreshPreserveSourceIPServices reprograms the breth0 flows of all the services preserving the client source IP
+func (npw *nodePortWatcher) refreshPreserveSourceIPServices() {
+	npw.serviceInfoLock.Lock()
+	defer npw.serviceInfoLock.Unlock()
+	refreshed := false
+	for _, svcConfig := range npw.serviceInfo {
+		if !util.ServicePreserveSourceIP(svcConfig.service) {
+			continue
+		}
+		if err := npw.updateServiceFlowCache(svcConfig.service, true, svcConfig.hasLocalHostNetworkEp); err != nil {
+			klog.Errorf("Failed to refresh the flows of service %s/%s: %v", svcConfig.service.Namespace, svcConfig.service.Name, err)
+		}
+		refreshed = true
+	}
+	if refreshed {
+		npw.ofm.requestFlowSync()
+	}
 }
 
 func (npw *nodePortWatcher) DeleteEndpointSlice(epSlice *discovery.EndpointSlice) error {
@@ -966,6 +1179,13 @@ func (npw *nodePortWatcher) UpdateEndpointSlice(oldEpSlice, newEpSlice *discover
 		return apierrors.NewAggregate(errors)
 	}
 
+	if serviceInfo != nil && svc != nil && util.ServicePreserveSourceIP(svc) {
+		// flows of services preserving the client source IP depend on the endpoints of other nodes too
+		if err = npw.refreshServiceFlows(svc, hasLocalHostNetworkEpNew); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
 	return apierrors.NewAggregate(errors)
 }
 
@@ -1056,7 +1276,8 @@ func (npwipt *nodePortWatcherIptables) SyncServices(services []interface{}) erro
 //
 // -- to handle host -> service access, via masquerading from the host to OVN GR
 // -- to handle external -> service(ExternalTrafficPolicy: Local) -> host access without SNAT
-func newGatewayOpenFlowManager(gwBridge, exGWBridge *bridgeConfiguration, subnets []*net.IPNet, extraIPs []net.IP) (*openflowManager, error) {
+func newGatewayOpenFlowManager(gwBridge, exGWBridge *bridgeConfiguration, uplinkBridges []*bridgeConfiguration,
+	subnets []*net.IPNet, extraIPs []net.IP) (*openflowManager, error) {
 	// add health check function to check default OpenFlow flows are on the shared gateway bridge
 	ofm := &openflowManager{
 		defaultBridge:         gwBridge,
@@ -1067,6 +1288,12 @@ func newGatewayOpenFlowManager(gwBridge, exGWBridge *bridgeConfiguration, subnet
 		exGWFlowMutex:         sync.Mutex{},
 		flowChan:              make(chan struct{}, 1),
 	}
+	for _, uplinkBridge := range uplinkBridges {
+		ofm.uplinkBridges = append(ofm.uplinkBridges, &uplinkBridgeFlows{
+			bridge:    uplinkBridge,
+			flowCache: make(map[string][]string),
+		})
+	}
 
 	if err := ofm.updateBridgeFlowCache(subnets, extraIPs); err != nil {
 		return nil, err
@@ -1105,6 +1332,19 @@ func (ofm *openflowManager) updateBridgeFlowCache(subnets []*net.IPNet, extraIPs
 		}
 		ofm.updateExBridgeFlowCacheEntry("DEFAULT", exGWBridgeDftFlows)
 	}
+
+	// the additional uplink bridges only carry the traffic of the gateway
+	// router like the ex gw bridge
+	ofm.uplinkFlowMutex.Lock()
+	defer ofm.uplinkFlowMutex.Unlock()
+	for _, uplink := range ofm.uplinkBridges {
+		uplinkDftFlows, err := commonFlows(subnets, uplink.bridge)
+		if err != nil {
+			return err
+		}
+		uplink.flowCache["NORMAL"] = []string{fmt.Sprintf("table=0,priority=0,actions=%s\n", util.NormalAction)}
+		uplink.flowCache["DEFAULT"] = uplinkDftFlows
+	}
 	return nil
 }
 
@@ -1270,7 +1510,7 @@ func flowsForDefaultBridge(bridge *bridgeConfiguration, extraIPs []net.IP) ([]st
 	}
 
 	var actions string
-	if config.Gateway.Mode != config.GatewayModeLocal || config.Gateway.DisablePacketMTUCheck {
+	if config.GetGatewayMode() != config.GatewayModeLocal || config.Gateway.DisablePacketMTUCheck {
 		actions = fmt.Sprintf("output:%s", ofPortPatch)
 	} else {
 		// packets larger than known acceptable MTU need to go to kernel for
@@ -1373,6 +1613,34 @@ func flowsForDefaultBridge(bridge *bridgeConfiguration, extraIPs []net.IP) ([]st
 				"actions=ct(commit,zone=%d,nat,table=2)",
 				defaultOpenFlowCookie, HostMasqCTZone))
 	}
+
+	if util.IsSecondaryNetworkGatewaySupportEnabled() {
+		var secondaryMasqSubnets []string
+		if config.IPv4Mode {
+			secondaryMasqSubnets = append(secondaryMasqSubnets, config.Gateway.V4SecondaryMasqueradeSubnet)
+		}
+		if config.IPv6Mode {
+			secondaryMasqSubnets = append(secondaryMasqSubnets, config.Gateway.V6SecondaryMasqueradeSubnet)
+		}
+		for _, secondaryMasqSubnet := range secondaryMasqSubnets {
+			protoPrefix = "ip"
+			if utilnet.IsIPv6CIDRString(secondaryMasqSubnet) {
+				protoPrefix = "ipv6"
+			}
+			// table 0, traffic from the gateway routers of the secondary
+			// networks goes to the host, which forwards it to its destination
+			dftFlows = append(dftFlows,
+				fmt.Sprintf("cookie=%s, priority=550, in_port=%s, %s, %s_src=%s, "+
+					"actions=output:%s",
+					defaultOpenFlowCookie, ofPortPatch, protoPrefix, protoPrefix, secondaryMasqSubnet, ofPortHost))
+			// table 0, traffic from the host to the gateway routers of the
+			// secondary networks goes to OVN
+			dftFlows = append(dftFlows,
+				fmt.Sprintf("cookie=%s, priority=550, in_port=%s, %s, %s_dst=%s, "+
+					"actions=output:%s",
+					defaultOpenFlowCookie, ofPortHost, protoPrefix, protoPrefix, secondaryMasqSubnet, ofPortPatch))
+		}
+	}
 	return dftFlows, nil
 }
 
@@ -1422,7 +1690,7 @@ func commonFlows(subnets []*net.IPNet, bridge *bridgeConfiguration) ([]string, e
 					"actions=ct(commit, zone=%d, exec(set_field:%s->ct_mark)), output:%s",
 					defaultOpenFlowCookie, ofPortHost, config.Default.ConntrackZone, ctMarkHost, ofPortPhys))
 		}
-		if config.Gateway.Mode == config.GatewayModeLocal {
+		if config.GetGatewayMode() == config.GatewayModeLocal {
 			// table 0, any packet coming from OVN send to host in LGW mode, host will take care of sending it outside if needed.
 			// exceptions are traffic for egressIP and egressGW features and ICMP related traffic which will hit the priority 100 flow instead of this.
 			dftFlows = append(dftFlows,
@@ -1483,7 +1751,7 @@ func commonFlows(subnets []*net.IPNet, bridge *bridgeConfiguration) ([]string, e
 					"actions=ct(commit, zone=%d, exec(set_field:%s->ct_mark)), output:%s",
 					defaultOpenFlowCookie, ofPortHost, config.Default.ConntrackZone, ctMarkHost, ofPortPhys))
 		}
-		if config.Gateway.Mode == config.GatewayModeLocal {
+		if config.GetGatewayMode() == config.GatewayModeLocal {
 			// table 0, any packet coming from OVN send to host in LGW mode, host will take care of sending it outside if needed.
 			// exceptions are traffic for egressIP and egressGW features and ICMP related traffic which will hit the priority 100 flow instead of this.
 			dftFlows = append(dftFlows,
@@ -1599,7 +1867,7 @@ func commonFlows(subnets []*net.IPNet, bridge *bridgeConfiguration) ([]string, e
 		// potential fragmentation
 		// introduced specifically for replies to egress traffic not routed
 		// through the host
-		if config.Gateway.Mode == config.GatewayModeLocal && !config.Gateway.DisablePacketMTUCheck {
+		if config.GetGatewayMode() == config.GatewayModeLocal && !config.Gateway.DisablePacketMTUCheck {
 			dftFlows = append(dftFlows,
 				fmt.Sprintf("cookie=%s, priority=10, table=11, reg0=0x1, "+
 					"actions=output:%s", defaultOpenFlowCookie, ofPortHost))
@@ -1713,32 +1981,22 @@ func initSvcViaMgmPortRoutingRules(hostSubnets []*net.IPNet) error {
 
 func newSharedGateway(nodeName string, subnets []*net.IPNet, gwNextHops []net.IP, gwIntf, egressGWIntf string,
 	gwIPs []*net.IPNet, nodeAnnotator kube.Annotator, kube kube.Interface, cfg *managementPortConfig,
-	watchFactory factory.NodeWatchFactory, routeManager *routemanager.Controller) (*gateway, error) {
+	watchFactory factory.NodeWatchFactory, routeManager *routemanager.Controller, ovsClient libovsdbclient.Client) (*gateway, error) {
 	klog.Info("Creating new shared gateway")
 	gw := &gateway{}
 
-	gwBridge, exGwBridge, err := gatewayInitInternal(
+	gwBridge, exGwBridge, uplinkBridges, err := gatewayInitInternal(ovsClient,
 		nodeName, gwIntf, egressGWIntf, gwNextHops, gwIPs, nodeAnnotator)
 	if err != nil {
 		return nil, err
 	}
 
+	bridges := append([]*bridgeConfiguration{gwBridge}, uplinkBridges...)
 	if exGwBridge != nil {
-		gw.readyFunc = func() (bool, error) {
-			ready, err := gatewayReady(gwBridge.patchPort)
-			if err != nil {
-				return false, err
-			}
-			exGWReady, err := gatewayReady(exGwBridge.patchPort)
-			if err != nil {
-				return false, err
-			}
-			return ready && exGWReady, nil
-		}
-	} else {
-		gw.readyFunc = func() (bool, error) {
-			return gatewayReady(gwBridge.patchPort)
-		}
+		bridges = append(bridges, exGwBridge)
+	}
+	gw.readyFunc = func() (bool, error) {
+		return gatewayBridgesReady(bridges...)
 	}
 
 	gw.initFunc = func() error {
@@ -1750,17 +2008,16 @@ func newSharedGateway(nodeName string, subnets []*net.IPNet, gwNextHops []net.IP
 			return err
 		}
 		if exGwBridge != nil {
-			err = setBridgeOfPorts(exGwBridge)
-			if err != nil {
+			if err = initExternalBridge(exGwBridge); err != nil {
 				return err
 			}
-			if config.Gateway.DisableForwarding {
-				if err := initExternalBridgeDropForwardingRules(exGwBridge.bridgeName); err != nil {
-					return fmt.Errorf("failed to add forwarding block rules for bridge %s: err %v", exGwBridge.bridgeName, err)
-				}
+		}
+		for _, uplinkBridge := range uplinkBridges {
+			if err = initExternalBridge(uplinkBridge); err != nil {
+				return err
 			}
 		}
-		gw.nodeIPManager = newAddressManager(nodeName, kube, cfg, watchFactory, gwBridge)
+		gw.nodeIPManager = newAddressManager(nodeName, kube, cfg, watchFactory, gwBridge, gwNextHops, ovsClient)
 		nodeIPs := gw.nodeIPManager.ListAddresses()
 
 		if config.OvnKubeNode.Mode == types.NodeModeFull {
@@ -1771,9 +2028,15 @@ func newSharedGateway(nodeName string, subnets []*net.IPNet, gwNextHops []net.IP
 			if err := addMasqueradeRoute(routeManager, gwBridge.bridgeName, nodeName, gwIPs, watchFactory); err != nil {
 				return fmt.Errorf("failed to set the node masquerade route to OVN: %v", err)
 			}
+
+			if util.IsSecondaryNetworkGatewaySupportEnabled() {
+				if err := initSecondaryNetworkGatewayRules(); err != nil {
+					return fmt.Errorf("failed to set the secondary network gateway rules: %v", err)
+				}
+			}
 		}
 
-		gw.openflowManager, err = newGatewayOpenFlowManager(gwBridge, exGwBridge, subnets, nodeIPs)
+		gw.openflowManager, err = newGatewayOpenFlowManager(gwBridge, exGwBridge, uplinkBridges, subnets, nodeIPs)
 		if err != nil {
 			return err
 		}
@@ -1811,6 +2074,12 @@ func newSharedGateway(nodeName string, subnets []*net.IPNet, gwNextHops []net.IP
 			return fmt.Errorf("failed to add MAC bindings for service routing")
 		}
 
+		if config.OvnKubeNode.Mode == types.NodeModeFull {
+			gw.modeController = newGatewayModeController(nodeName, kube, watchFactory, func() error {
+				return gw.reprogramGatewayMode(subnets, cfg, routeManager, uplinkBridges)
+			})
+		}
+
 		return nil
 	}
 	gw.watchFactory = watchFactory.(*factory.WatchFactory)
@@ -1843,11 +2112,11 @@ func newNodePortWatcher(gwBridge *bridgeConfiguration, ofm *openflowManager,
 	// NodePortIP:NodePort to ClusterServiceIP:Port. We don't need to do this while
 	// running on DPU or on DPU-Host.
 	if config.OvnKubeNode.Mode == types.NodeModeFull {
-		if config.Gateway.Mode == config.GatewayModeLocal {
+		if config.GetGatewayMode() == config.GatewayModeLocal {
 			if err := initLocalGatewayIPTables(); err != nil {
 				return nil, err
 			}
-		} else if config.Gateway.Mode == config.GatewayModeShared {
+		} else if config.GetGatewayMode() == config.GatewayModeShared {
 			if err := initSharedGatewayIPTables(); err != nil {
 				return nil, err
 			}
@@ -1881,6 +2150,7 @@ func newNodePortWatcher(gwBridge *bridgeConfiguration, ofm *openflowManager,
 		ofportPhys:    ofportPhys,
 		ofportPatch:   ofportPatch,
 		gwBridge:      gwBridge.bridgeName,
+		gwBridgeMAC:   gwBridge.macAddress.String(),
 		serviceInfo:   make(map[ktypes.NamespacedName]*serviceConfig),
 		nodeIPManager: nodeIPManager,
 		ofm:           ofm,
@@ -2054,6 +2324,22 @@ func setNodeMasqueradeIPOnExtBridge(extBridgeName string) error {
 		bridgeCIDRs = append(bridgeCIDRs, cidrAndFlags{ipNet: masqIPNet, flags: unix.IFA_F_NODAD})
 	}
 
+	if config.OVNKubernetesFeature.EnableMultiNetwork {
+		// the gateway routers of the secondary networks reach the host
+		// through this IP
+		secondaryMasqIPNets, err := util.GetSecondaryNetworkHostMasqueradeIPs()
+		if err != nil {
+			return err
+		}
+		for _, secondaryMasqIPNet := range secondaryMasqIPNets {
+			var flags int
+			if utilnet.IsIPv6CIDR(secondaryMasqIPNet) {
+				flags = unix.IFA_F_NODAD
+			}
+			bridgeCIDRs = append(bridgeCIDRs, cidrAndFlags{ipNet: secondaryMasqIPNet, flags: flags})
+		}
+	}
+
 	for _, bridgeCIDR := range bridgeCIDRs {
 		if exists, err := util.LinkAddrExist(extBridge, bridgeCIDR.ipNet); err == nil && !exists {
 			if err := util.LinkAddrAdd(extBridge, bridgeCIDR.ipNet, bridgeCIDR.flags); err != nil {
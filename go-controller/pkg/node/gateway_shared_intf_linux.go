@@ -7,16 +7,19 @@ import (
 	"fmt"
 	"strings"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	nodeipt "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/iptables"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
 	kapi "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
 // deletes the local bridge used for DGP and removes the corresponding iface, as well as OVS bridge mappings
-func deleteLocalNodeAccessBridge() error {
+func deleteLocalNodeAccessBridge(ovsClient libovsdbclient.Client) error {
 	// remove br-local bridge
 	_, stderr, err := util.RunOVSVsctl("--if-exists", "del-br", types.LocalBridgeName)
 	if err != nil {
@@ -25,16 +28,15 @@ func deleteLocalNodeAccessBridge() error {
 	}
 	// ovn-bridge-mappings maps a physical network name to a local ovs bridge
 	// that provides connectivity to that network. It is in the form of physnet1:br1,physnet2:br2.
-	stdout, stderr, err := util.RunOVSVsctl("--if-exists", "get", "Open_vSwitch", ".",
-		"external_ids:ovn-bridge-mappings")
+	openvSwitch, err := libovsdbops.GetOpenvSwitch(ovsClient)
 	if err != nil {
-		return fmt.Errorf("failed to get ovn-bridge-mappings stderr:%s (%v)", stderr, err)
+		return fmt.Errorf("failed to get ovn-bridge-mappings: %v", err)
 	}
-	if len(stdout) > 0 {
+	if mappings := openvSwitch.ExternalIDs["ovn-bridge-mappings"]; len(mappings) > 0 {
 		locnetMapping := fmt.Sprintf("%s:%s", types.LocalNetworkName, types.LocalBridgeName)
-		if strings.Contains(stdout, locnetMapping) {
+		if strings.Contains(mappings, locnetMapping) {
 			var newMappings string
-			bridgeMappings := strings.Split(stdout, ",")
+			bridgeMappings := strings.Split(mappings, ",")
 			for _, bridgeMapping := range bridgeMappings {
 				if bridgeMapping != locnetMapping {
 					if len(newMappings) != 0 {
@@ -43,10 +45,11 @@ func deleteLocalNodeAccessBridge() error {
 					newMappings += bridgeMapping
 				}
 			}
-			_, stderr, err = util.RunOVSVsctl("set", "Open_vSwitch", ".",
-				fmt.Sprintf("external_ids:ovn-bridge-mappings=%s", newMappings))
+			err = libovsdbops.UpdateOpenvSwitchSetExternalIDsAndOtherConfig(ovsClient, &vswitchd.OpenvSwitch{
+				ExternalIDs: map[string]string{"ovn-bridge-mappings": newMappings},
+			})
 			if err != nil {
-				return fmt.Errorf("failed to set ovn-bridge-mappings, stderr:%s, error: (%v)", stderr, err)
+				return fmt.Errorf("failed to set ovn-bridge-mappings: %v", err)
 			}
 		}
 	}
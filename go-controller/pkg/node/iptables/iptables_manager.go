@@ -8,10 +8,14 @@ import (
 	"sync"
 	"time"
 
+	goiptables "github.com/coreos/go-iptables/iptables"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/util/iptables"
 	kexec "k8s.io/utils/exec"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 )
 
 // Chain allows users to manage rules for a particular iptables table and chain for ipv4 and ipv6 protocols
@@ -35,19 +39,142 @@ type Controller struct {
 	mu         *sync.Mutex
 	chainRules map[Chain][]RuleArg
 	chains     []Chain
-	iptV4      iptables.Interface
-	iptV6      iptables.Interface
+	iptV4      ruleInterface
+	iptV6      ruleInterface
+}
+
+// ruleInterface is the subset of iptables.Interface used by the Controller
+type ruleInterface interface {
+	EnsureChain(table iptables.Table, chain iptables.Chain) (bool, error)
+	ChainExists(table iptables.Table, chain iptables.Chain) (bool, error)
+	DeleteChain(table iptables.Table, chain iptables.Chain) error
+	EnsureRule(position iptables.RulePosition, table iptables.Table, chain iptables.Chain, args ...string) (bool, error)
+	DeleteRule(table iptables.Table, chain iptables.Chain, args ...string) error
+	SaveInto(table iptables.Table, buffer *bytes.Buffer) error
+	Protocol() iptables.Protocol
 }
 
 // NewController creates a controller to manage chains and rules
 func NewController() *Controller {
-	return &Controller{
+	c := &Controller{
 		chainRules: make(map[Chain][]RuleArg, 0),
 		chains:     make([]Chain, 0),
 		mu:         &sync.Mutex{},
 		iptV4:      iptables.New(kexec.New(), iptables.ProtocolIPv4),
 		iptV6:      iptables.New(kexec.New(), iptables.ProtocolIPv6),
 	}
+	if config.OvnKubeNode.FirewallBackend == config.FirewallBackendNFTables {
+		c.iptV4 = &helperRuleInterface{proto: iptables.ProtocolIPv4}
+		c.iptV6 = &helperRuleInterface{proto: iptables.ProtocolIPv6}
+	}
+	return c
+}
+
+// helperRuleInterface implements ruleInterface on top of the util.IPTablesHelper,
+// so that rules are programmed by the configured firewall backend
+type helperRuleInterface struct {
+	proto iptables.Protocol
+}
+
+func (h *helperRuleInterface) helper() (util.IPTablesHelper, error) {
+	if h.proto == iptables.ProtocolIPv6 {
+		return util.GetIPTablesHelper(goiptables.ProtocolIPv6)
+	}
+	return util.GetIPTablesHelper(goiptables.ProtocolIPv4)
+}
+
+func (h *helperRuleInterface) EnsureChain(table iptables.Table, chain iptables.Chain) (bool, error) {
+	exists, err := h.ChainExists(table, chain)
+	if err != nil || exists {
+		return exists, err
+	}
+	ipt, err := h.helper()
+	if err != nil {
+		return false, err
+	}
+	return false, ipt.NewChain(string(table), string(chain))
+}
+
+func (h *helperRuleInterface) ChainExists(table iptables.Table, chain iptables.Chain) (bool, error) {
+	ipt, err := h.helper()
+	if err != nil {
+		return false, err
+	}
+	chains, err := ipt.ListChains(string(table))
+	if err != nil {
+		return false, err
+	}
+	for _, c := range chains {
+		if c == string(chain) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (h *helperRuleInterface) DeleteChain(table iptables.Table, chain iptables.Chain) error {
+	ipt, err := h.helper()
+	if err != nil {
+		return err
+	}
+	return ipt.DeleteChain(string(table), string(chain))
+}
+
+func (h *helperRuleInterface) EnsureRule(position iptables.RulePosition, table iptables.Table, chain iptables.Chain, args ...string) (bool, error) {
+	ipt, err := h.helper()
+	if err != nil {
+		return false, err
+	}
+	exists, err := ipt.Exists(string(table), string(chain), args...)
+	if err != nil || exists {
+		return exists, err
+	}
+	if position == iptables.Prepend {
+		return false, ipt.Insert(string(table), string(chain), 1, args...)
+	}
+	return false, ipt.Append(string(table), string(chain), args...)
+}
+
+func (h *helperRuleInterface) DeleteRule(table iptables.Table, chain iptables.Chain, args ...string) error {
+	ipt, err := h.helper()
+	if err != nil {
+		return err
+	}
+	exists, err := ipt.Exists(string(table), string(chain), args...)
+	if err != nil || !exists {
+		return err
+	}
+	return ipt.Delete(string(table), string(chain), args...)
+}
+
+// SaveInto writes the rules of the table in the iptables-save format
+func (h *helperRuleInterface) SaveInto(table iptables.Table, buffer *bytes.Buffer) error {
+	ipt, err := h.helper()
+	if err != nil {
+		return err
+	}
+	chains, err := ipt.ListChains(string(table))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buffer, "*%s\n", table)
+	for _, chain := range chains {
+		rules, err := ipt.List(string(table), chain)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if strings.HasPrefix(rule, "-A ") {
+				fmt.Fprintln(buffer, rule)
+			}
+		}
+	}
+	fmt.Fprintln(buffer, "COMMIT")
+	return nil
+}
+
+func (h *helperRuleInterface) Protocol() iptables.Protocol {
+	return h.proto
 }
 
 func (c *Controller) Run(stopCh <-chan struct{}, syncPeriod time.Duration) {
@@ -189,7 +316,7 @@ func (c *Controller) GetIPv6ChainRuleArgs(table iptables.Table, chain iptables.C
 	return getChainRuleArgs(c.iptV6, table, chain)
 }
 
-func getChainRuleArgs(ipt iptables.Interface, table iptables.Table, chain iptables.Chain) ([]RuleArg, error) {
+func getChainRuleArgs(ipt ruleInterface, table iptables.Table, chain iptables.Chain) ([]RuleArg, error) {
 	buf := bytes.NewBuffer(nil)
 	err := execIPTablesWithRetry(func() error {
 		if err := ipt.SaveInto(table, buf); err != nil {
@@ -213,7 +340,7 @@ func getChainRuleArgs(ipt iptables.Interface, table iptables.Table, chain iptabl
 	return rules, nil
 }
 
-func processRules(ipt iptables.Interface, ownedChains []Chain, wantedChainRules map[Chain][]RuleArg, existingChainRules map[Chain][]RuleArg) error {
+func processRules(ipt ruleInterface, ownedChains []Chain, wantedChainRules map[Chain][]RuleArg, existingChainRules map[Chain][]RuleArg) error {
 	var err error
 	for _, wantedChain := range ownedChains {
 		err = execIPTablesWithRetry(func() error {
@@ -0,0 +1,192 @@
+package node
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+const (
+	ethTypeRARP   = 0x8035
+	arpOpRequest  = 1
+	rarpOpRequest = 3
+)
+
+// watchVirtualMachineMigrations watches the local live migratable pods on the
+// network to announce the addresses of their VMs once live migrated to them
+func (nc *SecondaryNodeNetworkController) watchVirtualMachineMigrations() (*factory.Handler, error) {
+	return nc.watchFactory.AddPodHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// announce again the VMs live migrated to the node, as the
+			// announcement may have been lost on a restart
+			nc.announceVirtualMachineMigration(obj.(*kapi.Pod))
+		},
+		UpdateFunc: func(old, newer interface{}) {
+			// announce the VM only when the pod becomes the ready target of a
+			// live migration
+			if kubevirt.IsPodMigrationTargetReady(old.(*kapi.Pod)) {
+				return
+			}
+			nc.announceVirtualMachineMigration(newer.(*kapi.Pod))
+		},
+	}, nil)
+}
+
+// announceVirtualMachineMigration announces the VM of the pod on each NAD of
+// the network if the pod is the local ready target of a live migration
+func (nc *SecondaryNodeNetworkController) announceVirtualMachineMigration(pod *kapi.Pod) {
+	if pod.Spec.NodeName != nc.name || !kubevirt.IsPodMigrationTargetReady(pod) ||
+		!kubevirt.IsPodAllowedForMigration(pod, nc.NetInfo) {
+		return
+	}
+	on, networkMap, err := util.GetPodNADToNetworkMapping(pod, nc.NetInfo)
+	if err != nil || !on {
+		return
+	}
+	for nadName := range networkMap {
+		if err := announceVirtualMachine(nc.ovsClient, pod, nadName); err != nil {
+			klog.Errorf("Failed to announce the VM of pod %s/%s on NAD %s: %v",
+				pod.Namespace, pod.Name, nadName, err)
+		}
+	}
+}
+
+// announceVirtualMachine injects a RARP for the MAC of the VM of the pod, and
+// a GARP or an unsolicited NA for each of its IPs, in the OVS pipeline as if
+// sent through the pod port, so that the network learns the new location of
+// the VM right after it was live migrated
+func announceVirtualMachine(ovsClient libovsdbclient.Client, pod *kapi.Pod, nadName string) error {
+	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName)
+	if err != nil {
+		return err
+	}
+
+	ifaceID := util.GetSecondaryNetworkIfaceId(pod.Namespace, pod.Name, nadName)
+	ifaces, err := libovsdbops.FindInterfacesWithPredicate(ovsClient, func(iface *vswitchd.Interface) bool {
+		return iface.ExternalIDs["iface-id"] == ifaceID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find the OVS interface %s: %v", ifaceID, err)
+	}
+	if len(ifaces) == 0 || ifaces[0].Ofport == nil || *ifaces[0].Ofport == -1 {
+		return fmt.Errorf("failed to find the OVS interface %s", ifaceID)
+	}
+	ofport := *ifaces[0].Ofport
+
+	frames := [][]byte{newRARPFrame(podAnnotation.MAC)}
+	for _, ip := range podAnnotation.IPs {
+		if utilnet.IsIPv6(ip.IP) {
+			frames = append(frames, newUnsolicitedNAFrame(podAnnotation.MAC, ip.IP))
+		} else {
+			frames = append(frames, newGARPFrame(podAnnotation.MAC, ip.IP))
+		}
+	}
+	for _, frame := range frames {
+		_, stderr, err := util.RunOVSOfctl("packet-out", "br-int",
+			fmt.Sprintf("in_port=%d packet=%s actions=table", ofport, hex.EncodeToString(frame)))
+		if err != nil {
+			return fmt.Errorf("failed to inject packet through OVS interface %s, stderr: %q, error: %v",
+				ifaceID, stderr, err)
+		}
+	}
+	klog.Infof("Announced MAC %s and IPs %v of the VM of pod %s/%s on NAD %s",
+		podAnnotation.MAC, util.StringSlice(podAnnotation.IPs), pod.Namespace, pod.Name, nadName)
+	return nil
+}
+
+// newBroadcastARPFrame returns a broadcast ARP or RARP frame of the given
+// ethertype and operation sent from mac
+func newBroadcastARPFrame(ethType, op uint16, mac net.HardwareAddr, senderIP, targetIP net.IP, targetMAC net.HardwareAddr) []byte {
+	frame := make([]byte, 14+28)
+	copy(frame[0:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], ethType)
+	arp := frame[14:]
+	// htype, ptype, hlen, plen, oper, sha, spa, tha, tpa
+	binary.BigEndian.PutUint16(arp[0:2], 1)
+	binary.BigEndian.PutUint16(arp[2:4], 0x0800)
+	arp[4], arp[5] = 6, 4
+	binary.BigEndian.PutUint16(arp[6:8], op)
+	copy(arp[8:14], mac)
+	copy(arp[14:18], senderIP.To4())
+	copy(arp[18:24], targetMAC)
+	copy(arp[24:28], targetIP.To4())
+	return frame
+}
+
+// newRARPFrame returns the RARP frame announcing mac, as sent by hypervisors
+// after a live migration
+func newRARPFrame(mac net.HardwareAddr) []byte {
+	return newBroadcastARPFrame(ethTypeRARP, rarpOpRequest, mac, net.IPv4zero, net.IPv4zero, mac)
+}
+
+// newGARPFrame returns the gratuitous ARP request frame announcing the IPv4
+// ip on mac
+func newGARPFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	return newBroadcastARPFrame(ethTypeARP, arpOpRequest, mac, ip, ip, make(net.HardwareAddr, 6))
+}
+
+// newUnsolicitedNAFrame returns the unsolicited neighbor advertisement frame
+// sent to all nodes announcing the IPv6 ip on mac, with the override flag set
+// and the target link-layer address option
+func newUnsolicitedNAFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	const icmpLen = 32
+	allNodes := net.ParseIP("ff02::1")
+	frame := make([]byte, 14+40+icmpLen)
+	copy(frame[0:6], []byte{0x33, 0x33, 0x00, 0x00, 0x00, 0x01})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], ethTypeIPv6)
+	ipv6 := frame[14:]
+	ipv6[0] = 0x60
+	binary.BigEndian.PutUint16(ipv6[4:6], icmpLen)
+	ipv6[6] = ipProtoICMPv6
+	ipv6[7] = 255
+	copy(ipv6[8:24], ip.To16())
+	copy(ipv6[24:40], allNodes.To16())
+	icmp := ipv6[40:]
+	icmp[0] = icmpv6TypeNA
+	// override flag
+	icmp[4] = 0x20
+	copy(icmp[8:24], ip.To16())
+	// target link-layer address option
+	icmp[24], icmp[25] = 2, 1
+	copy(icmp[26:32], mac)
+	binary.BigEndian.PutUint16(icmp[2:4], icmpv6Checksum(ip, allNodes, icmp))
+	return frame
+}
+
+// icmpv6Checksum returns the checksum of the ICMPv6 message including the
+// IPv6 pseudo header
+func icmpv6Checksum(src, dst net.IP, icmp []byte) uint16 {
+	pseudo := make([]byte, 40, 40+len(icmp))
+	copy(pseudo[0:16], src.To16())
+	copy(pseudo[16:32], dst.To16())
+	binary.BigEndian.PutUint32(pseudo[32:36], uint32(len(icmp)))
+	pseudo[39] = ipProtoICMPv6
+	data := append(pseudo, icmp...)
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i : i+2]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,80 @@
+package node
+
+import (
+	"encoding/hex"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+var _ = Describe("KubeVirt live migration announcement", func() {
+	vmMAC, _ := net.ParseMAC("0a:58:c0:a8:0a:05")
+	var execMock *ovntest.FakeExec
+
+	BeforeEach(func() {
+		execMock = ovntest.NewFakeExec()
+		Expect(util.SetExec(execMock)).To(Succeed())
+	})
+
+	It("builds frames announcing the VM addresses", func() {
+		Expect(getObservedIP(newGARPFrame(vmMAC, net.ParseIP("192.168.10.5")), vmMAC).String()).To(Equal("192.168.10.5"))
+
+		na := newUnsolicitedNAFrame(vmMAC, net.ParseIP("2001:db8::5"))
+		Expect(getObservedIP(na, vmMAC).String()).To(Equal("2001:db8::5"))
+		// the checksum of a message including its own checksum is zero
+		Expect(icmpv6Checksum(net.ParseIP("2001:db8::5"), net.ParseIP("ff02::1"), na[14+40:])).To(BeZero())
+
+		rarp := newRARPFrame(vmMAC)
+		Expect(rarp[12:14]).To(Equal([]byte{0x80, 0x35}))
+		Expect(net.HardwareAddr(rarp[14+8 : 14+14])).To(Equal(vmMAC))
+		Expect(net.HardwareAddr(rarp[14+18 : 14+24])).To(Equal(vmMAC))
+	})
+
+	It("injects the announcements through the pod port", func() {
+		const nadName = "ns1/l2"
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-vm1", Namespace: "ns1"}}
+		var err error
+		pod.Annotations, err = util.MarshalPodAnnotation(nil, &util.PodAnnotation{
+			MAC: vmMAC,
+			IPs: ovntest.MustParseIPNets("192.168.10.5/24", "2001:db8::5/64"),
+		}, nadName)
+		Expect(err).NotTo(HaveOccurred())
+
+		ifaceID := util.GetSecondaryNetworkIfaceId(pod.Namespace, pod.Name, nadName)
+		ofport := 7
+		ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+			OVSData: []libovsdbtest.TestData{
+				&vswitchd.Interface{
+					UUID:        "iface-vm1",
+					Name:        "vm1-port",
+					Ofport:      &ofport,
+					ExternalIDs: map[string]string{"iface-id": ifaceID},
+				},
+			},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer libovsdbCleanup.Cleanup()
+
+		for _, frame := range [][]byte{
+			newRARPFrame(vmMAC),
+			newGARPFrame(vmMAC, net.ParseIP("192.168.10.5")),
+			newUnsolicitedNAFrame(vmMAC, net.ParseIP("2001:db8::5")),
+		} {
+			execMock.AddFakeCmdsNoOutputNoError([]string{
+				"ovs-ofctl packet-out br-int in_port=7 packet=" + hex.EncodeToString(frame) + " actions=table",
+			})
+		}
+
+		Expect(announceVirtualMachine(ovsClient, pod, nadName)).To(Succeed())
+		Expect(execMock.CalledMatchesExpected()).To(BeTrue(), execMock.ErrorDesc)
+	})
+})
@@ -105,7 +105,8 @@ func (mp *managementPortRepresentor) Create(_ *routemanager.Controller, nodeAnno
 	return mpcfg, nil
 }
 
-func (mp *managementPortRepresentor) checkRepresentorPortHealth(cfg *managementPortConfig) {
+func (mp *managementPortRepresentor) checkRepresentorPortHealth(cfg *managementPortConfig) ([]managementPortWarning, error) {
+	var warnings []managementPortWarning
 	// After host reboot, management port link name changes back to default name.
 	link, err := util.GetNetLinkOps().LinkByName(cfg.ifName)
 	if err != nil {
@@ -113,16 +114,15 @@ func (mp *managementPortRepresentor) checkRepresentorPortHealth(cfg *managementP
 		// Get management port representor by name
 		link, err := util.GetNetLinkOps().LinkByName(mp.repName)
 		if err != nil {
-			klog.Errorf("Failed to get link device %s, error: %v", mp.repName, err)
-			return
+			return warnings, fmt.Errorf("failed to get link device %s: %v", mp.repName, err)
 		}
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftLinkRenamed,
+			"management port representor %s was renamed to %s, renaming it back...", cfg.ifName, mp.repName))
 		if err = util.GetNetLinkOps().LinkSetDown(link); err != nil {
-			klog.Errorf("Failed to set link down for device %s. %v", mp.repName, err)
-			return
+			return warnings, fmt.Errorf("failed to set link down for device %s: %v", mp.repName, err)
 		}
 		if err = util.GetNetLinkOps().LinkSetName(link, cfg.ifName); err != nil {
-			klog.Errorf("Rename link from %s to %s failed: %v", mp.repName, cfg.ifName, err)
-			return
+			return warnings, fmt.Errorf("rename link from %s to %s failed: %v", mp.repName, cfg.ifName, err)
 		}
 		if link.Attrs().MTU != config.Default.MTU {
 			if err = util.GetNetLinkOps().LinkSetMTU(link, config.Default.MTU); err != nil {
@@ -134,16 +134,21 @@ func (mp *managementPortRepresentor) checkRepresentorPortHealth(cfg *managementP
 		}
 		cfg.link = link
 	} else if (link.Attrs().Flags & net.FlagUp) != net.FlagUp {
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftLinkDown,
+			"management port representor %s is down, setting it up...", cfg.ifName))
 		if err = util.GetNetLinkOps().LinkSetUp(link); err != nil {
-			klog.Errorf("Failed to set link up for device %s. %v", cfg.ifName, err)
+			return warnings, fmt.Errorf("failed to set link up for device %s: %v", cfg.ifName, err)
 		}
 	}
+	return warnings, nil
 }
 
-func (mp *managementPortRepresentor) CheckManagementPortHealth(_ *routemanager.Controller, cfg *managementPortConfig, stopChan chan struct{}) {
+func (mp *managementPortRepresentor) CheckManagementPortHealth(_ *routemanager.Controller, cfg *managementPortConfig,
+	health *managementPortHealth, stopChan chan struct{}) {
 	go wait.Until(
 		func() {
-			mp.checkRepresentorPortHealth(cfg)
+			warnings, err := mp.checkRepresentorPortHealth(cfg)
+			health.report(cfg.ifName, warnings, err)
 		},
 		5*time.Second,
 		stopChan)
@@ -239,10 +244,11 @@ func (mp *managementPortNetdev) Create(routeManager *routemanager.Controller, no
 	return cfg, nil
 }
 
-func (mp *managementPortNetdev) CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig, stopChan chan struct{}) {
+func (mp *managementPortNetdev) CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig,
+	health *managementPortHealth, stopChan chan struct{}) {
 	go wait.Until(
 		func() {
-			checkManagementPortHealth(routeManager, cfg)
+			checkManagementPortHealth(routeManager, cfg, health)
 		},
 		30*time.Second,
 		stopChan)
@@ -3,15 +3,22 @@ package node
 import (
 	"fmt"
 	"net"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	kapi "k8s.io/api/core/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 
 	"k8s.io/klog/v2"
 
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/routemanager"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
@@ -23,8 +30,9 @@ type ManagementPort interface {
 	// and waiter to set up condition to wait on for management port creation
 	Create(routeManager *routemanager.Controller, nodeAnnotator kube.Annotator, waiter *startupWaiter) (*managementPortConfig, error)
 	// CheckManagementPortHealth checks periodically for management port health until stopChan is posted
-	// or closed and reports any warnings/errors to log
-	CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig, stopChan chan struct{})
+	// or closed and reports any warnings/errors to health
+	CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig, health *managementPortHealth,
+		stopChan chan struct{})
 	// Currently, the management port(s) that doesn't have an assignable IP address are the following cases:
 	//   - Full mode with HW backed device (e.g. Virtual Function Representor).
 	//   - DPU mode with Virtual Function Representor.
@@ -122,10 +130,11 @@ func (mp *managementPort) Create(routeManager *routemanager.Controller, nodeAnno
 	return cfg, nil
 }
 
-func (mp *managementPort) CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig, stopChan chan struct{}) {
+func (mp *managementPort) CheckManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig,
+	health *managementPortHealth, stopChan chan struct{}) {
 	go wait.Until(
 		func() {
-			checkManagementPortHealth(routeManager, cfg)
+			checkManagementPortHealth(routeManager, cfg, health)
 		},
 		30*time.Second,
 		stopChan)
@@ -161,3 +170,130 @@ func managementPortReady() (bool, error) {
 	klog.Infof("Management port %s is ready", k8sMgmtIntfName)
 	return true, nil
 }
+
+// mgmtPortDegradedThreshold is the number of consecutive health checks finding
+// a management port drifted after which it is reported as degraded
+const mgmtPortDegradedThreshold = 3
+
+// managementPortDrift is the kind of configuration of the management port
+// found missing or wrong by its health check
+type managementPortDrift string
+
+const (
+	mgmtPortDriftLinkDown            managementPortDrift = "link-down"
+	mgmtPortDriftLinkRenamed         managementPortDrift = "link-renamed"
+	mgmtPortDriftMACMismatch         managementPortDrift = "mac-mismatch"
+	mgmtPortDriftMissingAddress      managementPortDrift = "missing-address"
+	mgmtPortDriftMissingRoute        managementPortDrift = "missing-route"
+	mgmtPortDriftMissingNeighbor     managementPortDrift = "missing-neighbor"
+	mgmtPortDriftMissingIPTablesRule managementPortDrift = "missing-iptables-rule"
+)
+
+// managementPortWarning describes a drift of the management port repaired by
+// its health check
+type managementPortWarning struct {
+	drift   managementPortDrift
+	message string
+}
+
+func newManagementPortWarning(drift managementPortDrift, format string, args ...interface{}) managementPortWarning {
+	return managementPortWarning{drift: drift, message: fmt.Sprintf(format, args...)}
+}
+
+// managementPortHealth reports the outcome of the management ports health
+// checks through metrics and node events. A management port drifting in
+// mgmtPortDegradedThreshold consecutive checks is reported as degraded through
+// degradedFile, which fails the ovnkube-node readiness probe, until a check
+// finds it healthy again.
+type managementPortHealth struct {
+	sync.Mutex
+	nodeRef  *kapi.ObjectReference
+	recorder record.EventRecorder
+	// degradedFile exists, with the reason, while a management port is degraded
+	degradedFile string
+	// drifted is the number of consecutive drifted checks per management port
+	drifted map[string]int
+	// degraded is the reason the management ports are degraded, if any
+	degraded string
+}
+
+func newManagementPortHealth(nodeName string, recorder record.EventRecorder) *managementPortHealth {
+	h := &managementPortHealth{
+		nodeRef: &kapi.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			UID:  ktypes.UID(nodeName),
+		},
+		recorder:     recorder,
+		degradedFile: types.ManagementPortDegradedFile,
+		drifted:      map[string]int{},
+	}
+	// a previous ovnkube-node instance might have left it behind
+	if err := os.Remove(h.degradedFile); err != nil && !os.IsNotExist(err) {
+		klog.Errorf("Failed to remove the management port degraded file %s: %v", h.degradedFile, err)
+	}
+	return h
+}
+
+// report reports the warnings and the error of a health check of the
+// management port ifName
+func (h *managementPortHealth) report(ifName string, warnings []managementPortWarning, err error) {
+	h.Lock()
+	defer h.Unlock()
+
+	drifts := sets.New[string]()
+	for _, warning := range warnings {
+		klog.Warningf(warning.message)
+		metrics.MetricManagementPortDriftCount.WithLabelValues(string(warning.drift)).Inc()
+		drifts.Insert(string(warning.drift))
+	}
+	if len(warnings) > 0 {
+		h.recorder.Eventf(h.nodeRef, kapi.EventTypeWarning, "ManagementPortDrift",
+			"Management port %s drifted from its configuration and was repaired: %s",
+			ifName, strings.Join(sets.List(drifts), ", "))
+	}
+	if err != nil {
+		klog.Errorf(err.Error())
+		h.recorder.Eventf(h.nodeRef, kapi.EventTypeWarning, "ManagementPortRepairFailed",
+			"Failed to repair management port %s: %v", ifName, err)
+	}
+
+	if len(warnings) == 0 && err == nil {
+		h.drifted[ifName] = 0
+	} else {
+		h.drifted[ifName]++
+	}
+	var degraded []string
+	for _, name := range sets.List(sets.KeySet(h.drifted)) {
+		if h.drifted[name] >= mgmtPortDegradedThreshold {
+			degraded = append(degraded, fmt.Sprintf("management port %s drifted from its configuration in %d consecutive health checks",
+				name, h.drifted[name]))
+		}
+	}
+	h.setDegraded(strings.Join(degraded, "; "))
+}
+
+func (h *managementPortHealth) setDegraded(degraded string) {
+	if degraded == h.degraded {
+		return
+	}
+	if degraded != "" {
+		if err := os.WriteFile(h.degradedFile, []byte(degraded+"\n"), 0644); err != nil {
+			klog.Errorf("Failed to write the management port degraded file %s: %v", h.degradedFile, err)
+			return
+		}
+		metrics.MetricManagementPortDegraded.Set(1)
+		if h.degraded == "" {
+			h.recorder.Eventf(h.nodeRef, kapi.EventTypeWarning, "ManagementPortDegraded", "The %s", degraded)
+		}
+	} else {
+		if err := os.Remove(h.degradedFile); err != nil && !os.IsNotExist(err) {
+			klog.Errorf("Failed to remove the management port degraded file %s: %v", h.degradedFile, err)
+			return
+		}
+		metrics.MetricManagementPortDegraded.Set(0)
+		h.recorder.Eventf(h.nodeRef, kapi.EventTypeNormal, "ManagementPortRecovered",
+			"The management ports are healthy again")
+	}
+	h.degraded = degraded
+}
@@ -254,4 +254,37 @@ var _ = Describe("Mananagement port DPU tests", func() {
 				"createPlatformManagementPort error"))
 		})
 	})
+
+	Context("Check Management port DPU health", func() {
+		It("Reports and repairs a representor link that is down", func() {
+			mgmtPortDpu := managementPortRepresentor{
+				repName: "enp3s0f0_0",
+			}
+			cfg := &managementPortConfig{ifName: types.K8sMgmtIntfName}
+			linkMock := &mocks.Link{}
+			linkMock.On("Attrs").Return(&netlink.LinkAttrs{Name: types.K8sMgmtIntfName})
+			netlinkOpsMock.On("LinkByName", types.K8sMgmtIntfName).Return(linkMock, nil)
+			netlinkOpsMock.On("LinkSetUp", linkMock).Return(nil)
+
+			warnings, err := mgmtPortDpu.checkRepresentorPortHealth(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0].drift).To(Equal(mgmtPortDriftLinkDown))
+			netlinkOpsMock.AssertCalled(GinkgoT(), "LinkSetUp", linkMock)
+		})
+
+		It("Does not report a healthy representor link", func() {
+			mgmtPortDpu := managementPortRepresentor{
+				repName: "enp3s0f0_0",
+			}
+			cfg := &managementPortConfig{ifName: types.K8sMgmtIntfName}
+			linkMock := &mocks.Link{}
+			linkMock.On("Attrs").Return(&netlink.LinkAttrs{Name: types.K8sMgmtIntfName, Flags: net.FlagUp})
+			netlinkOpsMock.On("LinkByName", types.K8sMgmtIntfName).Return(linkMock, nil)
+
+			warnings, err := mgmtPortDpu.checkRepresentorPortHealth(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
 })
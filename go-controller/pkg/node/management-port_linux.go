@@ -4,6 +4,7 @@
 package node
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"strings"
@@ -35,6 +36,9 @@ type managementPortConfig struct {
 	ifName    string
 	link      netlink.Link
 	routerMAC net.HardwareAddr
+	// macAddress is the MAC address of the interface, restored by the health
+	// check when it changes
+	macAddress net.HardwareAddr
 
 	ipv4 *managementPortIPFamilyConfig
 	ipv6 *managementPortIPFamilyConfig
@@ -91,6 +95,7 @@ func newManagementPortConfig(interfaceName string, hostSubnets []*net.IPNet) (*m
 	if mpcfg.link, err = util.LinkSetUp(mpcfg.ifName); err != nil {
 		return nil, err
 	}
+	mpcfg.macAddress = mpcfg.link.Attrs().HardwareAddr
 
 	for _, hostSubnet := range hostSubnets {
 		isIPv6 := utilnet.IsIPv6CIDR(hostSubnet)
@@ -175,16 +180,16 @@ func tearDownManagementPortConfig(mpcfg *managementPortConfig) error {
 	return tearDownInterfaceIPConfig(mpcfg.link, ipt4, ipt6)
 }
 
-func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mpcfg *managementPortConfig, cfg *managementPortIPFamilyConfig) ([]string, error) {
-	var warnings []string
+func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mpcfg *managementPortConfig, cfg *managementPortIPFamilyConfig) ([]managementPortWarning, error) {
+	var warnings []managementPortWarning
 	var err error
 	var exists bool
 
 	if exists, err = util.LinkAddrExist(mpcfg.link, cfg.ifAddr); err == nil && !exists {
 		// we should log this so that one can debug as to why addresses are
 		// disappearing
-		warnings = append(warnings, fmt.Sprintf("missing IP address %s on the interface %s, adding it...",
-			cfg.ifAddr, mpcfg.ifName))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingAddress,
+			"missing IP address %s on the interface %s, adding it...", cfg.ifAddr, mpcfg.ifName))
 		err = util.LinkAddrAdd(mpcfg.link, cfg.ifAddr, 0)
 	}
 	if err != nil {
@@ -201,8 +206,8 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 			continue
 		}
 		// we need to warn so that it can be debugged as to why routes are disappearing
-		warnings = append(warnings, fmt.Sprintf("missing route entry for subnet %s via gateway %s on link %v",
-			subnet, cfg.gwIP, mpcfg.ifName))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingRoute,
+			"missing route entry for subnet %s via gateway %s on link %v", subnet, cfg.gwIP, mpcfg.ifName))
 		subnetCopy := *subnet
 		routes = append(routes, routemanager.Route{
 			GwIP:   cfg.gwIP,
@@ -221,8 +226,9 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 	// K8s Node IP. OVN Logical Router pipeline drops such packets since it expects
 	// source protocol address to be in the Logical Switch's subnet.
 	if exists, err = util.LinkNeighExists(mpcfg.link, cfg.gwIP, mpcfg.routerMAC); err == nil && !exists {
-		warnings = append(warnings, fmt.Sprintf("missing arp entry for MAC/IP binding (%s/%s) on link %s",
-			mpcfg.routerMAC.String(), cfg.gwIP, types.K8sMgmtIntfName))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingNeighbor,
+			"missing arp entry for MAC/IP binding (%s/%s) on link %s", mpcfg.routerMAC.String(), cfg.gwIP,
+			types.K8sMgmtIntfName))
 		err = util.LinkNeighAdd(mpcfg.link, cfg.gwIP, mpcfg.routerMAC)
 	}
 	if err != nil {
@@ -250,8 +256,8 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 	}
 
 	if _, err = cfg.ipt.List("nat", iptableMgmPortChain); err != nil {
-		warnings = append(warnings, fmt.Sprintf("missing iptables chain %s in the nat table, adding it",
-			iptableMgmPortChain))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingIPTablesRule,
+			"missing iptables chain %s in the nat table, adding it", iptableMgmPortChain))
 		err = cfg.ipt.NewChain("nat", iptableMgmPortChain)
 	}
 	if err != nil {
@@ -260,8 +266,8 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 	}
 	rule := []string{"-o", mpcfg.ifName, "-j", iptableMgmPortChain}
 	if exists, err = cfg.ipt.Exists("nat", "POSTROUTING", rule...); err == nil && !exists {
-		warnings = append(warnings, fmt.Sprintf("missing iptables postrouting nat chain %s, adding it",
-			iptableMgmPortChain))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingIPTablesRule,
+			"missing iptables postrouting nat chain %s, adding it", iptableMgmPortChain))
 		err = cfg.ipt.Insert("nat", "POSTROUTING", 1, rule...)
 	}
 	if err != nil {
@@ -271,8 +277,8 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 	rule = []string{"-o", mpcfg.ifName, "-j", "SNAT", "--to-source", cfg.ifAddr.IP.String(),
 		"-m", "comment", "--comment", "OVN SNAT to Management Port"}
 	if exists, err = cfg.ipt.Exists("nat", iptableMgmPortChain, rule...); err == nil && !exists {
-		warnings = append(warnings, fmt.Sprintf("missing management port nat rule in chain %s, adding it",
-			iptableMgmPortChain))
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMissingIPTablesRule,
+			"missing management port nat rule in chain %s, adding it", iptableMgmPortChain))
 		// NOTE: SNAT to mp0 rule should be the last in the chain, so append it
 		err = cfg.ipt.Append("nat", iptableMgmPortChain, rule...)
 	}
@@ -284,10 +290,13 @@ func setupManagementPortIPFamilyConfig(routeManager *routemanager.Controller, mp
 	return warnings, nil
 }
 
-func setupManagementPortConfig(routeManager *routemanager.Controller, cfg *managementPortConfig) ([]string, error) {
-	var warnings, allWarnings []string
-	var err error
+func setupManagementPortConfig(routeManager *routemanager.Controller, cfg *managementPortConfig) ([]managementPortWarning, error) {
+	allWarnings, err := setupManagementPortLink(cfg)
+	if err != nil {
+		return allWarnings, err
+	}
 
+	var warnings []managementPortWarning
 	if cfg.ipv4 != nil {
 		warnings, err = setupManagementPortIPFamilyConfig(routeManager, cfg, cfg.ipv4)
 		allWarnings = append(allWarnings, warnings...)
@@ -481,16 +490,41 @@ func DelMgtPortIptRules() {
 	_ = ipt6.DeleteChain("nat", iptableMgmPortChain)
 }
 
-// checks to make sure that following configurations are present on the k8s node
-// 1. route entries to cluster CIDR and service CIDR through management port
-// 2. ARP entry for the node subnet's gateway ip
-// 3. IPtables chain and rule for SNATing packets entering the logical topology
-func checkManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig) {
-	warnings, err := setupManagementPortConfig(routeManager, cfg)
-	for _, warning := range warnings {
-		klog.Warningf(warning)
-	}
+// setupManagementPortLink makes sure that the management port link is up and
+// still has its MAC address
+func setupManagementPortLink(cfg *managementPortConfig) ([]managementPortWarning, error) {
+	var warnings []managementPortWarning
+	link, err := util.GetNetLinkOps().LinkByName(cfg.ifName)
 	if err != nil {
-		klog.Errorf(err.Error())
+		return nil, fmt.Errorf("failed to get link device %s: %v", cfg.ifName, err)
+	}
+	cfg.link = link
+
+	if len(cfg.macAddress) > 0 && !bytes.Equal(link.Attrs().HardwareAddr, cfg.macAddress) {
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftMACMismatch,
+			"MAC address %s of the interface %s does not match %s, setting it...", link.Attrs().HardwareAddr,
+			cfg.ifName, cfg.macAddress))
+		if err = util.GetNetLinkOps().LinkSetHardwareAddr(link, cfg.macAddress); err != nil {
+			return warnings, fmt.Errorf("failed to set MAC address %s on the interface %s: %v", cfg.macAddress,
+				cfg.ifName, err)
+		}
+	}
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		warnings = append(warnings, newManagementPortWarning(mgmtPortDriftLinkDown,
+			"interface %s is down, setting it up...", cfg.ifName))
+		if err = util.GetNetLinkOps().LinkSetUp(link); err != nil {
+			return warnings, fmt.Errorf("failed to set link up for device %s: %v", cfg.ifName, err)
+		}
 	}
+	return warnings, nil
+}
+
+// checks to make sure that following configurations are present on the k8s node
+// 1. management port link up with its MAC address
+// 2. route entries to cluster CIDR and service CIDR through management port
+// 3. ARP entry for the node subnet's gateway ip
+// 4. IPtables chain and rule for SNATing packets entering the logical topology
+func checkManagementPortHealth(routeManager *routemanager.Controller, cfg *managementPortConfig, health *managementPortHealth) {
+	warnings, err := setupManagementPortConfig(routeManager, cfg)
+	health.report(cfg.ifName, warnings, err)
 }
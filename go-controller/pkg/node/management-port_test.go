@@ -1,12 +1,17 @@
 package node
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+
+	"k8s.io/client-go/tools/record"
 )
 
 var _ = Describe("Mananagement port tests", func() {
@@ -52,4 +57,54 @@ var _ = Describe("Mananagement port tests", func() {
 			Expect(port.repName).To(Equal("ens1f0_0"))
 		})
 	})
+
+	Context("Management port health", func() {
+		var (
+			recorder *record.FakeRecorder
+			health   *managementPortHealth
+			tmpDir   string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = os.MkdirTemp("", "mgmtporthealth")
+			Expect(err).NotTo(HaveOccurred())
+			recorder = record.NewFakeRecorder(20)
+			health = newManagementPortHealth("worker-node", recorder)
+			health.degradedFile = filepath.Join(tmpDir, "mgmt-port-degraded")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		It("reports drifts and the management port degraded while it keeps drifting", func() {
+			warnings := []managementPortWarning{
+				newManagementPortWarning(mgmtPortDriftMissingRoute, "missing route"),
+				newManagementPortWarning(mgmtPortDriftLinkDown, "link down"),
+			}
+			for i := 1; i < mgmtPortDegradedThreshold; i++ {
+				health.report(types.K8sMgmtIntfName, warnings, nil)
+				Expect(recorder.Events).To(Receive(Equal(
+					"Warning ManagementPortDrift Management port ovn-k8s-mp0 drifted from its configuration and was repaired: link-down, missing-route")))
+				Expect(health.degradedFile).NotTo(BeAnExistingFile())
+			}
+
+			health.report(types.K8sMgmtIntfName, nil, fmt.Errorf("boom"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ManagementPortRepairFailed")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ManagementPortDegraded")))
+			reason, err := os.ReadFile(health.degradedFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(reason)).To(ContainSubstring("management port ovn-k8s-mp0 drifted"))
+
+			// another management port being healthy does not clear it
+			health.report(types.K8sMgmtIntfName+"_0", nil, nil)
+			Expect(health.degradedFile).To(BeAnExistingFile())
+
+			health.report(types.K8sMgmtIntfName, nil, nil)
+			Expect(recorder.Events).To(Receive(ContainSubstring("ManagementPortRecovered")))
+			Expect(health.degradedFile).NotTo(BeAnExistingFile())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
 })
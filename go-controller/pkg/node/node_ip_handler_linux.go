@@ -4,20 +4,26 @@
 package node
 
 import (
+	"bytes"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/node/linkmanager"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 	kapi "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
@@ -31,6 +37,9 @@ type addressManager struct {
 	cidrs          sets.Set[string]
 	nodeAnnotator  kube.Annotator
 	mgmtPortConfig *managementPortConfig
+	// ovsClient is the client of the local Open_vSwitch database the encap IP
+	// is configured in
+	ovsClient libovsdbclient.Client
 	// useNetlink indicates the addressManager should use machine
 	// information from netlink. Set to false for testcases.
 	useNetlink bool
@@ -38,29 +47,40 @@ type addressManager struct {
 	// compare node primary IP change
 	nodePrimaryAddr net.IP
 	gatewayBridge   *bridgeConfiguration
+	// trackIPv6NextHop indicates the IPv6 next hop of the gateway follows the
+	// default router of the gateway bridge, if any, e.g. learned from router
+	// advertisements, as none is configured
+	trackIPv6NextHop bool
+	// fallbackIPv6NextHop is the IPv6 next hop found when the gateway was
+	// initialized, e.g. the dummy next hop of a node without uplink, used while
+	// the gateway bridge has no default router
+	fallbackIPv6NextHop net.IP
 
 	OnChanged func()
 	sync.Mutex
 }
 
 // initializes a new address manager which will hold all the IPs on a node
-func newAddressManager(nodeName string, k kube.Interface, config *managementPortConfig, watchFactory factory.NodeWatchFactory, gwBridge *bridgeConfiguration) *addressManager {
-	return newAddressManagerInternal(nodeName, k, config, watchFactory, gwBridge, true)
+func newAddressManager(nodeName string, k kube.Interface, config *managementPortConfig, watchFactory factory.NodeWatchFactory, gwBridge *bridgeConfiguration, gwNextHops []net.IP, ovsClient libovsdbclient.Client) *addressManager {
+	return newAddressManagerInternal(nodeName, k, config, watchFactory, gwBridge, gwNextHops, ovsClient, true)
 }
 
 // newAddressManagerInternal creates a new address manager; this function is
 // only expose for testcases to disable netlink subscription to ensure
 // reproducibility of unit tests.
-func newAddressManagerInternal(nodeName string, k kube.Interface, config *managementPortConfig, watchFactory factory.NodeWatchFactory, gwBridge *bridgeConfiguration, useNetlink bool) *addressManager {
+func newAddressManagerInternal(nodeName string, k kube.Interface, config *managementPortConfig, watchFactory factory.NodeWatchFactory, gwBridge *bridgeConfiguration, gwNextHops []net.IP, ovsClient libovsdbclient.Client, useNetlink bool) *addressManager {
 	mgr := &addressManager{
 		nodeName:       nodeName,
 		watchFactory:   watchFactory,
 		cidrs:          sets.New[string](),
 		mgmtPortConfig: config,
 		gatewayBridge:  gwBridge,
+		ovsClient:      ovsClient,
 		OnChanged:      func() {},
 		useNetlink:     useNetlink,
 	}
+	mgr.trackIPv6NextHop = useNetlink && gwBridge != nil && shouldTrackIPv6NextHop()
+	mgr.fallbackIPv6NextHop, _ = util.MatchFirstIPFamily(true, gwNextHops)
 	mgr.nodeAnnotator = kube.NewNodeAnnotator(k, nodeName)
 	mgr.sync()
 	return mgr
@@ -113,6 +133,8 @@ func (c *addressManager) ListAddresses() []net.IP {
 
 type subscribeFn func() (bool, chan netlink.AddrUpdate, error)
 
+type routeSubscribeFn func() (bool, chan netlink.RouteUpdate, error)
+
 func (c *addressManager) Run(stopChan <-chan struct{}, doneWg *sync.WaitGroup) {
 	addrSubscribeOptions := netlink.AddrSubscribeOptions{
 		ErrorCallback: func(err error) {
@@ -131,12 +153,30 @@ func (c *addressManager) Run(stopChan <-chan struct{}, doneWg *sync.WaitGroup) {
 		return true, addrChan, nil
 	}
 
-	c.runInternal(stopChan, doneWg, subscribe)
+	var subscribeRoutes routeSubscribeFn
+	if c.trackIPv6NextHop {
+		routeSubscribeOptions := netlink.RouteSubscribeOptions{
+			ErrorCallback: func(err error) {
+				klog.Errorf("Failed during RouteSubscribe callback: %v", err)
+			},
+		}
+		subscribeRoutes = func() (bool, chan netlink.RouteUpdate, error) {
+			routeChan := make(chan netlink.RouteUpdate, 20)
+			if err := netlink.RouteSubscribeWithOptions(routeChan, stopChan, routeSubscribeOptions); err != nil {
+				return false, nil, err
+			}
+			return true, routeChan, nil
+		}
+	}
+
+	c.runInternal(stopChan, doneWg, subscribe, subscribeRoutes)
 }
 
 // runInternal can be used by testcases to provide a fake subscription function
-// rather than using netlink
-func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.WaitGroup, subscribe subscribeFn) {
+// rather than using netlink. subscribeRoutes is nil if the routes of the
+// gateway bridge are not tracked.
+func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.WaitGroup, subscribe subscribeFn,
+	subscribeRoutes routeSubscribeFn) {
 	// Add an event handler to the node informer. This is needed for cases where users first update the node's IP
 	// address but only later update kubelet configuration and restart kubelet (which in turn will update the reported
 	// IP address inside the node's status field).
@@ -161,6 +201,14 @@ func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.Wait
 		if err != nil {
 			klog.Errorf("Error during netlink subscribe for IP Manager: %v", err)
 		}
+		// routeChan stays nil, blocking forever, if routes are not tracked
+		var routeChan chan netlink.RouteUpdate
+		var routesSubscribed bool
+		if subscribeRoutes != nil {
+			if routesSubscribed, routeChan, err = subscribeRoutes(); err != nil {
+				klog.Errorf("Error during netlink route subscribe for IP Manager: %v", err)
+			}
+		}
 
 		for {
 			select {
@@ -180,7 +228,9 @@ func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.Wait
 				}
 
 				c.handleNodePrimaryAddrChange()
-				if addrChanged || !c.doNodeHostCIDRsMatch() {
+				// an address of the gateway bridge may be deprecated, e.g.
+				// when its prefix is renumbered, without being removed
+				if addrChanged || c.gatewayBridgeChanged() || !c.doNodeHostCIDRsMatch() {
 					klog.Infof("Host CIDRs changed to %v. Updating node address annotations.", c.cidrs)
 					err := c.updateNodeAddressAnnotations()
 					if err != nil {
@@ -188,6 +238,27 @@ func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.Wait
 					}
 					c.OnChanged()
 				}
+			case r, ok := <-routeChan:
+				if !ok {
+					if routesSubscribed, routeChan, err = subscribeRoutes(); err != nil {
+						klog.Errorf("Error during netlink route re-subscribe due to channel closing for IP Manager: %v", err)
+					}
+					continue
+				}
+				// default routers and on-link prefixes learned from router
+				// advertisements change the next hop and the addresses of
+				// the gateway
+				if r.Family != netlink.FAMILY_V6 || (r.Table != unix.RT_TABLE_UNSPEC && r.Table != unix.RT_TABLE_MAIN) {
+					continue
+				}
+				if c.gatewayBridgeChanged() {
+					klog.Infof("Gateway bridge routes changed. Updating node address annotations.")
+					err := c.updateNodeAddressAnnotations()
+					if err != nil {
+						klog.Errorf("Address Manager failed to update node address annotations: %v", err)
+					}
+					c.OnChanged()
+				}
 			case <-addressSyncTimer.C:
 				if subscribed {
 					klog.V(5).Info("Node IP manager calling sync() explicitly")
@@ -197,6 +268,11 @@ func (c *addressManager) runInternal(stopChan <-chan struct{}, doneWg *sync.Wait
 						klog.Errorf("Error during netlink re-subscribe for IP Manager: %v", err)
 					}
 				}
+				if subscribeRoutes != nil && !routesSubscribed {
+					if routesSubscribed, routeChan, err = subscribeRoutes(); err != nil {
+						klog.Errorf("Error during netlink route re-subscribe for IP Manager: %v", err)
+					}
+				}
 			case <-stopChan:
 				return
 			}
@@ -217,7 +293,7 @@ func (c *addressManager) handleNodePrimaryAddrChange() {
 	}
 	if nodePrimaryAddrChanged {
 		klog.Infof("Node primary address changed to %v. Updating OVN encap IP.", c.nodePrimaryAddr)
-		updateOVNEncapIPAndReconnect(c.nodePrimaryAddr)
+		updateOVNEncapIPAndReconnect(c.ovsClient, c.nodePrimaryAddr)
 	}
 }
 
@@ -259,6 +335,15 @@ func (c *addressManager) updateNodeAddressAnnotations() error {
 		return err
 	}
 	gatewayCfg.IPAddresses = ifAddrs
+	if c.trackIPv6NextHop {
+		nextHop, err := c.getIPv6NextHop()
+		if err != nil {
+			return err
+		}
+		if nextHop != nil {
+			gatewayCfg.NextHops = replaceIPv6NextHop(gatewayCfg.NextHops, nextHop)
+		}
+	}
 	err = util.SetL3GatewayConfig(c.nodeAnnotator, gatewayCfg)
 	if err != nil {
 		return err
@@ -418,7 +503,7 @@ func (c *addressManager) sync() {
 
 	addrChanged := c.assignCIDRs(currAddresses)
 	c.handleNodePrimaryAddrChange()
-	if addrChanged || !c.doNodeHostCIDRsMatch() {
+	if addrChanged || c.gatewayBridgeChanged() || !c.doNodeHostCIDRsMatch() {
 		klog.Infof("Node address changed to %v. Updating annotations.", currAddresses)
 		err := c.updateNodeAddressAnnotations()
 		if err != nil {
@@ -428,42 +513,162 @@ func (c *addressManager) sync() {
 	}
 }
 
-// updateOVNEncapIPAndReconnect updates encap IP to OVS when the node primary IP changed.
-func updateOVNEncapIPAndReconnect(newIP net.IP) {
-	checkCmd := []string{
-		"get",
-		"Open_vSwitch",
-		".",
-		"external_ids:ovn-encap-ip",
-	}
-	encapIP, stderr, err := util.RunOVSVsctl(checkCmd...)
+// gatewayBridgeChanged returns true if the addresses of the gateway bridge or
+// its IPv6 next hop, the default router or the fallback next hop once the
+// router is gone, differ from the ones annotated in the l3 gateway config of
+// the node
+func (c *addressManager) gatewayBridgeChanged() bool {
+	if !c.useNetlink || config.OvnKubeNode.Mode == types.NodeModeDPU {
+		return false
+	}
+	node, err := c.watchFactory.GetNode(c.nodeName)
 	if err != nil {
-		klog.Warningf("Unable to retrieve configured ovn-encap-ip from OVS: %v, %q", err, stderr)
-	} else {
-		encapIP = strings.TrimSuffix(encapIP, "\n")
-		if len(encapIP) > 0 && newIP.String() == encapIP {
-			klog.V(4).Infof("Will not update encap IP %s - it is already configured", newIP.String())
-			return
+		klog.Errorf("Unable to get node from informer: %v", err)
+		return false
+	}
+	gatewayCfg, err := util.ParseNodeL3GatewayAnnotation(node)
+	if err != nil {
+		// the gateway is not initialized yet
+		return false
+	}
+
+	ifAddrs, err := getNetworkInterfaceIPAddresses(c.gatewayBridge.bridgeName)
+	if err != nil {
+		klog.Errorf("Unable to get the addresses of gateway bridge %s: %v", c.gatewayBridge.bridgeName, err)
+		return false
+	}
+	if len(ifAddrs) != len(gatewayCfg.IPAddresses) {
+		return true
+	}
+	for i := range ifAddrs {
+		if ifAddrs[i].String() != gatewayCfg.IPAddresses[i].String() {
+			return true
 		}
 	}
 
-	confCmd := []string{
-		"set",
-		"Open_vSwitch",
-		".",
-		fmt.Sprintf("external_ids:ovn-encap-ip=%s", newIP),
+	if c.trackIPv6NextHop {
+		nextHop, err := c.getIPv6NextHop()
+		if err != nil {
+			klog.Errorf("Unable to get the IPv6 next hop of gateway bridge %s: %v", c.gatewayBridge.bridgeName, err)
+			return false
+		}
+		annotatedNextHop, _ := util.MatchFirstIPFamily(true, gatewayCfg.NextHops)
+		if nextHop != nil && !nextHop.Equal(annotatedNextHop) {
+			return true
+		}
+	}
+	return false
+}
+
+// getIPv6NextHop returns the IPv6 default router of the gateway bridge or, if
+// there is none, the IPv6 next hop found when the gateway was initialized
+func (c *addressManager) getIPv6NextHop() (net.IP, error) {
+	link, err := util.GetNetLinkOps().LinkByName(c.gatewayBridge.bridgeName)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := util.GetNetLinkOps().RouteListFiltered(netlink.FAMILY_V6,
+		&netlink.Route{Table: unix.RT_TABLE_MAIN}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
 	}
+	if nextHop := getIPv6DefaultRouter(routes, link.Attrs().Index); nextHop != nil {
+		return nextHop, nil
+	}
+	return c.fallbackIPv6NextHop, nil
+}
 
-	_, stderr, err = util.RunOVSVsctl(confCmd...)
+// getIPv6DefaultRouter returns the next hop of the preferred IPv6 default route
+// through the given link: the one with the lowest metric, routes learned from
+// router advertisements being preferred over other routes of the same metric.
+// It returns nil if there is no such route.
+func getIPv6DefaultRouter(routes []netlink.Route, linkIndex int) net.IP {
+	type defaultRouter struct {
+		ip       net.IP
+		priority int
+		ra       bool
+	}
+	var routers []defaultRouter
+	for _, route := range routes {
+		if route.Dst != nil {
+			if ones, _ := route.Dst.Mask.Size(); ones != 0 {
+				continue
+			}
+		}
+		ra := route.Protocol == unix.RTPROT_RA
+		if route.Gw != nil && route.LinkIndex == linkIndex {
+			routers = append(routers, defaultRouter{ip: route.Gw, priority: route.Priority, ra: ra})
+		}
+		for _, nh := range route.MultiPath {
+			if nh.Gw != nil && nh.LinkIndex == linkIndex {
+				routers = append(routers, defaultRouter{ip: nh.Gw, priority: route.Priority, ra: ra})
+			}
+		}
+	}
+	if len(routers) == 0 {
+		return nil
+	}
+	sort.Slice(routers, func(i, j int) bool {
+		if routers[i].priority != routers[j].priority {
+			return routers[i].priority < routers[j].priority
+		}
+		if routers[i].ra != routers[j].ra {
+			return routers[i].ra
+		}
+		return bytes.Compare(routers[i].ip.To16(), routers[j].ip.To16()) < 0
+	})
+	return routers[0].ip
+}
+
+// replaceIPv6NextHop returns the given next hops with the IPv6 next hop
+// replaced by nextHop
+func replaceIPv6NextHop(nextHops []net.IP, nextHop net.IP) []net.IP {
+	out := make([]net.IP, 0, len(nextHops)+1)
+	for _, ip := range nextHops {
+		if !utilnet.IsIPv6(ip) {
+			out = append(out, ip)
+		}
+	}
+	return append(out, nextHop)
+}
+
+// shouldTrackIPv6NextHop returns true if the IPv6 next hop of the gateway
+// follows the default router of the gateway bridge: no IPv6 gateway next hop
+// is configured
+func shouldTrackIPv6NextHop() bool {
+	if !config.IPv6Mode || config.OvnKubeNode.Mode != types.NodeModeFull {
+		return false
+	}
+	for _, nextHop := range strings.Split(config.Gateway.NextHop, ",") {
+		if ip := net.ParseIP(strings.TrimSpace(nextHop)); ip != nil && utilnet.IsIPv6(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateOVNEncapIPAndReconnect updates encap IP to OVS when the node primary IP changed.
+func updateOVNEncapIPAndReconnect(ovsClient libovsdbclient.Client, newIP net.IP) {
+	openvSwitch, err := libovsdbops.GetOpenvSwitch(ovsClient)
+	if err != nil {
+		klog.Warningf("Unable to retrieve configured ovn-encap-ip from OVS: %v", err)
+	} else if encapIP := openvSwitch.ExternalIDs["ovn-encap-ip"]; len(encapIP) > 0 && newIP.String() == encapIP {
+		klog.V(4).Infof("Will not update encap IP %s - it is already configured", newIP.String())
+		return
+	}
+
+	err = libovsdbops.UpdateOpenvSwitchSetExternalIDsAndOtherConfig(ovsClient, &vswitchd.OpenvSwitch{
+		ExternalIDs: map[string]string{"ovn-encap-ip": newIP.String()},
+	})
 	if err != nil {
-		klog.Errorf("Error setting OVS encap IP %s: %v %q", newIP.String(), err, stderr)
+		klog.Errorf("Error setting OVS encap IP %s: %v", newIP.String(), err)
 		return
 	}
 
 	// force ovn-controller to reconnect SB with new encap IP immediately.
 	// otherwise there will be a max delay of 200s due to the 100s
 	// ovn-controller inactivity probe.
-	_, stderr, err = util.RunOVNAppctlWithTimeout(5, "-t", "ovn-controller", "exit", "--restart")
+	_, stderr, err := util.RunOVNAppctlWithTimeout(5, "-t", "ovn-controller", "exit", "--restart")
 	if err != nil {
 		klog.Errorf("Failed to exit ovn-controller %v %q", err, stderr)
 		return
@@ -13,9 +13,14 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	linkMock "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/mocks/github.com/vishvananda/netlink"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	utilMocks "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util/mocks"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -113,7 +118,7 @@ var _ = Describe("Node IP Handler tests", func() {
 		fakeBridgeConfiguration := &bridgeConfiguration{}
 
 		k := &kube.Kube{KClient: tc.fakeClient}
-		tc.ipManager = newAddressManagerInternal(nodeName, k, fakeMgmtPortConfig, tc.watchFactory, fakeBridgeConfiguration, false)
+		tc.ipManager = newAddressManagerInternal(nodeName, k, fakeMgmtPortConfig, tc.watchFactory, fakeBridgeConfiguration, nil, nil, false)
 
 		// We need to wait until the ipManager's goroutine runs the subscribe
 		// function at least once. We can't use a WaitGroup because we have
@@ -125,7 +130,7 @@ var _ = Describe("Node IP Handler tests", func() {
 			tc.ipManager.sync()
 			return true, tc.addrChan, nil
 		}
-		tc.ipManager.runInternal(tc.stopCh, tc.doneWg, subscribe)
+		tc.ipManager.runInternal(tc.stopCh, tc.doneWg, subscribe, nil)
 		Eventually(func() bool {
 			return atomic.LoadUint32(&tc.subscribed) == 1
 		}, 5).Should(BeTrue())
@@ -194,3 +199,100 @@ var _ = Describe("Node IP Handler tests", func() {
 		})
 	})
 })
+
+var _ = Describe("Gateway IPv6 next hop", func() {
+	const linkIndex = 5
+	defaultRoute := func(gw string, priority int, protocol netlink.RouteProtocol) netlink.Route {
+		return netlink.Route{
+			LinkIndex: linkIndex,
+			Gw:        net.ParseIP(gw),
+			Priority:  priority,
+			Protocol:  protocol,
+			Family:    netlink.FAMILY_V6,
+		}
+	}
+
+	It("selects the preferred default router of the gateway bridge", func() {
+		Expect(getIPv6DefaultRouter(nil, linkIndex)).To(BeNil())
+
+		onLink := netlink.Route{LinkIndex: linkIndex, Dst: ovntest.MustParseIPNet("2001:db8::/64"), Protocol: unix.RTPROT_RA}
+		otherLink := defaultRoute("fe80::9", 1, unix.RTPROT_RA)
+		otherLink.LinkIndex = linkIndex + 1
+		Expect(getIPv6DefaultRouter([]netlink.Route{onLink, otherLink}, linkIndex)).To(BeNil())
+
+		routes := []netlink.Route{
+			onLink,
+			otherLink,
+			defaultRoute("fe80::3", 1024, unix.RTPROT_RA),
+			defaultRoute("fe80::2", 1024, unix.RTPROT_BOOT),
+		}
+		// router advertisements are preferred for the same metric
+		Expect(getIPv6DefaultRouter(routes, linkIndex).String()).To(Equal("fe80::3"))
+
+		routes = append(routes, defaultRoute("fe80::4", 100, unix.RTPROT_STATIC))
+		Expect(getIPv6DefaultRouter(routes, linkIndex).String()).To(Equal("fe80::4"))
+
+		// routers of a multipath route
+		multipath := netlink.Route{
+			Dst:      ovntest.MustParseIPNet("::/0"),
+			Priority: 1024,
+			Protocol: unix.RTPROT_RA,
+			MultiPath: []*netlink.NexthopInfo{
+				{LinkIndex: linkIndex, Gw: net.ParseIP("fe80::7")},
+				{LinkIndex: linkIndex, Gw: net.ParseIP("fe80::6")},
+			},
+		}
+		Expect(getIPv6DefaultRouter([]netlink.Route{multipath}, linkIndex).String()).To(Equal("fe80::6"))
+	})
+
+	It("replaces the IPv6 next hop of the gateway", func() {
+		nextHops := []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("fe80::1")}
+		Expect(replaceIPv6NextHop(nextHops, net.ParseIP("fe80::2"))).To(Equal(
+			[]net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("fe80::2")}))
+		Expect(replaceIPv6NextHop(nil, net.ParseIP("fe80::2"))).To(Equal([]net.IP{net.ParseIP("fe80::2")}))
+	})
+
+	It("falls back to the IPv6 next hop of the gateway initialization without default router", func() {
+		origNetlinkOps := util.GetNetLinkOps()
+		defer util.SetNetLinkOpMockInst(origNetlinkOps)
+		netlinkOpsMock := &utilMocks.NetLinkOps{}
+		link := &linkMock.Link{}
+		util.SetNetLinkOpMockInst(netlinkOpsMock)
+		netlinkOpsMock.On("LinkByName", "breth0").Return(link, nil)
+		link.On("Attrs").Return(&netlink.LinkAttrs{Name: "breth0", Index: linkIndex})
+
+		mgr := &addressManager{
+			gatewayBridge:       &bridgeConfiguration{bridgeName: "breth0"},
+			fallbackIPv6NextHop: net.ParseIP("fd69::1"),
+		}
+		call := netlinkOpsMock.On("RouteListFiltered", netlink.FAMILY_V6, mock.Anything, mock.Anything).Return(
+			[]netlink.Route{defaultRoute("fe80::3", 1024, unix.RTPROT_RA)}, nil)
+		nextHop, err := mgr.getIPv6NextHop()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextHop.String()).To(Equal("fe80::3"))
+
+		// the router is gone
+		call.Unset()
+		netlinkOpsMock.On("RouteListFiltered", netlink.FAMILY_V6, mock.Anything, mock.Anything).Return(nil, nil)
+		nextHop, err = mgr.getIPv6NextHop()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextHop.String()).To(Equal("fd69::1"))
+	})
+
+	It("follows the default router unless an IPv6 next hop is configured", func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.IPv6Mode = true
+		config.OvnKubeNode.Mode = types.NodeModeFull
+		Expect(shouldTrackIPv6NextHop()).To(BeTrue())
+
+		config.Gateway.NextHop = "192.168.1.1"
+		Expect(shouldTrackIPv6NextHop()).To(BeTrue())
+
+		config.Gateway.NextHop = "192.168.1.1,fe80::1"
+		Expect(shouldTrackIPv6NextHop()).To(BeFalse())
+
+		config.Gateway.NextHop = ""
+		config.IPv6Mode = false
+		Expect(shouldTrackIPv6NextHop()).To(BeFalse())
+	})
+})
@@ -1,13 +1,18 @@
 package node
 
 import (
+	"net"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 	"github.com/pkg/errors"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -19,10 +24,24 @@ type openflowManager struct {
 	flowMutex     sync.Mutex
 	exGWFlowCache map[string][]string
 	exGWFlowMutex sync.Mutex
+	// flows installed in the bridges by the last sync, by table, priority and match. nil if
+	// the installed flows are unknown and all the flows must be replaced.
+	flowsInstalled     map[string]string
+	exGWFlowsInstalled map[string]string
+	// flows of the additional gateway uplink bridges
+	uplinkBridges   []*uplinkBridgeFlows
+	uplinkFlowMutex sync.Mutex
 	// channel to indicate we need to update flows immediately
 	flowChan chan struct{}
 }
 
+// uplinkBridgeFlows holds the flow cache of an additional gateway uplink bridge
+type uplinkBridgeFlows struct {
+	bridge         *bridgeConfiguration
+	flowCache      map[string][]string
+	flowsInstalled map[string]string
+}
+
 func (c *openflowManager) updateFlowCacheEntry(key string, flows []string) {
 	c.flowMutex.Lock()
 	defer c.flowMutex.Unlock()
@@ -50,7 +69,18 @@ func (c *openflowManager) requestFlowSync() {
 	}
 }
 
+// syncFlows replaces all the flows of the bridges with the cached flows
 func (c *openflowManager) syncFlows() {
+	c.sync(true)
+}
+
+// syncFlowChanges only programs the flows that changed since the last sync. All the flows are
+// replaced if the flows installed in a bridge are unknown, e.g. on startup or after a failure.
+func (c *openflowManager) syncFlowChanges() {
+	c.sync(false)
+}
+
+func (c *openflowManager) sync(full bool) {
 	// protect gwBridge config from being updated by gw.nodeIPManager
 	c.defaultBridge.Lock()
 	defer c.defaultBridge.Unlock()
@@ -58,30 +88,346 @@ func (c *openflowManager) syncFlows() {
 	c.flowMutex.Lock()
 	defer c.flowMutex.Unlock()
 
-	flows := []string{}
-	for _, entry := range c.flowCache {
-		flows = append(flows, entry...)
+	c.flowsInstalled = syncBridgeFlows(c.defaultBridge.bridgeName, c.flowCache, c.flowsInstalled, full)
+
+	if c.externalGatewayBridge != nil {
+		c.exGWFlowMutex.Lock()
+		defer c.exGWFlowMutex.Unlock()
+
+		c.exGWFlowsInstalled = syncBridgeFlows(c.externalGatewayBridge.bridgeName, c.exGWFlowCache, c.exGWFlowsInstalled, full)
 	}
 
-	_, stderr, err := util.ReplaceOFFlows(c.defaultBridge.bridgeName, flows)
-	if err != nil {
-		klog.Errorf("Failed to add flows, error: %v, stderr, %s, flows: %s", err, stderr, c.flowCache)
+	c.uplinkFlowMutex.Lock()
+	defer c.uplinkFlowMutex.Unlock()
+	for _, uplink := range c.uplinkBridges {
+		uplink.flowsInstalled = syncBridgeFlows(uplink.bridge.bridgeName, uplink.flowCache, uplink.flowsInstalled, full)
 	}
+}
+
+// checkFlowsInstalled makes the next sync replace all the flows of the bridges that do not
+// hold the flows installed, e.g. once ovs-vswitchd restarted or the flows were added or
+// deleted behind our back
+func (c *openflowManager) checkFlowsInstalled() {
+	c.flowMutex.Lock()
+	c.flowsInstalled = checkBridgeFlowsInstalled(c.defaultBridge.bridgeName, c.flowsInstalled)
+	c.flowMutex.Unlock()
 
 	if c.externalGatewayBridge != nil {
 		c.exGWFlowMutex.Lock()
-		defer c.exGWFlowMutex.Unlock()
+		c.exGWFlowsInstalled = checkBridgeFlowsInstalled(c.externalGatewayBridge.bridgeName, c.exGWFlowsInstalled)
+		c.exGWFlowMutex.Unlock()
+	}
 
-		flows := []string{}
-		for _, entry := range c.exGWFlowCache {
-			flows = append(flows, entry...)
+	c.uplinkFlowMutex.Lock()
+	defer c.uplinkFlowMutex.Unlock()
+	for _, uplink := range c.uplinkBridges {
+		uplink.flowsInstalled = checkBridgeFlowsInstalled(uplink.bridge.bridgeName, uplink.flowsInstalled)
+	}
+}
+
+// checkBridgeFlowsInstalled returns the flows installed in the bridge, or nil if the bridge
+// does not hold as many flows of each cookie
+func checkBridgeFlowsInstalled(bridgeName string, installed map[string]string) map[string]string {
+	if installed == nil {
+		return nil
+	}
+	stdout, stderr, err := util.RunOVSOfctl("-O", "OpenFlow13", "--no-stats", "dump-flows", bridgeName)
+	if err != nil {
+		klog.Warningf("Failed to get the flows of bridge %s, stderr: %q, error: %v", bridgeName, stderr, err)
+		return installed
+	}
+	wantedCookies := map[string]int{}
+	for _, flow := range installed {
+		wantedCookies[getFlowCookie(flow)]++
+	}
+	cookies := map[string]int{}
+	for _, flow := range strings.Split(stdout, "\n") {
+		if strings.Contains(flow, "actions=") {
+			cookies[getFlowCookie(flow)]++
 		}
+	}
+	for cookie := range sets.KeySet(wantedCookies).Union(sets.KeySet(cookies)) {
+		if cookies[cookie] != wantedCookies[cookie] {
+			klog.Infof("Bridge %s holds %d flows with cookie %s instead of %d, replacing all its flows",
+				bridgeName, cookies[cookie], cookie, wantedCookies[cookie])
+			return nil
+		}
+	}
+	return installed
+}
 
-		_, stderr, err := util.ReplaceOFFlows(c.externalGatewayBridge.bridgeName, flows)
+// getFlowCookie returns the cookie of the flow in hexadecimal, the way ovs-ofctl prints it
+func getFlowCookie(flow string) string {
+	for _, field := range strings.Split(flow, ",") {
+		value, found := strings.CutPrefix(strings.TrimSpace(field), "cookie=")
+		if !found {
+			continue
+		}
+		cookie, err := strconv.ParseUint(value, 0, 64)
 		if err != nil {
-			klog.Errorf("Failed to add flows, error: %v, stderr, %s, flows: %s", err, stderr, c.exGWFlowCache)
+			return value
+		}
+		return "0x" + strconv.FormatUint(cookie, 16)
+	}
+	return "0x0"
+}
+
+// syncBridgeFlows programs the cached flows in the bridge, either replacing all its flows or
+// only applying the changes to the installed ones, and returns the flows now installed
+func syncBridgeFlows(bridgeName string, flowCache map[string][]string, installed map[string]string, full bool) map[string]string {
+	wanted := getWantedFlows(flowCache)
+
+	if full || installed == nil {
+		flows := make([]string, 0, len(wanted))
+		for _, flow := range wanted {
+			flows = append(flows, flow)
+		}
+		sort.Strings(flows)
+		_, stderr, err := util.ReplaceOFFlows(bridgeName, flows)
+		if err != nil {
+			klog.Errorf("Failed to add flows, error: %v, stderr, %s, flows: %s", err, stderr, flowCache)
+			return nil
+		}
+		return wanted
+	}
+
+	flowMods := getFlowMods(installed, wanted)
+	if len(flowMods) == 0 {
+		return wanted
+	}
+	klog.V(5).Infof("Applying %d flow modifications to bridge %s", len(flowMods), bridgeName)
+	_, stderr, err := util.ModifyOFFlows(bridgeName, flowMods)
+	if err != nil {
+		klog.Errorf("Failed to modify flows, error: %v, stderr, %s, flow modifications: %s", err, stderr, flowMods)
+		return nil
+	}
+	return wanted
+}
+
+// getWantedFlows returns the cached flows by table, priority and match. A bridge holds a
+// single flow per match, so when several cache entries hold different flows with the same
+// match, the flow of the first entry in key order is the one programmed and the conflict
+// is logged.
+func getWantedFlows(flowCache map[string][]string) map[string]string {
+	keys := make([]string, 0, len(flowCache))
+	for key := range flowCache {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wanted := map[string]string{}
+	for _, key := range keys {
+		for _, flow := range flowCache[key] {
+			flow = strings.TrimSpace(flow)
+			if flow == "" {
+				continue
+			}
+			match := getFlowMatchKey(flow)
+			if other, ok := wanted[match]; ok {
+				if other != flow {
+					klog.Warningf("Flow %q of %s has the same match as flow %q, skipping it", flow, key, other)
+				}
+				continue
+			}
+			wanted[match] = flow
+		}
+	}
+	return wanted
+}
+
+// getFlowMods returns the flow modifications turning the installed flows into the wanted ones.
+// Adding a flow replaces the installed flow with the same match, so the installed flows are
+// only deleted if no wanted flow has their match.
+func getFlowMods(installed, wanted map[string]string) []string {
+	var adds, deletes []string
+	for match, flow := range wanted {
+		if installed[match] != flow {
+			adds = append(adds, "add "+flow)
+		}
+	}
+	for match, flow := range installed {
+		if _, ok := wanted[match]; !ok {
+			deletes = append(deletes, "delete_strict "+getFlowMatch(flow))
 		}
 	}
+	sort.Strings(deletes)
+	sort.Strings(adds)
+	return append(deletes, adds...)
+}
+
+// getFlowMatch returns the table, priority and match of the flow to delete it strictly
+func getFlowMatch(flow string) string {
+	table, priority, fields := parseFlowMatch(flow)
+	return strings.Join(append([]string{"table=" + table, "priority=" + priority}, fields...), ", ")
+}
+
+// getFlowMatchKey returns the table, priority and match of the flow, regardless of the way
+// the match is written, identifying the flow in the bridge
+func getFlowMatchKey(flow string) string {
+	table, priority, fields := parseFlowMatch(flow)
+	return strings.Join(append([]string{"table=" + table, "priority=" + priority}, normalizeFlowMatch(fields)...), ", ")
+}
+
+// flowMatchProtocols are the protocol shorthands of the flow matches along with the fields
+// they stand for
+var flowMatchProtocols = map[string][]string{
+	"ip":    {"dl_type=0x0800"},
+	"ipv6":  {"dl_type=0x86dd"},
+	"arp":   {"dl_type=0x0806"},
+	"icmp":  {"dl_type=0x0800", "nw_proto=1"},
+	"icmp6": {"dl_type=0x86dd", "nw_proto=58"},
+	"tcp":   {"dl_type=0x0800", "nw_proto=6"},
+	"tcp6":  {"dl_type=0x86dd", "nw_proto=6"},
+	"udp":   {"dl_type=0x0800", "nw_proto=17"},
+	"udp6":  {"dl_type=0x86dd", "nw_proto=17"},
+	"sctp":  {"dl_type=0x0800", "nw_proto=132"},
+	"sctp6": {"dl_type=0x86dd", "nw_proto=132"},
+	"rarp":  {"dl_type=0x8035"},
+	"mpls":  {"dl_type=0x8847"},
+	"mplsm": {"dl_type=0x8848"},
+}
+
+// flowMatchFieldAliases are the alternative names of the flow match fields
+var flowMatchFieldAliases = map[string]string{
+	"eth_src":  "dl_src",
+	"eth_dst":  "dl_dst",
+	"eth_type": "dl_type",
+	"ip_src":   "nw_src",
+	"ip_dst":   "nw_dst",
+	"ip_proto": "nw_proto",
+	"tcp_src":  "tp_src",
+	"tcp_dst":  "tp_dst",
+	"udp_src":  "tp_src",
+	"udp_dst":  "tp_dst",
+	"sctp_src": "tp_src",
+	"sctp_dst": "tp_dst",
+}
+
+// normalizeFlowMatch returns the sorted match fields in a canonical form, so that the same
+// match written in different ways, e.g. "tcp" or "ip, nw_proto=6", "tp_dst=0x50" or
+// "tp_dst=80", gives the same fields
+func normalizeFlowMatch(fields []string) []string {
+	normalized := sets.New[string]()
+	for _, field := range fields {
+		name, value, found := strings.Cut(field, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !found {
+			if protocolFields, ok := flowMatchProtocols[name]; ok {
+				normalized.Insert(normalizeFlowMatch(protocolFields)...)
+				continue
+			}
+			normalized.Insert(name)
+			continue
+		}
+		if alias, ok := flowMatchFieldAliases[name]; ok {
+			name = alias
+		}
+		value = strings.TrimSpace(value)
+		if name == "ct_state" && (strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")) {
+			normalized.Insert(name + "=" + normalizeCTState(value))
+			continue
+		}
+		normalized.Insert(name + "=" + normalizeFlowMatchValue(value))
+	}
+	return sets.List(normalized)
+}
+
+// normalizeCTState returns the connection tracking state flags of a match, e.g. "+trk+est",
+// sorted
+func normalizeCTState(value string) string {
+	var flags []string
+	for i := 0; i < len(value); {
+		j := i + 1
+		for j < len(value) && value[j] != '+' && value[j] != '-' {
+			j++
+		}
+		flags = append(flags, value[i:j])
+		i = j
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, "")
+}
+
+// normalizeFlowMatchValue returns the value of a match field, with an optional mask, in a
+// canonical form: numbers in decimal, IP addresses in their canonical form with a prefix
+// length, a full mask being left out, and MAC addresses in lower case
+func normalizeFlowMatchValue(value string) string {
+	addr, mask, masked := strings.Cut(value, "/")
+	if ip := net.ParseIP(addr); ip != nil {
+		if !masked {
+			return ip.String()
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+		ones, err := strconv.Atoi(mask)
+		if maskIP := net.ParseIP(mask); maskIP != nil {
+			ipMask := net.IPMask(maskIP.To16())
+			if ip.To4() != nil {
+				ipMask = net.IPMask(maskIP.To4())
+			}
+			var size int
+			ones, size = ipMask.Size()
+			if size == 0 {
+				// not a prefix mask
+				return ip.String() + "/" + maskIP.String()
+			}
+		} else if err != nil {
+			return value
+		}
+		if ones == bits {
+			return ip.String()
+		}
+		return ip.String() + "/" + strconv.Itoa(ones)
+	}
+	if mac, err := net.ParseMAC(addr); err == nil {
+		if masked {
+			if macMask, err := net.ParseMAC(mask); err == nil {
+				return mac.String() + "/" + macMask.String()
+			}
+			return value
+		}
+		return mac.String()
+	}
+	number, err := strconv.ParseUint(addr, 0, 64)
+	if err != nil {
+		return value
+	}
+	if !masked {
+		return strconv.FormatUint(number, 10)
+	}
+	numberMask, err := strconv.ParseUint(mask, 0, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatUint(number, 10) + "/" + strconv.FormatUint(numberMask, 10)
+}
+
+// parseFlowMatch returns the table, priority and match fields of the flow, leaving out its
+// actions and the fields ovs-ofctl does not accept when deleting flows strictly. The table
+// and priority default to the ones ovs-ofctl uses when adding the flow.
+func parseFlowMatch(flow string) (string, string, []string) {
+	if i := strings.Index(flow, "actions="); i >= 0 {
+		flow = flow[:i]
+	}
+	table, priority := "0", "32768"
+	var fields []string
+	for _, field := range strings.Split(flow, ",") {
+		field = strings.TrimSpace(field)
+		name, value, _ := strings.Cut(field, "=")
+		switch name {
+		case "", "cookie", "idle_timeout", "hard_timeout", "importance", "send_flow_rem", "check_overlap":
+			continue
+		case "table":
+			table = value
+			continue
+		case "priority":
+			priority = value
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return table, priority, fields
 }
 
 // checkDefaultOpenFlow checks for the existence of default OpenFlow rules and
@@ -93,6 +439,10 @@ func (c *openflowManager) Run(stopChan <-chan struct{}, doneWg *sync.WaitGroup)
 		syncPeriod := 15 * time.Second
 		timer := time.NewTicker(syncPeriod)
 		defer timer.Stop()
+		// only the changes are programmed periodically, all the flows are replaced as soon as
+		// the bridges lost flows, and once in a while in case they were modified behind our back
+		fullSyncPeriod := 10 * time.Minute
+		lastFullSync := time.Now()
 		for {
 			select {
 			case <-timer.C:
@@ -109,9 +459,19 @@ func (c *openflowManager) Run(stopChan <-chan struct{}, doneWg *sync.WaitGroup)
 						continue
 					}
 				}
+				if err := c.checkUplinkBridgePorts(); err != nil {
+					klog.Errorf("Checkports failed %v", err)
+					continue
+				}
+				if time.Since(lastFullSync) < fullSyncPeriod {
+					c.checkFlowsInstalled()
+					c.syncFlowChanges()
+					continue
+				}
 				c.syncFlows()
+				lastFullSync = time.Now()
 			case <-c.flowChan:
-				c.syncFlows()
+				c.syncFlowChanges()
 				timer.Reset(syncPeriod)
 			case <-stopChan:
 				return
@@ -120,6 +480,16 @@ func (c *openflowManager) Run(stopChan <-chan struct{}, doneWg *sync.WaitGroup)
 	}()
 }
 
+func (c *openflowManager) checkUplinkBridgePorts() error {
+	for _, uplink := range c.uplinkBridges {
+		if err := checkPorts(uplink.bridge.patchPort, uplink.bridge.ofPortPatch,
+			uplink.bridge.uplinkName, uplink.bridge.ofPortPhys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func checkPorts(patchIntf, ofPortPatch, physIntf, ofPortPhys string) error {
 	// it could be that the ovn-controller recreated the patch between the host OVS bridge and
 	// the integration bridge, as a result the ofport number changed for that patch interface
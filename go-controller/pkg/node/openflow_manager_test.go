@@ -0,0 +1,181 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("OpenFlow Manager", func() {
+	const (
+		normalFlow   = "table=0,priority=0,actions=NORMAL"
+		nodePortFlow = "cookie=0x453ae29bcbbc08bd, priority=110, in_port=eth0, tcp, tp_dst=30080, actions=output:LOCAL"
+		changedFlow  = "cookie=0x453ae29bcbbc08bd, priority=110, in_port=eth0, tcp, tp_dst=30080, actions=drop"
+		otherFlow    = "cookie=0x77e4ac6fd4e9c8e1, priority=110, in_port=eth0, udp, tp_dst=30053, actions=output:LOCAL"
+		table12Flow  = "cookie=0x10c6b89e483ea111, priority=110, table=12, reg1=0, tcp, nw_dst=5.5.5.5, tp_dst=8080, actions=output:in_port"
+	)
+
+	// byMatch returns the flows by table, priority and match, as installed in a bridge
+	byMatch := func(flows ...string) map[string]string {
+		installed := map[string]string{}
+		for _, flow := range flows {
+			installed[getFlowMatchKey(flow)] = flow
+		}
+		return installed
+	}
+
+	table.DescribeTable("computes the flow modifications",
+		func(installed, wanted []string, expected []string) {
+			Expect(getFlowMods(byMatch(installed...), byMatch(wanted...))).To(Equal(expected))
+		},
+		table.Entry("with nothing to change",
+			[]string{normalFlow, nodePortFlow},
+			[]string{normalFlow, nodePortFlow},
+			nil,
+		),
+		table.Entry("adding new flows",
+			[]string{normalFlow},
+			[]string{normalFlow, nodePortFlow},
+			[]string{"add " + nodePortFlow},
+		),
+		table.Entry("deleting stale flows strictly without their actions",
+			[]string{normalFlow, nodePortFlow},
+			[]string{normalFlow},
+			[]string{"delete_strict table=0, priority=110, in_port=eth0, tcp, tp_dst=30080"},
+		),
+		table.Entry("deleting stale flows of other tables strictly",
+			[]string{normalFlow, table12Flow},
+			[]string{normalFlow},
+			[]string{"delete_strict table=12, priority=110, reg1=0, tcp, nw_dst=5.5.5.5, tp_dst=8080"},
+		),
+		table.Entry("replacing a flow with the same match",
+			[]string{normalFlow, nodePortFlow, otherFlow},
+			[]string{normalFlow, changedFlow},
+			[]string{
+				"delete_strict table=0, priority=110, in_port=eth0, udp, tp_dst=30053",
+				"add " + changedFlow,
+			},
+		),
+	)
+
+	It("programs a single flow per match", func() {
+		flowCache := map[string][]string{
+			"b": {changedFlow},
+			"a": {nodePortFlow, normalFlow},
+		}
+		Expect(getWantedFlows(flowCache)).To(Equal(byMatch(nodePortFlow, normalFlow)))
+
+		// the flow of the other entry is programmed once the first entry goes away
+		delete(flowCache, "a")
+		wanted := getWantedFlows(flowCache)
+		Expect(wanted).To(Equal(byMatch(changedFlow)))
+		Expect(getFlowMods(byMatch(nodePortFlow, normalFlow), wanted)).To(Equal([]string{
+			"delete_strict table=0, priority=0",
+			"add " + changedFlow,
+		}))
+	})
+
+	It("only programs the changes after the first full sync", func() {
+		fexec := ovntest.NewFakeExec()
+		Expect(util.SetExec(fexec)).To(Succeed())
+		fexec.AddFakeCmdsNoOutputNoError([]string{
+			"ovs-ofctl -O OpenFlow13 --bundle replace-flows breth0 -",
+			"ovs-ofctl -O OpenFlow13 --bundle add-flows breth0 -",
+		})
+		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
+			Cmd: "ovs-ofctl -O OpenFlow13 --bundle add-flows breth0 -",
+			Err: fmt.Errorf("failed to apply bundle"),
+		})
+		fexec.AddFakeCmdsNoOutputNoError([]string{
+			"ovs-ofctl -O OpenFlow13 --bundle replace-flows breth0 -",
+		})
+
+		ofm := &openflowManager{
+			defaultBridge: &bridgeConfiguration{bridgeName: "breth0"},
+			flowCache:     map[string][]string{"NORMAL": {normalFlow + "\n"}},
+			flowMutex:     sync.Mutex{},
+			exGWFlowCache: map[string][]string{},
+			flowChan:      make(chan struct{}, 1),
+		}
+
+		// the installed flows are unknown on startup, all the flows are replaced
+		ofm.syncFlowChanges()
+		Expect(ofm.flowsInstalled).To(Equal(byMatch(normalFlow)))
+
+		ofm.updateFlowCacheEntry("NodePort_default_svc_tcp_80", []string{nodePortFlow})
+		ofm.syncFlowChanges()
+		Expect(ofm.flowsInstalled).To(Equal(byMatch(normalFlow, nodePortFlow)))
+
+		// nothing changed, no command is run
+		ofm.syncFlowChanges()
+
+		// failing to apply the changes makes the next sync replace all the flows
+		ofm.deleteFlowsByKey("NodePort_default_svc_tcp_80")
+		ofm.syncFlowChanges()
+		Expect(ofm.flowsInstalled).To(BeNil())
+		ofm.syncFlowChanges()
+		Expect(ofm.flowsInstalled).To(Equal(byMatch(normalFlow)))
+
+		Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
+	})
+
+	table.DescribeTable("identifies the same match written in different ways",
+		func(flow, sameFlow string) {
+			Expect(getFlowMatchKey(flow)).To(Equal(getFlowMatchKey(sameFlow)))
+		},
+		table.Entry("with protocol shorthands",
+			"priority=110, in_port=eth0, tcp, tp_dst=30080, actions=output:LOCAL",
+			"priority=110, in_port=eth0, ip, nw_proto=6, tp_dst=30080, actions=drop",
+		),
+		table.Entry("with field aliases and hexadecimal numbers",
+			"priority=110, in_port=eth0, tcp6, ipv6_dst=fd00::1, tcp_dst=0x50, actions=output:LOCAL",
+			"priority=110, in_port=eth0, dl_type=0x86dd, nw_proto=6, ipv6_dst=fd00:0:0:0:0:0:0:1, tp_dst=80, actions=drop",
+		),
+		table.Entry("with IP masks and MAC addresses in upper case",
+			"priority=100, ip, dl_dst=0A:58:0A:80:00:01, nw_src=10.128.0.0/255.255.0.0, nw_dst=1.1.1.1/32, actions=drop",
+			"priority=100, ip, dl_dst=0a:58:0a:80:00:01, nw_src=10.128.0.0/16, nw_dst=1.1.1.1, actions=drop",
+		),
+		table.Entry("with connection tracking states in another order",
+			"table=1, priority=100, ip, ct_state=+trk+est, actions=drop",
+			"table=1, priority=100, ip, ct_state=+est+trk, actions=drop",
+		),
+	)
+
+	It("replaces all the flows of a bridge that lost flows", func() {
+		fexec := ovntest.NewFakeExec()
+		Expect(util.SetExec(fexec)).To(Succeed())
+		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
+			Cmd: "ovs-ofctl -O OpenFlow13 --no-stats dump-flows breth0",
+			Output: "OFPST_FLOW reply (OF1.3) (xid=0x2):\n" +
+				" cookie=0x453ae29bcbbc08bd, priority=110,tcp,in_port=eth0,tp_dst=30080 actions=LOCAL\n" +
+				" priority=0 actions=NORMAL\n",
+		})
+		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
+			Cmd: "ovs-ofctl -O OpenFlow13 --no-stats dump-flows breth0",
+			Output: "OFPST_FLOW reply (OF1.3) (xid=0x2):\n" +
+				" cookie=0x77e4ac6fd4e9c8e1, priority=110,udp,in_port=eth0,tp_dst=30053 actions=LOCAL\n" +
+				" priority=0 actions=NORMAL\n",
+		})
+		fexec.AddFakeCmd(&ovntest.ExpectedCmd{
+			Cmd:    "ovs-ofctl -O OpenFlow13 --no-stats dump-flows breth0",
+			Output: "OFPST_FLOW reply (OF1.3) (xid=0x2):\n",
+		})
+
+		installed := byMatch(normalFlow, nodePortFlow)
+		Expect(checkBridgeFlowsInstalled("breth0", installed)).To(Equal(installed))
+		// e.g. a flow was replaced behind our back
+		Expect(checkBridgeFlowsInstalled("breth0", installed)).To(BeNil())
+		// e.g. ovs-vswitchd restarted
+		Expect(checkBridgeFlowsInstalled("breth0", installed)).To(BeNil())
+		// no command is run while the installed flows are unknown
+		Expect(checkBridgeFlowsInstalled("breth0", nil)).To(BeNil())
+
+		Expect(fexec.CalledMatchesExpected()).To(BeTrue(), fexec.ErrorDesc)
+	})
+})
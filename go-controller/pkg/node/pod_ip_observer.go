@@ -0,0 +1,465 @@
+package node
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	libovsdbcache "github.com/ovn-org/libovsdb/cache"
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+const (
+	// period to report the observed IPs on the pods
+	podIPObserverReportPeriod = time.Second
+	// period past which an IP not observed anymore on a pod port is forgotten
+	podIPObserverIPTimeout = 10 * time.Minute
+	// the number of IPs observed on a pod port past which new IPs are ignored
+	maxObservedIPsPerPort = 16
+
+	ethTypeARP    = 0x0806
+	ethTypeIPv6   = 0x86dd
+	ipProtoICMPv6 = 58
+	icmpv6TypeNS  = 135
+	icmpv6TypeNA  = 136
+)
+
+// podPort is the port of a local pod on the network
+type podPort struct {
+	podNamespace string
+	podName      string
+	nadName      string
+	mac          string
+}
+
+// podIPObserver learns the IPs of the local pods on an IPAM-less secondary
+// network from the ARP and ND packets they send through the host side of
+// their interfaces, and reports them in the observed IPs annotation of the
+// pods. The IPs not observed anymore for podIPObserverIPTimeout are removed
+// from the annotation.
+//
+// Nothing but the source MAC of the pods is enforced on these networks, so
+// the IPs a pod claims are not trusted beyond first come, first served: an IP
+// is only learnt for a pod if no other pod of the network already claims it,
+// either observed or annotated, until that other pod stops using it.
+type podIPObserver struct {
+	netInfo      util.NetInfo
+	nodeName     string
+	watchFactory factory.NodeWatchFactory
+	kube         kube.Interface
+	ovsClient    libovsdbclient.Client
+	// signals a change of the OVS interfaces of the network
+	portsChanged chan struct{}
+
+	sync.Mutex
+	// the pod ports of the network, by ifindex of their host interface
+	ports map[int]podPort
+	// the IPs the port security of each pod port allows, none if any IP is
+	// allowed
+	allowedIPs map[podPort]sets.Set[string]
+	// the IPs observed on each pod port, with the time they were last observed
+	observedIPs map[podPort]map[string]time.Time
+	// the pod ports with IPs not reported yet
+	unreported sets.Set[podPort]
+	// the IPs claimed by each pod port while already claimed by another pod
+	conflicts map[podPort]sets.Set[string]
+}
+
+func newPodIPObserver(netInfo util.NetInfo, nodeName string, watchFactory factory.NodeWatchFactory,
+	kube kube.Interface, ovsClient libovsdbclient.Client) *podIPObserver {
+	return &podIPObserver{
+		netInfo:      netInfo,
+		nodeName:     nodeName,
+		watchFactory: watchFactory,
+		kube:         kube,
+		ovsClient:    ovsClient,
+		portsChanged: make(chan struct{}, 1),
+		ports:        map[int]podPort{},
+		allowedIPs:   map[podPort]sets.Set[string]{},
+		observedIPs:  map[podPort]map[string]time.Time{},
+		unreported:   sets.New[podPort](),
+		conflicts:    map[podPort]sets.Set[string]{},
+	}
+}
+
+// Start starts observing the ARP and ND packets of the local pods on the
+// network until stopChan is closed
+func (o *podIPObserver) Start(stopChan <-chan struct{}, wg *sync.WaitGroup) error {
+	klog.Infof("Starting pod IP observer of network %s", o.netInfo.GetNetworkName())
+	if err := o.startSnooping(stopChan, wg); err != nil {
+		return fmt.Errorf("failed to snoop ARP and ND packets for network %s: %w", o.netInfo.GetNetworkName(), err)
+	}
+	// the pod ports are refreshed when the OVS interfaces of the network change
+	o.ovsClient.Cache().AddEventHandler(&libovsdbcache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			o.onInterfaceEvent(m)
+		},
+		UpdateFunc: func(table string, _, new model.Model) {
+			o.onInterfaceEvent(new)
+		},
+		DeleteFunc: func(table string, m model.Model) {
+			o.onInterfaceEvent(m)
+		},
+	})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		o.syncPorts()
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-o.portsChanged:
+				o.syncPorts()
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		wait.Until(func() {
+			o.forgetStaleIPs(time.Now())
+			o.report()
+		}, podIPObserverReportPeriod, stopChan)
+	}()
+	return nil
+}
+
+// onInterfaceEvent signals the change of an OVS interface of the network
+func (o *podIPObserver) onInterfaceEvent(m model.Model) {
+	iface, ok := m.(*vswitchd.Interface)
+	if !ok || iface.ExternalIDs[types.NetworkExternalID] != o.netInfo.GetNetworkName() {
+		return
+	}
+	select {
+	case o.portsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// syncPorts refreshes the pod ports of the network from the OVS interfaces of
+// the local pods
+func (o *podIPObserver) syncPorts() {
+	ifaces, err := libovsdbops.FindInterfacesWithPredicate(o.ovsClient, func(iface *vswitchd.Interface) bool {
+		return iface.ExternalIDs[types.NetworkExternalID] == o.netInfo.GetNetworkName()
+	})
+	if err != nil {
+		klog.Errorf("Failed to list the OVS interfaces of network %s: %v", o.netInfo.GetNetworkName(), err)
+		return
+	}
+
+	pods, err := o.watchFactory.GetPods("")
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		return
+	}
+	localPods := map[string]*kapi.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == o.nodeName && !util.PodWantsHostNetwork(pod) {
+			localPods[string(pod.UID)] = pod
+		}
+	}
+
+	ports := map[int]podPort{}
+	allowedIPs := map[podPort]sets.Set[string]{}
+	for _, iface := range ifaces {
+		pod, ok := localPods[iface.ExternalIDs["iface-id-ver"]]
+		if !ok {
+			continue
+		}
+		link, err := net.InterfaceByName(iface.Name)
+		if err != nil {
+			klog.V(5).Infof("Failed to find interface %s of pod %s/%s: %v", iface.Name, pod.Namespace, pod.Name, err)
+			continue
+		}
+		port := podPort{
+			podNamespace: pod.Namespace,
+			podName:      pod.Name,
+			nadName:      iface.ExternalIDs[types.NADExternalID],
+			mac:          iface.ExternalIDs["attached_mac"],
+		}
+		ports[link.Index] = port
+		allowedIPs[port] = getPodPortAllowedIPs(pod, port.nadName)
+	}
+	o.setPorts(ports, allowedIPs)
+}
+
+// getPodPortAllowedIPs returns the IPs annotated on the pod for the NAD, the
+// only ones the port security of its port allows, if any
+func getPodPortAllowedIPs(pod *kapi.Pod, nadName string) sets.Set[string] {
+	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName)
+	if err != nil || len(podAnnotation.IPs) == 0 {
+		return nil
+	}
+	ips := sets.New[string]()
+	for _, ip := range podAnnotation.IPs {
+		ips.Insert(ip.IP.String())
+	}
+	return ips
+}
+
+// setPorts sets the pod ports of the network and the IPs they allow, and
+// forgets the IPs observed on the ports that are gone or not allowed anymore
+func (o *podIPObserver) setPorts(ports map[int]podPort, allowedIPs map[podPort]sets.Set[string]) {
+	o.Lock()
+	defer o.Unlock()
+	o.ports = ports
+	o.allowedIPs = allowedIPs
+	for port := range o.conflicts {
+		if _, ok := allowedIPs[port]; !ok {
+			delete(o.conflicts, port)
+		}
+	}
+	for port, ips := range o.observedIPs {
+		if _, ok := allowedIPs[port]; !ok {
+			delete(o.observedIPs, port)
+			o.unreported.Delete(port)
+			continue
+		}
+		for ip := range ips {
+			if !o.isAllowedIP(port, net.ParseIP(ip)) {
+				delete(ips, ip)
+				o.unreported.Insert(port)
+			}
+		}
+	}
+}
+
+// isAllowedIP returns true if the pod port can use the IP: a global unicast
+// IP, in the subnets of the network if it has any, and among the IPs the port
+// security of the port allows if it restricts them. Must be called with the
+// lock held.
+func (o *podIPObserver) isAllowedIP(port podPort, ip net.IP) bool {
+	if ip == nil || !ip.IsGlobalUnicast() {
+		return false
+	}
+	if subnets := o.netInfo.Subnets(); len(subnets) > 0 {
+		inSubnets := false
+		for _, subnet := range subnets {
+			if subnet.CIDR.Contains(ip) {
+				inSubnets = true
+				break
+			}
+		}
+		if !inSubnets {
+			return false
+		}
+	}
+	for _, subnet := range o.netInfo.ExcludeSubnets() {
+		if subnet.Contains(ip) {
+			return false
+		}
+	}
+	if allowedIPs := o.allowedIPs[port]; allowedIPs.Len() > 0 && !allowedIPs.Has(ip.String()) {
+		return false
+	}
+	return true
+}
+
+// observe records the IP claimed by the ARP or ND frame received on the host
+// interface of ifindex, if any
+func (o *podIPObserver) observe(ifindex int, frame []byte) {
+	o.Lock()
+	defer o.Unlock()
+	port, ok := o.ports[ifindex]
+	if !ok {
+		return
+	}
+	mac, err := net.ParseMAC(port.mac)
+	if err != nil {
+		return
+	}
+	ip := getObservedIP(frame, mac)
+	if !o.isAllowedIP(port, ip) {
+		return
+	}
+	ips := o.observedIPs[port]
+	if ips == nil {
+		ips = map[string]time.Time{}
+		o.observedIPs[port] = ips
+	}
+	if _, ok := ips[ip.String()]; ok {
+		ips[ip.String()] = time.Now()
+		return
+	}
+	if len(ips) >= maxObservedIPsPerPort {
+		klog.V(5).Infof("Ignoring IP %s observed on pod %s/%s for NAD %s: too many IPs observed",
+			ip, port.podNamespace, port.podName, port.nadName)
+		return
+	}
+	if claimant := o.getOtherClaimant(port, ip); claimant != "" {
+		if o.conflicts[port] == nil {
+			o.conflicts[port] = sets.New[string]()
+		}
+		if !o.conflicts[port].Has(ip.String()) {
+			o.conflicts[port].Insert(ip.String())
+			klog.Warningf("Ignoring IP %s observed on pod %s/%s for NAD %s: already claimed by pod %s",
+				ip, port.podNamespace, port.podName, port.nadName, claimant)
+		}
+		return
+	}
+	o.conflicts[port].Delete(ip.String())
+	klog.V(4).Infof("Observed IP %s on pod %s/%s for NAD %s", ip, port.podNamespace, port.podName, port.nadName)
+	ips[ip.String()] = time.Now()
+	o.unreported.Insert(port)
+}
+
+// getOtherClaimant returns the pod, other than the one of the port, that
+// already claims the IP on the network: observed on another local pod port,
+// or in the observed or annotated IPs of another pod of the network. Must be
+// called with the lock held.
+func (o *podIPObserver) getOtherClaimant(port podPort, ip net.IP) string {
+	isPortPod := func(namespace, name string) bool {
+		return namespace == port.podNamespace && name == port.podName
+	}
+	for otherPort, ips := range o.observedIPs {
+		if isPortPod(otherPort.podNamespace, otherPort.podName) {
+			continue
+		}
+		if _, ok := ips[ip.String()]; ok {
+			return otherPort.podNamespace + "/" + otherPort.podName
+		}
+	}
+
+	pods, err := o.watchFactory.GetPods("")
+	if err != nil {
+		// without knowing the other claims, do not learn the IP
+		klog.Errorf("Failed to list pods to check the claims on IP %s: %v", ip, err)
+		return "unknown"
+	}
+	for _, pod := range pods {
+		if isPortPod(pod.Namespace, pod.Name) || util.PodCompleted(pod) {
+			continue
+		}
+		observedIPs, _ := util.UnmarshalPodObservedIPsAllNetworks(pod.Annotations)
+		for nadName, ipStrs := range observedIPs {
+			if !o.netInfo.HasNAD(nadName) {
+				continue
+			}
+			for _, ipStr := range ipStrs {
+				if ipStr == ip.String() {
+					return pod.Namespace + "/" + pod.Name
+				}
+			}
+		}
+		podAnnotations, _ := util.UnmarshalPodAnnotationAllNetworks(pod.Annotations)
+		for nadName := range podAnnotations {
+			if !o.netInfo.HasNAD(nadName) {
+				continue
+			}
+			podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, nadName)
+			if err != nil {
+				continue
+			}
+			for _, podIP := range podAnnotation.IPs {
+				if podIP.IP.Equal(ip) {
+					return pod.Namespace + "/" + pod.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// forgetStaleIPs forgets the IPs not observed for podIPObserverIPTimeout
+// before now
+func (o *podIPObserver) forgetStaleIPs(now time.Time) {
+	o.Lock()
+	defer o.Unlock()
+	for port, ips := range o.observedIPs {
+		for ip, lastObserved := range ips {
+			if now.Sub(lastObserved) > podIPObserverIPTimeout {
+				klog.V(4).Infof("Forgetting IP %s of pod %s/%s for NAD %s: not observed since %v",
+					ip, port.podNamespace, port.podName, port.nadName, lastObserved)
+				delete(ips, ip)
+				o.unreported.Insert(port)
+			}
+		}
+	}
+}
+
+// report sets the observed IPs annotation of the pods with IPs not reported
+// yet
+func (o *podIPObserver) report() {
+	o.Lock()
+	unreported := map[podPort][]net.IP{}
+	for port := range o.unreported {
+		ips := make([]net.IP, 0, len(o.observedIPs[port]))
+		for ip := range o.observedIPs[port] {
+			ips = append(ips, net.ParseIP(ip))
+		}
+		unreported[port] = ips
+	}
+	o.unreported = sets.New[podPort]()
+	o.Unlock()
+
+	for port, ips := range unreported {
+		pod, err := o.watchFactory.GetPod(port.podNamespace, port.podName)
+		if err != nil {
+			klog.V(5).Infof("Failed to get pod %s/%s to report its observed IPs: %v", port.podNamespace, port.podName, err)
+			continue
+		}
+		err = util.UpdatePodObservedIPsWithRetry(o.watchFactory.PodCoreInformer().Lister(), o.kube, pod, ips, port.nadName)
+		if err != nil {
+			klog.Errorf("Failed to report the observed IPs %v of pod %s/%s for NAD %s: %v",
+				ips, port.podNamespace, port.podName, port.nadName, err)
+			o.Lock()
+			if _, ok := o.observedIPs[port]; ok {
+				o.unreported.Insert(port)
+			}
+			o.Unlock()
+		}
+	}
+}
+
+// getObservedIP returns the IP the sender of the ARP or ND frame claims, if
+// the frame was sent from mac. ARP requests and replies claim their sender
+// address, neighbor solicitations their source address, and neighbor
+// advertisements their target address.
+func getObservedIP(frame []byte, mac net.HardwareAddr) net.IP {
+	const ethHeaderLen = 14
+	if len(frame) < ethHeaderLen || !bytes.Equal(frame[6:12], mac) {
+		return nil
+	}
+	switch binary.BigEndian.Uint16(frame[12:14]) {
+	case ethTypeARP:
+		arp := frame[ethHeaderLen:]
+		// htype, ptype, hlen, plen, oper, sha, spa, tha, tpa
+		if len(arp) < 28 ||
+			binary.BigEndian.Uint16(arp[0:2]) != 1 ||
+			binary.BigEndian.Uint16(arp[2:4]) != 0x0800 ||
+			arp[4] != 6 || arp[5] != 4 ||
+			!bytes.Equal(arp[8:14], mac) {
+			return nil
+		}
+		return net.IP(append([]byte(nil), arp[14:18]...))
+	case ethTypeIPv6:
+		ipv6 := frame[ethHeaderLen:]
+		// the ICMPv6 header and the target address follow the fixed header
+		if len(ipv6) < 40+24 || ipv6[6] != ipProtoICMPv6 {
+			return nil
+		}
+		switch ipv6[40] {
+		case icmpv6TypeNS:
+			return net.IP(append([]byte(nil), ipv6[8:24]...))
+		case icmpv6TypeNA:
+			return net.IP(append([]byte(nil), ipv6[48:64]...))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+//go:build linux
+// +build linux
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// snoopFilter accepts the ARP packets and the ICMPv6 neighbor solicitations
+// and advertisements not carried in IPv6 extension headers
+var snoopFilter = []bpf.Instruction{
+	// ethertype
+	bpf.LoadAbsolute{Off: 12, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: ethTypeARP, SkipFalse: 1},
+	bpf.RetConstant{Val: 0xffff},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: ethTypeIPv6, SkipFalse: 5},
+	// IPv6 next header
+	bpf.LoadAbsolute{Off: 20, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: ipProtoICMPv6, SkipFalse: 3},
+	// ICMPv6 type
+	bpf.LoadAbsolute{Off: 54, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: icmpv6TypeNS, SkipTrue: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: icmpv6TypeNA, SkipTrue: 1},
+	bpf.RetConstant{Val: 0},
+	bpf.RetConstant{Val: 0xffff},
+}
+
+func htons(i uint16) uint16 {
+	return (i<<8)&0xff00 | i>>8
+}
+
+// startSnooping opens a packet socket receiving the ARP and ND packets of all
+// the interfaces of the host, and hands over the ones received from the pods
+// to observe
+func (o *podIPObserver) startSnooping(stopChan <-chan struct{}, wg *sync.WaitGroup) error {
+	rawFilter, err := bpf.Assemble(snoopFilter)
+	if err != nil {
+		return fmt.Errorf("failed to assemble packet filter: %w", err)
+	}
+	filter := make([]unix.SockFilter, 0, len(rawFilter))
+	for _, ins := range rawFilter {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_ALL)))
+	if err != nil {
+		return fmt.Errorf("failed to open packet socket: %w", err)
+	}
+	prog := unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: (*unix.SockFilter)(unsafe.Pointer(&filter[0])),
+	}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &prog); err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("failed to attach packet filter: %w", err)
+	}
+	// wake up periodically to check whether to stop
+	timeout := unix.NsecToTimeval(podIPObserverReportPeriod.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("failed to set packet socket timeout: %w", err)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unix.Close(fd)
+		// ARP and ND headers fit in a small buffer, longer frames are truncated
+		buf := make([]byte, 256)
+		for {
+			select {
+			case <-stopChan:
+				return
+			default:
+			}
+			n, from, err := unix.Recvfrom(fd, buf, 0)
+			if err != nil {
+				if !errors.Is(err, unix.EAGAIN) && !errors.Is(err, unix.EINTR) {
+					klog.Errorf("Failed to receive packets for network %s: %v", o.netInfo.GetNetworkName(), err)
+				}
+				continue
+			}
+			ll, ok := from.(*unix.SockaddrLinklayer)
+			// skip the packets sent to the pods
+			if !ok || ll.Pkttype == unix.PACKET_OUTGOING {
+				continue
+			}
+			o.observe(ll.Ifindex, buf[:n])
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package node
+
+import (
+	"encoding/binary"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/bpf"
+)
+
+var _ = Describe("Pod IP observer packet filter", func() {
+	podMAC, _ := net.ParseMAC("0a:58:c0:a8:0a:05")
+
+	It("only accepts ARP, neighbor solicitations and neighbor advertisements", func() {
+		vm, err := bpf.NewVM(snoopFilter)
+		Expect(err).NotTo(HaveOccurred())
+
+		udp := newNDFrame(podMAC, 0, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::1"))
+		udp[14+6] = 17
+		ipv4 := newARPFrame(podMAC, net.ParseIP("192.168.10.5"))
+		binary.BigEndian.PutUint16(ipv4[12:14], 0x0800)
+
+		tests := []struct {
+			desc     string
+			frame    []byte
+			accepted bool
+		}{
+			{"ARP", newARPFrame(podMAC, net.ParseIP("192.168.10.5")), true},
+			{"neighbor solicitation", newNDFrame(podMAC, icmpv6TypeNS, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::1")), true},
+			{"neighbor advertisement", newNDFrame(podMAC, icmpv6TypeNA, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::5")), true},
+			{"ICMPv6 echo request", newNDFrame(podMAC, 128, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::1")), false},
+			{"IPv6 UDP", udp, false},
+			{"IPv4", ipv4, false},
+		}
+		for _, tc := range tests {
+			n, err := vm.Run(tc.frame)
+			Expect(err).NotTo(HaveOccurred(), tc.desc)
+			Expect(n > 0).To(Equal(tc.accepted), tc.desc)
+		}
+	})
+})
@@ -0,0 +1,312 @@
+package node
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+// newARPFrame returns an ARP frame sent from mac claiming ip
+func newARPFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	frame := make([]byte, 14+28)
+	copy(frame[0:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], ethTypeARP)
+	arp := frame[14:]
+	binary.BigEndian.PutUint16(arp[0:2], 1)
+	binary.BigEndian.PutUint16(arp[2:4], 0x0800)
+	arp[4], arp[5] = 6, 4
+	binary.BigEndian.PutUint16(arp[6:8], 1)
+	copy(arp[8:14], mac)
+	copy(arp[14:18], ip.To4())
+	copy(arp[24:28], net.ParseIP("192.168.10.1").To4())
+	return frame
+}
+
+// newNDFrame returns an ICMPv6 frame of the given type sent from mac
+func newNDFrame(mac net.HardwareAddr, icmpType byte, src, target net.IP) []byte {
+	frame := make([]byte, 14+40+24)
+	copy(frame[0:6], []byte{0x33, 0x33, 0xff, 0x00, 0x00, 0x01})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], ethTypeIPv6)
+	ipv6 := frame[14:]
+	ipv6[0] = 0x60
+	binary.BigEndian.PutUint16(ipv6[4:6], 24)
+	ipv6[6] = ipProtoICMPv6
+	ipv6[7] = 255
+	copy(ipv6[8:24], src.To16())
+	copy(ipv6[24:40], net.ParseIP("ff02::1:ff00:1").To16())
+	ipv6[40] = icmpType
+	copy(ipv6[48:64], target.To16())
+	return frame
+}
+
+var _ = Describe("Pod IP observer", func() {
+	podMAC, _ := net.ParseMAC("0a:58:c0:a8:0a:05")
+	otherMAC, _ := net.ParseMAC("0a:58:c0:a8:0a:06")
+
+	table.DescribeTable("gets the IP claimed by the frame",
+		func(frame []byte, expectedIP string) {
+			ip := getObservedIP(frame, podMAC)
+			if expectedIP == "" {
+				Expect(ip).To(BeNil())
+			} else {
+				Expect(ip.String()).To(Equal(expectedIP))
+			}
+		},
+		table.Entry("ARP sender", newARPFrame(podMAC, net.ParseIP("192.168.10.5")), "192.168.10.5"),
+		table.Entry("ARP from another MAC", newARPFrame(otherMAC, net.ParseIP("192.168.10.5")), ""),
+		table.Entry("neighbor solicitation source",
+			newNDFrame(podMAC, icmpv6TypeNS, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::1")), "2001:db8::5"),
+		table.Entry("neighbor advertisement target",
+			newNDFrame(podMAC, icmpv6TypeNA, net.ParseIP("fe80::5"), net.ParseIP("2001:db8::5")), "2001:db8::5"),
+		table.Entry("router solicitation",
+			newNDFrame(podMAC, 133, net.ParseIP("2001:db8::5"), net.ParseIP("2001:db8::1")), ""),
+		table.Entry("truncated frame", newARPFrame(podMAC, net.ParseIP("192.168.10.5"))[:30], ""),
+	)
+
+	newNetInfo := func(topology, subnets, excludeSubnets string) util.NetInfo {
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:        cnitypes.NetConf{Name: "localnet", Type: "ovn-k8s-cni-overlay"},
+			Topology:       topology,
+			NADName:        "ns1/localnet",
+			Subnets:        subnets,
+			ExcludeSubnets: excludeSubnets,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return netInfo
+	}
+
+	newWatchFactory := func(objects ...runtime.Object) (*fake.Clientset, *factory.WatchFactory) {
+		kubeClient := fake.NewSimpleClientset(objects...)
+		wf, err := factory.NewNodeWatchFactory(&util.OVNNodeClientset{KubeClient: kubeClient}, "node1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wf.Start()).To(Succeed())
+		return kubeClient, wf
+	}
+
+	It("reports the IPs observed on the pod ports", func() {
+		const (
+			nodeName = "node1"
+			nadName  = "ns1/localnet"
+			ifindex  = 10
+		)
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", UID: "pod1-uid"},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+		}
+		kubeClient, wf := newWatchFactory(pod)
+		defer wf.Shutdown()
+
+		observer := newPodIPObserver(newNetInfo(types.LocalnetTopology, "", ""), nodeName, wf,
+			&kube.Kube{KClient: kubeClient}, nil)
+		port := podPort{podNamespace: "ns1", podName: "pod1", nadName: nadName, mac: podMAC.String()}
+		observer.ports = map[int]podPort{ifindex: port}
+
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.5")))
+		observer.observe(ifindex, newNDFrame(podMAC, icmpv6TypeNA, net.ParseIP("fe80::5"), net.ParseIP("2001:db8::5")))
+		// link local, unspecified and spoofed addresses, and frames of other
+		// interfaces are ignored
+		observer.observe(ifindex, newNDFrame(podMAC, icmpv6TypeNA, net.ParseIP("fe80::5"), net.ParseIP("fe80::5")))
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("0.0.0.0")))
+		observer.observe(ifindex, newARPFrame(otherMAC, net.ParseIP("192.168.10.6")))
+		observer.observe(ifindex+1, newARPFrame(podMAC, net.ParseIP("192.168.10.7")))
+		Expect(observer.unreported.Has(port)).To(BeTrue())
+
+		// the informer is up to date before the next report
+		getAnnotation := func() string {
+			pod, err := wf.GetPod("ns1", "pod1")
+			Expect(err).NotTo(HaveOccurred())
+			return pod.Annotations[util.OvnPodObservedIPsAnnotationName]
+		}
+		observer.report()
+		Expect(observer.unreported.Len()).To(Equal(0))
+		Eventually(getAnnotation).Should(Equal(`{"ns1/localnet":["192.168.10.5","2001:db8::5"]}`))
+
+		// the IPs not observed for a while are forgotten
+		observer.forgetStaleIPs(time.Now())
+		Expect(observer.unreported.Len()).To(Equal(0))
+		observer.observedIPs[port]["2001:db8::5"] = time.Now().Add(-2 * podIPObserverIPTimeout)
+		observer.forgetStaleIPs(time.Now())
+		Expect(observer.unreported.Has(port)).To(BeTrue())
+		observer.report()
+		Eventually(getAnnotation).Should(Equal(`{"ns1/localnet":["192.168.10.5"]}`))
+
+		observer.forgetStaleIPs(time.Now().Add(2 * podIPObserverIPTimeout))
+		observer.report()
+		Eventually(getAnnotation).Should(Equal(`{}`))
+
+		// the number of IPs observed on a port is bounded
+		for i := 0; i < 2*maxObservedIPsPerPort; i++ {
+			observer.observe(ifindex, newARPFrame(podMAC, net.IPv4(192, 168, 11, byte(i))))
+		}
+		Expect(observer.observedIPs[port]).To(HaveLen(maxObservedIPsPerPort))
+	})
+
+	It("only observes the IPs in the subnets of the network", func() {
+		const ifindex = 10
+		_, wf := newWatchFactory()
+		defer wf.Shutdown()
+		observer := newPodIPObserver(newNetInfo(types.Layer2Topology, "192.168.10.0/24", "192.168.10.128/25"),
+			"node1", wf, nil, nil)
+		port := podPort{podNamespace: "ns1", podName: "pod1", nadName: "ns1/localnet", mac: podMAC.String()}
+		observer.ports = map[int]podPort{ifindex: port}
+
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.11.5")))
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.200")))
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.5")))
+		Expect(observer.observedIPs[port]).To(HaveLen(1))
+		Expect(observer.observedIPs[port]).To(HaveKey("192.168.10.5"))
+	})
+
+	It("only observes the IPs allowed on the pod ports", func() {
+		const ifindex = 10
+		_, wf := newWatchFactory()
+		defer wf.Shutdown()
+		observer := newPodIPObserver(newNetInfo(types.LocalnetTopology, "", ""), "node1", wf, nil, nil)
+		port := podPort{podNamespace: "ns1", podName: "pod1", nadName: "ns1/localnet", mac: podMAC.String()}
+		observer.setPorts(map[int]podPort{ifindex: port},
+			map[podPort]sets.Set[string]{port: sets.New[string]("192.168.10.5", "2001:db8::5")})
+
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.6")))
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.5")))
+		Expect(observer.observedIPs[port]).To(HaveLen(1))
+		Expect(observer.observedIPs[port]).To(HaveKey("192.168.10.5"))
+		observer.unreported = sets.New[podPort]()
+
+		// the IPs not allowed anymore are forgotten
+		observer.setPorts(map[int]podPort{ifindex: port},
+			map[podPort]sets.Set[string]{port: sets.New[string]("192.168.10.6")})
+		Expect(observer.observedIPs[port]).To(BeEmpty())
+		Expect(observer.unreported.Has(port)).To(BeTrue())
+
+		// as are the ports that are gone
+		observer.setPorts(map[int]podPort{}, map[podPort]sets.Set[string]{})
+		Expect(observer.observedIPs).To(BeEmpty())
+		Expect(observer.unreported.Len()).To(Equal(0))
+	})
+
+	It("does not observe the IPs claimed by other pods of the network", func() {
+		const ifindex = 10
+		remotePod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "remote",
+				Namespace: "ns1",
+				Annotations: map[string]string{
+					util.OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.7"],"ns1/other":["192.168.10.8"]}`,
+				},
+			},
+			Spec: v1.PodSpec{NodeName: "node2"},
+		}
+		_, wf := newWatchFactory(remotePod)
+		defer wf.Shutdown()
+		netInfo := newNetInfo(types.LocalnetTopology, "", "")
+		netInfo.AddNAD("ns1/localnet")
+		observer := newPodIPObserver(netInfo, "node1", wf, nil, nil)
+		port1 := podPort{podNamespace: "ns1", podName: "pod1", nadName: "ns1/localnet", mac: podMAC.String()}
+		port2 := podPort{podNamespace: "ns1", podName: "pod2", nadName: "ns1/localnet", mac: otherMAC.String()}
+		observer.setPorts(map[int]podPort{ifindex: port1, ifindex + 1: port2},
+			map[podPort]sets.Set[string]{port1: nil, port2: nil})
+		Eventually(func() error {
+			_, err := wf.GetPod("ns1", "remote")
+			return err
+		}).Should(Succeed())
+
+		// the IPs observed on another local port
+		observer.observe(ifindex, newARPFrame(podMAC, net.ParseIP("192.168.10.5")))
+		observer.observe(ifindex+1, newARPFrame(otherMAC, net.ParseIP("192.168.10.5")))
+		Expect(observer.observedIPs[port1]).To(HaveKey("192.168.10.5"))
+		Expect(observer.observedIPs[port2]).To(BeEmpty())
+		Expect(observer.conflicts[port2].Has("192.168.10.5")).To(BeTrue())
+
+		// and the IPs observed on a remote pod of the network are ignored,
+		// not the ones of other networks
+		observer.observe(ifindex+1, newARPFrame(otherMAC, net.ParseIP("192.168.10.7")))
+		observer.observe(ifindex+1, newARPFrame(otherMAC, net.ParseIP("192.168.10.8")))
+		Expect(observer.observedIPs[port2]).To(HaveLen(1))
+		Expect(observer.observedIPs[port2]).To(HaveKey("192.168.10.8"))
+
+		// once forgotten by the other pod, the IP can be claimed
+		observer.forgetStaleIPs(time.Now().Add(2 * podIPObserverIPTimeout))
+		observer.observe(ifindex+1, newARPFrame(otherMAC, net.ParseIP("192.168.10.5")))
+		Expect(observer.observedIPs[port2]).To(HaveKey("192.168.10.5"))
+		Expect(observer.conflicts[port2].Has("192.168.10.5")).To(BeFalse())
+	})
+
+	It("gets the pod ports from the OVS interfaces of the network", func() {
+		const nodeName = "node1"
+		lo, err := net.InterfaceByName("lo")
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				UID:       "pod1-uid",
+				Annotations: map[string]string{
+					util.OvnPodAnnotationName: `{"ns1/localnet":{"ip_addresses":["192.168.10.5/24"],"mac_address":"0a:58:c0:a8:0a:05"}}`,
+				},
+			},
+			Spec: v1.PodSpec{NodeName: nodeName},
+		}
+		kubeClient, wf := newWatchFactory(pod)
+		defer wf.Shutdown()
+
+		ovsClient, libovsdbCleanup, err := libovsdbtest.NewOVSTestHarness(libovsdbtest.TestSetup{
+			OVSData: []libovsdbtest.TestData{
+				&vswitchd.Interface{
+					UUID: "iface-pod1",
+					Name: lo.Name,
+					ExternalIDs: map[string]string{
+						"iface-id-ver":          "pod1-uid",
+						"attached_mac":          podMAC.String(),
+						types.NetworkExternalID: "localnet",
+						types.NADExternalID:     "ns1/localnet",
+					},
+				},
+				&vswitchd.Interface{
+					UUID: "iface-other",
+					Name: "other",
+					ExternalIDs: map[string]string{
+						"iface-id-ver":          "pod1-uid",
+						types.NetworkExternalID: "other",
+					},
+				},
+			},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer libovsdbCleanup.Cleanup()
+
+		observer := newPodIPObserver(newNetInfo(types.LocalnetTopology, "", ""), nodeName, wf,
+			&kube.Kube{KClient: kubeClient}, ovsClient)
+		observer.syncPorts()
+		port := podPort{podNamespace: "ns1", podName: "pod1", nadName: "ns1/localnet", mac: podMAC.String()}
+		Expect(observer.ports).To(Equal(map[int]podPort{lo.Index: port}))
+		Expect(observer.allowedIPs).To(Equal(map[podPort]sets.Set[string]{port: sets.New[string]("192.168.10.5")}))
+
+		// the changes of the OVS interfaces of the network are signaled
+		observer.onInterfaceEvent(&vswitchd.Interface{ExternalIDs: map[string]string{types.NetworkExternalID: "other"}})
+		Consistently(observer.portsChanged).ShouldNot(Receive())
+		observer.onInterfaceEvent(&vswitchd.Interface{ExternalIDs: map[string]string{types.NetworkExternalID: "localnet"}})
+		Eventually(observer.portsChanged).Should(Receive())
+	})
+})
@@ -13,6 +13,20 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// RouteProtocol is the routing protocol number of the routes applied by the route manager. It tells them apart from the
+// routes of other route managers like NetworkManager, which are never replaced nor deleted.
+const RouteProtocol netlink.RouteProtocol = 84
+
+// isManagedRouteProtocol returns true if a route of protocol p may be owned by the route manager. Previous versions of the
+// route manager added routes without protocol, which the kernel sets to boot, so these are taken over when applied or
+// when deleted while in the store.
+func isManagedRouteProtocol(p netlink.RouteProtocol, takeOver bool) bool {
+	return p == RouteProtocol || (takeOver && p == unix.RTPROT_BOOT)
+}
+
+// ipv6DefaultRoutePriority is the priority the kernel sets on IPv6 routes added without priority
+const ipv6DefaultRoutePriority = 1024
+
 type Controller struct {
 	// netlinkOps is used to swap netlink lib with a mock lib in order to allow consumers to test without creating netns
 	netlinkOps NetLinkOps
@@ -93,6 +107,7 @@ func (c *Controller) addRoutesPerLink(rl RoutesPerLink) error {
 	if err := rl.validate(); err != nil {
 		return fmt.Errorf("failed to validate addition of new routes for link (%s): %w", rl.String(), err)
 	}
+	rl = rl.withNextHopLinks()
 	if err := c.addRoutesPerLinkStore(rl); err != nil {
 		return fmt.Errorf("failed to add route for link to store: %w", err)
 	}
@@ -108,13 +123,7 @@ func (c *Controller) delRoutesPerLink(rl RoutesPerLink) error {
 	if err := rl.validate(); err != nil {
 		return fmt.Errorf("failed to validate route for link (%s): %v", rl.String(), err)
 	}
-	var deletedRoutes []Route
-	for _, r := range rl.Routes {
-		if err := c.netlinkDelRoute(rl.Link, r.Subnet, r.Table); err != nil {
-			return err
-		}
-		deletedRoutes = append(deletedRoutes, r)
-	}
+	rl = rl.withNextHopLinks()
 	infName, err := rl.getLinkName()
 	if err != nil {
 		return fmt.Errorf("failed to delete route (%+v) because we failed to get link name: %v",
@@ -124,6 +133,14 @@ func (c *Controller) delRoutesPerLink(rl RoutesPerLink) error {
 	if !ok {
 		routesPerLinkFound = RoutesPerLink{rl.Link, []Route{}}
 	}
+	var deletedRoutes []Route
+	for _, r := range rl.Routes {
+		// the routes in the store are owned by the route manager even if added without protocol by a previous version
+		if err := c.netlinkDelRoute(rl.Link, r, routesPerLinkFound.hasRoute(r)); err != nil {
+			return err
+		}
+		deletedRoutes = append(deletedRoutes, r)
+	}
 	if len(deletedRoutes) > 0 {
 		routesPerLinkFound.delRoutes(deletedRoutes)
 	}
@@ -149,9 +166,22 @@ func (c *Controller) processNetlinkEvent(ru netlink.RouteUpdate) error {
 		return nil
 	}
 	klog.V(5).Infof("Route Manager: netlink route deletion event: %q", ru.String())
-	link, err := c.netlinkOps.LinkByIndex(ru.LinkIndex)
+	if ru.LinkIndex == 0 && len(ru.MultiPath) > 0 {
+		// multipath routes have no output interface, their next hops do and any of them may be the managed link
+		for _, nextHop := range ru.MultiPath {
+			if err := c.processNetlinkEventForLink(ru, nextHop.LinkIndex); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.processNetlinkEventForLink(ru, ru.LinkIndex)
+}
+
+func (c *Controller) processNetlinkEventForLink(ru netlink.RouteUpdate, linkIndex int) error {
+	link, err := c.netlinkOps.LinkByIndex(linkIndex)
 	if err != nil {
-		return fmt.Errorf("failed to get link by index %d for netlink route event (%s): %w", ru.LinkIndex, ru.String(), err)
+		return fmt.Errorf("failed to get link by index %d for netlink route event (%s): %w", linkIndex, ru.String(), err)
 	}
 	rlEvent, err := convertRouteUpdateToRoutesPerLink(link, ru)
 	if err != nil {
@@ -188,7 +218,7 @@ func (c *Controller) processNetlinkEvent(ru netlink.RouteUpdate) error {
 
 func (c *Controller) applyRoutesPerLink(rl RoutesPerLink) error {
 	for _, r := range rl.Routes {
-		if err := c.applyRoute(rl.Link, r.GwIP, r.Subnet, r.MTU, r.SrcIP, r.Table); err != nil {
+		if err := c.applyRoute(rl.Link, r); err != nil {
 			return fmt.Errorf("failed to apply route (%s) because of error: %v", r.String(), err)
 		}
 	}
@@ -197,83 +227,111 @@ func (c *Controller) applyRoutesPerLink(rl RoutesPerLink) error {
 
 var _, defaultRouteIPNet, _ = net.ParseCIDR("0.0.0.0/0")
 
-func (c *Controller) applyRoute(link netlink.Link, gwIP net.IP, subnet *net.IPNet, mtu int, src net.IP, table int) error {
-	var filterRoute *netlink.Route
-	var filterMask uint64
+func (c *Controller) applyRoute(link netlink.Link, r Route) error {
 	// netlink library contains an issue where we cannot filter by a default route with dst 0.0.0.0/0 because netlink library represents
 	// a default route with dst is nil therefore filter by table only
-	isDefaultRoute := subnet != nil && subnet.String() == defaultRouteIPNet.String()
-	if isDefaultRoute {
-		filterRoute, filterMask = filterRouteByTable(link.Attrs().Index, table)
-	} else {
-		filterRoute, filterMask = filterRouteByDstAndTable(link.Attrs().Index, subnet, table)
+	isDefaultRoute := r.Subnet != nil && r.Subnet.String() == defaultRouteIPNet.String()
+	var subnet *net.IPNet
+	if !isDefaultRoute {
+		subnet = r.Subnet
 	}
-
-	nlRoutes, err := c.netlinkOps.RouteListFiltered(getNetlinkIPFamily(subnet), filterRoute, filterMask)
+	nlRoutes, err := c.listRoutes(link, getNetlinkIPFamily(r.Subnet), subnet, r.Table)
 	if err != nil {
 		return fmt.Errorf("failed to list filtered routes: %v", err)
 	}
-	if len(nlRoutes) == 0 {
-		return c.netlinkAddRoute(link, gwIP, subnet, mtu, src, table)
-	}
-	// remove when netlink library is fixed
-	// currently, the library we use to interact with netlink represents a route with destination 0.0.0.0/0 represented as nil in field dst
-	if isDefaultRoute {
-		temp := nlRoutes[:0]
-		for _, nlRoute := range nlRoutes {
-			if nlRoute.Dst == nil && nlRoute.Src == nil && nlRoute.Gw == nil {
-				temp = append(temp, nlRoute)
-			}
+	temp := nlRoutes[:0]
+	for _, nlRoute := range nlRoutes {
+		// remove when netlink library is fixed
+		// currently, the library we use to interact with netlink represents a route with destination 0.0.0.0/0 represented as nil in field dst
+		if isDefaultRoute && nlRoute.Dst != nil {
+			continue
 		}
-		nlRoutes = temp
+		// routes with another priority are other routes
+		if nlRoute.Priority != r.kernelPriority() {
+			continue
+		}
+		temp = append(temp, nlRoute)
+	}
+	nlRoutes = temp
+	if len(nlRoutes) == 0 {
+		return c.netlinkAddRoute(link, r)
 	}
 	if len(nlRoutes) > 1 {
 		return fmt.Errorf("unexpected number of routes after filtering. Expecting one route but found: %+v", nlRoutes)
 	}
-	netlinkRoute := &nlRoutes[0]
-	if netlinkRoute.MTU != mtu || !src.Equal(netlinkRoute.Src) || !gwIP.Equal(netlinkRoute.Gw) {
-		netlinkRoute.MTU = mtu
-		netlinkRoute.Src = src
-		netlinkRoute.Gw = gwIP
-		err = c.netlinkOps.RouteReplace(netlinkRoute)
+	netlinkRoute := nlRoutes[0]
+	if !isManagedRouteProtocol(netlinkRoute.Protocol, true) {
+		return fmt.Errorf("failed to apply route (%s): a route of protocol %s already exists",
+			r.String(), netlinkRoute.Protocol.String())
+	}
+	if netlinkRoute.MTU != r.MTU || !r.SrcIP.Equal(netlinkRoute.Src) || !r.GwIP.Equal(netlinkRoute.Gw) ||
+		!multiPathEqual(ConvertNetlinkRouteToRoute(netlinkRoute).MultiPath, r.MultiPath) ||
+		netlinkRoute.Protocol != RouteProtocol {
+		err = c.netlinkOps.RouteReplace(newNetlinkRoute(link, r))
 		if err != nil {
-			return fmt.Errorf("failed to replace route for subnet %s via gateway %s with mtu %d: %v",
-				subnet.String(), gwIP.String(), mtu, err)
+			return fmt.Errorf("failed to replace route (%s): %v", r.String(), err)
 		}
 	}
 	return nil
 }
 
-func (c *Controller) netlinkAddRoute(link netlink.Link, gwIP net.IP, subnet *net.IPNet, mtu int, srcIP net.IP, table int) error {
-	newNlRoute := &netlink.Route{
-		Dst:       subnet,
-		LinkIndex: link.Attrs().Index,
-		Scope:     netlink.SCOPE_UNIVERSE,
-		Gw:        gwIP,
-		Table:     table,
+func (c *Controller) netlinkAddRoute(link netlink.Link, r Route) error {
+	err := c.netlinkOps.RouteAdd(newNetlinkRoute(link, r))
+	if err != nil {
+		return fmt.Errorf("failed to add route (%s): %v", r.String(), err)
 	}
-	if len(srcIP) > 0 {
-		newNlRoute.Src = srcIP
+	return nil
+}
+
+// newNetlinkRoute returns the netlink route of route r through link
+func newNetlinkRoute(link netlink.Link, r Route) *netlink.Route {
+	nlRoute := &netlink.Route{
+		Dst:      r.Subnet,
+		Scope:    netlink.SCOPE_UNIVERSE,
+		Table:    r.Table,
+		Priority: r.Priority,
+		Protocol: RouteProtocol,
+	}
+	if len(r.MultiPath) > 0 {
+		for _, nextHop := range r.MultiPath {
+			linkIndex := link.Attrs().Index
+			if nextHop.Link != nil {
+				linkIndex = nextHop.Link.Attrs().Index
+			}
+			nlRoute.MultiPath = append(nlRoute.MultiPath, &netlink.NexthopInfo{
+				LinkIndex: linkIndex,
+				Gw:        nextHop.GwIP,
+				Hops:      nextHop.weight() - 1,
+			})
+		}
+	} else {
+		nlRoute.LinkIndex = link.Attrs().Index
+		nlRoute.Gw = r.GwIP
 	}
-	if mtu != 0 {
-		newNlRoute.MTU = mtu
+	if len(r.SrcIP) > 0 {
+		nlRoute.Src = r.SrcIP
 	}
-	err := c.netlinkOps.RouteAdd(newNlRoute)
-	if err != nil {
-		return fmt.Errorf("failed to add route (gw: %v, subnet %v, mtu %d, src IP %v): %v", gwIP, subnet, mtu, srcIP, err)
+	if r.MTU != 0 {
+		nlRoute.MTU = r.MTU
 	}
-	return nil
+	return nlRoute
 }
 
-func (c *Controller) netlinkDelRoute(link netlink.Link, subnet *net.IPNet, table int) error {
+func (c *Controller) netlinkDelRoute(link netlink.Link, r Route, takeOver bool) error {
 	// List routes for the link in the default routing table
-	filter, mask := filterRouteByTable(link.Attrs().Index, table)
-
-	nlRoutes, err := c.netlinkOps.RouteListFiltered(netlink.FAMILY_ALL, filter, mask)
+	nlRoutes, err := c.listRoutes(link, netlink.FAMILY_ALL, nil, r.Table)
 	if err != nil {
 		return fmt.Errorf("failed to get routes for link %s: %v", link.Attrs().Name, err)
 	}
+	subnet := r.Subnet
 	for _, nlRoute := range nlRoutes {
+		if nlRoute.Priority != r.kernelPriority() {
+			continue
+		}
+		// never delete the routes of other route managers
+		if !isManagedRouteProtocol(nlRoute.Protocol, takeOver) {
+			continue
+		}
 		deleteRoute := false
 		// Delete if subnet is nil and netlink route dst is nil or if they are equal
 		if subnet == nil {
@@ -303,6 +361,42 @@ func (c *Controller) netlinkDelRoute(link netlink.Link, subnet *net.IPNet, table
 	return nil
 }
 
+// listRoutes lists the routes of table through link, including the multipath routes with a next hop through link.
+// Only the routes to subnet are listed, unless it is nil.
+func (c *Controller) listRoutes(link netlink.Link, family int, subnet *net.IPNet, table int) ([]netlink.Route, error) {
+	var filterRoute *netlink.Route
+	var filterMask uint64
+	if subnet != nil {
+		filterRoute, filterMask = filterRouteByDstAndTable(link.Attrs().Index, subnet, table)
+	} else {
+		filterRoute, filterMask = filterRouteByTable(link.Attrs().Index, table)
+	}
+	// multipath routes have no output interface, they are filtered by link below
+	nlRoutes, err := c.netlinkOps.RouteListFiltered(family, filterRoute, filterMask&^netlink.RT_FILTER_OIF)
+	if err != nil {
+		return nil, err
+	}
+	routes := nlRoutes[:0]
+	for _, nlRoute := range nlRoutes {
+		if isRouteThroughLink(nlRoute, link.Attrs().Index) {
+			routes = append(routes, nlRoute)
+		}
+	}
+	return routes, nil
+}
+
+func isRouteThroughLink(nlRoute netlink.Route, linkIndex int) bool {
+	if nlRoute.LinkIndex == linkIndex {
+		return true
+	}
+	for _, nextHop := range nlRoute.MultiPath {
+		if nextHop.LinkIndex == linkIndex {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Controller) addRoutesPerLinkStore(rl RoutesPerLink) error {
 	infName, err := rl.getLinkName()
 	if err != nil {
@@ -340,11 +434,9 @@ func (c *Controller) addRoutesPerLinkStore(rl RoutesPerLink) error {
 func (c *Controller) sync() {
 	for infName, rl := range c.store {
 		for _, route := range rl.Routes {
-			filterRoute, filterMask := filterRouteByTable(rl.Link.Attrs().Index, route.Table)
-			activeNlRoutes, err := c.netlinkOps.RouteListFiltered(netlink.FAMILY_ALL, filterRoute, filterMask)
+			activeNlRoutes, err := c.listRoutes(rl.Link, netlink.FAMILY_ALL, nil, route.Table)
 			if err != nil {
-				klog.Errorf("Route Manager: failed to list routes for link %q usin filter route (%s) and filter mask %d: %w",
-					filterRoute.String(), filterMask, infName, err)
+				klog.Errorf("Route Manager: failed to list routes of table %d for link %q: %v", route.Table, infName, err)
 				continue
 			}
 			var activeRoutes []Route
@@ -368,7 +460,7 @@ func (c *Controller) sync() {
 				}
 			}
 			if syncNeeded {
-				if err := c.applyRoute(rl.Link, route.GwIP, route.Subnet, route.MTU, route.SrcIP, route.Table); err != nil {
+				if err := c.applyRoute(rl.Link, route); err != nil {
 					klog.Errorf("Route Manager: failed to apply route because %s (%s): %v", syncReason, route.String(), err)
 				}
 			}
@@ -403,10 +495,50 @@ func (rl RoutesPerLink) validate() error {
 		if r.Subnet == nil || r.Subnet.String() == "<nil>" {
 			return fmt.Errorf("invalid subnet for route entry")
 		}
+		if len(r.MultiPath) > 0 && len(r.GwIP) > 0 {
+			return fmt.Errorf("route entry with subnet %s cannot have both a gateway IP and multipath next hops", r.Subnet)
+		}
+		var throughLink bool
+		for _, nextHop := range r.MultiPath {
+			if nextHop.GwIP == nil || utilnet.IsIPv6(nextHop.GwIP) != utilnet.IsIPv6CIDR(r.Subnet) {
+				return fmt.Errorf("invalid next hop gateway IP %s for route entry with subnet %s", nextHop.GwIP, r.Subnet)
+			}
+			if nextHop.Weight < 0 || nextHop.Weight > maxNextHopWeight {
+				return fmt.Errorf("invalid weight %d of next hop %s, must be between 0 (same as 1) and %d", nextHop.Weight,
+					nextHop.GwIP, maxNextHopWeight)
+			}
+			if nextHop.Link != nil && nextHop.Link.Attrs() == nil {
+				return fmt.Errorf("invalid link of next hop %s", nextHop.GwIP)
+			}
+			throughLink = throughLink || nextHop.Link == nil || nextHop.Link.Attrs().Index == rl.Link.Attrs().Index
+		}
+		if len(r.MultiPath) > 0 && !throughLink {
+			return fmt.Errorf("route entry with subnet %s must have at least one next hop through link %s", r.Subnet,
+				rl.Link.Attrs().Name)
+		}
 	}
 	return nil
 }
 
+// withNextHopLinks returns a copy of rl where the next hops without link are set to the link of the routes
+func (rl RoutesPerLink) withNextHopLinks() RoutesPerLink {
+	routes := make([]Route, 0, len(rl.Routes))
+	for _, r := range rl.Routes {
+		if len(r.MultiPath) > 0 {
+			nextHops := make([]NextHop, 0, len(r.MultiPath))
+			for _, nextHop := range r.MultiPath {
+				if nextHop.Link == nil {
+					nextHop.Link = rl.Link
+				}
+				nextHops = append(nextHops, nextHop)
+			}
+			r.MultiPath = nextHops
+		}
+		routes = append(routes, r)
+	}
+	return RoutesPerLink{Link: rl.Link, Routes: routes}
+}
+
 func (rl RoutesPerLink) getLinkName() (string, error) {
 	if rl.Link == nil || rl.Link.Attrs() == nil || rl.Link.Attrs().Name == "" {
 		return "", fmt.Errorf("unable to get link name from: '%+v'", rl.Link)
@@ -422,6 +554,16 @@ func (rl RoutesPerLink) String() string {
 	return fmt.Sprintf("Route(s) for link name: %q, with %d routes: %s", rl.Link.Attrs().Name, len(rl.Routes), routes)
 }
 
+// hasRoute returns true if r is one of the routes of the link
+func (rl RoutesPerLink) hasRoute(r Route) bool {
+	for _, existingRoute := range rl.Routes {
+		if existingRoute.Equal(r) {
+			return true
+		}
+	}
+	return false
+}
+
 func (rl *RoutesPerLink) delRoutes(delRoutes []Route) {
 	if len(delRoutes) == 0 {
 		return
@@ -447,6 +589,71 @@ type Route struct {
 	MTU    int
 	SrcIP  net.IP
 	Table  int
+	// Priority is the metric of the route. Routes to the same subnet with different priorities are different routes.
+	Priority int
+	// MultiPath are the next hops of an ECMP route, used instead of GwIP. At least one of them is through the link.
+	MultiPath []NextHop
+}
+
+// maxNextHopWeight is the maximum weight of a next hop of a multipath route
+const maxNextHopWeight = 256
+
+// NextHop is a next hop of a multipath route
+type NextHop struct {
+	GwIP net.IP
+	// Weight is the relative weight of the next hop, between 1 and 256. Zero means 1.
+	Weight int
+	// Link is the link of the next hop. Nil means the link of the route.
+	Link netlink.Link
+}
+
+func (nh NextHop) linkIndex() int {
+	if nh.Link == nil || nh.Link.Attrs() == nil {
+		return 0
+	}
+	return nh.Link.Attrs().Index
+}
+
+func (nh NextHop) weight() int {
+	if nh.Weight == 0 {
+		return 1
+	}
+	return nh.Weight
+}
+
+func (nh NextHop) String() string {
+	if nh.Link != nil && nh.Link.Attrs() != nil && nh.Link.Attrs().Name != "" {
+		return fmt.Sprintf("%s dev %s weight %d", nh.GwIP.String(), nh.Link.Attrs().Name, nh.weight())
+	}
+	return fmt.Sprintf("%s weight %d", nh.GwIP.String(), nh.weight())
+}
+
+// multiPathEqual returns true if both sets of next hops are equal, regardless of their order
+func multiPathEqual(nextHops1, nextHops2 []NextHop) bool {
+	if len(nextHops1) != len(nextHops2) {
+		return false
+	}
+	for _, nh1 := range nextHops1 {
+		var found bool
+		for _, nh2 := range nextHops2 {
+			if nh1.GwIP.Equal(nh2.GwIP) && nh1.weight() == nh2.weight() && nh1.linkIndex() == nh2.linkIndex() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// kernelPriority returns the priority of the route as set by the kernel
+func (r Route) kernelPriority() int {
+	if r.Priority == 0 && r.Subnet != nil && utilnet.IsIPv6CIDR(r.Subnet) {
+		return ipv6DefaultRoutePriority
+	}
+	return r.Priority
 }
 
 // Equal compares two routes and returns true if they are equal
@@ -474,6 +681,12 @@ func (r Route) Equal(r2 Route) bool {
 	if r.SrcIP.String() != r2.SrcIP.String() {
 		return false
 	}
+	if r.kernelPriority() != r2.kernelPriority() {
+		return false
+	}
+	if !multiPathEqual(r.MultiPath, r2.MultiPath) {
+		return false
+	}
 	return true
 }
 
@@ -491,17 +704,33 @@ func (r Route) String() string {
 	if len(r.GwIP) > 0 {
 		s = fmt.Sprintf("%s Gateway IP: %q", s, r.GwIP.String())
 	}
+	if r.Priority != 0 {
+		s = fmt.Sprintf("%s Priority: %d", s, r.Priority)
+	}
+	for i, nextHop := range r.MultiPath {
+		s = fmt.Sprintf("%s Next hop %d: %q", s, i+1, nextHop.String())
+	}
 	return s
 }
 
 func ConvertNetlinkRouteToRoute(nlRoute netlink.Route) Route {
-	return Route{
-		GwIP:   nlRoute.Gw,
-		Subnet: nlRoute.Dst,
-		MTU:    nlRoute.MTU,
-		SrcIP:  nlRoute.Src,
-		Table:  nlRoute.Table,
+	r := Route{
+		GwIP:     nlRoute.Gw,
+		Subnet:   nlRoute.Dst,
+		MTU:      nlRoute.MTU,
+		SrcIP:    nlRoute.Src,
+		Table:    nlRoute.Table,
+		Priority: nlRoute.Priority,
 	}
+	for _, nextHop := range nlRoute.MultiPath {
+		r.MultiPath = append(r.MultiPath, NextHop{
+			GwIP:   nextHop.Gw,
+			Weight: nextHop.Hops + 1,
+			// only the index of the link is known
+			Link: &netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: nextHop.LinkIndex}},
+		})
+	}
+	return r
 }
 
 func convertRouteUpdateToRoutesPerLink(link netlink.Link, ru netlink.RouteUpdate) (RoutesPerLink, error) {
@@ -512,16 +741,8 @@ func convertRouteUpdateToRoutesPerLink(link netlink.Link, ru netlink.RouteUpdate
 	}
 
 	return RoutesPerLink{
-		Link: link,
-		Routes: []Route{
-			{
-				GwIP:   ru.Gw,
-				Subnet: ru.Dst,
-				MTU:    ru.MTU,
-				SrcIP:  ru.Src,
-				Table:  ru.Table,
-			},
-		},
+		Link:   link,
+		Routes: []Route{ConvertNetlinkRouteToRoute(ru.Route)},
 	}, nil
 }
 
@@ -533,16 +754,8 @@ func convertNetlinkRouteToRoutesPerLink(link netlink.Link, nlRoute netlink.Route
 		nlRoute.Dst = ipNet
 	}
 	return RoutesPerLink{
-		Link: link,
-		Routes: []Route{
-			{
-				GwIP:   nlRoute.Gw,
-				Subnet: nlRoute.Dst,
-				MTU:    nlRoute.MTU,
-				SrcIP:  nlRoute.Src,
-				Table:  nlRoute.Table,
-			},
-		},
+		Link:   link,
+		Routes: []Route{ConvertNetlinkRouteToRoute(nlRoute)},
 	}, nil
 }
 
@@ -14,6 +14,7 @@ import (
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 var _ = ginkgo.Describe("Route Manager", func() {
@@ -95,7 +96,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 
 	ginkgo.Context("add route", func() {
 		ginkgo.It("applies route with subnet, gateway IP, src IP, MTU", func() {
-			r := Route{loGWIP, loSubnet, loMTU, loIP, defaultTableID}
+			r := Route{GwIP: loGWIP, Subnet: loSubnet, MTU: loMTU, SrcIP: loIP, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -104,7 +105,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 
 		ginkgo.It("applies route with subnets, gateway IP, src IP", func() {
-			r := Route{loGWIP, loSubnet, 0, loIP, defaultTableID}
+			r := Route{GwIP: loGWIP, Subnet: loSubnet, SrcIP: loIP, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -113,7 +114,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 
 		ginkgo.It("applies route with subnets, gateway IP", func() {
-			r := Route{loGWIP, loSubnet, 0, nil, defaultTableID}
+			r := Route{GwIP: loGWIP, Subnet: loSubnet, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -122,7 +123,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 
 		ginkgo.It("applies route with subnets", func() {
-			r := Route{nil, loSubnet, 0, nil, defaultTableID}
+			r := Route{Subnet: loSubnet, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -132,7 +133,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 
 		ginkgo.It("route exists, has different mtu and is updated", func() {
 			// route already exists for default mtu - no need to add it
-			r := Route{nil, loSubnet, loAlternativeMTU, nil, defaultTableID}
+			r := Route{Subnet: loSubnet, MTU: loAlternativeMTU, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -142,7 +143,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 
 		ginkgo.It("route exists, has different src and is updated", func() {
 			// route already exists for src ip - no need to add it
-			r := Route{nil, loSubnet, 0, loIPDiff, defaultTableID}
+			r := Route{Subnet: loSubnet, SrcIP: loIPDiff, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -152,7 +153,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 
 		ginkgo.It("two equal routes, different tables", func() {
 			// route already exists for src ip - no need to add it
-			r := Route{nil, loSubnet, 0, loIPDiff, 5}
+			r := Route{Subnet: loSubnet, SrcIP: loIPDiff, Table: 5}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 
@@ -202,9 +203,141 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 	})
 
+	ginkgo.Context("multipath routes, priorities and protocol", func() {
+		getRoute := func(r Route) *netlink.Route {
+			var found *netlink.Route
+			err := testNS.Do(func(netNS ns.NetNS) error {
+				nlRoutes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: r.Table}, netlink.RT_FILTER_TABLE)
+				if err != nil {
+					return err
+				}
+				for i := range nlRoutes {
+					if ConvertNetlinkRouteToRoute(nlRoutes[i]).Equal(r) {
+						found = &nlRoutes[i]
+					}
+				}
+				return nil
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			return found
+		}
+
+		ginkgo.It("applies multipath route with weights and restores it", func() {
+			r := Route{
+				Subnet: altSubnet,
+				Table:  defaultTableID,
+				MultiPath: []NextHop{
+					{GwIP: loGWIP, Weight: 2, Link: loLink},
+					{GwIP: net.IPv4(127, 1, 1, 253), Link: loLink},
+				},
+			}
+			rm.Add(RoutesPerLink{loLink, []Route{r}})
+			gomega.Eventually(func() *netlink.Route { return getRoute(r) }, time.Second).ShouldNot(gomega.BeNil())
+			nlRoute := getRoute(r)
+			gomega.Expect(nlRoute.Protocol).To(gomega.Equal(RouteProtocol))
+			gomega.Expect(nlRoute.MultiPath).To(gomega.HaveLen(2))
+
+			gomega.Expect(deleteRoutes(testNS, *nlRoute)).ShouldNot(gomega.HaveOccurred())
+			gomega.Eventually(func() *netlink.Route { return getRoute(r) }, time.Second).ShouldNot(gomega.BeNil())
+		})
+
+		ginkgo.It("applies and deletes routes with different priorities", func() {
+			r1 := Route{GwIP: loGWIP, Subnet: altSubnet, Table: defaultTableID, Priority: 100}
+			r2 := Route{GwIP: loGWIP, Subnet: altSubnet, Table: defaultTableID, Priority: 200}
+			rm.Add(RoutesPerLink{loLink, []Route{r1, r2}})
+			gomega.Eventually(func() *netlink.Route { return getRoute(r1) }, time.Second).ShouldNot(gomega.BeNil())
+			gomega.Eventually(func() *netlink.Route { return getRoute(r2) }, time.Second).ShouldNot(gomega.BeNil())
+
+			rm.Del(RoutesPerLink{loLink, []Route{r1}})
+			gomega.Eventually(func() *netlink.Route { return getRoute(r1) }, time.Second).Should(gomega.BeNil())
+			gomega.Consistently(func() *netlink.Route { return getRoute(r2) }, 500*time.Millisecond).ShouldNot(gomega.BeNil())
+		})
+
+		ginkgo.It("applies multipath route with next hops through different links", func() {
+			vethSubnet := &net.IPNet{IP: net.IPv4(10, 20, 0, 1), Mask: net.CIDRMask(24, 32)}
+			var vethLink netlink.Link
+			err := testNS.Do(func(netNS ns.NetNS) error {
+				veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "veth0"}, PeerName: "veth1"}
+				if err := netlink.LinkAdd(veth); err != nil {
+					return err
+				}
+				for _, name := range []string{"veth1", "veth0"} {
+					var err error
+					if vethLink, err = netlink.LinkByName(name); err != nil {
+						return err
+					}
+					if err = netlink.LinkSetUp(vethLink); err != nil {
+						return err
+					}
+				}
+				return netlink.AddrAdd(vethLink, &netlink.Addr{IPNet: vethSubnet})
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			r := Route{
+				Subnet: altSubnet,
+				Table:  defaultTableID,
+				MultiPath: []NextHop{
+					{GwIP: loGWIP},
+					{GwIP: net.IPv4(10, 20, 0, 254), Link: vethLink},
+				},
+			}
+			rm.Add(RoutesPerLink{loLink, []Route{r}})
+			r.MultiPath[0].Link = loLink
+			gomega.Eventually(func() *netlink.Route { return getRoute(r) }, time.Second).ShouldNot(gomega.BeNil())
+			nlRoute := getRoute(r)
+			gomega.Expect(nlRoute.MultiPath).To(gomega.HaveLen(2))
+			gomega.Expect([]int{nlRoute.MultiPath[0].LinkIndex, nlRoute.MultiPath[1].LinkIndex}).To(
+				gomega.ConsistOf(loLink.Attrs().Index, vethLink.Attrs().Index))
+
+			rm.Del(RoutesPerLink{loLink, []Route{r}})
+			gomega.Eventually(func() *netlink.Route { return getRoute(r) }, time.Second).Should(gomega.BeNil())
+		})
+
+		ginkgo.It("takes over an existing route of the boot protocol", func() {
+			// route already exists, added without protocol like previous versions of the route manager did
+			r := Route{Subnet: loSubnet, SrcIP: loIP, Table: defaultTableID}
+			gomega.Expect(getRoute(r).Protocol).To(gomega.Equal(netlink.RouteProtocol(unix.RTPROT_BOOT)))
+			rm.Add(RoutesPerLink{loLink, []Route{r}})
+			gomega.Eventually(func() netlink.RouteProtocol { return getRoute(r).Protocol }, time.Second).Should(gomega.Equal(RouteProtocol))
+		})
+
+		ginkgo.It("neither replaces nor deletes routes of other protocols", func() {
+			r := Route{GwIP: loGWIP, Subnet: altSubnet, Table: defaultTableID}
+			err := testNS.Do(func(netNS ns.NetNS) error {
+				return netlink.RouteAdd(&netlink.Route{LinkIndex: loLink.Attrs().Index, Dst: altSubnet, Gw: loGWIP,
+					Protocol: unix.RTPROT_STATIC})
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			rm.Add(RoutesPerLink{loLink, []Route{r}})
+			gomega.Consistently(func() netlink.RouteProtocol { return getRoute(r).Protocol }, 500*time.Millisecond).Should(
+				gomega.Equal(netlink.RouteProtocol(unix.RTPROT_STATIC)))
+			rm.Del(RoutesPerLink{loLink, []Route{r}})
+			gomega.Consistently(func() *netlink.Route { return getRoute(r) }, 500*time.Millisecond).ShouldNot(gomega.BeNil())
+		})
+
+		ginkgo.It("deletes a route of the boot protocol only when taking it over", func() {
+			r := Route{GwIP: loGWIP, Subnet: altSubnet, Table: defaultTableID}
+			err := testNS.Do(func(netNS ns.NetNS) error {
+				return netlink.RouteAdd(&netlink.Route{LinkIndex: loLink.Attrs().Index, Dst: altSubnet, Gw: loGWIP})
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			gomega.Expect(getRoute(r).Protocol).To(gomega.Equal(netlink.RouteProtocol(unix.RTPROT_BOOT)))
+			err = testNS.Do(func(netNS ns.NetNS) error {
+				return rm.netlinkDelRoute(loLink, r, false)
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			gomega.Expect(getRoute(r)).ShouldNot(gomega.BeNil())
+			err = testNS.Do(func(netNS ns.NetNS) error {
+				return rm.netlinkDelRoute(loLink, r, true)
+			})
+			gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+			gomega.Expect(getRoute(r)).Should(gomega.BeNil())
+		})
+	})
+
 	ginkgo.Context("del route", func() {
 		ginkgo.It("del route", func() {
-			r := Route{nil, altSubnet, 0, nil, defaultTableID}
+			r := Route{Subnet: altSubnet, Table: defaultTableID}
 			rl := RoutesPerLink{loLink, []Route{r}}
 			rm.Add(rl)
 			gomega.Eventually(func() bool {
@@ -219,7 +352,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 
 	ginkgo.Context("runtime sync", func() {
 		ginkgo.It("reapplies managed route that was removed (gw IP, mtu, src IP)", func() {
-			r := Route{loGWIP, loSubnet, loMTU, loIP, defaultTableID}
+			r := Route{GwIP: loGWIP, Subnet: loSubnet, MTU: loMTU, SrcIP: loIP, Table: defaultTableID}
 			rm.Add(RoutesPerLink{loLink, []Route{r}})
 			gomega.Eventually(func() bool {
 				return doesRouteEntryExistInDefaultRuleTable(rm.netlinkOps, testNS, loLink, r)
@@ -236,7 +369,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 
 		ginkgo.It("reapplies managed route that was removed (mtu, src IP)", func() {
-			r := Route{nil, loSubnet, loMTU, loIP, defaultTableID}
+			r := Route{Subnet: loSubnet, MTU: loMTU, SrcIP: loIP, Table: defaultTableID}
 			rm.Add(RoutesPerLink{loLink, []Route{r}})
 			gomega.Eventually(func() bool {
 				return doesRouteEntryExistInDefaultRuleTable(rm.netlinkOps, testNS, loLink, r)
@@ -253,7 +386,7 @@ var _ = ginkgo.Describe("Route Manager", func() {
 		})
 
 		ginkgo.It("reapplies managed route that was removed because link is down", func() {
-			r := Route{nil, loSubnet, loMTU, loIP, defaultTableID}
+			r := Route{Subnet: loSubnet, MTU: loMTU, SrcIP: loIP, Table: defaultTableID}
 			rm.Add(RoutesPerLink{loLink, []Route{r}})
 			gomega.Eventually(func() bool {
 				return doesRouteEntryExistInDefaultRuleTable(rm.netlinkOps, testNS, loLink, r)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
 	"k8s.io/klog/v2"
@@ -16,6 +18,10 @@ type SecondaryNodeNetworkController struct {
 	BaseNodeNetworkController
 	// pod events factory handler
 	podHandler *factory.Handler
+	// learns the IPs of the pods on IPAM-less networks
+	podIPObserver *podIPObserver
+	// announces the VMs live migrated to the node
+	vmMigrationHandler *factory.Handler
 }
 
 // NewSecondaryNodeNetworkController creates a new OVN controller for creating logical network
@@ -34,11 +40,27 @@ func NewSecondaryNodeNetworkController(cnnci *CommonNodeNetworkControllerInfo, n
 // Start starts the default controller; handles all events and creates all needed logical entities
 func (nc *SecondaryNodeNetworkController) Start(ctx context.Context) error {
 	klog.Infof("Start secondary node network controller of network %s", nc.GetNetworkName())
-	handler, err := nc.watchPodsDPU()
-	if err != nil {
-		return err
+	if config.OvnKubeNode.Mode == types.NodeModeDPU {
+		handler, err := nc.watchPodsDPU()
+		if err != nil {
+			return err
+		}
+		nc.podHandler = handler
+	}
+	if config.OvnKubeNode.Mode == types.NodeModeFull && nc.TopologyType() == types.Layer2Topology {
+		handler, err := nc.watchVirtualMachineMigrations()
+		if err != nil {
+			return err
+		}
+		nc.vmMigrationHandler = handler
+	}
+	if config.OvnKubeNode.Mode == types.NodeModeFull && util.IsObservedPodIPsEnabled() &&
+		!util.DoesNetworkRequireIPAM(nc.NetInfo) {
+		nc.podIPObserver = newPodIPObserver(nc.NetInfo, nc.name, nc.watchFactory, nc.Kube, nc.ovsClient)
+		if err := nc.podIPObserver.Start(nc.stopChan, nc.wg); err != nil {
+			return err
+		}
 	}
-	nc.podHandler = handler
 	return nil
 }
 
@@ -51,6 +73,9 @@ func (nc *SecondaryNodeNetworkController) Stop() {
 	if nc.podHandler != nil {
 		nc.watchFactory.RemovePodHandler(nc.podHandler)
 	}
+	if nc.vmMigrationHandler != nil {
+		nc.watchFactory.RemovePodHandler(nc.vmMigrationHandler)
+	}
 }
 
 // Cleanup cleans up node entities for the given secondary network
@@ -312,6 +312,19 @@ func GetHashNamesForAS(dbIDs *libovsdbops.DbObjectIDs) (string, string) {
 		buildAddressSet(dbIDs, ipv6InternalID).Name
 }
 
+// BuildAddressSetWithAddresses returns the address set of the given dbIDs and IP
+// family holding the given addresses. Unlike the address sets of the factory,
+// the addresses may be subnets, e.g. to restrict NATs to some destinations.
+func BuildAddressSetWithAddresses(dbIDs *libovsdbops.DbObjectIDs, isIPv6 bool, addresses []string) *nbdb.AddressSet {
+	ipFamily := ipv4InternalID
+	if isIPv6 {
+		ipFamily = ipv6InternalID
+	}
+	as := buildAddressSet(dbIDs, ipFamily)
+	as.Addresses = addresses
+	return as
+}
+
 // GetTestDbAddrSets returns nbdb.AddressSet objects both for ipv4 and ipv6, regardless of current config.
 // May only be used for testing.
 func GetTestDbAddrSets(dbIDs *libovsdbops.DbObjectIDs, ips []net.IP) (*nbdb.AddressSet, *nbdb.AddressSet) {
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -161,6 +163,12 @@ type BaseNetworkController struct {
 	// might have been already be released on startup
 	releasedPodsBeforeStartup  map[string]sets.Set[string]
 	releasedPodsOnStartupMutex sync.Mutex
+
+	// lbHealthCheckPodIPs indexes the pods by IP to find the ones holding the
+	// load balancer health check source addresses of the node switches. It is
+	// built once per node sync, or upon first use.
+	lbHealthCheckPodIPs      map[string]ktypes.NamespacedName
+	lbHealthCheckPodIPsMutex sync.Mutex
 }
 
 // BaseSecondaryNetworkController structure holds per-network fields and network specific
@@ -169,6 +177,8 @@ type BaseSecondaryNetworkController struct {
 	BaseNetworkController
 	// multi-network policy events factory handler
 	policyHandler *factory.Handler
+	// nodes whose network gateway router failed to sync
+	gatewaysFailed sync.Map
 }
 
 // NewCommonNetworkControllerInfo creates CommonNetworkControllerInfo shared by controllers
@@ -196,7 +206,7 @@ func NewCommonNetworkControllerInfo(client clientset.Interface, kube *kube.KubeO
 
 func (bnc *BaseNetworkController) GetLogicalPortName(pod *kapi.Pod, nadName string) string {
 	if !bnc.IsSecondary() {
-		return util.GetLogicalPortName(pod.Namespace, pod.Name)
+		return kubevirt.GetPodLogicalPortName(pod)
 	} else {
 		return util.GetSecondaryNetworkLogicalPortName(pod.Namespace, pod.Name, nadName)
 	}
@@ -334,6 +344,15 @@ func (bnc *BaseNetworkController) createNodeLogicalSwitch(nodeName string, hostS
 		}
 	}
 
+	var lbHealthCheckIPs []*net.IPNet
+	if config.Kubernetes.OVNLBHealthChecks && !bnc.IsSecondary() {
+		var err error
+		lbHealthCheckIPs, err = bnc.findLBHealthCheckSourceIPs(switchName, hostSubnets)
+		if err != nil {
+			return fmt.Errorf("failed to find the load balancer health check source addresses of node %s: %v", nodeName, err)
+		}
+	}
+
 	var v4Gateway, v6Gateway net.IP
 	logicalSwitch.OtherConfig = map[string]string{}
 	for _, hostSubnet := range hostSubnets {
@@ -411,13 +430,128 @@ func (bnc *BaseNetworkController) createNodeLogicalSwitch(nodeName string, hostS
 			return fmt.Errorf("failed adding port to portgroup for multicast: %v", err)
 		}
 	}
+	if !bnc.IsSecondary() {
+		// record the reserved addresses for the services controller, an empty
+		// value, e.g. with health checks disabled, removes the key
+		sources := make([]string, 0, len(lbHealthCheckIPs))
+		for _, ip := range lbHealthCheckIPs {
+			sources = append(sources, ip.IP.String())
+		}
+		sw := nbdb.LogicalSwitch{
+			Name:        switchName,
+			ExternalIDs: map[string]string{types.LBHealthCheckSourceExternalID: strings.Join(sources, ",")},
+		}
+		if err := libovsdbops.UpdateLogicalSwitchSetExternalIDs(bnc.nbClient, &sw); err != nil {
+			return fmt.Errorf("failed to set the load balancer health check source addresses of switch %s: %v", switchName, err)
+		}
+	}
+
 	// Add the switch to the logical switch cache
 	migratableIPsByPod, err := bnc.findMigratablePodIPsForSubnets(hostSubnets)
 	if err != nil {
 		return fmt.Errorf("failed finding migratable pod IPs belonging to %s: %v", nodeName, err)
 	}
 
-	return bnc.lsManager.AddOrUpdateSwitch(logicalSwitch.Name, hostSubnets, migratableIPsByPod...)
+	return bnc.lsManager.AddOrUpdateSwitch(logicalSwitch.Name, hostSubnets, append(migratableIPsByPod, lbHealthCheckIPs...)...)
+}
+
+// findLBHealthCheckSourceIPs returns the addresses of the host subnets to
+// reserve as source of the load balancer health checks. Pods created before
+// health checks were enabled may hold some of them, the health checks of the
+// pods of these subnets are then skipped rather than reusing their address.
+func (bnc *BaseNetworkController) findLBHealthCheckSourceIPs(switchName string, hostSubnets []*net.IPNet) ([]*net.IPNet, error) {
+	candidates := make([]*net.IPNet, 0, len(hostSubnets))
+	for _, hostSubnet := range hostSubnets {
+		ip := util.GetNodeLBHealthCheckIfAddr(hostSubnet).IP
+		candidates = append(candidates, &net.IPNet{IP: ip, Mask: util.GetIPFullMask(ip)})
+	}
+
+	// the addresses are never allocated to pods of new switches
+	_, err := libovsdbops.GetLogicalSwitch(bnc.nbClient, &nbdb.LogicalSwitch{Name: switchName})
+	if errors.Is(err, libovsdbclient.ErrNotFound) {
+		return candidates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the addresses recorded on the switch may have been allocated while
+	// health checks were disabled, so always look for pods holding them
+	podIPs, err := bnc.getLBHealthCheckPodIndex()
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]*net.IPNet, 0, len(candidates))
+	for _, candidate := range candidates {
+		podName, ok := podIPs[candidate.IP.String()]
+		if ok && bnc.podHoldsIP(podName, candidate.IP) {
+			klog.Warningf("Load balancer health check source address %s of switch %s is used by pod %s, "+
+				"the pods of its subnet are not health checked", candidate.IP, switchName, podName)
+			continue
+		}
+		ips = append(ips, candidate)
+	}
+	return ips, nil
+}
+
+// syncLBHealthCheckPodIndex rebuilds the index of the pods by IP used to find
+// the pods holding the load balancer health check source addresses
+func (bnc *BaseNetworkController) syncLBHealthCheckPodIndex() error {
+	bnc.lbHealthCheckPodIPsMutex.Lock()
+	defer bnc.lbHealthCheckPodIPsMutex.Unlock()
+	return bnc.buildLBHealthCheckPodIndexLocked()
+}
+
+func (bnc *BaseNetworkController) getLBHealthCheckPodIndex() (map[string]ktypes.NamespacedName, error) {
+	bnc.lbHealthCheckPodIPsMutex.Lock()
+	defer bnc.lbHealthCheckPodIPsMutex.Unlock()
+	if bnc.lbHealthCheckPodIPs == nil {
+		if err := bnc.buildLBHealthCheckPodIndexLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return bnc.lbHealthCheckPodIPs, nil
+}
+
+func (bnc *BaseNetworkController) buildLBHealthCheckPodIndexLocked() error {
+	pods, err := bnc.watchFactory.GetAllPods()
+	if err != nil {
+		return err
+	}
+	// completed pods keep their addresses until released
+	podIPs := map[string]ktypes.NamespacedName{}
+	for _, pod := range pods {
+		if util.PodWantsHostNetwork(pod) {
+			continue
+		}
+		podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, types.DefaultNetworkName)
+		if err != nil {
+			continue
+		}
+		for _, ip := range podAnnotation.IPs {
+			podIPs[ip.IP.String()] = ktypes.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		}
+	}
+	bnc.lbHealthCheckPodIPs = podIPs
+	return nil
+}
+
+// podHoldsIP tells whether the indexed pod still exists and holds the IP
+func (bnc *BaseNetworkController) podHoldsIP(podName ktypes.NamespacedName, ip net.IP) bool {
+	pod, err := bnc.watchFactory.GetPod(podName.Namespace, podName.Name)
+	if err != nil {
+		return false
+	}
+	podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, types.DefaultNetworkName)
+	if err != nil {
+		return false
+	}
+	for _, podIP := range podAnnotation.IPs {
+		if podIP.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // deleteNodeLogicalNetwork removes the logical switch and logical router port associated with the node
@@ -8,6 +8,7 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
 	knet "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -130,8 +131,37 @@ func convertMultiNetPolicyToNetPolicy(mpolicy *mnpapi.MultiNetworkPolicy, allowP
 }
 
 func (bnc *BaseNetworkController) convertMultiNetPolicyToNetPolicy(mpolicy *mnpapi.MultiNetworkPolicy) (*knet.NetworkPolicy, error) {
-	allowPeerSelectors := bnc.doesNetworkRequireIPAM()
-	return convertMultiNetPolicyToNetPolicy(mpolicy, allowPeerSelectors)
+	// the IPs of the peer pods on IPAM-less networks are the ones observed
+	// from their traffic
+	allowPeerSelectors := bnc.doesNetworkRequireIPAM() || util.IsObservedPodIPsEnabled()
+	policy, err := convertMultiNetPolicyToNetPolicy(mpolicy, allowPeerSelectors)
+	if err != nil {
+		return nil, err
+	}
+	if !bnc.doesNetworkRequireIPAM() {
+		selectAllPodsOfPeerNamespaces(policy)
+	}
+	return policy, nil
+}
+
+// selectAllPodsOfPeerNamespaces turns the namespace selector peers of the
+// policy into peers selecting all the pods of the namespaces. The namespace
+// address sets are not maintained on IPAM-less networks, while the pod
+// selector address sets hold the observed IPs of the pods.
+func selectAllPodsOfPeerNamespaces(policy *knet.NetworkPolicy) {
+	selectAllPods := func(peers []knet.NetworkPolicyPeer) {
+		for i := range peers {
+			if peers[i].NamespaceSelector != nil && peers[i].PodSelector == nil {
+				peers[i].PodSelector = &metav1.LabelSelector{}
+			}
+		}
+	}
+	for _, ingress := range policy.Spec.Ingress {
+		selectAllPods(ingress.From)
+	}
+	for _, egress := range policy.Spec.Egress {
+		selectAllPods(egress.To)
+	}
 }
 
 func isPeerSelector(peer mnpapi.MultiNetworkPolicyPeer) bool {
@@ -188,6 +188,40 @@ var _ = Describe("convertMultiNetPolicyToNetPolicy", func() {
 	})
 })
 
+var _ = Describe("selectAllPodsOfPeerNamespaces", func() {
+	It("selects all the pods of the namespace selector peers", func() {
+		policy := &netv1.NetworkPolicy{
+			Spec: netv1.NetworkPolicySpec{
+				Ingress: []netv1.NetworkPolicyIngressRule{
+					{
+						From: []netv1.NetworkPolicyPeer{
+							{NamespaceSelector: sameLabelsEverywhere()},
+							{NamespaceSelector: sameLabelsEverywhere(), PodSelector: sameLabelsEverywhere()},
+						},
+					},
+				},
+				Egress: []netv1.NetworkPolicyEgressRule{
+					{
+						To: []netv1.NetworkPolicyPeer{
+							{NamespaceSelector: sameLabelsEverywhere()},
+							{IPBlock: &netv1.IPBlock{CIDR: "10.128.0.0/16"}},
+						},
+					},
+				},
+			},
+		}
+		selectAllPodsOfPeerNamespaces(policy)
+		Expect(policy.Spec.Ingress[0].From).To(Equal([]netv1.NetworkPolicyPeer{
+			{NamespaceSelector: sameLabelsEverywhere(), PodSelector: &metav1.LabelSelector{}},
+			{NamespaceSelector: sameLabelsEverywhere(), PodSelector: sameLabelsEverywhere()},
+		}))
+		Expect(policy.Spec.Egress[0].To).To(Equal([]netv1.NetworkPolicyPeer{
+			{NamespaceSelector: sameLabelsEverywhere(), PodSelector: &metav1.LabelSelector{}},
+			{IPBlock: &netv1.IPBlock{CIDR: "10.128.0.0/16"}},
+		}))
+	})
+})
+
 func sameLabelsEverywhere() *metav1.LabelSelector {
 	return &metav1.LabelSelector{
 		MatchLabels: map[string]string{"George": "Costanza"},
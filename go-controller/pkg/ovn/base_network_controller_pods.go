@@ -192,6 +192,25 @@ func (bnc *BaseNetworkController) deletePodLogicalPort(pod *kapi.Pod, portInfo *
 
 	podDesc := fmt.Sprintf("pod %s/%s/%s", nadName, pod.Namespace, pod.Name)
 	logicalPort = bnc.GetLogicalPortName(pod, nadName)
+	if !bnc.IsSecondary() && kubevirt.IsPodLiveMigratable(pod) {
+		// the logical port shared by the pods of a VM is kept as long
+		// as any of them is bound to it
+		released, err := kubevirt.ReleaseLogicalSwitchPort(bnc.nbClient, bnc.watchFactory, pod)
+		if err != nil {
+			return nil, fmt.Errorf("unable to release logical port %s for %s: %w", logicalPort, podDesc, err)
+		}
+		if !released {
+			klog.Infof("Keeping logical port %s of %s shared with other pods of its VM", logicalPort, podDesc)
+			return nil, nil
+		}
+		_, sharedPortSwitchName, err := kubevirt.FindLogicalSwitchPort(bnc.watchFactory, bnc.lsManager, bnc.NetInfo, pod)
+		if err != nil {
+			return nil, err
+		}
+		if sharedPortSwitchName != "" {
+			expectedSwitchName = sharedPortSwitchName
+		}
+	}
 	if portInfo == nil {
 		// If ovnkube-master restarts, it is also possible the Pod's logical switch port
 		// is not re-added into the cache. Delete logical switch port anyway.
@@ -423,7 +442,7 @@ func (bnc *BaseNetworkController) getExpectedSwitchName(pod *kapi.Pod) (string,
 // controller's zone.
 func (bnc *BaseNetworkController) ensurePodAnnotation(pod *kapi.Pod, nadName string) (*util.PodAnnotation, bool, error) {
 	if kubevirt.IsPodLiveMigratable(pod) {
-		podAnnotation, err := kubevirt.EnsurePodAnnotationForVM(bnc.watchFactory, bnc.kube, pod, bnc.NetInfo, nadName)
+		podAnnotation, err := kubevirt.EnsurePodAnnotationForVM(bnc.watchFactory, bnc.kube, bnc.lsManager, pod, bnc.NetInfo, nadName)
 		if err != nil {
 			return nil, false, err
 		}
@@ -453,6 +472,16 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 		return nil, nil, nil, false, fmt.Errorf("[%s] failed geting expected switch name when adding logical switch port: %v", podDesc, err)
 	}
 
+	// With multi chassis live migration, the target pod of a live migration
+	// binds to the logical switch port of the source pod
+	sharedPortName, sharedPortSwitchName, err := kubevirt.FindLogicalSwitchPort(bnc.watchFactory, bnc.lsManager, bnc.NetInfo, pod)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("[%s] failed finding shared logical switch port: %v", podDesc, err)
+	}
+	if sharedPortSwitchName != "" {
+		switchName = sharedPortSwitchName
+	}
+
 	// it is possible to try to add a pod here that has no node. For example if a pod was deleted with
 	// a finalizer, and then the node was removed. In this case the pod will still exist in a running state.
 	// Terminating pods should still have network connectivity for pre-stop hooks or termination grace period
@@ -473,6 +502,9 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 	}
 
 	portName := bnc.GetLogicalPortName(pod, nadName)
+	if sharedPortName != "" {
+		portName = sharedPortName
+	}
 	klog.Infof("[%s] creating logical port %s for pod on switch %s", podDesc, portName, switchName)
 
 	var addresses []string
@@ -510,6 +542,22 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 		}
 	}
 
+	// Bind the port to the node's chassis; prevents ping-ponging between
+	// chassis if ovnkube-node isn't running correctly and hasn't cleared
+	// out iface-id for an old instance of this pod, and the pod got
+	// rescheduled. The port of a live migrated VM is bound to the chassis
+	// of both the source and target nodes.
+	chassis := []string{pod.Spec.NodeName}
+	if !bnc.IsSecondary() && config.OVNKubernetesFeature.EnableMultiChassisLiveMigration && kubevirt.IsPodLiveMigratable(pod) {
+		vmChassis, err := kubevirt.FindLogicalSwitchPortChassis(bnc.watchFactory, pod, portName)
+		if err != nil {
+			return nil, nil, nil, false, fmt.Errorf("[%s] failed finding the chassis of logical port %s: %v", podDesc, portName, err)
+		}
+		if len(vmChassis) > 0 {
+			chassis = vmChassis
+		}
+	}
+
 	lsp.Options = make(map[string]string)
 	// Unique identifier to distinguish interfaces for recreated pods, also set by ovnkube-node
 	// ovn-controller will claim the OVS interface only if external_ids:iface-id
@@ -522,14 +570,12 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 	// (then ovn-controller won't bind the interface).
 	// May happen on upgrade, because ovnkube-node doesn't update
 	// existing OVS interfaces with new iface-id-ver option.
-	if !lspExist || len(existingLSP.Options["iface-id-ver"]) != 0 {
+	// A port shared by several pods can't match the UID of all of them.
+	portShared := sharedPortName != "" || len(chassis) > 1
+	if !portShared && (!lspExist || len(existingLSP.Options["iface-id-ver"]) != 0) {
 		lsp.Options["iface-id-ver"] = string(pod.UID)
 	}
-	// Bind the port to the node's chassis; prevents ping-ponging between
-	// chassis if ovnkube-node isn't running correctly and hasn't cleared
-	// out iface-id for an old instance of this pod, and the pod got
-	// rescheduled.
-	lsp.Options["requested-chassis"] = pod.Spec.NodeName
+	kubevirt.SetLogicalSwitchPortChassis(lsp, chassis)
 
 	// Although we have different code to allocate the pod annotation for the
 	// default network and secondary networks, at the time of this writing they
@@ -568,6 +614,15 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 	// CNI depends on the flows from port security, delay setting it until end
 	lsp.PortSecurity = addresses
 
+	// On secondary layer2 networks, the addresses of a live migratable pod
+	// follow the VM
+	if bnc.IsSecondary() && kubevirt.IsPodAllowedForMigration(pod, bnc.NetInfo) {
+		ops, err = bnc.setVirtualMachinePortAddresses(pod, nadName, lsp)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	// On layer2 topology with interconnect, we need to add specific port config
 	if bnc.isLayer2Interconnect() {
 		isRemotePort := !bnc.isPodScheduledinLocalZone(pod)
@@ -577,7 +632,7 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 		}
 	}
 
-	ops, err = libovsdbops.CreateOrUpdateLogicalSwitchPortsOnSwitchOps(bnc.nbClient, nil, ls, lsp)
+	ops, err = libovsdbops.CreateOrUpdateLogicalSwitchPortsOnSwitchOps(bnc.nbClient, ops, ls, lsp)
 	if err != nil {
 		return nil, nil, nil, false,
 			fmt.Errorf("error creating logical switch port %+v on switch %+v: %+v", *lsp, *ls, err)
@@ -586,6 +641,52 @@ func (bnc *BaseNetworkController) addLogicalPortToNetwork(pod *kapi.Pod, nadName
 	return ops, lsp, podAnnotation, annotationUpdated && !lspExist, nil
 }
 
+// setVirtualMachinePortAddresses leaves the addresses of the port of a live
+// migratable pod unset unless its VM runs on the pod. Through a live migration
+// the addresses move from the port of the source pod to the port of the target
+// pod when the target pod is ready to receive traffic, so the ops unsetting
+// them from the ports of the other pods of the VM are returned.
+func (bnc *BaseNetworkController) setVirtualMachinePortAddresses(pod *kapi.Pod, nadName string,
+	lsp *nbdb.LogicalSwitchPort) ([]ovsdb.Operation, error) {
+	vmRunningOnPod, err := kubevirt.IsVirtualMachineRunningOnPod(bnc.watchFactory, pod)
+	if err != nil {
+		return nil, err
+	}
+	if !vmRunningOnPod {
+		klog.Infof("VM of pod %s/%s does not run on it, unsetting the addresses of port %s",
+			pod.Namespace, pod.Name, lsp.Name)
+		lsp.Addresses = nil
+		return nil, nil
+	}
+
+	vmPods, err := kubevirt.FindVMRelatedPods(bnc.watchFactory, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding related pods for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	var ops []ovsdb.Operation
+	for _, vmPod := range vmPods {
+		vmPodLSP, err := libovsdbops.GetLogicalSwitchPort(bnc.nbClient,
+			&nbdb.LogicalSwitchPort{Name: bnc.GetLogicalPortName(vmPod, nadName)})
+		if errors.Is(err, libovsdbclient.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the lsp of pod %s/%s from the nbdb: %w", vmPod.Namespace, vmPod.Name, err)
+		}
+		if len(vmPodLSP.Addresses) == 0 {
+			continue
+		}
+		klog.Infof("VM of pod %s/%s moved to pod %s/%s, unsetting the addresses of port %s",
+			vmPod.Namespace, vmPod.Name, pod.Namespace, pod.Name, vmPodLSP.Name)
+		vmPodLSP.Addresses = nil
+		ops, err = libovsdbops.UpdateLogicalSwitchPortAddressesOps(bnc.nbClient, ops, vmPodLSP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unset the addresses of port %s: %w", vmPodLSP.Name, err)
+		}
+	}
+	return ops, nil
+}
+
 func (bnc *BaseNetworkController) updatePodAnnotationWithRetry(origPod *kapi.Pod, podInfo *util.PodAnnotation, nadName string) error {
 	return util.UpdatePodAnnotationWithRetry(
 		bnc.watchFactory.PodCoreInformer().Lister(),
@@ -978,7 +1079,7 @@ func (bnc *BaseNetworkController) allocatesPodAnnotation() bool {
 func (bnc *BaseNetworkController) shouldReleaseDeletedPod(pod *kapi.Pod, switchName, nad string, podIfAddrs []*net.IPNet) (bool, error) {
 	var err error
 	var isMigratedSourcePodStale bool
-	if !bnc.IsSecondary() {
+	if !bnc.IsSecondary() || kubevirt.IsPodAllowedForMigration(pod, bnc.NetInfo) {
 		isMigratedSourcePodStale, err = kubevirt.IsMigratedSourcePodStale(bnc.watchFactory, pod)
 		if err != nil {
 			return false, err
@@ -991,7 +1092,10 @@ func (bnc *BaseNetworkController) shouldReleaseDeletedPod(pod *kapi.Pod, switchN
 		return false, nil
 	}
 
-	if !util.PodCompleted(pod) {
+	// On secondary networks, the IPs of a live migratable pod removed before
+	// completing might still be in use by the other pod of a live migration
+	vmPodOnSecondaryNetwork := bnc.IsSecondary() && kubevirt.IsPodAllowedForMigration(pod, bnc.NetInfo)
+	if !util.PodCompleted(pod) && !vmPodOnSecondaryNetwork {
 		return true, nil
 	}
 
@@ -11,6 +11,7 @@ import (
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
@@ -561,7 +562,7 @@ func (bnc *BaseNetworkController) getNewLocalPolicyPorts(np *networkPolicy,
 				portInfo.name, np.name)
 
 			policyPortUUIDs = append(policyPortUUIDs, portInfo.uuid)
-			policyPortsToUUIDs[portInfo.name] = portInfo.uuid
+			policyPortsToUUIDs[logicalPortName] = portInfo.uuid
 		}
 	}
 
@@ -588,6 +589,19 @@ func (bnc *BaseNetworkController) getExistingLocalPolicyPorts(np *networkPolicy,
 			}
 			portUUID := loadedPortUUID.(string)
 
+			// the logical port shared by the pods of a live migrated
+			// VM is still selected through the other pods
+			if !bnc.IsSecondary() {
+				shared, err := kubevirt.IsLogicalSwitchPortShared(bnc.watchFactory, pod)
+				if err != nil {
+					klog.Warningf("Failed to check if port %s for network policy %s is shared: %v",
+						logicalPortName, np.name, err)
+				}
+				if shared {
+					continue
+				}
+			}
+
 			policyPortsToUUIDs[logicalPortName] = portUUID
 			policyPortUUIDs = append(policyPortUUIDs, portUUID)
 		}
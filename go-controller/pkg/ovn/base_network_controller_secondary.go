@@ -293,6 +293,14 @@ func (bsnc *BaseSecondaryNetworkController) addLogicalPortToNetworkForNAD(pod *k
 		}
 	}
 
+	// enforce the VLAN the pod selected on a VLAN trunk
+	if lsp != nil && podAnnotation.VLANID != 0 {
+		ops, err = bsnc.addPodVLANACLsOps(ops, switchName, lsp.Name, podAnnotation.VLANID)
+		if err != nil {
+			return err
+		}
+	}
+
 	if bsnc.doesNetworkRequireIPAM() && util.IsMultiNetworkPoliciesSupportEnabled() {
 		// Ensure the namespace/nsInfo exists
 		addOps, err := bsnc.addPodToNamespaceForSecondaryNetwork(pod.Namespace, podAnnotation.IPs)
@@ -320,6 +328,12 @@ func (bsnc *BaseSecondaryNetworkController) addLogicalPortToNetworkForNAD(pod *k
 		_ = bsnc.logicalPortCache.add(pod, switchName, nadName, lsp.UUID, podAnnotation.MAC, podAnnotation.IPs)
 	}
 
+	if isLocalPod && lsp != nil && bsnc.DHCP() != nil {
+		if err := bsnc.ensureDHCPOptionsForPod(lsp, podAnnotation.IPs); err != nil {
+			return err
+		}
+	}
+
 	// we need to create the binding ourselves for the remote ports we create on
 	// layer2 topologies with interconnect
 	isRemotePort := !isLocalPod && bsnc.isLayer2Interconnect()
@@ -391,6 +405,12 @@ func (bsnc *BaseSecondaryNetworkController) removePodForSecondaryNetwork(pod *ka
 			return err
 		}
 
+		if len(bsnc.VLANTrunk()) > 0 {
+			if err = bsnc.deletePodVLANACLs(bsnc.GetLogicalPortName(pod, nadName)); err != nil {
+				return err
+			}
+		}
+
 		// do not release IP address if this controller does not handle IP allocation
 		if !bsnc.allocatesPodAnnotation() {
 			continue
@@ -474,6 +494,12 @@ func (bsnc *BaseSecondaryNetworkController) syncPodsForSecondaryNetwork(pods []i
 	// keep track of which pods might have already been released
 	bsnc.trackPodsReleasedBeforeStartup(annotatedLocalPods)
 
+	if len(bsnc.VLANTrunk()) > 0 {
+		if err := bsnc.deleteStalePodVLANACLs(expectedLogicalPorts); err != nil {
+			return err
+		}
+	}
+
 	return bsnc.deleteStaleLogicalSwitchPorts(expectedLogicalPorts)
 }
 
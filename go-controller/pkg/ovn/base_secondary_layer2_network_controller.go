@@ -18,6 +18,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 )
@@ -97,7 +98,7 @@ func (h *baseSecondaryLayer2NetworkControllerEventHandler) AddResource(obj inter
 		if !ok {
 			return fmt.Errorf("could not cast %T object to Node", obj)
 		}
-		return h.oc.addUpdateNodeEvent(node)
+		return h.oc.addUpdateNodeEvent(node, true)
 	default:
 		return h.oc.AddSecondaryNetworkResourceCommon(h.objType, obj)
 	}
@@ -114,7 +115,13 @@ func (h *baseSecondaryLayer2NetworkControllerEventHandler) UpdateResource(oldObj
 		if !ok {
 			return fmt.Errorf("could not cast %T object to Node", newObj)
 		}
-		return h.oc.addUpdateNodeEvent(node)
+		oldNode, ok := oldObj.(*corev1.Node)
+		if !ok {
+			return fmt.Errorf("could not cast %T object to Node", oldObj)
+		}
+		_, gwSync := h.oc.gatewaysFailed.Load(node.Name)
+		gwSync = gwSync || secondaryGatewayChanged(h.oc.GetNetworkName(), oldNode, node)
+		return h.oc.addUpdateNodeEvent(node, gwSync)
 	default:
 		return h.oc.UpdateSecondaryNetworkResourceCommon(h.objType, oldObj, newObj, inRetryCache)
 	}
@@ -248,11 +255,30 @@ func (oc *BaseSecondaryLayer2NetworkController) cleanup(topotype, netName string
 		return fmt.Errorf("failed to get ops for deleting switches of network %s: %v", netName, err)
 	}
 
+	// delete the gateway routers, if any
+	ops, err = libovsdbops.DeleteLogicalRoutersWithPredicateOps(oc.nbClient, ops,
+		func(item *nbdb.LogicalRouter) bool {
+			return item.ExternalIDs[types.NetworkExternalID] == netName
+		})
+	if err != nil {
+		return fmt.Errorf("failed to get ops for deleting routers of network %s: %v", netName, err)
+	}
+
+	ops, err = cleanupGatewayRouters(oc.nbClient, ops, netName)
+	if err != nil {
+		return err
+	}
+
 	ops, err = cleanupPolicyLogicalEntities(oc.nbClient, ops, netName)
 	if err != nil {
 		return err
 	}
 
+	ops, err = cleanupDHCPOptions(oc.nbClient, ops, oc.controllerName, netName)
+	if err != nil {
+		return err
+	}
+
 	_, err = libovsdbops.TransactAndCheck(oc.nbClient, ops)
 	if err != nil {
 		return fmt.Errorf("failed to deleting switches of network %s: %v", netName, err)
@@ -310,6 +336,14 @@ func (oc *BaseSecondaryLayer2NetworkController) initializeLogicalSwitch(switchNa
 		}
 	}
 
+	// let the pods on a VLAN trunk tag their traffic, which OVN drops otherwise
+	if len(oc.VLANTrunk()) > 0 {
+		if logicalSwitch.OtherConfig == nil {
+			logicalSwitch.OtherConfig = map[string]string{}
+		}
+		logicalSwitch.OtherConfig["vlan-passthru"] = "true"
+	}
+
 	if oc.isLayer2Interconnect() {
 		err := oc.zoneICHandler.AddTransitSwitchConfig(&logicalSwitch)
 		if err != nil {
@@ -322,23 +356,41 @@ func (oc *BaseSecondaryLayer2NetworkController) initializeLogicalSwitch(switchNa
 		return nil, fmt.Errorf("failed to create logical switch %+v: %v", logicalSwitch, err)
 	}
 
-	if err = oc.lsManager.AddOrUpdateSwitch(switchName, hostSubnets, excludeSubnets...); err != nil {
+	if err = oc.lsManager.AddOrUpdateSwitchWithIPPools(switchName, hostSubnets, oc.IPPools(), excludeSubnets...); err != nil {
 		return nil, err
 	}
 
 	return &logicalSwitch, nil
 }
 
-func (oc *BaseSecondaryLayer2NetworkController) addUpdateNodeEvent(node *corev1.Node) error {
+func (oc *BaseSecondaryLayer2NetworkController) addUpdateNodeEvent(node *corev1.Node, syncGw bool) error {
 	if oc.isLocalZoneNode(node) {
-		return oc.addUpdateLocalNodeEvent(node)
+		return oc.addUpdateLocalNodeEvent(node, syncGw)
 	}
 	return oc.addUpdateRemoteNodeEvent(node)
 }
 
-func (oc *BaseSecondaryLayer2NetworkController) addUpdateLocalNodeEvent(node *corev1.Node) error {
+func (oc *BaseSecondaryLayer2NetworkController) addUpdateLocalNodeEvent(node *corev1.Node, syncGw bool) error {
 	_, present := oc.localZoneNodes.LoadOrStore(node.Name, true)
 
+	if oc.IsGatewayEnabled() {
+		if !oc.gatewayRouterSupported() {
+			if !present {
+				klog.Warningf("Network %s gateway routers need a single node per zone: removing them", oc.GetNetworkName())
+				if err := oc.deleteStaleGatewayRouters(sets.New[string]()); err != nil {
+					return err
+				}
+			}
+		} else if syncGw || !present {
+			if err := oc.syncGatewayRouter(node, nil); err != nil {
+				oc.gatewaysFailed.Store(node.Name, true)
+				return fmt.Errorf("failed to sync the gateway router of node %s for network %s: %w",
+					node.Name, oc.GetNetworkName(), err)
+			}
+			oc.gatewaysFailed.Delete(node.Name)
+		}
+	}
+
 	if !present {
 		// process all pods so they are reconfigured as local
 		errs := oc.addAllPodsOnNode(node.Name)
@@ -372,11 +424,25 @@ func (oc *BaseSecondaryLayer2NetworkController) addUpdateRemoteNodeEvent(node *c
 }
 
 func (oc *BaseSecondaryLayer2NetworkController) deleteNodeEvent(node *corev1.Node) error {
+	if oc.IsGatewayEnabled() {
+		if err := oc.deleteGatewayRouter(node.Name); err != nil {
+			return err
+		}
+		oc.gatewaysFailed.Delete(node.Name)
+	}
 	oc.localZoneNodes.Delete(node.Name)
+	if oc.IsGatewayEnabled() && oc.gatewayRouterSupported() {
+		// the remaining local node, if any, can have its gateway router back
+		oc.localZoneNodes.Range(func(nodeName, _ interface{}) bool {
+			oc.gatewaysFailed.Store(nodeName, true)
+			return true
+		})
+	}
 	return nil
 }
 
 func (oc *BaseSecondaryLayer2NetworkController) syncNodes(nodes []interface{}) error {
+	foundNodes := sets.New[string]()
 	for _, tmp := range nodes {
 		node, ok := tmp.(*corev1.Node)
 		if !ok {
@@ -385,9 +451,35 @@ func (oc *BaseSecondaryLayer2NetworkController) syncNodes(nodes []interface{}) e
 
 		// Add the node to the foundNodes only if it belongs to the local zone.
 		if oc.isLocalZoneNode(node) {
+			foundNodes.Insert(node.Name)
 			oc.localZoneNodes.Store(node.Name, true)
 		}
 	}
 
+	if oc.IsGatewayEnabled() {
+		if !oc.gatewayRouterSupported() {
+			foundNodes = sets.New[string]()
+		}
+		if err := oc.deleteStaleGatewayRouters(foundNodes); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// gatewayRouterSupported returns whether the local zone nodes can have a
+// network gateway router. Each gateway router joins the network switch with
+// the first IP of each subnet, so only one node per zone can have one, which
+// requires interconnect.
+func (oc *BaseSecondaryLayer2NetworkController) gatewayRouterSupported() bool {
+	if !oc.isLayer2Interconnect() {
+		return false
+	}
+	localNodes := 0
+	oc.localZoneNodes.Range(func(_, _ interface{}) bool {
+		localNodes++
+		return localNodes <= 1
+	})
+	return localNodes <= 1
+}
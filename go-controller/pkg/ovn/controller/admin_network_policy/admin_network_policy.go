@@ -9,6 +9,7 @@ import (
 
 	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
@@ -455,7 +456,7 @@ func (c *Controller) getPortsOfSubject(anpSubject *adminNetworkPolicySubject) ([
 			if util.PodWantsHostNetwork(pod) || util.PodCompleted(pod) || !util.PodScheduled(pod) || !c.isPodScheduledinLocalZone(pod) {
 				continue
 			}
-			logicalPortName := util.GetLogicalPortName(pod.Namespace, pod.Name)
+			logicalPortName := kubevirt.GetPodLogicalPortName(pod)
 			lsp := &nbdb.LogicalSwitchPort{Name: logicalPortName}
 			lsp, err = libovsdbops.GetLogicalSwitchPort(c.nbClient, lsp)
 			if err != nil {
@@ -473,7 +474,10 @@ func (c *Controller) getPortsOfSubject(anpSubject *adminNetworkPolicySubject) ([
 				return nil, fmt.Errorf("error retrieving logical switch port with name %s "+
 					" from libovsdb cache: %w", logicalPortName, err)
 			}
-			ports = append(ports, lsp)
+			// the pods of a live migrated VM may share the same port
+			if !anpSubject.podPorts.Has(lsp.UUID) {
+				ports = append(ports, lsp)
+			}
 			anpSubject.podPorts.Insert(lsp.UUID)
 			podCache.Insert(pod.Name)
 		}
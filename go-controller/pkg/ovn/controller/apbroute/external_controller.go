@@ -109,6 +109,24 @@ func (e *ExternalGatewayRouteInfoCache) Cleanup(podName ktypes.NamespacedName, f
 	})
 }
 
+// ForEach locks the key of every pod in the cache in turn and passes its
+// routeInfo as an argument to function `f`.
+func (e *ExternalGatewayRouteInfoCache) ForEach(f func(routeInfo *RouteInfo) error) error {
+	for _, podName := range e.routeInfos.GetKeys() {
+		err := e.routeInfos.DoWithLock(podName, func(key ktypes.NamespacedName) error {
+			routeInfo, loaded := e.routeInfos.Load(key)
+			if !loaded {
+				return nil
+			}
+			return f(routeInfo)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CleanupNamespace wraps the cleanup call for all the pods in a given namespace.
 // The routeInfo reference for each pod in the given namespace is processed by the `f` function inside the `Cleanup` function
 func (e *ExternalGatewayRouteInfoCache) CleanupNamespace(nsName string, f func(routeInfo *RouteInfo) error) error {
@@ -170,6 +170,11 @@ func (c *ExternalGatewayMasterController) DelHybridRoutePolicyForPod(podIP net.I
 	return c.nbClient.delHybridRoutePolicyForPod(podIP, node)
 }
 
+// SyncNodeHybridRoutePolicies exposes the function syncNodeHybridRoutePolicies
+func (c *ExternalGatewayMasterController) SyncNodeHybridRoutePolicies(node string) error {
+	return c.nbClient.syncNodeHybridRoutePolicies(node)
+}
+
 // DelAllHybridRoutePolicies exposes the function delAllHybridRoutePolicies
 func (c *ExternalGatewayMasterController) DelAllHybridRoutePolicies() error {
 	return c.nbClient.delAllHybridRoutePolicies()
@@ -109,6 +109,55 @@ func (nb *northBoundClient) delAllHybridRoutePolicies() error {
 	return nil
 }
 
+// delNodeHybridRoutePolicies deletes the 501 hybrid-route-policies of the pods
+// of the given node along with their address set. Called when the node
+// switches to shared gateway mode.
+func (nb *northBoundClient) delNodeHybridRoutePolicies(node string) error {
+	inport := fmt.Sprintf(`inport == "%s%s"`, types.RouterToSwitchPrefix, node)
+	p := func(item *nbdb.LogicalRouterPolicy) bool {
+		return item.Priority == types.HybridOverlayReroutePriority && strings.HasPrefix(item.Match, inport)
+	}
+	err := libovsdbops.DeleteLogicalRouterPoliciesWithPredicate(nb.nbClient, types.OVNClusterRouter, p)
+	if err != nil {
+		return fmt.Errorf("error deleting hybrid route policies of node %s on %s: %v", node, types.OVNClusterRouter, err)
+	}
+	if err := nb.addressSetFactory.DestroyAddressSet(GetHybridRouteAddrSetDbIDs(node, nb.controllerName)); err != nil {
+		return fmt.Errorf("failed to remove hybrid route address set of node %s: %v", node, err)
+	}
+	return nil
+}
+
+// syncNodeHybridRoutePolicies adds or deletes the 501 hybrid-route-policies of
+// the pods of the given node with external gateway routes, according to the
+// gateway mode of the node
+func (nb *northBoundClient) syncNodeHybridRoutePolicies(node string) error {
+	if nb.nodeGatewayMode(node) != config.GatewayModeLocal {
+		return nb.delNodeHybridRoutePolicies(node)
+	}
+	gr := types.GWRouterPrefix + node
+	return nb.externalGatewayRouteInfo.ForEach(func(routeInfo *RouteInfo) error {
+		for podIP, routes := range routeInfo.PodExternalRoutes {
+			for _, podGR := range routes {
+				if podGR != gr {
+					continue
+				}
+				if err := nb.addHybridRoutePolicyForPod(net.ParseIP(podIP), node); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// nodeGatewayMode returns the gateway mode of the node, which is the
+// configured gateway mode unless the node is switching to the other one
+func (nb *northBoundClient) nodeGatewayMode(node string) config.GatewayMode {
+	n, _ := nb.nodeLister.Get(node)
+	return util.GetNodeGatewayMode(n)
+}
+
 // delAllLegacyHybridRoutePolicies deletes all the 501 hybrid-route-policies that
 // force pod egress traffic to be rerouted to a gateway router for local gateway mode.
 // New hybrid route matches on address set, while legacy matches just on pod IP
@@ -303,7 +352,7 @@ func (nb *northBoundClient) addGWRoutesForPod(gateways []*gateway_info.GatewayIn
 // AddHybridRoutePolicyForPod handles adding a higher priority allow policy to allow traffic to be routed normally
 // by ecmp routes
 func (nb *northBoundClient) addHybridRoutePolicyForPod(podIP net.IP, node string) error {
-	if config.Gateway.Mode == config.GatewayModeLocal {
+	if nb.nodeGatewayMode(node) == config.GatewayModeLocal {
 		// Add podIP to the node's address_set.
 		asIndex := GetHybridRouteAddrSetDbIDs(node, nb.controllerName)
 		as, err := nb.addressSetFactory.EnsureAddressSet(asIndex)
@@ -537,7 +586,7 @@ func (nb *northBoundClient) deleteLogicalRouterStaticRoute(podIP, mask, gw, gr s
 // DelHybridRoutePolicyForPod handles deleting a logical route policy that
 // forces pod egress traffic to be rerouted to a gateway router for local gateway mode.
 func (nb *northBoundClient) delHybridRoutePolicyForPod(podIP net.IP, node string) error {
-	if config.Gateway.Mode != config.GatewayModeLocal {
+	if nb.nodeGatewayMode(node) != config.GatewayModeLocal {
 		return nil
 	}
 
@@ -34,17 +34,23 @@ func (c *ExternalGatewayMasterController) Repair() error {
 	}()
 
 	// migration from LGW to SGW mode
-	// for shared gateway mode, these LRPs shouldn't exist, so delete them all
-	if config.Gateway.Mode == config.GatewayModeShared {
-		if err := c.nbClient.delAllHybridRoutePolicies(); err != nil {
-			klog.Errorf("Error while removing hybrid policies on moving to SGW mode, error: %v", err)
+	// for nodes in shared gateway mode, these LRPs shouldn't exist, so delete them
+	nodes, err := c.nbClient.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodes {
+		if util.GetNodeGatewayMode(node) == config.GatewayModeLocal {
+			continue
 		}
-	} else if config.Gateway.Mode == config.GatewayModeLocal {
-		// remove all legacy hybrid route policies
-		if err := c.nbClient.delAllLegacyHybridRoutePolicies(); err != nil {
-			klog.Errorf("Error while removing legacy hybrid policies, error: %v", err)
+		if err := c.nbClient.delNodeHybridRoutePolicies(node.Name); err != nil {
+			klog.Errorf("Error while removing hybrid policies of node %s in SGW mode, error: %v", node.Name, err)
 		}
 	}
+	// remove all legacy hybrid route policies
+	if err := c.nbClient.delAllLegacyHybridRoutePolicies(); err != nil {
+		klog.Errorf("Error while removing legacy hybrid policies, error: %v", err)
+	}
 
 	// Build cache of expected routes in the cluster
 	// will be used for cleanup
@@ -168,21 +174,19 @@ func (c *ExternalGatewayMasterController) Repair() error {
 
 	// could be stale hybrid policies with stale addresses in the set that had no corresponding OVN ecmp routes
 	// get all pods, attempt to delete their hybridRoutePolicy that have no policy
-	if config.Gateway.Mode == config.GatewayModeLocal {
-		ovnHybridCache, err := c.buildOVNHybridCache()
-		if err != nil {
-			return fmt.Errorf("failed to build hybrid cache: %w", err)
-		}
-		for ip, node := range ovnHybridCache {
-			// check if this pod IP has a corresponding policy, if not, remove it
-			_, okPolicy := policyGWIPsMap[ip]
-			_, okAnnotation := annotatedGWIPsMap[ip]
-			if !okPolicy && !okAnnotation {
-				klog.Infof("CleanHybridPRoutes: Removing IP: %s from hybrid route policy", ip)
-				if err := c.nbClient.delHybridRoutePolicyForPod(net.ParseIP(ip), node); err != nil {
-					return fmt.Errorf("CleanHybridPRoutes: error while removing hybrid policy for pod IP: %s, on node: %s, error: %v",
-						ip, node, err)
-				}
+	ovnHybridCache, err := c.buildOVNHybridCache()
+	if err != nil {
+		return fmt.Errorf("failed to build hybrid cache: %w", err)
+	}
+	for ip, node := range ovnHybridCache {
+		// check if this pod IP has a corresponding policy, if not, remove it
+		_, okPolicy := policyGWIPsMap[ip]
+		_, okAnnotation := annotatedGWIPsMap[ip]
+		if !okPolicy && !okAnnotation {
+			klog.Infof("CleanHybridPRoutes: Removing IP: %s from hybrid route policy", ip)
+			if err := c.nbClient.delHybridRoutePolicyForPod(net.ParseIP(ip), node); err != nil {
+				return fmt.Errorf("CleanHybridPRoutes: error while removing hybrid policy for pod IP: %s, on node: %s, error: %v",
+					ip, node, err)
 			}
 		}
 	}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"strings"
 
@@ -107,12 +108,18 @@ var protos = []v1.Protocol{
 // - services with host-network endpoints
 // - services with ExternalTrafficPolicy=Local
 // - services with InternalTrafficPolicy=Local
+// - services preserving the client source IP (see util.ServicePreserveSourceIP)
 //
 // Template LBs will be created for
-//   - services with NodePort set but *without* ExternalTrafficPolicy=Local or
-//     affinity timeout set.
+//   - services with NodePort set but *without* ExternalTrafficPolicy=Local,
+//     affinity timeout or health checks set.
+//
+// Services forwarding a port range get a single ClusterIP (and ExternalIP)
+// config with no port, services forwarding all ports a single ExternalIP one.
 func buildServiceLBConfigs(service *v1.Service, endpointSlices []*discovery.EndpointSlice, useLBGroup, useTemplates bool) (perNodeConfigs, templateConfigs, clusterConfigs []lbConfig) {
 	needsAffinityTimeout := hasSessionAffinityTimeOut(service)
+	needsHealthCheck := getLBHealthCheck(service) != nil
+	portRange := getLBPortRange(service)
 
 	// For each svcPort, determine if it will be applied per-node or cluster-wide
 	for _, svcPort := range service.Spec.Ports {
@@ -135,10 +142,10 @@ func buildServiceLBConfigs(service *v1.Service, endpointSlices []*discovery.Endp
 				internalTrafficLocal: false, // always false for non-ClusterIPs
 				hasNodePort:          true,
 			}
-			// Only "plain" NodePort services (no ETP, no affinity timeout)
-			// can use load balancer templates.
+			// Only "plain" NodePort services (no ETP, no affinity timeout,
+			// no health checks) can use load balancer templates.
 			if !useLBGroup || !useTemplates || externalTrafficLocal ||
-				needsAffinityTimeout {
+				needsAffinityTimeout || needsHealthCheck {
 				perNodeConfigs = append(perNodeConfigs, nodePortLBConfig)
 			} else {
 				templateConfigs = append(templateConfigs, nodePortLBConfig)
@@ -149,20 +156,60 @@ func buildServiceLBConfigs(service *v1.Service, endpointSlices []*discovery.Endp
 		vips := util.GetClusterIPs(service)
 		externalVips := util.GetExternalAndLBIPs(service)
 
+		// Port ranges and all ports are forwarded by a single VIP without port,
+		// see getLBPortRange, on behalf of the first service port. All ports
+		// are only forwarded by the external VIPs, the ClusterIPs keep
+		// forwarding the service ports only.
+		inport, externalInport, externalEps := svcPort.Port, svcPort.Port, eps
+		if portRange != nil {
+			firstPort := svcPort.Name == service.Spec.Ports[0].Name
+			rangeEps := eps
+			rangeEps.Port = 0
+			switch {
+			case portRange.AllPorts() && firstPort:
+				externalInport, externalEps = 0, rangeEps
+			case portRange.AllPorts():
+				externalVips = nil
+			case firstPort:
+				inport, externalInport = 0, 0
+				eps, externalEps = rangeEps, rangeEps
+			default:
+				continue
+			}
+		}
+
 		// if ETP=Local, then treat ExternalIPs and LoadBalancer IPs specially
 		// otherwise, they're just cluster IPs
+		// Services preserving the client source IP get the same treatment: the
+		// node gateway bridges steer external traffic to the nodes hosting
+		// endpoints, so the router load balancers only need the local ones.
 		// This is NEVER influenced by InternalTrafficPolicy
-		if externalTrafficLocal && len(externalVips) > 0 {
+		if (externalTrafficLocal || util.ServicePreserveSourceIP(service)) && len(externalVips) > 0 {
 			externalIPConfig := lbConfig{
 				protocol:             svcPort.Protocol,
-				inport:               svcPort.Port,
+				inport:               externalInport,
 				vips:                 externalVips,
-				eps:                  eps,
+				eps:                  externalEps,
 				externalTrafficLocal: true,
 				internalTrafficLocal: false, // always false for non-ClusterIPs
 				hasNodePort:          false,
 			}
 			perNodeConfigs = append(perNodeConfigs, externalIPConfig)
+		} else if externalInport != inport && len(externalVips) > 0 {
+			externalIPConfig := lbConfig{
+				protocol:             svcPort.Protocol,
+				inport:               externalInport,
+				vips:                 externalVips,
+				eps:                  externalEps,
+				externalTrafficLocal: false,
+				internalTrafficLocal: false, // always false for non-ClusterIPs
+				hasNodePort:          false,
+			}
+			if hasHostEndpoints(externalEps.V4IPs) || hasHostEndpoints(externalEps.V6IPs) {
+				perNodeConfigs = append(perNodeConfigs, externalIPConfig)
+			} else {
+				clusterConfigs = append(clusterConfigs, externalIPConfig)
+			}
 		} else {
 			vips = append(vips, externalVips...)
 		}
@@ -171,7 +218,7 @@ func buildServiceLBConfigs(service *v1.Service, endpointSlices []*discovery.Endp
 		// This is NEVER influenced by ExternalTrafficPolicy
 		clusterIPConfig := lbConfig{
 			protocol:             svcPort.Protocol,
-			inport:               svcPort.Port,
+			inport:               inport,
 			vips:                 vips,
 			eps:                  eps,
 			externalTrafficLocal: false, // always false for ClusterIPs
@@ -731,6 +778,121 @@ func hasSessionAffinityTimeOut(service *v1.Service) bool {
 		getSessionAffinityTimeOut(service) > 0
 }
 
+// getLBHealthCheck returns the load balancer health check configuration requested
+// by the service, or nil if load balancer health checks are disabled or not
+// requested.
+func getLBHealthCheck(service *v1.Service) *util.LBHealthCheckConfig {
+	if !config.Kubernetes.OVNLBHealthChecks {
+		return nil
+	}
+	hc, err := util.ParseLBHealthCheckAnnotation(service)
+	if err != nil {
+		klog.Errorf("Ignoring load balancer health checks for service %s/%s: %v",
+			service.Namespace, service.Name, err)
+		return nil
+	}
+	if hc != nil && getLBPortRange(service) != nil {
+		klog.Errorf("Ignoring load balancer health checks for service %s/%s: not supported with port ranges",
+			service.Namespace, service.Name)
+		return nil
+	}
+	return hc
+}
+
+// getLBPortRange returns the range of ports forwarded by the service VIPs, or
+// nil if only the service ports are. OVN load balancer VIPs without port forward
+// all ports and protocols to the same ports of the backends, so a port range
+// takes a single VIP whatever its size. The range itself is enforced by the
+// nodes on traffic entering the cluster, traffic from within the cluster can
+// reach the other ports of the endpoints, as it can through their own IPs.
+// All ports and protocols, ICMP included, are only forwarded by the external
+// VIPs: the ClusterIPs keep forwarding the service ports only.
+func getLBPortRange(service *v1.Service) *util.LBPortRange {
+	portRange, err := util.ParseLBPortRangeAnnotation(service)
+	if err != nil {
+		klog.Errorf("Ignoring load balancer port range for service %s/%s: %v",
+			service.Namespace, service.Name, err)
+		return nil
+	}
+	return portRange
+}
+
+// getLBSelectionFields returns the load balancer selection fields requested by
+// the service, or nil if the default OVN backend selection is to be used.
+func getLBSelectionFields(service *v1.Service) []string {
+	fields, err := util.ParseLBSelectionFieldsAnnotation(service)
+	if err != nil {
+		klog.Errorf("Ignoring load balancer selection fields for service %s/%s: %v",
+			service.Namespace, service.Name, err)
+		return nil
+	}
+	return fields
+}
+
+// setLBIPPortMappings sets, on the load balancers that have health checks
+// enabled, the mappings of every pod backend to its logical switch port and to
+// the health check source address of the node switch the pod is attached to.
+// Backends that are not local to any of the nodes (host-network, or remote
+// zone endpoints) are not health checked.
+func setLBIPPortMappings(lbs []LB, endpointSlices []*discovery.EndpointSlice, nodes []nodeInfo) {
+	var mappings map[string]string
+	for i := range lbs {
+		if lbs[i].Opts.HealthCheck == nil || lbs[i].Opts.Template {
+			continue
+		}
+		if mappings == nil {
+			mappings = buildLBIPPortMappings(endpointSlices, nodes)
+		}
+		lbs[i].IPPortMappings = mappings
+	}
+}
+
+// buildLBIPPortMappings builds the OVN load balancer ip_port_mappings for the
+// pod endpoints of the provided endpoint slices, in the form of
+// backend_ip -> logical_port:source_ip.
+func buildLBIPPortMappings(endpointSlices []*discovery.EndpointSlice, nodes []nodeInfo) map[string]string {
+	mappings := map[string]string{}
+	for _, slice := range endpointSlices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			logicalPort := util.GetLogicalPortName(endpoint.TargetRef.Namespace, endpoint.TargetRef.Name)
+			for _, address := range endpoint.Addresses {
+				ip := utilnet.ParseIPSloppy(address)
+				if ip == nil {
+					continue
+				}
+				srcIP := getLBHealthCheckSourceIP(ip, nodes)
+				if srcIP == nil {
+					continue
+				}
+				if utilnet.IsIPv6(ip) {
+					mappings["["+ip.String()+"]"] = fmt.Sprintf("%s:[%s]", logicalPort, srcIP)
+				} else {
+					mappings[ip.String()] = fmt.Sprintf("%s:%s", logicalPort, srcIP)
+				}
+			}
+		}
+	}
+	return mappings
+}
+
+// getLBHealthCheckSourceIP returns the health check source address of the node
+// switch subnet the provided pod IP belongs to, or nil if it doesn't belong to
+// any of the nodes pod subnets.
+func getLBHealthCheckSourceIP(ip net.IP, nodes []nodeInfo) net.IP {
+	for _, node := range nodes {
+		for i := range node.podSubnets {
+			subnet := node.podSubnets[i]
+			if subnet.Contains(ip) {
+				return util.GetNodeLBHealthCheckIfAddr(&subnet).IP
+			}
+		}
+	}
+	return nil
+}
+
 // lbOpts generates the OVN load balancer options from the kubernetes Service.
 func lbOpts(service *v1.Service) LBOpts {
 	affinity := service.Spec.SessionAffinity == v1.ServiceAffinityClientIP
@@ -762,6 +924,9 @@ func lbOpts(service *v1.Service) LBOpts {
 	if affinity {
 		lbOptions.AffinityTimeOut = getSessionAffinityTimeOut(service)
 	}
+
+	lbOptions.HealthCheck = getLBHealthCheck(service)
+	lbOptions.SelectionFields = getLBSelectionFields(service)
 	return lbOptions
 }
 
@@ -771,6 +936,9 @@ func lbTemplateOpts(service *v1.Service, addressFamily v1.IPFamily) LBOpts {
 	// Only template LBs need an explicit address family.
 	lbOptions.AddressFamily = addressFamily
 	lbOptions.Template = true
+
+	// Health checks are not supported on template LBs.
+	lbOptions.HealthCheck = nil
 	return lbOptions
 }
 
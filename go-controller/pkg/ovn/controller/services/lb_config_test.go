@@ -2039,3 +2039,412 @@ func Test_idledServices(t *testing.T) {
 		})
 	}
 }
+
+func Test_lbHealthChecks(t *testing.T) {
+	serviceName := "foo"
+	ns := "testns"
+
+	globalconfig.Kubernetes.OVNLBHealthChecks = true
+	defer func() {
+		globalconfig.Kubernetes.OVNLBHealthChecks = false
+	}()
+
+	tc := []struct {
+		name     string
+		service  *v1.Service
+		expected LBOpts
+	}{
+		{
+			name: "service without health checks",
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{}},
+			},
+			expected: LBOpts{
+				Reject: true,
+			},
+		},
+		{
+			name: "service with default health checks",
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+					"k8s.ovn.org/lb-health-check": "{}",
+				}},
+			},
+			expected: LBOpts{
+				Reject:      true,
+				HealthCheck: &util.LBHealthCheckConfig{},
+			},
+		},
+		{
+			name: "service with custom health checks",
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+					"k8s.ovn.org/lb-health-check": `{"interval": 2, "timeout": 10, "successCount": 1, "failureCount": 2}`,
+				}},
+			},
+			expected: LBOpts{
+				Reject:      true,
+				HealthCheck: &util.LBHealthCheckConfig{Interval: 2, Timeout: 10, SuccessCount: 1, FailureCount: 2},
+			},
+		},
+		{
+			name: "service with invalid health checks",
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+					"k8s.ovn.org/lb-health-check": `{"interval": -1}`,
+				}},
+			},
+			expected: LBOpts{
+				Reject: true,
+			},
+		},
+	}
+
+	for i, tt := range tc {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.name), func(t *testing.T) {
+			actualLbOpts := lbOpts(tt.service)
+			assert.Equal(t, tt.expected, actualLbOpts)
+		})
+	}
+}
+
+func Test_buildLBIPPortMappings(t *testing.T) {
+	nodes := []nodeInfo{
+		{
+			name:       "node-a",
+			podSubnets: []net.IPNet{{IP: net.ParseIP("10.128.0.0"), Mask: net.CIDRMask(24, 32)}, {IP: net.ParseIP("fe00::"), Mask: net.CIDRMask(64, 128)}},
+		},
+		{
+			name:       "node-b",
+			podSubnets: []net.IPNet{{IP: net.ParseIP("10.128.1.0"), Mask: net.CIDRMask(24, 32)}},
+		},
+	}
+	endpointSlices := []*discovery.EndpointSlice{
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "foo-ab23", Namespace: "testns"},
+			AddressType: discovery.AddressTypeIPv4,
+			Endpoints: []discovery.Endpoint{
+				{
+					Addresses: []string{"10.128.0.5"},
+					TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "testns", Name: "pod-a"},
+				},
+				{
+					Addresses: []string{"10.128.1.5"},
+					TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "testns", Name: "pod-b"},
+				},
+				{
+					// host-network endpoint
+					Addresses: []string{"192.168.0.1"},
+					TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "testns", Name: "pod-c"},
+				},
+				{
+					// not a pod
+					Addresses: []string{"10.128.1.6"},
+				},
+			},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "foo-ab24", Namespace: "testns"},
+			AddressType: discovery.AddressTypeIPv6,
+			Endpoints: []discovery.Endpoint{
+				{
+					Addresses: []string{"fe00::5"},
+					TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "testns", Name: "pod-a"},
+				},
+			},
+		},
+	}
+
+	expected := map[string]string{
+		"10.128.0.5": "testns_pod-a:10.128.0.4",
+		"10.128.1.5": "testns_pod-b:10.128.1.4",
+		"[fe00::5]":  "testns_pod-a:[fe00::4]",
+	}
+	assert.Equal(t, expected, buildLBIPPortMappings(endpointSlices, nodes))
+}
+
+func Test_lbSelectionFields(t *testing.T) {
+	serviceName := "foo"
+	ns := "testns"
+
+	tc := []struct {
+		name       string
+		annotation string
+		expected   []string
+	}{
+		{
+			name:       "source IP preset",
+			annotation: "src-ip",
+			expected:   []string{"ip_src"},
+		},
+		{
+			name:       "5-tuple preset",
+			annotation: "5-tuple",
+			expected:   []string{"ip_dst", "ip_src", "tp_dst", "tp_src"},
+		},
+		{
+			name:       "explicit fields",
+			annotation: "tp_src, ip_src,ip_src",
+			expected:   []string{"ip_src", "tp_src"},
+		},
+		{
+			name:       "invalid fields",
+			annotation: "ip_src,foo",
+			expected:   nil,
+		},
+	}
+
+	for i, tt := range tc {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.name), func(t *testing.T) {
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+					"k8s.ovn.org/lb-selection-fields": tt.annotation,
+				}},
+			}
+			assert.Equal(t, tt.expected, lbOpts(service).SelectionFields)
+			assert.Equal(t, tt.expected, lbTemplateOpts(service, v1.IPv4Protocol).SelectionFields)
+		})
+	}
+}
+
+func Test_buildServiceLBConfigsPreserveSourceIP(t *testing.T) {
+	oldClusterSubnet := globalconfig.Default.ClusterSubnets
+	oldGwMode := globalconfig.Gateway.Mode
+	defer func() {
+		globalconfig.Gateway.Mode = oldGwMode
+		globalconfig.Default.ClusterSubnets = oldClusterSubnet
+	}()
+	_, cidr4, _ := net.ParseCIDR("10.128.0.0/16")
+	globalconfig.Default.ClusterSubnets = []globalconfig.CIDRNetworkEntry{{CIDR: cidr4, HostSubnetLength: 26}}
+
+	serviceName := "foo"
+	ns := "testns"
+	inport := int32(80)
+	outport := int32(8080)
+	tcp := v1.ProtocolTCP
+	portName := "port80"
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+			"k8s.ovn.org/preserve-source-ip": "true",
+		}},
+		Spec: v1.ServiceSpec{
+			Type:                  v1.ServiceTypeLoadBalancer,
+			ClusterIP:             "192.168.1.1",
+			ClusterIPs:            []string{"192.168.1.1"},
+			ExternalIPs:           []string{"4.2.2.2"},
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeCluster,
+			Ports: []v1.ServicePort{{
+				Name:       portName,
+				Port:       inport,
+				Protocol:   tcp,
+				TargetPort: intstr.FromInt(int(outport)),
+			}},
+		},
+	}
+	slices := []*discovery.EndpointSlice{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName + "ab1",
+			Namespace: ns,
+			Labels:    map[string]string{discovery.LabelServiceName: serviceName},
+		},
+		Ports: []discovery.EndpointPort{{
+			Protocol: &tcp,
+			Port:     &outport,
+			Name:     &portName,
+		}},
+		AddressType: discovery.AddressTypeIPv4,
+		Endpoints: []discovery.Endpoint{{
+			Conditions: discovery.EndpointConditions{Ready: utilpointer.Bool(true)},
+			Addresses:  []string{"10.128.0.2"},
+		}},
+	}}
+	eps := util.LbEndpoints{V4IPs: []string{"10.128.0.2"}, V6IPs: []string{}, Port: outport}
+
+	tc := []struct {
+		name                string
+		gatewayMode         globalconfig.GatewayMode
+		expectedPerNode     []lbConfig
+		expectedClusterVIPs []string
+	}{
+		{
+			name:        "shared gateway mode",
+			gatewayMode: globalconfig.GatewayModeShared,
+			expectedPerNode: []lbConfig{{
+				vips:                 []string{"4.2.2.2"},
+				protocol:             tcp,
+				inport:               inport,
+				eps:                  eps,
+				externalTrafficLocal: true,
+			}},
+			expectedClusterVIPs: []string{"192.168.1.1"},
+		},
+		{
+			name:                "local gateway mode",
+			gatewayMode:         globalconfig.GatewayModeLocal,
+			expectedPerNode:     nil,
+			expectedClusterVIPs: []string{"192.168.1.1", "4.2.2.2"},
+		},
+	}
+
+	for i, tt := range tc {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.name), func(t *testing.T) {
+			globalconfig.Gateway.Mode = tt.gatewayMode
+			perNode, template, cluster := buildServiceLBConfigs(service, slices, true, true)
+			assert.Equal(t, tt.expectedPerNode, perNode)
+			assert.Empty(t, template)
+			assert.Len(t, cluster, 1)
+			assert.Equal(t, tt.expectedClusterVIPs, cluster[0].vips)
+		})
+	}
+}
+
+func Test_buildServiceLBConfigsPortRange(t *testing.T) {
+	oldClusterSubnet := globalconfig.Default.ClusterSubnets
+	defer func() {
+		globalconfig.Default.ClusterSubnets = oldClusterSubnet
+	}()
+	_, cidr4, _ := net.ParseCIDR("10.128.0.0/16")
+	globalconfig.Default.ClusterSubnets = []globalconfig.CIDRNetworkEntry{{CIDR: cidr4, HostSubnetLength: 26}}
+
+	serviceName := "foo"
+	ns := "testns"
+	inport := int32(5060)
+	tcp := v1.ProtocolTCP
+	udp := v1.ProtocolUDP
+	portName := "sip"
+	portName1 := "sip-udp"
+
+	makeService := func(annotation string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: ns, Annotations: map[string]string{
+				"k8s.ovn.org/lb-port-range": annotation,
+			}},
+			Spec: v1.ServiceSpec{
+				Type:        v1.ServiceTypeClusterIP,
+				ClusterIP:   "192.168.1.1",
+				ClusterIPs:  []string{"192.168.1.1"},
+				ExternalIPs: []string{"4.2.2.2"},
+				Ports: []v1.ServicePort{
+					{
+						Name:       portName,
+						Port:       inport,
+						Protocol:   tcp,
+						TargetPort: intstr.FromInt(int(inport)),
+					},
+					{
+						Name:       portName1,
+						Port:       inport,
+						Protocol:   udp,
+						TargetPort: intstr.FromInt(int(inport)),
+					},
+				},
+			},
+		}
+	}
+	slices := []*discovery.EndpointSlice{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName + "ab1",
+			Namespace: ns,
+			Labels:    map[string]string{discovery.LabelServiceName: serviceName},
+		},
+		Ports: []discovery.EndpointPort{
+			{
+				Protocol: &tcp,
+				Port:     &inport,
+				Name:     &portName,
+			},
+			{
+				Protocol: &udp,
+				Port:     &inport,
+				Name:     &portName1,
+			},
+		},
+		AddressType: discovery.AddressTypeIPv4,
+		Endpoints: []discovery.Endpoint{{
+			Conditions: discovery.EndpointConditions{Ready: utilpointer.Bool(true)},
+			Addresses:  []string{"10.128.0.2"},
+		}},
+	}}
+	makeVIPsConfig := func(vips []string, proto v1.Protocol, port int32) lbConfig {
+		return lbConfig{
+			vips:     vips,
+			protocol: proto,
+			inport:   port,
+			eps:      util.LbEndpoints{V4IPs: []string{"10.128.0.2"}, V6IPs: []string{}, Port: port},
+		}
+	}
+	makeConfig := func(proto v1.Protocol, port int32) lbConfig {
+		return makeVIPsConfig([]string{"192.168.1.1", "4.2.2.2"}, proto, port)
+	}
+
+	tc := []struct {
+		name            string
+		annotation      string
+		expectedCluster []lbConfig
+	}{
+		{
+			// the ClusterIP does not expose all ports and protocols
+			name:       "all ports",
+			annotation: "all",
+			expectedCluster: []lbConfig{
+				makeVIPsConfig([]string{"4.2.2.2"}, tcp, 0),
+				makeVIPsConfig([]string{"192.168.1.1"}, tcp, inport),
+				makeVIPsConfig([]string{"192.168.1.1"}, udp, inport),
+			},
+		},
+		{
+			name:       "port range",
+			annotation: "10000-10001",
+			expectedCluster: []lbConfig{
+				makeConfig(tcp, 0),
+			},
+		},
+		{
+			name:       "port range of thousands of ports",
+			annotation: "10000-19999",
+			expectedCluster: []lbConfig{
+				makeConfig(tcp, 0),
+			},
+		},
+		{
+			name:       "invalid port range",
+			annotation: "10001-10000",
+			expectedCluster: []lbConfig{
+				makeConfig(tcp, inport),
+				makeConfig(udp, inport),
+			},
+		},
+	}
+
+	for i, tt := range tc {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.name), func(t *testing.T) {
+			perNode, template, cluster := buildServiceLBConfigs(makeService(tt.annotation), slices, true, true)
+			assert.Empty(t, perNode)
+			assert.Empty(t, template)
+			assert.Equal(t, tt.expectedCluster, cluster)
+		})
+	}
+}
+
+func Test_buildClusterLBsAllPorts(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "testns"},
+	}
+	configs := []lbConfig{{
+		vips:     []string{"192.168.1.1", "fe80::1"},
+		protocol: v1.ProtocolTCP,
+		inport:   0,
+		eps: util.LbEndpoints{
+			V4IPs: []string{"10.128.0.2"},
+			V6IPs: []string{"fe00::2"},
+			Port:  0,
+		},
+	}}
+	lbs := buildClusterLBs(service, configs, nil, true)
+	assert.Len(t, lbs, 1)
+	assert.Equal(t, map[string]string{
+		"192.168.1.1": "10.128.0.2",
+		"fe80::1":     "fe00::2",
+	}, buildVipMap(lbs[0].Rules))
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/metrics"
@@ -30,6 +31,10 @@ type LB struct {
 
 	Templates TemplateMap // Templates that this LB uses as backends.
 
+	// IPPortMappings maps backend IPs to their logical port and health check
+	// source IP, only used when health checks are enabled.
+	IPPortMappings map[string]string
+
 	// the names of logical switches, routers and LB groups that this LB should be attached to
 	Switches []string
 	Routers  []string
@@ -54,6 +59,13 @@ type LBOpts struct {
 
 	// Only useful for template LBs.
 	AddressFamily corev1.IPFamily
+
+	// If not nil, then enable health checks for the LB vips.
+	HealthCheck *util.LBHealthCheckConfig
+
+	// If not empty, then select backends by hashing these fields with
+	// consistent hashing instead of the default datapath hash.
+	SelectionFields []string
 }
 
 type Addr struct {
@@ -68,7 +80,10 @@ type LBRule struct {
 }
 
 func (a *Addr) String() string {
-	if a.Template == nil {
+	if a.Template == nil && a.Port == 0 {
+		// VIPs without port forward all ports and protocols
+		return a.IP
+	} else if a.Template == nil {
 		return util.JoinHostPortInt32(a.IP, a.Port)
 	} else if a.Port != 0 {
 		return fmt.Sprintf("%s:%d", a.Template.toReferenceString(), a.Port)
@@ -90,8 +105,9 @@ func addrsToString(addrs []Addr) string {
 // templateLoadBalancer enriches a NB load balancer record with the
 // associated template maps it requires provisioned in the NB database.
 type templateLoadBalancer struct {
-	nbLB      *nbdb.LoadBalancer
-	templates TemplateMap
+	nbLB         *nbdb.LoadBalancer
+	templates    TemplateMap
+	healthChecks []*nbdb.LoadBalancerHealthCheck
 }
 
 func toNBLoadBalancerList(tlbs []*templateLoadBalancer) []*nbdb.LoadBalancer {
@@ -175,7 +191,33 @@ func EnsureLBs(nbClient libovsdbclient.Client, service *corev1.Service, existing
 		mapLBDifferenceByKey(removeLBsFromGroups, existingGroups, wantGroups, blb)
 	}
 
-	ops, err := libovsdbops.CreateOrUpdateLoadBalancersOps(nbClient, nil, toNBLoadBalancerList(tlbs)...)
+	var ops []libovsdb.Operation
+	var err error
+	wantedHealthChecks := sets.New[string]()
+	for _, tlb := range tlbs {
+		ops, err = libovsdbops.CreateOrUpdateLoadBalancerHealthChecksOps(nbClient, ops, tlb.nbLB, tlb.healthChecks...)
+		if err != nil {
+			return fmt.Errorf("failed to create ops for ensuring health checks of load balancer %s for service %s/%s: %w",
+				tlb.nbLB.Name, service.Namespace, service.Name, err)
+		}
+		for _, hc := range tlb.healthChecks {
+			wantedHealthChecks.Insert(hc.UUID)
+		}
+	}
+
+	// Delete the health checks of this service that are not wanted anymore,
+	// either because their vip or load balancer is gone or because health
+	// checks got disabled.
+	ops, err = libovsdbops.DeleteLoadBalancerHealthChecksWithPredicateOps(nbClient, ops, func(item *nbdb.LoadBalancerHealthCheck) bool {
+		return item.ExternalIDs[types.LoadBalancerOwnerExternalID] == externalIDs[types.LoadBalancerOwnerExternalID] &&
+			!wantedHealthChecks.Has(item.UUID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create ops for removing stale health checks for service %s/%s: %w",
+			service.Namespace, service.Name, err)
+	}
+
+	ops, err = libovsdbops.CreateOrUpdateLoadBalancersOps(nbClient, ops, toNBLoadBalancerList(tlbs)...)
 	if err != nil {
 		return err
 	}
@@ -376,12 +418,69 @@ func buildLB(lb *LB) *templateLoadBalancer {
 		}
 	}
 
+	nbLB := libovsdbops.BuildLoadBalancer(lb.Name, strings.ToLower(lb.Protocol), buildVipMap(lb.Rules), options, lb.ExternalIDs)
+
+	// Selection fields
+	// If set, OVN switches the OVS select group from dp_hash to hash over the
+	// provided fields, which uses consistent hashing (HRW) to pick a backend.
+	// Always set a non-nil value so that previously set fields get cleared.
+	nbLB.SelectionFields = make([]nbdb.LoadBalancerSelectionFields, 0, len(lb.Opts.SelectionFields))
+	for _, field := range lb.Opts.SelectionFields {
+		nbLB.SelectionFields = append(nbLB.SelectionFields, nbdb.LoadBalancerSelectionFields(field))
+	}
+
+	// Health checks
+	// If enabled, OVN monitors every backend for which an ip_port_mapping exists
+	// and removes the ones that are offline from the vip backends.
+	var healthChecks []*nbdb.LoadBalancerHealthCheck
+	if lb.Opts.HealthCheck != nil && !lb.Opts.Template && lb.Protocol != string(corev1.ProtocolSCTP) {
+		healthChecks = buildLBHealthChecks(lb)
+		nbLB.IPPortMappings = lb.IPPortMappings
+	}
+	if nbLB.IPPortMappings == nil {
+		// make sure to clear existing mappings when health checks get disabled
+		nbLB.IPPortMappings = map[string]string{}
+	}
+
 	return &templateLoadBalancer{
-		nbLB:      libovsdbops.BuildLoadBalancer(lb.Name, strings.ToLower(lb.Protocol), buildVipMap(lb.Rules), options, lb.ExternalIDs),
-		templates: lb.Templates,
+		nbLB:         nbLB,
+		templates:    lb.Templates,
+		healthChecks: healthChecks,
 	}
 }
 
+// buildLBHealthChecks returns one health check per vip of the load balancer
+func buildLBHealthChecks(lb *LB) []*nbdb.LoadBalancerHealthCheck {
+	options := map[string]string{}
+	if lb.Opts.HealthCheck.Interval > 0 {
+		options["interval"] = fmt.Sprintf("%d", lb.Opts.HealthCheck.Interval)
+	}
+	if lb.Opts.HealthCheck.Timeout > 0 {
+		options["timeout"] = fmt.Sprintf("%d", lb.Opts.HealthCheck.Timeout)
+	}
+	if lb.Opts.HealthCheck.SuccessCount > 0 {
+		options["success_count"] = fmt.Sprintf("%d", lb.Opts.HealthCheck.SuccessCount)
+	}
+	if lb.Opts.HealthCheck.FailureCount > 0 {
+		options["failure_count"] = fmt.Sprintf("%d", lb.Opts.HealthCheck.FailureCount)
+	}
+
+	healthChecks := make([]*nbdb.LoadBalancerHealthCheck, 0, len(lb.Rules))
+	for _, r := range lb.Rules {
+		externalIDs := make(map[string]string, len(lb.ExternalIDs)+1)
+		for k, v := range lb.ExternalIDs {
+			externalIDs[k] = v
+		}
+		externalIDs[types.LoadBalancerNameExternalID] = lb.Name
+		healthChecks = append(healthChecks, &nbdb.LoadBalancerHealthCheck{
+			Vip:         r.Source.String(),
+			Options:     options,
+			ExternalIDs: externalIDs,
+		})
+	}
+	return healthChecks
+}
+
 // buildVipMap returns a viups map from a set of rules
 func buildVipMap(rules []LBRule) map[string]string {
 	vipMap := make(map[string]string, len(rules))
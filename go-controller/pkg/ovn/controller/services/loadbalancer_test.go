@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"testing"
 
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -88,3 +91,95 @@ func TestEnsureLBs(t *testing.T) {
 		t.Fatalf("EnsureLBs did not set UUID of cached LB as is should")
 	}
 }
+
+func TestEnsureLBsHealthChecks(t *testing.T) {
+	nbClient, cleanup, err := libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{}, nil)
+	if err != nil {
+		t.Fatalf("Error creating NB: %v", err)
+	}
+	t.Cleanup(cleanup.Cleanup)
+	name := "foo"
+	namespace := "testns"
+	defaultExternalIDs := map[string]string{
+		types.LoadBalancerKindExternalID:  "Service",
+		types.LoadBalancerOwnerExternalID: fmt.Sprintf("%s/%s", namespace, name),
+	}
+
+	defaultService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeClusterIP,
+		},
+	}
+
+	LBs := []LB{
+		{
+			Name:        "Service_testns/foo_TCP_cluster",
+			ExternalIDs: defaultExternalIDs,
+			Protocol:    "TCP",
+			Opts:        LBOpts{HealthCheck: &util.LBHealthCheckConfig{Interval: 2}},
+			Rules: []LBRule{
+				{
+					Source:  Addr{IP: "192.168.1.1", Port: 80},
+					Targets: []Addr{{IP: "10.128.0.5", Port: 8080}},
+				},
+				{
+					Source:  Addr{IP: "fe10::1", Port: 80},
+					Targets: []Addr{{IP: "fe00::5", Port: 8080}},
+				},
+			},
+			IPPortMappings: map[string]string{
+				"10.128.0.5": "testns_pod-a:10.128.0.4",
+				"[fe00::5]":  "testns_pod-a:[fe00::4]",
+			},
+		},
+	}
+	err = EnsureLBs(nbClient, defaultService, nil, LBs)
+	if err != nil {
+		t.Fatalf("Error EnsureLBs: %v", err)
+	}
+
+	lbs, err := libovsdbops.ListLoadBalancers(nbClient)
+	if err != nil {
+		t.Fatalf("Error listing load balancers: %v", err)
+	}
+	if len(lbs) != 1 || len(lbs[0].HealthCheck) != 2 || len(lbs[0].IPPortMappings) != 2 {
+		t.Fatalf("Expected one load balancer with 2 health checks and 2 ip port mappings, got %+v", lbs)
+	}
+	hcs, err := libovsdbops.ListLoadBalancerHealthChecks(nbClient)
+	if err != nil {
+		t.Fatalf("Error listing health checks: %v", err)
+	}
+	vips := []string{}
+	for _, hc := range hcs {
+		if hc.Options["interval"] != "2" || hc.ExternalIDs[types.LoadBalancerNameExternalID] != LBs[0].Name {
+			t.Fatalf("Unexpected health check %+v", hc)
+		}
+		vips = append(vips, hc.Vip)
+	}
+	assert.ElementsMatch(t, []string{"192.168.1.1:80", "[fe10::1]:80"}, vips)
+
+	// disabling health checks removes them
+	existingLBs := make([]LB, len(LBs))
+	copy(existingLBs, LBs)
+	LBs[0].Opts.HealthCheck = nil
+	LBs[0].IPPortMappings = nil
+	err = EnsureLBs(nbClient, defaultService, existingLBs, LBs)
+	if err != nil {
+		t.Fatalf("Error EnsureLBs: %v", err)
+	}
+	lbs, err = libovsdbops.ListLoadBalancers(nbClient)
+	if err != nil {
+		t.Fatalf("Error listing load balancers: %v", err)
+	}
+	if len(lbs) != 1 || len(lbs[0].HealthCheck) != 0 || len(lbs[0].IPPortMappings) != 0 {
+		t.Fatalf("Expected one load balancer without health checks, got %+v", lbs)
+	}
+	hcs, err = libovsdbops.ListLoadBalancerHealthChecks(nbClient)
+	if err != nil {
+		t.Fatalf("Error listing health checks: %v", err)
+	}
+	if len(hcs) != 0 {
+		t.Fatalf("Expected health checks to be removed, got %+v", hcs)
+	}
+}
@@ -4,10 +4,17 @@ import (
 	"net"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 
+	libovsdbcache "github.com/ovn-org/libovsdb/cache"
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+
 	globalconfig "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
@@ -31,6 +38,14 @@ type nodeTracker struct {
 	// resyncFn is the function to call so that all service are resynced
 	resyncFn func(nodes []nodeInfo)
 
+	// lbHealthCheckResyncFn is the function to call so that the health checked
+	// services are resynced
+	lbHealthCheckResyncFn func(nodes []nodeInfo)
+
+	// lbHealthCheckSources is the map of switch name -> load balancer health
+	// check source addresses reserved on the switch
+	lbHealthCheckSources map[string]sets.Set[string]
+
 	// zone in which this nodeTracker is tracking
 	zone string
 }
@@ -50,6 +65,8 @@ type nodeInfo struct {
 	switchName string
 	// The chassisID of the node (ovs.external-ids:system-id)
 	chassisID string
+	// The load balancer health check source addresses reserved on the switch
+	lbHealthCheckSources sets.Set[string]
 
 	// The node's zone
 	zone string
@@ -96,11 +113,13 @@ func (ni *nodeInfo) nodeSubnets() []net.IPNet {
 	return out
 }
 
-func newNodeTracker(zone string, resyncFn func(nodes []nodeInfo)) *nodeTracker {
+func newNodeTracker(zone string, resyncFn, lbHealthCheckResyncFn func(nodes []nodeInfo)) *nodeTracker {
 	return &nodeTracker{
-		nodes:    map[string]nodeInfo{},
-		zone:     zone,
-		resyncFn: resyncFn,
+		nodes:                 map[string]nodeInfo{},
+		zone:                  zone,
+		resyncFn:              resyncFn,
+		lbHealthCheckResyncFn: lbHealthCheckResyncFn,
+		lbHealthCheckSources:  map[string]sets.Set[string]{},
 	}
 }
 
@@ -165,6 +184,75 @@ func (nt *nodeTracker) Start(nodeInformer coreinformers.NodeInformer) (cache.Res
 
 }
 
+// StartLBHealthCheckSources tracks the load balancer health check source
+// addresses the network controller reserves on the node switches. They may be
+// reserved after the node is added, the health checked services are resynced
+// when they change.
+func (nt *nodeTracker) StartLBHealthCheckSources(nbClient libovsdbclient.Client) error {
+	nbClient.Cache().AddEventHandler(&libovsdbcache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			if sw, ok := m.(*nbdb.LogicalSwitch); ok {
+				nt.updateLBHealthCheckSources(sw.Name, sw.ExternalIDs[types.LBHealthCheckSourceExternalID])
+			}
+		},
+		UpdateFunc: func(table string, _, new model.Model) {
+			if sw, ok := new.(*nbdb.LogicalSwitch); ok {
+				nt.updateLBHealthCheckSources(sw.Name, sw.ExternalIDs[types.LBHealthCheckSourceExternalID])
+			}
+		},
+		DeleteFunc: func(table string, m model.Model) {
+			if sw, ok := m.(*nbdb.LogicalSwitch); ok {
+				nt.updateLBHealthCheckSources(sw.Name, "")
+			}
+		},
+	})
+	// the handler is only called for the changes to come
+	switches, err := libovsdbops.FindLogicalSwitchesWithPredicate(nbClient, func(item *nbdb.LogicalSwitch) bool {
+		return item.ExternalIDs[types.LBHealthCheckSourceExternalID] != ""
+	})
+	if err != nil {
+		return err
+	}
+	for _, sw := range switches {
+		nt.updateLBHealthCheckSources(sw.Name, sw.ExternalIDs[types.LBHealthCheckSourceExternalID])
+	}
+	return nil
+}
+
+// updateLBHealthCheckSources updates the load balancer health check source
+// addresses of the switch, and syncs the health checked services if the ones
+// of a node changed.
+func (nt *nodeTracker) updateLBHealthCheckSources(switchName, sources string) {
+	var reserved sets.Set[string]
+	if sources != "" {
+		reserved = sets.New(strings.Split(sources, ",")...)
+	}
+
+	nt.Lock()
+	defer nt.Unlock()
+	if reserved.Equal(nt.lbHealthCheckSources[switchName]) {
+		return
+	}
+	if reserved == nil {
+		delete(nt.lbHealthCheckSources, switchName)
+	} else {
+		nt.lbHealthCheckSources[switchName] = reserved
+	}
+
+	changed := false
+	for name, ni := range nt.nodes {
+		if ni.switchName == switchName {
+			ni.lbHealthCheckSources = reserved
+			nt.nodes[name] = ni
+			changed = true
+		}
+	}
+	if changed {
+		klog.Infof("Load balancer health check sources of switch %s changed, syncing health checked services", switchName)
+		nt.lbHealthCheckResyncFn(nt.getZoneNodes())
+	}
+}
+
 // updateNodeInfo updates the node info cache, and syncs all services
 // if it changed.
 func (nt *nodeTracker) updateNodeInfo(nodeName, switchName, routerName, chassisID string, l3gatewayAddresses,
@@ -188,6 +276,7 @@ func (nt *nodeTracker) updateNodeInfo(nodeName, switchName, routerName, chassisI
 
 	nt.Lock()
 	defer nt.Unlock()
+	ni.lbHealthCheckSources = nt.lbHealthCheckSources[switchName]
 	if existing, ok := nt.nodes[nodeName]; ok {
 		if reflect.DeepEqual(existing, ni) {
 			return
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -133,6 +134,11 @@ func (r *repair) runBeforeSync(useTemplates bool) {
 	}
 	klog.V(2).Infof("Deleted %d stale Chassis Template Vars", len(staleTemplateNames))
 
+	// Delete service health checks not referenced by any load balancer anymore
+	if err := r.deleteStaleHealthChecks(); err != nil {
+		klog.Errorf("Failed to delete stale service load balancer health checks: %v", err)
+	}
+
 	// Remove existing reject rules. They are not used anymore
 	// given the introduction of idling loadbalancers
 	p := func(item *nbdb.ACL) bool {
@@ -152,6 +158,39 @@ func (r *repair) runBeforeSync(useTemplates bool) {
 	}
 }
 
+// deleteStaleHealthChecks deletes the Load_Balancer_Health_Check rows owned by
+// a Service that are not referenced by any load balancer, e.g. because their
+// load balancer was deleted while ovnkube was not running.
+func (r *repair) deleteStaleHealthChecks() error {
+	lbs, err := libovsdbops.ListLoadBalancers(r.nbClient)
+	if err != nil {
+		return fmt.Errorf("could not list load balancers: %w", err)
+	}
+	referenced := sets.New[string]()
+	for _, lb := range lbs {
+		referenced.Insert(lb.HealthCheck...)
+	}
+
+	var stale int
+	p := func(item *nbdb.LoadBalancerHealthCheck) bool {
+		if item.ExternalIDs[types.LoadBalancerKindExternalID] == "Service" && !referenced.Has(item.UUID) {
+			stale++
+			return true
+		}
+		return false
+	}
+	ops, err := libovsdbops.DeleteLoadBalancerHealthChecksWithPredicateOps(r.nbClient, nil, p)
+	if err != nil {
+		return err
+	}
+	_, err = libovsdbops.TransactAndCheck(r.nbClient, ops)
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Deleted %d stale service load balancer health checks", stale)
+	return nil
+}
+
 // serviceSynced is called by a ServiceController worker when it has successfully
 // applied a service.
 // If all services have successfully synced at least once, kick off
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -82,7 +83,7 @@ func NewController(client clientset.Interface,
 	// load balancers need to be applied to nodes, so
 	// we need to watch Node objects for changes.
 	// Need to re-sync all services when a node gains its switch or GWR
-	c.nodeTracker = newNodeTracker(zone, c.RequestFullSync)
+	c.nodeTracker = newNodeTracker(zone, c.RequestFullSync, c.requestLBHealthCheckSync)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +164,13 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}, runRepair, useLBGr
 	klog.Infof("Starting controller %s", controllerName)
 	defer klog.Infof("Shutting down controller %s", controllerName)
 
+	if globalconfig.Kubernetes.OVNLBHealthChecks {
+		// track the health check sources before the nodes so that they are
+		// known when the nodes are added
+		if err := c.nodeTracker.StartLBHealthCheckSources(c.nbClient); err != nil {
+			return fmt.Errorf("failed to track the load balancer health check sources: %w", err)
+		}
+	}
 	nodeHandler, err := c.nodeTracker.Start(c.nodeInformer)
 	if err != nil {
 		return err
@@ -417,6 +425,7 @@ func (c *Controller) syncService(key string) error {
 		len(clusterLBs), len(perNodeLBs), len(templateLBs))
 	lbs := append(clusterLBs, templateLBs...)
 	lbs = append(lbs, perNodeLBs...)
+	setLBIPPortMappings(lbs, endpointSlices, c.lbHealthCheckNodes(lbs))
 
 	// Short-circuit if nothing has changed
 	c.alreadyAppliedRWLock.RLock()
@@ -449,6 +458,36 @@ func (c *Controller) syncService(key string) error {
 	return nil
 }
 
+// lbHealthCheckNodes returns, if any of the load balancers is health checked,
+// the nodes with the pod subnets whose health check source address is reserved
+// on the node switch. It may not be when a pod already held it.
+func (c *Controller) lbHealthCheckNodes(lbs []LB) []nodeInfo {
+	var healthChecked bool
+	for i := range lbs {
+		if lbs[i].Opts.HealthCheck != nil {
+			healthChecked = true
+			break
+		}
+	}
+	if !healthChecked {
+		return nil
+	}
+	nodes := make([]nodeInfo, 0, len(c.nodeInfos))
+	for _, node := range c.nodeInfos {
+		podSubnets := make([]net.IPNet, 0, len(node.podSubnets))
+		for i := range node.podSubnets {
+			if node.lbHealthCheckSources.Has(util.GetNodeLBHealthCheckIfAddr(&node.podSubnets[i]).IP.String()) {
+				podSubnets = append(podSubnets, node.podSubnets[i])
+			}
+		}
+		if len(podSubnets) > 0 {
+			node.podSubnets = podSubnets
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
 func (c *Controller) syncNodeInfos(nodeInfos []nodeInfo) {
 	c.nodeInfoRWLock.Lock()
 	defer c.nodeInfoRWLock.Unlock()
@@ -524,6 +563,29 @@ func (c *Controller) RequestFullSync(nodeInfos []nodeInfo) {
 	}
 }
 
+// requestLBHealthCheckSync re-syncs the health checked services, when the
+// health check source addresses reserved on the node switches changed
+func (c *Controller) requestLBHealthCheckSync(nodeInfos []nodeInfo) {
+	klog.Info("Health checked service sync requested")
+
+	// the node IP templates do not depend on the health check sources
+	c.nodeInfoRWLock.Lock()
+	c.nodeInfos = nodeInfos
+	c.nodeInfoRWLock.Unlock()
+
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Cached lister failed!? %v", err)
+		return
+	}
+
+	for _, service := range services {
+		if getLBHealthCheck(service) != nil {
+			c.onServiceAdd(service)
+		}
+	}
+}
+
 // handlers
 
 // onServiceAdd queues the Service for processing.
@@ -12,10 +12,13 @@ import (
 	"github.com/onsi/gomega/format"
 	libovsdbclient "github.com/ovn-org/libovsdb/client"
 	globalconfig "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
 	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
@@ -913,3 +916,80 @@ func readyEndpointsWithAddresses(addresses ...string) discovery.Endpoint {
 		Addresses: addresses,
 	}
 }
+
+func Test_lbHealthCheckNodes(t *testing.T) {
+	g := gomega.NewWithT(t)
+	controller, err := newControllerWithDBSetup(libovsdbtest.TestSetup{
+		NBData: []libovsdbtest.TestData{
+			&nbdb.LogicalSwitch{
+				UUID:        "node-a-UUID",
+				Name:        "node-a",
+				ExternalIDs: map[string]string{types.LBHealthCheckSourceExternalID: "10.128.0.4,fd00:10:244::4"},
+			},
+			&nbdb.LogicalSwitch{
+				UUID:        "node-b-UUID",
+				Name:        "node-b",
+				ExternalIDs: map[string]string{types.LBHealthCheckSourceExternalID: "fd00:10:244:1::4"},
+			},
+			&nbdb.LogicalSwitch{
+				UUID: "node-c-UUID",
+				Name: "node-c",
+			},
+		},
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer controller.close()
+	ns := "testns"
+	defer func() { globalconfig.Kubernetes.OVNLBHealthChecks = false }()
+	globalconfig.Kubernetes.OVNLBHealthChecks = true
+
+	healthCheckedSvc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "health-checked",
+			Namespace:   ns,
+			Annotations: map[string]string{util.LBHealthCheckAnnotation: "{}"},
+		},
+	}
+	g.Expect(controller.serviceStore.Add(healthCheckedSvc)).To(gomega.Succeed())
+	g.Expect(controller.serviceStore.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns}})).To(gomega.Succeed())
+
+	// the node tracker caches the sources reserved on the node switches
+	g.Expect(controller.nodeTracker.StartLBHealthCheckSources(controller.nbClient)).To(gomega.Succeed())
+	for i, name := range []string{"a", "b", "c"} {
+		controller.nodeTracker.updateNodeInfo("node-"+name, "node-"+name, "", "", nil, nil,
+			ovntest.MustParseIPNets(fmt.Sprintf("10.128.%d.0/24", i), fmt.Sprintf("fd00:10:244:%d::/64", i)),
+			types.OvnDefaultZone, false)
+	}
+	for controller.queue.Len() > 0 {
+		key, _ := controller.queue.Get()
+		controller.queue.Done(key)
+		controller.queue.Forget(key)
+	}
+	healthCheckNodes := func() []nodeInfo {
+		controller.nodeInfoRWLock.RLock()
+		defer controller.nodeInfoRWLock.RUnlock()
+		return controller.lbHealthCheckNodes([]LB{{Opts: LBOpts{HealthCheck: &util.LBHealthCheckConfig{}}}})
+	}
+
+	g.Expect(controller.lbHealthCheckNodes([]LB{{}})).To(gomega.BeEmpty())
+
+	nodes := healthCheckNodes()
+	g.Expect(nodes).To(gomega.HaveLen(2))
+	g.Expect(nodes[0].name).To(gomega.Equal("node-a"))
+	g.Expect(nodes[0].podSubnets).To(gomega.HaveLen(2))
+	// the IPv4 source address of node-b is held by a pod
+	g.Expect(nodes[1].name).To(gomega.Equal("node-b"))
+	g.Expect(nodes[1].podSubnets).To(gomega.Equal([]net.IPNet{*ovntest.MustParseIPNet("fd00:10:244:1::/64")}))
+
+	// the sources reserved later on requeue the health checked services
+	err = libovsdbops.UpdateLogicalSwitchSetExternalIDs(controller.nbClient, &nbdb.LogicalSwitch{
+		Name:        "node-c",
+		ExternalIDs: map[string]string{types.LBHealthCheckSourceExternalID: "10.128.2.4"},
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Eventually(healthCheckNodes).Should(gomega.HaveLen(3))
+	g.Eventually(controller.queue.Len).Should(gomega.Equal(1))
+	key, _ := controller.queue.Get()
+	g.Expect(key).To(gomega.Equal(ns + "/health-checked"))
+	controller.queue.Done(key)
+}
@@ -122,6 +122,9 @@ type DefaultNetworkController struct {
 	hybridOverlayFailed         sync.Map
 	syncZoneICFailed            sync.Map
 
+	// switch the ARP proxy serving each VM was last reported at, by VM
+	vmARPProxySwitches sync.Map
+
 	// variable to determine if all pods present on the node during startup have been processed
 	// updated atomically
 	allInitialPodsProcessed uint32
@@ -223,7 +226,7 @@ func newDefaultNetworkControllerCommon(cnci *CommonNetworkControllerInfo,
 
 	// Allocate IPs for logical router port "GwRouterToJoinSwitchPrefix + OVNClusterRouter". This should always
 	// allocate the first IPs in the join switch subnets.
-	gwLRPIfAddrs, err := oc.getOVNClusterRouterPortToJoinSwitchIfAddrs()
+	gwLRPIfAddrs, err := getOVNClusterRouterPortToJoinSwitchIfAddrs()
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate join switch IP address connected to %s: %v", ovntypes.OVNClusterRouter, err)
 	}
@@ -737,6 +740,9 @@ func (h *defaultNetworkControllerEventHandler) AddResource(obj interface{}, from
 		if !ok {
 			return fmt.Errorf("could not cast %T object to *kapi.Node", obj)
 		}
+		if err := h.oc.syncGatewayUplinkNodeIPs(); err != nil {
+			return err
+		}
 		if config.HybridOverlay.Enabled {
 			if util.NoHostSubnet(node) {
 				return h.oc.addUpdateHoNodeEvent(node)
@@ -862,6 +868,11 @@ func (h *defaultNetworkControllerEventHandler) UpdateResource(oldObj, newObj int
 		if !ok {
 			return fmt.Errorf("could not cast oldObj of type %T to *kapi.Node", oldObj)
 		}
+		if hostCIDRsChanged(oldNode, newNode) {
+			if err := h.oc.syncGatewayUplinkNodeIPs(); err != nil {
+				return err
+			}
+		}
 		var switchToOvnNode bool
 		if config.HybridOverlay.Enabled {
 			if util.NoHostSubnet(newNode) && !util.NoHostSubnet(oldNode) {
@@ -906,7 +917,8 @@ func (h *defaultNetworkControllerEventHandler) UpdateResource(oldObj, newObj int
 				_, failed := h.oc.nodeClusterRouterPortFailed.Load(newNode.Name)
 				clusterRtrSync := failed || nodeChassisChanged(oldNode, newNode) || nodeSubnetChanged
 				_, failed = h.oc.mgmtPortFailed.Load(newNode.Name)
-				mgmtSync := failed || macAddressChanged(oldNode, newNode) || nodeSubnetChanged
+				mgmtSync := failed || macAddressChanged(oldNode, newNode) || nodeSubnetChanged ||
+					util.NodeGatewayModeChanged(oldNode, newNode)
 				_, failed = h.oc.gatewaysFailed.Load(newNode.Name)
 				gwSync := (failed || gatewayChanged(oldNode, newNode) ||
 					nodeSubnetChanged || hostCIDRsChanged(oldNode, newNode) ||
@@ -1014,7 +1026,10 @@ func (h *defaultNetworkControllerEventHandler) DeleteResource(obj, cachedObj int
 		if !ok {
 			return fmt.Errorf("could not cast obj of type %T to *knet.Node", obj)
 		}
-		return h.oc.deleteNodeEvent(node)
+		if err := h.oc.deleteNodeEvent(node); err != nil {
+			return err
+		}
+		return h.oc.syncGatewayUplinkNodeIPs()
 
 	case factory.EgressFirewallType:
 		egressFirewall := obj.(*egressfirewall.EgressFirewall)
@@ -750,7 +750,7 @@ func addOrUpdatePodSNATOps(nbClient libovsdbclient.Client, nodeName string, extI
 // WARNING: updates same db entries as apbroutecontroller. Make sure to call only when route is not managed by
 // apbroute controller.
 func (oc *DefaultNetworkController) addHybridRoutePolicyForPod(podIP net.IP, node string) error {
-	if config.Gateway.Mode == config.GatewayModeLocal {
+	if oc.nodeGatewayMode(node) == config.GatewayModeLocal {
 		// Add podIP to the node's address_set.
 		asIndex := apbroutecontroller.GetHybridRouteAddrSetDbIDs(node, oc.controllerName)
 		as, err := oc.addressSetFactory.EnsureAddressSet(asIndex)
@@ -821,12 +821,19 @@ func (oc *DefaultNetworkController) addHybridRoutePolicyForPod(podIP net.IP, nod
 	return nil
 }
 
+// nodeGatewayMode returns the gateway mode of the node, which is the
+// configured gateway mode unless the node is switching to the other one
+func (oc *DefaultNetworkController) nodeGatewayMode(nodeName string) config.GatewayMode {
+	node, _ := oc.watchFactory.GetNode(nodeName)
+	return util.GetNodeGatewayMode(node)
+}
+
 // delHybridRoutePolicyForPod handles deleting a logical route policy that
 // forces pod egress traffic to be rerouted to a gateway router for local gateway mode.
 // WARNING: updates same db entries as apbroutecontroller. Make sure to call only when route is not managed by
 // apbroute controller.
 func (oc *DefaultNetworkController) delHybridRoutePolicyForPod(podIP net.IP, node string) error {
-	if config.Gateway.Mode == config.GatewayModeLocal {
+	if oc.nodeGatewayMode(node) == config.GatewayModeLocal {
 		// Delete podIP from the node's address_set.
 		asIndex := apbroutecontroller.GetHybridRouteAddrSetDbIDs(node, oc.controllerName)
 		as, err := oc.addressSetFactory.EnsureAddressSet(asIndex)
@@ -3087,10 +3087,15 @@ var _ = ginkgo.Describe("OVN Egress Gateway Operations", func() {
 // injectNode adds a valid node to the nodeinformer so the get
 // to understand if there are two bridged won't fail
 func injectNode(fakeOvn *FakeOVN) {
+	// the node runs in the configured gateway mode
+	gatewayMode := config.Gateway.Mode
+	if gatewayMode != config.GatewayModeLocal {
+		gatewayMode = config.GatewayModeShared
+	}
 	node := &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "node1",
-			Annotations: map[string]string{"k8s.ovn.org/l3-gateway-config": `{"default":{"mode":"local","mac-address":"7e:57:f8:f0:3c:49", "ip-address":"169.254.33.2/24", "next-hop":"169.254.33.1"}}`,
+			Annotations: map[string]string{"k8s.ovn.org/l3-gateway-config": fmt.Sprintf(`{"default":{"mode":"%s","mac-address":"7e:57:f8:f0:3c:49", "ip-address":"169.254.33.2/24", "next-hop":"169.254.33.1"}}`, gatewayMode),
 				"k8s.ovn.org/node-chassis-id": "79fdcfc4-6fe6-4cd3-8242-c0f85a4668ec",
 				"k8s.ovn.org/node-subnets":    `{"default":"10.128.1.0/24"}`,
 			},
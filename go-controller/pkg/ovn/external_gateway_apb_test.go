@@ -27,6 +27,7 @@ import (
 	"github.com/urfave/cli/v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 )
@@ -2422,6 +2423,101 @@ var _ = ginkgo.Describe("OVN for APB External Route Operations", func() {
 			err := app.Run([]string{app.Name})
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 		})
+		ginkgo.It("adds and removes the hybrid route policies of a node following its gateway mode", func() {
+			app.Action = func(ctx *cli.Context) error {
+				config.Gateway.Mode = config.GatewayModeShared
+
+				node1 := tNode{
+					Name:                 "node1",
+					NodeIP:               "1.2.3.4",
+					NodeLRPMAC:           "0a:58:0a:01:01:01",
+					LrpIP:                "100.64.0.2",
+					LrpIPv6:              "fd98::2",
+					DrLrpIP:              "100.64.0.1",
+					PhysicalBridgeMAC:    "11:22:33:44:55:66",
+					SystemID:             "cb9ec8fa-b409-4ef3-9f42-d9283c47aac6",
+					NodeSubnet:           "10.1.1.0/24",
+					GWRouter:             ovntypes.GWRouterPrefix + "node1",
+					GatewayRouterIPMask:  "172.16.16.2/24",
+					GatewayRouterIP:      "172.16.16.2",
+					GatewayRouterNextHop: "172.16.16.1",
+					PhysicalBridgeName:   "br-eth0",
+					NodeGWIP:             "10.1.1.1/24",
+					NodeMgmtPortIP:       "10.1.1.2",
+					NodeMgmtPortMAC:      "0a:58:0a:01:01:02",
+					DnatSnatIP:           "169.254.0.1",
+				}
+				testNode := node1.k8sNode("2")
+				clusterRouter := &nbdb.LogicalRouter{
+					Name: ovntypes.OVNClusterRouter,
+					UUID: ovntypes.OVNClusterRouter + "-UUID",
+				}
+				gwRouterPort := &nbdb.LogicalRouterPort{
+					UUID:     ovntypes.GWRouterToJoinSwitchPrefix + ovntypes.GWRouterPrefix + "node1" + "-UUID",
+					Name:     ovntypes.GWRouterToJoinSwitchPrefix + ovntypes.GWRouterPrefix + "node1",
+					Networks: []string{"100.64.0.4/32"},
+				}
+				fakeOvn.startWithDBSetup(
+					libovsdbtest.TestSetup{
+						NBData: []libovsdbtest.TestData{clusterRouter, gwRouterPort},
+					},
+					&v1.NodeList{
+						Items: []v1.Node{
+							testNode,
+						},
+					},
+				)
+				fakeOvn.RunAPBExternalPolicyController()
+
+				setNodeGatewayMode := func(mode config.GatewayMode) {
+					nodeAnnotator := kube.NewNodeAnnotator(&kube.Kube{KClient: fakeOvn.fakeClient.KubeClient}, testNode.Name)
+					err := util.SetL3GatewayConfig(nodeAnnotator, node1.gatewayConfig(mode, uint(1024)))
+					gomega.Expect(err).NotTo(gomega.HaveOccurred())
+					gomega.Expect(nodeAnnotator.Run()).To(gomega.Succeed())
+					gomega.Eventually(func() config.GatewayMode {
+						node, err := fakeOvn.watcher.GetNode(testNode.Name)
+						gomega.Expect(err).NotTo(gomega.HaveOccurred())
+						return util.GetNodeGatewayMode(node)
+					}).Should(gomega.Equal(mode))
+				}
+
+				// the pod of the node has an external gateway route
+				podName := ktypes.NamespacedName{Namespace: "namespace1", Name: "myPod"}
+				err := fakeOvn.controller.apbExternalRouteController.ExternalGWRouteInfoCache.CreateOrLoad(podName,
+					func(routeInfo *apbroute.RouteInfo) error {
+						routeInfo.PodExternalRoutes["10.128.1.3"] = map[string]string{"9.0.0.1": ovntypes.GWRouterPrefix + "node1"}
+						return nil
+					})
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+				ginkgo.By("switching the node to local gateway mode")
+				setNodeGatewayMode(config.GatewayModeLocal)
+				gomega.Expect(fakeOvn.controller.apbExternalRouteController.SyncNodeHybridRoutePolicies(testNode.Name)).To(gomega.Succeed())
+				asIndex := apbroute.GetHybridRouteAddrSetDbIDs("node1", DefaultNetworkControllerName)
+				asv4, _ := addressset.GetHashNamesForAS(asIndex)
+				policy := &nbdb.LogicalRouterPolicy{
+					UUID:     "hybrid-policy-UUID",
+					Priority: ovntypes.HybridOverlayReroutePriority,
+					Action:   nbdb.LogicalRouterPolicyActionReroute,
+					Nexthops: []string{"100.64.0.4"},
+					Match:    "inport == \"rtos-node1\" && ip4.src == $" + asv4 + " && ip4.dst != 10.128.0.0/14",
+				}
+				expectedClusterRouter := clusterRouter.DeepCopy()
+				expectedClusterRouter.Policies = []string{policy.UUID}
+				gomega.Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(policy, expectedClusterRouter, gwRouterPort))
+				fakeOvn.asf.ExpectAddressSetWithIPs(asIndex, []string{"10.128.1.3"})
+
+				ginkgo.By("switching the node back to shared gateway mode")
+				setNodeGatewayMode(config.GatewayModeShared)
+				gomega.Expect(fakeOvn.controller.apbExternalRouteController.SyncNodeHybridRoutePolicies(testNode.Name)).To(gomega.Succeed())
+				gomega.Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(clusterRouter, gwRouterPort))
+				fakeOvn.asf.EventuallyExpectNoAddressSet(asIndex)
+				return nil
+			}
+
+			err := app.Run([]string{app.Name})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
 		ginkgo.It("should reconcile a pod and create/delete the hybridRoutePolicy accordingly", func() {
 			app.Action = func(ctx *cli.Context) error {
 				config.Gateway.Mode = config.GatewayModeLocal
@@ -82,6 +82,15 @@ func (oc *DefaultNetworkController) gatewayCleanup(nodeName string) error {
 		return fmt.Errorf("failed to delete external switch %s: %w", exGWexternalSwitch, err)
 	}
 
+	if err := oc.deleteGatewayUplinkSwitches(nodeName); err != nil {
+		return err
+	}
+	// the SNATs referring to the address sets of the uplinks went with the
+	// gateway router
+	if err := oc.deleteGatewayUplinkAddressSets(nodeName, nil); err != nil {
+		return err
+	}
+
 	// This will cleanup the NodeSubnetPolicy in local and shared gateway modes. It will be a no-op for any other mode.
 	oc.delPbrAndNatRules(nodeName, nil)
 	return nil
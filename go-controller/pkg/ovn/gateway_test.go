@@ -10,10 +10,17 @@ import (
 	"github.com/onsi/gomega"
 	"github.com/onsi/gomega/format"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilnet "k8s.io/utils/net"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	addressset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/address_set"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
@@ -406,7 +413,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -483,7 +490,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -511,6 +518,85 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			gomega.Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(expectedDatabaseState))
 		})
 
+		ginkgo.It("updates the default route when the next hop changes and keeps it without next hop", func() {
+			expectedOVNClusterRouter := &nbdb.LogicalRouter{
+				UUID:         types.OVNClusterRouter + "-UUID",
+				Name:         types.OVNClusterRouter,
+				StaticRoutes: []string{},
+			}
+			expectedNodeSwitch := &nbdb.LogicalSwitch{
+				UUID: nodeName + "-UUID",
+				Name: nodeName,
+			}
+			expectedClusterLBGroup := &nbdb.LoadBalancerGroup{
+				UUID: types.ClusterLBGroupName + "-UUID",
+				Name: types.ClusterLBGroupName,
+			}
+			expectedSwitchLBGroup := &nbdb.LoadBalancerGroup{
+				UUID: types.ClusterSwitchLBGroupName + "-UUID",
+				Name: types.ClusterSwitchLBGroupName,
+			}
+			expectedRouterLBGroup := &nbdb.LoadBalancerGroup{
+				UUID: types.ClusterRouterLBGroupName + "-UUID",
+				Name: types.ClusterRouterLBGroupName,
+			}
+			fakeOvn.startWithDBSetup(libovsdbtest.TestSetup{
+				NBData: []libovsdbtest.TestData{
+					&nbdb.LogicalSwitch{
+						UUID: types.OVNJoinSwitch + "-UUID",
+						Name: types.OVNJoinSwitch,
+					},
+					expectedOVNClusterRouter,
+					expectedNodeSwitch,
+					expectedClusterLBGroup,
+					expectedSwitchLBGroup,
+					expectedRouterLBGroup,
+				},
+			})
+
+			clusterIPSubnets := ovntest.MustParseIPNets("10.128.0.0/14")
+			hostSubnets := ovntest.MustParseIPNets("10.130.0.0/23")
+			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
+			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
+			l3GatewayConfig := &util.L3GatewayConfig{
+				Mode:           config.GatewayModeShared,
+				ChassisID:      "SYSTEM-ID",
+				InterfaceID:    "INTERFACE-ID",
+				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
+				IPAddresses:    ovntest.MustParseIPNets("169.254.33.2/24"),
+				NextHops:       ovntest.MustParseIPs("169.254.33.1"),
+				NodePortEnable: true,
+			}
+			sctpSupport := false
+
+			var err error
+			fakeOvn.controller.defaultCOPPUUID, err = EnsureDefaultCOPP(fakeOvn.nbClient)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			expectGateway := func(nextHops []net.IP) {
+				err := fakeOvn.controller.gatewayInit(
+					nodeName, clusterIPSubnets, hostSubnets, l3GatewayConfig, sctpSupport, joinLRPIPs, defLRPIPs, true)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				expectedOVNClusterRouter.StaticRoutes = []string{}
+				expectedL3GatewayConfig := *l3GatewayConfig
+				expectedL3GatewayConfig.NextHops = nextHops
+				expectedDatabaseState := generateGatewayInitExpectedNB([]libovsdb.TestData{}, expectedOVNClusterRouter,
+					expectedNodeSwitch, nodeName, clusterIPSubnets, hostSubnets, &expectedL3GatewayConfig, joinLRPIPs, defLRPIPs,
+					false, "", "1400")
+				gomega.Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(expectedDatabaseState))
+			}
+
+			expectGateway(l3GatewayConfig.NextHops)
+
+			ginkgo.By("changing the next hop")
+			l3GatewayConfig.NextHops = ovntest.MustParseIPs("169.254.33.3")
+			expectGateway(l3GatewayConfig.NextHops)
+
+			ginkgo.By("keeping the default route without next hop")
+			l3GatewayConfig.NextHops = nil
+			expectGateway(ovntest.MustParseIPs("169.254.33.3"))
+		})
+
 		ginkgo.It("updates options:gateway_mtu for GR LRP", func() {
 			expectedOVNClusterRouter := &nbdb.LogicalRouter{
 				UUID:         types.OVNClusterRouter + "-UUID",
@@ -552,7 +638,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -634,7 +720,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -728,7 +814,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("fd98::3/64")
 			defLRPIPs := ovntest.MustParseIPNets("fd98::1/64")
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -797,7 +883,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			defLRPIPs := ovntest.MustParseIPNets("fd98::1/64")
 			nodeName := "test-node"
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -870,7 +956,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16", "fd98::1/64")
 			nodeName := "test-node"
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -939,7 +1025,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			nodeName := "test-node"
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -968,6 +1054,191 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			gomega.Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(expectedDatabaseState))
 		})
 
+		ginkgo.It("connects the gateway uplinks and removes them when no longer configured", func() {
+			expectedOVNClusterRouter := &nbdb.LogicalRouter{
+				UUID: types.OVNClusterRouter + "-UUID",
+				Name: types.OVNClusterRouter,
+			}
+			expectedNodeSwitch := &nbdb.LogicalSwitch{
+				UUID: nodeName + "-UUID",
+				Name: nodeName,
+			}
+			fakeOvn.startWithDBSetup(libovsdbtest.TestSetup{
+				NBData: []libovsdbtest.TestData{
+					&nbdb.LogicalSwitch{
+						UUID: types.OVNJoinSwitch + "-UUID",
+						Name: types.OVNJoinSwitch,
+					},
+					expectedOVNClusterRouter,
+					expectedNodeSwitch,
+				},
+			},
+				&v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+						Annotations: map[string]string{
+							"k8s.ovn.org/node-chassis-id": "SYSTEM-ID",
+							"k8s.ovn.org/l3-gateway-config": `{"default":{"mode":"shared","mac-address":"11:22:33:44:55:66",` +
+								`"ip-address":"169.254.33.2/24","next-hop":"169.254.33.1","uplinks":[{"name":"storage",` +
+								`"interface-id":"breth1_test-node","mac-address":"11:22:33:44:55:77","ip-addresses":["192.168.10.2/24"],` +
+								`"next-hops":["192.168.10.1"],"destinations":["192.168.100.0/24"],"namespaces":["storage-clients"]}]}}`,
+							"k8s.ovn.org/host-cidrs": `["172.18.0.2/16"]`,
+						},
+					},
+				},
+				newPod("storage-clients", "client", nodeName, "10.130.0.5"),
+				newPod("storage-clients", "remote-client", "other-node", "10.130.2.5"),
+				newPod("default", "other", nodeName, "10.130.0.6"),
+			)
+
+			clusterIPSubnets := ovntest.MustParseIPNets("10.128.0.0/14")
+			hostSubnets := ovntest.MustParseIPNets("10.130.0.0/23")
+			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
+			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
+			l3GatewayConfig := &util.L3GatewayConfig{
+				Mode:           config.GatewayModeShared,
+				ChassisID:      "SYSTEM-ID",
+				InterfaceID:    "INTERFACE-ID",
+				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
+				IPAddresses:    ovntest.MustParseIPNets("169.254.33.2/24"),
+				NextHops:       ovntest.MustParseIPs("169.254.33.1"),
+				NodePortEnable: true,
+				Uplinks: []util.L3GatewayUplink{
+					{
+						Name:         "storage",
+						InterfaceID:  "breth1_" + nodeName,
+						MACAddress:   ovntest.MustParseMAC("11:22:33:44:55:77"),
+						IPAddresses:  ovntest.MustParseIPNets("192.168.10.2/24"),
+						NextHops:     ovntest.MustParseIPs("192.168.10.1"),
+						Destinations: ovntest.MustParseIPNets("192.168.100.0/24"),
+						Namespaces:   []string{"storage-clients"},
+					},
+				},
+			}
+
+			var err error
+			fakeOvn.controller.defaultCOPPUUID, err = EnsureDefaultCOPP(fakeOvn.nbClient)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			err = fakeOvn.controller.gatewayInit(
+				nodeName, clusterIPSubnets, hostSubnets, l3GatewayConfig, false, joinLRPIPs, defLRPIPs, true)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gatewayRouter := types.GWRouterPrefix + nodeName
+			uplinkRouterPort := "uplink-storage-" + types.GWRouterToExtSwitchPrefix + gatewayRouter
+			lrp, err := libovsdbops.GetLogicalRouterPort(fakeOvn.nbClient, &nbdb.LogicalRouterPort{Name: uplinkRouterPort})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(lrp.Networks).To(gomega.ConsistOf("192.168.10.2/24"))
+			uplinkSwitch, err := libovsdbops.GetLogicalSwitch(fakeOvn.nbClient, &nbdb.LogicalSwitch{Name: "uplink-storage-" + types.ExternalSwitchPrefix + nodeName})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(uplinkSwitch.Ports).To(gomega.HaveLen(2))
+			localnetPort, err := libovsdbops.GetLogicalSwitchPort(fakeOvn.nbClient, &nbdb.LogicalSwitchPort{Name: "breth1_" + nodeName})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(localnetPort.Options).To(gomega.HaveKeyWithValue("network_name", types.PhysicalNetworkUplinkPrefix+"storage"))
+
+			routes, err := libovsdbops.FindLogicalRouterStaticRoutesWithPredicate(fakeOvn.nbClient, func(item *nbdb.LogicalRouterStaticRoute) bool {
+				return item.ExternalIDs[gatewayUplinkExternalID] == "storage"
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(routes).To(gomega.HaveLen(1))
+			gomega.Expect(routes[0].IPPrefix).To(gomega.Equal("192.168.100.0/24"))
+			gomega.Expect(routes[0].Nexthop).To(gomega.Equal("192.168.10.1"))
+			gomega.Expect(routes[0].OutputPort).To(gomega.Equal(&uplinkRouterPort))
+
+			v4AddressSet, _ := addressset.GetHashNamesForAS(getNamespaceAddrSetDbIDs("storage-clients", DefaultNetworkControllerName))
+			policies, err := libovsdbops.FindLogicalRouterPoliciesWithPredicate(fakeOvn.nbClient, func(item *nbdb.LogicalRouterPolicy) bool {
+				return item.ExternalIDs[gatewayUplinkExternalID] == "storage"
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(policies).To(gomega.HaveLen(1))
+			nodeIPsDbIDs := getEgressIPAddrSetDbIDs(NodeIPAddrSetName, DefaultNetworkControllerName)
+			v4NodeIPsAddressSet, _ := addressset.GetHashNamesForAS(nodeIPsDbIDs)
+			gomega.Expect(policies[0].Match).To(gomega.Equal(fmt.Sprintf("ip4.src == $%s && ip4.dst != {10.128.0.0/14, %s} && ip4.dst != $%s",
+				v4AddressSet, config.Gateway.V4MasqueradeSubnet, v4NodeIPsAddressSet)))
+			gomega.Expect(policies[0].Nexthops).To(gomega.ConsistOf("192.168.10.1"))
+			// the traffic to the nodes is not rerouted, their IPs are kept up
+			// to date without egress IP and egress services
+			gomega.Expect(fakeOvn.controller.syncGatewayUplinkNodeIPs()).To(gomega.Succeed())
+			fakeOvn.asf.ExpectAddressSetWithIPs(nodeIPsDbIDs, []string{"172.18.0.2"})
+
+			// the SNATs of the uplink are more specific than the default SNAT
+			// of the cluster subnet: the local pods of the selected namespaces
+			// and the host subnet restricted to the destinations
+			uplinkNATs := func() map[string]*nbdb.NAT {
+				nats, err := libovsdbops.FindNATsWithPredicate(fakeOvn.nbClient, func(item *nbdb.NAT) bool {
+					return item.ExternalIDs[gatewayUplinkExternalID] == "storage"
+				})
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				natsPerLogicalIP := map[string]*nbdb.NAT{}
+				for _, nat := range nats {
+					gomega.Expect(nat.ExternalIP).To(gomega.Equal("192.168.10.2"))
+					natsPerLogicalIP[nat.LogicalIP] = nat
+				}
+				return natsPerLogicalIP
+			}
+			nats := uplinkNATs()
+			gomega.Expect(nats).To(gomega.HaveLen(2))
+			gomega.Expect(nats).To(gomega.HaveKey("10.130.0.5"))
+			gomega.Expect(nats["10.130.0.5"].AllowedExtIPs).To(gomega.BeNil())
+			gomega.Expect(nats).To(gomega.HaveKey("10.130.0.0/23"))
+			gomega.Expect(nats["10.130.0.0/23"].AllowedExtIPs).NotTo(gomega.BeNil())
+			destinations, err := libovsdbops.FindAddressSetsWithPredicate(fakeOvn.nbClient, func(item *nbdb.AddressSet) bool {
+				return item.UUID == *nats["10.130.0.0/23"].AllowedExtIPs
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(destinations).To(gomega.HaveLen(1))
+			gomega.Expect(destinations[0].Addresses).To(gomega.ConsistOf("192.168.100.0/24"))
+			defaultNAT, err := libovsdbops.GetNAT(fakeOvn.nbClient, libovsdbops.BuildSNAT(nil, clusterIPSubnets[0], "", nil))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(defaultNAT.ExternalIP).To(gomega.Equal("169.254.33.2"))
+
+			// the SNATs follow the pods of the selected namespaces
+			var ops []ovsdb.Operation
+			newClient := newPod("storage-clients", "new-client", nodeName, "10.130.0.7")
+			ops, err = fakeOvn.controller.addGatewayUplinkPodSNATOps(newClient, ovntest.MustParseIPNets("10.130.0.7/23"), ops)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			_, err = libovsdbops.TransactAndCheck(fakeOvn.nbClient, ops)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			ops, err = fakeOvn.controller.addGatewayUplinkPodSNATOps(newPod("default", "new-other", nodeName, "10.130.0.8"),
+				ovntest.MustParseIPNets("10.130.0.8/23"), nil)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(ops).To(gomega.BeEmpty())
+			err = fakeOvn.controller.deleteGatewayUplinkPodSNATs(nodeName, ovntest.MustParseIPNets("10.130.0.5/23"))
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			nats = uplinkNATs()
+			gomega.Expect(nats).To(gomega.HaveLen(2))
+			gomega.Expect(nats).To(gomega.HaveKey("10.130.0.7"))
+			gomega.Expect(nats).To(gomega.HaveKey("10.130.0.0/23"))
+
+			l3GatewayConfig.Uplinks = nil
+			err = fakeOvn.controller.gatewayInit(
+				nodeName, clusterIPSubnets, hostSubnets, l3GatewayConfig, false, joinLRPIPs, defLRPIPs, true)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			_, err = libovsdbops.GetLogicalRouterPort(fakeOvn.nbClient, &nbdb.LogicalRouterPort{Name: uplinkRouterPort})
+			gomega.Expect(err).To(gomega.MatchError(libovsdbclient.ErrNotFound))
+			_, err = libovsdbops.GetLogicalSwitch(fakeOvn.nbClient, &nbdb.LogicalSwitch{Name: "uplink-storage-" + types.ExternalSwitchPrefix + nodeName})
+			gomega.Expect(err).To(gomega.MatchError(libovsdbclient.ErrNotFound))
+			gr, err := libovsdbops.GetLogicalRouter(fakeOvn.nbClient, &nbdb.LogicalRouter{Name: gatewayRouter})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(gr.Policies).To(gomega.BeEmpty())
+			routes, err = libovsdbops.FindLogicalRouterStaticRoutesWithPredicate(fakeOvn.nbClient, func(item *nbdb.LogicalRouterStaticRoute) bool {
+				return item.ExternalIDs[gatewayUplinkExternalID] != ""
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(routes).To(gomega.BeEmpty())
+			staleNATs, err := libovsdbops.FindNATsWithPredicate(fakeOvn.nbClient, func(item *nbdb.NAT) bool {
+				return item.ExternalIDs[gatewayUplinkExternalID] != ""
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(staleNATs).To(gomega.BeEmpty())
+			destinations, err = libovsdbops.FindAddressSetsWithPredicate(fakeOvn.nbClient, func(item *nbdb.AddressSet) bool {
+				return item.ExternalIDs[libovsdbops.OwnerTypeKey.String()] == string(libovsdbops.GatewayUplinkOwnerType)
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(destinations).To(gomega.BeEmpty())
+		})
+
 		ginkgo.It("ensures only a single static route per node for ovn_cluster_router", func() {
 			joinLRPIPs := ovntest.MustParseIPNets("100.64.0.3/16")
 			hostSubnets := ovntest.MustParseIPNets("10.130.0.0/23")
@@ -1041,7 +1312,7 @@ var _ = ginkgo.Describe("Gateway Init Operations", func() {
 			defLRPIPs := ovntest.MustParseIPNets("100.64.0.1/16")
 			nodeName := "test-node"
 			l3GatewayConfig := &util.L3GatewayConfig{
-				Mode:           config.GatewayModeLocal,
+				Mode:           config.GatewayModeShared,
 				ChassisID:      "SYSTEM-ID",
 				InterfaceID:    "INTERFACE-ID",
 				MACAddress:     ovntest.MustParseMAC("11:22:33:44:55:66"),
@@ -0,0 +1,495 @@
+package ovn
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/pkg/errors"
+	kapi "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilnet "k8s.io/utils/net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	addressset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/address_set"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+// gatewayUplinkExternalID is the external ID identifying the uplink the routes,
+// policies and NATs of the gateway router were created for
+const gatewayUplinkExternalID = "gateway-uplink"
+
+// gatewayUplinkPrefix returns the prefix of the names of the external switch
+// and of the gateway router port of the given uplink
+func gatewayUplinkPrefix(uplinkName string) string {
+	return types.GatewayUplinkPrefix + uplinkName + "-"
+}
+
+// syncGatewayUplinks connects the additional uplinks of the node to its gateway
+// router. Each uplink gets its own external switch and router port, routes to
+// its destinations, reroute policies for the namespaces selecting it, except
+// for the traffic to the cluster, masquerade and node IPs, and, when SNAT is
+// enabled, the SNATs of buildGatewayUplinkSNATOps.
+// The objects of the uplinks no longer configured are removed.
+func (oc *DefaultNetworkController) syncGatewayUplinks(nodeName string, clusterIPSubnet, hostSubnets []*net.IPNet, uplinks []util.L3GatewayUplink) error {
+	gatewayRouter := types.GWRouterPrefix + nodeName
+	logicalRouter := nbdb.LogicalRouter{Name: gatewayRouter}
+
+	uplinkNames := sets.New[string]()
+	routes := sets.New[string]()
+	policies := sets.New[string]()
+	nats := sets.New[string]()
+	nodeIPsDbIDs := getEgressIPAddrSetDbIDs(NodeIPAddrSetName, oc.controllerName)
+	v4NodeIPsAddressSet, v6NodeIPsAddressSet := addressset.GetHashNamesForAS(nodeIPsDbIDs)
+	for _, uplink := range uplinks {
+		prefix := gatewayUplinkPrefix(uplink.Name)
+		if err := oc.addExternalSwitch(prefix,
+			uplink.InterfaceID,
+			nodeName,
+			gatewayRouter,
+			uplink.MACAddress.String(),
+			types.PhysicalNetworkUplinkPrefix+uplink.Name,
+			uplink.IPAddresses,
+			nil); err != nil {
+			return err
+		}
+		uplinkNames.Insert(uplink.Name)
+		routerPort := prefix + types.GWRouterToExtSwitchPrefix + gatewayRouter
+		externalIDs := map[string]string{gatewayUplinkExternalID: uplink.Name}
+
+		for _, destination := range uplink.Destinations {
+			nextHop, err := util.MatchFirstIPFamily(utilnet.IsIPv6CIDR(destination), uplink.NextHops)
+			if err != nil {
+				return fmt.Errorf("failed to find a next hop for destination %s of uplink %s: %v", destination, uplink.Name, err)
+			}
+			lrsr := nbdb.LogicalRouterStaticRoute{
+				IPPrefix:    destination.String(),
+				Nexthop:     nextHop.String(),
+				OutputPort:  &routerPort,
+				ExternalIDs: externalIDs,
+			}
+			p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+				return item.IPPrefix == lrsr.IPPrefix && item.ExternalIDs[gatewayUplinkExternalID] == uplink.Name
+			}
+			if err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(oc.nbClient, gatewayRouter,
+				&lrsr, p, &lrsr.Nexthop, &lrsr.OutputPort, &lrsr.ExternalIDs); err != nil {
+				return fmt.Errorf("failed to add static route %+v to gateway router %s: %v", lrsr, gatewayRouter, err)
+			}
+			routes.Insert(uplink.Name + "/" + lrsr.IPPrefix)
+		}
+
+		if len(uplink.Namespaces) > 0 {
+			// the traffic to the nodes is not rerouted, like the egress IP
+			// traffic is not by the default no reroute policies
+			if _, err := oc.addressSetFactory.EnsureAddressSet(nodeIPsDbIDs); err != nil {
+				return fmt.Errorf("cannot ensure that addressSet %s exists: %v", NodeIPAddrSetName, err)
+			}
+		}
+		for _, namespace := range uplink.Namespaces {
+			v4AddressSet, v6AddressSet := addressset.GetHashNamesForAS(getNamespaceAddrSetDbIDs(namespace, oc.controllerName))
+			for _, nextHop := range uplink.NextHops {
+				isIPv6 := utilnet.IsIPv6(nextHop)
+				clusterSubnets := []string{}
+				for _, subnet := range clusterIPSubnet {
+					if utilnet.IsIPv6CIDR(subnet) == isIPv6 {
+						clusterSubnets = append(clusterSubnets, subnet.String())
+					}
+				}
+				if len(clusterSubnets) == 0 {
+					continue
+				}
+				ipPrefix, addressSet, nodeIPsAddressSet, masqueradeSubnet := "ip4", v4AddressSet, v4NodeIPsAddressSet,
+					config.Gateway.V4MasqueradeSubnet
+				if isIPv6 {
+					ipPrefix, addressSet, nodeIPsAddressSet, masqueradeSubnet = "ip6", v6AddressSet, v6NodeIPsAddressSet,
+						config.Gateway.V6MasqueradeSubnet
+				}
+				lrp := nbdb.LogicalRouterPolicy{
+					Priority: types.GatewayUplinkReroutePriority,
+					Match: fmt.Sprintf("%s.src == $%s && %s.dst != {%s, %s} && %s.dst != $%s", ipPrefix, addressSet, ipPrefix,
+						strings.Join(clusterSubnets, ", "), masqueradeSubnet, ipPrefix, nodeIPsAddressSet),
+					Action:      nbdb.LogicalRouterPolicyActionReroute,
+					Nexthops:    []string{nextHop.String()},
+					ExternalIDs: externalIDs,
+				}
+				p := func(item *nbdb.LogicalRouterPolicy) bool {
+					return item.Priority == lrp.Priority && item.Match == lrp.Match
+				}
+				if err := libovsdbops.CreateOrUpdateLogicalRouterPolicyWithPredicate(oc.nbClient, gatewayRouter,
+					&lrp, p, &lrp.Nexthops, &lrp.ExternalIDs, &lrp.Action); err != nil {
+					return fmt.Errorf("failed to add policy %+v to gateway router %s: %v", lrp, gatewayRouter, err)
+				}
+				policies.Insert(uplink.Name + "/" + lrp.Match)
+			}
+		}
+
+		if config.Gateway.DisableSNATMultipleGWs || config.BGP.DisableSNAT {
+			continue
+		}
+		ops, uplinkNATs, err := oc.buildGatewayUplinkSNATOps(nodeName, hostSubnets, &uplink, nil)
+		if err != nil {
+			return err
+		}
+		ops, err = libovsdbops.CreateOrUpdateNATsOps(oc.nbClient, ops, &logicalRouter, uplinkNATs...)
+		if err != nil {
+			return fmt.Errorf("failed to update SNAT rules of uplink %s on router %s: %v", uplink.Name, gatewayRouter, err)
+		}
+		if _, err = libovsdbops.TransactAndCheck(oc.nbClient, ops); err != nil {
+			return fmt.Errorf("failed to update SNAT rules of uplink %s on router %s: %v", uplink.Name, gatewayRouter, err)
+		}
+		for _, nat := range uplinkNATs {
+			nats.Insert(uplink.Name + "/" + nat.ExternalIP + "/" + nat.LogicalIP)
+		}
+	}
+
+	return oc.cleanupStaleGatewayUplinks(nodeName, uplinkNames, routes, policies, nats)
+}
+
+// syncGatewayUplinkNodeIPs sets the IPs of the nodes, which the reroute policies
+// of the gateway uplinks do not reroute, in the node IPs address set. Egress IP
+// and egress services maintain it instead when enabled, as their default no
+// reroute policies use it too.
+func (oc *DefaultNetworkController) syncGatewayUplinkNodeIPs() error {
+	if config.OVNKubernetesFeature.EnableEgressIP || config.OVNKubernetesFeature.EnableEgressService {
+		return nil
+	}
+	nodes, err := oc.watchFactory.GetNodes()
+	if err != nil {
+		return err
+	}
+	if !hasNamespaceGatewayUplinks(nodes) {
+		return nil
+	}
+	v4NodeAddrs, v6NodeAddrs, err := util.GetNodeAddresses(config.IPv4Mode, config.IPv6Mode, nodes...)
+	if err != nil {
+		return err
+	}
+	as, err := oc.addressSetFactory.EnsureAddressSet(getEgressIPAddrSetDbIDs(NodeIPAddrSetName, oc.controllerName))
+	if err != nil {
+		return fmt.Errorf("cannot ensure that addressSet %s exists: %v", NodeIPAddrSetName, err)
+	}
+	if err = as.SetIPs(append(v4NodeAddrs, v6NodeAddrs...)); err != nil {
+		return fmt.Errorf("unable to set IPs to address set %s: %w", NodeIPAddrSetName, err)
+	}
+	return nil
+}
+
+// hasNamespaceGatewayUplinks tells whether any of the nodes has an uplink
+// selected by namespaces, and thus reroute policies
+func hasNamespaceGatewayUplinks(nodes []*kapi.Node) bool {
+	for _, node := range nodes {
+		l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+		if err != nil {
+			continue
+		}
+		for _, uplink := range l3GatewayConfig.Uplinks {
+			if len(uplink.Namespaces) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildGatewayUplinkSNATOps returns the SNATs masquerading the traffic leaving
+// through the uplink to its IPs and the operations creating the address sets
+// they refer to. OVN ignores the gateway port of the NATs of gateway routers, so
+// the SNATs are more specific than the default SNATs of the cluster subnets to
+// the node IPs, and take precedence over them:
+//   - the SNATs of the IPs of the local pods of the namespaces selecting the
+//     uplink, as their traffic is rerouted to it
+//   - the SNATs of the host subnets of the node, restricted to the destinations
+//     of the uplink, as the traffic to them is routed through it
+//
+// The SNAT of the IP of a pod is the most specific, like its reroute policy
+// takes precedence over the routes of the destinations of the other uplinks.
+func (oc *DefaultNetworkController) buildGatewayUplinkSNATOps(nodeName string, hostSubnets []*net.IPNet,
+	uplink *util.L3GatewayUplink, ops []ovsdb.Operation) ([]ovsdb.Operation, []*nbdb.NAT, error) {
+	podIPs, err := oc.getGatewayUplinkPodIPs(nodeName, uplink)
+	if err != nil {
+		return nil, nil, err
+	}
+	nats := buildGatewayUplinkPodSNATs(uplink, podIPs)
+
+	externalIDs := map[string]string{gatewayUplinkExternalID: uplink.Name}
+	for _, isIPv6 := range []bool{false, true} {
+		uplinkIP, err := util.MatchFirstIPNetFamily(isIPv6, uplink.IPAddresses)
+		if err != nil {
+			// the uplink may be single stack on a dual stack cluster
+			continue
+		}
+		destinations := []string{}
+		for _, destination := range uplink.Destinations {
+			if utilnet.IsIPv6CIDR(destination) == isIPv6 {
+				destinations = append(destinations, destination.String())
+			}
+		}
+		if len(destinations) == 0 {
+			continue
+		}
+		addrSet := addressset.BuildAddressSetWithAddresses(getGatewayUplinkAddrSetDbIDs(nodeName, uplink.Name, oc.controllerName),
+			isIPv6, destinations)
+		ops, err = libovsdbops.CreateOrUpdateAddressSetsOps(oc.nbClient, ops, addrSet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update the destinations address set of uplink %s: %v", uplink.Name, err)
+		}
+		for _, hostSubnet := range hostSubnets {
+			if utilnet.IsIPv6CIDR(hostSubnet) != isIPv6 {
+				continue
+			}
+			nat := libovsdbops.BuildSNAT(&uplinkIP.IP, hostSubnet, "", externalIDs)
+			nat.AllowedExtIPs = &addrSet.UUID
+			nats = append(nats, nat)
+		}
+	}
+	return ops, nats, nil
+}
+
+// getGatewayUplinkPodIPs returns the IPs of the local pods of the node in the
+// namespaces selecting the uplink
+func (oc *DefaultNetworkController) getGatewayUplinkPodIPs(nodeName string, uplink *util.L3GatewayUplink) ([]*net.IPNet, error) {
+	podIPs := []*net.IPNet{}
+	for _, namespace := range uplink.Namespaces {
+		pods, err := oc.watchFactory.GetPods(namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the pods of namespace %s: %v", namespace, err)
+		}
+		for _, pod := range pods {
+			if pod.Spec.NodeName != nodeName || util.PodWantsHostNetwork(pod) || util.PodCompleted(pod) {
+				continue
+			}
+			ips, err := util.GetPodCIDRsWithFullMask(pod, oc.NetInfo)
+			if err != nil {
+				if errors.Is(err, util.ErrNoPodIPFound) {
+					// the SNATs are added along with the logical port of the pod
+					continue
+				}
+				return nil, fmt.Errorf("failed to get the IPs of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			podIPs = append(podIPs, ips...)
+		}
+	}
+	return podIPs, nil
+}
+
+// buildGatewayUplinkPodSNATs returns the SNATs of the given pod IPs to the IPs
+// of the uplink of the same family
+func buildGatewayUplinkPodSNATs(uplink *util.L3GatewayUplink, podIPs []*net.IPNet) []*nbdb.NAT {
+	nats := make([]*nbdb.NAT, 0, len(podIPs))
+	for _, podIP := range podIPs {
+		uplinkIP, err := util.MatchFirstIPNetFamily(utilnet.IsIPv6(podIP.IP), uplink.IPAddresses)
+		if err != nil {
+			continue
+		}
+		fullMaskPodNet := &net.IPNet{
+			IP:   podIP.IP,
+			Mask: util.GetIPFullMask(podIP.IP),
+		}
+		nats = append(nats, libovsdbops.BuildSNAT(&uplinkIP.IP, fullMaskPodNet, "",
+			map[string]string{gatewayUplinkExternalID: uplink.Name}))
+	}
+	return nats
+}
+
+// addGatewayUplinkPodSNATOps returns the operations adding the SNATs of the
+// IPs of the pod to the IPs of the uplink of its node selecting its namespace
+func (oc *DefaultNetworkController) addGatewayUplinkPodSNATOps(pod *kapi.Pod, podIPs []*net.IPNet, ops []ovsdb.Operation) ([]ovsdb.Operation, error) {
+	if config.Gateway.DisableSNATMultipleGWs || config.BGP.DisableSNAT {
+		return ops, nil
+	}
+	// the SNATs of the pods of a node not set up yet are added along with its
+	// gateway router
+	node, err := oc.watchFactory.GetNode(pod.Spec.NodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ops, nil
+		}
+		return nil, fmt.Errorf("failed to get node %s: %v", pod.Spec.NodeName, err)
+	}
+	l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+	if err != nil {
+		if util.IsAnnotationNotSetError(err) {
+			return ops, nil
+		}
+		return nil, fmt.Errorf("failed to parse the L3 gateway annotation of node %s: %v", node.Name, err)
+	}
+	for i := range l3GatewayConfig.Uplinks {
+		uplink := &l3GatewayConfig.Uplinks[i]
+		// a namespace selects at most one uplink
+		if !sets.New(uplink.Namespaces...).Has(pod.Namespace) {
+			continue
+		}
+		logicalRouter := nbdb.LogicalRouter{Name: types.GWRouterPrefix + node.Name}
+		ops, err = libovsdbops.CreateOrUpdateNATsOps(oc.nbClient, ops, &logicalRouter, buildGatewayUplinkPodSNATs(uplink, podIPs)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update SNAT rules of uplink %s on router %s: %v", uplink.Name, logicalRouter.Name, err)
+		}
+		break
+	}
+	return ops, nil
+}
+
+// deleteGatewayUplinkPodSNATs removes the SNATs of the IPs of the pod to the IPs
+// of the uplinks of its node
+func (oc *DefaultNetworkController) deleteGatewayUplinkPodSNATs(nodeName string, podIPs []*net.IPNet) error {
+	logicalIPs := sets.New[string]()
+	for _, podIP := range podIPs {
+		logicalIPs.Insert(podIP.IP.String())
+	}
+	logicalRouter := nbdb.LogicalRouter{Name: types.GWRouterPrefix + nodeName}
+	routerNATs, err := libovsdbops.GetRouterNATs(oc.nbClient, &logicalRouter)
+	if err != nil {
+		if errors.Is(err, libovsdbclient.ErrNotFound) {
+			// the gateway router of a remote node or a node being deleted
+			return nil
+		}
+		return fmt.Errorf("failed to get NATs of gateway router %s: %v", logicalRouter.Name, err)
+	}
+	podNATs := []*nbdb.NAT{}
+	for _, nat := range routerNATs {
+		if _, ok := nat.ExternalIDs[gatewayUplinkExternalID]; ok && logicalIPs.Has(nat.LogicalIP) {
+			podNATs = append(podNATs, nat)
+		}
+	}
+	if len(podNATs) == 0 {
+		return nil
+	}
+	if err := libovsdbops.DeleteNATs(oc.nbClient, &logicalRouter, podNATs...); err != nil {
+		return fmt.Errorf("failed to delete uplink SNAT rules of gateway router %s: %v", logicalRouter.Name, err)
+	}
+	return nil
+}
+
+// getGatewayUplinkAddrSetDbIDs returns the IDs of the address set of the
+// destinations of the uplink of the node
+func getGatewayUplinkAddrSetDbIDs(nodeName, uplinkName, controller string) *libovsdbops.DbObjectIDs {
+	return libovsdbops.NewDbObjectIDs(libovsdbops.AddressSetGatewayUplink, controller, map[libovsdbops.ExternalIDKey]string{
+		libovsdbops.ObjectNameKey:    nodeName,
+		libovsdbops.GatewayUplinkKey: uplinkName,
+	})
+}
+
+// cleanupStaleGatewayUplinks removes the external switches, router ports,
+// routes, policies and NATs of the gateway router of the node belonging to
+// uplinks or selectors that are no longer configured
+func (oc *DefaultNetworkController) cleanupStaleGatewayUplinks(nodeName string, uplinkNames, routes, policies, nats sets.Set[string]) error {
+	gatewayRouter := types.GWRouterPrefix + nodeName
+	logicalRouter := nbdb.LogicalRouter{Name: gatewayRouter}
+
+	err := libovsdbops.DeleteLogicalRouterStaticRoutesWithPredicate(oc.nbClient, gatewayRouter,
+		func(item *nbdb.LogicalRouterStaticRoute) bool {
+			uplink, ok := item.ExternalIDs[gatewayUplinkExternalID]
+			return ok && !routes.Has(uplink+"/"+item.IPPrefix)
+		})
+	if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+		return fmt.Errorf("failed to delete stale uplink routes of gateway router %s: %v", gatewayRouter, err)
+	}
+
+	err = libovsdbops.DeleteLogicalRouterPoliciesWithPredicate(oc.nbClient, gatewayRouter,
+		func(item *nbdb.LogicalRouterPolicy) bool {
+			uplink, ok := item.ExternalIDs[gatewayUplinkExternalID]
+			return ok && !policies.Has(uplink+"/"+item.Match)
+		})
+	if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+		return fmt.Errorf("failed to delete stale uplink policies of gateway router %s: %v", gatewayRouter, err)
+	}
+
+	routerNATs, err := libovsdbops.GetRouterNATs(oc.nbClient, &logicalRouter)
+	if err != nil {
+		return fmt.Errorf("failed to get NATs of gateway router %s: %v", gatewayRouter, err)
+	}
+	staleNATs := []*nbdb.NAT{}
+	addressSets := sets.New[string]()
+	for _, nat := range routerNATs {
+		uplink, ok := nat.ExternalIDs[gatewayUplinkExternalID]
+		if ok && !nats.Has(uplink+"/"+nat.ExternalIP+"/"+nat.LogicalIP) {
+			staleNATs = append(staleNATs, nat)
+		} else if ok && nat.AllowedExtIPs != nil {
+			addressSets.Insert(*nat.AllowedExtIPs)
+		}
+	}
+	if len(staleNATs) > 0 {
+		if err := libovsdbops.DeleteNATs(oc.nbClient, &logicalRouter, staleNATs...); err != nil {
+			return fmt.Errorf("failed to delete stale uplink NATs of gateway router %s: %v", gatewayRouter, err)
+		}
+	}
+	// the address sets of the destinations are removed once no SNAT refers to them
+	if err := oc.deleteGatewayUplinkAddressSets(nodeName, addressSets); err != nil {
+		return err
+	}
+
+	// the router ports of the uplinks are the only ones of the gateway router
+	// prefixed with the uplink prefix
+	staleRouterPorts := []*nbdb.LogicalRouterPort{}
+	staleUplinks := []string{}
+	router, err := libovsdbops.GetLogicalRouter(oc.nbClient, &logicalRouter)
+	if err != nil {
+		return fmt.Errorf("failed to get gateway router %s: %v", gatewayRouter, err)
+	}
+	routerPorts := sets.New(router.Ports...)
+	ports, err := libovsdbops.FindLogicalRouterPortWithPredicate(oc.nbClient, func(item *nbdb.LogicalRouterPort) bool {
+		return routerPorts.Has(item.UUID) && strings.HasPrefix(item.Name, types.GatewayUplinkPrefix)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find uplink ports of gateway router %s: %v", gatewayRouter, err)
+	}
+	for _, port := range ports {
+		uplink := strings.TrimSuffix(strings.TrimPrefix(port.Name, types.GatewayUplinkPrefix),
+			"-"+types.GWRouterToExtSwitchPrefix+gatewayRouter)
+		if !uplinkNames.Has(uplink) {
+			staleRouterPorts = append(staleRouterPorts, port)
+			staleUplinks = append(staleUplinks, uplink)
+		}
+	}
+	if len(staleRouterPorts) > 0 {
+		if err := libovsdbops.DeleteLogicalRouterPorts(oc.nbClient, &logicalRouter, staleRouterPorts...); err != nil {
+			return fmt.Errorf("failed to delete stale uplink ports of gateway router %s: %v", gatewayRouter, err)
+		}
+	}
+	for _, uplink := range staleUplinks {
+		externalSwitch := externalSwitchName(gatewayUplinkPrefix(uplink), nodeName)
+		err = libovsdbops.DeleteLogicalSwitch(oc.nbClient, externalSwitch)
+		if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+			return fmt.Errorf("failed to delete external switch %s: %v", externalSwitch, err)
+		}
+	}
+	return nil
+}
+
+// deleteGatewayUplinkAddressSets removes the address sets of the destinations
+// of the uplinks of the node, except the given ones
+func (oc *DefaultNetworkController) deleteGatewayUplinkAddressSets(nodeName string, keep sets.Set[string]) error {
+	predicateIDs := libovsdbops.NewDbObjectIDs(libovsdbops.AddressSetGatewayUplink, oc.controllerName,
+		map[libovsdbops.ExternalIDKey]string{
+			libovsdbops.ObjectNameKey: nodeName,
+		})
+	p := libovsdbops.GetPredicate[*nbdb.AddressSet](predicateIDs, func(item *nbdb.AddressSet) bool {
+		return !keep.Has(item.UUID)
+	})
+	if err := libovsdbops.DeleteAddressSetsWithPredicate(oc.nbClient, p); err != nil {
+		return fmt.Errorf("failed to delete stale uplink address sets of node %s: %v", nodeName, err)
+	}
+	return nil
+}
+
+// deleteGatewayUplinkSwitches removes the external switches of the uplinks of
+// the node
+func (oc *DefaultNetworkController) deleteGatewayUplinkSwitches(nodeName string) error {
+	suffix := "-" + types.ExternalSwitchPrefix + nodeName
+	ops, err := libovsdbops.DeleteLogicalSwitchesWithPredicateOps(oc.nbClient, nil, func(item *nbdb.LogicalSwitch) bool {
+		return strings.HasPrefix(item.Name, types.GatewayUplinkPrefix) && strings.HasSuffix(item.Name, suffix)
+	})
+	if err == nil {
+		_, err = libovsdbops.TransactAndCheck(oc.nbClient, ops)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete uplink external switches of node %s: %v", nodeName, err)
+	}
+	return nil
+}
@@ -14,10 +14,13 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kubevirt"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
@@ -754,6 +757,28 @@ var _ = Describe("OVN Kubevirt Operations", func() {
 					)
 				}
 				Eventually(fakeOvn.nbClient).Should(libovsdbtest.HaveData(expectedOVN), "should populate ovn")
+
+				expectedEventReasons := []string{}
+				// migration target pods are created already annotated, so
+				// their ports are not reported as newly created
+				if t.migrationTarget.nodeName == "" && (len(t.expectedDhcpv4) > 0 || len(t.expectedDhcpv6) > 0) {
+					expectedEventReasons = append(expectedEventReasons, kubevirt.DHCPOptionsConfiguredReason, kubevirt.ARPProxyConfiguredReason)
+				}
+				if len(t.staticRoutes) == 0 && len(t.expectedStaticRoutes) > 0 {
+					expectedEventReasons = append(expectedEventReasons, kubevirt.MigrationRoutesConfiguredReason)
+				}
+				eventReasons := []string{}
+				Eventually(func() []string {
+					for {
+						select {
+						case event := <-fakeOvn.fakeRecorder.Events:
+							eventReasons = append(eventReasons, strings.Fields(event)[1])
+						default:
+							return eventReasons
+						}
+					}
+				}).Should(ContainElements(expectedEventReasons), "should post the VM events")
+
 				if t.replaceNode != "" {
 					By("Replace vm node with newNode at the logical switch manager")
 					newNode := &corev1.Node{
@@ -1376,4 +1401,106 @@ var _ = Describe("OVN Kubevirt Operations", func() {
 			}),
 		)
 	})
+
+	Context("with multi chassis live migration", func() {
+		It("binds the port of the VM to the source and target nodes until the migration completes", func() {
+			app.Action = func(ctx *cli.Context) error {
+				_, parsedClusterCIDRIPv4, err := net.ParseCIDR(clusterCIDRIPv4)
+				Expect(err).NotTo(HaveOccurred())
+				config.Default.ClusterSubnets = []config.CIDRNetworkEntry{{CIDR: parsedClusterCIDRIPv4, HostSubnetLength: 24}}
+				config.IPv4Mode = true
+				config.OVNKubernetesFeature.EnableMultiChassisLiveMigration = true
+
+				newVirtLauncherPod := func(name, nodeName string, age time.Duration) *corev1.Pod {
+					pod := newPod("namespace1", name, nodeName, "")
+					pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+					pod.Labels = map[string]string{kubevirtv1.VirtualMachineNameLabel: vm1}
+					pod.Annotations = map[string]string{kubevirtv1.AllowPodBridgeNetworkLiveMigrationAnnotation: ""}
+					return pod
+				}
+				sourcePod := newVirtLauncherPod("virt-launcher-1", node1, time.Hour)
+				targetPod := newVirtLauncherPod("virt-launcher-2", node2, 0)
+				sourcePortName := util.GetLogicalPortName(sourcePod.Namespace, sourcePod.Name)
+
+				fakeOvn.startWithDBSetup(libovsdb.TestSetup{
+					NBData: []libovsdb.TestData{
+						&nbdb.LogicalSwitch{Name: node1, UUID: node1 + "-UUID"},
+						&nbdb.LogicalSwitch{Name: node2, UUID: node2 + "-UUID"},
+						&nbdb.LogicalRouter{Name: ovntypes.OVNClusterRouter, UUID: ovntypes.OVNClusterRouter + "-UUID"},
+					},
+				},
+					&v1.NamespaceList{Items: []v1.Namespace{*newNamespace("namespace1")}},
+					&v1.PodList{Items: []v1.Pod{*sourcePod}},
+					&corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "kube-dns"},
+						Spec:       corev1.ServiceSpec{ClusterIPs: []string{dnsServiceIPv4}},
+					},
+					&v1.NodeList{Items: []v1.Node{
+						{ObjectMeta: metav1.ObjectMeta{Name: node1}},
+						{ObjectMeta: metav1.ObjectMeta{Name: node2}},
+					}},
+				)
+				for _, node := range []string{node1, node2} {
+					Expect(fakeOvn.controller.lsManager.AddOrUpdateSwitch(node,
+						[]*net.IPNet{ovntest.MustParseIPNet(nodeByName[node].subnetIPv4)})).To(Succeed())
+				}
+				Expect(fakeOvn.controller.WatchNamespaces()).To(Succeed())
+				Expect(fakeOvn.controller.WatchPods()).To(Succeed())
+
+				sourcePortChassis := func() map[string]string {
+					lsp, err := libovsdbops.GetLogicalSwitchPort(fakeOvn.nbClient, &nbdb.LogicalSwitchPort{Name: sourcePortName})
+					if err != nil {
+						return nil
+					}
+					return map[string]string{
+						kubevirt.RequestedChassisLSPOption:   lsp.Options[kubevirt.RequestedChassisLSPOption],
+						kubevirt.ActivationStrategyLSPOption: lsp.Options[kubevirt.ActivationStrategyLSPOption],
+					}
+				}
+				Eventually(sourcePortChassis).Should(Equal(map[string]string{
+					kubevirt.RequestedChassisLSPOption:   node1,
+					kubevirt.ActivationStrategyLSPOption: "",
+				}))
+
+				By("binding the target pod to the port of the source pod")
+				_, err = fakeOvn.fakeClient.KubeClient.CoreV1().Pods(targetPod.Namespace).Create(context.TODO(), targetPod, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(func() (string, error) {
+					pod, err := fakeOvn.fakeClient.KubeClient.CoreV1().Pods(targetPod.Namespace).Get(context.TODO(), targetPod.Name, metav1.GetOptions{})
+					if err != nil {
+						return "", err
+					}
+					podAnnotation, err := util.UnmarshalPodAnnotation(pod.Annotations, ovntypes.DefaultNetworkName)
+					if err != nil {
+						return "", err
+					}
+					return podAnnotation.LogicalSwitchPort, nil
+				}).Should(Equal(sourcePortName))
+				Eventually(sourcePortChassis).Should(Equal(map[string]string{
+					kubevirt.RequestedChassisLSPOption:   node1 + "," + node2,
+					kubevirt.ActivationStrategyLSPOption: kubevirt.RARPActivationStrategy,
+				}))
+				_, err = libovsdbops.GetLogicalSwitchPort(fakeOvn.nbClient,
+					&nbdb.LogicalSwitchPort{Name: util.GetLogicalPortName(targetPod.Namespace, targetPod.Name)})
+				Expect(err).To(MatchError(libovsdbclient.ErrNotFound))
+
+				By("keeping the port bound to the target node once the source pod completes")
+				sourcePod.Status.Phase = corev1.PodSucceeded
+				_, err = fakeOvn.fakeClient.KubeClient.CoreV1().Pods(sourcePod.Namespace).UpdateStatus(context.TODO(), sourcePod, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sourcePortChassis).Should(Equal(map[string]string{
+					kubevirt.RequestedChassisLSPOption:   node2,
+					kubevirt.ActivationStrategyLSPOption: "",
+				}))
+				Consistently(sourcePortChassis).ShouldNot(BeNil())
+
+				By("deleting the port once the target pod is deleted")
+				err = fakeOvn.fakeClient.KubeClient.CoreV1().Pods(targetPod.Namespace).Delete(context.TODO(), targetPod.Name, metav1.DeleteOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sourcePortChassis).Should(BeNil())
+				return nil
+			}
+			Expect(app.Run([]string{app.Name})).To(Succeed())
+		})
+	})
 })
@@ -34,6 +34,13 @@ func NewL2SwitchManager() *LogicalSwitchManager {
 // AddOrUpdateSwitch adds/updates a switch to the logical switch manager for subnet
 // and IPAM management.
 func (manager *LogicalSwitchManager) AddOrUpdateSwitch(switchName string, hostSubnets []*net.IPNet, excludeSubnets ...*net.IPNet) error {
+	return manager.AddOrUpdateSwitchWithIPPools(switchName, hostSubnets, nil, excludeSubnets...)
+}
+
+// AddOrUpdateSwitchWithIPPools adds/updates a switch to the logical switch
+// manager for subnet and IPAM management, along with the IP pools of its
+// subnets.
+func (manager *LogicalSwitchManager) AddOrUpdateSwitchWithIPPools(switchName string, hostSubnets []*net.IPNet, ipPools []util.IPPool, excludeSubnets ...*net.IPNet) error {
 	if manager.reserveIPs {
 		for _, hostSubnet := range hostSubnets {
 			for _, ip := range []*net.IPNet{util.GetNodeGatewayIfAddr(hostSubnet), util.GetNodeManagementIfAddr(hostSubnet)} {
@@ -43,7 +50,7 @@ func (manager *LogicalSwitchManager) AddOrUpdateSwitch(switchName string, hostSu
 			}
 		}
 	}
-	return manager.allocator.AddOrUpdateSubnet(switchName, hostSubnets, excludeSubnets...)
+	return manager.allocator.AddOrUpdateSubnetWithIPPools(switchName, hostSubnets, ipPools, excludeSubnets...)
 }
 
 // AddNoHostSubnetSwitch adds/updates a switch without any host subnets
@@ -241,6 +241,10 @@ func (oc *DefaultNetworkController) syncNodeManagementPort(node *kapi.Node, host
 		}
 	}
 
+	// the node may not have a gateway yet, fall back to the configured mode
+	l3GatewayConfig, _ := util.ParseNodeL3GatewayAnnotation(node)
+	gatewayMode := l3GatewayConfig.GatewayMode()
+
 	var v4Subnet *net.IPNet
 	addresses := macAddress.String()
 	for _, hostSubnet := range hostSubnets {
@@ -254,7 +258,7 @@ func (oc *DefaultNetworkController) syncNodeManagementPort(node *kapi.Node, host
 		if !utilnet.IsIPv6CIDR(hostSubnet) {
 			v4Subnet = hostSubnet
 		}
-		if config.Gateway.Mode == config.GatewayModeLocal {
+		if gatewayMode == config.GatewayModeLocal {
 			lrsr := nbdb.LogicalRouterStaticRoute{
 				Policy:   &nbdb.LogicalRouterStaticRoutePolicySrcIP,
 				IPPrefix: hostSubnet.String(),
@@ -550,6 +554,14 @@ func (oc *DefaultNetworkController) syncNodes(kNodes []interface{}) error {
 		}
 	}
 
+	if config.Kubernetes.OVNLBHealthChecks {
+		// the node switches about to be synced look for the pods holding their
+		// load balancer health check source addresses
+		if err := oc.syncLBHealthCheckPodIndex(); err != nil {
+			return fmt.Errorf("failed to index the pods holding load balancer health check source addresses: %w", err)
+		}
+	}
+
 	defaultNetworkPredicate := func(item *nbdb.LogicalSwitch) bool {
 		_, ok := item.ExternalIDs[types.NetworkExternalID]
 		return len(item.OtherConfig) > 0 && !ok
@@ -774,6 +786,11 @@ func (oc *DefaultNetworkController) addUpdateLocalNodeEvent(node *kapi.Node, nSy
 
 	if nSyncs.syncMgmtPort {
 		err := oc.syncNodeManagementPort(node, hostSubnets)
+		if err == nil {
+			// the hybrid route policies of the pods with external gateways
+			// follow the gateway mode of the node too
+			err = oc.apbExternalRouteController.SyncNodeHybridRoutePolicies(node.Name)
+		}
 		if err != nil {
 			errs = append(errs, err)
 			oc.mgmtPortFailed.Store(node.Name, true)
@@ -941,7 +958,7 @@ func (oc *DefaultNetworkController) deleteOVNNodeEvent(node *kapi.Node) error {
 // logical router port "GwRouterToJoinSwitchPrefix + OVNClusterRouter" from the
 // config.Gateway.V4JoinSubnet and  config.Gateway.V6JoinSubnet. This will
 // always be the first IP from these subnets.
-func (oc *DefaultNetworkController) getOVNClusterRouterPortToJoinSwitchIfAddrs() (gwLRPIPs []*net.IPNet, err error) {
+func getOVNClusterRouterPortToJoinSwitchIfAddrs() (gwLRPIPs []*net.IPNet, err error) {
 	joinSubnetsConfig := []string{}
 	if config.IPv4Mode {
 		joinSubnetsConfig = append(joinSubnetsConfig, config.Gateway.V4JoinSubnet)
@@ -1028,7 +1028,7 @@ var _ = ginkgo.Describe("Default network controller operations", func() {
 
 		gomega.Expect(err).NotTo(gomega.HaveOccurred())
 		nodeAnnotator = kube.NewNodeAnnotator(&kube.Kube{kubeFakeClient}, testNode.Name)
-		l3GatewayConfig = node1.gatewayConfig(config.GatewayModeLocal, uint(vlanID))
+		l3GatewayConfig = node1.gatewayConfig(config.GatewayModeShared, uint(vlanID))
 		err = util.SetL3GatewayConfig(nodeAnnotator, l3GatewayConfig)
 		gomega.Expect(err).NotTo(gomega.HaveOccurred())
 		err = util.SetNodeManagementPortMACAddress(nodeAnnotator, ovntest.MustParseMAC(node1.NodeMgmtPortMAC))
@@ -1092,6 +1092,23 @@ var _ = ginkgo.Describe("Default network controller operations", func() {
 		app.Action = func(ctx *cli.Context) error {
 			_, err := config.InitConfig(ctx, nil, nil)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			l3GatewayConfig = node1.gatewayConfig(config.GatewayModeLocal, uint(vlanID))
+			nodeAnnotator = kube.NewNodeAnnotator(&kube.Kube{kubeFakeClient}, testNode.Name)
+			err = util.SetL3GatewayConfig(nodeAnnotator, l3GatewayConfig)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			err = nodeAnnotator.Run()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Eventually(func() config.GatewayMode {
+				node, err := f.GetNode(testNode.Name)
+				if err != nil {
+					return ""
+				}
+				l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+				if err != nil {
+					return ""
+				}
+				return l3GatewayConfig.Mode
+			}).Should(gomega.Equal(config.GatewayModeLocal))
 			clusterSubnets := startFakeController(oc, wg)
 
 			subnet := ovntest.MustParseIPNet(node1.NodeSubnet)
@@ -1134,6 +1151,23 @@ var _ = ginkgo.Describe("Default network controller operations", func() {
 		app.Action = func(ctx *cli.Context) error {
 			_, err := config.InitConfig(ctx, nil, nil)
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			l3GatewayConfig = node1.gatewayConfig(config.GatewayModeLocal, uint(vlanID))
+			nodeAnnotator = kube.NewNodeAnnotator(&kube.Kube{kubeFakeClient}, testNode.Name)
+			err = util.SetL3GatewayConfig(nodeAnnotator, l3GatewayConfig)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			err = nodeAnnotator.Run()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Eventually(func() config.GatewayMode {
+				node, err := f.GetNode(testNode.Name)
+				if err != nil {
+					return ""
+				}
+				l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+				if err != nil {
+					return ""
+				}
+				return l3GatewayConfig.Mode
+			}).Should(gomega.Equal(config.GatewayModeLocal))
 			clusterSubnets := startFakeController(oc, wg)
 
 			subnet := ovntest.MustParseIPNet(node1.NodeSubnet)
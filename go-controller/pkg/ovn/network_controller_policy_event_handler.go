@@ -161,7 +161,7 @@ func (h *networkControllerPolicyEventHandler) UpdateResource(oldObj, newObj inte
 	switch h.objType {
 	case factory.AddressSetPodSelectorType:
 		peerAS := h.extraParameters.(*PodSelectorAddrSetHandlerInfo)
-		return h.bnc.handlePodAddUpdate(peerAS, newObj)
+		return h.bnc.handlePodUpdate(peerAS, oldObj, newObj)
 
 	case factory.LocalPodSelectorType:
 		extraParameters := h.extraParameters.(*NetworkPolicyExtraParameters)
@@ -239,5 +239,10 @@ func (h *networkControllerPolicyEventHandler) IsObjectInTerminalState(obj interf
 }
 
 func needsPolicyResourceUpdateDuringRetry(objType reflect.Type) bool {
+	switch objType {
+	case factory.AddressSetPodSelectorType:
+		// a failed pod update may have to remove the IPs the pod no longer uses
+		return true
+	}
 	return false
 }
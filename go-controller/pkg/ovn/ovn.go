@@ -28,6 +28,8 @@ import (
 	listers "k8s.io/client-go/listers/core/v1"
 	ref "k8s.io/client-go/tools/reference"
 	"k8s.io/klog/v2"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
 )
 
 const egressFirewallDNSDefaultDuration = 30 * time.Minute
@@ -97,6 +99,48 @@ func (oc *DefaultNetworkController) recordPodEvent(reason string, addErr error,
 	}
 }
 
+// recordVirtualMachineEvent posts a normal event about the network plumbing of
+// the VM of a live migratable pod on the pod
+func (oc *DefaultNetworkController) recordVirtualMachineEvent(reason string, pod *kapi.Pod, messageFmt string, args ...interface{}) {
+	podRef, err := ref.GetReference(scheme.Scheme, pod)
+	if err != nil {
+		klog.Errorf("Couldn't get a reference to pod %s/%s to post an event: '%v'",
+			pod.Namespace, pod.Name, err)
+		return
+	}
+	klog.V(5).Infof("Posting a %s event for VM pod %s/%s", kapi.EventTypeNormal, pod.Namespace, pod.Name)
+	oc.recorder.Eventf(podRef, kapi.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// forgetVirtualMachineARPProxySwitch forgets the switch the ARP proxy of the
+// VM of a deleted pod was reported at, once the VM has no pod left
+func (oc *DefaultNetworkController) forgetVirtualMachineARPProxySwitch(pod *kapi.Pod) {
+	vmKey := kubevirt.ExtractVMNameFromPod(pod)
+	if vmKey == nil {
+		return
+	}
+	vmPods, err := kubevirt.FindVMRelatedPods(oc.watchFactory, pod)
+	if err != nil {
+		klog.Warningf("Failed to find the other pods of VM %s: %v", vmKey, err)
+		return
+	}
+	if len(vmPods) == 0 {
+		oc.vmARPProxySwitches.Delete(*vmKey)
+	}
+}
+
+// recordVirtualMachineRouted posts an event on a live migratable pod the VM
+// traffic has just been routed to and, if the pod is the target of a live
+// migration, records how long the traffic took to follow the VM.
+func (oc *DefaultNetworkController) recordVirtualMachineRouted(pod *kapi.Pod) {
+	vm := pod.Labels[kubevirtv1.VirtualMachineNameLabel]
+	oc.recordVirtualMachineEvent(kubevirt.MigrationRoutesConfiguredReason, pod,
+		"Routes to VM %s configured through node %s", vm, pod.Spec.NodeName)
+	if readyTime, ok := kubevirt.MigrationTargetReadyTime(pod); ok {
+		metrics.RecordKubevirtMigrationCutover(pod.Namespace, time.Since(readyTime))
+	}
+}
+
 func (oc *DefaultNetworkController) recordNodeEvent(reason string, addErr error, node *kapi.Node) {
 	nodeRef, err := ref.GetReference(scheme.Scheme, node)
 	if err != nil {
@@ -178,7 +222,20 @@ func (oc *DefaultNetworkController) ensureLocalZonePod(oldPod, pod *kapi.Pod, ad
 	}
 
 	if kubevirt.IsPodLiveMigratable(pod) {
-		return kubevirt.EnsureLocalZonePodAddressesToNodeRoute(oc.watchFactory, oc.nbClient, oc.lsManager, pod, ovntypes.DefaultNetworkName)
+		// the chassis the logical switch port of a live migrated VM is
+		// bound to change with the pods of the VM
+		if !addPort && config.OVNKubernetesFeature.EnableMultiChassisLiveMigration && !util.PodWantsHostNetwork(pod) {
+			if err := kubevirt.EnsureLogicalSwitchPortChassis(oc.nbClient, oc.watchFactory, pod); err != nil {
+				return fmt.Errorf("failed to bind logical switch port of pod %s/%s to its VM chassis: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		routed, err := kubevirt.EnsureLocalZonePodAddressesToNodeRoute(oc.watchFactory, oc.nbClient, oc.lsManager, pod, ovntypes.DefaultNetworkName)
+		if err != nil {
+			return err
+		}
+		if routed {
+			oc.recordVirtualMachineRouted(pod)
+		}
 	}
 
 	return nil
@@ -219,7 +276,13 @@ func (oc *DefaultNetworkController) ensureRemoteZonePod(oldPod, pod *kapi.Pod, a
 		}
 	}
 	if kubevirt.IsPodLiveMigratable(pod) {
-		return kubevirt.EnsureRemoteZonePodAddressesToNodeRoute(oc.controllerName, oc.watchFactory, oc.nbClient, oc.lsManager, pod, ovntypes.DefaultNetworkName)
+		routed, err := kubevirt.EnsureRemoteZonePodAddressesToNodeRoute(oc.controllerName, oc.watchFactory, oc.nbClient, oc.lsManager, pod, ovntypes.DefaultNetworkName)
+		if err != nil {
+			return err
+		}
+		if routed {
+			oc.recordVirtualMachineRouted(pod)
+		}
 	}
 	return nil
 }
@@ -241,6 +304,7 @@ func (oc *DefaultNetworkController) removePod(pod *kapi.Pod, portInfo *lpInfo) e
 	if err != nil {
 		return err
 	}
+	oc.forgetVirtualMachineARPProxySwitch(pod)
 
 	oc.forgetPodReleasedBeforeStartup(string(pod.UID), ovntypes.DefaultNetworkName)
 	return nil
@@ -375,7 +439,7 @@ func (oc *DefaultNetworkController) syncNodeGateway(node *kapi.Node, hostSubnets
 		}
 	} else if hostSubnets != nil {
 		var hostAddrs sets.Set[string]
-		if config.Gateway.Mode == config.GatewayModeShared {
+		if l3GatewayConfig.GatewayMode() == config.GatewayModeShared {
 			hostAddrs, err = util.ParseNodeHostCIDRsDropNetMask(node)
 			if err != nil && !util.IsAnnotationNotSetError(err) {
 				return fmt.Errorf("failed to get host CIDRs for node: %s: %v", node.Name, err)
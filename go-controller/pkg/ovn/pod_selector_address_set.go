@@ -323,6 +323,11 @@ type PodSelectorAddrSetHandlerInfo struct {
 	ipv4Mode bool
 	ipv6Mode bool
 
+	// observedIPsLock serializes the address set updates on networks without
+	// IPAM, where the address set is rebuilt from the observed IPs of all the
+	// selected pods
+	observedIPsLock sync.Mutex
+
 	stopChan <-chan struct{}
 }
 
@@ -408,6 +413,10 @@ func (bnc *BaseNetworkController) handlePodAddUpdate(podHandlerInfo *PodSelector
 	if podHandlerInfo.deleted {
 		return nil
 	}
+	if bnc.usesObservedPodIPs() {
+		podHandlerInfo.observedIPsLock.Lock()
+		defer podHandlerInfo.observedIPsLock.Unlock()
+	}
 	pods := make([]*kapi.Pod, 0, len(objs))
 	for _, obj := range objs {
 		pod := obj.(*kapi.Pod)
@@ -421,6 +430,71 @@ func (bnc *BaseNetworkController) handlePodAddUpdate(podHandlerInfo *PodSelector
 	return podHandlerInfo.addPods(pods...)
 }
 
+// handlePodUpdate adds the IP addresses of a pod that has been selected by
+// PodSelectorAddressSet. On networks without IPAM, the observed IP addresses
+// of a pod change over time, so the address set is rebuilt from the observed
+// IP addresses of all the selected pods instead.
+func (bnc *BaseNetworkController) handlePodUpdate(podHandlerInfo *PodSelectorAddrSetHandlerInfo, oldObj, newObj interface{}) error {
+	if !bnc.usesObservedPodIPs() {
+		return bnc.handlePodAddUpdate(podHandlerInfo, newObj)
+	}
+	podHandlerInfo.RLock()
+	defer podHandlerInfo.RUnlock()
+	if podHandlerInfo.deleted {
+		return nil
+	}
+	podHandlerInfo.observedIPsLock.Lock()
+	defer podHandlerInfo.observedIPsLock.Unlock()
+	return bnc.setSelectedPodsObservedIPs(podHandlerInfo)
+}
+
+// usesObservedPodIPs returns true if the pod IPs of the network are learnt
+// from the pods traffic rather than assigned
+func (bnc *BaseNetworkController) usesObservedPodIPs() bool {
+	return util.IsObservedPodIPsEnabled() && !bnc.doesNetworkRequireIPAM()
+}
+
+// setSelectedPodsObservedIPs replaces the IPs of the address set with the IPs
+// observed on all the selected pods. An IP a pod no longer uses is kept as long
+// as another selected pod uses it, and the IPs left behind by failed events are
+// removed on the next one.
+// must be called with PodSelectorAddrSetHandlerInfo read lock and observedIPsLock
+func (bnc *BaseNetworkController) setSelectedPodsObservedIPs(podHandlerInfo *PodSelectorAddrSetHandlerInfo) error {
+	namespaces := []string{podHandlerInfo.namespace}
+	if podHandlerInfo.namespace == "" && podHandlerInfo.namespaceSelector != nil &&
+		!podHandlerInfo.namespaceSelector.Empty() {
+		allNamespaces, err := bnc.watchFactory.GetNamespaces()
+		if err != nil {
+			return fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		namespaces = namespaces[:0]
+		for _, namespace := range allNamespaces {
+			if podHandlerInfo.namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+				namespaces = append(namespaces, namespace.Name)
+			}
+		}
+	}
+	ips := []net.IP{}
+	for _, namespace := range namespaces {
+		pods, err := bnc.watchFactory.GetPods(namespace)
+		if err != nil {
+			return fmt.Errorf("failed to list pods in namespace %q: %w", namespace, err)
+		}
+		for _, pod := range pods {
+			if pod.Spec.NodeName == "" || util.PodCompleted(pod) ||
+				!podHandlerInfo.podSelector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			podIPs, err := util.GetPodIPsOfNetwork(pod, podHandlerInfo.netInfo)
+			if err != nil {
+				continue
+			}
+			ips = append(ips, podIPs...)
+		}
+	}
+	return podHandlerInfo.addressSet.SetIPs(ips)
+}
+
 // handlePodDelete removes the IP address of a pod that no longer
 // matches a selector
 func (bnc *BaseNetworkController) handlePodDelete(podHandlerInfo *PodSelectorAddrSetHandlerInfo, obj interface{}) error {
@@ -441,6 +515,11 @@ func (bnc *BaseNetworkController) handlePodDelete(podHandlerInfo *PodSelectorAdd
 		klog.Infof("Pod %s/%s not scheduled on any node, skipping it", pod.Namespace, pod.Name)
 		return nil
 	}
+	if bnc.usesObservedPodIPs() {
+		podHandlerInfo.observedIPsLock.Lock()
+		defer podHandlerInfo.observedIPsLock.Unlock()
+		return bnc.setSelectedPodsObservedIPs(podHandlerInfo)
+	}
 	collidingPodName, err := bnc.podSelectorPodNeedsDelete(pod, podHandlerInfo)
 	if err != nil {
 		return fmt.Errorf("failed to check if ip is reused for pod %s/%s: %w", pod.Namespace, pod.Name, err)
@@ -7,19 +7,26 @@ import (
 	"runtime"
 	"time"
 
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	"github.com/onsi/gomega"
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	addressset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/address_set"
 	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 
 	v1 "k8s.io/api/core/v1"
 	knet "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func getPolicyKeyWithKind(policy *knet.NetworkPolicy) string {
@@ -497,6 +504,81 @@ var _ = ginkgo.Describe("OVN PodSelectorAddressSet", func() {
 	})
 })
 
+var _ = ginkgo.Describe("OVN PodSelectorAddressSet observed IPs", func() {
+	ginkgo.It("rebuilds the address set from the observed IPs of the selected pods on networks without IPAM", func() {
+		const nadName = "ns1/localnet"
+		gomega.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+
+		newObservedPod := func(name, observedIPs string) *v1.Pod {
+			return &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "ns1",
+					Annotations: map[string]string{
+						nadapi.NetworkAttachmentAnnot:        "localnet",
+						util.OvnPodObservedIPsAnnotationName: observedIPs,
+					},
+				},
+				Spec: v1.PodSpec{NodeName: "node1"},
+			}
+		}
+		oldPod1 := newObservedPod("pod1", `{"ns1/localnet":["192.168.10.5","192.168.10.6"]}`)
+		newPod1 := newObservedPod("pod1", `{"ns1/localnet":["192.168.10.7"]}`)
+		pod2 := newObservedPod("pod2", `{"ns1/localnet":["192.168.10.6"]}`)
+
+		kubeClient := fake.NewSimpleClientset(&v1.PodList{Items: []v1.Pod{*newPod1, *pod2}})
+		watchFactory, err := factory.NewMasterWatchFactory(&util.OVNMasterClientset{KubeClient: kubeClient})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(watchFactory.Start()).To(gomega.Succeed())
+		defer watchFactory.Shutdown()
+
+		config.OVNKubernetesFeature.EnableMultiNetwork = true
+		config.OVNKubernetesFeature.EnableMultiNetworkPolicy = true
+		config.OVNKubernetesFeature.EnableObservedPodIPs = true
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:  cnitypes.NetConf{Name: "localnet", Type: "ovn-k8s-cni-overlay"},
+			Topology: types.LocalnetTopology,
+			NADName:  nadName,
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		netInfo.AddNAD(nadName)
+
+		asFactory := addressset.NewFakeAddressSetFactory("test-controller")
+		dbIDs := getPodSelectorAddrSetDbIDs("test", "test-controller")
+		as, err := asFactory.NewAddressSet(dbIDs, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		bnc := &BaseNetworkController{
+			CommonNetworkControllerInfo: CommonNetworkControllerInfo{watchFactory: watchFactory},
+			NetInfo:                     netInfo,
+		}
+		handlerInfo := &PodSelectorAddrSetHandlerInfo{
+			addressSet:  as,
+			netInfo:     netInfo,
+			ipv4Mode:    true,
+			namespace:   "ns1",
+			podSelector: labels.Everything(),
+		}
+
+		// an IP left behind by a failed update
+		gomega.Expect(bnc.handlePodAddUpdate(handlerInfo, oldPod1, pod2)).To(gomega.Succeed())
+		gomega.Expect(as.AddIPs([]net.IP{net.ParseIP("192.168.10.9")})).To(gomega.Succeed())
+		asFactory.ExpectAddressSetWithIPs(dbIDs, []string{"192.168.10.5", "192.168.10.6", "192.168.10.9"})
+
+		// the IP pod1 no longer uses but pod2 does is kept
+		gomega.Expect(bnc.handlePodUpdate(handlerInfo, oldPod1, newPod1)).To(gomega.Succeed())
+		asFactory.ExpectAddressSetWithIPs(dbIDs, []string{"192.168.10.6", "192.168.10.7"})
+
+		gomega.Expect(kubeClient.CoreV1().Pods("ns1").Delete(context.TODO(), pod2.Name, metav1.DeleteOptions{})).
+			To(gomega.Succeed())
+		gomega.Eventually(func() error {
+			_, err := watchFactory.GetPod("ns1", pod2.Name)
+			return err
+		}).Should(gomega.HaveOccurred())
+		gomega.Expect(bnc.handlePodDelete(handlerInfo, pod2)).To(gomega.Succeed())
+		asFactory.ExpectAddressSetWithIPs(dbIDs, []string{"192.168.10.7"})
+	})
+})
+
 var _ = ginkgo.Describe("shortLabelSelectorString function", func() {
 	ginkgo.It("handles LabelSelectorRequirement.Values order", func() {
 		ls1 := &metav1.LabelSelector{
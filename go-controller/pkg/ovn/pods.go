@@ -20,6 +20,8 @@ import (
 	kapi "k8s.io/api/core/v1"
 	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
 )
 
 func (oc *DefaultNetworkController) syncPods(pods []interface{}) error {
@@ -172,6 +174,9 @@ func (oc *DefaultNetworkController) deleteLogicalPort(pod *kapi.Pod, portInfo *l
 			return fmt.Errorf("cannot delete GR SNAT for pod %s: %w", podDesc, err)
 		}
 	}
+	if err := oc.deleteGatewayUplinkPodSNATs(pInfo.logicalSwitch, pInfo.ips); err != nil {
+		return fmt.Errorf("cannot delete uplink SNAT for pod %s: %w", podDesc, err)
+	}
 	podNsName := ktypes.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
 	if err := oc.deleteGWRoutesForPod(podNsName, pInfo.ips); err != nil {
 		return fmt.Errorf("cannot delete GW Routes for pod %s: %w", podDesc, err)
@@ -267,6 +272,11 @@ func (oc *DefaultNetworkController) addLogicalPort(pod *kapi.Pod) (err error) {
 			return err
 		}
 	}
+	// the traffic of the namespaces selecting an uplink of the node is
+	// masqueraded to its IPs
+	if ops, err = oc.addGatewayUplinkPodSNATOps(pod, podAnnotation.IPs, ops); err != nil {
+		return err
+	}
 
 	recordOps, txOkCallBack, _, err := oc.AddConfigDurationRecord("pod", pod.Namespace, pod.Name)
 	if err != nil {
@@ -294,13 +304,34 @@ func (oc *DefaultNetworkController) addLogicalPort(pod *kapi.Pod) (err error) {
 		return fmt.Errorf("UUID is empty from LSP: %+v", *lsp)
 	}
 
+	// The logical switch port shared by the pods of a live migrated VM
+	// belongs to the switch owning the VM subnet
+	if podAnnotation.LogicalSwitchPort != "" {
+		switchName, _ = kubevirt.ZoneContainsPodSubnet(oc.lsManager, podAnnotation.IPs)
+	}
+
 	// Add the pod's logical switch port to the port cache
 	_ = oc.logicalPortCache.add(pod, switchName, ovntypes.DefaultNetworkName, lsp.UUID, podAnnotation.MAC, podAnnotation.IPs)
 
 	if kubevirt.IsPodLiveMigratable(pod) {
-		if err := kubevirt.EnsureDHCPOptionsForMigratablePod(oc.controllerName, oc.nbClient, oc.watchFactory, pod, podAnnotation.IPs, lsp); err != nil {
+		vm := pod.Labels[kubevirtv1.VirtualMachineNameLabel]
+		dhcpStart := time.Now()
+		dhcpCreated, err := kubevirt.EnsureDHCPOptionsForMigratablePod(oc.controllerName, oc.nbClient, oc.watchFactory, pod, podAnnotation.IPs, lsp)
+		if err != nil {
 			return err
 		}
+		if dhcpCreated {
+			metrics.RecordKubevirtDHCPOptions(pod.Namespace, time.Since(dhcpStart))
+			oc.recordVirtualMachineEvent(kubevirt.DHCPOptionsConfiguredReason, pod,
+				"DHCP options for VM %s configured at port %s", vm, lsp.Name)
+		}
+		// the ARP proxy is reported once per switch the VM is served at
+		if vmKey := kubevirt.ExtractVMNameFromPod(pod); vmKey != nil {
+			if previous, loaded := oc.vmARPProxySwitches.Swap(*vmKey, switchName); !loaded || previous != switchName {
+				oc.recordVirtualMachineEvent(kubevirt.ARPProxyConfiguredReason, pod,
+					"ARP proxy for VM %s configured at switch %s with MAC %s", vm, switchName, kubevirt.ARPProxyMAC)
+			}
+		}
 	}
 
 	//observe the pod creation latency metric for newly created pods only
@@ -456,6 +456,69 @@ var _ = ginkgo.Describe("OVN Pod Operations", func() {
 		fakeOvn.shutdown()
 	})
 
+	ginkgo.It("does not reserve the load balancer health check source address held by a pod", func() {
+		t := newTPod(node1Name, "10.128.1.0/24", "10.128.1.2", "10.128.1.1", "myPod", "10.128.1.4",
+			"0a:58:0a:80:01:04", "namespace1")
+		pod := newPod(t.namespace, t.podName, t.nodeName, t.podIP)
+		setPodAnnotations(pod, t)
+		// a stale reservation, e.g. recorded before health checks were disabled
+		t3 := newTPod("node3", "10.128.3.0/24", "10.128.3.2", "10.128.3.1", "myPod3", "10.128.3.4",
+			"0a:58:0a:80:03:04", "namespace1")
+		pod3 := newPod(t3.namespace, t3.podName, t3.nodeName, t3.podIP)
+		setPodAnnotations(pod3, t3)
+		initialDB.NBData = append(initialDB.NBData,
+			&nbdb.LogicalSwitch{
+				Name:        "node3",
+				ExternalIDs: map[string]string{ovntypes.LBHealthCheckSourceExternalID: "10.128.3.4"},
+			},
+			&nbdb.LogicalSwitch{
+				Name:        "node4",
+				ExternalIDs: map[string]string{ovntypes.LBHealthCheckSourceExternalID: "10.128.4.4"},
+			},
+		)
+		fakeOvn.startWithDBSetup(initialDB, &v1.PodList{Items: []v1.Pod{*pod, *pod3}})
+		gomega.Expect(fakeOvn.controller.syncLBHealthCheckPodIndex()).To(gomega.Succeed())
+
+		// the address of an existing switch may be held by a pod
+		ips, err := fakeOvn.controller.findLBHealthCheckSourceIPs(node1Name, ovntest.MustParseIPNets("10.128.1.0/24"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(ips).To(gomega.BeEmpty())
+
+		// even if recorded as reserved
+		ips, err = fakeOvn.controller.findLBHealthCheckSourceIPs("node3", ovntest.MustParseIPNets("10.128.3.0/24"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(ips).To(gomega.BeEmpty())
+
+		// the address of a new switch, or not held by a pod, is reserved
+		ips, err = fakeOvn.controller.findLBHealthCheckSourceIPs(node2Name, ovntest.MustParseIPNets("10.128.2.0/24"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(ips).To(gomega.ConsistOf(ovntest.MustParseIPNet("10.128.2.4/32")))
+		ips, err = fakeOvn.controller.findLBHealthCheckSourceIPs("node4", ovntest.MustParseIPNets("10.128.4.0/24"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(ips).To(gomega.ConsistOf(ovntest.MustParseIPNet("10.128.4.4/32")))
+
+		// the pods deleted since the index was built do not hold addresses
+		err = fakeOvn.fakeClient.KubeClient.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Eventually(func() ([]*net.IPNet, error) {
+			return fakeOvn.controller.findLBHealthCheckSourceIPs(node1Name, ovntest.MustParseIPNets("10.128.1.0/24"))
+		}).Should(gomega.ConsistOf(ovntest.MustParseIPNet("10.128.1.4/32")))
+	})
+
+	ginkgo.It("clears the load balancer health check source addresses with health checks disabled", func() {
+		initialDB.NBData = append(initialDB.NBData, &nbdb.LogicalSwitch{
+			Name:        "node3",
+			ExternalIDs: map[string]string{ovntypes.LBHealthCheckSourceExternalID: "10.128.3.4"},
+		})
+		fakeOvn.startWithDBSetup(initialDB)
+
+		err := fakeOvn.controller.createNodeLogicalSwitch("node3", ovntest.MustParseIPNets("10.128.3.0/24"), "", "")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		sw, err := libovsdbops.GetLogicalSwitch(fakeOvn.nbClient, &nbdb.LogicalSwitch{Name: "node3"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(sw.ExternalIDs).NotTo(gomega.HaveKey(ovntypes.LBHealthCheckSourceExternalID))
+	})
+
 	ginkgo.Context("during execution", func() {
 
 		ginkgo.It("reconciles an existing pod", func() {
@@ -0,0 +1,228 @@
+package ovn
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/factory"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Secondary layer2 network KubeVirt live migration", func() {
+	const (
+		netName   = "tenantblue"
+		nadName   = "ns1/tenantblue"
+		addresses = "0a:58:c0:a8:01:05 192.168.1.5"
+	)
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+	})
+
+	It("moves the VM addresses to the port of the target pod once ready", func() {
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:  cnitypes.NetConf{Name: netName},
+			Topology: ovntypes.Layer2Topology,
+			Subnets:  "192.168.1.0/24",
+			NADName:  nadName,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		newVMPod := func(name, nodeName string, age time.Duration) *v1.Pod {
+			return &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "ns1",
+					UID:               ktypes.UID("uid-" + name),
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+					Labels:            map[string]string{kubevirtv1.VirtualMachineNameLabel: "vm1"},
+					Annotations:       map[string]string{kubevirtv1.AllowPodBridgeNetworkLiveMigrationAnnotation: ""},
+				},
+				Spec: v1.PodSpec{NodeName: nodeName},
+			}
+		}
+		sourcePod := newVMPod("virt-launcher-vm1-source", "node1", time.Hour)
+		targetPod := newVMPod("virt-launcher-vm1-target", "node2", time.Minute)
+
+		fakeClient := util.GetOVNClientset(sourcePod, targetPod)
+		wf, err := factory.NewMasterWatchFactory(fakeClient.GetMasterClientset())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wf.Start()).To(Succeed())
+		defer wf.Shutdown()
+
+		sourceLSP := &nbdb.LogicalSwitchPort{
+			UUID:         "source-UUID",
+			Name:         util.GetSecondaryNetworkLogicalPortName("ns1", sourcePod.Name, nadName),
+			Addresses:    []string{addresses},
+			PortSecurity: []string{addresses},
+		}
+		ls := &nbdb.LogicalSwitch{
+			UUID:  "ls-UUID",
+			Name:  netInfo.GetNetworkScopedName(ovntypes.OVNLayer2Switch),
+			Ports: []string{sourceLSP.UUID},
+		}
+		nbClient, cleanup, err := libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{
+			NBData: []libovsdbtest.TestData{ls, sourceLSP},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer cleanup.Cleanup()
+
+		bnc := &BaseNetworkController{
+			CommonNetworkControllerInfo: CommonNetworkControllerInfo{nbClient: nbClient, watchFactory: wf},
+			NetInfo:                     netInfo,
+		}
+
+		// the VM still runs on the source pod
+		targetLSP := &nbdb.LogicalSwitchPort{
+			Name:         util.GetSecondaryNetworkLogicalPortName("ns1", targetPod.Name, nadName),
+			Addresses:    []string{addresses},
+			PortSecurity: []string{addresses},
+		}
+		ops, err := bnc.setVirtualMachinePortAddresses(targetPod, nadName, targetLSP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ops).To(BeEmpty())
+		Expect(targetLSP.Addresses).To(BeEmpty())
+		Expect(targetLSP.PortSecurity).To(ConsistOf(addresses))
+
+		// the target pod is ready to receive the traffic of the VM
+		targetPod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp] = time.Now().String()
+		_, err = fakeClient.KubeClient.CoreV1().Pods("ns1").Update(context.TODO(), targetPod, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() bool {
+			pod, err := wf.GetPod("ns1", targetPod.Name)
+			return err == nil && pod.Annotations[kubevirtv1.MigrationTargetReadyTimestamp] != ""
+		}).Should(BeTrue())
+
+		targetLSP.Addresses = []string{addresses}
+		ops, err = bnc.setVirtualMachinePortAddresses(targetPod, nadName, targetLSP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targetLSP.Addresses).To(ConsistOf(addresses))
+		ops, err = libovsdbops.CreateOrUpdateLogicalSwitchPortsOnSwitchOps(nbClient, ops, ls, targetLSP)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = libovsdbops.TransactAndCheck(nbClient, ops)
+		Expect(err).NotTo(HaveOccurred())
+
+		expectedSourceLSP := sourceLSP.DeepCopy()
+		expectedSourceLSP.Addresses = nil
+		expectedTargetLSP := targetLSP.DeepCopy()
+		expectedTargetLSP.UUID = "target-UUID"
+		expectedLS := ls.DeepCopy()
+		expectedLS.Ports = []string{sourceLSP.UUID, expectedTargetLSP.UUID}
+		Eventually(nbClient).Should(libovsdbtest.HaveData(expectedLS, expectedSourceLSP, expectedTargetLSP))
+	})
+})
+
+var _ = Describe("Secondary layer2 network gateway routers", func() {
+	const netName = "tenantblue"
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.OVNKubernetesFeature.EnableMultiNetwork = true
+		config.OVNKubernetesFeature.EnableInterconnect = true
+		config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+	})
+
+	It("only connects a gateway router to the network switch in zones of a single node", func() {
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:       cnitypes.NetConf{Name: netName},
+			Topology:      ovntypes.Layer2Topology,
+			Subnets:       "192.168.1.0/24",
+			EnableGateway: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		node1 := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+		node2 := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}
+
+		// both nodes of the zone were given a gateway router joining the
+		// network switch with the same address
+		switchPorts := []*nbdb.LogicalSwitchPort{}
+		nbData := []libovsdbtest.TestData{}
+		for _, node := range []*v1.Node{node1, node2} {
+			gatewayRouter := netInfo.GetNetworkScopedName(ovntypes.GWRouterPrefix + node.Name)
+			lrp := &nbdb.LogicalRouterPort{
+				UUID:     gatewayRouter + "-lrp-UUID",
+				Name:     ovntypes.GWRouterToJoinSwitchPrefix + gatewayRouter,
+				MAC:      util.IPAddrToHWAddr(net.ParseIP("192.168.1.1")).String(),
+				Networks: []string{"192.168.1.1/24"},
+			}
+			lr := &nbdb.LogicalRouter{
+				UUID:        gatewayRouter + "-UUID",
+				Name:        gatewayRouter,
+				Ports:       []string{lrp.UUID},
+				ExternalIDs: map[string]string{ovntypes.NetworkExternalID: netName},
+			}
+			lsp := &nbdb.LogicalSwitchPort{
+				UUID:      gatewayRouter + "-lsp-UUID",
+				Name:      ovntypes.JoinSwitchToGWRouterPrefix + gatewayRouter,
+				Type:      "router",
+				Addresses: []string{"router"},
+				Options:   map[string]string{"router-port": lrp.Name},
+			}
+			switchPorts = append(switchPorts, lsp)
+			nbData = append(nbData, lrp, lr, lsp)
+		}
+		ls := &nbdb.LogicalSwitch{
+			UUID:  "ls-UUID",
+			Name:  netInfo.GetNetworkScopedName(ovntypes.OVNLayer2Switch),
+			Ports: []string{switchPorts[0].UUID, switchPorts[1].UUID},
+		}
+		nbData = append(nbData, ls)
+		nbClient, cleanup, err := libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{NBData: nbData}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer cleanup.Cleanup()
+
+		oc := &BaseSecondaryLayer2NetworkController{
+			BaseSecondaryNetworkController: BaseSecondaryNetworkController{
+				BaseNetworkController: BaseNetworkController{
+					CommonNetworkControllerInfo: CommonNetworkControllerInfo{nbClient: nbClient, zone: ovntypes.OvnDefaultZone},
+					NetInfo:                     netInfo,
+					localZoneNodes:              &sync.Map{},
+				},
+			},
+		}
+
+		// the gateway routers of both local nodes are removed
+		gatewayRoutersAndPorts := func() []string {
+			names := []string{}
+			routers := []nbdb.LogicalRouter{}
+			Expect(nbClient.List(context.Background(), &routers)).To(Succeed())
+			for _, router := range routers {
+				names = append(names, router.Name)
+			}
+			sw, err := libovsdbops.GetLogicalSwitch(nbClient, &nbdb.LogicalSwitch{Name: ls.Name})
+			Expect(err).NotTo(HaveOccurred())
+			return append(names, sw.Ports...)
+		}
+		Expect(oc.syncNodes([]interface{}{node1, node2})).To(Succeed())
+		Eventually(gatewayRoutersAndPorts).Should(BeEmpty())
+
+		// and not added back when the nodes are updated
+		Expect(oc.addUpdateLocalNodeEvent(node2, true)).To(Succeed())
+		Consistently(gatewayRoutersAndPorts).Should(BeEmpty())
+
+		// the last node of the zone gets its gateway router back on its
+		// next update
+		Expect(oc.deleteNodeEvent(node1)).To(Succeed())
+		_, gwSync := oc.gatewaysFailed.Load(node2.Name)
+		Expect(gwSync).To(BeTrue())
+		Expect(oc.gatewayRouterSupported()).To(BeTrue())
+	})
+})
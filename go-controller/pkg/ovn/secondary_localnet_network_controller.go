@@ -2,11 +2,15 @@ package ovn
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/allocator/pod"
 	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	addressset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/address_set"
 	lsm "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/logical_switch_manager"
@@ -127,9 +131,128 @@ func (oc *SecondaryLocalnetNetworkController) Init() error {
 		return err
 	}
 
+	if len(oc.VLANTrunk()) > 0 {
+		if err = oc.addVLANTrunkACLs(switchName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addVLANTrunkACLs drops the traffic tagged with the VLANs not allowed on the
+// trunk, both from the pods and from the localnet. There is no delete function
+// for these ACLs, they are garbage-collected along with the network switch.
+func (oc *SecondaryLocalnetNetworkController) addVLANTrunkACLs(switchName string) error {
+	acls := buildVLANTrunkACLs(oc.controllerName, oc.GetNetworkName(), oc.VLANTrunk())
+	ops, err := libovsdbops.CreateOrUpdateACLsOps(oc.nbClient, nil, acls...)
+	if err != nil {
+		return fmt.Errorf("failed to create or update VLAN trunk ACLs of network %s: %v", oc.GetNetworkName(), err)
+	}
+	ops, err = libovsdbops.AddACLsToLogicalSwitchOps(oc.nbClient, ops, switchName, acls...)
+	if err != nil {
+		return fmt.Errorf("failed to add VLAN trunk ACLs to switch %s: %v", switchName, err)
+	}
+	_, err = libovsdbops.TransactAndCheck(oc.nbClient, ops)
+	return err
+}
+
+func buildVLANTrunkACLs(controllerName, netName string, trunk []util.VLANRange) []*nbdb.ACL {
+	allowed := make([]string, 0, len(trunk))
+	for _, r := range trunk {
+		if r.Start == r.End {
+			allowed = append(allowed, fmt.Sprintf("vlan.vid == %d", r.Start))
+		} else {
+			allowed = append(allowed, fmt.Sprintf("%d <= vlan.vid <= %d", r.Start, r.End))
+		}
+	}
+	match := fmt.Sprintf("vlan.present && !(%s)", strings.Join(allowed, " || "))
+
+	acls := make([]*nbdb.ACL, 0, 2)
+	for _, aclDir := range []libovsdbutil.ACLDirection{libovsdbutil.ACLEgress, libovsdbutil.ACLIngress} {
+		dbIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLSecondaryNetworkVLANTrunk, controllerName,
+			map[libovsdbops.ExternalIDKey]string{
+				libovsdbops.ObjectNameKey:      netName,
+				libovsdbops.PolicyDirectionKey: string(aclDir),
+			})
+		acls = append(acls, libovsdbutil.BuildACL(dbIDs, types.VLANTrunkDenyPriority, match,
+			nbdb.ACLActionDrop, nil, libovsdbutil.ACLDirectionToACLPipeline(aclDir)))
+	}
+	return acls
+}
+
+// addPodVLANACLsOps returns the ops to drop the traffic of a pod on a VLAN
+// trunk that is not tagged with the VLAN the pod selected. The pods not
+// selecting a VLAN tag their traffic on their own.
+func (bsnc *BaseSecondaryNetworkController) addPodVLANACLsOps(ops []ovsdb.Operation, switchName, portName string,
+	vlanID uint) ([]ovsdb.Operation, error) {
+	acls := buildPodVLANACLs(bsnc.controllerName, portName, vlanID)
+	ops, err := libovsdbops.CreateOrUpdateACLsOps(bsnc.nbClient, ops, acls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update VLAN ACLs of port %s: %v", portName, err)
+	}
+	ops, err = libovsdbops.AddACLsToLogicalSwitchOps(bsnc.nbClient, ops, switchName, acls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add VLAN ACLs of port %s to switch %s: %v", portName, switchName, err)
+	}
+	return ops, nil
+}
+
+// deletePodVLANACLs deletes the VLAN ACLs of a pod port
+func (bsnc *BaseSecondaryNetworkController) deletePodVLANACLs(portName string) error {
+	predicateIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLPodVLAN, bsnc.controllerName,
+		map[libovsdbops.ExternalIDKey]string{
+			libovsdbops.ObjectNameKey: portName,
+		})
+	return bsnc.deletePodVLANACLsWithPredicate(libovsdbops.GetPredicate[*nbdb.ACL](predicateIDs, nil))
+}
+
+// deleteStalePodVLANACLs deletes the VLAN ACLs of the pod ports that are not expected
+func (bsnc *BaseSecondaryNetworkController) deleteStalePodVLANACLs(expectedLogicalPorts map[string]bool) error {
+	predicateIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLPodVLAN, bsnc.controllerName, nil)
+	return bsnc.deletePodVLANACLsWithPredicate(libovsdbops.GetPredicate[*nbdb.ACL](predicateIDs, func(item *nbdb.ACL) bool {
+		return !expectedLogicalPorts[item.ExternalIDs[libovsdbops.ObjectNameKey.String()]]
+	}))
+}
+
+func (bsnc *BaseSecondaryNetworkController) deletePodVLANACLsWithPredicate(p func(item *nbdb.ACL) bool) error {
+	acls, err := libovsdbops.FindACLsWithPredicate(bsnc.nbClient, p)
+	if err != nil {
+		return fmt.Errorf("failed to find pod VLAN ACLs of network %s: %v", bsnc.GetNetworkName(), err)
+	}
+	if len(acls) == 0 {
+		return nil
+	}
+	// ACLs referenced by the switch are deleted by the db once no longer referenced
+	switchName := bsnc.GetNetworkScopedName(types.OVNLocalnetSwitch)
+	err = libovsdbops.RemoveACLsFromLogicalSwitchesWithPredicate(bsnc.nbClient, func(item *nbdb.LogicalSwitch) bool {
+		return item.Name == switchName
+	}, acls...)
+	if err != nil {
+		return fmt.Errorf("failed to delete pod VLAN ACLs from switch %s: %v", switchName, err)
+	}
 	return nil
 }
 
+func buildPodVLANACLs(controllerName, portName string, vlanID uint) []*nbdb.ACL {
+	acls := make([]*nbdb.ACL, 0, 2)
+	for _, aclDir := range []libovsdbutil.ACLDirection{libovsdbutil.ACLEgress, libovsdbutil.ACLIngress} {
+		dbIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLPodVLAN, controllerName,
+			map[libovsdbops.ExternalIDKey]string{
+				libovsdbops.ObjectNameKey:      portName,
+				libovsdbops.PolicyDirectionKey: string(aclDir),
+			})
+		portField := "inport"
+		if aclDir == libovsdbutil.ACLIngress {
+			portField = "outport"
+		}
+		match := fmt.Sprintf("%s == %q && !(vlan.present && vlan.vid == %d)", portField, portName, vlanID)
+		acls = append(acls, libovsdbutil.BuildACL(dbIDs, types.VLANTrunkDenyPriority, match,
+			nbdb.ACLActionDrop, nil, libovsdbutil.ACLDirectionToACLPipeline(aclDir)))
+	}
+	return acls
+}
+
 func (oc *SecondaryLocalnetNetworkController) Stop() {
 	klog.Infof("Stoping controller for secondary network %s", oc.GetNetworkName())
 	oc.BaseSecondaryLayer2NetworkController.stop()
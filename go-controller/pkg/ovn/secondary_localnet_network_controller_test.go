@@ -0,0 +1,139 @@
+package ovn
+
+import (
+	"strconv"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	lsm "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/ovn/logical_switch_manager"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Secondary localnet network VLAN trunk", func() {
+	const (
+		netName        = "tenantblue"
+		controllerName = netName + "-network-controller"
+	)
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.OVNKubernetesFeature.EnableMultiNetwork = true
+	})
+
+	It("lets the pods tag their traffic with the VLANs of the trunk only", func() {
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:   cnitypes.NetConf{Name: netName},
+			Topology:  ovntypes.LocalnetTopology,
+			Subnets:   "192.168.1.0/24",
+			VLANTrunk: "100,200-299",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		nbClient, cleanup, err := libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer cleanup.Cleanup()
+
+		oc := &SecondaryLocalnetNetworkController{
+			BaseSecondaryLayer2NetworkController{
+				BaseSecondaryNetworkController: BaseSecondaryNetworkController{
+					BaseNetworkController: BaseNetworkController{
+						CommonNetworkControllerInfo: CommonNetworkControllerInfo{nbClient: nbClient},
+						controllerName:              controllerName,
+						NetInfo:                     netInfo,
+						lsManager:                   lsm.NewL2SwitchManager(),
+					},
+				},
+			},
+		}
+		Expect(oc.Init()).To(Succeed())
+
+		localnetPort := &nbdb.LogicalSwitchPort{
+			UUID:      "localnet-port-UUID",
+			Name:      netInfo.GetNetworkScopedName(ovntypes.OVNLocalnetPort),
+			Addresses: []string{"unknown"},
+			Type:      "localnet",
+			Options:   map[string]string{"network_name": netName},
+		}
+		match := "vlan.present && !(vlan.vid == 100 || 200 <= vlan.vid <= 299)"
+		var acls []*nbdb.ACL
+		for _, aclDir := range []libovsdbutil.ACLDirection{libovsdbutil.ACLEgress, libovsdbutil.ACLIngress} {
+			dbIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLSecondaryNetworkVLANTrunk, controllerName,
+				map[libovsdbops.ExternalIDKey]string{
+					libovsdbops.ObjectNameKey:      netName,
+					libovsdbops.PolicyDirectionKey: string(aclDir),
+				})
+			acl := libovsdbutil.BuildACL(dbIDs, ovntypes.VLANTrunkDenyPriority, match, nbdb.ACLActionDrop, nil,
+				libovsdbutil.ACLDirectionToACLPipeline(aclDir))
+			acl.UUID = string(aclDir) + "-acl-UUID"
+			acls = append(acls, acl)
+		}
+		localnetSwitch := &nbdb.LogicalSwitch{
+			UUID: "localnet-switch-UUID",
+			Name: netInfo.GetNetworkScopedName(ovntypes.OVNLocalnetSwitch),
+			ExternalIDs: map[string]string{
+				ovntypes.NetworkExternalID:         netName,
+				ovntypes.TopologyExternalID:        ovntypes.LocalnetTopology,
+				ovntypes.TopologyVersionExternalID: strconv.Itoa(oc.topologyVersion),
+			},
+			OtherConfig: map[string]string{
+				"subnet":        "192.168.1.0/24",
+				"vlan-passthru": "true",
+			},
+			Ports: []string{localnetPort.UUID},
+			ACLs:  []string{acls[0].UUID, acls[1].UUID},
+		}
+		Eventually(nbClient).Should(libovsdbtest.HaveData(localnetSwitch, localnetPort, acls[0], acls[1]))
+
+		// each pod selecting a VLAN is held to it
+		podVLANACLs := func(portName string, vlanMatch string) []*nbdb.ACL {
+			var acls []*nbdb.ACL
+			for _, aclDir := range []libovsdbutil.ACLDirection{libovsdbutil.ACLEgress, libovsdbutil.ACLIngress} {
+				dbIDs := libovsdbops.NewDbObjectIDs(libovsdbops.ACLPodVLAN, controllerName,
+					map[libovsdbops.ExternalIDKey]string{
+						libovsdbops.ObjectNameKey:      portName,
+						libovsdbops.PolicyDirectionKey: string(aclDir),
+					})
+				portField := "inport"
+				if aclDir == libovsdbutil.ACLIngress {
+					portField = "outport"
+				}
+				acl := libovsdbutil.BuildACL(dbIDs, ovntypes.VLANTrunkDenyPriority,
+					portField+` == "`+portName+`" && `+vlanMatch, nbdb.ACLActionDrop, nil,
+					libovsdbutil.ACLDirectionToACLPipeline(aclDir))
+				acl.UUID = portName + "-" + string(aclDir) + "-acl-UUID"
+				acls = append(acls, acl)
+			}
+			return acls
+		}
+		ops, err := oc.addPodVLANACLsOps(nil, localnetSwitch.Name, "pod1", 200)
+		Expect(err).NotTo(HaveOccurred())
+		ops, err = oc.addPodVLANACLsOps(ops, localnetSwitch.Name, "pod2", 100)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = libovsdbops.TransactAndCheck(nbClient, ops)
+		Expect(err).NotTo(HaveOccurred())
+		pod1ACLs := podVLANACLs("pod1", "!(vlan.present && vlan.vid == 200)")
+		pod2ACLs := podVLANACLs("pod2", "!(vlan.present && vlan.vid == 100)")
+		localnetSwitch.ACLs = append(localnetSwitch.ACLs, pod1ACLs[0].UUID, pod1ACLs[1].UUID, pod2ACLs[0].UUID, pod2ACLs[1].UUID)
+		Eventually(nbClient).Should(libovsdbtest.HaveData(localnetSwitch, localnetPort, acls[0], acls[1],
+			pod1ACLs[0], pod1ACLs[1], pod2ACLs[0], pod2ACLs[1]))
+
+		// the ACLs go away with the pods, de-referenced ACLs are not
+		// garbage-collected by the test server
+		Expect(oc.deletePodVLANACLs("pod1")).To(Succeed())
+		localnetSwitch.ACLs = []string{acls[0].UUID, acls[1].UUID, pod2ACLs[0].UUID, pod2ACLs[1].UUID}
+		Eventually(nbClient).Should(libovsdbtest.HaveData(localnetSwitch, localnetPort, acls[0], acls[1],
+			pod1ACLs[0], pod1ACLs[1], pod2ACLs[0], pod2ACLs[1]))
+		Expect(oc.deleteStalePodVLANACLs(map[string]bool{})).To(Succeed())
+		localnetSwitch.ACLs = []string{acls[0].UUID, acls[1].UUID}
+		Eventually(nbClient).Should(libovsdbtest.HaveData(localnetSwitch, localnetPort, acls[0], acls[1],
+			pod1ACLs[0], pod1ACLs[1], pod2ACLs[0], pod2ACLs[1]))
+	})
+})
@@ -0,0 +1,174 @@
+package ovn
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+
+	utilnet "k8s.io/utils/net"
+)
+
+const (
+	// secondaryNetworkDHCPLeaseTime is the lease time, in seconds, of the
+	// DHCPv4 addresses when not configured
+	secondaryNetworkDHCPLeaseTime = 3600
+)
+
+// The DHCP options of a layer2 or localnet secondary network are shared by
+// all the logical switch ports of the network pods in the same subnet. OVN
+// answers the DHCP requests of the ports with the addresses they are
+// configured with, which are the pod IPs allocated from the network subnets.
+
+// ensureDHCPOptionsForPod sets the DHCP options of the network on the logical
+// switch port of the pod, so that it is served its IPs over DHCP
+func (bsnc *BaseSecondaryNetworkController) ensureDHCPOptionsForPod(lsp *nbdb.LogicalSwitchPort, podIPs []*net.IPNet) error {
+	var dhcpv4Options, dhcpv6Options *nbdb.DHCPOptions
+	for _, podIP := range podIPs {
+		subnet := bsnc.getSubnetOfIP(podIP.IP)
+		if subnet == nil {
+			return fmt.Errorf("failed to find the subnet of IP %s of port %s", podIP.IP, lsp.Name)
+		}
+		if utilnet.IsIPv6CIDR(subnet) {
+			dhcpv6Options = composeSecondaryNetworkDHCPv6Options(bsnc.controllerName, bsnc.NetInfo, subnet)
+		} else {
+			dhcpv4Options = composeSecondaryNetworkDHCPv4Options(bsnc.controllerName, bsnc.NetInfo, subnet)
+		}
+	}
+	if err := libovsdbops.CreateOrUpdateDhcpOptions(bsnc.nbClient, lsp, dhcpv4Options, dhcpv6Options); err != nil {
+		return fmt.Errorf("failed to set the DHCP options of port %s: %v", lsp.Name, err)
+	}
+	return nil
+}
+
+// getSubnetOfIP returns the network subnet the IP belongs to, if any
+func (bsnc *BaseSecondaryNetworkController) getSubnetOfIP(ip net.IP) *net.IPNet {
+	for _, subnet := range bsnc.Subnets() {
+		if subnet.CIDR.Contains(ip) {
+			return subnet.CIDR
+		}
+	}
+	return nil
+}
+
+func composeSecondaryNetworkDHCPv4Options(controllerName string, netInfo util.NetInfo, subnet *net.IPNet) *nbdb.DHCPOptions {
+	dhcp := netInfo.DHCP()
+	serverIP := util.GetDHCPServerIP(subnet)
+	leaseTime := dhcp.LeaseTime
+	if leaseTime == 0 {
+		leaseTime = secondaryNetworkDHCPLeaseTime
+	}
+	options := map[string]string{
+		"lease_time": strconv.Itoa(leaseTime),
+		"server_id":  serverIP.String(),
+		"server_mac": util.IPAddrToHWAddr(serverIP).String(),
+	}
+	if netInfo.MTU() > 0 {
+		options["mtu"] = strconv.Itoa(netInfo.MTU())
+	}
+	if dnsServers := filterDHCPDNSServers(dhcp, false); dnsServers != "" {
+		options["dns_server"] = dnsServers
+	}
+	if dhcp.Domain != "" {
+		options["domain_name"] = strconv.Quote(dhcp.Domain)
+	}
+
+	// the routes to the services go through the gateway router, if any, as
+	// they do for the pods configured by the CNI
+	routes := append([]ovncnitypes.DHCPRoute{}, dhcp.Routes...)
+	if netInfo.IsGatewayEnabled() {
+		for _, serviceSubnet := range config.Kubernetes.ServiceCIDRs {
+			if utilnet.IsIPv4CIDR(serviceSubnet) {
+				routes = append(routes, ovncnitypes.DHCPRoute{Dest: serviceSubnet.String(), NextHop: serverIP.String()})
+			}
+		}
+	}
+	staticRoutes := make([]string, 0, len(routes))
+	for _, route := range routes {
+		_, dest, err := net.ParseCIDR(route.Dest)
+		if err != nil {
+			// validated along with the network configuration
+			continue
+		}
+		if ones, _ := dest.Mask.Size(); ones == 0 {
+			options["router"] = route.NextHop
+		}
+		staticRoutes = append(staticRoutes, dest.String()+","+route.NextHop)
+	}
+	// clients ignore the router option when offered classless static routes,
+	// which then include the default route
+	if len(staticRoutes) > 0 {
+		options["classless_static_route"] = "{" + strings.Join(staticRoutes, ", ") + "}"
+	}
+
+	return composeSecondaryNetworkDHCPOptions(controllerName, netInfo.GetNetworkName(), subnet, options)
+}
+
+func composeSecondaryNetworkDHCPv6Options(controllerName string, netInfo util.NetInfo, subnet *net.IPNet) *nbdb.DHCPOptions {
+	dhcp := netInfo.DHCP()
+	options := map[string]string{
+		"server_id": util.IPAddrToHWAddr(util.GetDHCPServerIP(subnet)).String(),
+	}
+	if dhcp.DHCPv6Stateless {
+		options["dhcpv6_stateless"] = "true"
+	}
+	if dnsServers := filterDHCPDNSServers(dhcp, true); dnsServers != "" {
+		options["dns_server"] = dnsServers
+	}
+	if dhcp.Domain != "" {
+		options["domain_search"] = strconv.Quote(dhcp.Domain)
+	}
+	return composeSecondaryNetworkDHCPOptions(controllerName, netInfo.GetNetworkName(), subnet, options)
+}
+
+func composeSecondaryNetworkDHCPOptions(controllerName, netName string, subnet *net.IPNet, options map[string]string) *nbdb.DHCPOptions {
+	dbIDs := libovsdbops.NewDbObjectIDs(libovsdbops.SecondaryNetworkDHCPOptions, controllerName,
+		map[libovsdbops.ExternalIDKey]string{
+			libovsdbops.ObjectNameKey: netName,
+			libovsdbops.CIDRKey:       strings.ReplaceAll(subnet.String(), ":", "."),
+		})
+	return &nbdb.DHCPOptions{
+		Cidr:        subnet.String(),
+		Options:     options,
+		ExternalIDs: dbIDs.GetExternalIDs(),
+	}
+}
+
+// filterDHCPDNSServers returns the DNS servers of the IP family in the
+// format of the DHCP options, empty if there is none
+func filterDHCPDNSServers(dhcp *ovncnitypes.DHCPConfig, ipv6 bool) string {
+	var dnsServers []string
+	for _, dnsServer := range dhcp.DNSServers {
+		if utilnet.IsIPv6String(dnsServer) == ipv6 {
+			dnsServers = append(dnsServers, dnsServer)
+		}
+	}
+	if len(dnsServers) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(dnsServers, ", ") + "}"
+}
+
+// cleanupDHCPOptions returns the operations to delete the DHCP options of the
+// network, which are not removed along with its logical switch ports
+func cleanupDHCPOptions(nbClient libovsdbclient.Client, ops []libovsdb.Operation, controllerName, netName string) ([]libovsdb.Operation, error) {
+	predicateIDs := libovsdbops.NewDbObjectIDs(libovsdbops.SecondaryNetworkDHCPOptions, controllerName,
+		map[libovsdbops.ExternalIDKey]string{
+			libovsdbops.ObjectNameKey: netName,
+		})
+	ops, err := libovsdbops.DeleteDHCPOptionsWithPredicateOps(nbClient, ops,
+		libovsdbops.GetPredicate[*nbdb.DHCPOptions](predicateIDs, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ops for deleting the DHCP options of network %s: %v", netName, err)
+	}
+	return ops, nil
+}
@@ -0,0 +1,133 @@
+package ovn
+
+import (
+	"context"
+	"net"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Secondary network DHCP options", func() {
+	const (
+		netName        = "tenantblue"
+		controllerName = netName + "-network-controller"
+	)
+
+	newNetInfo := func(topology string, enableGateway bool, dhcp *ovncnitypes.DHCPConfig) util.NetInfo {
+		netInfo, err := util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:       cnitypes.NetConf{Name: netName},
+			Topology:      topology,
+			Subnets:       "192.168.1.0/24, fda6::/64",
+			MTU:           1400,
+			EnableGateway: enableGateway,
+			DHCP:          dhcp,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return netInfo
+	}
+
+	dhcpOptionsExternalIDs := func(cidr string) map[string]string {
+		return libovsdbops.NewDbObjectIDs(libovsdbops.SecondaryNetworkDHCPOptions, controllerName,
+			map[libovsdbops.ExternalIDKey]string{
+				libovsdbops.ObjectNameKey: netName,
+				libovsdbops.CIDRKey:       cidr,
+			}).GetExternalIDs()
+	}
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.OVNKubernetesFeature.EnableMultiNetwork = true
+		config.OVNKubernetesFeature.EnableInterconnect = true
+		config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+		config.Kubernetes.ServiceCIDRs = ovntest.MustParseIPNets("172.30.0.0/16", "fd02::/112")
+	})
+
+	It("serves the pod IPs over DHCP and cleans up the options along with the network", func() {
+		netInfo := newNetInfo(ovntypes.LocalnetTopology, false, &ovncnitypes.DHCPConfig{
+			LeaseTime:  600,
+			DNSServers: []string{"192.168.1.53", "fda6::53"},
+			Domain:     "example.com",
+			Routes:     []ovncnitypes.DHCPRoute{{Dest: "0.0.0.0/0", NextHop: "192.168.1.254"}},
+		})
+		lsp := &nbdb.LogicalSwitchPort{UUID: "lsp-UUID", Name: "tenantblue_ns1_pod1"}
+		ls := &nbdb.LogicalSwitch{UUID: "ls-UUID", Name: "tenantblue_ovn_localnet_switch", Ports: []string{lsp.UUID}}
+		nbClient, cleanup, err := libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{
+			NBData: []libovsdbtest.TestData{ls, lsp},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer cleanup.Cleanup()
+
+		bsnc := &BaseSecondaryNetworkController{
+			BaseNetworkController: BaseNetworkController{
+				CommonNetworkControllerInfo: CommonNetworkControllerInfo{nbClient: nbClient},
+				controllerName:              controllerName,
+				NetInfo:                     netInfo,
+			},
+		}
+		Expect(bsnc.ensureDHCPOptionsForPod(lsp.DeepCopy(), ovntest.MustParseIPNets("192.168.1.5/24", "fda6::5/64"))).To(Succeed())
+
+		dhcpv4Options := &nbdb.DHCPOptions{
+			UUID: "dhcpv4-UUID",
+			Cidr: "192.168.1.0/24",
+			Options: map[string]string{
+				"lease_time":             "600",
+				"server_id":              "192.168.1.1",
+				"server_mac":             util.IPAddrToHWAddr(net.ParseIP("192.168.1.1")).String(),
+				"mtu":                    "1400",
+				"dns_server":             "{192.168.1.53}",
+				"domain_name":            `"example.com"`,
+				"router":                 "192.168.1.254",
+				"classless_static_route": "{0.0.0.0/0,192.168.1.254}",
+			},
+			ExternalIDs: dhcpOptionsExternalIDs("192.168.1.0/24"),
+		}
+		dhcpv6Options := &nbdb.DHCPOptions{
+			UUID: "dhcpv6-UUID",
+			Cidr: "fda6::/64",
+			Options: map[string]string{
+				"server_id":     util.IPAddrToHWAddr(net.ParseIP("fda6::1")).String(),
+				"dns_server":    "{fda6::53}",
+				"domain_search": `"example.com"`,
+			},
+			ExternalIDs: dhcpOptionsExternalIDs("fda6../64"),
+		}
+		expectedLSP := lsp.DeepCopy()
+		expectedLSP.Dhcpv4Options = &dhcpv4Options.UUID
+		expectedLSP.Dhcpv6Options = &dhcpv6Options.UUID
+		Eventually(nbClient).Should(libovsdbtest.HaveData(ls, expectedLSP, dhcpv4Options, dhcpv6Options))
+
+		ops, err := cleanupDHCPOptions(nbClient, nil, controllerName, netName)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = libovsdbops.TransactAndCheck(nbClient, ops)
+		Expect(err).NotTo(HaveOccurred())
+		dhcpOptions := []nbdb.DHCPOptions{}
+		Expect(nbClient.List(context.Background(), &dhcpOptions)).To(Succeed())
+		Expect(dhcpOptions).To(BeEmpty())
+	})
+
+	It("offers the routes to the services through the gateway router", func() {
+		netInfo := newNetInfo(ovntypes.Layer2Topology, true, &ovncnitypes.DHCPConfig{})
+		dhcpOptions := composeSecondaryNetworkDHCPv4Options(controllerName, netInfo, ovntest.MustParseIPNet("192.168.1.0/24"))
+		Expect(dhcpOptions.Options).To(HaveKeyWithValue("classless_static_route", "{172.30.0.0/16,192.168.1.1}"))
+		Expect(dhcpOptions.Options).To(HaveKeyWithValue("lease_time", "3600"))
+		Expect(dhcpOptions.Options).NotTo(HaveKey("router"))
+	})
+
+	It("only offers the DNS configuration over stateless DHCPv6", func() {
+		netInfo := newNetInfo(ovntypes.Layer2Topology, false, &ovncnitypes.DHCPConfig{DHCPv6Stateless: true})
+		dhcpOptions := composeSecondaryNetworkDHCPv6Options(controllerName, netInfo, ovntest.MustParseIPNet("fda6::/64"))
+		Expect(dhcpOptions.Options).To(HaveKeyWithValue("dhcpv6_stateless", "true"))
+		Expect(dhcpOptions.Options).NotTo(HaveKey("dns_server"))
+	})
+})
@@ -0,0 +1,495 @@
+package ovn
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	libovsdbutil "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/util"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+)
+
+// The gateway router of a secondary network on a node is connected:
+//   - on layer3 networks, to the network cluster router through a network
+//     scoped join switch, addressed like the join switch of the default network
+//   - on layer2 networks, directly to the network switch with the first IP of
+//     each subnet, which is why only zones of a single node have one
+//   - to a network scoped external switch with a localnet port on the node
+//     external bridge, with a masquerade IP derived from the network ID
+//
+// The gateway router SNATs the network traffic to its masquerade IP and
+// routes it to the host, which forwards it to external networks and
+// Kubernetes services.
+
+// gatewayRouterName returns the name of the network gateway router of the node
+func (bsnc *BaseSecondaryNetworkController) gatewayRouterName(nodeName string) string {
+	return bsnc.GetNetworkScopedName(types.GWRouterPrefix + nodeName)
+}
+
+// gatewayExternalIDs returns the external IDs of the network gateway entities
+func (bsnc *BaseSecondaryNetworkController) gatewayExternalIDs() map[string]string {
+	return map[string]string{
+		types.NetworkExternalID:  bsnc.GetNetworkName(),
+		types.TopologyExternalID: bsnc.TopologyType(),
+	}
+}
+
+// syncGatewayRouter creates or updates the network gateway router of the node
+func (bsnc *BaseSecondaryNetworkController) syncGatewayRouter(node *kapi.Node, hostSubnets []*net.IPNet) error {
+	l3GatewayConfig, err := util.ParseNodeL3GatewayAnnotation(node)
+	if err != nil {
+		return err
+	}
+	if l3GatewayConfig.Mode == config.GatewayModeDisabled {
+		return bsnc.deleteGatewayRouter(node.Name)
+	}
+
+	networkID, err := util.ParseNetworkIDAnnotation(node, bsnc.GetNetworkName())
+	if err != nil {
+		return fmt.Errorf("failed to get the network ID of network %s on node %s: %w", bsnc.GetNetworkName(), node.Name, err)
+	}
+	externalIPs, err := util.GetSecondaryNetworkGatewayMasqueradeIPs(networkID)
+	if err != nil {
+		return err
+	}
+	hostIPs, err := util.GetSecondaryNetworkHostMasqueradeIPs()
+	if err != nil {
+		return err
+	}
+
+	gatewayRouter := bsnc.gatewayRouterName(node.Name)
+	logicalRouter := nbdb.LogicalRouter{
+		Name: gatewayRouter,
+		Options: map[string]string{
+			"always_learn_from_arp_request": "false",
+			"dynamic_neigh_routers":         "true",
+			"chassis":                       l3GatewayConfig.ChassisID,
+			"lb_force_snat_ip":              "router_ip",
+			"mac_binding_age_threshold":     types.GRMACBindingAgeThreshold,
+		},
+		ExternalIDs: bsnc.gatewayExternalIDs(),
+	}
+	err = libovsdbops.CreateOrUpdateLogicalRouter(bsnc.nbClient, &logicalRouter, &logicalRouter.Options,
+		&logicalRouter.ExternalIDs)
+	if err != nil {
+		return fmt.Errorf("failed to create logical router %+v: %v", logicalRouter, err)
+	}
+
+	switch bsnc.TopologyType() {
+	case types.Layer3Topology:
+		err = bsnc.syncLayer3GatewayRouterPorts(node, gatewayRouter, hostSubnets)
+	case types.Layer2Topology:
+		err = bsnc.syncLayer2GatewayRouterPorts(gatewayRouter)
+	default:
+		err = fmt.Errorf("gateway router not supported on %s topology", bsnc.TopologyType())
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := bsnc.syncGatewayRouterExternalPort(node.Name, gatewayRouter, l3GatewayConfig, externalIPs); err != nil {
+		return err
+	}
+
+	// the host masquerade IP is owned by the host of every node, so it is
+	// not resolved through ARP: bind it to the MAC of the external bridge
+	externalRouterPort := types.GWRouterToExtSwitchPrefix + gatewayRouter
+	smbs := make([]*nbdb.StaticMACBinding, 0, len(hostIPs))
+	for _, hostIP := range hostIPs {
+		smbs = append(smbs, &nbdb.StaticMACBinding{
+			LogicalPort:        externalRouterPort,
+			MAC:                l3GatewayConfig.MACAddress.String(),
+			IP:                 hostIP.IP.String(),
+			OverrideDynamicMAC: true,
+		})
+	}
+	if err := libovsdbops.CreateOrUpdateStaticMacBinding(bsnc.nbClient, smbs...); err != nil {
+		return fmt.Errorf("failed to create the host MAC bindings of gateway router %s: %v", gatewayRouter, err)
+	}
+
+	// default routes through the host
+	for _, hostIP := range hostIPs {
+		allIPs := "0.0.0.0/0"
+		if utilnet.IsIPv6CIDR(hostIP) {
+			allIPs = "::/0"
+		}
+		lrsr := nbdb.LogicalRouterStaticRoute{
+			IPPrefix:   allIPs,
+			Nexthop:    hostIP.IP.String(),
+			OutputPort: &externalRouterPort,
+		}
+		p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+			return item.OutputPort != nil && *item.OutputPort == *lrsr.OutputPort && item.IPPrefix == lrsr.IPPrefix &&
+				libovsdbops.PolicyEqualPredicate(lrsr.Policy, item.Policy)
+		}
+		err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(bsnc.nbClient, gatewayRouter, &lrsr,
+			p, &lrsr.Nexthop)
+		if err != nil {
+			return fmt.Errorf("error creating static route %+v in GR %s: %v", lrsr, gatewayRouter, err)
+		}
+	}
+
+	// SNAT the network traffic leaving through the host
+	nats := make([]*nbdb.NAT, 0, len(bsnc.Subnets()))
+	for _, subnet := range bsnc.Subnets() {
+		externalIP, err := util.MatchFirstIPNetFamily(utilnet.IsIPv6CIDR(subnet.CIDR), externalIPs)
+		if err != nil {
+			return fmt.Errorf("failed to create SNAT rules for gateway router %s: %v", gatewayRouter, err)
+		}
+		nats = append(nats, libovsdbops.BuildSNAT(&externalIP.IP, subnet.CIDR, "", nil))
+	}
+	if err := libovsdbops.CreateOrUpdateNATs(bsnc.nbClient, &logicalRouter, nats...); err != nil {
+		return fmt.Errorf("failed to update SNAT rules on gateway router %s: %v", gatewayRouter, err)
+	}
+
+	return nil
+}
+
+// syncLayer3GatewayRouterPorts connects the gateway router to the network
+// cluster router through the network join switch, and routes the node
+// subnets traffic through it
+func (bsnc *BaseSecondaryNetworkController) syncLayer3GatewayRouterPorts(node *kapi.Node, gatewayRouter string,
+	hostSubnets []*net.IPNet) error {
+	gwLRPIfAddrs, err := util.ParseNodeGatewayRouterLRPAddrs(node)
+	if err != nil {
+		return fmt.Errorf("failed to get the gateway router join addresses of node %s: %w", node.Name, err)
+	}
+	if len(hostSubnets) == 0 {
+		hostSubnets, err = util.ParseNodeHostSubnetAnnotation(node, bsnc.GetNetworkName())
+		if err != nil {
+			return fmt.Errorf("failed to get the subnets of network %s on node %s: %w", bsnc.GetNetworkName(), node.Name, err)
+		}
+	}
+	drLRPIfAddrs, err := getOVNClusterRouterPortToJoinSwitchIfAddrs()
+	if err != nil {
+		return err
+	}
+
+	clusterRouter := bsnc.GetNetworkScopedName(types.OVNClusterRouter)
+	joinSwitch := nbdb.LogicalSwitch{
+		Name:        bsnc.GetNetworkScopedName(types.OVNJoinSwitch),
+		ExternalIDs: bsnc.gatewayExternalIDs(),
+	}
+	if err := libovsdbops.CreateOrUpdateLogicalSwitch(bsnc.nbClient, &joinSwitch, &joinSwitch.ExternalIDs); err != nil {
+		return fmt.Errorf("failed to create logical switch %+v: %v", joinSwitch, err)
+	}
+
+	if err := bsnc.connectRouterToSwitch(clusterRouter, joinSwitch.Name, drLRPIfAddrs); err != nil {
+		return err
+	}
+	if err := bsnc.connectRouterToSwitch(gatewayRouter, joinSwitch.Name, gwLRPIfAddrs); err != nil {
+		return err
+	}
+
+	// cluster subnets are reached through the cluster router
+	for _, subnet := range bsnc.Subnets() {
+		drLRPIfAddr, err := util.MatchFirstIPNetFamily(utilnet.IsIPv6CIDR(subnet.CIDR), drLRPIfAddrs)
+		if err != nil {
+			return fmt.Errorf("failed to add a static route in GR %s with distributed router as the nexthop: %v",
+				gatewayRouter, err)
+		}
+		lrsr := nbdb.LogicalRouterStaticRoute{
+			IPPrefix: subnet.CIDR.String(),
+			Nexthop:  drLRPIfAddr.IP.String(),
+		}
+		p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+			return item.IPPrefix == lrsr.IPPrefix && libovsdbops.PolicyEqualPredicate(item.Policy, lrsr.Policy)
+		}
+		err = libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(bsnc.nbClient, gatewayRouter, &lrsr, p,
+			&lrsr.Nexthop)
+		if err != nil {
+			return fmt.Errorf("failed to add a static route %+v in GR %s with distributed router as the nexthop, err: %v",
+				lrsr, gatewayRouter, err)
+		}
+	}
+
+	// the node subnets traffic not destined to the cluster goes through the
+	// gateway router of the node, and so does the return traffic
+	for _, gwLRPIfAddr := range gwLRPIfAddrs {
+		lrsr := nbdb.LogicalRouterStaticRoute{
+			IPPrefix: gwLRPIfAddr.IP.String(),
+			Nexthop:  gwLRPIfAddr.IP.String(),
+		}
+		p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+			return item.IPPrefix == lrsr.IPPrefix && libovsdbops.PolicyEqualPredicate(lrsr.Policy, item.Policy)
+		}
+		err := libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(bsnc.nbClient, clusterRouter, &lrsr, p,
+			&lrsr.Nexthop)
+		if err != nil {
+			return fmt.Errorf("error creating static route %+v in %s: %v", lrsr, clusterRouter, err)
+		}
+	}
+	for _, hostSubnet := range hostSubnets {
+		gwLRPIfAddr, err := util.MatchFirstIPNetFamily(utilnet.IsIPv6CIDR(hostSubnet), gwLRPIfAddrs)
+		if err != nil {
+			return fmt.Errorf("failed to add source IP address based routes in distributed router %s: %v",
+				clusterRouter, err)
+		}
+		lrsr := nbdb.LogicalRouterStaticRoute{
+			Policy:   &nbdb.LogicalRouterStaticRoutePolicySrcIP,
+			IPPrefix: hostSubnet.String(),
+			Nexthop:  gwLRPIfAddr.IP.String(),
+		}
+		p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+			return item.IPPrefix == lrsr.IPPrefix && libovsdbops.PolicyEqualPredicate(lrsr.Policy, item.Policy)
+		}
+		err = libovsdbops.CreateOrReplaceLogicalRouterStaticRouteWithPredicate(bsnc.nbClient, clusterRouter, &lrsr, p,
+			&lrsr.Nexthop)
+		if err != nil {
+			return fmt.Errorf("error creating static route %+v in %s: %v", lrsr, clusterRouter, err)
+		}
+	}
+
+	return nil
+}
+
+// syncLayer2GatewayRouterPorts connects the gateway router to the network
+// switch with the gateway IPs of the network subnets
+func (bsnc *BaseSecondaryNetworkController) syncLayer2GatewayRouterPorts(gatewayRouter string) error {
+	gwIfAddrs := make([]*net.IPNet, 0, len(bsnc.Subnets()))
+	for _, subnet := range bsnc.Subnets() {
+		gwIfAddrs = append(gwIfAddrs, util.GetNodeGatewayIfAddr(subnet.CIDR))
+	}
+	return bsnc.connectRouterToSwitch(gatewayRouter, bsnc.GetNetworkScopedName(types.OVNLayer2Switch), gwIfAddrs)
+}
+
+// connectRouterToSwitch creates the router port with the provided addresses
+// and its peer switch port
+func (bsnc *BaseSecondaryNetworkController) connectRouterToSwitch(routerName, switchName string, ifAddrs []*net.IPNet) error {
+	routerPort := types.GWRouterToJoinSwitchPrefix + routerName
+	switchPort := types.JoinSwitchToGWRouterPrefix + routerName
+
+	networks := make([]string, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		networks = append(networks, ifAddr.String())
+	}
+	logicalRouterPort := nbdb.LogicalRouterPort{
+		Name:     routerPort,
+		MAC:      util.IPAddrToHWAddr(ifAddrs[0].IP).String(),
+		Networks: networks,
+	}
+	logicalRouter := nbdb.LogicalRouter{Name: routerName}
+	err := libovsdbops.CreateOrUpdateLogicalRouterPort(bsnc.nbClient, &logicalRouter, &logicalRouterPort, nil,
+		&logicalRouterPort.MAC, &logicalRouterPort.Networks)
+	if err != nil {
+		return fmt.Errorf("failed to create port %+v on router %s: %v", logicalRouterPort, routerName, err)
+	}
+
+	logicalSwitchPort := nbdb.LogicalSwitchPort{
+		Name:      switchPort,
+		Type:      "router",
+		Addresses: []string{"router"},
+		Options: map[string]string{
+			"router-port": routerPort,
+		},
+	}
+	sw := nbdb.LogicalSwitch{Name: switchName}
+	err = libovsdbops.CreateOrUpdateLogicalSwitchPortsOnSwitch(bsnc.nbClient, &sw, &logicalSwitchPort)
+	if err != nil {
+		return fmt.Errorf("failed to create port %v on logical switch %q: %v", switchPort, switchName, err)
+	}
+	return nil
+}
+
+// syncGatewayRouterExternalPort connects the gateway router to the network
+// external switch of the node, bridged to the node external bridge
+func (bsnc *BaseSecondaryNetworkController) syncGatewayRouterExternalPort(nodeName, gatewayRouter string,
+	l3GatewayConfig *util.L3GatewayConfig, externalIPs []*net.IPNet) error {
+	externalRouterPort := types.GWRouterToExtSwitchPrefix + gatewayRouter
+	macAddress := util.IPAddrToHWAddr(externalIPs[0].IP).String()
+
+	networks := make([]string, 0, len(externalIPs))
+	for _, externalIP := range externalIPs {
+		networks = append(networks, externalIP.String())
+	}
+	externalLogicalRouterPort := nbdb.LogicalRouterPort{
+		Name:     externalRouterPort,
+		MAC:      macAddress,
+		Networks: networks,
+	}
+	logicalRouter := nbdb.LogicalRouter{Name: gatewayRouter}
+	err := libovsdbops.CreateOrUpdateLogicalRouterPort(bsnc.nbClient, &logicalRouter, &externalLogicalRouterPort, nil,
+		&externalLogicalRouterPort.MAC, &externalLogicalRouterPort.Networks)
+	if err != nil {
+		return fmt.Errorf("failed to add logical router port %+v to router %s: %v", externalLogicalRouterPort, gatewayRouter, err)
+	}
+
+	externalLogicalSwitchPort := nbdb.LogicalSwitchPort{
+		Name:      bsnc.GetNetworkScopedName(l3GatewayConfig.InterfaceID),
+		Addresses: []string{"unknown"},
+		Type:      "localnet",
+		Options: map[string]string{
+			"network_name": types.PhysicalNetworkName,
+		},
+	}
+	if l3GatewayConfig.VLANID != nil && int(*l3GatewayConfig.VLANID) != 0 {
+		intVlanID := int(*l3GatewayConfig.VLANID)
+		externalLogicalSwitchPort.TagRequest = &intVlanID
+	}
+	externalLogicalSwitchPortToRouter := nbdb.LogicalSwitchPort{
+		Name:      types.EXTSwitchToGWRouterPrefix + gatewayRouter,
+		Type:      "router",
+		Addresses: []string{macAddress},
+		Options: map[string]string{
+			"router-port": externalRouterPort,
+		},
+	}
+	sw := nbdb.LogicalSwitch{
+		Name:        bsnc.GetNetworkScopedName(externalSwitchName("", nodeName)),
+		ExternalIDs: bsnc.gatewayExternalIDs(),
+	}
+	err = libovsdbops.CreateOrUpdateLogicalSwitchPortsAndSwitch(bsnc.nbClient, &sw, &externalLogicalSwitchPort,
+		&externalLogicalSwitchPortToRouter)
+	if err != nil {
+		return fmt.Errorf("failed to create logical switch ports %+v, %+v, and switch %s: %v",
+			externalLogicalSwitchPort, externalLogicalSwitchPortToRouter, sw.Name, err)
+	}
+	return nil
+}
+
+// deleteGatewayRouter removes the network gateway router of the node along
+// with its external switch and the routes pointing to it
+func (bsnc *BaseSecondaryNetworkController) deleteGatewayRouter(nodeName string) error {
+	gatewayRouter := bsnc.gatewayRouterName(nodeName)
+	routerPort := types.GWRouterToJoinSwitchPrefix + gatewayRouter
+
+	if bsnc.TopologyType() == types.Layer3Topology {
+		gwIPAddrs, err := libovsdbutil.GetLRPAddrs(bsnc.nbClient, routerPort)
+		if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+			return err
+		}
+		nextHops := make([]string, 0, len(gwIPAddrs))
+		for _, gwIPAddr := range gwIPAddrs {
+			nextHops = append(nextHops, gwIPAddr.IP.String())
+		}
+		clusterRouter := bsnc.GetNetworkScopedName(types.OVNClusterRouter)
+		p := func(item *nbdb.LogicalRouterStaticRoute) bool {
+			for _, nextHop := range nextHops {
+				if item.Nexthop == nextHop {
+					return true
+				}
+			}
+			return false
+		}
+		if len(nextHops) > 0 {
+			err = libovsdbops.DeleteLogicalRouterStaticRoutesWithPredicate(bsnc.nbClient, clusterRouter, p)
+			if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+				return fmt.Errorf("failed to delete static routes to gateway router %s: %w", gatewayRouter, err)
+			}
+		}
+	}
+
+	var switchName string
+	switch bsnc.TopologyType() {
+	case types.Layer3Topology:
+		switchName = bsnc.GetNetworkScopedName(types.OVNJoinSwitch)
+	case types.Layer2Topology:
+		switchName = bsnc.GetNetworkScopedName(types.OVNLayer2Switch)
+	}
+	if switchName != "" {
+		sw := nbdb.LogicalSwitch{Name: switchName}
+		lsp := nbdb.LogicalSwitchPort{Name: types.JoinSwitchToGWRouterPrefix + gatewayRouter}
+		err := libovsdbops.DeleteLogicalSwitchPorts(bsnc.nbClient, &sw, &lsp)
+		if err != nil && !errors.Is(err, libovsdbclient.ErrNotFound) {
+			return fmt.Errorf("failed to delete logical switch port %s from switch %s: %w", lsp.Name, switchName, err)
+		}
+	}
+
+	externalRouterPort := types.GWRouterToExtSwitchPrefix + gatewayRouter
+	ops, err := libovsdbops.DeleteStaticMacBindingsWithPredicateOps(bsnc.nbClient, nil,
+		func(item *nbdb.StaticMACBinding) bool {
+			return item.LogicalPort == externalRouterPort
+		})
+	if err != nil {
+		return fmt.Errorf("failed to get ops for deleting the host MAC bindings of gateway router %s: %v", gatewayRouter, err)
+	}
+	ops, err = libovsdbops.DeleteLogicalRouterOps(bsnc.nbClient, ops, &nbdb.LogicalRouter{Name: gatewayRouter})
+	if err != nil {
+		return fmt.Errorf("failed to get ops for deleting gateway router %s: %v", gatewayRouter, err)
+	}
+	externalSwitch := bsnc.GetNetworkScopedName(externalSwitchName("", nodeName))
+	ops, err = libovsdbops.DeleteLogicalSwitchOps(bsnc.nbClient, ops, externalSwitch)
+	if err != nil {
+		return fmt.Errorf("failed to get ops for deleting external switch %s: %v", externalSwitch, err)
+	}
+	if _, err = libovsdbops.TransactAndCheck(bsnc.nbClient, ops); err != nil {
+		return fmt.Errorf("failed to delete gateway router %s: %v", gatewayRouter, err)
+	}
+
+	klog.V(5).Infof("Deleted gateway router %s of network %s", gatewayRouter, bsnc.GetNetworkName())
+	return nil
+}
+
+// deleteStaleGatewayRouters removes the network gateway routers of the nodes
+// that are not in the provided set of local zone nodes
+func (bsnc *BaseSecondaryNetworkController) deleteStaleGatewayRouters(localNodes sets.Set[string]) error {
+	prefix := bsnc.gatewayRouterName("")
+	gatewayRouters, err := libovsdbops.FindLogicalRoutersWithPredicate(bsnc.nbClient,
+		func(item *nbdb.LogicalRouter) bool {
+			return item.ExternalIDs[types.NetworkExternalID] == bsnc.GetNetworkName() &&
+				strings.HasPrefix(item.Name, prefix)
+		})
+	if err != nil {
+		return fmt.Errorf("failed to find the gateway routers of network %s: %v", bsnc.GetNetworkName(), err)
+	}
+	for _, gatewayRouter := range gatewayRouters {
+		nodeName := strings.TrimPrefix(gatewayRouter.Name, prefix)
+		if localNodes.Has(nodeName) {
+			continue
+		}
+		if err := bsnc.deleteGatewayRouter(nodeName); err != nil {
+			return fmt.Errorf("failed to delete stale gateway router %s: %w", gatewayRouter.Name, err)
+		}
+	}
+	return nil
+}
+
+// cleanupGatewayRouters returns the operations to delete the static MAC
+// bindings of the gateway routers of the given network, which are not
+// removed along with the routers
+func cleanupGatewayRouters(nbClient libovsdbclient.Client, ops []libovsdb.Operation, netName string) ([]libovsdb.Operation, error) {
+	prefix := types.GWRouterToExtSwitchPrefix + util.GetSecondaryNetworkPrefix(netName) + types.GWRouterPrefix
+	ops, err := libovsdbops.DeleteStaticMacBindingsWithPredicateOps(nbClient, ops,
+		func(item *nbdb.StaticMACBinding) bool {
+			return strings.HasPrefix(item.LogicalPort, prefix)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ops for deleting the gateway router MAC bindings of network %s: %v", netName, err)
+	}
+	return ops, nil
+}
+
+// secondaryGatewayChanged returns true if the node annotations the network
+// gateway router depends on changed
+func secondaryGatewayChanged(netName string, oldNode, newNode *kapi.Node) bool {
+	if gatewayChanged(oldNode, newNode) || nodeChassisChanged(oldNode, newNode) {
+		return true
+	}
+	oldGRLRPAddrs, _ := util.ParseNodeGatewayRouterLRPAddrs(oldNode)
+	newGRLRPAddrs, _ := util.ParseNodeGatewayRouterLRPAddrs(newNode)
+	if !reflect.DeepEqual(oldGRLRPAddrs, newGRLRPAddrs) {
+		return true
+	}
+	oldNetworkID, _ := util.ParseNetworkIDAnnotation(oldNode, netName)
+	newNetworkID, _ := util.ParseNetworkIDAnnotation(newNode, netName)
+	if oldNetworkID != newNetworkID {
+		return true
+	}
+	oldSubnets, _ := util.ParseNodeHostSubnetAnnotation(oldNode, netName)
+	newSubnets, _ := util.ParseNodeHostSubnetAnnotation(newNode, netName)
+	return !reflect.DeepEqual(oldSubnets, newSubnets)
+}
@@ -0,0 +1,216 @@
+package ovn
+
+import (
+	"context"
+	"sync"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	libovsdbops "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb/ops"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+	libovsdbtest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing/libovsdb"
+	ovntypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+var _ = Describe("Secondary network gateway routers", func() {
+	const netName = "tenantblue"
+
+	var (
+		netInfo  util.NetInfo
+		nbClient libovsdbclient.Client
+		cleanup  *libovsdbtest.Context
+		oc       *BaseSecondaryLayer2NetworkController
+	)
+
+	newNode := func(name, networkIDs string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					"k8s.ovn.org/node-chassis-id": "SYSTEM-ID-" + name,
+					"k8s.ovn.org/l3-gateway-config": `{"default":{"mode":"shared","interface-id":"breth0_` + name + `",` +
+						`"mac-address":"11:22:33:44:55:66","ip-address":"172.18.0.2/16","next-hop":"172.18.0.1"}}`,
+					"k8s.ovn.org/network-ids": networkIDs,
+				},
+			},
+		}
+	}
+
+	listAll := func(result interface{}) {
+		Expect(nbClient.List(context.Background(), result)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		Expect(config.PrepareTestConfig()).To(Succeed())
+		config.IPv4Mode = true
+		config.OVNKubernetesFeature.EnableMultiNetwork = true
+		config.OVNKubernetesFeature.EnableInterconnect = true
+		config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+
+		var err error
+		netInfo, err = util.NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:       cnitypes.NetConf{Name: netName},
+			Topology:      ovntypes.Layer2Topology,
+			Subnets:       "192.168.1.0/24",
+			EnableGateway: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		nbClient, cleanup, err = libovsdbtest.NewNBTestHarness(libovsdbtest.TestSetup{
+			NBData: []libovsdbtest.TestData{
+				&nbdb.LogicalSwitch{
+					UUID: "ls-UUID",
+					Name: netInfo.GetNetworkScopedName(ovntypes.OVNLayer2Switch),
+				},
+			},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		oc = &BaseSecondaryLayer2NetworkController{
+			BaseSecondaryNetworkController: BaseSecondaryNetworkController{
+				BaseNetworkController: BaseNetworkController{
+					CommonNetworkControllerInfo: CommonNetworkControllerInfo{nbClient: nbClient, zone: ovntypes.OvnDefaultZone},
+					NetInfo:                     netInfo,
+					localZoneNodes:              &sync.Map{},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		cleanup.Cleanup()
+	})
+
+	It("creates the gateway router of a node and deletes it", func() {
+		node := newNode("node1", `{"default":"0","tenantblue":"2"}`)
+		gatewayRouter := netInfo.GetNetworkScopedName(ovntypes.GWRouterPrefix + node.Name)
+		externalSwitch := netInfo.GetNetworkScopedName(externalSwitchName("", node.Name))
+
+		// syncing twice leaves a single copy of every entity
+		Expect(oc.syncGatewayRouter(node, nil)).To(Succeed())
+		Expect(oc.syncGatewayRouter(node, nil)).To(Succeed())
+
+		router, err := libovsdbops.GetLogicalRouter(nbClient, &nbdb.LogicalRouter{Name: gatewayRouter})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(router.Options).To(HaveKeyWithValue("chassis", "SYSTEM-ID-node1"))
+		Expect(router.ExternalIDs).To(HaveKeyWithValue(ovntypes.NetworkExternalID, netName))
+		Expect(router.Ports).To(HaveLen(2))
+
+		// the network traffic is SNATed to the masquerade IP of the network
+		nats := []nbdb.NAT{}
+		listAll(&nats)
+		Expect(nats).To(HaveLen(1))
+		Expect(nats[0].Type).To(Equal(nbdb.NATTypeSNAT))
+		Expect(nats[0].ExternalIP).To(Equal("169.254.0.3"))
+		Expect(nats[0].LogicalIP).To(Equal("192.168.1.0/24"))
+
+		// and routed to the host masquerade IP, bound to the bridge MAC
+		routes := []nbdb.LogicalRouterStaticRoute{}
+		listAll(&routes)
+		Expect(routes).To(HaveLen(1))
+		Expect(routes[0].IPPrefix).To(Equal("0.0.0.0/0"))
+		Expect(routes[0].Nexthop).To(Equal("169.254.0.1"))
+		smbs := []nbdb.StaticMACBinding{}
+		listAll(&smbs)
+		Expect(smbs).To(HaveLen(1))
+		Expect(smbs[0].IP).To(Equal("169.254.0.1"))
+		Expect(smbs[0].MAC).To(Equal("11:22:33:44:55:66"))
+		Expect(smbs[0].LogicalPort).To(Equal(ovntypes.GWRouterToExtSwitchPrefix + gatewayRouter))
+
+		// the router joins the network switch with the subnet gateway IP
+		routerPort, err := libovsdbops.GetLogicalRouterPort(nbClient,
+			&nbdb.LogicalRouterPort{Name: ovntypes.GWRouterToJoinSwitchPrefix + gatewayRouter})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routerPort.Networks).To(ConsistOf("192.168.1.1/24"))
+		ls, err := libovsdbops.GetLogicalSwitch(nbClient,
+			&nbdb.LogicalSwitch{Name: netInfo.GetNetworkScopedName(ovntypes.OVNLayer2Switch)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ls.Ports).To(HaveLen(1))
+
+		// and the external bridge through a localnet port
+		sw, err := libovsdbops.GetLogicalSwitch(nbClient, &nbdb.LogicalSwitch{Name: externalSwitch})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sw.Ports).To(HaveLen(2))
+		localnetPort, err := libovsdbops.GetLogicalSwitchPort(nbClient,
+			&nbdb.LogicalSwitchPort{Name: netInfo.GetNetworkScopedName("breth0_node1")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(localnetPort.Type).To(Equal("localnet"))
+
+		Expect(oc.deleteGatewayRouter(node.Name)).To(Succeed())
+
+		routers := []nbdb.LogicalRouter{}
+		listAll(&routers)
+		Expect(routers).To(BeEmpty())
+		switches := []nbdb.LogicalSwitch{}
+		listAll(&switches)
+		Expect(switches).To(HaveLen(1))
+		Expect(switches[0].Ports).To(BeEmpty())
+		smbs = []nbdb.StaticMACBinding{}
+		listAll(&smbs)
+		Expect(smbs).To(BeEmpty())
+	})
+
+	It("deletes the gateway router of a node with the gateway disabled", func() {
+		node := newNode("node1", `{"default":"0","tenantblue":"2"}`)
+		Expect(oc.syncGatewayRouter(node, nil)).To(Succeed())
+
+		node.Annotations["k8s.ovn.org/l3-gateway-config"] = `{"default":{"mode":""}}`
+		Expect(oc.syncGatewayRouter(node, nil)).To(Succeed())
+
+		routers := []nbdb.LogicalRouter{}
+		listAll(&routers)
+		Expect(routers).To(BeEmpty())
+	})
+
+	It("fails to create the gateway router of a node without a network ID", func() {
+		node := newNode("node1", `{"default":"0"}`)
+		Expect(oc.syncGatewayRouter(node, nil)).NotTo(Succeed())
+
+		routers := []nbdb.LogicalRouter{}
+		listAll(&routers)
+		Expect(routers).To(BeEmpty())
+	})
+
+	It("deletes the gateway routers of the nodes no longer in the zone", func() {
+		node1 := newNode("node1", `{"default":"0","tenantblue":"2"}`)
+		node2 := newNode("node2", `{"default":"0","tenantblue":"2"}`)
+		Expect(oc.syncGatewayRouter(node1, nil)).To(Succeed())
+		Expect(oc.syncGatewayRouter(node2, nil)).To(Succeed())
+
+		Expect(oc.deleteStaleGatewayRouters(sets.New[string](node1.Name))).To(Succeed())
+
+		routers := []nbdb.LogicalRouter{}
+		listAll(&routers)
+		Expect(routers).To(HaveLen(1))
+		Expect(routers[0].Name).To(Equal(netInfo.GetNetworkScopedName(ovntypes.GWRouterPrefix + node1.Name)))
+		smbs := []nbdb.StaticMACBinding{}
+		listAll(&smbs)
+		Expect(smbs).To(HaveLen(1))
+	})
+
+	It("detects the node changes the gateway router depends on", func() {
+		oldNode := newNode("node1", `{"default":"0","tenantblue":"2"}`)
+
+		newNode1 := oldNode.DeepCopy()
+		newNode1.Annotations["unrelated"] = "value"
+		Expect(secondaryGatewayChanged(netName, oldNode, newNode1)).To(BeFalse())
+
+		newNode2 := oldNode.DeepCopy()
+		newNode2.Annotations["k8s.ovn.org/network-ids"] = `{"default":"0","tenantblue":"3"}`
+		Expect(secondaryGatewayChanged(netName, oldNode, newNode2)).To(BeTrue())
+
+		newNode3 := oldNode.DeepCopy()
+		newNode3.Annotations["k8s.ovn.org/node-chassis-id"] = "OTHER-SYSTEM-ID"
+		Expect(secondaryGatewayChanged(netName, oldNode, newNode3)).To(BeTrue())
+	})
+})
@@ -30,6 +30,7 @@ var commonNodeAnnotationChecks = map[string]checkNodeAnnot{
 	util.OvnNodeManagementPortMacAddress: nil,
 	util.OvnNodeIfAddr:                   nil,
 	util.OvnNodeGatewayMtuSupport:        nil,
+	util.OvnNodeGatewayModeStatus:        nil,
 	util.OvnNodeManagementPort:           nil,
 	util.OvnNodeChassisID: func(v annotationChange, nodeName string) error {
 		if v.action == removed {
@@ -32,6 +32,7 @@ import (
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/libovsdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/sbdb"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
 )
 
 type TestSetup struct {
@@ -40,8 +41,9 @@ type TestSetup struct {
 	// addition of invalid data (like duplicate indexes).
 	IgnoreConstraints bool
 
-	NBData []TestData
-	SBData []TestData
+	NBData  []TestData
+	SBData  []TestData
+	OVSData []TestData
 }
 
 type TestData interface{}
@@ -125,6 +127,21 @@ func NewSBTestHarness(setup TestSetup, testCtx *Context) (libovsdbclient.Client,
 	return client, testCtx, err
 }
 
+// NewOVSTestHarness runs an Open_vSwitch server and returns the corresponding client
+func NewOVSTestHarness(setup TestSetup, testCtx *Context) (libovsdbclient.Client, *Context, error) {
+	if testCtx == nil {
+		testCtx = newContext()
+	}
+
+	client, server, err := newOVSDBTestHarness(setup.OVSData, setup.IgnoreConstraints, newOVSServer, newOVSClient, testCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	testCtx.VSServer = server
+
+	return client, testCtx, err
+}
+
 func newOVSDBTestHarness(serverData []TestData, ignoreConstraints bool, newServer serverBuilderFn, newClient clientBuilderFn, testCtx *Context) (libovsdbclient.Client, *TestOvsdbServer, error) {
 	cfg := config.OvnAuthConfig{
 		Scheme:  config.OvnDBSchemeUnix,
@@ -182,6 +199,16 @@ func newSBClient(cfg config.OvnAuthConfig, testCtx *Context) (libovsdbclient.Cli
 	return sbClient, err
 }
 
+func newOVSClient(cfg config.OvnAuthConfig, testCtx *Context) (libovsdbclient.Client, error) {
+	stopChan := make(chan struct{})
+	ovsClient, err := libovsdb.NewOVSClientWithConfig(cfg, prometheus.NewRegistry(), stopChan)
+	if err != nil {
+		return nil, err
+	}
+	clientWaitOnCleanup(testCtx, ovsClient, stopChan)
+	return ovsClient, err
+}
+
 func newSBServer(cfg config.OvnAuthConfig, data []TestData, ignoreConstraints bool) (*TestOvsdbServer, error) {
 	dbModel, err := sbdb.FullDatabaseModel()
 	if err != nil {
@@ -200,6 +227,15 @@ func newNBServer(cfg config.OvnAuthConfig, data []TestData, ignoreConstraints bo
 	return newOVSDBServer(cfg, dbModel, schema, data, ignoreConstraints)
 }
 
+func newOVSServer(cfg config.OvnAuthConfig, data []TestData, ignoreConstraints bool) (*TestOvsdbServer, error) {
+	dbModel, err := vswitchd.FullDatabaseModel()
+	if err != nil {
+		return nil, err
+	}
+	schema := vswitchd.Schema()
+	return newOVSDBServer(cfg, dbModel, schema, data, ignoreConstraints)
+}
+
 func testDataToOperations(dbMod model.DatabaseModel, data []TestData) ([]ovsdb.Operation, error) {
 	m := mapper.NewMapper(dbMod.Schema)
 	newData := copystructure.Must(copystructure.Copy(data)).([]TestData)
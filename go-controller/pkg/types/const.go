@@ -11,11 +11,17 @@ const (
 
 	// K8sMgmtIntfName name to be used as an OVS internal port on the node
 	K8sMgmtIntfName = "ovn-k8s-mp0"
+	// ManagementPortDegradedFile exists, with the reason, while ovnkube-node
+	// reports the management port as degraded
+	ManagementPortDegradedFile = "/var/run/ovn-kubernetes/mgmt-port-degraded"
 
 	// PhysicalNetworkName is the name that maps to an OVS bridge that provides
 	// access to physical/external network
 	PhysicalNetworkName     = "physnet"
 	PhysicalNetworkExGwName = "exgwphysnet"
+	// PhysicalNetworkUplinkPrefix prefixes the names of the physical networks
+	// of the additional gateway uplinks
+	PhysicalNetworkUplinkPrefix = "uplinkphysnet-"
 
 	// LocalNetworkName is the name that maps to an OVS bridge that provides
 	// access to local service
@@ -44,6 +50,7 @@ const (
 	EXTSwitchToGWRouterPrefix    = "etor-"
 	GWRouterToExtSwitchPrefix    = "rtoe-"
 	EgressGWSwitchPrefix         = "exgw-"
+	GatewayUplinkPrefix          = "uplink-"
 
 	NodeLocalSwitch = "node_local_switch"
 
@@ -60,6 +67,8 @@ const (
 
 	// ACL Priorities
 
+	// Default deny of the VLANs not allowed on a localnet VLAN trunk acl rule priority
+	VLANTrunkDenyPriority = 1014
 	// Default routed multicast allow acl rule priority
 	DefaultRoutedMcastAllowPriority = 1013
 	// Default multicast allow acl rule priority
@@ -96,6 +105,9 @@ const (
 	EgressIPReroutePriority               = 100
 	EgressLiveMigrationReroutePiority     = 10
 
+	// priority of logical router policies on the gateway routers
+	GatewayUplinkReroutePriority = 100
+
 	V6NodeLocalNATSubnet           = "fd99::/64"
 	V6NodeLocalNATSubnetPrefix     = 64
 	V6NodeLocalNATSubnetNextHop    = "fd99::1"
@@ -169,6 +181,14 @@ const (
 	LoadBalancerKindExternalID = OvnK8sPrefix + "/" + "kind"
 	// key for load_balancer service external-id
 	LoadBalancerOwnerExternalID = OvnK8sPrefix + "/" + "owner"
+	// key for load_balancer_health_check load balancer name external-id
+	LoadBalancerNameExternalID = OvnK8sPrefix + "/" + "lb-name"
+	// key for logical_switch load balancer health check source addresses external-id
+	LBHealthCheckSourceExternalID = OvnK8sPrefix + "/" + "lb-health-check-source"
+	// label holding the name of the network of an IPAMClaim
+	IPAMClaimNetworkLabel = OvnK8sPrefix + "/" + "network"
+	// label holding the name of the NetworkDefinition a NetworkAttachmentDefinition is rendered from
+	NetworkDefinitionLabel = OvnK8sPrefix + "/" + "network-definition"
 
 	// different secondary network topology type defined in CNI netconf
 	Layer3Topology   = "layer3"
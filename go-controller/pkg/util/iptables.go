@@ -9,6 +9,8 @@ import (
 	"sync"
 
 	"github.com/coreos/go-iptables/iptables"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 )
 
 // IPTablesHelper is an interface that wraps go-iptables to allow
@@ -44,9 +46,19 @@ func SetIPTablesHelper(proto iptables.Protocol, ipt IPTablesHelper) {
 }
 
 // GetIPTablesHelper returns an IPTablesHelper. If SetIPTablesHelper has not yet been
-// called, it will create a new IPTablesHelper wrapping "live" go-iptables
+// called, it will create a new IPTablesHelper wrapping "live" go-iptables, or
+// programming nftables if it is the configured firewall backend
 func GetIPTablesHelper(proto iptables.Protocol) (IPTablesHelper, error) {
 	if helpers[proto] == nil {
+		if config.OvnKubeNode.FirewallBackend == config.FirewallBackendNFTables {
+			ipt, err := NewNFTablesHelper(proto)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create nftables IPTablesHelper for proto %v: %v",
+					proto, err)
+			}
+			SetIPTablesHelper(proto, ipt)
+			return ipt, nil
+		}
 		ipt, err := iptables.NewWithProtocol(proto)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create IPTablesHelper for proto %v: %v",
@@ -138,10 +150,11 @@ func (f *FakeIPTables) List(tableName, chainName string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	for i := range chain {
-		chain[i] = fmt.Sprintf("-A %s %s", chainName, chain[i])
+	rules := make([]string, 0, len(chain))
+	for _, rule := range chain {
+		rules = append(rules, fmt.Sprintf("-A %s %s", chainName, rule))
 	}
-	return chain, nil
+	return rules, nil
 }
 
 // ListChains returns the names of all chains in the table
@@ -46,6 +46,8 @@ import (
 	egressipclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressip/v1/apis/clientset/versioned"
 	egressqosclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressqos/v1/apis/clientset/versioned"
 	egressserviceclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressservice/v1/apis/clientset/versioned"
+	ipamclaimclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/ipamclaim/v1/apis/clientset/versioned"
+	networkdefinitionclientset "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/networkdefinition/v1/apis/clientset/versioned"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	anpclientset "sigs.k8s.io/network-policy-api/pkg/client/clientset/versioned"
 )
@@ -62,6 +64,8 @@ type OVNClientset struct {
 	MultiNetworkPolicyClient multinetworkpolicyclientset.Interface
 	EgressServiceClient      egressserviceclientset.Interface
 	AdminPolicyRouteClient   adminpolicybasedrouteclientset.Interface
+	IPAMClaimClient          ipamclaimclientset.Interface
+	NetworkDefinitionClient  networkdefinitionclientset.Interface
 }
 
 // OVNMasterClientset
@@ -97,13 +101,15 @@ type OVNNodeClientset struct {
 }
 
 type OVNClusterManagerClientset struct {
-	KubeClient             kubernetes.Interface
-	EgressIPClient         egressipclientset.Interface
-	CloudNetworkClient     ocpcloudnetworkclientset.Interface
-	NetworkAttchDefClient  networkattchmentdefclientset.Interface
-	EgressServiceClient    egressserviceclientset.Interface
-	AdminPolicyRouteClient adminpolicybasedrouteclientset.Interface
-	EgressFirewallClient   egressfirewallclientset.Interface
+	KubeClient              kubernetes.Interface
+	EgressIPClient          egressipclientset.Interface
+	CloudNetworkClient      ocpcloudnetworkclientset.Interface
+	NetworkAttchDefClient   networkattchmentdefclientset.Interface
+	EgressServiceClient     egressserviceclientset.Interface
+	AdminPolicyRouteClient  adminpolicybasedrouteclientset.Interface
+	EgressFirewallClient    egressfirewallclientset.Interface
+	IPAMClaimClient         ipamclaimclientset.Interface
+	NetworkDefinitionClient networkdefinitionclientset.Interface
 }
 
 const (
@@ -158,13 +164,15 @@ func (cs *OVNClientset) GetOVNKubeControllerClientset() *OVNKubeControllerClient
 
 func (cs *OVNClientset) GetClusterManagerClientset() *OVNClusterManagerClientset {
 	return &OVNClusterManagerClientset{
-		KubeClient:             cs.KubeClient,
-		EgressIPClient:         cs.EgressIPClient,
-		CloudNetworkClient:     cs.CloudNetworkClient,
-		NetworkAttchDefClient:  cs.NetworkAttchDefClient,
-		EgressServiceClient:    cs.EgressServiceClient,
-		AdminPolicyRouteClient: cs.AdminPolicyRouteClient,
-		EgressFirewallClient:   cs.EgressFirewallClient,
+		KubeClient:              cs.KubeClient,
+		EgressIPClient:          cs.EgressIPClient,
+		CloudNetworkClient:      cs.CloudNetworkClient,
+		NetworkAttchDefClient:   cs.NetworkAttchDefClient,
+		EgressServiceClient:     cs.EgressServiceClient,
+		AdminPolicyRouteClient:  cs.AdminPolicyRouteClient,
+		EgressFirewallClient:    cs.EgressFirewallClient,
+		IPAMClaimClient:         cs.IPAMClaimClient,
+		NetworkDefinitionClient: cs.NetworkDefinitionClient,
 	}
 }
 
@@ -396,6 +404,16 @@ func NewOVNClientset(conf *config.KubernetesConfig) (*OVNClientset, error) {
 		return nil, err
 	}
 
+	ipamClaimClientset, err := ipamclaimclientset.NewForConfig(kconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	networkDefinitionClientset, err := networkdefinitionclientset.NewForConfig(kconfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &OVNClientset{
 		KubeClient:               kclientset,
 		ANPClient:                anpClientset,
@@ -407,6 +425,8 @@ func NewOVNClientset(conf *config.KubernetesConfig) (*OVNClientset, error) {
 		MultiNetworkPolicyClient: multiNetworkPolicyClientset,
 		EgressServiceClient:      egressserviceClientset,
 		AdminPolicyRouteClient:   adminPolicyBasedRouteClientset,
+		IPAMClaimClient:          ipamClaimClientset,
+		NetworkDefinitionClient:  networkDefinitionClientset,
 	}, nil
 }
 
@@ -93,6 +93,32 @@ func (_m *NetLinkOps) ConntrackDeleteFilter(table netlink.ConntrackTableType, fa
 	return r0, r1
 }
 
+// ConntrackTableList provides a mock function with given fields: table, family
+func (_m *NetLinkOps) ConntrackTableList(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+	ret := _m.Called(table, family)
+
+	var r0 []*netlink.ConntrackFlow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(netlink.ConntrackTableType, netlink.InetFamily) ([]*netlink.ConntrackFlow, error)); ok {
+		return rf(table, family)
+	}
+	if rf, ok := ret.Get(0).(func(netlink.ConntrackTableType, netlink.InetFamily) []*netlink.ConntrackFlow); ok {
+		r0 = rf(table, family)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*netlink.ConntrackFlow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(netlink.ConntrackTableType, netlink.InetFamily) error); ok {
+		r1 = rf(table, family)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsLinkNotFoundError provides a mock function with given fields: err
 func (_m *NetLinkOps) IsLinkNotFoundError(err error) bool {
 	ret := _m.Called(err)
@@ -107,6 +133,20 @@ func (_m *NetLinkOps) IsLinkNotFoundError(err error) bool {
 	return r0
 }
 
+// LinkAdd provides a mock function with given fields: link
+func (_m *NetLinkOps) LinkAdd(link netlink.Link) error {
+	ret := _m.Called(link)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(netlink.Link) error); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // LinkByIndex provides a mock function with given fields: index
 func (_m *NetLinkOps) LinkByIndex(index int) (netlink.Link, error) {
 	ret := _m.Called(index)
@@ -35,6 +35,11 @@ type BasicNetInfo interface {
 	Subnets() []config.CIDRNetworkEntry
 	ExcludeSubnets() []*net.IPNet
 	Vlan() uint
+	AllowsPersistentIPs() bool
+	IsGatewayEnabled() bool
+	DHCP() *ovncnitypes.DHCPConfig
+	IPPools() []IPPool
+	VLANTrunk() []VLANRange
 
 	// utility methods
 	CompareNetInfo(BasicNetInfo) bool
@@ -127,12 +132,43 @@ func (nInfo *DefaultNetInfo) Vlan() uint {
 	return config.Gateway.VLANID
 }
 
+// AllowsPersistentIPs returns the defaultNetConfInfo's AllowPersistentIPs value
+func (nInfo *DefaultNetInfo) AllowsPersistentIPs() bool {
+	return false
+}
+
+// IsGatewayEnabled returns the defaultNetConfInfo's EnableGateway value. The
+// default network gateway is not handled as a secondary network gateway.
+func (nInfo *DefaultNetInfo) IsGatewayEnabled() bool {
+	return false
+}
+
+// DHCP returns the defaultNetConfInfo's DHCP configuration
+func (nInfo *DefaultNetInfo) DHCP() *ovncnitypes.DHCPConfig {
+	return nil
+}
+
+// IPPools returns the defaultNetConfInfo's IP pools
+func (nInfo *DefaultNetInfo) IPPools() []IPPool {
+	return nil
+}
+
+// VLANTrunk returns the defaultNetConfInfo's VLAN trunk
+func (nInfo *DefaultNetInfo) VLANTrunk() []VLANRange {
+	return nil
+}
+
 // SecondaryNetInfo holds the network name information for secondary network if non-nil
 type secondaryNetInfo struct {
-	netName  string
-	topology string
-	mtu      int
-	vlan     uint
+	netName            string
+	topology           string
+	mtu                int
+	vlan               uint
+	allowPersistentIPs bool
+	enableGateway      bool
+	dhcp               *ovncnitypes.DHCPConfig
+	ipPools            []IPPool
+	vlanTrunk          []VLANRange
 
 	ipv4mode, ipv6mode bool
 	subnets            []config.CIDRNetworkEntry
@@ -203,6 +239,32 @@ func (nInfo *secondaryNetInfo) Vlan() uint {
 	return nInfo.vlan
 }
 
+// AllowsPersistentIPs returns the AllowPersistentIPs value
+func (nInfo *secondaryNetInfo) AllowsPersistentIPs() bool {
+	return nInfo.allowPersistentIPs
+}
+
+// IsGatewayEnabled returns the EnableGateway value
+func (nInfo *secondaryNetInfo) IsGatewayEnabled() bool {
+	return nInfo.enableGateway
+}
+
+// DHCP returns the DHCP configuration, nil when DHCP is disabled
+func (nInfo *secondaryNetInfo) DHCP() *ovncnitypes.DHCPConfig {
+	return nInfo.dhcp
+}
+
+// IPPools returns the IP pools of the network subnets
+func (nInfo *secondaryNetInfo) IPPools() []IPPool {
+	return nInfo.ipPools
+}
+
+// VLANTrunk returns the VLANs the pods can tag their traffic with, nil when
+// the network is not a VLAN trunk
+func (nInfo *secondaryNetInfo) VLANTrunk() []VLANRange {
+	return nInfo.vlanTrunk
+}
+
 // IPMode returns the ipv4/ipv6 mode
 func (nInfo *secondaryNetInfo) IPMode() (bool, bool) {
 	return nInfo.ipv4mode, nInfo.ipv6mode
@@ -232,6 +294,21 @@ func (nInfo *secondaryNetInfo) CompareNetInfo(other BasicNetInfo) bool {
 	if nInfo.vlan != other.Vlan() {
 		return false
 	}
+	if nInfo.allowPersistentIPs != other.AllowsPersistentIPs() {
+		return false
+	}
+	if nInfo.enableGateway != other.IsGatewayEnabled() {
+		return false
+	}
+	if !cmp.Equal(nInfo.dhcp, other.DHCP()) {
+		return false
+	}
+	if !cmp.Equal(nInfo.ipPools, other.IPPools()) {
+		return false
+	}
+	if !cmp.Equal(nInfo.vlanTrunk, other.VLANTrunk()) {
+		return false
+	}
 
 	lessCIDRNetworkEntry := func(a, b config.CIDRNetworkEntry) bool { return a.String() < b.String() }
 	if !cmp.Equal(nInfo.subnets, other.Subnets(), cmpopts.SortSlices(lessCIDRNetworkEntry)) {
@@ -247,12 +324,28 @@ func newLayer3NetConfInfo(netconf *ovncnitypes.NetConf) (NetInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	if netconf.AllowPersistentIPs {
+		return nil, fmt.Errorf("invalid %s netconf %s: persistent IPs are not supported", netconf.Topology, netconf.Name)
+	}
+	if netconf.DHCP != nil {
+		return nil, fmt.Errorf("invalid %s netconf %s: DHCP is not supported", netconf.Topology, netconf.Name)
+	}
+	if len(netconf.IPPools) > 0 {
+		return nil, fmt.Errorf("invalid %s netconf %s: IP pools are not supported", netconf.Topology, netconf.Name)
+	}
+	if netconf.VLANTrunk != "" {
+		return nil, fmt.Errorf("invalid %s netconf %s: VLAN trunk is not supported", netconf.Topology, netconf.Name)
+	}
+	if netconf.EnableGateway && !IsSecondaryNetworkGatewaySupportEnabled() {
+		return nil, fmt.Errorf("invalid %s netconf %s: gateway support is not enabled", netconf.Topology, netconf.Name)
+	}
 
 	ni := &secondaryNetInfo{
-		netName:  netconf.Name,
-		topology: types.Layer3Topology,
-		subnets:  subnets,
-		mtu:      netconf.MTU,
+		netName:       netconf.Name,
+		topology:      types.Layer3Topology,
+		subnets:       subnets,
+		mtu:           netconf.MTU,
+		enableGateway: netconf.EnableGateway,
 	}
 	ni.ipv4mode, ni.ipv6mode = getIPMode(subnets)
 	return ni, nil
@@ -263,13 +356,51 @@ func newLayer2NetConfInfo(netconf *ovncnitypes.NetConf) (NetInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
 	}
+	if netconf.AllowPersistentIPs && len(subnets) == 0 {
+		return nil, fmt.Errorf("invalid %s netconf %s: persistent IPs require subnets", netconf.Topology, netconf.Name)
+	}
+	if netconf.VLANTrunk != "" {
+		return nil, fmt.Errorf("invalid %s netconf %s: VLAN trunk is not supported", netconf.Topology, netconf.Name)
+	}
+	if netconf.EnableGateway {
+		if len(subnets) == 0 {
+			return nil, fmt.Errorf("invalid %s netconf %s: gateway requires subnets", netconf.Topology, netconf.Name)
+		}
+		if !config.OVNKubernetesFeature.EnableInterconnect {
+			return nil, fmt.Errorf("invalid %s netconf %s: gateway requires interconnect", netconf.Topology, netconf.Name)
+		}
+		if !IsSecondaryNetworkGatewaySupportEnabled() {
+			return nil, fmt.Errorf("invalid %s netconf %s: gateway support is not enabled", netconf.Topology, netconf.Name)
+		}
+		// the first IP of each subnet is reserved for the gateway router
+		for _, subnet := range subnets {
+			gwIP := GetNodeGatewayIfAddr(subnet.CIDR).IP
+			excludes = append(excludes, &net.IPNet{IP: gwIP, Mask: GetIPFullMask(gwIP)})
+		}
+	}
+	if netconf.DHCP != nil {
+		if err := validateDHCPConfig(netconf.DHCP, subnets); err != nil {
+			return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
+		}
+		if !netconf.EnableGateway {
+			excludes = append(excludes, getDHCPServerExcludes(subnets)...)
+		}
+	}
+	ipPools, excludes, err := parseIPPools(netconf.IPPools, subnets, excludes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
+	}
 
 	ni := &secondaryNetInfo{
-		netName:        netconf.Name,
-		topology:       types.Layer2Topology,
-		subnets:        subnets,
-		excludeSubnets: excludes,
-		mtu:            netconf.MTU,
+		netName:            netconf.Name,
+		topology:           types.Layer2Topology,
+		subnets:            subnets,
+		excludeSubnets:     excludes,
+		mtu:                netconf.MTU,
+		allowPersistentIPs: netconf.AllowPersistentIPs,
+		enableGateway:      netconf.EnableGateway,
+		dhcp:               netconf.DHCP,
+		ipPools:            ipPools,
 	}
 	ni.ipv4mode, ni.ipv6mode = getIPMode(subnets)
 	return ni, nil
@@ -280,14 +411,41 @@ func newLocalnetNetConfInfo(netconf *ovncnitypes.NetConf) (NetInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
 	}
+	if netconf.AllowPersistentIPs && len(subnets) == 0 {
+		return nil, fmt.Errorf("invalid %s netconf %s: persistent IPs require subnets", netconf.Topology, netconf.Name)
+	}
+	if netconf.EnableGateway {
+		return nil, fmt.Errorf("invalid %s netconf %s: gateway is not supported", netconf.Topology, netconf.Name)
+	}
+	vlanTrunk, err := parseVLANTrunk(netconf.VLANTrunk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
+	}
+	if len(vlanTrunk) > 0 && netconf.VLANID != 0 {
+		return nil, fmt.Errorf("invalid %s netconf %s: VLAN ID and VLAN trunk are exclusive", netconf.Topology, netconf.Name)
+	}
+	if netconf.DHCP != nil {
+		if err := validateDHCPConfig(netconf.DHCP, subnets); err != nil {
+			return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
+		}
+		excludes = append(excludes, getDHCPServerExcludes(subnets)...)
+	}
+	ipPools, excludes, err := parseIPPools(netconf.IPPools, subnets, excludes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s netconf %s: %v", netconf.Topology, netconf.Name, err)
+	}
 
 	ni := &secondaryNetInfo{
-		netName:        netconf.Name,
-		topology:       types.LocalnetTopology,
-		subnets:        subnets,
-		excludeSubnets: excludes,
-		mtu:            netconf.MTU,
-		vlan:           uint(netconf.VLANID),
+		netName:            netconf.Name,
+		topology:           types.LocalnetTopology,
+		subnets:            subnets,
+		excludeSubnets:     excludes,
+		mtu:                netconf.MTU,
+		vlan:               uint(netconf.VLANID),
+		allowPersistentIPs: netconf.AllowPersistentIPs,
+		dhcp:               netconf.DHCP,
+		ipPools:            ipPools,
+		vlanTrunk:          vlanTrunk,
 	}
 	ni.ipv4mode, ni.ipv6mode = getIPMode(subnets)
 	return ni, nil
@@ -481,6 +639,31 @@ func IsMultiNetworkPoliciesSupportEnabled() bool {
 	return config.OVNKubernetesFeature.EnableMultiNetwork && config.OVNKubernetesFeature.EnableMultiNetworkPolicy
 }
 
+// IsObservedPodIPsEnabled returns true if the IPs of pods on IPAM-less
+// secondary networks are learnt from their traffic and used by multi-network
+// policies
+func IsObservedPodIPsEnabled() bool {
+	return IsMultiNetworkPoliciesSupportEnabled() && config.OVNKubernetesFeature.EnableObservedPodIPs
+}
+
+// IsPersistentIPsSupportEnabled returns true if the IPs of workloads on
+// secondary networks can be persisted with IPAMClaims
+func IsPersistentIPsSupportEnabled() bool {
+	return config.OVNKubernetesFeature.EnableMultiNetwork && config.OVNKubernetesFeature.EnablePersistentIPs
+}
+
+// IsNetworkDefinitionsEnabled returns true if secondary networks can be defined
+// for multiple namespaces at once with NetworkDefinitions
+func IsNetworkDefinitionsEnabled() bool {
+	return config.OVNKubernetesFeature.EnableMultiNetwork && config.OVNKubernetesFeature.EnableNetworkDefinitions
+}
+
+// IsSecondaryNetworkGatewaySupportEnabled returns true if layer3 and layer2
+// secondary networks can have gateway routers
+func IsSecondaryNetworkGatewaySupportEnabled() bool {
+	return config.OVNKubernetesFeature.EnableMultiNetwork && config.OVNKubernetesFeature.EnableSecondaryNetworkGateway
+}
+
 func DoesNetworkRequireIPAM(netInfo NetInfo) bool {
 	return !((netInfo.TopologyType() == types.Layer2Topology || netInfo.TopologyType() == types.LocalnetTopology) && len(netInfo.Subnets()) == 0)
 }
@@ -489,3 +672,44 @@ func DoesNetworkRequireTunnelIDs(netInfo NetInfo) bool {
 	// Layer2Topology with IC require that we allocate tunnel IDs for each pod
 	return netInfo.TopologyType() == types.Layer2Topology && config.OVNKubernetesFeature.EnableInterconnect
 }
+
+// GetSecondaryNetworkHostMasqueradeIPs returns the IPs, one per enabled IP
+// family, through which the host is reached from the gateway routers of the
+// secondary networks
+func GetSecondaryNetworkHostMasqueradeIPs() ([]*net.IPNet, error) {
+	return getSecondaryNetworkMasqueradeIPs(1)
+}
+
+// GetSecondaryNetworkGatewayMasqueradeIPs returns the external IPs, one per
+// enabled IP family, of the gateway routers of the secondary network with the
+// provided network ID
+func GetSecondaryNetworkGatewayMasqueradeIPs(networkID int) ([]*net.IPNet, error) {
+	if networkID < 1 {
+		return nil, fmt.Errorf("invalid secondary network ID %d", networkID)
+	}
+	return getSecondaryNetworkMasqueradeIPs(networkID + 1)
+}
+
+func getSecondaryNetworkMasqueradeIPs(offset int) ([]*net.IPNet, error) {
+	var masqueradeSubnets []string
+	if config.IPv4Mode {
+		masqueradeSubnets = append(masqueradeSubnets, config.Gateway.V4SecondaryMasqueradeSubnet)
+	}
+	if config.IPv6Mode {
+		masqueradeSubnets = append(masqueradeSubnets, config.Gateway.V6SecondaryMasqueradeSubnet)
+	}
+	masqueradeIPs := make([]*net.IPNet, 0, len(masqueradeSubnets))
+	for _, masqueradeSubnet := range masqueradeSubnets {
+		_, subnet, err := net.ParseCIDR(masqueradeSubnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secondary masquerade subnet %s: %v", masqueradeSubnet, err)
+		}
+		// skip the network address and the last address of the subnet
+		if int64(offset) >= knet.RangeSize(subnet)-1 {
+			return nil, fmt.Errorf("secondary masquerade subnet %s exhausted at offset %d", masqueradeSubnet, offset)
+		}
+		ip := knet.AddIPOffset(knet.BigForIP(subnet.IP), offset)
+		masqueradeIPs = append(masqueradeIPs, &net.IPNet{IP: ip, Mask: subnet.Mask})
+	}
+	return masqueradeIPs, nil
+}
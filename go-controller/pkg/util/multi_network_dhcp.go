@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+	"net"
+
+	utilnet "k8s.io/utils/net"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+)
+
+// GetDHCPServerIP returns the IP the DHCPv4 server of a layer2 or localnet
+// network answers from in the provided subnet: the first IP of the subnet,
+// which is also the IP of the gateway router of the network, if any.
+func GetDHCPServerIP(subnet *net.IPNet) net.IP {
+	return GetNodeGatewayIfAddr(subnet).IP
+}
+
+// getDHCPServerExcludes returns the IPs of the DHCPv4 servers of the subnets,
+// to be excluded from the pod IP allocation
+func getDHCPServerExcludes(subnets []config.CIDRNetworkEntry) []*net.IPNet {
+	var excludes []*net.IPNet
+	for _, subnet := range subnets {
+		if utilnet.IsIPv4CIDR(subnet.CIDR) {
+			serverIP := GetDHCPServerIP(subnet.CIDR)
+			excludes = append(excludes, &net.IPNet{IP: serverIP, Mask: GetIPFullMask(serverIP)})
+		}
+	}
+	return excludes
+}
+
+func validateDHCPConfig(dhcp *ovncnitypes.DHCPConfig, subnets []config.CIDRNetworkEntry) error {
+	if len(subnets) == 0 {
+		return fmt.Errorf("DHCP requires subnets")
+	}
+	if dhcp.LeaseTime < 0 {
+		return fmt.Errorf("invalid DHCP lease time %d", dhcp.LeaseTime)
+	}
+	for _, dnsServer := range dhcp.DNSServers {
+		if net.ParseIP(dnsServer) == nil {
+			return fmt.Errorf("invalid DHCP DNS server %q", dnsServer)
+		}
+	}
+	for _, route := range dhcp.Routes {
+		_, dest, err := net.ParseCIDR(route.Dest)
+		if err != nil || !utilnet.IsIPv4CIDR(dest) {
+			return fmt.Errorf("invalid DHCP route destination %q: only IPv4 routes are supported", route.Dest)
+		}
+		nextHop := net.ParseIP(route.NextHop)
+		if nextHop == nil || !utilnet.IsIPv4(nextHop) {
+			return fmt.Errorf("invalid DHCP route next hop %q", route.NextHop)
+		}
+		onLink := false
+		for _, subnet := range subnets {
+			if subnet.CIDR.Contains(nextHop) {
+				onLink = true
+				break
+			}
+		}
+		if !onLink {
+			return fmt.Errorf("invalid DHCP route next hop %s: not in the network subnets", nextHop)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilnet "k8s.io/utils/net"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+)
+
+// IPPoolsCNIArg is the CNI argument of the network selection element of a pod
+// listing the IP pools it gets its IPs on the network from
+const IPPoolsCNIArg = "ipPools"
+
+// IPPool is a named range of IPs of one of the subnets of a layer2 or localnet
+// network, only allocated to the pods selecting it
+type IPPool struct {
+	Name       string
+	Subnet     *net.IPNet
+	RangeStart net.IP
+	RangeEnd   net.IP
+	Routes     []PodRoute
+	Namespaces sets.Set[string]
+}
+
+// Contains returns whether the IP is in the range of the pool
+func (pool *IPPool) Contains(ip net.IP) bool {
+	return pool.Subnet.Contains(ip) &&
+		bytes.Compare(ip.To16(), pool.RangeStart.To16()) >= 0 &&
+		bytes.Compare(ip.To16(), pool.RangeEnd.To16()) <= 0
+}
+
+// GetPodIPPools returns the names of the IP pools a pod gets its IPs on the
+// network from: the pools listed in the ipPools CNI argument of its network
+// selection element or, if none, the first pool of each subnet restricted to
+// its namespace. None means the pod gets its IPs out of the pools.
+func GetPodIPPools(netInfo NetInfo, pod *kapi.Pod, network *nadapi.NetworkSelectionElement) ([]string, error) {
+	ipPools := netInfo.IPPools()
+	if len(ipPools) == 0 {
+		return nil, nil
+	}
+	requested, err := getRequestedIPPools(network)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	subnets := sets.New[string]()
+	if len(requested) > 0 {
+		for _, name := range requested {
+			pool := findIPPool(ipPools, name)
+			if pool == nil {
+				return nil, fmt.Errorf("IP pool %s not found on network %s", name, netInfo.GetNetworkName())
+			}
+			if pool.Namespaces.Len() > 0 && !pool.Namespaces.Has(pod.Namespace) {
+				return nil, fmt.Errorf("IP pool %s of network %s is not available to namespace %s",
+					name, netInfo.GetNetworkName(), pod.Namespace)
+			}
+			if subnets.Has(pool.Subnet.String()) {
+				return nil, fmt.Errorf("IP pool %s of network %s is in the same subnet as another requested pool",
+					name, netInfo.GetNetworkName())
+			}
+			subnets.Insert(pool.Subnet.String())
+			names = append(names, name)
+		}
+		return names, nil
+	}
+
+	for _, pool := range ipPools {
+		if pool.Namespaces.Has(pod.Namespace) && !subnets.Has(pool.Subnet.String()) {
+			subnets.Insert(pool.Subnet.String())
+			names = append(names, pool.Name)
+		}
+	}
+	return names, nil
+}
+
+// getRequestedIPPools returns the IP pools listed in the ipPools CNI argument
+// of the network selection element, if any
+func getRequestedIPPools(network *nadapi.NetworkSelectionElement) ([]string, error) {
+	if network == nil || network.CNIArgs == nil {
+		return nil, nil
+	}
+	arg, ok := (*network.CNIArgs)[IPPoolsCNIArg]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := arg.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid %s CNI argument %v: expected a list of IP pool names", IPPoolsCNIArg, arg)
+	}
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		name, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s CNI argument %v: expected a list of IP pool names", IPPoolsCNIArg, arg)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func findIPPool(ipPools []IPPool, name string) *IPPool {
+	for i := range ipPools {
+		if ipPools[i].Name == name {
+			return &ipPools[i]
+		}
+	}
+	return nil
+}
+
+// findIPPoolOfIP returns the IP pool the IP belongs to, if any
+func findIPPoolOfIP(ipPools []IPPool, ip net.IP) *IPPool {
+	for i := range ipPools {
+		if ipPools[i].Contains(ip) {
+			return &ipPools[i]
+		}
+	}
+	return nil
+}
+
+// parseIPPools validates the IP pools of a network and returns them along with
+// the excludes of the network extended with the gateways of the pools, which
+// are not allocated to the pods
+func parseIPPools(ipPools []ovncnitypes.IPPool, subnets []config.CIDRNetworkEntry, excludes []*net.IPNet) ([]IPPool, []*net.IPNet, error) {
+	var pools []IPPool
+	for _, ipPool := range ipPools {
+		if ipPool.Name == "" {
+			return nil, nil, fmt.Errorf("IP pool without name")
+		}
+		if findIPPool(pools, ipPool.Name) != nil {
+			return nil, nil, fmt.Errorf("duplicate IP pool %s", ipPool.Name)
+		}
+		pool := IPPool{
+			Name:       ipPool.Name,
+			Namespaces: sets.New(ipPool.Namespaces...),
+		}
+
+		_, subnet, err := net.ParseCIDR(ipPool.Subnet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid subnet %q of IP pool %s", ipPool.Subnet, ipPool.Name)
+		}
+		for _, networkSubnet := range subnets {
+			if networkSubnet.CIDR.String() == subnet.String() {
+				pool.Subnet = networkSubnet.CIDR
+				break
+			}
+		}
+		if pool.Subnet == nil {
+			return nil, nil, fmt.Errorf("subnet %s of IP pool %s is not one of the network subnets", subnet, ipPool.Name)
+		}
+
+		// the pool spans the allocatable IPs of the subnet by default
+		pool.RangeStart, pool.RangeEnd = getSubnetAllocatableRange(pool.Subnet)
+		if ipPool.RangeStart != "" {
+			if pool.RangeStart, err = parseIPPoolIP(ipPool.RangeStart, pool.Subnet); err != nil {
+				return nil, nil, fmt.Errorf("invalid range start of IP pool %s: %v", ipPool.Name, err)
+			}
+		}
+		if ipPool.RangeEnd != "" {
+			if pool.RangeEnd, err = parseIPPoolIP(ipPool.RangeEnd, pool.Subnet); err != nil {
+				return nil, nil, fmt.Errorf("invalid range end of IP pool %s: %v", ipPool.Name, err)
+			}
+		}
+		if bytes.Compare(pool.RangeStart.To16(), pool.RangeEnd.To16()) > 0 {
+			return nil, nil, fmt.Errorf("invalid range of IP pool %s: %s is after %s", ipPool.Name, pool.RangeStart, pool.RangeEnd)
+		}
+		for _, other := range pools {
+			if other.Contains(pool.RangeStart) || other.Contains(pool.RangeEnd) || pool.Contains(other.RangeStart) {
+				return nil, nil, fmt.Errorf("range of IP pool %s overlaps with IP pool %s", ipPool.Name, other.Name)
+			}
+		}
+
+		var gateway net.IP
+		if ipPool.Gateway != "" {
+			if gateway, err = parseIPPoolIP(ipPool.Gateway, pool.Subnet); err != nil {
+				return nil, nil, fmt.Errorf("invalid gateway of IP pool %s: %v", ipPool.Name, err)
+			}
+			if !isIPExcluded(gateway, excludes) {
+				excludes = append(excludes, &net.IPNet{IP: gateway, Mask: GetIPFullMask(gateway)})
+			}
+		}
+		for _, route := range ipPool.Routes {
+			_, dest, err := net.ParseCIDR(route.Dest)
+			if err != nil || utilnet.IsIPv6CIDR(dest) != utilnet.IsIPv6CIDR(pool.Subnet) {
+				return nil, nil, fmt.Errorf("invalid route destination %q of IP pool %s", route.Dest, ipPool.Name)
+			}
+			nextHop := gateway
+			if route.NextHop != "" {
+				if nextHop, err = parseIPPoolIP(route.NextHop, pool.Subnet); err != nil {
+					return nil, nil, fmt.Errorf("invalid route next hop of IP pool %s: %v", ipPool.Name, err)
+				}
+			}
+			if nextHop == nil {
+				return nil, nil, fmt.Errorf("route to %s of IP pool %s has no next hop", dest, ipPool.Name)
+			}
+			pool.Routes = append(pool.Routes, PodRoute{Dest: dest, NextHop: nextHop})
+		}
+
+		pools = append(pools, pool)
+	}
+	return pools, excludes, nil
+}
+
+func isIPExcluded(ip net.IP, excludes []*net.IPNet) bool {
+	for _, exclude := range excludes {
+		if exclude.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPPoolIP parses an IP of an IP pool, which must be in the subnet of the
+// pool
+func parseIPPoolIP(ipString string, subnet *net.IPNet) (net.IP, error) {
+	ip := net.ParseIP(ipString)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", ipString)
+	}
+	start, end := getSubnetAllocatableRange(subnet)
+	if !subnet.Contains(ip) || bytes.Compare(ip.To16(), start.To16()) < 0 || bytes.Compare(ip.To16(), end.To16()) > 0 {
+		return nil, fmt.Errorf("IP %s is not an allocatable IP of subnet %s", ip, subnet)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4, nil
+	}
+	return ip, nil
+}
+
+// getSubnetAllocatableRange returns the first and last IPs of the subnet that
+// can be allocated, which leaves out the network address and the IPv4
+// broadcast address
+func getSubnetAllocatableRange(subnet *net.IPNet) (net.IP, net.IP) {
+	ip := subnet.IP
+	if len(subnet.Mask) == net.IPv4len {
+		ip = ip.To4()
+	}
+	last := make(net.IP, len(ip))
+	for i := range ip {
+		last[i] = ip[i] | ^subnet.Mask[i]
+	}
+	start := utilnet.AddIPOffset(utilnet.BigForIP(ip), 1)
+	if utilnet.IsIPv4CIDR(subnet) {
+		start, last = start.To4(), utilnet.AddIPOffset(utilnet.BigForIP(last), -1).To4()
+	}
+	return start, last
+}
@@ -10,6 +10,8 @@ import (
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 
 	nadv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
@@ -314,3 +316,641 @@ func applyNADDefaults(nad *nadv1.NetworkAttachmentDefinition) *nadv1.NetworkAtta
 	nad.Namespace = namespace
 	return nad
 }
+
+func TestNewNetInfoPersistentIPs(t *testing.T) {
+	tests := []struct {
+		desc        string
+		topology    string
+		subnets     string
+		expectError bool
+	}{
+		{
+			desc:     "layer 2 topology with subnets",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+		},
+		{
+			desc:        "layer 2 topology without subnets",
+			topology:    types.Layer2Topology,
+			expectError: true,
+		},
+		{
+			desc:     "localnet topology with subnets",
+			topology: types.LocalnetTopology,
+			subnets:  "192.168.1.0/24",
+		},
+		{
+			desc:        "localnet topology without subnets",
+			topology:    types.LocalnetTopology,
+			expectError: true,
+		},
+		{
+			desc:        "layer 3 topology",
+			topology:    types.Layer3Topology,
+			subnets:     "192.168.0.0/16/24",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:            cnitypes.NetConf{Name: "tenantred"},
+				Topology:           tc.topology,
+				Subnets:            tc.subnets,
+				AllowPersistentIPs: true,
+			})
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(netInfo.AllowsPersistentIPs()).To(gomega.BeTrue())
+		})
+	}
+}
+
+func TestNewNetInfoGateway(t *testing.T) {
+	tests := []struct {
+		desc             string
+		topology         string
+		subnets          string
+		interconnect     bool
+		expectedExcludes []*net.IPNet
+		expectError      bool
+	}{
+		{
+			desc:     "layer 3 topology",
+			topology: types.Layer3Topology,
+			subnets:  "192.168.0.0/16/24",
+		},
+		{
+			desc:             "layer 2 topology with subnets and interconnect",
+			topology:         types.Layer2Topology,
+			subnets:          "192.168.1.0/24, fda6::/64",
+			interconnect:     true,
+			expectedExcludes: ovntest.MustParseIPNets("192.168.1.1/32", "fda6::1/128"),
+		},
+		{
+			desc:         "layer 2 topology without subnets",
+			topology:     types.Layer2Topology,
+			interconnect: true,
+			expectError:  true,
+		},
+		{
+			desc:        "layer 2 topology without interconnect",
+			topology:    types.Layer2Topology,
+			subnets:     "192.168.1.0/24",
+			expectError: true,
+		},
+		{
+			desc:         "localnet topology",
+			topology:     types.LocalnetTopology,
+			subnets:      "192.168.1.0/24",
+			interconnect: true,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			config.OVNKubernetesFeature.EnableInterconnect = tc.interconnect
+			config.OVNKubernetesFeature.EnableMultiNetwork = true
+			config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:       cnitypes.NetConf{Name: "tenantred"},
+				Topology:      tc.topology,
+				Subnets:       tc.subnets,
+				EnableGateway: true,
+			})
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(netInfo.IsGatewayEnabled()).To(gomega.BeTrue())
+			g.Expect(netInfo.ExcludeSubnets()).To(gomega.Equal(tc.expectedExcludes))
+		})
+	}
+}
+
+func TestNewNetInfoDHCP(t *testing.T) {
+	tests := []struct {
+		desc             string
+		topology         string
+		subnets          string
+		enableGateway    bool
+		dhcp             *ovncnitypes.DHCPConfig
+		expectedExcludes []*net.IPNet
+		expectError      bool
+	}{
+		{
+			desc:             "localnet topology reserves the IPv4 server IPs",
+			topology:         types.LocalnetTopology,
+			subnets:          "192.168.1.0/24, fda6::/64",
+			dhcp:             &ovncnitypes.DHCPConfig{DNSServers: []string{"192.168.1.53", "fda6::53"}},
+			expectedExcludes: ovntest.MustParseIPNets("192.168.1.1/32"),
+		},
+		{
+			desc:     "layer 2 topology with routes",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			dhcp: &ovncnitypes.DHCPConfig{
+				LeaseTime: 600,
+				Routes:    []ovncnitypes.DHCPRoute{{Dest: "0.0.0.0/0", NextHop: "192.168.1.254"}},
+			},
+			expectedExcludes: ovntest.MustParseIPNets("192.168.1.1/32"),
+		},
+		{
+			desc:             "layer 2 topology with gateway serves from the gateway IP",
+			topology:         types.Layer2Topology,
+			subnets:          "192.168.1.0/24",
+			enableGateway:    true,
+			dhcp:             &ovncnitypes.DHCPConfig{},
+			expectedExcludes: ovntest.MustParseIPNets("192.168.1.1/32"),
+		},
+		{
+			desc:        "layer 3 topology",
+			topology:    types.Layer3Topology,
+			subnets:     "192.168.0.0/16/24",
+			dhcp:        &ovncnitypes.DHCPConfig{},
+			expectError: true,
+		},
+		{
+			desc:        "layer 2 topology without subnets",
+			topology:    types.Layer2Topology,
+			dhcp:        &ovncnitypes.DHCPConfig{},
+			expectError: true,
+		},
+		{
+			desc:        "invalid DNS server",
+			topology:    types.Layer2Topology,
+			subnets:     "192.168.1.0/24",
+			dhcp:        &ovncnitypes.DHCPConfig{DNSServers: []string{"dns.example.com"}},
+			expectError: true,
+		},
+		{
+			desc:     "route next hop outside of the subnets",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			dhcp: &ovncnitypes.DHCPConfig{
+				Routes: []ovncnitypes.DHCPRoute{{Dest: "10.0.0.0/8", NextHop: "192.168.2.1"}},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "IPv6 route",
+			topology: types.Layer2Topology,
+			subnets:  "fda6::/64",
+			dhcp: &ovncnitypes.DHCPConfig{
+				Routes: []ovncnitypes.DHCPRoute{{Dest: "::/0", NextHop: "fda6::1"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			config.OVNKubernetesFeature.EnableInterconnect = true
+			config.OVNKubernetesFeature.EnableMultiNetwork = true
+			config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:       cnitypes.NetConf{Name: "tenantred"},
+				Topology:      tc.topology,
+				Subnets:       tc.subnets,
+				EnableGateway: tc.enableGateway,
+				DHCP:          tc.dhcp,
+			})
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(netInfo.DHCP()).To(gomega.Equal(tc.dhcp))
+			g.Expect(netInfo.ExcludeSubnets()).To(gomega.Equal(tc.expectedExcludes))
+		})
+	}
+}
+
+func TestNewNetInfoIPPools(t *testing.T) {
+	tests := []struct {
+		desc             string
+		topology         string
+		subnets          string
+		ipPools          []ovncnitypes.IPPool
+		expectedRanges   []string
+		expectedExcludes []*net.IPNet
+		expectError      bool
+	}{
+		{
+			desc:     "pools span their whole subnet by default",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24, fda6::/64",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "v4", Subnet: "192.168.1.0/24"},
+				{Name: "v6", Subnet: "fda6::/64"},
+			},
+			expectedRanges: []string{"192.168.1.1-192.168.1.254", "fda6::1-fda6::ffff:ffff:ffff:ffff"},
+		},
+		{
+			desc:     "the gateways of the pools are excluded",
+			topology: types.LocalnetTopology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.10", RangeEnd: "192.168.1.19", Gateway: "192.168.1.254"},
+				{Name: "red", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.20", RangeEnd: "192.168.1.29", Gateway: "192.168.1.254"},
+			},
+			expectedRanges:   []string{"192.168.1.10-192.168.1.19", "192.168.1.20-192.168.1.29"},
+			expectedExcludes: ovntest.MustParseIPNets("192.168.1.254/32"),
+		},
+		{
+			desc:     "layer 3 topology",
+			topology: types.Layer3Topology,
+			subnets:  "192.168.0.0/16/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.0.0/16"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "pool out of the network subnets",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.2.0/24"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "duplicate pool names",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.10", RangeEnd: "192.168.1.19"},
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.20", RangeEnd: "192.168.1.29"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "overlapping pools",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.10", RangeEnd: "192.168.1.19"},
+				{Name: "red", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.5", RangeEnd: "192.168.1.29"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "range start after range end",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.19", RangeEnd: "192.168.1.10"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "broadcast range end",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", RangeEnd: "192.168.1.255"},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "route without next hop",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", Routes: []ovncnitypes.IPPoolRoute{{Dest: "10.0.0.0/8"}}},
+			},
+			expectError: true,
+		},
+		{
+			desc:     "route of another IP family",
+			topology: types.Layer2Topology,
+			subnets:  "192.168.1.0/24",
+			ipPools: []ovncnitypes.IPPool{
+				{Name: "blue", Subnet: "192.168.1.0/24", Gateway: "192.168.1.254", Routes: []ovncnitypes.IPPoolRoute{{Dest: "fd00::/8"}}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:  cnitypes.NetConf{Name: "tenantred"},
+				Topology: tc.topology,
+				Subnets:  tc.subnets,
+				IPPools:  tc.ipPools,
+			})
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			var ranges []string
+			for _, pool := range netInfo.IPPools() {
+				ranges = append(ranges, fmt.Sprintf("%s-%s", pool.RangeStart, pool.RangeEnd))
+			}
+			g.Expect(ranges).To(gomega.Equal(tc.expectedRanges))
+			g.Expect(netInfo.ExcludeSubnets()).To(gomega.Equal(tc.expectedExcludes))
+		})
+	}
+}
+
+func TestGetPodIPPools(t *testing.T) {
+	netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+		NetConf:  cnitypes.NetConf{Name: "tenantred"},
+		Topology: types.Layer2Topology,
+		Subnets:  "192.168.1.0/24, fda6::/64",
+		IPPools: []ovncnitypes.IPPool{
+			{Name: "blue", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.10", RangeEnd: "192.168.1.19", Namespaces: []string{"ns1"}},
+			{Name: "blue6", Subnet: "fda6::/64", RangeStart: "fda6::10", RangeEnd: "fda6::19", Namespaces: []string{"ns1"}},
+			{Name: "red", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.20", RangeEnd: "192.168.1.29", Namespaces: []string{"ns1"}},
+			{Name: "static", Subnet: "192.168.1.0/24", RangeStart: "192.168.1.30", RangeEnd: "192.168.1.39"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		desc          string
+		namespace     string
+		cniArgs       map[string]interface{}
+		expectedPools []string
+		expectError   bool
+	}{
+		{
+			desc:          "the first pools of each subnet restricted to the namespace by default",
+			namespace:     "ns1",
+			expectedPools: []string{"blue", "blue6"},
+		},
+		{
+			desc:      "no pool by default for other namespaces",
+			namespace: "ns2",
+		},
+		{
+			desc:          "requested pools",
+			namespace:     "ns1",
+			cniArgs:       map[string]interface{}{IPPoolsCNIArg: []interface{}{"red"}},
+			expectedPools: []string{"red"},
+		},
+		{
+			desc:          "requested pool not restricted to any namespace",
+			namespace:     "ns2",
+			cniArgs:       map[string]interface{}{IPPoolsCNIArg: []interface{}{"static"}},
+			expectedPools: []string{"static"},
+		},
+		{
+			desc:        "requested pool restricted to other namespaces",
+			namespace:   "ns2",
+			cniArgs:     map[string]interface{}{IPPoolsCNIArg: []interface{}{"red"}},
+			expectError: true,
+		},
+		{
+			desc:        "requested pools of the same subnet",
+			namespace:   "ns1",
+			cniArgs:     map[string]interface{}{IPPoolsCNIArg: []interface{}{"blue", "red"}},
+			expectError: true,
+		},
+		{
+			desc:        "unknown pool",
+			namespace:   "ns1",
+			cniArgs:     map[string]interface{}{IPPoolsCNIArg: []interface{}{"green"}},
+			expectError: true,
+		},
+		{
+			desc:        "invalid CNI argument",
+			namespace:   "ns1",
+			cniArgs:     map[string]interface{}{IPPoolsCNIArg: "blue"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: tc.namespace}}
+			network := &nadv1.NetworkSelectionElement{Name: "tenantred"}
+			if tc.cniArgs != nil {
+				network.CNIArgs = &tc.cniArgs
+			}
+			pools, err := GetPodIPPools(netInfo, pod, network)
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(pools).To(gomega.Equal(tc.expectedPools))
+		})
+	}
+}
+
+func TestNewNetInfoVLANTrunk(t *testing.T) {
+	tests := []struct {
+		desc           string
+		topology       string
+		vlanID         int
+		vlanTrunk      string
+		expectedRanges []VLANRange
+		expectError    bool
+	}{
+		{
+			desc:           "VLAN IDs and ranges",
+			topology:       types.LocalnetTopology,
+			vlanTrunk:      "100, 200-299,4094",
+			expectedRanges: []VLANRange{{Start: 100, End: 100}, {Start: 200, End: 299}, {Start: 4094, End: 4094}},
+		},
+		{
+			desc:     "no trunk",
+			topology: types.LocalnetTopology,
+			vlanID:   10,
+		},
+		{
+			desc:        "trunk along with a VLAN ID",
+			topology:    types.LocalnetTopology,
+			vlanID:      10,
+			vlanTrunk:   "100",
+			expectError: true,
+		},
+		{
+			desc:        "overlapping ranges",
+			topology:    types.LocalnetTopology,
+			vlanTrunk:   "200-299,250",
+			expectError: true,
+		},
+		{
+			desc:        "range start after range end",
+			topology:    types.LocalnetTopology,
+			vlanTrunk:   "299-200",
+			expectError: true,
+		},
+		{
+			desc:        "VLAN ID out of range",
+			topology:    types.LocalnetTopology,
+			vlanTrunk:   "4095",
+			expectError: true,
+		},
+		{
+			desc:        "invalid VLAN ID",
+			topology:    types.LocalnetTopology,
+			vlanTrunk:   "100,blue",
+			expectError: true,
+		},
+		{
+			desc:        "layer 2 topology",
+			topology:    types.Layer2Topology,
+			vlanTrunk:   "100",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:   cnitypes.NetConf{Name: "tenantred"},
+				Topology:  tc.topology,
+				Subnets:   "192.168.1.0/24",
+				VLANID:    tc.vlanID,
+				VLANTrunk: tc.vlanTrunk,
+			})
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(netInfo.VLANTrunk()).To(gomega.Equal(tc.expectedRanges))
+		})
+	}
+}
+
+func TestGetPodVLANID(t *testing.T) {
+	newNetInfo := func(vlanTrunk string) NetInfo {
+		netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+			NetConf:   cnitypes.NetConf{Name: "tenantred"},
+			Topology:  types.LocalnetTopology,
+			VLANTrunk: vlanTrunk,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return netInfo
+	}
+	tests := []struct {
+		desc           string
+		vlanTrunk      string
+		cniArgs        map[string]interface{}
+		expectedVLANID uint
+		expectError    bool
+	}{
+		{
+			desc:      "untagged by default",
+			vlanTrunk: "100,200-299",
+		},
+		{
+			desc:           "VLAN ID of the trunk",
+			vlanTrunk:      "100,200-299",
+			cniArgs:        map[string]interface{}{VLANIDCNIArg: float64(250)},
+			expectedVLANID: 250,
+		},
+		{
+			desc:        "VLAN ID out of the trunk",
+			vlanTrunk:   "100,200-299",
+			cniArgs:     map[string]interface{}{VLANIDCNIArg: float64(300)},
+			expectError: true,
+		},
+		{
+			desc:        "invalid VLAN ID",
+			vlanTrunk:   "100,200-299",
+			cniArgs:     map[string]interface{}{VLANIDCNIArg: "100"},
+			expectError: true,
+		},
+		{
+			desc:        "not a VLAN trunk",
+			cniArgs:     map[string]interface{}{VLANIDCNIArg: float64(100)},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			network := &nadv1.NetworkSelectionElement{Name: "tenantred"}
+			if tc.cniArgs != nil {
+				network.CNIArgs = &tc.cniArgs
+			}
+			vlanID, err := GetPodVLANID(newNetInfo(tc.vlanTrunk), network)
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(vlanID).To(gomega.Equal(tc.expectedVLANID))
+		})
+	}
+}
+
+func TestGetSecondaryNetworkGatewayMasqueradeIPs(t *testing.T) {
+	tests := []struct {
+		desc        string
+		ipv4Mode    bool
+		ipv6Mode    bool
+		networkID   int
+		expectedIPs []*net.IPNet
+		expectError bool
+	}{
+		{
+			desc:        "dual stack",
+			ipv4Mode:    true,
+			ipv6Mode:    true,
+			networkID:   2,
+			expectedIPs: ovntest.MustParseIPNets("169.254.0.3/17", "fd69::1:3/112"),
+		},
+		{
+			desc:        "single stack IPv6",
+			ipv6Mode:    true,
+			networkID:   1,
+			expectedIPs: ovntest.MustParseIPNets("fd69::1:2/112"),
+		},
+		{
+			desc:        "invalid network ID",
+			ipv4Mode:    true,
+			networkID:   0,
+			expectError: true,
+		},
+		{
+			desc:        "exhausted subnet",
+			ipv4Mode:    true,
+			ipv6Mode:    true,
+			networkID:   65534,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			config.IPv4Mode = tc.ipv4Mode
+			config.IPv6Mode = tc.ipv6Mode
+			ips, err := GetSecondaryNetworkGatewayMasqueradeIPs(tc.networkID)
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(ips).To(gomega.Equal(tc.expectedIPs))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+)
+
+// VLANIDCNIArg is the CNI argument of the network selection element of a pod
+// selecting the VLAN its interface on a trunk localnet network is tagged with
+const VLANIDCNIArg = "vlanID"
+
+const (
+	minVLANID = 1
+	maxVLANID = 4094
+)
+
+// VLANRange is a range of VLAN IDs, both ends included
+type VLANRange struct {
+	Start uint
+	End   uint
+}
+
+// Contains returns whether the VLAN ID is in the range
+func (r VLANRange) Contains(vlanID uint) bool {
+	return vlanID >= r.Start && vlanID <= r.End
+}
+
+// String returns the range in the format of the VLAN trunk configuration
+func (r VLANRange) String() string {
+	if r.Start == r.End {
+		return strconv.FormatUint(uint64(r.Start), 10)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// GetPodVLANID returns the VLAN a pod tags its traffic with on a trunk
+// localnet network, as requested with the vlanID CNI argument of its network
+// selection element. Zero means the pod interface is not tagged.
+func GetPodVLANID(netInfo NetInfo, network *nadapi.NetworkSelectionElement) (uint, error) {
+	if network == nil || network.CNIArgs == nil {
+		return 0, nil
+	}
+	arg, ok := (*network.CNIArgs)[VLANIDCNIArg]
+	if !ok {
+		return 0, nil
+	}
+	trunk := netInfo.VLANTrunk()
+	if len(trunk) == 0 {
+		return 0, fmt.Errorf("invalid %s CNI argument: network %s is not a VLAN trunk", VLANIDCNIArg, netInfo.GetNetworkName())
+	}
+	// numbers are unmarshalled from the JSON annotation as float64
+	value, ok := arg.(float64)
+	if !ok || value != float64(uint(value)) || value < minVLANID || value > maxVLANID {
+		return 0, fmt.Errorf("invalid %s CNI argument %v: expected a VLAN ID between %d and %d",
+			VLANIDCNIArg, arg, minVLANID, maxVLANID)
+	}
+	vlanID := uint(value)
+	for _, r := range trunk {
+		if r.Contains(vlanID) {
+			return vlanID, nil
+		}
+	}
+	return 0, fmt.Errorf("VLAN %d is not allowed on the trunk of network %s", vlanID, netInfo.GetNetworkName())
+}
+
+// parseVLANTrunk parses the comma-seperated list of VLAN IDs and ranges of
+// VLAN IDs of a trunk, which must not overlap
+func parseVLANTrunk(trunk string) ([]VLANRange, error) {
+	if strings.TrimSpace(trunk) == "" {
+		return nil, nil
+	}
+	var ranges []VLANRange
+	for _, item := range strings.Split(trunk, ",") {
+		item = strings.TrimSpace(item)
+		start, end, isRange := strings.Cut(item, "-")
+		r := VLANRange{}
+		var err error
+		if r.Start, err = parseVLANID(start); err != nil {
+			return nil, fmt.Errorf("invalid VLAN trunk %q: %v", trunk, err)
+		}
+		r.End = r.Start
+		if isRange {
+			if r.End, err = parseVLANID(end); err != nil {
+				return nil, fmt.Errorf("invalid VLAN trunk %q: %v", trunk, err)
+			}
+		}
+		if r.Start > r.End {
+			return nil, fmt.Errorf("invalid VLAN trunk %q: %d is after %d", trunk, r.Start, r.End)
+		}
+		for _, other := range ranges {
+			if other.Contains(r.Start) || other.Contains(r.End) || r.Contains(other.Start) {
+				return nil, fmt.Errorf("invalid VLAN trunk %q: %s overlaps with %s", trunk, r, other)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func parseVLANID(vlanID string) (uint, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(vlanID), 10, 16)
+	if err != nil || id < minVLANID || id > maxVLANID {
+		return 0, fmt.Errorf("invalid VLAN ID %q", vlanID)
+	}
+	return uint(id), nil
+}
@@ -63,6 +63,17 @@ func GetNodeHybridOverlayIfAddr(subnet *net.IPNet) *net.IPNet {
 	return &net.IPNet{IP: iputils.NextIP(mgmtIfAddr.IP), Mask: subnet.Mask}
 }
 
+// GetNodeLBHealthCheckIfAddr returns the node logical switch address used as
+// source of the OVN load balancer health checks (the ".4" address), return nil
+// if the subnet is invalid
+func GetNodeLBHealthCheckIfAddr(subnet *net.IPNet) *net.IPNet {
+	hybridOverlayIfAddr := GetNodeHybridOverlayIfAddr(subnet)
+	if hybridOverlayIfAddr == nil {
+		return nil
+	}
+	return &net.IPNet{IP: iputils.NextIP(hybridOverlayIfAddr.IP), Mask: subnet.Mask}
+}
+
 // IsNodeHybridOverlayIfAddr returns whether the provided IP is a node hybrid
 // overlay address on any of the provided subnets
 func IsNodeHybridOverlayIfAddr(ip net.IP, subnets []*net.IPNet) bool {
@@ -26,6 +26,7 @@ type NetLinkOps interface {
 	LinkByName(ifaceName string) (netlink.Link, error)
 	LinkByIndex(index int) (netlink.Link, error)
 	LinkSetDown(link netlink.Link) error
+	LinkAdd(link netlink.Link) error
 	LinkDelete(link netlink.Link) error
 	LinkSetName(link netlink.Link, newName string) error
 	LinkSetUp(link netlink.Link) error
@@ -47,6 +48,7 @@ type NetLinkOps interface {
 	NeighDel(neigh *netlink.Neigh) error
 	NeighList(linkIndex, family int) ([]netlink.Neigh, error)
 	ConntrackDeleteFilter(table netlink.ConntrackTableType, family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error)
+	ConntrackTableList(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error)
 }
 
 type defaultNetLinkOps struct {
@@ -85,6 +87,10 @@ func (defaultNetLinkOps) LinkSetDown(link netlink.Link) error {
 	return netlink.LinkSetDown(link)
 }
 
+func (defaultNetLinkOps) LinkAdd(link netlink.Link) error {
+	return netlink.LinkAdd(link)
+}
+
 func (defaultNetLinkOps) LinkDelete(link netlink.Link) error {
 	return netlink.LinkDel(link)
 }
@@ -169,6 +175,10 @@ func (defaultNetLinkOps) ConntrackDeleteFilter(table netlink.ConntrackTableType,
 	return netlink.ConntrackDeleteFilter(table, family, filter)
 }
 
+func (defaultNetLinkOps) ConntrackTableList(table netlink.ConntrackTableType, family netlink.InetFamily) ([]*netlink.ConntrackFlow, error) {
+	return netlink.ConntrackTableList(table, family)
+}
+
 func getFamily(ip net.IP) int {
 	if utilnet.IsIPv6(ip) {
 		return netlink.FAMILY_V6
@@ -395,45 +405,57 @@ func LinkNeighExists(link netlink.Link, neighIP net.IP, neighMAC net.HardwareAdd
 	return false, nil
 }
 
-func DeleteConntrack(ip string, port int32, protocol kapi.Protocol, ipFilterType netlink.ConntrackFilterType, labels [][]byte) error {
+// NewConntrackFilter returns a conntrack filter matching the entries of the given
+// protocol with the given IP and, if not zero, destination port. The entries with
+// one of the given labels are left out.
+func NewConntrackFilter(ip string, port int32, protocol kapi.Protocol, ipFilterType netlink.ConntrackFilterType,
+	labels [][]byte) (*netlink.ConntrackFilter, error) {
 	ipAddress := net.ParseIP(ip)
 	if ipAddress == nil {
-		return fmt.Errorf("value %q passed to DeleteConntrack is not an IP address", ipAddress)
+		return nil, fmt.Errorf("value %q passed to the conntrack filter is not an IP address", ip)
 	}
 
 	filter := &netlink.ConntrackFilter{}
 	if protocol == kapi.ProtocolUDP {
 		// 17 = UDP protocol
 		if err := filter.AddProtocol(17); err != nil {
-			return fmt.Errorf("could not add Protocol UDP to conntrack filter %v", err)
+			return nil, fmt.Errorf("could not add Protocol UDP to conntrack filter %v", err)
 		}
 	} else if protocol == kapi.ProtocolSCTP {
 		// 132 = SCTP protocol
 		if err := filter.AddProtocol(132); err != nil {
-			return fmt.Errorf("could not add Protocol SCTP to conntrack filter %v", err)
+			return nil, fmt.Errorf("could not add Protocol SCTP to conntrack filter %v", err)
 		}
 	} else if protocol == kapi.ProtocolTCP {
 		// 6 = TCP protocol
 		if err := filter.AddProtocol(6); err != nil {
-			return fmt.Errorf("could not add Protocol TCP to conntrack filter %v", err)
+			return nil, fmt.Errorf("could not add Protocol TCP to conntrack filter %v", err)
 		}
 	}
 	if port > 0 {
 		if err := filter.AddPort(netlink.ConntrackOrigDstPort, uint16(port)); err != nil {
-			return fmt.Errorf("could not add port %d to conntrack filter: %v", port, err)
+			return nil, fmt.Errorf("could not add port %d to conntrack filter: %v", port, err)
 		}
 	}
 	if err := filter.AddIP(ipFilterType, ipAddress); err != nil {
-		return fmt.Errorf("could not add IP: %s to conntrack filter: %v", ipAddress, err)
+		return nil, fmt.Errorf("could not add IP: %s to conntrack filter: %v", ipAddress, err)
 	}
 
 	if len(labels) > 0 {
 		// for now we only need unmatch label, we can add match label later if needed
 		if err := filter.AddLabels(netlink.ConntrackUnmatchLabels, labels); err != nil {
-			return fmt.Errorf("could not add label %s to conntrack filter: %v", labels, err)
+			return nil, fmt.Errorf("could not add label %s to conntrack filter: %v", labels, err)
 		}
 	}
-	if ipAddress.To4() != nil {
+	return filter, nil
+}
+
+func DeleteConntrack(ip string, port int32, protocol kapi.Protocol, ipFilterType netlink.ConntrackFilterType, labels [][]byte) error {
+	filter, err := NewConntrackFilter(ip, port, protocol, ipFilterType, labels)
+	if err != nil {
+		return err
+	}
+	if utilnet.IsIPv4String(ip) {
 		if _, err := netLinkOps.ConntrackDeleteFilter(netlink.ConntrackTable, netlink.FAMILY_V4, filter); err != nil {
 			return err
 		}
@@ -489,19 +511,33 @@ func GetNetworkInterfaceIPs(iface string) ([]*net.IPNet, error) {
 }
 
 func IsAddressReservedForInternalUse(addr net.IP) bool {
-	var subnetStr string
+	var subnetStrs []string
 	if addr.To4() != nil {
-		subnetStr = config.Gateway.V4MasqueradeSubnet
+		subnetStrs = []string{config.Gateway.V4MasqueradeSubnet}
+		if IsSecondaryNetworkGatewaySupportEnabled() {
+			subnetStrs = append(subnetStrs, config.Gateway.V4SecondaryMasqueradeSubnet)
+		}
 	} else {
-		subnetStr = config.Gateway.V6MasqueradeSubnet
+		subnetStrs = []string{config.Gateway.V6MasqueradeSubnet}
+		if IsSecondaryNetworkGatewaySupportEnabled() {
+			subnetStrs = append(subnetStrs, config.Gateway.V6SecondaryMasqueradeSubnet)
+		}
 	}
-	_, subnet, err := net.ParseCIDR(subnetStr)
-	if err != nil {
-		klog.Errorf("Could not determine if %s is in reserved subnet %v: %v",
-			addr, subnetStr, err)
-		return false
+	for _, subnetStr := range subnetStrs {
+		if subnetStr == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(subnetStr)
+		if err != nil {
+			klog.Errorf("Could not determine if %s is in reserved subnet %v: %v",
+				addr, subnetStr, err)
+			return false
+		}
+		if subnet.Contains(addr) {
+			return true
+		}
 	}
-	return subnet.Contains(addr)
+	return false
 }
 
 // IsAddressAddedByKeepAlived returns true if the input interface address obtained
@@ -0,0 +1,1035 @@
+//go:build linux
+// +build linux
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-iptables/iptables"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// NFTablesTable is the name of the nftables table, one per IP family,
+	// holding the host firewall and NAT rules when the nftables backend is used
+	NFTablesTable = "ovn-kubernetes"
+
+	nftCommand = "nft"
+	// nftCommentMaxLen is the maximum length of a rule comment supported by nft
+	nftCommentMaxLen = 128
+	// nftProbabilityModulus is the modulus of the random number generator
+	// used to translate the iptables statistic match
+	nftProbabilityModulus = 1000000
+)
+
+// nftBaseChains maps the iptables builtin chains of each supported table to
+// the definition of the nftables base chain emulating them
+var nftBaseChains = map[string]map[string]string{
+	"nat": {
+		"PREROUTING":  "type nat hook prerouting priority -100",
+		"INPUT":       "type nat hook input priority 100",
+		"OUTPUT":      "type nat hook output priority -100",
+		"POSTROUTING": "type nat hook postrouting priority 100",
+	},
+	"filter": {
+		"INPUT":   "type filter hook input priority 0",
+		"FORWARD": "type filter hook forward priority 0",
+		"OUTPUT":  "type filter hook output priority 0",
+	},
+	"mangle": {
+		"PREROUTING":  "type filter hook prerouting priority -150",
+		"INPUT":       "type filter hook input priority -150",
+		"FORWARD":     "type filter hook forward priority -150",
+		"OUTPUT":      "type route hook output priority -150",
+		"POSTROUTING": "type filter hook postrouting priority -150",
+	},
+}
+
+// IPTablesChainReplacer is implemented by the IPTablesHelpers that are able
+// to replace all the rules of a chain in a single operation
+type IPTablesChainReplacer interface {
+	// ReplaceChain replaces the rules in the specified table/chain with the
+	// given rulespecs. If the chain does not exist, a new one will be created
+	ReplaceChain(table, chain string, rulespecs [][]string) error
+}
+
+// NFTablesRunner runs nft commands, allowing mock implementations for unit testing
+type NFTablesRunner interface {
+	// Run applies the given nft script as a single transaction
+	Run(script string) error
+	// ListTable returns the JSON listing of the given table, or nil if the
+	// table does not exist
+	ListTable(family, table string) ([]byte, error)
+}
+
+type nftRunner struct {
+	path string
+}
+
+// NewNFTablesRunner returns a NFTablesRunner running the nft binary found in PATH
+func NewNFTablesRunner() (NFTablesRunner, error) {
+	if runner == nil {
+		return nil, fmt.Errorf("exec helper has not been initialized")
+	}
+	path, err := runner.exec.LookPath(nftCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s: %v", nftCommand, err)
+	}
+	return &nftRunner{path: path}, nil
+}
+
+func (r *nftRunner) Run(script string) error {
+	args := []string{"-f", "-"}
+	stdin := &bytes.Buffer{}
+	stdin.WriteString(script)
+
+	cmd := runner.exec.Command(r.path, args...)
+	cmd.SetStdin(stdin)
+	_, stderr, err := runCmd(cmd, r.path, args...)
+	if err != nil {
+		return fmt.Errorf("failed to apply nft script %q, stderr: %q: %v", script, stderr, err)
+	}
+	return nil
+}
+
+func (r *nftRunner) ListTable(family, table string) ([]byte, error) {
+	stdout, stderr, err := run(r.path, "-j", "list", "table", family, table)
+	if err != nil {
+		if strings.Contains(stderr.String(), "No such file or directory") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list nft table %s %s, stderr: %q: %v", family, table, stderr, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// DeleteNFTablesTables removes the tables programmed by the nftables backend.
+// It is a no-op if the nft binary is not available on the host.
+func DeleteNFTablesTables() error {
+	if runner == nil {
+		return fmt.Errorf("exec helper has not been initialized")
+	}
+	path, err := runner.exec.LookPath(nftCommand)
+	if err != nil {
+		klog.V(5).Infof("Skipping removal of nftables tables: %v", err)
+		return nil
+	}
+	var script []string
+	for _, family := range []string{"ip", "ip6"} {
+		// adding the table first makes the deletion succeed whether it
+		// existed or not
+		script = append(script,
+			fmt.Sprintf("add table %s %s", family, NFTablesTable),
+			fmt.Sprintf("delete table %s %s", family, NFTablesTable))
+	}
+	return (&nftRunner{path: path}).Run(strings.Join(script, "\n") + "\n")
+}
+
+// nftDNATKind identifies the map a service DNAT rule is stored in
+type nftDNATKind int
+
+const (
+	// nftNodePortDNAT are DNAT rules matching a port of any local address
+	nftNodePortDNAT nftDNATKind = iota
+	// nftVIPDNAT are DNAT rules matching a port of a given address
+	nftVIPDNAT
+)
+
+var nftDNATKinds = []nftDNATKind{nftNodePortDNAT, nftVIPDNAT}
+
+// nftDNAT is a service DNAT rule stored as an element of a map
+type nftDNAT struct {
+	kind nftDNATKind
+	// key is the element key, e.g. "tcp . 30080" or "10.0.0.1 . tcp . 80"
+	key string
+	// value is the element value, e.g. "10.96.0.10 . 80"
+	value string
+	spec  []string
+}
+
+// nftRule is a rule of a chain along with its nft translation
+type nftRule struct {
+	spec  []string
+	expr  string
+	jumps []string
+}
+
+// nftChain holds the rules of a chain. Service DNAT rules are kept apart as
+// they are programmed as map elements looked up at the top of the chain.
+type nftChain struct {
+	table string
+	name  string
+	rules []nftRule
+	dnats []nftDNAT
+	// maps tracks the maps that have been created for this chain
+	maps map[nftDNATKind]bool
+}
+
+func (c *nftChain) builtin() bool {
+	_, ok := nftBaseChains[c.table][c.name]
+	return ok
+}
+
+// elements returns, for each map, the elements in effect. When several rules
+// share a key the first one wins, as it would in an iptables chain.
+func (c *nftChain) elements() map[nftDNATKind]map[string]string {
+	elements := map[nftDNATKind]map[string]string{}
+	for _, kind := range nftDNATKinds {
+		elements[kind] = map[string]string{}
+	}
+	for _, d := range c.dnats {
+		if _, ok := elements[d.kind][d.key]; !ok {
+			elements[d.kind][d.key] = d.value
+		}
+	}
+	return elements
+}
+
+// nftHelper implements IPTablesHelper on top of nftables. Each iptables chain
+// is emulated by a chain named "<table>-<chain>" in the NFTablesTable table of
+// the helper's family. Rules are rendered with their iptables rulespec as
+// comment, which allows rebuilding the helper state after a restart.
+type nftHelper struct {
+	sync.Mutex
+	family string
+	runner NFTablesRunner
+	loaded bool
+	chains map[string]*nftChain
+}
+
+// NewNFTablesHelper returns an IPTablesHelper programming nftables rules for
+// the given protocol
+func NewNFTablesHelper(proto iptables.Protocol) (IPTablesHelper, error) {
+	r, err := NewNFTablesRunner()
+	if err != nil {
+		return nil, err
+	}
+	return newNFTablesHelper(proto, r), nil
+}
+
+func newNFTablesHelper(proto iptables.Protocol, r NFTablesRunner) *nftHelper {
+	family := "ip"
+	if proto == iptables.ProtocolIPv6 {
+		family = "ip6"
+	}
+	return &nftHelper{
+		family: family,
+		runner: r,
+		chains: map[string]*nftChain{},
+	}
+}
+
+func nftChainName(table, chain string) string {
+	return table + "-" + chain
+}
+
+func nftMapName(chain string, kind nftDNATKind) string {
+	if kind == nftNodePortDNAT {
+		return chain + "-nodeports"
+	}
+	return chain + "-vips"
+}
+
+func (h *nftHelper) addrType() string {
+	if h.family == "ip6" {
+		return "ipv6_addr"
+	}
+	return "ipv4_addr"
+}
+
+// getChain returns the given chain. Builtin chains are created on demand,
+// other chains are only created if create is set.
+func (h *nftHelper) getChain(table, chain string, create bool) (*nftChain, error) {
+	if _, ok := nftBaseChains[table]; !ok {
+		return nil, fmt.Errorf("table %s is not supported by the nftables backend", table)
+	}
+	name := nftChainName(table, chain)
+	if c, ok := h.chains[name]; ok {
+		return c, nil
+	}
+	if _, builtin := nftBaseChains[table][chain]; !builtin && !create {
+		return nil, fmt.Errorf("chain %s does not exist in table %s", chain, table)
+	}
+	c := &nftChain{table: table, name: chain, maps: map[nftDNATKind]bool{}}
+	h.chains[name] = c
+	return c, nil
+}
+
+func (h *nftHelper) chainDefinition(c *nftChain) string {
+	definition := fmt.Sprintf("add chain %s %s %s", h.family, NFTablesTable, nftChainName(c.table, c.name))
+	if spec, ok := nftBaseChains[c.table][c.name]; ok {
+		definition += " { " + spec + " ; }"
+	}
+	return definition
+}
+
+func (h *nftHelper) tableDefinition() string {
+	return fmt.Sprintf("add table %s %s", h.family, NFTablesTable)
+}
+
+// render returns the script rewriting the whole chain
+func (h *nftHelper) render(c *nftChain) []string {
+	name := nftChainName(c.table, c.name)
+	script := []string{h.tableDefinition(), h.chainDefinition(c)}
+	for _, r := range c.rules {
+		for _, jump := range r.jumps {
+			target, _ := h.getChain(c.table, jump, true)
+			script = append(script, h.chainDefinition(target))
+		}
+	}
+	script = append(script, fmt.Sprintf("flush chain %s %s %s", h.family, NFTablesTable, name))
+
+	elements := c.elements()
+	var lookups []string
+	for _, kind := range nftDNATKinds {
+		if len(elements[kind]) == 0 && !c.maps[kind] {
+			continue
+		}
+		mapName := nftMapName(name, kind)
+		keyType := "inet_proto . inet_service"
+		lookup := fmt.Sprintf("fib daddr type local dnat %s to meta l4proto . th dport map @%s", h.family, mapName)
+		if kind == nftVIPDNAT {
+			keyType = h.addrType() + " . " + keyType
+			lookup = fmt.Sprintf("dnat %s to %s daddr . meta l4proto . th dport map @%s", h.family, h.family, mapName)
+		}
+		script = append(script,
+			fmt.Sprintf("add map %s %s %s { type %s : %s . inet_service ; }", h.family, NFTablesTable, mapName, keyType, h.addrType()),
+			fmt.Sprintf("flush map %s %s %s", h.family, NFTablesTable, mapName))
+		if len(elements[kind]) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(elements[kind]))
+		for key := range elements[kind] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, 0, len(keys))
+		for _, key := range keys {
+			entries = append(entries, key+" : "+elements[kind][key])
+		}
+		script = append(script, fmt.Sprintf("add element %s %s %s { %s }", h.family, NFTablesTable, mapName, strings.Join(entries, ", ")))
+		lookups = append(lookups, lookup)
+	}
+	for _, lookup := range lookups {
+		script = append(script, fmt.Sprintf("add rule %s %s %s %s", h.family, NFTablesTable, name, lookup))
+	}
+	for _, r := range c.rules {
+		rule := fmt.Sprintf("add rule %s %s %s %s", h.family, NFTablesTable, name, r.expr)
+		if comment, ok := nftRuleComment(r.spec); ok {
+			rule += fmt.Sprintf(" comment %q", comment)
+		}
+		script = append(script, rule)
+	}
+	return script
+}
+
+func (h *nftHelper) run(script []string) error {
+	return h.runner.Run(strings.Join(script, "\n") + "\n")
+}
+
+// update applies mutate to the chain and programs the result. Changes to the
+// service DNAT rules only are programmed as incremental map updates unless a
+// map becomes empty or non-empty. The chain is restored if programming fails.
+func (h *nftHelper) update(c *nftChain, dnatOnly bool, mutate func()) error {
+	rules := append([]nftRule(nil), c.rules...)
+	dnats := append([]nftDNAT(nil), c.dnats...)
+	before := c.elements()
+	mutate()
+	after := c.elements()
+
+	full := !dnatOnly
+	var script []string
+	for _, kind := range nftDNATKinds {
+		if (len(before[kind]) == 0) != (len(after[kind]) == 0) {
+			full = true
+			break
+		}
+		mapName := nftMapName(nftChainName(c.table, c.name), kind)
+		for key, value := range before[kind] {
+			if after[kind][key] != value {
+				script = append(script, fmt.Sprintf("delete element %s %s %s { %s }", h.family, NFTablesTable, mapName, key))
+			}
+		}
+		for key, value := range after[kind] {
+			if before[kind][key] != value {
+				script = append(script, fmt.Sprintf("add element %s %s %s { %s : %s }", h.family, NFTablesTable, mapName, key, value))
+			}
+		}
+	}
+	if full {
+		script = h.render(c)
+	}
+	if len(script) == 0 {
+		return nil
+	}
+	if err := h.run(script); err != nil {
+		c.rules, c.dnats = rules, dnats
+		return err
+	}
+	for _, kind := range nftDNATKinds {
+		if len(after[kind]) > 0 {
+			c.maps[kind] = true
+		}
+	}
+	return nil
+}
+
+// ensureLoaded rebuilds the helper state from the rules found in nftables the
+// first time the helper is used
+func (h *nftHelper) ensureLoaded() error {
+	if h.loaded {
+		return nil
+	}
+	listing, err := h.runner.ListTable(h.family, NFTablesTable)
+	if err != nil {
+		return err
+	}
+	if len(listing) > 0 {
+		if err := h.load(listing); err != nil {
+			return fmt.Errorf("failed to load nftables table %s %s: %v", h.family, NFTablesTable, err)
+		}
+	}
+	h.loaded = true
+	return nil
+}
+
+type nftJSONConcat struct {
+	Concat []interface{} `json:"concat"`
+}
+
+type nftJSONObject struct {
+	Chain *struct {
+		Name string `json:"name"`
+	} `json:"chain"`
+	Map *struct {
+		Name string            `json:"name"`
+		Elem [][]nftJSONConcat `json:"elem"`
+	} `json:"map"`
+	Rule *struct {
+		Chain   string `json:"chain"`
+		Comment string `json:"comment"`
+	} `json:"rule"`
+}
+
+type nftJSONListing struct {
+	Nftables []nftJSONObject `json:"nftables"`
+}
+
+func (h *nftHelper) load(listing []byte) error {
+	var objects nftJSONListing
+	if err := json.Unmarshal(listing, &objects); err != nil {
+		return err
+	}
+	lookupChain := func(name string) *nftChain {
+		table, chain, ok := strings.Cut(name, "-")
+		if !ok {
+			return nil
+		}
+		c, err := h.getChain(table, chain, true)
+		if err != nil {
+			return nil
+		}
+		return c
+	}
+	for _, object := range objects.Nftables {
+		if object.Chain != nil {
+			lookupChain(object.Chain.Name)
+		}
+	}
+	for _, object := range objects.Nftables {
+		if object.Map == nil {
+			continue
+		}
+		kind := nftVIPDNAT
+		chainName, ok := strings.CutSuffix(object.Map.Name, "-vips")
+		if !ok {
+			kind = nftNodePortDNAT
+			if chainName, ok = strings.CutSuffix(object.Map.Name, "-nodeports"); !ok {
+				continue
+			}
+		}
+		c := lookupChain(chainName)
+		if c == nil {
+			continue
+		}
+		c.maps[kind] = true
+		for _, elem := range object.Map.Elem {
+			if len(elem) != 2 {
+				continue
+			}
+			if d := nftDNATFromElement(kind, elem[0].values(), elem[1].values()); d != nil {
+				c.dnats = append(c.dnats, *d)
+			}
+		}
+	}
+	for _, object := range objects.Nftables {
+		if object.Rule == nil || object.Rule.Comment == "" {
+			continue
+		}
+		c := lookupChain(object.Rule.Chain)
+		if c == nil {
+			continue
+		}
+		spec := splitNFTRuleComment(object.Rule.Comment)
+		r, err := translateNFTRule(h.family, c.table, spec)
+		if err != nil {
+			klog.Warningf("Ignoring nftables rule %q of chain %s: %v", object.Rule.Comment, object.Rule.Chain, err)
+			continue
+		}
+		c.rules = append(c.rules, *r)
+	}
+	return nil
+}
+
+func (c nftJSONConcat) values() []string {
+	values := make([]string, 0, len(c.Concat))
+	for _, v := range c.Concat {
+		switch v := v.(type) {
+		case float64:
+			values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+	return values
+}
+
+var nftProtocolNumbers = map[string]string{"6": "tcp", "17": "udp", "132": "sctp"}
+
+// nftDNATFromElement rebuilds the service DNAT rule stored as the given map element
+func nftDNATFromElement(kind nftDNATKind, key, value []string) *nftDNAT {
+	if len(value) != 2 {
+		return nil
+	}
+	var spec []string
+	switch {
+	case kind == nftNodePortDNAT && len(key) == 2:
+		spec = []string{"-p", "", "-m", "addrtype", "--dst-type", "LOCAL", "--dport", key[1]}
+	case kind == nftVIPDNAT && len(key) == 3:
+		spec = []string{"-p", "", "-d", key[0], "--dport", key[2]}
+	default:
+		return nil
+	}
+	proto := key[len(key)-2]
+	if name, ok := nftProtocolNumbers[proto]; ok {
+		proto = name
+	}
+	spec[1] = strings.ToUpper(proto)
+	spec = append(spec, "-j", "DNAT", "--to-destination", net.JoinHostPort(value[0], value[1]))
+	d, _ := parseNFTServiceDNAT(spec)
+	return d
+}
+
+// parseNFTServiceDNAT returns the map element for the given rulespec if it is
+// a service DNAT rule, as programmed for NodePort, ExternalIP and LoadBalancer
+// services
+func parseNFTServiceDNAT(spec []string) (*nftDNAT, bool) {
+	var kind nftDNATKind
+	var vip, port, destination string
+	switch {
+	case len(spec) == 12 && spec[0] == "-p" && spec[2] == "-m" && spec[3] == "addrtype" &&
+		spec[4] == "--dst-type" && spec[5] == "LOCAL" && spec[6] == "--dport" &&
+		spec[8] == "-j" && spec[9] == "DNAT" && spec[10] == "--to-destination":
+		kind, port, destination = nftNodePortDNAT, spec[7], spec[11]
+	case len(spec) == 10 && spec[0] == "-p" && spec[2] == "-d" && spec[4] == "--dport" &&
+		spec[6] == "-j" && spec[7] == "DNAT" && spec[8] == "--to-destination":
+		kind, vip, port, destination = nftVIPDNAT, spec[3], spec[5], spec[9]
+	default:
+		return nil, false
+	}
+	proto := strings.ToLower(spec[1])
+	if proto != "tcp" && proto != "udp" && proto != "sctp" {
+		return nil, false
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return nil, false
+	}
+	host, targetPort, err := net.SplitHostPort(destination)
+	if err != nil {
+		return nil, false
+	}
+	hostIP := net.ParseIP(host)
+	if hostIP == nil {
+		return nil, false
+	}
+	if p, err := strconv.Atoi(targetPort); err != nil || p < 1 || p > 65535 {
+		return nil, false
+	}
+	key := proto + " . " + port
+	if kind == nftVIPDNAT {
+		vipIP := net.ParseIP(vip)
+		if vipIP == nil || (vipIP.To4() == nil) != (hostIP.To4() == nil) {
+			return nil, false
+		}
+		key = vipIP.String() + " . " + key
+	}
+	return &nftDNAT{
+		kind:  kind,
+		key:   key,
+		value: hostIP.String() + " . " + targetPort,
+		spec:  append([]string(nil), spec...),
+	}, true
+}
+
+// nftRuleComment returns the comment storing the given rulespec, if it fits
+func nftRuleComment(spec []string) (string, bool) {
+	tokens := make([]string, 0, len(spec))
+	for _, token := range spec {
+		if strings.ContainsAny(token, "'\"") {
+			return "", false
+		}
+		if token == "" || strings.ContainsAny(token, " \t") {
+			token = "'" + token + "'"
+		}
+		tokens = append(tokens, token)
+	}
+	comment := strings.Join(tokens, " ")
+	if len(comment) > nftCommentMaxLen {
+		return "", false
+	}
+	return comment, true
+}
+
+// splitNFTRuleComment returns the rulespec stored in the given comment
+func splitNFTRuleComment(comment string) []string {
+	var spec []string
+	var token strings.Builder
+	quoted, started := false, false
+	for _, r := range comment {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			started = true
+		case (r == ' ' || r == '\t') && !quoted:
+			if started {
+				spec = append(spec, token.String())
+				token.Reset()
+				started = false
+			}
+		default:
+			token.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		spec = append(spec, token.String())
+	}
+	return spec
+}
+
+// joinRuleSpec joins the rulespec the way iptables lists it
+func joinRuleSpec(spec []string) string {
+	tokens := make([]string, 0, len(spec))
+	for _, token := range spec {
+		if token == "" || strings.ContainsAny(token, " \t") {
+			token = strconv.Quote(token)
+		}
+		tokens = append(tokens, token)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// translateNFTRule translates an iptables rulespec into a nft rule. Only the
+// matches and targets used by ovnkube-node are supported.
+func translateNFTRule(family, table string, spec []string) (*nftRule, error) {
+	var matches, targetArgs []string
+	var target string
+	jumpTarget := ""
+	negate := false
+	for i := 0; i < len(spec); i++ {
+		option := spec[i]
+		if option == "!" {
+			negate = true
+			continue
+		}
+		value := ""
+		switch option {
+		case "--random", "--random-fully", "--persistent":
+		default:
+			if i+1 >= len(spec) {
+				return nil, fmt.Errorf("missing value for option %s in rule %q", option, spec)
+			}
+			i++
+			value = spec[i]
+		}
+		op := ""
+		if negate {
+			op = "!= "
+		}
+		negate = false
+		switch option {
+		case "-p", "--protocol":
+			matches = append(matches, "meta l4proto "+op+strings.ToLower(value))
+		case "-s", "--source":
+			matches = append(matches, family+" saddr "+op+value)
+		case "-d", "--destination":
+			matches = append(matches, family+" daddr "+op+value)
+		case "-i", "--in-interface":
+			matches = append(matches, "iifname "+op+strconv.Quote(value))
+		case "-o", "--out-interface":
+			matches = append(matches, "oifname "+op+strconv.Quote(value))
+		case "--dport", "--destination-port":
+			matches = append(matches, "th dport "+op+strings.Replace(value, ":", "-", 1))
+		case "--sport", "--source-port":
+			matches = append(matches, "th sport "+op+strings.Replace(value, ":", "-", 1))
+		case "-m", "--match":
+			switch value {
+			case "comment", "addrtype", "mark", "statistic", "tcp", "udp", "sctp":
+			default:
+				return nil, fmt.Errorf("unsupported match %s in rule %q", value, spec)
+			}
+		case "--comment":
+			// the whole rulespec is stored as the rule comment
+		case "--dst-type":
+			matches = append(matches, "fib daddr type "+op+strings.ToLower(value))
+		case "--src-type":
+			matches = append(matches, "fib saddr type "+op+strings.ToLower(value))
+		case "--mark":
+			mark, mask, masked := strings.Cut(value, "/")
+			if masked {
+				if op == "" {
+					op = "== "
+				}
+				matches = append(matches, fmt.Sprintf("meta mark & %s %s%s", mask, op, mark))
+			} else {
+				matches = append(matches, "meta mark "+op+mark)
+			}
+		case "--mode":
+			if value != "random" {
+				return nil, fmt.Errorf("unsupported statistic mode %s in rule %q", value, spec)
+			}
+		case "--probability":
+			p, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid probability %s in rule %q: %v", value, spec, err)
+			}
+			matches = append(matches, fmt.Sprintf("numgen random mod %d < %d",
+				nftProbabilityModulus, int64(math.Round(p*nftProbabilityModulus))))
+		case "-j", "--jump":
+			target = value
+		case "--to-destination", "--to-source":
+			targetArgs = append(targetArgs, "to "+value)
+		case "--to-port", "--to-ports":
+			targetArgs = append(targetArgs, "to :"+value)
+		case "--set-mark", "--set-xmark":
+			mark, mask, masked := strings.Cut(value, "/")
+			if masked && mask != "0xffffffff" {
+				return nil, fmt.Errorf("unsupported masked mark %s in rule %q", value, spec)
+			}
+			targetArgs = append(targetArgs, "set "+mark)
+		case "--random":
+			targetArgs = append(targetArgs, "random")
+		case "--random-fully":
+			targetArgs = append(targetArgs, "fully-random")
+		case "--persistent":
+			targetArgs = append(targetArgs, "persistent")
+		default:
+			return nil, fmt.Errorf("unsupported option %s in rule %q", option, spec)
+		}
+	}
+
+	var verdict string
+	switch target {
+	case "":
+	case "ACCEPT", "DROP", "RETURN":
+		verdict = strings.ToLower(target)
+	case "MASQUERADE":
+		verdict = "masquerade"
+	case "DNAT", "SNAT":
+		if len(targetArgs) == 0 || !strings.HasPrefix(targetArgs[0], "to ") {
+			return nil, fmt.Errorf("missing %s address in rule %q", target, spec)
+		}
+		verdict = strings.ToLower(target)
+	case "REDIRECT":
+		verdict = "redirect"
+	case "MARK":
+		verdict = "meta mark"
+	default:
+		verdict = "jump " + nftChainName(table, target)
+		jumpTarget = target
+	}
+	if verdict != "" {
+		matches = append(matches, strings.Join(append([]string{verdict}, targetArgs...), " "))
+	} else if len(targetArgs) > 0 {
+		return nil, fmt.Errorf("missing target in rule %q", spec)
+	}
+
+	r := &nftRule{
+		spec: append([]string(nil), spec...),
+		expr: strings.Join(matches, " "),
+	}
+	if jumpTarget != "" {
+		r.jumps = []string{jumpTarget}
+	}
+	if r.expr == "" {
+		r.expr = "counter"
+	}
+	return r, nil
+}
+
+func ruleSpecEqual(a, b []string) bool {
+	return strings.Join(a, " ") == strings.Join(b, " ")
+}
+
+// List rules in specified table/chain
+func (h *nftHelper) List(table, chain string) ([]string, error) {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	c, err := h.getChain(table, chain, false)
+	if err != nil {
+		return nil, err
+	}
+	rules := []string{"-N " + chain}
+	if c.builtin() {
+		rules = []string{"-P " + chain + " ACCEPT"}
+	}
+	for _, d := range c.dnats {
+		rules = append(rules, fmt.Sprintf("-A %s %s", chain, joinRuleSpec(d.spec)))
+	}
+	for _, r := range c.rules {
+		rules = append(rules, fmt.Sprintf("-A %s %s", chain, joinRuleSpec(r.spec)))
+	}
+	return rules, nil
+}
+
+// ListChains returns the names of all chains in the table
+func (h *nftHelper) ListChains(table string) ([]string, error) {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	builtins, ok := nftBaseChains[table]
+	if !ok {
+		return nil, fmt.Errorf("table %s is not supported by the nftables backend", table)
+	}
+	var chains []string
+	for chain := range builtins {
+		chains = append(chains, chain)
+	}
+	for _, c := range h.chains {
+		if c.table == table && !c.builtin() {
+			chains = append(chains, c.name)
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+// ClearChain removes all rules in the specified table/chain.
+// If the chain does not exist, a new one will be created
+func (h *nftHelper) ClearChain(table, chain string) error {
+	return h.ReplaceChain(table, chain, nil)
+}
+
+// ReplaceChain replaces the rules in the specified table/chain.
+// If the chain does not exist, a new one will be created
+func (h *nftHelper) ReplaceChain(table, chain string, rulespecs [][]string) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return err
+	}
+	var rules []nftRule
+	var dnats []nftDNAT
+	for _, spec := range rulespecs {
+		if table == "nat" {
+			if d, ok := parseNFTServiceDNAT(spec); ok {
+				dnats = append(dnats, *d)
+				continue
+			}
+		}
+		r, err := translateNFTRule(h.family, table, spec)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, *r)
+	}
+	_, exists := h.chains[nftChainName(table, chain)]
+	c, err := h.getChain(table, chain, true)
+	if err != nil {
+		return err
+	}
+	err = h.update(c, false, func() {
+		c.rules, c.dnats = rules, dnats
+	})
+	if err != nil && !exists {
+		delete(h.chains, nftChainName(table, chain))
+	}
+	return err
+}
+
+// DeleteChain deletes the chain in the specified table.
+// The chain must be empty and not referenced
+func (h *nftHelper) DeleteChain(table, chain string) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return err
+	}
+	c, err := h.getChain(table, chain, false)
+	if err != nil {
+		return err
+	}
+	if c.builtin() {
+		return fmt.Errorf("builtin chain %s of table %s can not be deleted", chain, table)
+	}
+	if len(c.rules) > 0 || len(c.dnats) > 0 {
+		return fmt.Errorf("chain %s of table %s must be empty", chain, table)
+	}
+	for _, other := range h.chains {
+		for _, r := range other.rules {
+			for _, jump := range r.jumps {
+				if other.table == table && jump == chain {
+					return fmt.Errorf("chain %s of table %s is referenced by chain %s", chain, table, other.name)
+				}
+			}
+		}
+	}
+	name := nftChainName(table, chain)
+	script := []string{h.tableDefinition(), h.chainDefinition(c),
+		fmt.Sprintf("delete chain %s %s %s", h.family, NFTablesTable, name)}
+	for _, kind := range nftDNATKinds {
+		if c.maps[kind] {
+			script = append(script, fmt.Sprintf("delete map %s %s %s", h.family, NFTablesTable, nftMapName(name, kind)))
+		}
+	}
+	if err := h.run(script); err != nil {
+		return err
+	}
+	delete(h.chains, name)
+	return nil
+}
+
+// NewChain creates a new chain in the specified table.
+// If the chain already exists, it will result in an error.
+func (h *nftHelper) NewChain(table, chain string) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return err
+	}
+	if _, err := h.getChain(table, chain, false); err == nil {
+		return fmt.Errorf("chain %s already exists in table %s", chain, table)
+	}
+	c, err := h.getChain(table, chain, true)
+	if err != nil {
+		return err
+	}
+	if err := h.run([]string{h.tableDefinition(), h.chainDefinition(c)}); err != nil {
+		delete(h.chains, nftChainName(table, chain))
+		return err
+	}
+	return nil
+}
+
+// Exists checks if given rulespec in specified table/chain exists
+func (h *nftHelper) Exists(table, chain string, rulespec ...string) (bool, error) {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return false, err
+	}
+	c, err := h.getChain(table, chain, false)
+	if err != nil {
+		return false, err
+	}
+	for _, d := range c.dnats {
+		if ruleSpecEqual(d.spec, rulespec) {
+			return true, nil
+		}
+	}
+	for _, r := range c.rules {
+		if ruleSpecEqual(r.spec, rulespec) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Insert inserts a rule into the specified table/chain. Service DNAT rules
+// are evaluated before the other rules of the chain, so the position is
+// relative to the rules of the same kind.
+func (h *nftHelper) Insert(table, chain string, pos int, rulespec ...string) error {
+	if pos < 1 {
+		return fmt.Errorf("invalid rule position %d", pos)
+	}
+	return h.insert(table, chain, pos-1, rulespec)
+}
+
+// Append appends rulespec to specified table/chain
+func (h *nftHelper) Append(table, chain string, rulespec ...string) error {
+	return h.insert(table, chain, -1, rulespec)
+}
+
+func (h *nftHelper) insert(table, chain string, index int, rulespec []string) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return err
+	}
+	c, err := h.getChain(table, chain, false)
+	if err != nil {
+		return err
+	}
+	if table == "nat" {
+		if d, ok := parseNFTServiceDNAT(rulespec); ok {
+			return h.update(c, true, func() {
+				if index < 0 || index > len(c.dnats) {
+					index = len(c.dnats)
+				}
+				c.dnats = append(c.dnats[:index], append([]nftDNAT{*d}, c.dnats[index:]...)...)
+			})
+		}
+	}
+	r, err := translateNFTRule(h.family, table, rulespec)
+	if err != nil {
+		return err
+	}
+	return h.update(c, false, func() {
+		if index < 0 || index > len(c.rules) {
+			index = len(c.rules)
+		}
+		c.rules = append(c.rules[:index], append([]nftRule{*r}, c.rules[index:]...)...)
+	})
+}
+
+// Delete removes rulespec in specified table/chain
+func (h *nftHelper) Delete(table, chain string, rulespec ...string) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.ensureLoaded(); err != nil {
+		return err
+	}
+	c, err := h.getChain(table, chain, false)
+	if err != nil {
+		return err
+	}
+	for i, d := range c.dnats {
+		if ruleSpecEqual(d.spec, rulespec) {
+			return h.update(c, true, func() {
+				c.dnats = append(c.dnats[:i:i], c.dnats[i+1:]...)
+			})
+		}
+	}
+	for i, r := range c.rules {
+		if ruleSpecEqual(r.spec, rulespec) {
+			return h.update(c, false, func() {
+				c.rules = append(c.rules[:i:i], c.rules[i+1:]...)
+			})
+		}
+	}
+	return fmt.Errorf("rule %q does not exist in chain %s of table %s", joinRuleSpec(rulespec), chain, table)
+}
@@ -0,0 +1,359 @@
+//go:build linux
+// +build linux
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-iptables/iptables"
+)
+
+type fakeNFTablesRunner struct {
+	scripts []string
+	listing []byte
+	err     error
+}
+
+func (f *fakeNFTablesRunner) Run(script string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.scripts = append(f.scripts, script)
+	return nil
+}
+
+func (f *fakeNFTablesRunner) ListTable(family, table string) ([]byte, error) {
+	return f.listing, nil
+}
+
+func (f *fakeNFTablesRunner) lastScript() []string {
+	if len(f.scripts) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(f.scripts[len(f.scripts)-1]), "\n")
+}
+
+func TestTranslateNFTRule(t *testing.T) {
+	tests := []struct {
+		desc   string
+		family string
+		table  string
+		spec   []string
+		expr   string
+		jumps  []string
+		errMsg string
+	}{
+		{
+			desc:   "jump to a chain",
+			family: "ip",
+			table:  "nat",
+			spec:   []string{"-o", "ovn-k8s-mp0", "-j", "OVN-KUBE-SNAT-MGMTPORT"},
+			expr:   `oifname "ovn-k8s-mp0" jump nat-OVN-KUBE-SNAT-MGMTPORT`,
+			jumps:  []string{"OVN-KUBE-SNAT-MGMTPORT"},
+		},
+		{
+			desc:   "snat with comment",
+			family: "ip",
+			table:  "nat",
+			spec:   []string{"-o", "ovn-k8s-mp0", "-j", "SNAT", "--to-source", "10.1.1.2", "-m", "comment", "--comment", "OVN SNAT to Management Port"},
+			expr:   `oifname "ovn-k8s-mp0" snat to 10.1.1.2`,
+		},
+		{
+			desc:   "negated source and destination",
+			family: "ip6",
+			table:  "nat",
+			spec:   []string{"-s", "fd00:10:244::/64", "!", "-d", "fd00:10:244::/64", "-j", "MASQUERADE"},
+			expr:   "ip6 saddr fd00:10:244::/64 ip6 daddr != fd00:10:244::/64 masquerade",
+		},
+		{
+			desc:   "itp mark",
+			family: "ip",
+			table:  "mangle",
+			spec:   []string{"-p", "TCP", "-d", "10.96.0.10", "--dport", "80", "-j", "MARK", "--set-xmark", "0x1745ec"},
+			expr:   "meta l4proto tcp ip daddr 10.96.0.10 th dport 80 meta mark set 0x1745ec",
+		},
+		{
+			desc:   "redirect",
+			family: "ip",
+			table:  "nat",
+			spec:   []string{"-p", "UDP", "-d", "10.96.0.10", "--dport", "53", "-j", "REDIRECT", "--to-port", "5353"},
+			expr:   "meta l4proto udp ip daddr 10.96.0.10 th dport 53 redirect to :5353",
+		},
+		{
+			desc:   "etp local endpoint load balancing",
+			family: "ip",
+			table:  "nat",
+			spec: []string{"-p", "TCP", "-d", "1.1.1.1", "--dport", "80", "-j", "DNAT", "--to-destination", "10.244.0.3:8080",
+				"-m", "statistic", "--mode", "random", "--probability", "0.5000000000"},
+			expr: "meta l4proto tcp ip daddr 1.1.1.1 th dport 80 numgen random mod 1000000 < 500000 dnat to 10.244.0.3:8080",
+		},
+		{
+			desc:   "port range",
+			family: "ip",
+			table:  "nat",
+			spec:   []string{"-p", "TCP", "-d", "1.1.1.1", "--dport", "8000:8100", "-j", "DNAT", "--to-destination", "10.96.0.10"},
+			expr:   "meta l4proto tcp ip daddr 1.1.1.1 th dport 8000-8100 dnat to 10.96.0.10",
+		},
+		{
+			desc:   "mark match",
+			family: "ip",
+			table:  "nat",
+			spec:   []string{"-m", "mark", "--mark", "0x3f0", "-m", "comment", "--comment", "DoNotSNAT", "-j", "RETURN"},
+			expr:   "meta mark 0x3f0 return",
+		},
+		{
+			desc:   "unsupported match",
+			family: "ip",
+			table:  "filter",
+			spec:   []string{"-m", "conntrack", "--ctstate", "INVALID", "-j", "DROP"},
+			errMsg: "unsupported match conntrack",
+		},
+		{
+			desc:   "missing value",
+			family: "ip",
+			table:  "filter",
+			spec:   []string{"-i", "breth0", "-j"},
+			errMsg: "missing value for option -j",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := translateNFTRule(tc.family, tc.table, tc.spec)
+			if tc.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+					t.Fatalf("expected error containing %q, got %v", tc.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.expr != tc.expr {
+				t.Errorf("expected expression %q, got %q", tc.expr, r.expr)
+			}
+			if !reflect.DeepEqual(r.jumps, tc.jumps) {
+				t.Errorf("expected jumps %v, got %v", tc.jumps, r.jumps)
+			}
+		})
+	}
+}
+
+func TestNFTablesHelperChains(t *testing.T) {
+	runner := &fakeNFTablesRunner{}
+	h := newNFTablesHelper(iptables.ProtocolIPv4, runner)
+
+	if err := h.NewChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := h.NewChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err == nil {
+		t.Errorf("expected an error creating an existing chain")
+	}
+	if err := h.NewChain("nat", "POSTROUTING"); err == nil {
+		t.Errorf("expected an error creating a builtin chain")
+	}
+	if _, err := h.Exists("nat", "OVN-KUBE-MISSING", "-j", "ACCEPT"); err == nil {
+		t.Errorf("expected an error looking up a rule of a missing chain")
+	}
+	if err := h.Insert("nat", "POSTROUTING", 1, "-o", "ovn-k8s-mp0", "-j", "OVN-KUBE-SNAT-MGMTPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedScript := []string{
+		"add table ip ovn-kubernetes",
+		"add chain ip ovn-kubernetes nat-POSTROUTING { type nat hook postrouting priority 100 ; }",
+		"add chain ip ovn-kubernetes nat-OVN-KUBE-SNAT-MGMTPORT",
+		"flush chain ip ovn-kubernetes nat-POSTROUTING",
+		`add rule ip ovn-kubernetes nat-POSTROUTING oifname "ovn-k8s-mp0" jump nat-OVN-KUBE-SNAT-MGMTPORT comment "-o ovn-k8s-mp0 -j OVN-KUBE-SNAT-MGMTPORT"`,
+	}
+	if script := runner.lastScript(); !reflect.DeepEqual(script, expectedScript) {
+		t.Errorf("expected script %q, got %q", expectedScript, script)
+	}
+	if err := h.Append("nat", "OVN-KUBE-SNAT-MGMTPORT", "-o", "ovn-k8s-mp0", "-j", "SNAT", "--to-source", "10.1.1.2",
+		"-m", "comment", "--comment", "OVN SNAT to Management Port"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRule := `add rule ip ovn-kubernetes nat-OVN-KUBE-SNAT-MGMTPORT oifname "ovn-k8s-mp0" snat to 10.1.1.2 ` +
+		`comment "-o ovn-k8s-mp0 -j SNAT --to-source 10.1.1.2 -m comment --comment 'OVN SNAT to Management Port'"`
+	if script := runner.lastScript(); script[len(script)-1] != expectedRule {
+		t.Errorf("expected rule %q, got %q", expectedRule, script[len(script)-1])
+	}
+
+	rules, err := h.List("nat", "OVN-KUBE-SNAT-MGMTPORT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRules := []string{
+		"-N OVN-KUBE-SNAT-MGMTPORT",
+		`-A OVN-KUBE-SNAT-MGMTPORT -o ovn-k8s-mp0 -j SNAT --to-source 10.1.1.2 -m comment --comment "OVN SNAT to Management Port"`,
+	}
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("expected rules %q, got %q", expectedRules, rules)
+	}
+
+	if err := h.DeleteChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err == nil {
+		t.Errorf("expected an error deleting a non empty chain")
+	}
+	if err := h.ClearChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := h.DeleteChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err == nil {
+		t.Errorf("expected an error deleting a referenced chain")
+	}
+	if err := h.Delete("nat", "POSTROUTING", "-o", "ovn-k8s-mp0", "-j", "OVN-KUBE-SNAT-MGMTPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := h.Delete("nat", "POSTROUTING", "-o", "ovn-k8s-mp0", "-j", "OVN-KUBE-SNAT-MGMTPORT"); err == nil {
+		t.Errorf("expected an error deleting a missing rule")
+	}
+	if err := h.DeleteChain("nat", "OVN-KUBE-SNAT-MGMTPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chains, err := h.ListChains("nat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expectedChains := []string{"INPUT", "OUTPUT", "POSTROUTING", "PREROUTING"}; !reflect.DeepEqual(chains, expectedChains) {
+		t.Errorf("expected chains %v, got %v", expectedChains, chains)
+	}
+
+	// failures to program nftables leave the helper untouched
+	runner.err = fmt.Errorf("nft failure")
+	if err := h.Append("filter", "FORWARD", "-i", "breth0", "-j", "ACCEPT"); err == nil {
+		t.Errorf("expected an error")
+	}
+	if exists, _ := h.Exists("filter", "FORWARD", "-i", "breth0", "-j", "ACCEPT"); exists {
+		t.Errorf("expected the rule not to exist after a failure")
+	}
+}
+
+func TestNFTablesHelperServiceMaps(t *testing.T) {
+	nodePort := func(port, destination string) []string {
+		return []string{"-p", "TCP", "-m", "addrtype", "--dst-type", "LOCAL", "--dport", port, "-j", "DNAT", "--to-destination", destination}
+	}
+	externalIP := func(vip, destination string) []string {
+		return []string{"-p", "UDP", "-d", vip, "--dport", "53", "-j", "DNAT", "--to-destination", destination}
+	}
+	runner := &fakeNFTablesRunner{}
+	h := newNFTablesHelper(iptables.ProtocolIPv4, runner)
+	if err := h.NewChain("nat", "OVN-KUBE-NODEPORT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		desc   string
+		apply  func() error
+		script []string
+	}{
+		{
+			desc:  "first element creates the map and the lookup rule",
+			apply: func() error { return h.Insert("nat", "OVN-KUBE-NODEPORT", 1, nodePort("30080", "10.96.0.10:80")...) },
+			script: []string{
+				"add table ip ovn-kubernetes",
+				"add chain ip ovn-kubernetes nat-OVN-KUBE-NODEPORT",
+				"flush chain ip ovn-kubernetes nat-OVN-KUBE-NODEPORT",
+				"add map ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { type inet_proto . inet_service : ipv4_addr . inet_service ; }",
+				"flush map ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports",
+				"add element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30080 : 10.96.0.10 . 80 }",
+				"add rule ip ovn-kubernetes nat-OVN-KUBE-NODEPORT fib daddr type local dnat ip to meta l4proto . th dport map @nat-OVN-KUBE-NODEPORT-nodeports",
+			},
+		},
+		{
+			desc:   "further elements are added incrementally",
+			apply:  func() error { return h.Insert("nat", "OVN-KUBE-NODEPORT", 1, nodePort("30081", "10.96.0.11:80")...) },
+			script: []string{"add element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30081 : 10.96.0.11 . 80 }"},
+		},
+		{
+			desc:  "the first rule of a key takes precedence",
+			apply: func() error { return h.Insert("nat", "OVN-KUBE-NODEPORT", 1, nodePort("30081", "10.96.0.12:80")...) },
+			script: []string{
+				"delete element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30081 }",
+				"add element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30081 : 10.96.0.12 . 80 }",
+			},
+		},
+		{
+			desc:  "deleting the first rule of a key restores the shadowed one",
+			apply: func() error { return h.Delete("nat", "OVN-KUBE-NODEPORT", nodePort("30081", "10.96.0.12:80")...) },
+			script: []string{
+				"delete element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30081 }",
+				"add element ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { tcp . 30081 : 10.96.0.11 . 80 }",
+			},
+		},
+		{
+			desc:  "deleting the last element removes the lookup rule",
+			apply: func() error { return h.ReplaceChain("nat", "OVN-KUBE-NODEPORT", nil) },
+			script: []string{
+				"add table ip ovn-kubernetes",
+				"add chain ip ovn-kubernetes nat-OVN-KUBE-NODEPORT",
+				"flush chain ip ovn-kubernetes nat-OVN-KUBE-NODEPORT",
+				"add map ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports { type inet_proto . inet_service : ipv4_addr . inet_service ; }",
+				"flush map ip ovn-kubernetes nat-OVN-KUBE-NODEPORT-nodeports",
+			},
+		},
+		{
+			desc: "external IPs use a map keyed by address",
+			apply: func() error {
+				return h.ReplaceChain("nat", "OVN-KUBE-EXTERNALIP", [][]string{externalIP("1.1.1.1", "10.96.0.10:53")})
+			},
+			script: []string{
+				"add table ip ovn-kubernetes",
+				"add chain ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP",
+				"flush chain ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP",
+				"add map ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP-vips { type ipv4_addr . inet_proto . inet_service : ipv4_addr . inet_service ; }",
+				"flush map ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP-vips",
+				"add element ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP-vips { 1.1.1.1 . udp . 53 : 10.96.0.10 . 53 }",
+				"add rule ip ovn-kubernetes nat-OVN-KUBE-EXTERNALIP dnat ip to ip daddr . meta l4proto . th dport map @nat-OVN-KUBE-EXTERNALIP-vips",
+			},
+		},
+	}
+	for _, step := range steps {
+		if err := step.apply(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.desc, err)
+		}
+		if script := runner.lastScript(); !reflect.DeepEqual(script, step.script) {
+			t.Errorf("%s: expected script %q, got %q", step.desc, step.script, script)
+		}
+	}
+
+	exists, err := h.Exists("nat", "OVN-KUBE-EXTERNALIP", externalIP("1.1.1.1", "10.96.0.10:53")...)
+	if err != nil || !exists {
+		t.Errorf("expected the external IP rule to exist, got %v, %v", exists, err)
+	}
+}
+
+func TestNFTablesHelperLoad(t *testing.T) {
+	runner := &fakeNFTablesRunner{
+		listing: []byte(`{"nftables": [
+			{"metainfo": {"version": "1.0.9", "json_schema_version": 1}},
+			{"table": {"family": "ip", "name": "ovn-kubernetes", "handle": 1}},
+			{"chain": {"family": "ip", "table": "ovn-kubernetes", "name": "nat-PREROUTING", "handle": 1, "type": "nat", "hook": "prerouting", "prio": -100, "policy": "accept"}},
+			{"chain": {"family": "ip", "table": "ovn-kubernetes", "name": "nat-OVN-KUBE-NODEPORT", "handle": 2}},
+			{"map": {"family": "ip", "name": "nat-OVN-KUBE-NODEPORT-nodeports", "table": "ovn-kubernetes", "type": ["inet_proto", "inet_service"], "handle": 3, "map": ["ipv4_addr", "inet_service"],
+				"elem": [[{"concat": ["tcp", 30080]}, {"concat": ["10.96.0.10", 80]}]]}},
+			{"rule": {"family": "ip", "table": "ovn-kubernetes", "chain": "nat-PREROUTING", "handle": 4, "comment": "-j OVN-KUBE-NODEPORT", "expr": []}},
+			{"rule": {"family": "ip", "table": "ovn-kubernetes", "chain": "nat-OVN-KUBE-NODEPORT", "handle": 5, "expr": []}}
+		]}`),
+	}
+	h := newNFTablesHelper(iptables.ProtocolIPv4, runner)
+
+	rules, err := h.List("nat", "OVN-KUBE-NODEPORT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedRules := []string{
+		"-N OVN-KUBE-NODEPORT",
+		"-A OVN-KUBE-NODEPORT -p TCP -m addrtype --dst-type LOCAL --dport 30080 -j DNAT --to-destination 10.96.0.10:80",
+	}
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("expected rules %q, got %q", expectedRules, rules)
+	}
+	exists, err := h.Exists("nat", "PREROUTING", "-j", "OVN-KUBE-NODEPORT")
+	if err != nil || !exists {
+		t.Errorf("expected the jump rule to exist, got %v, %v", exists, err)
+	}
+	if len(runner.scripts) != 0 {
+		t.Errorf("expected no changes to be programmed, got %q", runner.scripts)
+	}
+}
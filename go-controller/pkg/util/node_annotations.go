@@ -53,6 +53,10 @@ const (
 	// OvnNodeGatewayMtuSupport determines if option:gateway_mtu shall be set for GR router ports.
 	OvnNodeGatewayMtuSupport = "k8s.ovn.org/gateway-mtu-support"
 
+	// OvnNodeGatewayModeStatus reports the gateway mode programmed on the node and the
+	// mode it is switching to, if any. It is set by ovnkube-node.
+	OvnNodeGatewayModeStatus = "k8s.ovn.org/gateway-mode-status"
+
 	// OvnDefaultNetworkGateway captures L3 gateway config for default OVN network interface
 	ovnDefaultNetworkGateway = "default"
 
@@ -132,22 +136,46 @@ type L3GatewayConfig struct {
 	NextHops            []net.IP
 	NodePortEnable      bool
 	VLANID              *uint
+	Uplinks             []L3GatewayUplink
+}
+
+// L3GatewayUplink is an additional uplink of the node connected to the
+// gateway router along with the traffic it is selected for
+type L3GatewayUplink struct {
+	Name         string
+	InterfaceID  string
+	MACAddress   net.HardwareAddr
+	IPAddresses  []*net.IPNet
+	NextHops     []net.IP
+	Destinations []*net.IPNet
+	Namespaces   []string
+}
+
+type l3GatewayUplinkJSON struct {
+	Name         string   `json:"name"`
+	InterfaceID  string   `json:"interface-id"`
+	MACAddress   string   `json:"mac-address"`
+	IPAddresses  []string `json:"ip-addresses"`
+	NextHops     []string `json:"next-hops,omitempty"`
+	Destinations []string `json:"destinations,omitempty"`
+	Namespaces   []string `json:"namespaces,omitempty"`
 }
 
 type l3GatewayConfigJSON struct {
-	Mode                config.GatewayMode `json:"mode"`
-	InterfaceID         string             `json:"interface-id,omitempty"`
-	MACAddress          string             `json:"mac-address,omitempty"`
-	IPAddresses         []string           `json:"ip-addresses,omitempty"`
-	IPAddress           string             `json:"ip-address,omitempty"`
-	EgressGWInterfaceID string             `json:"exgw-interface-id,omitempty"`
-	EgressGWMACAddress  string             `json:"exgw-mac-address,omitempty"`
-	EgressGWIPAddresses []string           `json:"exgw-ip-addresses,omitempty"`
-	EgressGWIPAddress   string             `json:"exgw-ip-address,omitempty"`
-	NextHops            []string           `json:"next-hops,omitempty"`
-	NextHop             string             `json:"next-hop,omitempty"`
-	NodePortEnable      string             `json:"node-port-enable,omitempty"`
-	VLANID              string             `json:"vlan-id,omitempty"`
+	Mode                config.GatewayMode    `json:"mode"`
+	InterfaceID         string                `json:"interface-id,omitempty"`
+	MACAddress          string                `json:"mac-address,omitempty"`
+	IPAddresses         []string              `json:"ip-addresses,omitempty"`
+	IPAddress           string                `json:"ip-address,omitempty"`
+	EgressGWInterfaceID string                `json:"exgw-interface-id,omitempty"`
+	EgressGWMACAddress  string                `json:"exgw-mac-address,omitempty"`
+	EgressGWIPAddresses []string              `json:"exgw-ip-addresses,omitempty"`
+	EgressGWIPAddress   string                `json:"exgw-ip-address,omitempty"`
+	NextHops            []string              `json:"next-hops,omitempty"`
+	NextHop             string                `json:"next-hop,omitempty"`
+	NodePortEnable      string                `json:"node-port-enable,omitempty"`
+	VLANID              string                `json:"vlan-id,omitempty"`
+	Uplinks             []l3GatewayUplinkJSON `json:"uplinks,omitempty"`
 }
 
 func (cfg *L3GatewayConfig) MarshalJSON() ([]byte, error) {
@@ -188,6 +216,24 @@ func (cfg *L3GatewayConfig) MarshalJSON() ([]byte, error) {
 	if len(cfgjson.NextHops) == 1 {
 		cfgjson.NextHop = cfgjson.NextHops[0]
 	}
+	for _, uplink := range cfg.Uplinks {
+		uplinkjson := l3GatewayUplinkJSON{
+			Name:        uplink.Name,
+			InterfaceID: uplink.InterfaceID,
+			MACAddress:  uplink.MACAddress.String(),
+			Namespaces:  uplink.Namespaces,
+		}
+		for _, ip := range uplink.IPAddresses {
+			uplinkjson.IPAddresses = append(uplinkjson.IPAddresses, ip.String())
+		}
+		for _, nh := range uplink.NextHops {
+			uplinkjson.NextHops = append(uplinkjson.NextHops, nh.String())
+		}
+		for _, destination := range uplink.Destinations {
+			uplinkjson.Destinations = append(uplinkjson.Destinations, destination.String())
+		}
+		cfgjson.Uplinks = append(cfgjson.Uplinks, uplinkjson)
+	}
 
 	return json.Marshal(&cfgjson)
 }
@@ -281,6 +327,40 @@ func (cfg *L3GatewayConfig) UnmarshalJSON(bytes []byte) error {
 		}
 	}
 
+	for _, uplinkjson := range cfgjson.Uplinks {
+		uplink := L3GatewayUplink{
+			Name:        uplinkjson.Name,
+			InterfaceID: uplinkjson.InterfaceID,
+			Namespaces:  uplinkjson.Namespaces,
+		}
+		uplink.MACAddress, err = net.ParseMAC(uplinkjson.MACAddress)
+		if err != nil {
+			return fmt.Errorf("bad uplink %q 'mac-address' value %q: %v", uplink.Name, uplinkjson.MACAddress, err)
+		}
+		for _, ipStr := range uplinkjson.IPAddresses {
+			ip, ipnet, err := net.ParseCIDR(ipStr)
+			if err != nil {
+				return fmt.Errorf("bad uplink %q 'ip-addresses' value %q: %v", uplink.Name, ipStr, err)
+			}
+			uplink.IPAddresses = append(uplink.IPAddresses, &net.IPNet{IP: ip, Mask: ipnet.Mask})
+		}
+		for _, nextHopStr := range uplinkjson.NextHops {
+			nextHop := net.ParseIP(nextHopStr)
+			if nextHop == nil {
+				return fmt.Errorf("bad uplink %q 'next-hops' value %q", uplink.Name, nextHopStr)
+			}
+			uplink.NextHops = append(uplink.NextHops, nextHop)
+		}
+		for _, destinationStr := range uplinkjson.Destinations {
+			_, destination, err := net.ParseCIDR(destinationStr)
+			if err != nil {
+				return fmt.Errorf("bad uplink %q 'destinations' value %q: %v", uplink.Name, destinationStr, err)
+			}
+			uplink.Destinations = append(uplink.Destinations, destination)
+		}
+		cfg.Uplinks = append(cfg.Uplinks, uplink)
+	}
+
 	return nil
 }
 
@@ -313,6 +393,36 @@ func ParseNodeGatewayMTUSupport(node *kapi.Node) bool {
 	return node.Annotations[OvnNodeGatewayMtuSupport] != "false"
 }
 
+// GatewayModeStatus is the content of the "k8s.ovn.org/gateway-mode-status"
+// annotation
+type GatewayModeStatus struct {
+	// Mode is the gateway mode programmed on the node
+	Mode config.GatewayMode `json:"mode"`
+	// TargetMode is the gateway mode the node is switching to, empty once the
+	// switch is complete
+	TargetMode config.GatewayMode `json:"target-mode,omitempty"`
+	// Error is the reason the last attempt to switch to TargetMode failed
+	Error string `json:"error,omitempty"`
+}
+
+// SetNodeGatewayModeStatus sets the "k8s.ovn.org/gateway-mode-status" annotation
+func SetNodeGatewayModeStatus(nodeAnnotator kube.Annotator, status *GatewayModeStatus) error {
+	return nodeAnnotator.Set(OvnNodeGatewayModeStatus, status)
+}
+
+// ParseNodeGatewayModeStatus returns the parsed gateway-mode-status annotation
+func ParseNodeGatewayModeStatus(node *kapi.Node) (*GatewayModeStatus, error) {
+	annotation, ok := node.Annotations[OvnNodeGatewayModeStatus]
+	if !ok {
+		return nil, newAnnotationNotSetError("%s annotation not found for node %q", OvnNodeGatewayModeStatus, node.Name)
+	}
+	status := &GatewayModeStatus{}
+	if err := json.Unmarshal([]byte(annotation), status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal gateway mode status annotation %s for node %q: %v", annotation, node.Name, err)
+	}
+	return status, nil
+}
+
 // ParseNodeL3GatewayAnnotation returns the parsed l3-gateway-config annotation
 func ParseNodeL3GatewayAnnotation(node *kapi.Node) (*L3GatewayConfig, error) {
 	l3GatewayAnnotation, ok := node.Annotations[OvnNodeL3GatewayConfig]
@@ -343,6 +453,32 @@ func NodeL3GatewayAnnotationChanged(oldNode, newNode *kapi.Node) bool {
 	return oldNode.Annotations[OvnNodeL3GatewayConfig] != newNode.Annotations[OvnNodeL3GatewayConfig]
 }
 
+// GatewayMode returns the gateway mode annotated by the node in its L3 gateway
+// config, which differs from the configured mode while the node switches its
+// gateway mode. It falls back to the configured mode if the node does not
+// annotate a local or shared gateway mode.
+func (cfg *L3GatewayConfig) GatewayMode() config.GatewayMode {
+	if cfg != nil && (cfg.Mode == config.GatewayModeLocal || cfg.Mode == config.GatewayModeShared) {
+		return cfg.Mode
+	}
+	return config.Gateway.Mode
+}
+
+// GetNodeGatewayMode returns the gateway mode of the node, or the configured
+// gateway mode if the node is unknown or does not annotate one
+func GetNodeGatewayMode(node *kapi.Node) config.GatewayMode {
+	if node == nil {
+		return config.Gateway.Mode
+	}
+	l3GatewayConfig, _ := ParseNodeL3GatewayAnnotation(node)
+	return l3GatewayConfig.GatewayMode()
+}
+
+// NodeGatewayModeChanged returns true if the gateway mode of the node changed
+func NodeGatewayModeChanged(oldNode, newNode *kapi.Node) bool {
+	return GetNodeGatewayMode(oldNode) != GetNodeGatewayMode(newNode)
+}
+
 // ParseNodeChassisIDAnnotation returns the node's ovnNodeChassisID annotation
 func ParseNodeChassisIDAnnotation(node *kapi.Node) (string, error) {
 	chassisID, ok := node.Annotations[OvnNodeChassisID]
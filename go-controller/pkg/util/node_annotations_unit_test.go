@@ -72,6 +72,27 @@ func TestL3GatewayConfig_MarshalJSON(t *testing.T) {
 			},
 			expOutput: []byte(`{"mode":"local","interface-id":"INTERFACE-ID","mac-address":"11:22:33:44:55:66","ip-addresses":["192.168.1.10/24","fd01::1234/64"],"next-hops":["192.168.1.1","fd01::1"],"node-port-enable":"false","vlan-id":"1024"}`),
 		},
+		{
+			desc: "test additional uplinks",
+			inpL3GwCfg: &L3GatewayConfig{
+				Mode:        config.GatewayModeShared,
+				InterfaceID: "INTERFACE-ID",
+				MACAddress:  ovntest.MustParseMAC("11:22:33:44:55:66"),
+				IPAddresses: []*net.IPNet{ovntest.MustParseIPNet("192.168.1.10/24")},
+				Uplinks: []L3GatewayUplink{
+					{
+						Name:         "storage",
+						InterfaceID:  "breth2_node1",
+						MACAddress:   ovntest.MustParseMAC("11:22:33:44:55:77"),
+						IPAddresses:  []*net.IPNet{ovntest.MustParseIPNet("10.10.0.5/24")},
+						NextHops:     []net.IP{ovntest.MustParseIP("10.10.0.1")},
+						Destinations: []*net.IPNet{ovntest.MustParseIPNet("10.20.0.0/16")},
+						Namespaces:   []string{"backup"},
+					},
+				},
+			},
+			expOutput: []byte(`{"mode":"shared","interface-id":"INTERFACE-ID","mac-address":"11:22:33:44:55:66","ip-addresses":["192.168.1.10/24"],"ip-address":"192.168.1.10/24","node-port-enable":"false","uplinks":[{"name":"storage","interface-id":"breth2_node1","mac-address":"11:22:33:44:55:77","ip-addresses":["10.10.0.5/24"],"next-hops":["10.10.0.1"],"destinations":["10.20.0.0/16"],"namespaces":["backup"]}]}`),
+		},
 	}
 	for i, tc := range tests {
 		t.Run(fmt.Sprintf("%d:%s", i, tc.desc), func(t *testing.T) {
@@ -183,6 +204,32 @@ func TestL3GatewayConfig_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:       "test bad uplink 'mac-address' value",
+			inputParam: []byte(`{"mode":"shared","mac-address":"11:22:33:44:55:66","ip-address":"192.168.1.5/24","uplinks":[{"name":"storage","mac-address":"BADMAC"}]}`),
+			errMatch:   fmt.Errorf("bad uplink \"storage\" 'mac-address' value"),
+		},
+		{
+			desc:       "test valid uplinks value",
+			inputParam: []byte(`{"mode":"shared","mac-address":"11:22:33:44:55:66","ip-address":"192.168.1.5/24","uplinks":[{"name":"storage","interface-id":"breth2_node1","mac-address":"11:22:33:44:55:77","ip-addresses":["10.10.0.5/24"],"next-hops":["10.10.0.1"],"destinations":["10.20.0.0/16"],"namespaces":["backup"]}]}`),
+			expOut: L3GatewayConfig{
+				Mode:        "shared",
+				MACAddress:  ovntest.MustParseMAC("11:22:33:44:55:66"),
+				IPAddresses: ovntest.MustParseIPNets("192.168.1.5/24"),
+				NextHops:    []net.IP{},
+				Uplinks: []L3GatewayUplink{
+					{
+						Name:         "storage",
+						InterfaceID:  "breth2_node1",
+						MACAddress:   ovntest.MustParseMAC("11:22:33:44:55:77"),
+						IPAddresses:  ovntest.MustParseIPNets("10.10.0.5/24"),
+						NextHops:     []net.IP{ovntest.MustParseIP("10.10.0.1")},
+						Destinations: ovntest.MustParseIPNets("10.20.0.0/16"),
+						Namespaces:   []string{"backup"},
+					},
+				},
+			},
+		},
 	}
 	for i, tc := range tests {
 		t.Run(fmt.Sprintf("%d:%s", i, tc.desc), func(t *testing.T) {
@@ -616,3 +663,108 @@ func TestParseNodeGatewayMTUSupport(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNodeGatewayModeStatus(t *testing.T) {
+	tests := []struct {
+		desc        string
+		inpNode     *v1.Node
+		res         *GatewayModeStatus
+		errExpected bool
+	}{
+		{
+			desc:        "annotation not found for node",
+			inpNode:     &v1.Node{},
+			errExpected: true,
+		},
+		{
+			desc: "parse the programmed mode",
+			inpNode: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"k8s.ovn.org/gateway-mode-status": `{"mode":"shared"}`,
+					},
+				},
+			},
+			res: &GatewayModeStatus{Mode: config.GatewayModeShared},
+		},
+		{
+			desc: "parse a failed switch",
+			inpNode: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"k8s.ovn.org/gateway-mode-status": `{"mode":"shared","target-mode":"local","error":"boom"}`,
+					},
+				},
+			},
+			res: &GatewayModeStatus{Mode: config.GatewayModeShared, TargetMode: config.GatewayModeLocal, Error: "boom"},
+		},
+		{
+			desc: "error: fail to unmarshal annotation",
+			inpNode: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"k8s.ovn.org/gateway-mode-status": "shared",
+					},
+				},
+			},
+			errExpected: true,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d:%s", i, tc.desc), func(t *testing.T) {
+			res, err := ParseNodeGatewayModeStatus(tc.inpNode)
+			if tc.errExpected {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.res, res)
+			}
+		})
+	}
+}
+
+func TestGetNodeGatewayMode(t *testing.T) {
+	nodeWithMode := func(mode string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"k8s.ovn.org/node-chassis-id":   "chassis1",
+					"k8s.ovn.org/l3-gateway-config": fmt.Sprintf(`{"default":{"mode":"%s","mac-address":"7e:57:f8:f0:3c:49", "ip-address":"169.254.33.2/24", "next-hop":"169.254.33.1"}}`, mode),
+				},
+			},
+		}
+	}
+	tests := []struct {
+		desc   string
+		node   *v1.Node
+		result config.GatewayMode
+	}{
+		{
+			desc:   "unknown node uses the configured mode",
+			result: config.GatewayModeShared,
+		},
+		{
+			desc:   "node without L3 gateway config uses the configured mode",
+			node:   &v1.Node{},
+			result: config.GatewayModeShared,
+		},
+		{
+			desc:   "node with a disabled gateway uses the configured mode",
+			node:   nodeWithMode("disabled"),
+			result: config.GatewayModeShared,
+		},
+		{
+			desc:   "node switching to local gateway mode",
+			node:   nodeWithMode("local"),
+			result: config.GatewayModeLocal,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			config.PrepareTestConfig()
+			config.Gateway.Mode = config.GatewayModeShared
+			assert.Equal(t, tc.result, GetNodeGatewayMode(tc.node))
+		})
+	}
+}
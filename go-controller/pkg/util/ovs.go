@@ -658,6 +658,20 @@ func ReplaceOFFlows(bridgeName string, flows []string) (string, string, error) {
 	return strings.Trim(stdout.String(), "\" \n"), stderr.String(), err
 }
 
+// ModifyOFFlows applies a slice of flow modifications to the bridge in a single bundle. Each
+// modification is a flow prefixed by its command, i.e. add, modify, modify_strict, delete or
+// delete_strict
+func ModifyOFFlows(bridgeName string, flowMods []string) (string, string, error) {
+	args := []string{"-O", "OpenFlow13", "--bundle", "add-flows", bridgeName, "-"}
+	stdin := &bytes.Buffer{}
+	stdin.Write([]byte(strings.Join(flowMods, "\n")))
+
+	cmd := runner.exec.Command(runner.ofctlPath, args...)
+	cmd.SetStdin(stdin)
+	stdout, stderr, err := runCmd(cmd, runner.ofctlPath, args...)
+	return strings.Trim(stdout.String(), "\" \n"), stderr.String(), err
+}
+
 // Get OpenFlow Port names or numbers for a given bridge
 func GetOpenFlowPorts(bridgeName string, namedPorts bool) ([]string, error) {
 	stdout, stderr, err := RunOVSOfctl("show", bridgeName)
@@ -71,6 +71,15 @@ type PodAnnotation struct {
 
 	// TunnelID assigned to each pod for layer2 secondary networks
 	TunnelID int
+
+	// VLANID the pod interface is tagged with on trunk localnet secondary
+	// networks, zero when untagged
+	VLANID uint
+
+	// LogicalSwitchPort is the logical switch port the pod interface binds
+	// to when it is not the port of the pod itself, like the port shared by
+	// the pods of a KubeVirt VM live migrated with multi-chassis port binding
+	LogicalSwitchPort string
 }
 
 // PodRoute describes any routes to be added to the pod's network namespace
@@ -95,7 +104,10 @@ type podAnnotation struct {
 	IP      string `json:"ip_address,omitempty"`
 	Gateway string `json:"gateway_ip,omitempty"`
 
-	TunnelID int `json:"tunnel_id,omitempty"`
+	TunnelID int  `json:"tunnel_id,omitempty"`
+	VLANID   uint `json:"vlan_id,omitempty"`
+
+	LogicalSwitchPort string `json:"logical_switch_port,omitempty"`
 }
 
 // Internal struct used to marshal PodRoute to the pod annotation
@@ -114,8 +126,10 @@ func MarshalPodAnnotation(annotations map[string]string, podInfo *PodAnnotation,
 		return nil, err
 	}
 	pa := podAnnotation{
-		TunnelID: podInfo.TunnelID,
-		MAC:      podInfo.MAC.String(),
+		TunnelID:          podInfo.TunnelID,
+		VLANID:            podInfo.VLANID,
+		MAC:               podInfo.MAC.String(),
+		LogicalSwitchPort: podInfo.LogicalSwitchPort,
 	}
 
 	if len(podInfo.IPs) == 1 {
@@ -190,7 +204,9 @@ func UnmarshalPodAnnotation(annotations map[string]string, nadName string) (*Pod
 	a := &tempA
 
 	podAnnotation := &PodAnnotation{
-		TunnelID: a.TunnelID,
+		TunnelID:          a.TunnelID,
+		VLANID:            a.VLANID,
+		LogicalSwitchPort: a.LogicalSwitchPort,
 	}
 	podAnnotation.MAC, err = net.ParseMAC(a.MAC)
 	if err != nil {
@@ -328,7 +344,14 @@ func SecondaryNetworkPodIPs(pod *v1.Pod, networkInfo NetInfo) ([]net.IP, error)
 	if err != nil {
 		return nil, err
 	}
+	// the IPs of pods on IPAM-less networks are only known from their traffic
+	useObservedIPs := IsObservedPodIPsEnabled() && !DoesNetworkRequireIPAM(networkInfo)
 	for _, nadName := range podNadNames {
+		if useObservedIPs {
+			observedIPs, _ := UnmarshalPodObservedIPs(pod.Annotations, nadName)
+			ips = append(ips, observedIPs...)
+			continue
+		}
 		ips = append(ips, getAnnotatedPodIPs(pod, nadName)...)
 	}
 	return ips, nil
@@ -453,14 +476,28 @@ func AddRoutesGatewayIP(
 	nodeSubnets := IPsToNetworkIPs(podAnnotation.IPs...)
 
 	if netinfo.IsSecondary() {
-		// for secondary network, see if its network-attachment's annotation has default-route key.
-		// If present, then we need to add default route for it
-		podAnnotation.Gateways = append(podAnnotation.Gateways, network.GatewayRequest...)
+		var gatewayIPs []net.IP
 		topoType := netinfo.TopologyType()
 		switch topoType {
 		case types.Layer2Topology, types.LocalnetTopology:
-			// no route needed for directly connected subnets
-			return nil
+			// the routes of the IP pools go through the gateways of the pools
+			for _, podIfAddr := range podAnnotation.IPs {
+				if pool := findIPPoolOfIP(netinfo.IPPools(), podIfAddr.IP); pool != nil {
+					podAnnotation.Routes = append(podAnnotation.Routes, pool.Routes...)
+				}
+			}
+			// no route needed for directly connected subnets, the gateway
+			// router, if any, is reached through the first IP of the subnet
+			if netinfo.IsGatewayEnabled() {
+				for _, podIfAddr := range podAnnotation.IPs {
+					for _, subnet := range netinfo.Subnets() {
+						if subnet.CIDR.Contains(podIfAddr.IP) {
+							gatewayIPs = append(gatewayIPs, GetNodeGatewayIfAddr(subnet.CIDR).IP)
+							break
+						}
+					}
+				}
+			}
 		case types.Layer3Topology:
 			for _, podIfAddr := range podAnnotation.IPs {
 				isIPv6 := utilnet.IsIPv6CIDR(podIfAddr)
@@ -477,10 +514,39 @@ func AddRoutesGatewayIP(
 						})
 					}
 				}
+				gatewayIPs = append(gatewayIPs, gatewayIPnet.IP)
 			}
+		default:
+			return fmt.Errorf("topology type %s not supported", topoType)
+		}
+
+		if !netinfo.IsGatewayEnabled() {
+			// for secondary network, see if its network-attachment's annotation has default-route key.
+			// If present, then we need to add default route for it
+			podAnnotation.Gateways = append(podAnnotation.Gateways, network.GatewayRequest...)
 			return nil
 		}
-		return fmt.Errorf("topology type %s not supported", topoType)
+
+		// the network has its own gateway router: requested default routes
+		// go through it, and so does the service network traffic
+		for _, gatewayRequest := range network.GatewayRequest {
+			gatewayIP, err := MatchFirstIPFamily(utilnet.IsIPv6(gatewayRequest), gatewayIPs)
+			if err != nil {
+				return fmt.Errorf("no gateway available for the requested default route %s: %v", gatewayRequest, err)
+			}
+			podAnnotation.Gateways = append(podAnnotation.Gateways, gatewayIP)
+		}
+		for _, gatewayIP := range gatewayIPs {
+			for _, serviceSubnet := range config.Kubernetes.ServiceCIDRs {
+				if utilnet.IsIPv6(gatewayIP) == utilnet.IsIPv6CIDR(serviceSubnet) {
+					podAnnotation.Routes = append(podAnnotation.Routes, PodRoute{
+						Dest:    serviceSubnet,
+						NextHop: gatewayIP,
+					})
+				}
+			}
+		}
+		return nil
 	}
 
 	// if there are other network attachments for the pod, then check if those network-attachment's
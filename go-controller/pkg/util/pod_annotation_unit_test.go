@@ -10,7 +10,10 @@ import (
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+
 	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	ovntest "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/testing"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -103,6 +106,14 @@ func TestMarshalPodAnnotation(t *testing.T) {
 			},
 			expectedOutput: map[string]string{"k8s.ovn.org/pod-networks": `{"default":{"ip_addresses":null,"mac_address":"","routes":[{"dest":"192.168.1.0/24","nextHop":""}]}}`},
 		},
+		{
+			desc: "logical switch port shared with another pod",
+			inpPodAnnot: PodAnnotation{
+				IPs:               []*net.IPNet{ovntest.MustParseIPNet("192.168.0.5/24")},
+				LogicalSwitchPort: "ns1_virt-launcher-vm1-source",
+			},
+			expectedOutput: map[string]string{"k8s.ovn.org/pod-networks": `{"default":{"ip_addresses":["192.168.0.5/24"],"mac_address":"","ip_address":"192.168.0.5/24","logical_switch_port":"ns1_virt-launcher-vm1-source"}}`},
+		},
 	}
 
 	for i, tc := range tests {
@@ -360,3 +371,118 @@ func newDummyNetInfo(namespace, networkName string) NetInfo {
 	netInfo.AddNAD(GetNADName(namespace, networkName))
 	return netInfo
 }
+
+func TestAddRoutesGatewayIPSecondaryNetworkGateway(t *testing.T) {
+	tests := []struct {
+		desc           string
+		netconf        *ovncnitypes.NetConf
+		podIPs         []*net.IPNet
+		gatewayRequest []net.IP
+		expGateways    []net.IP
+		expRoutes      []PodRoute
+		expErr         bool
+	}{
+		{
+			desc: "layer3 network without gateway keeps the requested default route",
+			netconf: &ovncnitypes.NetConf{
+				NetConf:  cnitypes.NetConf{Name: "tenantred"},
+				Topology: types.Layer3Topology,
+				Subnets:  "10.128.0.0/16/24",
+			},
+			podIPs:         ovntest.MustParseIPNets("10.128.1.3/24"),
+			gatewayRequest: []net.IP{ovntest.MustParseIP("10.128.1.254")},
+			expGateways:    []net.IP{ovntest.MustParseIP("10.128.1.254")},
+			expRoutes: []PodRoute{
+				{Dest: ovntest.MustParseIPNet("10.128.0.0/16"), NextHop: ovntest.MustParseIP("10.128.1.1")},
+			},
+		},
+		{
+			desc: "layer3 network with gateway routes services and the default route through it",
+			netconf: &ovncnitypes.NetConf{
+				NetConf:       cnitypes.NetConf{Name: "tenantred"},
+				Topology:      types.Layer3Topology,
+				Subnets:       "10.128.0.0/16/24",
+				EnableGateway: true,
+			},
+			podIPs:         ovntest.MustParseIPNets("10.128.1.3/24"),
+			gatewayRequest: []net.IP{ovntest.MustParseIP("10.128.1.254")},
+			expGateways:    []net.IP{ovntest.MustParseIP("10.128.1.1")},
+			expRoutes: []PodRoute{
+				{Dest: ovntest.MustParseIPNet("10.128.0.0/16"), NextHop: ovntest.MustParseIP("10.128.1.1")},
+				{Dest: ovntest.MustParseIPNet("172.16.1.0/24"), NextHop: ovntest.MustParseIP("10.128.1.1")},
+			},
+		},
+		{
+			desc: "layer2 network with gateway routes services through the first subnet IP",
+			netconf: &ovncnitypes.NetConf{
+				NetConf:       cnitypes.NetConf{Name: "tenantred"},
+				Topology:      types.Layer2Topology,
+				Subnets:       "192.168.1.0/24",
+				EnableGateway: true,
+			},
+			podIPs: ovntest.MustParseIPNets("192.168.1.3/24"),
+			expRoutes: []PodRoute{
+				{Dest: ovntest.MustParseIPNet("172.16.1.0/24"), NextHop: ovntest.MustParseIP("192.168.1.1")},
+			},
+		},
+		{
+			desc: "localnet network routes through the gateways of the IP pools",
+			netconf: &ovncnitypes.NetConf{
+				NetConf:  cnitypes.NetConf{Name: "tenantred"},
+				Topology: types.LocalnetTopology,
+				Subnets:  "192.168.1.0/24",
+				IPPools: []ovncnitypes.IPPool{
+					{
+						Name:       "blue",
+						Subnet:     "192.168.1.0/24",
+						RangeStart: "192.168.1.100",
+						RangeEnd:   "192.168.1.199",
+						Gateway:    "192.168.1.254",
+						Routes: []ovncnitypes.IPPoolRoute{
+							{Dest: "10.0.0.0/8"},
+							{Dest: "172.20.0.0/16", NextHop: "192.168.1.253"},
+						},
+					},
+				},
+			},
+			podIPs: ovntest.MustParseIPNets("192.168.1.100/24"),
+			expRoutes: []PodRoute{
+				{Dest: ovntest.MustParseIPNet("10.0.0.0/8"), NextHop: ovntest.MustParseIP("192.168.1.254")},
+				{Dest: ovntest.MustParseIPNet("172.20.0.0/16"), NextHop: ovntest.MustParseIP("192.168.1.253")},
+			},
+		},
+		{
+			desc: "layer2 network with gateway fails on a default route request of a missing IP family",
+			netconf: &ovncnitypes.NetConf{
+				NetConf:       cnitypes.NetConf{Name: "tenantred"},
+				Topology:      types.Layer2Topology,
+				Subnets:       "192.168.1.0/24",
+				EnableGateway: true,
+			},
+			podIPs:         ovntest.MustParseIPNets("192.168.1.3/24"),
+			gatewayRequest: []net.IP{ovntest.MustParseIP("fd00::1")},
+			expErr:         true,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d:%s", i, tc.desc), func(t *testing.T) {
+			assert.NoError(t, config.PrepareTestConfig())
+			config.OVNKubernetesFeature.EnableInterconnect = true
+			config.OVNKubernetesFeature.EnableMultiNetwork = true
+			config.OVNKubernetesFeature.EnableSecondaryNetworkGateway = true
+			config.Kubernetes.ServiceCIDRs = ovntest.MustParseIPNets("172.16.1.0/24")
+			netInfo, err := NewNetInfo(tc.netconf)
+			assert.NoError(t, err)
+			podAnnotation := &PodAnnotation{IPs: tc.podIPs}
+			network := &nadapi.NetworkSelectionElement{GatewayRequest: tc.gatewayRequest}
+			err = AddRoutesGatewayIP(netInfo, &v1.Pod{}, podAnnotation, network)
+			if tc.expErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprint(tc.expGateways), fmt.Sprint(podAnnotation.Gateways))
+			assert.Equal(t, fmt.Sprint(tc.expRoutes), fmt.Sprint(podAnnotation.Routes))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	listers "k8s.io/client-go/listers/core/v1"
+	utilnet "k8s.io/utils/net"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/kube"
+)
+
+// This handles the "k8s.ovn.org/pod-observed-ips" annotation on Pods, used to
+// report the IPs the pod was observed using on its IPAM-less secondary
+// networks, as learnt by the node it runs on from its ARP and ND traffic.
+// The annotation is keyed by NAD:
+//
+//   annotations:
+//     k8s.ovn.org/pod-observed-ips: |
+//       {
+//         "ns1/localnet": ["192.168.10.5", "2001:db8::5"]
+//       }
+//
+// The IPs of a NAD are the ones the pod used recently: the node adds them when
+// it observes them, unless another pod of the network already claims them, and
+// removes the ones it did not observe for a while.
+
+const (
+	// OvnPodObservedIPsAnnotationName is the pod annotation holding the IPs
+	// of the pod observed on its IPAM-less secondary networks
+	OvnPodObservedIPsAnnotationName = "k8s.ovn.org/pod-observed-ips"
+)
+
+// UnmarshalPodObservedIPsAllNetworks returns the observed IPs of the pod on
+// all of its networks, keyed by NAD
+func UnmarshalPodObservedIPsAllNetworks(annotations map[string]string) (map[string][]string, error) {
+	observedIPs := make(map[string][]string)
+	annotation, ok := annotations[OvnPodObservedIPsAnnotationName]
+	if ok {
+		if err := json.Unmarshal([]byte(annotation), &observedIPs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal OVN pod %s annotation %q: %v",
+				OvnPodObservedIPsAnnotationName, annotation, err)
+		}
+	}
+	return observedIPs, nil
+}
+
+// UnmarshalPodObservedIPs returns the observed IPs of the pod on the specified
+// NAD
+func UnmarshalPodObservedIPs(annotations map[string]string, nadName string) ([]net.IP, error) {
+	annotation, ok := annotations[OvnPodObservedIPsAnnotationName]
+	if !ok {
+		return nil, newAnnotationNotSetError("could not find OVN pod %s annotation in %v",
+			OvnPodObservedIPsAnnotationName, annotations)
+	}
+	observedIPs, err := UnmarshalPodObservedIPsAllNetworks(annotations)
+	if err != nil {
+		return nil, err
+	}
+	ipStrs, ok := observedIPs[nadName]
+	if !ok {
+		return nil, newAnnotationNotSetError("no OVN %s annotation for network %s: %q",
+			OvnPodObservedIPsAnnotationName, nadName, annotation)
+	}
+	ips := make([]net.IP, 0, len(ipStrs))
+	for _, ipStr := range ipStrs {
+		ip := utilnet.ParseIPSloppy(ipStr)
+		if ip == nil {
+			return nil, fmt.Errorf("failed to parse observed pod IP %q", ipStr)
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
+
+// MarshalPodObservedIPs sets the observed IPs of the pod on the specified NAD
+// in the corresponding pod annotation, removing the NAD if there are none. It
+// returns an AnnotationAlreadySetError if the annotation already has these
+// IPs.
+func MarshalPodObservedIPs(annotations map[string]string, ips []net.IP, nadName string) (map[string]string, error) {
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	observedIPs, err := UnmarshalPodObservedIPsAllNetworks(annotations)
+	if err != nil {
+		return nil, err
+	}
+	nadIPs := sets.New[string]()
+	for _, ip := range ips {
+		nadIPs.Insert(ip.String())
+	}
+	if nadIPs.Equal(sets.New[string](observedIPs[nadName]...)) {
+		return nil, newAnnotationAlreadySetError("OVN pod %s annotation for NAD %s already has IPs %v",
+			OvnPodObservedIPsAnnotationName, nadName, ips)
+	}
+	if nadIPs.Len() == 0 {
+		delete(observedIPs, nadName)
+	} else {
+		observedIPs[nadName] = sets.List(nadIPs)
+	}
+	bytes, err := json.Marshal(observedIPs)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling pod annotation map %v: %v", observedIPs, err)
+	}
+	annotations[OvnPodObservedIPsAnnotationName] = string(bytes)
+	return annotations, nil
+}
+
+// UpdatePodObservedIPsWithRetry sets the observed IPs annotation of the pod
+// on the specified NAD retrying on conflict
+func UpdatePodObservedIPsWithRetry(podLister listers.PodLister, kube kube.Interface, pod *v1.Pod, ips []net.IP, nadName string) error {
+	updatePodAnnotationNoRollback := func(pod *v1.Pod) (*v1.Pod, func(), error) {
+		var err error
+		pod.Annotations, err = MarshalPodObservedIPs(pod.Annotations, ips, nadName)
+		if IsAnnotationAlreadySetError(err) {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return pod, nil, nil
+	}
+
+	return UpdatePodWithRetryOrRollback(
+		podLister,
+		kube,
+		pod,
+		updatePodAnnotationNoRollback,
+	)
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"net"
+	"testing"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/onsi/gomega"
+
+	nadapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ovncnitypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/cni/types"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
+)
+
+func TestMarshalPodObservedIPs(t *testing.T) {
+	tests := []struct {
+		desc               string
+		annotations        map[string]string
+		ips                []string
+		nadName            string
+		expectedAnnotation string
+		expectAlreadySet   bool
+		expectErr          bool
+	}{
+		{
+			desc:               "adds the IPs of a NAD to a pod without annotation",
+			ips:                []string{"192.168.10.5", "2001:db8::5"},
+			nadName:            "ns1/localnet",
+			expectedAnnotation: `{"ns1/localnet":["192.168.10.5","2001:db8::5"]}`,
+		},
+		{
+			desc:               "replaces the IPs of the NAD, leaving the other NADs untouched",
+			annotations:        map[string]string{OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.5","192.168.10.7"],"ns1/layer2":["10.0.0.3"]}`},
+			ips:                []string{"192.168.10.6", "192.168.10.5"},
+			nadName:            "ns1/localnet",
+			expectedAnnotation: `{"ns1/layer2":["10.0.0.3"],"ns1/localnet":["192.168.10.5","192.168.10.6"]}`,
+		},
+		{
+			desc:             "returns an already set error when the IPs are the same",
+			annotations:      map[string]string{OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.5","192.168.10.6"]}`},
+			ips:              []string{"192.168.10.6", "192.168.10.5"},
+			nadName:          "ns1/localnet",
+			expectAlreadySet: true,
+		},
+		{
+			desc:             "returns an already set error when there are no IPs for a NAD not in the annotation",
+			annotations:      map[string]string{OvnPodObservedIPsAnnotationName: `{"ns1/layer2":["10.0.0.3"]}`},
+			nadName:          "ns1/localnet",
+			expectAlreadySet: true,
+		},
+		{
+			desc:               "removes the NAD without IPs",
+			annotations:        map[string]string{OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.5"],"ns1/layer2":["10.0.0.3"]}`},
+			nadName:            "ns1/localnet",
+			expectedAnnotation: `{"ns1/layer2":["10.0.0.3"]}`,
+		},
+		{
+			desc:        "fails on a malformed annotation",
+			annotations: map[string]string{OvnPodObservedIPsAnnotationName: `["192.168.10.5"]`},
+			ips:         []string{"192.168.10.6"},
+			nadName:     "ns1/localnet",
+			expectErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			ips := make([]net.IP, 0, len(tc.ips))
+			for _, ip := range tc.ips {
+				ips = append(ips, net.ParseIP(ip))
+			}
+			annotations, err := MarshalPodObservedIPs(tc.annotations, ips, tc.nadName)
+			switch {
+			case tc.expectAlreadySet:
+				g.Expect(IsAnnotationAlreadySetError(err)).To(gomega.BeTrue())
+			case tc.expectErr:
+				g.Expect(err).To(gomega.HaveOccurred())
+				g.Expect(IsAnnotationAlreadySetError(err)).To(gomega.BeFalse())
+			default:
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(annotations).To(gomega.HaveKeyWithValue(OvnPodObservedIPsAnnotationName, tc.expectedAnnotation))
+				observedIPs, err := UnmarshalPodObservedIPs(annotations, tc.nadName)
+				if len(ips) == 0 {
+					g.Expect(IsAnnotationNotSetError(err)).To(gomega.BeTrue())
+					return
+				}
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(observedIPs).To(gomega.ConsistOf(ips))
+			}
+		})
+	}
+}
+
+func TestUnmarshalPodObservedIPs(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	_, err := UnmarshalPodObservedIPs(nil, "ns1/localnet")
+	g.Expect(IsAnnotationNotSetError(err)).To(gomega.BeTrue())
+
+	annotations := map[string]string{OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.5"]}`}
+	_, err = UnmarshalPodObservedIPs(annotations, "ns1/layer2")
+	g.Expect(IsAnnotationNotSetError(err)).To(gomega.BeTrue())
+
+	ips, err := UnmarshalPodObservedIPs(annotations, "ns1/localnet")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ips).To(gomega.Equal([]net.IP{net.ParseIP("192.168.10.5")}))
+
+	annotations[OvnPodObservedIPsAnnotationName] = `{"ns1/localnet":["192.168.10"]}`
+	_, err = UnmarshalPodObservedIPs(annotations, "ns1/localnet")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestSecondaryNetworkPodIPsObservedIPs(t *testing.T) {
+	const nadName = "ns1/localnet"
+	tests := []struct {
+		desc                 string
+		subnets              string
+		enableObservedPodIPs bool
+		expectedIPs          []string
+	}{
+		{
+			desc:                 "returns the observed IPs on IPAM-less networks",
+			enableObservedPodIPs: true,
+			expectedIPs:          []string{"192.168.10.5"},
+		},
+		{
+			desc:        "ignores the observed IPs when disabled",
+			expectedIPs: []string{},
+		},
+		{
+			desc:                 "ignores the observed IPs on networks with IPAM",
+			subnets:              "10.100.0.0/16",
+			enableObservedPodIPs: true,
+			expectedIPs:          []string{"10.100.0.4"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(config.PrepareTestConfig()).To(gomega.Succeed())
+			config.OVNKubernetesFeature.EnableMultiNetwork = true
+			config.OVNKubernetesFeature.EnableMultiNetworkPolicy = true
+			config.OVNKubernetesFeature.EnableObservedPodIPs = tc.enableObservedPodIPs
+
+			netInfo, err := NewNetInfo(&ovncnitypes.NetConf{
+				NetConf:  cnitypes.NetConf{Name: "localnet", Type: "ovn-k8s-cni-overlay"},
+				Topology: types.LocalnetTopology,
+				Subnets:  tc.subnets,
+				NADName:  nadName,
+			})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			netInfo.AddNAD(nadName)
+
+			podNetworks := `{"ns1/localnet":{"ip_addresses":null,"mac_address":"0a:58:0a:64:00:04"}}`
+			if tc.subnets != "" {
+				podNetworks = `{"ns1/localnet":{"ip_addresses":["10.100.0.4/16"],"mac_address":"0a:58:0a:64:00:04"}}`
+			}
+
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+					Annotations: map[string]string{
+						nadapi.NetworkAttachmentAnnot:   "localnet",
+						OvnPodAnnotationName:            podNetworks,
+						OvnPodObservedIPsAnnotationName: `{"ns1/localnet":["192.168.10.5"]}`,
+					},
+				},
+			}
+			ips, err := SecondaryNetworkPodIPs(pod, netInfo)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			ipStrs := make([]string, 0, len(ips))
+			for _, ip := range ips {
+				ipStrs = append(ipStrs, ip.String())
+			}
+			g.Expect(ipStrs).To(gomega.Equal(tc.expectedIPs))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
+
+	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// LBHealthCheckAnnotation is used to enable OVN load balancer health
+	// checks for the backends of a service. Its value is a JSON encoded
+	// LBHealthCheckConfig, "{}" uses the OVN defaults.
+	LBHealthCheckAnnotation = "k8s.ovn.org/lb-health-check"
+	// LBSelectionFieldsAnnotation is used to choose the fields hashed by OVN to
+	// select the backend of a new connection to the service. Its value is
+	// either a comma separated list of OVN load balancer selection fields
+	// (eth_src, eth_dst, ip_src, ip_dst, tp_src, tp_dst) or one of the
+	// LBSelectionFieldsSrcIP or LBSelectionFields5Tuple presets.
+	// Setting selection fields makes OVS use consistent hashing to select
+	// backends, which minimizes the redistribution of existing connections
+	// when the set of endpoints changes.
+	LBSelectionFieldsAnnotation = "k8s.ovn.org/lb-selection-fields"
+	// PreserveSourceIPAnnotation is used on services with
+	// ExternalTrafficPolicy=Cluster to preserve the client source IP of
+	// traffic towards the service external IPs and load balancer ingress IPs
+	// in shared gateway mode. Set to "true" to enable.
+	PreserveSourceIPAnnotation = "k8s.ovn.org/preserve-source-ip"
+	// LBPortRangeAnnotation is used to forward a whole range of ports of the
+	// service ClusterIPs, ExternalIPs and load balancer ingress IPs to the same
+	// ports of the endpoints, instead of only the service ports. Its value is
+	// either a "<first>-<last>" port range, applied to each protocol of the
+	// service ports, or LBPortRangeAll to forward all ports and protocols of
+	// the ExternalIPs and load balancer ingress IPs only.
+	// The endpoints are the ones selected for the first service port. NodePorts
+	// are not affected.
+	LBPortRangeAnnotation = "k8s.ovn.org/lb-port-range"
+
+	// LBPortRangeAll forwards all ports and protocols
+	LBPortRangeAll = "all"
+
+	// LBSelectionFieldsSrcIP hashes on the client source IP only
+	LBSelectionFieldsSrcIP = "src-ip"
+	// LBSelectionFields5Tuple hashes on the connection 5-tuple
+	LBSelectionFields5Tuple = "5-tuple"
+)
+
+// LBPortRange is a range of service ports forwarded to the endpoints. The zero
+// value stands for all ports and protocols.
+type LBPortRange struct {
+	First int32
+	Last  int32
+}
+
+// AllPorts returns true if all ports and protocols are forwarded
+func (r *LBPortRange) AllPorts() bool {
+	return r.First == 0
+}
+
+// AppliesTo returns true if the range is forwarded on behalf of the given port
+// of the service, that is the first service port of each protocol for a port
+// range, or the first service port for all ports.
+func (r *LBPortRange) AppliesTo(service *kapi.Service, svcPort kapi.ServicePort) bool {
+	for _, port := range service.Spec.Ports {
+		if r.AllPorts() || port.Protocol == svcPort.Protocol {
+			// service port names are unique
+			return port.Name == svcPort.Name
+		}
+	}
+	return false
+}
+
+// String returns the textual representation of the range as found in the
+// LBPortRangeAnnotation
+func (r *LBPortRange) String() string {
+	if r.AllPorts() {
+		return LBPortRangeAll
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+var lbSelectionFields = sets.New[string]("eth_src", "eth_dst", "ip_src", "ip_dst", "tp_src", "tp_dst")
+
+// LBHealthCheckConfig holds the OVN load balancer health check options
+// requested for a service. Unset (zero) values fall back to the OVN defaults.
+type LBHealthCheckConfig struct {
+	// Interval in seconds between two health checks
+	Interval int `json:"interval,omitempty"`
+	// Timeout in seconds of a single health check
+	Timeout int `json:"timeout,omitempty"`
+	// SuccessCount is the number of successful checks after which a backend
+	// is considered online
+	SuccessCount int `json:"successCount,omitempty"`
+	// FailureCount is the number of failed checks after which a backend is
+	// considered offline
+	FailureCount int `json:"failureCount,omitempty"`
+}
+
+// ParseLBHealthCheckAnnotation returns the load balancer health check
+// configuration of the service, or nil if the service does not have the
+// LBHealthCheckAnnotation set.
+func ParseLBHealthCheckAnnotation(service *kapi.Service) (*LBHealthCheckConfig, error) {
+	annotation, ok := service.Annotations[LBHealthCheckAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	hc := &LBHealthCheckConfig{}
+	if err := json.Unmarshal([]byte(annotation), hc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s annotation %q: %w", LBHealthCheckAnnotation, annotation, err)
+	}
+	if hc.Interval < 0 || hc.Timeout < 0 || hc.SuccessCount < 0 || hc.FailureCount < 0 {
+		return nil, fmt.Errorf("invalid %s annotation %q: values must not be negative", LBHealthCheckAnnotation, annotation)
+	}
+	return hc, nil
+}
+
+// ParseLBSelectionFieldsAnnotation returns the sorted list of OVN load balancer
+// selection fields requested by the service, or nil if the service does not
+// have the LBSelectionFieldsAnnotation set.
+func ParseLBSelectionFieldsAnnotation(service *kapi.Service) ([]string, error) {
+	annotation, ok := service.Annotations[LBSelectionFieldsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	switch annotation {
+	case LBSelectionFieldsSrcIP:
+		return []string{"ip_src"}, nil
+	case LBSelectionFields5Tuple:
+		// the protocol is implicit, load balancers are per protocol
+		return []string{"ip_dst", "ip_src", "tp_dst", "tp_src"}, nil
+	}
+	fields := sets.New[string]()
+	for _, field := range strings.Split(annotation, ",") {
+		field = strings.TrimSpace(field)
+		if !lbSelectionFields.Has(field) {
+			return nil, fmt.Errorf("invalid %s annotation %q: unknown selection field %q", LBSelectionFieldsAnnotation, annotation, field)
+		}
+		fields.Insert(field)
+	}
+	out := fields.UnsortedList()
+	sort.Strings(out)
+	return out, nil
+}
+
+// ParseLBPortRangeAnnotation returns the range of ports forwarded by the
+// service, or nil if the service does not have the LBPortRangeAnnotation set.
+// Port ranges are not supported together with local external or internal
+// traffic policies or source IP preservation.
+func ParseLBPortRangeAnnotation(service *kapi.Service) (*LBPortRange, error) {
+	annotation, ok := service.Annotations[LBPortRangeAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	if ServiceExternalTrafficPolicyLocal(service) || ServiceInternalTrafficPolicyLocal(service) || ServicePreserveSourceIP(service) {
+		return nil, fmt.Errorf("%s annotation is not supported with local traffic policies or source IP preservation", LBPortRangeAnnotation)
+	}
+	if annotation == LBPortRangeAll {
+		return &LBPortRange{}, nil
+	}
+	first, last, found := strings.Cut(annotation, "-")
+	if !found {
+		return nil, fmt.Errorf("invalid %s annotation %q: expected %q or a <first>-<last> port range", LBPortRangeAnnotation, annotation, LBPortRangeAll)
+	}
+	firstPort, err := strconv.ParseInt(strings.TrimSpace(first), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", LBPortRangeAnnotation, annotation, err)
+	}
+	lastPort, err := strconv.ParseInt(strings.TrimSpace(last), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", LBPortRangeAnnotation, annotation, err)
+	}
+	if firstPort < 1 || lastPort > 65535 || firstPort > lastPort {
+		return nil, fmt.Errorf("invalid %s annotation %q: invalid port range", LBPortRangeAnnotation, annotation)
+	}
+	return &LBPortRange{First: int32(firstPort), Last: int32(lastPort)}, nil
+}
+
+// ServicePreserveSourceIP returns true if the client source IP of external
+// traffic towards the service has to be preserved even though the service has
+// ExternalTrafficPolicy=Cluster. External traffic is then forwarded at L2 from
+// the ingress node to a node hosting endpoints of the service, whose gateway
+// router delivers it without SNAT; replies are sent back directly from that
+// node (direct server return). It requires the nodes to share the L2 segment
+// of the gateway bridge uplink and is only supported in shared gateway mode.
+func ServicePreserveSourceIP(service *kapi.Service) bool {
+	return config.GetGatewayMode() == config.GatewayModeShared &&
+		!ServiceExternalTrafficPolicyLocal(service) &&
+		service.Annotations[PreserveSourceIPAnnotation] == "true"
+}
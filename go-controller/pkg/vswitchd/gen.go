@@ -0,0 +1,3 @@
+package vswitchd
+
+//go:generate modelgen --extended -p vswitchd -o . vswitch.ovsschema